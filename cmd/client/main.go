@@ -1,16 +1,19 @@
 package main
 
 import (
-	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"strconv"
-	"strings"
+	"time"
 
 	_ "github.com/joho/godotenv/autoload"
 
+	"github.com/shekshuev/gophkeeper/internal/certs"
 	"github.com/shekshuev/gophkeeper/internal/client"
 	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
 	"github.com/shekshuev/gophkeeper/internal/utils"
 )
 
@@ -37,11 +40,21 @@ func printBuildInfo() {
 	fmt.Printf("Коммит: %s\n\n", buildCommit)
 }
 
+// shutdownTracing выгружает спаны CLI, накопленные к моменту выхода.
+var shutdownTracing = func(context.Context) error { return nil }
+
+// exit завершает CLI, предварительно отправив накопленные спаны.
+func exit() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		fmt.Println("Не удалось отправить трассировку:", err)
+	}
+	os.Exit(0)
+}
+
 func prompt(label string) string {
-	fmt.Print(label)
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-	return strings.TrimSpace(scanner.Text())
+	return client.Prompt(label)
 }
 
 func isTokenValid(loadToken func() (string, error), getConfig func() config.Config) bool {
@@ -62,13 +75,28 @@ func isTokenValidDefault() bool {
 	return isTokenValid(client.LoadToken, config.GetConfig)
 }
 
+// mainMenu — меню авторизованного пользователя. Пока оно открыто, CLI подписан на ленту изменений
+// и сообщает о секретах, изменённых на других устройствах.
 func mainMenu() bool {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.WatchChanges(ctx, client.Api())
+
 	for {
 		fmt.Println(`[1] Создать секрет
 [2] Показать все секреты
 [3] Получить секрет по ID
 [4] Удалить секрет по ID
 [5] Завершить сессию
+[6] Папки и теги
+[7] Поиск секретов
+[8] Журнал просмотров секрета
+[9] Изменить секрет
+[10] История версий секрета
+[11] Корзина
+[12] Импорт из другого менеджера паролей
+[13] Экспорт в базу KeePass
+[14] Резервная копия
 [0] Выйти`)
 		choice := prompt("Выберите действие > ")
 
@@ -77,7 +105,7 @@ func mainMenu() bool {
 			title := prompt("Введите название секрета: ")
 			client.CreateSecret(title, client.Api())
 		case "2":
-			client.ListSecrets(client.Api(), client.GetUserIDFromToken)
+			client.ListSecrets(client.Api(), models.SecretFilterDTO{})
 		case "3":
 			idStr := prompt("Введите ID секрета: ")
 			id, err := strconv.ParseUint(idStr, 10, 64)
@@ -93,6 +121,10 @@ func mainMenu() bool {
 				fmt.Println("Некорректный ID")
 				continue
 			}
+			if !client.Confirm(fmt.Sprintf("Переместить секрет %d в корзину?", id)) {
+				fmt.Println("Отменено.")
+				continue
+			}
 			client.DeleteSecret(id, client.Api())
 		case "5":
 			err := client.Logout()
@@ -102,9 +134,201 @@ func mainMenu() bool {
 				fmt.Println("Сессия завершена.")
 			}
 			return false
+		case "6":
+			foldersMenu()
+		case "7":
+			query := prompt("Часть названия (Enter — любое): ")
+			typ := prompt("Тип: login, card, text, binary (Enter — любой): ")
+			client.ListSecrets(client.Api(), models.SecretFilterDTO{Query: query, Type: typ})
+		case "8":
+			id, err := strconv.ParseUint(prompt("Введите ID секрета: "), 10, 64)
+			if err != nil {
+				fmt.Println("Некорректный ID")
+				continue
+			}
+			client.SecretAudit(id, client.Api())
+		case "9":
+			id, err := strconv.ParseUint(prompt("Введите ID секрета: "), 10, 64)
+			if err != nil {
+				fmt.Println("Некорректный ID")
+				continue
+			}
+			title := prompt("Новое название секрета: ")
+			client.UpdateSecret(id, title, client.Api())
+		case "10":
+			id, err := strconv.ParseUint(prompt("Введите ID секрета: "), 10, 64)
+			if err != nil {
+				fmt.Println("Некорректный ID")
+				continue
+			}
+			versionsMenu(id)
+		case "11":
+			trashMenu()
+		case "12":
+			path := prompt("Путь к файлу экспорта: ")
+			format := prompt("Формат (" + client.ImportFormats() + "; Enter — определить автоматически): ")
+			client.ImportSecrets(path, format, client.Api())
+		case "13":
+			client.ExportKeePass(prompt("Путь к файлу базы (.kdbx): "), client.Api())
+		case "14":
+			backupMenu()
 		case "0":
 			fmt.Println("До свидания!")
-			os.Exit(0)
+			exit()
+		default:
+			fmt.Println("Неизвестная команда")
+		}
+		fmt.Println()
+	}
+}
+
+func foldersMenu() {
+	for {
+		fmt.Println(`[1] Показать дерево папок
+[2] Создать папку
+[3] Переименовать папку
+[4] Переместить папку
+[5] Показать секреты в папке
+[6] Показать секреты по тегу
+[0] Назад`)
+		choice := prompt("Выберите действие > ")
+
+		switch choice {
+		case "1":
+			client.ListFolders(client.Api())
+		case "2":
+			name := prompt("Название папки: ")
+			parentID, err := client.ParseOptionalID(prompt("ID родительской папки (Enter — корень): "))
+			if err != nil {
+				fmt.Println("Некорректный ID")
+				continue
+			}
+			client.CreateFolder(name, parentID, client.Api())
+		case "3":
+			id, err := strconv.ParseUint(prompt("ID папки: "), 10, 64)
+			if err != nil {
+				fmt.Println("Некорректный ID")
+				continue
+			}
+			client.RenameFolder(id, prompt("Новое название: "), client.Api())
+		case "4":
+			id, err := strconv.ParseUint(prompt("ID папки: "), 10, 64)
+			if err != nil {
+				fmt.Println("Некорректный ID")
+				continue
+			}
+			parentID, err := client.ParseOptionalID(prompt("ID новой родительской папки (Enter — корень): "))
+			if err != nil {
+				fmt.Println("Некорректный ID")
+				continue
+			}
+			client.MoveFolder(id, parentID, client.Api())
+		case "5":
+			folderID, err := client.ParseOptionalID(prompt("ID папки (Enter — секреты вне папок): "))
+			if err != nil {
+				fmt.Println("Некорректный ID")
+				continue
+			}
+			if folderID == nil {
+				root := uint64(0)
+				folderID = &root
+			}
+			client.ListSecrets(client.Api(), models.SecretFilterDTO{FolderID: folderID})
+		case "6":
+			tag := prompt("Тег: ")
+			client.ListSecrets(client.Api(), models.SecretFilterDTO{Tag: tag})
+		case "0":
+			return
+		default:
+			fmt.Println("Неизвестная команда")
+		}
+		fmt.Println()
+	}
+}
+
+func versionsMenu(id uint64) {
+	for {
+		fmt.Println(`[1] Показать версии
+[2] Посмотреть версию
+[3] Восстановить версию
+[0] Назад`)
+		choice := prompt("Выберите действие > ")
+
+		switch choice {
+		case "1":
+			client.ListVersions(id, client.Api())
+		case "2", "3":
+			version, err := strconv.Atoi(prompt("Номер версии: "))
+			if err != nil || version < 1 {
+				fmt.Println("Некорректный номер версии")
+				continue
+			}
+			if choice == "2" {
+				client.GetSecretVersion(id, version, client.Api())
+			} else {
+				client.RestoreSecretVersion(id, version, client.Api())
+			}
+		case "0":
+			return
+		default:
+			fmt.Println("Неизвестная команда")
+		}
+		fmt.Println()
+	}
+}
+
+func trashMenu() {
+	for {
+		fmt.Println(`[1] Показать корзину
+[2] Восстановить секрет
+[3] Удалить секрет окончательно
+[0] Назад`)
+		choice := prompt("Выберите действие > ")
+
+		switch choice {
+		case "1":
+			client.ListTrash(client.Api())
+		case "2", "3":
+			id, err := strconv.ParseUint(prompt("Введите ID секрета: "), 10, 64)
+			if err != nil {
+				fmt.Println("Некорректный ID")
+				continue
+			}
+			if choice == "2" {
+				client.RestoreFromTrash(id, client.Api())
+				break
+			}
+			if !client.Confirm(fmt.Sprintf("Удалить секрет %d без возможности восстановления?", id)) {
+				fmt.Println("Отменено.")
+				continue
+			}
+			client.PurgeSecret(id, client.Api())
+		case "0":
+			return
+		default:
+			fmt.Println("Неизвестная команда")
+		}
+		fmt.Println()
+	}
+}
+
+func backupMenu() {
+	for {
+		fmt.Println(`[1] Создать резервную копию
+[2] Проверить резервную копию
+[3] Восстановить из резервной копии
+[0] Назад`)
+		choice := prompt("Выберите действие > ")
+
+		switch choice {
+		case "1":
+			client.ExportBackup(prompt("Путь к файлу копии (.age): "), client.Api())
+		case "2":
+			client.VerifyBackup(prompt("Путь к файлу копии: "))
+		case "3":
+			client.RestoreBackup(prompt("Путь к файлу копии: "), client.Api())
+		case "0":
+			return
 		default:
 			fmt.Println("Неизвестная команда")
 		}
@@ -130,7 +354,7 @@ func authMenu() bool {
 			}
 		case "0":
 			fmt.Println("До свидания!")
-			os.Exit(0)
+			exit()
 		default:
 			fmt.Println("Неизвестная команда")
 		}
@@ -140,6 +364,16 @@ func authMenu() bool {
 
 func main() {
 	printBuildInfo()
+	cfg := config.GetConfig()
+	if shutdown, err := tracing.Setup(context.Background(), &cfg, "gophkeeper-cli", buildVersion); err != nil {
+		fmt.Println("Трассировка отключена:", err)
+	} else {
+		shutdownTracing = shutdown
+	}
+	if _, err := certs.ClientConfig(&cfg); err != nil {
+		fmt.Println("Ошибка настройки TLS:", err)
+		os.Exit(1)
+	}
 
 	for {
 		if isTokenValidDefault() {
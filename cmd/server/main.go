@@ -2,8 +2,12 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"database/sql"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,12 +15,22 @@ import (
 	"time"
 
 	_ "github.com/joho/godotenv/autoload"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
+	"github.com/shekshuev/gophkeeper/internal/certs"
 	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/database"
+	"github.com/shekshuev/gophkeeper/internal/events"
+	"github.com/shekshuev/gophkeeper/internal/grpcserver"
 	"github.com/shekshuev/gophkeeper/internal/handler"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/metrics"
 
 	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/shekshuev/gophkeeper/internal/repository/memory"
 	"github.com/shekshuev/gophkeeper/internal/service"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
 )
 
 var (
@@ -41,39 +55,222 @@ func printBuildInfo() {
 	fmt.Printf("Build commit: %s\n", buildCommit)
 }
 
-func NewServer(cfg *config.Config) *http.Server {
-	userRepo := repository.NewUserRepositoryImpl(cfg)
-	secretRepo := repository.NewSecretRepositoryImpl(cfg)
-	userService := service.NewUserServiceImpl(userRepo, cfg)
-	authService := service.NewAuthServiceImpl(userRepo, cfg)
-	secretService := service.NewSecretServiceImpl(secretRepo)
-	userHandler := handler.NewHandler(userService, authService, secretService, cfg)
+// NewServer создаёт HTTP-сервер REST API и gRPC-сервер поверх общих сервисов.
+// Оба сервера принимают только TLS-соединения (см. certs.ServerConfig): HTTP-сервер запускается
+// через ListenAndServeTLS("", "") с сертификатом из TLSConfig. Если включены сертификаты устройств,
+// клиенты могут предъявить в TLS-соединении сертификат своего устройства (см. newDeviceCA).
+// Фоновые задачи и потоки событий SSE останавливаются вместе с HTTP-сервером.
+// События об изменениях секретов доставляются подписчикам всех экземпляров сервера (см. newRelay)
+// и вебхукам пользователей через очередь доставок в хранилище.
+// Репозитории выбираются по cfg.Storage. В базе данных все репозитории работают через один пул соединений;
+// он возвращается, чтобы закрыть его после остановки серверов. С хранилищем в памяти пул равен nil.
+// Фоновые задачи, работающие с хранилищем, запускает возвращаемая функция start: её вызывают после того,
+// как база данных стала доступна (см. waitForDatabase), чтобы задачи не обращались к неготовой базе.
+func NewServer(cfg *config.Config) (*http.Server, *grpcserver.Server, *sql.DB, func()) {
+	var (
+		db    *sql.DB
+		repos repositories
+		err   error
+	)
+	switch cfg.Storage {
+	case config.StorageDatabase:
+		if db, err = database.Open(cfg); err != nil {
+			log.Fatal("Error opening database: ", err)
+		}
+		repos = newDatabaseRepositories(db, cfg)
+	case config.StorageMemory:
+		repos = newMemoryRepositories(cfg)
+	default:
+		log.Fatalf("Unknown storage %q: use %q or %q", cfg.Storage, config.StorageDatabase, config.StorageMemory)
+	}
+
+	tlsConfig, runTLS, err := certs.ServerConfig(cfg)
+	if err != nil {
+		log.Fatal("Error configuring TLS: ", err)
+	}
+	ca := newDeviceCA(cfg)
+	if ca != nil {
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		tlsConfig.ClientCAs = ca.Pool()
+	}
+
+	webhookService := service.NewWebhookServiceImpl(repos.webhooks, cfg)
+	healthService := service.NewHealthServiceImpl(repos.health, tlsConfig, ca, cfg)
+	userService := service.NewUserServiceImpl(repos.users, cfg)
+	relay, runRelay := newRelay(db, cfg)
+	deviceService := service.NewDeviceServiceImpl(repos.devices, ca, relay)
+	authService := service.NewAuthServiceImpl(repos.users, repos.folders, repos.loginAttempts, webhookService, deviceService, repos.tx, cfg)
+	broker := events.NewRelayBroker(relay)
+	secretService := service.NewSecretServiceImpl(repos.secrets, repos.folders, repos.audit, repos.tx, webhookService, broker)
+	folderService := service.NewFolderServiceImpl(repos.folders, repos.tx)
+	idempotencyService := service.NewIdempotencyServiceImpl(repos.idempotency, cfg)
+	userHandler := handler.NewHandler(userService, authService, secretService, folderService, idempotencyService, webhookService, healthService, deviceService, broker, cfg)
+	grpcServer := grpcserver.NewServer(userService, authService, secretService, deviceService, broker, cfg, grpc.Creds(credentials.NewTLS(tlsConfig)))
+
+	server := &http.Server{
+		Addr:      cfg.ServerAddress,
+		Handler:   userHandler.Router,
+		TLSConfig: tlsConfig,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	server.RegisterOnShutdown(cancel)
+	server.RegisterOnShutdown(userHandler.CloseStreams)
+	go runTLS(ctx)
+	start := func() {
+		go service.NewTrashPurger(repos.secrets, cfg).Run(ctx)
+		go idempotencyService.Run(ctx)
+		go runRelay(ctx)
+		go webhookService.Run(ctx)
+	}
+
+	return server, grpcServer, db, start
+}
+
+// newDeviceCA загружает центр сертификации устройств, если сертификаты устройств включены (cfg.DeviceCertMode).
+// Тогда TLS-серверы запрашивают у клиентов сертификат, подписанный этим центром, но не требуют его:
+// без сертификата устройство может войти и получить его. Если сертификаты выключены, возвращает nil.
+func newDeviceCA(cfg *config.Config) *certs.DeviceCA {
+	switch cfg.DeviceCertMode {
+	case config.DeviceCertOff:
+		return nil
+	case config.DeviceCertOptional, config.DeviceCertRequired:
+	default:
+		log.Fatalf("Unknown device certificate mode %q: use %q, %q or %q", cfg.DeviceCertMode, config.DeviceCertOff, config.DeviceCertOptional, config.DeviceCertRequired)
+	}
+	ca, err := certs.LoadDeviceCA(cfg)
+	if err != nil {
+		log.Fatal("Error loading device certificate authority: ", err)
+	}
+	return ca
+}
+
+// repositories — репозитории сервера в выбранном хранилище.
+type repositories struct {
+	tx            repository.TxManager
+	users         repository.UserRepository
+	secrets       repository.SecretRepository
+	folders       repository.FolderRepository
+	audit         repository.SecretAuditRepository
+	idempotency   repository.IdempotencyRepository
+	webhooks      repository.WebhookRepository
+	loginAttempts repository.LoginAttemptRepository
+	health        repository.HealthRepository
+	devices       repository.DeviceCertificateRepository
+}
 
-	return &http.Server{
-		Addr:    cfg.ServerAddress,
-		Handler: userHandler.Router,
+// newDatabaseRepositories создаёт репозитории поверх пула соединений с базой данных.
+func newDatabaseRepositories(db *sql.DB, cfg *config.Config) repositories {
+	return repositories{
+		tx:            repository.NewTxManagerImpl(db, cfg),
+		users:         repository.NewUserRepositoryImpl(db, cfg),
+		secrets:       repository.NewSecretRepositoryImpl(db, cfg),
+		folders:       repository.NewFolderRepositoryImpl(db, cfg),
+		audit:         repository.NewSecretAuditRepositoryImpl(db, cfg),
+		idempotency:   repository.NewIdempotencyRepositoryImpl(db, cfg),
+		webhooks:      repository.NewWebhookRepositoryImpl(db, cfg),
+		loginAttempts: repository.NewLoginAttemptRepositoryImpl(db, cfg),
+		health:        repository.NewHealthRepositoryImpl(db, cfg),
+		devices:       repository.NewDeviceCertificateRepositoryImpl(db, cfg),
 	}
 }
 
+// newMemoryRepositories создаёт пустые репозитории в памяти процесса.
+func newMemoryRepositories(cfg *config.Config) repositories {
+	return repositories{
+		tx:            memory.NewTxManager(),
+		users:         memory.NewUserRepository(),
+		secrets:       memory.NewSecretRepository(cfg),
+		folders:       memory.NewFolderRepository(),
+		audit:         memory.NewSecretAuditRepository(),
+		idempotency:   memory.NewIdempotencyRepository(),
+		webhooks:      memory.NewWebhookRepository(),
+		loginAttempts: memory.NewLoginAttemptRepository(),
+		health:        memory.NewHealthRepository(),
+		devices:       memory.NewDeviceCertificateRepository(),
+	}
+}
+
+// newRelay выбирает канал сообщений между экземплярами сервера по хранилищу. С PostgreSQL сообщения
+// доходят до всех экземпляров через LISTEN/NOTIFY. С SQLite и хранилищем в памяти работает один
+// экземпляр сервера, поэтому сообщения доставляются в памяти процесса. Возвращаемая функция
+// выполняет фоновую работу канала до отмены контекста.
+func newRelay(db *sql.DB, cfg *config.Config) (events.Relay, func(ctx context.Context)) {
+	if cfg.Storage == config.StorageMemory || database.IsSQLite(cfg.DatabaseDSN) {
+		return events.NewLocalRelay(), func(context.Context) {}
+	}
+	pg := events.NewPostgresRelay(db, cfg)
+	return pg, pg.Run
+}
+
+// waitForDatabase не даёт серверу стартовать, пока база данных недоступна:
+// после cfg.DatabaseConnectAttempts неудачных попыток возвращает ошибку.
+func waitForDatabase(db *sql.DB, cfg *config.Config) error {
+	health := service.NewHealthServiceImpl(repository.NewHealthRepositoryImpl(db, cfg), nil, nil, cfg)
+	return health.WaitForDatabase(context.Background())
+}
+
 func main() {
 	printBuildInfo()
+	metrics.SetBuildInfo(buildVersion, buildCommit)
 	cfg := config.GetConfig()
-	server := NewServer(&cfg)
+	flag.StringVar(&cfg.Storage, "storage", cfg.Storage, `where to keep data: "database" (DATABASE_DSN) or "memory"`)
+	flag.Parse()
+	if err := logger.Configure(cfg.LogLevel, cfg.LogFormat, cfg.LogFile); err != nil {
+		log.Fatal("Error configuring logger: ", err)
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), &cfg, "gophkeeper-server", buildVersion)
+	if err != nil {
+		log.Fatal("Error configuring tracing: ", err)
+	}
+	server, grpcServer, db, start := NewServer(&cfg)
+	if db != nil {
+		if err := waitForDatabase(db, &cfg); err != nil {
+			log.Fatal("Error connecting to database: ", err)
+		}
+		if database.IsSQLite(cfg.DatabaseDSN) {
+			if err := database.MigrateSQLite(context.Background(), db); err != nil {
+				log.Fatal("Error applying SQLite migrations: ", err)
+			}
+		}
+	}
+	start()
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGTERM)
 	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := server.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
 			log.Fatal("Error starting server")
 		}
 	}()
-	log.Print("Server listening on ", cfg.ServerAddress)
+	log.Print("Server listening on https://", cfg.ServerAddress)
+	if cfg.GRPCAddress != "" {
+		listener, err := net.Listen("tcp", cfg.GRPCAddress)
+		if err != nil {
+			log.Fatal("Error starting gRPC server")
+		}
+		go func() {
+			if err := grpcServer.GRPC.Serve(listener); err != nil {
+				log.Fatal("Error starting gRPC server")
+			}
+		}()
+		log.Print("gRPC server listening on ", cfg.GRPCAddress)
+	}
 	<-done
 	log.Print("Shutting down server...")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	grpcServer.Shutdown(ctx)
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatal("Server forced to shutdown")
 	} else {
 		log.Print("Server shutdown gracefully")
 	}
+	if db != nil {
+		if err := db.Close(); err != nil {
+			log.Print("Error closing database: ", err)
+		}
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		log.Print("Error flushing traces: ", err)
+	}
 }
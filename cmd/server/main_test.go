@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"net/http"
 	"os"
@@ -10,7 +11,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shekshuev/gophkeeper/internal/certs"
 	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/models"
 	"github.com/stretchr/testify/require"
 )
 
@@ -75,7 +78,8 @@ func TestServer_HealthCheck(t *testing.T) {
 	_ = os.Setenv("REFRESH_TOKEN_SECRET", "refresh")
 
 	cfg := config.GetConfig()
-	srv := NewServer(&cfg)
+	srv, _, db, _ := NewServer(&cfg)
+	defer db.Close()
 
 	go func() {
 		_ = srv.ListenAndServe()
@@ -92,3 +96,94 @@ func TestServer_HealthCheck(t *testing.T) {
 	require.Equal(t, http.StatusOK, resp.StatusCode)
 	require.Contains(t, string(body), "ok")
 }
+
+func TestServer_MemoryStorage(t *testing.T) {
+	_ = os.Setenv("SERVER_ADDRESS", "127.0.0.1:8091")
+	_ = os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	_ = os.Setenv("REFRESH_TOKEN_EXPIRES", "1h")
+	_ = os.Setenv("ACCESS_TOKEN_SECRET", "access")
+	_ = os.Setenv("REFRESH_TOKEN_SECRET", "refresh")
+
+	cfg := config.GetConfig()
+	cfg.Storage = config.StorageMemory
+	srv, _, db, start := NewServer(&cfg)
+	require.Nil(t, db)
+	start()
+
+	go func() {
+		_ = srv.ListenAndServe()
+	}()
+	defer srv.Close()
+
+	time.Sleep(200 * time.Millisecond)
+	baseURL := "http://" + cfg.ServerAddress
+
+	send := func(method, path, token string, body any) *http.Response {
+		payload, err := json.Marshal(body)
+		require.NoError(t, err)
+		req, err := http.NewRequest(method, baseURL+path, bytes.NewReader(payload))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := send(http.MethodGet, "/ready", "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = send(http.MethodPost, "/v1.0/auth/register", "", models.RegisterUserDTO{
+		UserName: "test_user", Password: "test123!", PasswordConfirm: "test123!", FirstName: "John", LastName: "Doe",
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var tokens models.ReadTokenDTO
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+
+	note := "hello"
+	resp = send(http.MethodPost, "/v1.0/secrets/", tokens.AccessToken, models.CreateSecretDTO{
+		Title: "Note",
+		Data:  models.SecretDataDTO{Text: &note},
+		Tags:  []string{"demo"},
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp = send(http.MethodGet, "/v1.0/secrets/export?tag=demo", tokens.AccessToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), "hello")
+}
+
+func TestServer_TLS(t *testing.T) {
+	_ = os.Setenv("SERVER_ADDRESS", "127.0.0.1:8092")
+	_ = os.Setenv("ACCESS_TOKEN_SECRET", "access")
+	_ = os.Setenv("REFRESH_TOKEN_SECRET", "refresh")
+
+	cfg := config.GetConfig()
+	cfg.Storage = config.StorageMemory
+	srv, _, _, _ := NewServer(&cfg)
+	require.Len(t, srv.TLSConfig.Certificates, 1, "without TLS_CERT_FILE the server must generate a certificate")
+
+	go func() {
+		_ = srv.ListenAndServeTLS("", "")
+	}()
+	defer srv.Close()
+
+	time.Sleep(200 * time.Millisecond)
+
+	_, err := http.Get("https://" + cfg.ServerAddress + "/health")
+	require.Error(t, err, "self-signed certificate must not be trusted by default")
+
+	cfg.TLSPinnedFingerprints = []string{certs.Fingerprint(srv.TLSConfig.Certificates[0].Leaf)}
+	tlsConfig, err := certs.ClientConfig(&cfg)
+	require.NoError(t, err)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	resp, err := client.Get("https://" + cfg.ServerAddress + "/health")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
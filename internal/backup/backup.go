@@ -0,0 +1,270 @@
+// Package backup записывает и читает зашифрованные резервные копии хранилища.
+//
+// Резервная копия — tar-архив, зашифрованный в формате age паролем (scrypt + ChaCha20-Poly1305).
+// Архив содержит опись manifest.json с размерами и SHA-256 остальных файлов, папки folders.json,
+// секреты secrets.json и бинарные данные секретов в каталоге blobs/. Его можно расшифровать
+// и проверить без GophKeeper: age -d backup.age | tar -x.
+package backup
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"filippo.io/age"
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// Формат и состав архива.
+const (
+	FormatName    = "gophkeeper-backup"
+	FormatVersion = 1
+
+	manifestName = "manifest.json"
+	foldersName  = "folders.json"
+	secretsName  = "secrets.json"
+	blobsDir     = "blobs/"
+)
+
+// maxFileSize — максимальный размер файла внутри архива.
+const maxFileSize = 1 << 30
+
+// scryptWorkFactor — сложность scrypt при шифровании (log2 N), как у утилиты age.
+var scryptWorkFactor = 18
+
+var (
+	// ErrWrongPassphrase возвращается, если пароль не подходит к архиву.
+	ErrWrongPassphrase = errors.New("wrong backup passphrase")
+	// ErrInvalidArchive возвращается, если архив повреждён или не является резервной копией GophKeeper.
+	ErrInvalidArchive = errors.New("invalid backup archive")
+	// ErrChecksumMismatch возвращается, если содержимое архива не совпадает с описью.
+	ErrChecksumMismatch = errors.New("backup checksum mismatch")
+)
+
+// Manifest — опись резервной копии.
+type Manifest struct {
+	Format    string    `json:"format"`     // Всегда FormatName
+	Version   int       `json:"version"`    // Версия формата
+	CreatedAt time.Time `json:"created_at"` // Когда создана копия
+	Folders   int       `json:"folders"`    // Количество папок
+	Secrets   int       `json:"secrets"`    // Количество секретов
+	Files     []File    `json:"files"`      // Файлы архива, кроме описи
+}
+
+// File — файл архива в описи.
+type File struct {
+	Name   string `json:"name"`   // Путь внутри архива
+	Size   int64  `json:"size"`   // Размер в байтах
+	SHA256 string `json:"sha256"` // Контрольная сумма в hex
+}
+
+// secretRecord — секрет в secrets.json; бинарные данные вынесены в файл Blob.
+type secretRecord struct {
+	ID        uint64               `json:"id"`
+	Title     string               `json:"title"`
+	Data      models.SecretDataDTO `json:"data"`
+	Blob      string               `json:"blob,omitempty"`
+	FolderID  *uint64              `json:"folder_id,omitempty"`
+	Tags      []string             `json:"tags,omitempty"`
+	Version   int                  `json:"version"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// Archive — содержимое проверенной резервной копии.
+type Archive struct {
+	Manifest Manifest
+	Folders  []models.ReadFolderDTO
+	Secrets  []models.ReadSecretDTO
+}
+
+// Write шифрует паролем и записывает в w резервную копию папок и секретов.
+// Возвращает опись записанного архива.
+func Write(w io.Writer, folders []models.ReadFolderDTO, secrets []models.ReadSecretDTO, passphrase string) (*Manifest, error) {
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	recipient.SetWorkFactor(scryptWorkFactor)
+
+	type file struct {
+		name string
+		data []byte
+	}
+	var files []file
+
+	records := make([]secretRecord, 0, len(secrets))
+	for _, s := range secrets {
+		rec := secretRecord{
+			ID:        s.ID,
+			Title:     s.Title,
+			Data:      s.Data,
+			FolderID:  s.FolderID,
+			Tags:      s.Tags,
+			Version:   s.Version,
+			CreatedAt: s.CreatedAt,
+			UpdatedAt: s.UpdatedAt,
+		}
+		if s.Data.Binary != nil {
+			rec.Blob = blobsDir + strconv.FormatUint(s.ID, 10)
+			rec.Data.Binary = nil
+			files = append(files, file{name: rec.Blob, data: s.Data.Binary})
+		}
+		records = append(records, rec)
+	}
+
+	if folders == nil {
+		folders = []models.ReadFolderDTO{}
+	}
+	foldersJSON, err := json.MarshalIndent(folders, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	secretsJSON, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files = append([]file{{name: foldersName, data: foldersJSON}, {name: secretsName, data: secretsJSON}}, files...)
+
+	manifest := &Manifest{
+		Format:    FormatName,
+		Version:   FormatVersion,
+		CreatedAt: time.Now().UTC(),
+		Folders:   len(folders),
+		Secrets:   len(secrets),
+	}
+	for _, f := range files {
+		sum := sha256.Sum256(f.data)
+		manifest.Files = append(manifest.Files, File{Name: f.name, Size: int64(len(f.data)), SHA256: hex.EncodeToString(sum[:])})
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	enc, err := age.Encrypt(w, recipient)
+	if err != nil {
+		return nil, err
+	}
+	tw := tar.NewWriter(enc)
+	for _, f := range append([]file{{name: manifestName, data: manifestJSON}}, files...) {
+		hdr := &tar.Header{Name: f.name, Mode: 0600, Size: int64(len(f.data)), ModTime: manifest.CreatedAt}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Read расшифровывает резервную копию, сверяет файлы с описью и возвращает её содержимое.
+func Read(r io.Reader, passphrase string) (*Archive, error) {
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	dec, err := age.Decrypt(r, identity)
+	var noMatch *age.NoIdentityMatchError
+	if errors.As(err, &noMatch) {
+		return nil, ErrWrongPassphrase
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(dec)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		if hdr.Typeflag != tar.TypeReg || hdr.Size > maxFileSize {
+			return nil, fmt.Errorf("%w: unexpected entry %q", ErrInvalidArchive, hdr.Name)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		files[hdr.Name] = data
+	}
+
+	archive := &Archive{}
+	if err := json.Unmarshal(files[manifestName], &archive.Manifest); err != nil || archive.Manifest.Format != FormatName {
+		return nil, fmt.Errorf("%w: no manifest", ErrInvalidArchive)
+	}
+	if archive.Manifest.Version != FormatVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidArchive, archive.Manifest.Version)
+	}
+	if err := verify(archive.Manifest, files); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(files[foldersName], &archive.Folders); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	var records []secretRecord
+	if err := json.Unmarshal(files[secretsName], &records); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	for _, rec := range records {
+		secret := models.ReadSecretDTO{
+			ID:        rec.ID,
+			Title:     rec.Title,
+			Data:      rec.Data,
+			FolderID:  rec.FolderID,
+			Tags:      rec.Tags,
+			Version:   rec.Version,
+			CreatedAt: rec.CreatedAt,
+			UpdatedAt: rec.UpdatedAt,
+		}
+		if rec.Blob != "" {
+			blob, ok := files[rec.Blob]
+			if !ok {
+				return nil, fmt.Errorf("%w: missing %s", ErrInvalidArchive, rec.Blob)
+			}
+			secret.Data.Binary = blob
+		}
+		archive.Secrets = append(archive.Secrets, secret)
+	}
+	if len(archive.Folders) != archive.Manifest.Folders || len(archive.Secrets) != archive.Manifest.Secrets {
+		return nil, fmt.Errorf("%w: counts differ from manifest", ErrChecksumMismatch)
+	}
+	return archive, nil
+}
+
+// verify сверяет файлы архива с описью: каждый файл описи должен быть в архиве с тем же размером
+// и контрольной суммой, а лишних файлов быть не должно.
+func verify(manifest Manifest, files map[string][]byte) error {
+	if len(files) != len(manifest.Files)+1 {
+		return fmt.Errorf("%w: %d files in archive, %d in manifest", ErrChecksumMismatch, len(files)-1, len(manifest.Files))
+	}
+	for _, f := range manifest.Files {
+		data, ok := files[f.Name]
+		if !ok {
+			return fmt.Errorf("%w: missing %s", ErrChecksumMismatch, f.Name)
+		}
+		sum := sha256.Sum256(data)
+		if int64(len(data)) != f.Size || hex.EncodeToString(sum[:]) != f.SHA256 {
+			return fmt.Errorf("%w: %s", ErrChecksumMismatch, f.Name)
+		}
+	}
+	return nil
+}
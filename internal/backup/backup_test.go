@@ -0,0 +1,93 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+func init() {
+	// Минимальная сложность scrypt, чтобы тесты выполнялись быстро
+	scryptWorkFactor = 10
+}
+
+func TestWriteRead(t *testing.T) {
+	folder := uint64(3)
+	text := "заметка"
+	created := time.Date(2025, 7, 16, 10, 0, 0, 0, time.UTC)
+	folders := []models.ReadFolderDTO{{ID: 3, Name: "Работа", CreatedAt: created, UpdatedAt: created}}
+	secrets := []models.ReadSecretDTO{
+		{ID: 1, Title: "Почта", FolderID: &folder, Tags: []string{"mail"}, Version: 2, CreatedAt: created, UpdatedAt: created,
+			Data: models.SecretDataDTO{LoginPassword: &models.LoginPasswordData{Login: "bob", Password: "p@ss"}}},
+		{ID: 2, Title: "Заметка", Version: 1, CreatedAt: created, UpdatedAt: created, Data: models.SecretDataDTO{Text: &text}},
+		{ID: 5, Title: "Ключ", Version: 1, CreatedAt: created, UpdatedAt: created, Data: models.SecretDataDTO{Binary: []byte{0, 1, 2}}},
+	}
+
+	var buf bytes.Buffer
+	manifest, err := Write(&buf, folders, secrets, "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, 1, manifest.Folders)
+	assert.Equal(t, 3, manifest.Secrets)
+	require.Len(t, manifest.Files, 3)
+	assert.Equal(t, "blobs/5", manifest.Files[2].Name)
+
+	archive, err := Read(bytes.NewReader(buf.Bytes()), "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, folders, archive.Folders)
+	assert.Equal(t, secrets, archive.Secrets)
+	assert.Equal(t, manifest.Files, archive.Manifest.Files)
+
+	t.Run("Wrong_passphrase", func(t *testing.T) {
+		_, err := Read(bytes.NewReader(buf.Bytes()), "wrong")
+		assert.ErrorIs(t, err, ErrWrongPassphrase)
+	})
+
+	t.Run("Not_an_archive", func(t *testing.T) {
+		_, err := Read(bytes.NewReader([]byte("plain text")), "correct horse")
+		assert.ErrorIs(t, err, ErrInvalidArchive)
+	})
+}
+
+func TestRead_ChecksumMismatch(t *testing.T) {
+	manifest := `{"format":"gophkeeper-backup","version":1,"folders":0,"secrets":0,"files":[` +
+		`{"name":"folders.json","size":2,"sha256":"4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945"},` +
+		`{"name":"secrets.json","size":2,"sha256":"0000000000000000000000000000000000000000000000000000000000000000"}]}`
+	data := encryptTar(t, map[string]string{"manifest.json": manifest, "folders.json": "[]", "secrets.json": "[]"})
+
+	_, err := Read(bytes.NewReader(data), "pass")
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+}
+
+func TestRead_MissingManifest(t *testing.T) {
+	data := encryptTar(t, map[string]string{"folders.json": "[]"})
+
+	_, err := Read(bytes.NewReader(data), "pass")
+	assert.ErrorIs(t, err, ErrInvalidArchive)
+}
+
+// encryptTar собирает и шифрует паролем "pass" архив из указанных файлов.
+func encryptTar(t *testing.T, files map[string]string) []byte {
+	recipient, err := age.NewScryptRecipient("pass")
+	require.NoError(t, err)
+	recipient.SetWorkFactor(scryptWorkFactor)
+
+	var buf bytes.Buffer
+	enc, err := age.Encrypt(&buf, recipient)
+	require.NoError(t, err)
+	tw := tar.NewWriter(enc)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, enc.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,138 @@
+// Package certs настраивает TLS сервера и клиента: загружает сертификат сервера из файлов и
+// перечитывает их при замене, выпускает самоподписанный сертификат для разработки и проверяет
+// сертификат сервера на клиенте по списку доверенных центров сертификации и по отпечатку SHA-256.
+// Для взаимной аутентификации TLS сервер выступает центром сертификации устройств (DeviceCA),
+// а клиент предъявляет сертификат своего устройства.
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+)
+
+// SelfSignedValidity — срок действия самоподписанного сертификата.
+const SelfSignedValidity = 365 * 24 * time.Hour
+
+// ErrFingerprintMismatch возвращается клиенту, если отпечаток сертификата сервера не входит в список закреплённых.
+var ErrFingerprintMismatch = errors.New("server certificate fingerprint does not match any pinned fingerprint")
+
+// SelfSigned выпускает самоподписанный сертификат сервера на ключе ECDSA P-256 для имён и IP-адресов hosts.
+// Возвращает сертификат и закрытый ключ в PEM.
+func SelfSigned(hosts []string, now time.Time) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"GophKeeper"}, CommonName: "GophKeeper development server"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(SelfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if host != "" {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// Fingerprint возвращает отпечаток сертификата: SHA-256 от его DER-кодировки в шестнадцатеричном виде.
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeFingerprint приводит отпечаток к виду Fingerprint: допускаются заглавные буквы
+// и разделители-двоеточия, как его печатает openssl x509 -fingerprint -sha256.
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(fingerprint), ":", ""))
+}
+
+// ClientConfig возвращает настройки TLS клиента для подключения к серверу.
+//
+// Если задан cfg.TLSCAFile, сертификат сервера проверяется только по центрам сертификации из этого файла,
+// иначе — по системным. Если заданы cfg.TLSPinnedFingerprints, сертификат сервера должен ещё и совпасть
+// с одним из отпечатков. Закреплённый отпечаток без файла центров сертификации заменяет проверку цепочки,
+// что позволяет подключаться к серверу с самоподписанным сертификатом.
+//
+// Если заданы cfg.DeviceCertFile и cfg.DeviceKeyFile, клиент предъявляет серверу сертификат устройства,
+// когда он уже получен при входе.
+func ClientConfig(cfg *config.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.DeviceCertFile != "" && cfg.DeviceKeyFile != "" {
+		tlsConfig.GetClientCertificate = clientCertificate(cfg.DeviceCertFile, cfg.DeviceKeyFile)
+	}
+
+	if cfg.TLSCAFile != "" {
+		bundle, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("CA bundle %s contains no certificates", cfg.TLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	var pins []string
+	for _, pin := range cfg.TLSPinnedFingerprints {
+		if pin = normalizeFingerprint(pin); pin != "" {
+			pins = append(pins, pin)
+		}
+	}
+	if len(pins) == 0 {
+		return tlsConfig, nil
+	}
+
+	tlsConfig.InsecureSkipVerify = cfg.TLSCAFile == ""
+	tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return ErrFingerprintMismatch
+		}
+		if !slices.Contains(pins, Fingerprint(state.PeerCertificates[0])) {
+			return ErrFingerprintMismatch
+		}
+		return nil
+	}
+	return tlsConfig, nil
+}
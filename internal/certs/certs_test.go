@@ -0,0 +1,135 @@
+package certs
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+)
+
+// parseCert разбирает сертификат в PEM.
+func parseCert(t *testing.T, certPEM []byte) *x509.Certificate {
+	t.Helper()
+	block, _ := pem.Decode(certPEM)
+	require.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestSelfSigned(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	certPEM, keyPEM, err := SelfSigned([]string{"localhost", "127.0.0.1", ""}, now)
+	require.NoError(t, err)
+
+	_, err = tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	cert := parseCert(t, certPEM)
+	assert.Equal(t, []string{"localhost"}, cert.DNSNames)
+	require.Len(t, cert.IPAddresses, 1)
+	assert.True(t, cert.IPAddresses[0].Equal(net.ParseIP("127.0.0.1")))
+	assert.Equal(t, now.Add(SelfSignedValidity), cert.NotAfter)
+	assert.NoError(t, cert.VerifyHostname("localhost"))
+	assert.Error(t, cert.VerifyHostname("example.com"))
+}
+
+func TestFingerprint(t *testing.T) {
+	certPEM, _, err := SelfSigned([]string{"localhost"}, time.Now())
+	require.NoError(t, err)
+	cert := parseCert(t, certPEM)
+
+	sum := sha256.Sum256(cert.Raw)
+	fingerprint := Fingerprint(cert)
+	assert.Equal(t, hex.EncodeToString(sum[:]), fingerprint)
+
+	var colons []string
+	for i := 0; i < len(fingerprint); i += 2 {
+		colons = append(colons, strings.ToUpper(fingerprint[i:i+2]))
+	}
+	assert.Equal(t, fingerprint, normalizeFingerprint(" "+strings.Join(colons, ":")+" "))
+}
+
+func TestClientConfig(t *testing.T) {
+	certPEM, keyPEM, err := SelfSigned([]string{"127.0.0.1"}, time.Now())
+	require.NoError(t, err)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	fingerprint := Fingerprint(parseCert(t, certPEM))
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	server.StartTLS()
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, certPEM, 0o600))
+	otherPEM, _, err := SelfSigned([]string{"127.0.0.1"}, time.Now())
+	require.NoError(t, err)
+	otherCAFile := filepath.Join(dir, "other.pem")
+	require.NoError(t, os.WriteFile(otherCAFile, otherPEM, 0o600))
+	otherFingerprint := Fingerprint(parseCert(t, otherPEM))
+
+	testCases := []struct {
+		name    string
+		cfg     config.Config
+		wantErr error
+		fails   bool
+	}{
+		{name: "System roots reject self-signed", cfg: config.Config{}, fails: true},
+		{name: "CA bundle", cfg: config.Config{TLSCAFile: caFile}},
+		{name: "Other CA bundle", cfg: config.Config{TLSCAFile: otherCAFile}, fails: true},
+		{name: "Pinned fingerprint", cfg: config.Config{TLSPinnedFingerprints: []string{otherFingerprint, strings.ToUpper(fingerprint)}}},
+		{name: "Wrong fingerprint", cfg: config.Config{TLSPinnedFingerprints: []string{otherFingerprint}}, wantErr: ErrFingerprintMismatch},
+		{name: "CA bundle and pinned fingerprint", cfg: config.Config{TLSCAFile: caFile, TLSPinnedFingerprints: []string{fingerprint}}},
+		{name: "CA bundle and wrong fingerprint", cfg: config.Config{TLSCAFile: caFile, TLSPinnedFingerprints: []string{otherFingerprint}}, wantErr: ErrFingerprintMismatch},
+		{name: "Pin does not bypass CA bundle", cfg: config.Config{TLSCAFile: otherCAFile, TLSPinnedFingerprints: []string{fingerprint}}, fails: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tlsConfig, err := ClientConfig(&tc.cfg)
+			require.NoError(t, err)
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+
+			resp, err := client.Get(server.URL)
+			if tc.wantErr != nil || tc.fails {
+				require.Error(t, err)
+				if tc.wantErr != nil {
+					assert.ErrorIs(t, err, tc.wantErr)
+				}
+				return
+			}
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		})
+	}
+}
+
+func TestClientConfig_InvalidCAFile(t *testing.T) {
+	dir := t.TempDir()
+	_, err := ClientConfig(&config.Config{TLSCAFile: filepath.Join(dir, "missing.pem")})
+	assert.Error(t, err)
+
+	empty := filepath.Join(dir, "empty.pem")
+	require.NoError(t, os.WriteFile(empty, []byte("not a certificate"), 0o600))
+	_, err = ClientConfig(&config.Config{TLSCAFile: empty})
+	assert.Error(t, err)
+}
@@ -0,0 +1,316 @@
+package certs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// DeviceCAValidity — срок действия центра сертификации устройств, который создаёт сервер.
+const DeviceCAValidity = 10 * 365 * 24 * time.Hour
+
+// CRLValidity — через сколько клиентам списка отзыва сертификатов устройств следует запросить его заново.
+const CRLValidity = time.Hour
+
+// ErrInvalidCSR возвращается, если запрос на сертификат устройства не разбирается или его подпись неверна.
+var ErrInvalidCSR = errors.New("invalid certificate signing request")
+
+// DeviceCA — центр сертификации, которым сервер подписывает сертификаты устройств
+// и список их отзыва (CRL).
+type DeviceCA struct {
+	cert     *x509.Certificate // Сертификат центра сертификации
+	key      crypto.Signer     // Закрытый ключ центра сертификации
+	validity time.Duration     // Срок действия выпускаемых сертификатов
+}
+
+// LoadDeviceCA загружает центр сертификации устройств из cfg.DeviceCACertFile и cfg.DeviceCAKeyFile.
+// Если ни одного из файлов ещё нет, центр сертификации создаётся и сохраняется по этим путям.
+// Если пути не заданы, он создаётся в памяти: выданные им сертификаты теряют силу при перезапуске сервера.
+func LoadDeviceCA(cfg *config.Config) (*DeviceCA, error) {
+	log := logger.NewLogger()
+
+	if cfg.DeviceCACertFile == "" && cfg.DeviceCAKeyFile == "" {
+		certPEM, keyPEM, err := newDeviceCA(time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("generate device CA: %w", err)
+		}
+		log.Log.Warn("Центр сертификации устройств не задан, используется временный: сертификаты устройств потеряют силу при перезапуске")
+		return NewDeviceCA(certPEM, keyPEM, cfg.DeviceCertValidity)
+	}
+	if cfg.DeviceCACertFile == "" || cfg.DeviceCAKeyFile == "" {
+		return nil, errors.New("both DEVICE_CA_CERT_FILE and DEVICE_CA_KEY_FILE must be set")
+	}
+
+	created, err := ensureKeyPair(cfg.DeviceCACertFile, cfg.DeviceCAKeyFile, func() ([]byte, []byte, error) {
+		certPEM, keyPEM, err := newDeviceCA(time.Now())
+		if err != nil {
+			return nil, nil, fmt.Errorf("generate device CA: %w", err)
+		}
+		return certPEM, keyPEM, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	certPEM, err := os.ReadFile(cfg.DeviceCACertFile)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := os.ReadFile(cfg.DeviceCAKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := NewDeviceCA(certPEM, keyPEM, cfg.DeviceCertValidity)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		log.Log.Warn("Создан центр сертификации устройств", zap.String("cert_file", cfg.DeviceCACertFile), zap.String("fingerprint", Fingerprint(ca.cert)))
+	}
+	return ca, nil
+}
+
+// NewDeviceCA создаёт центр сертификации устройств из сертификата и ключа в PEM.
+// Сертификаты устройств выпускаются на срок validity.
+func NewDeviceCA(certPEM, keyPEM []byte, validity time.Duration) (*DeviceCA, error) {
+	pair, err := parseKeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	if !pair.Leaf.IsCA {
+		return nil, errors.New("device CA certificate is not a CA")
+	}
+	key, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("device CA key cannot sign certificates")
+	}
+	return &DeviceCA{cert: pair.Leaf, key: key, validity: validity}, nil
+}
+
+// newDeviceCA выпускает самоподписанный сертификат центра сертификации устройств на ключе ECDSA P-256.
+func newDeviceCA(now time.Time) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"GophKeeper"}, CommonName: "GophKeeper device CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(DeviceCAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// Certificate возвращает сертификат центра сертификации.
+func (ca *DeviceCA) Certificate() *x509.Certificate {
+	return ca.cert
+}
+
+// Pool возвращает набор из сертификата центра сертификации для проверки сертификатов устройств
+// (tls.Config.ClientCAs).
+func (ca *DeviceCA) Pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+// Issue подписывает запрос на сертификат csrPEM (PKCS #10 в PEM) и выпускает сертификат клиента
+// пользователя userName для устройства device. Ключ устройства не покидает его: в запросе только открытый ключ.
+// Возвращает разобранный сертификат и его PEM. Если запрос не разбирается или подпись неверна,
+// возвращает ErrInvalidCSR.
+func (ca *DeviceCA) Issue(csrPEM, userName, device string, now time.Time) (*x509.Certificate, []byte, error) {
+	block, _ := pem.Decode([]byte(csrPEM))
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, nil, ErrInvalidCSR
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidCSR, err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidCSR, err)
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	subject := pkix.Name{Organization: []string{"GophKeeper"}, CommonName: userName}
+	if device != "" {
+		subject.OrganizationalUnit = []string{device}
+	}
+	notAfter := now.Add(ca.validity)
+	if notAfter.After(ca.cert.NotAfter) {
+		notAfter = ca.cert.NotAfter
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      subject,
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, csr.PublicKey, ca.key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidCSR, err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
+
+// RevocationList выпускает список отзыва (CRL) в PEM из отозванных сертификатов устройств.
+// Номер списка — время выпуска в секундах, поэтому более новый список всегда имеет больший номер.
+func (ca *DeviceCA) RevocationList(revoked []models.DeviceCertificateDTO, now time.Time) ([]byte, error) {
+	template := &x509.RevocationList{
+		Number:     big.NewInt(now.Unix()),
+		ThisUpdate: now,
+		NextUpdate: now.Add(CRLValidity),
+	}
+	for _, cert := range revoked {
+		serial, ok := new(big.Int).SetString(cert.Serial, 16)
+		if !ok || cert.RevokedAt == nil {
+			continue
+		}
+		template.RevokedCertificateEntries = append(template.RevokedCertificateEntries, x509.RevocationListEntry{
+			SerialNumber:   serial,
+			RevocationTime: *cert.RevokedAt,
+		})
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, template, ca.cert, ca.key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), nil
+}
+
+// Serial возвращает серийный номер сертификата в шестнадцатеричном виде, как он хранится в базе.
+func Serial(cert *x509.Certificate) string {
+	return hex.EncodeToString(cert.SerialNumber.Bytes())
+}
+
+// Thumbprint возвращает отпечаток сертификата для привязки к нему токенов: SHA-256 от DER-кодировки
+// в base64url без выравнивания, как поле x5t#S256 из RFC 8705.
+func Thumbprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// randomSerial возвращает случайный 128-битный серийный номер сертификата.
+func randomSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// DeviceKey загружает закрытый ключ устройства из keyFile, а если файла нет — создаёт ключ ECDSA P-256
+// и сохраняет его с доступом только для владельца.
+func DeviceKey(keyFile string) (crypto.Signer, error) {
+	keyPEM, err := os.ReadFile(keyFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(keyFile), 0o700); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("device key %s is not PEM", keyFile)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse device key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("device key %s cannot sign", keyFile)
+	}
+	return signer, nil
+}
+
+// CertificateRequest создаёт запрос на сертификат (PKCS #10 в PEM) для ключа устройства key.
+func CertificateRequest(key crypto.Signer, commonName string) ([]byte, error) {
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: commonName},
+	}, key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}), nil
+}
+
+// clientCertificate возвращает функцию для tls.Config.GetClientCertificate, которая при каждом подключении
+// читает сертификат устройства из certFile и ключ из keyFile. Если сертификата ещё нет, он не подходит к ключу
+// или истёк, подключение выполняется без сертификата: так устройство может войти заново и получить новый.
+func clientCertificate(certFile, keyFile string) func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		certPEM, err := os.ReadFile(certFile)
+		if err != nil {
+			return &tls.Certificate{}, nil
+		}
+		keyPEM, err := os.ReadFile(keyFile)
+		if err != nil {
+			return &tls.Certificate{}, nil
+		}
+		cert, err := parseKeyPair(certPEM, keyPEM)
+		if err != nil || time.Now().After(cert.Leaf.NotAfter) {
+			return &tls.Certificate{}, nil
+		}
+		return cert, nil
+	}
+}
@@ -0,0 +1,172 @@
+package certs
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// newTestDeviceCA создаёт центр сертификации устройств в памяти.
+func newTestDeviceCA(t *testing.T) *DeviceCA {
+	t.Helper()
+	ca, err := LoadDeviceCA(&config.Config{DeviceCertValidity: 24 * time.Hour})
+	require.NoError(t, err)
+	return ca
+}
+
+// issueDevice создаёт ключ устройства в keyFile и выпускает для него сертификат.
+func issueDevice(t *testing.T, ca *DeviceCA, keyFile string) (*x509.Certificate, []byte) {
+	t.Helper()
+	key, err := DeviceKey(keyFile)
+	require.NoError(t, err)
+	csr, err := CertificateRequest(key, "laptop")
+	require.NoError(t, err)
+	cert, certPEM, err := ca.Issue(string(csr), "john", "laptop", time.Now())
+	require.NoError(t, err)
+	return cert, certPEM
+}
+
+func TestLoadDeviceCA(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		DeviceCACertFile:   filepath.Join(dir, "ca", "devices.crt"),
+		DeviceCAKeyFile:    filepath.Join(dir, "ca", "devices.key"),
+		DeviceCertValidity: time.Hour,
+	}
+
+	first, err := LoadDeviceCA(cfg)
+	require.NoError(t, err)
+	assert.True(t, first.Certificate().IsCA)
+	info, err := os.Stat(cfg.DeviceCAKeyFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	second, err := LoadDeviceCA(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, Fingerprint(first.Certificate()), Fingerprint(second.Certificate()), "existing CA must be reused")
+
+	_, err = LoadDeviceCA(&config.Config{DeviceCACertFile: cfg.DeviceCACertFile})
+	assert.Error(t, err)
+
+	certPEM, keyPEM, err := SelfSigned([]string{"localhost"}, time.Now())
+	require.NoError(t, err)
+	_, err = NewDeviceCA(certPEM, keyPEM, time.Hour)
+	assert.Error(t, err, "a server certificate must not sign device certificates")
+}
+
+func TestDeviceCA_Issue(t *testing.T) {
+	ca := newTestDeviceCA(t)
+	cert, certPEM := issueDevice(t, ca, filepath.Join(t.TempDir(), "device.key"))
+
+	assert.Equal(t, Serial(cert), Serial(parseCert(t, certPEM)))
+	assert.Equal(t, "john", cert.Subject.CommonName)
+	assert.Equal(t, []string{"laptop"}, cert.Subject.OrganizationalUnit)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), cert.NotAfter, time.Minute)
+	_, err := cert.Verify(x509.VerifyOptions{Roots: ca.Pool(), KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	assert.NoError(t, err)
+	_, err = cert.Verify(x509.VerifyOptions{Roots: ca.Pool(), KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}})
+	assert.Error(t, err, "device certificates must not authenticate servers")
+
+	_, _, err = ca.Issue("garbage", "john", "laptop", time.Now())
+	assert.ErrorIs(t, err, ErrInvalidCSR)
+	_, _, err = ca.Issue(string(certPEM), "john", "laptop", time.Now())
+	assert.ErrorIs(t, err, ErrInvalidCSR)
+}
+
+func TestDeviceCA_RevocationList(t *testing.T) {
+	ca := newTestDeviceCA(t)
+	cert, _ := issueDevice(t, ca, filepath.Join(t.TempDir(), "device.key"))
+	revokedAt := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+
+	crlPEM, err := ca.RevocationList([]models.DeviceCertificateDTO{{Serial: Serial(cert), RevokedAt: &revokedAt}}, time.Now())
+	require.NoError(t, err)
+	block, _ := pem.Decode(crlPEM)
+	require.NotNil(t, block)
+	assert.Equal(t, "X509 CRL", block.Type)
+	crl, err := x509.ParseRevocationList(block.Bytes)
+	require.NoError(t, err)
+	require.NoError(t, crl.CheckSignatureFrom(ca.Certificate()))
+	require.Len(t, crl.RevokedCertificateEntries, 1)
+	assert.Equal(t, 0, cert.SerialNumber.Cmp(crl.RevokedCertificateEntries[0].SerialNumber))
+	assert.True(t, revokedAt.Equal(crl.RevokedCertificateEntries[0].RevocationTime))
+	assert.Positive(t, crl.Number.Cmp(big.NewInt(0)))
+}
+
+func TestDeviceKey(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "keys", "device.key")
+	first, err := DeviceKey(keyFile)
+	require.NoError(t, err)
+	info, err := os.Stat(keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	second, err := DeviceKey(keyFile)
+	require.NoError(t, err)
+	assert.True(t, first.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(second.Public()))
+
+	require.NoError(t, os.WriteFile(keyFile, []byte("garbage"), 0o600))
+	_, err = DeviceKey(keyFile)
+	assert.Error(t, err)
+}
+
+func TestClientConfig_DeviceCertificate(t *testing.T) {
+	ca := newTestDeviceCA(t)
+	certPEM, keyPEM, err := SelfSigned([]string{"127.0.0.1"}, time.Now())
+	require.NoError(t, err)
+	serverCert, err := parseKeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			_, _ = w.Write([]byte("anonymous"))
+			return
+		}
+		_, _ = w.Write([]byte(Thumbprint(r.TLS.PeerCertificates[0])))
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{*serverCert},
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		ClientCAs:    ca.Pool(),
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	dir := t.TempDir()
+	cfg := &config.Config{
+		TLSPinnedFingerprints: []string{Fingerprint(serverCert.Leaf)},
+		DeviceCertFile:        filepath.Join(dir, "device.crt"),
+		DeviceKeyFile:         filepath.Join(dir, "device.key"),
+	}
+	get := func() string {
+		tlsConfig, err := ClientConfig(cfg)
+		require.NoError(t, err)
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	assert.Equal(t, "anonymous", get(), "a device without a certificate connects anonymously")
+
+	cert, issued := issueDevice(t, ca, cfg.DeviceKeyFile)
+	require.NoError(t, os.WriteFile(cfg.DeviceCertFile, issued, 0o644))
+	assert.Equal(t, Thumbprint(cert), get())
+}
@@ -0,0 +1,231 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+)
+
+// ServerConfig возвращает настройки TLS сервера и функцию, которая до отмены контекста
+// перечитывает сертификат при замене файлов (см. Reloader.Run).
+//
+// Сертификат и ключ берутся из cfg.TLSCertFile и cfg.TLSKeyFile; если файлов нет, возвращается ошибка,
+// чтобы опечатка в пути не превратила рабочий сервер в сервер с сертификатом для разработки.
+// С cfg.TLSSelfSigned сервер вместо этого выпускает самоподписанный сертификат для cfg.TLSHosts и сохраняет
+// его по этим путям, чтобы клиенты могли довериться ему (TLS_CA_FILE) или закрепить его отпечаток.
+// Если пути не заданы, самоподписанный сертификат живёт только в памяти до остановки сервера.
+func ServerConfig(cfg *config.Config) (*tls.Config, func(ctx context.Context), error) {
+	log := logger.NewLogger()
+
+	if cfg.TLSCertFile == "" && cfg.TLSKeyFile == "" {
+		certPEM, keyPEM, err := SelfSigned(cfg.TLSHosts, time.Now())
+		if err != nil {
+			return nil, nil, fmt.Errorf("generate self-signed certificate: %w", err)
+		}
+		cert, err := parseKeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Log.Warn("Сертификат TLS не задан, используется временный самоподписанный сертификат",
+			zap.Strings("hosts", cfg.TLSHosts), zap.String("fingerprint", Fingerprint(cert.Leaf)))
+		return &tls.Config{MinVersion: tls.VersionTLS12, Certificates: []tls.Certificate{*cert}}, func(context.Context) {}, nil
+	}
+	if cfg.TLSCertFile == "" || cfg.TLSKeyFile == "" {
+		return nil, nil, errors.New("both TLS_CERT_FILE and TLS_KEY_FILE must be set")
+	}
+
+	created := false
+	if cfg.TLSSelfSigned {
+		var err error
+		if created, err = ensureSelfSigned(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSHosts); err != nil {
+			return nil, nil, err
+		}
+	}
+	reloader, err := NewReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSReloadInterval)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, fmt.Errorf("TLS certificate not found (TLS_CERT_FILE=%q, TLS_KEY_FILE=%q); set TLS_SELF_SIGNED=true to generate a development certificate: %w",
+			cfg.TLSCertFile, cfg.TLSKeyFile, err)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if created {
+		log.Log.Warn("Выпущен самоподписанный сертификат TLS для разработки",
+			zap.String("cert_file", cfg.TLSCertFile), zap.Strings("hosts", cfg.TLSHosts), zap.String("fingerprint", reloader.Fingerprint()))
+	}
+	return &tls.Config{MinVersion: tls.VersionTLS12, GetCertificate: reloader.GetCertificate}, reloader.Run, nil
+}
+
+// ensureSelfSigned выпускает самоподписанный сертификат и сохраняет его в certFile и keyFile,
+// если ни одного из файлов ещё нет. Сообщает, был ли сертификат выпущен.
+func ensureSelfSigned(certFile, keyFile string, hosts []string) (bool, error) {
+	return ensureKeyPair(certFile, keyFile, func() ([]byte, []byte, error) {
+		certPEM, keyPEM, err := SelfSigned(hosts, time.Now())
+		if err != nil {
+			return nil, nil, fmt.Errorf("generate self-signed certificate: %w", err)
+		}
+		return certPEM, keyPEM, nil
+	})
+}
+
+// ensureKeyPair сохраняет в certFile и keyFile сертификат и ключ от generate, если ни одного из файлов ещё нет.
+// Ключ доступен только владельцу. Сообщает, были ли файлы созданы.
+func ensureKeyPair(certFile, keyFile string, generate func() (certPEM, keyPEM []byte, err error)) (bool, error) {
+	_, certErr := os.Stat(certFile)
+	_, keyErr := os.Stat(keyFile)
+	if !errors.Is(certErr, fs.ErrNotExist) || !errors.Is(keyErr, fs.ErrNotExist) {
+		return false, nil
+	}
+
+	certPEM, keyPEM, err := generate()
+	if err != nil {
+		return false, err
+	}
+	for _, dir := range []string{filepath.Dir(certFile), filepath.Dir(keyFile)} {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return false, err
+		}
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(certFile, certPEM, 0o644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Reloader отдаёт серверу TLS сертификат из файлов и перечитывает их, когда они меняются,
+// поэтому обновлённый сертификат начинает работать без перезапуска сервера.
+type Reloader struct {
+	certFile string                          // Файл сертификата (PEM, может содержать цепочку)
+	keyFile  string                          // Файл закрытого ключа (PEM)
+	interval time.Duration                   // Как часто проверять файлы на изменения
+	cert     atomic.Pointer[tls.Certificate] // Текущий сертификат
+	mu       sync.Mutex                      // Защищает modTime при одновременных вызовах Reload
+	modTime  time.Time                       // Время изменения файлов, из которых загружен текущий сертификат
+	logger   *logger.Logger                  // Логгер
+}
+
+// NewReloader загружает сертификат из certFile и keyFile.
+// Файлы проверяются на изменения с интервалом interval (0 — только при запуске).
+func NewReloader(certFile, keyFile string, interval time.Duration) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+		logger:   logger.NewLogger(),
+	}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate возвращает текущий сертификат; подходит для tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// Fingerprint возвращает отпечаток текущего сертификата.
+func (r *Reloader) Fingerprint() string {
+	return Fingerprint(r.cert.Load().Leaf)
+}
+
+// Reload перечитывает сертификат, если файлы изменились с прошлой загрузки, и сообщает, был ли он заменён.
+// Если новые файлы не читаются или ключ не подходит к сертификату, остаётся прежний сертификат.
+func (r *Reloader) Reload() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modTime, err := r.lastModified()
+	if err != nil {
+		return false, err
+	}
+	if r.cert.Load() != nil && modTime.Equal(r.modTime) {
+		return false, nil
+	}
+
+	certPEM, err := os.ReadFile(r.certFile)
+	if err != nil {
+		return false, err
+	}
+	keyPEM, err := os.ReadFile(r.keyFile)
+	if err != nil {
+		return false, err
+	}
+	cert, err := parseKeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, err
+	}
+	r.cert.Store(cert)
+	r.modTime = modTime
+	return true, nil
+}
+
+// Run проверяет файлы сертификата с интервалом r.interval, пока не будет отменён контекст.
+// Если интервал не задан, сразу завершается.
+func (r *Reloader) Run(ctx context.Context) {
+	if r.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		reloaded, err := r.Reload()
+		if err != nil {
+			r.logger.Log.Error("Не удалось перечитать сертификат TLS, используется прежний", zap.String("cert_file", r.certFile), zap.Error(err))
+			continue
+		}
+		if reloaded {
+			r.logger.Log.Info("Сертификат TLS обновлён", zap.String("cert_file", r.certFile), zap.String("fingerprint", r.Fingerprint()))
+		}
+	}
+}
+
+// lastModified возвращает время последнего изменения файлов сертификата и ключа.
+func (r *Reloader) lastModified() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// parseKeyPair разбирает сертификат и ключ в PEM и заполняет Leaf разобранным сертификатом сервера.
+func parseKeyPair(certPEM, keyPEM []byte) (*tls.Certificate, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("load TLS key pair: %w", err)
+	}
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return nil, fmt.Errorf("parse TLS certificate: %w", err)
+		}
+	}
+	return &cert, nil
+}
@@ -0,0 +1,114 @@
+package certs
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+)
+
+// writeSelfSigned записывает новый самоподписанный сертификат в certFile и keyFile
+// и сдвигает время их изменения на shift, чтобы Reloader заметил замену.
+func writeSelfSigned(t *testing.T, certFile, keyFile string, shift time.Duration) string {
+	t.Helper()
+	certPEM, keyPEM, err := SelfSigned([]string{"localhost"}, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+	modTime := time.Now().Add(shift)
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+	return Fingerprint(parseCert(t, certPEM))
+}
+
+func TestServerConfig_Ephemeral(t *testing.T) {
+	tlsConfig, run, err := ServerConfig(&config.Config{TLSHosts: []string{"localhost"}})
+	require.NoError(t, err)
+	require.Len(t, tlsConfig.Certificates, 1)
+	assert.Equal(t, []string{"localhost"}, tlsConfig.Certificates[0].Leaf.DNSNames)
+	run(context.Background())
+}
+
+func TestServerConfig_GeneratesFiles(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		TLSCertFile:   filepath.Join(dir, "tls", "server.crt"),
+		TLSKeyFile:    filepath.Join(dir, "tls", "server.key"),
+		TLSHosts:      []string{"localhost"},
+		TLSSelfSigned: true,
+	}
+
+	tlsConfig, _, err := ServerConfig(cfg)
+	require.NoError(t, err)
+	first, err := tlsConfig.GetCertificate(nil)
+	require.NoError(t, err)
+
+	info, err := os.Stat(cfg.TLSKeyFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	certPEM, err := os.ReadFile(cfg.TLSCertFile)
+	require.NoError(t, err)
+	assert.Equal(t, Fingerprint(first.Leaf), Fingerprint(parseCert(t, certPEM)))
+
+	tlsConfig, _, err = ServerConfig(cfg)
+	require.NoError(t, err)
+	second, err := tlsConfig.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, Fingerprint(first.Leaf), Fingerprint(second.Leaf), "existing files must be reused")
+}
+
+func TestServerConfig_Errors(t *testing.T) {
+	dir := t.TempDir()
+	_, _, err := ServerConfig(&config.Config{TLSCertFile: filepath.Join(dir, "server.crt")})
+	assert.Error(t, err)
+
+	_, _, err = ServerConfig(&config.Config{TLSCertFile: filepath.Join(dir, "server.crt"), TLSKeyFile: filepath.Join(dir, "server.key")})
+	assert.ErrorIs(t, err, fs.ErrNotExist, "missing files are an error without TLS_SELF_SIGNED")
+	assert.ErrorContains(t, err, "TLS_SELF_SIGNED")
+	assert.NoFileExists(t, filepath.Join(dir, "server.crt"))
+
+	certFile := filepath.Join(dir, "server.crt")
+	require.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0o600))
+	_, _, err = ServerConfig(&config.Config{TLSCertFile: certFile, TLSKeyFile: filepath.Join(dir, "server.key"), TLSSelfSigned: true})
+	assert.Error(t, err, "a half-present pair must not be overwritten")
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "server.crt")
+	keyFile := filepath.Join(dir, "server.key")
+	first := writeSelfSigned(t, certFile, keyFile, -time.Hour)
+
+	r, err := NewReloader(certFile, keyFile, 10*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, first, r.Fingerprint())
+
+	reloaded, err := r.Reload()
+	require.NoError(t, err)
+	assert.False(t, reloaded, "unchanged files must not be reloaded")
+
+	second := writeSelfSigned(t, certFile, keyFile, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return r.Fingerprint() == second }, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	require.NoError(t, os.WriteFile(keyFile, []byte("broken"), 0o600))
+	_, err = r.Reload()
+	assert.Error(t, err)
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, second, Fingerprint(cert.Leaf), "a broken replacement must keep the previous certificate")
+}
@@ -1,22 +1,55 @@
 package client
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/shekshuev/gophkeeper/internal/certs"
 	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
 )
 
 // Api возвращает сконфигурированный HTTP-клиент resty.Client с базовым URL и заголовком авторизации.
 //
 // Клиент автоматически:
-//   - Устанавливает базовый адрес сервера из конфигурации (`cfg.ServerAddress`).
+//   - Устанавливает базовый адрес сервера из конфигурации (`https://` + `cfg.ServerAddress`).
+//   - Проверяет сертификат сервера по `TLS_CA_FILE` и закреплённым отпечаткам `TLS_PINNED_FINGERPRINTS` (см. certs.ClientConfig).
+//     Если настройки TLS некорректны, запросы не отправляются и завершаются ошибкой.
 //   - Добавляет заголовок `Authorization: Bearer <токен>`, если токен ранее сохранён.
+//   - Передаёт идентификатор устройства в заголовке `X-Device-ID` (см. DeviceID).
+//   - Начинает клиентский спан на каждый запрос и передаёт его в заголовке `traceparent` (W3C Trace Context).
 //
 // Используется везде, где требуется выполнять HTTP-запросы к API сервера.
 func Api() *resty.Client {
 	cfg := config.GetConfig()
 
-	rc := resty.New().
-		SetBaseURL("http://" + cfg.ServerAddress)
+	rc := resty.New()
+	tlsConfig, err := certs.ClientConfig(&cfg)
+	if err != nil {
+		// Без проверки сертификата по TLS_CA_FILE и закреплённым отпечаткам соединяться нельзя:
+		// каждый запрос завершается ошибкой настройки TLS.
+		rc.OnBeforeRequest(func(*resty.Client, *resty.Request) error {
+			return fmt.Errorf("TLS configuration: %w", err)
+		})
+	} else {
+		rc.SetTLSClientConfig(tlsConfig)
+	}
+	rc.SetBaseURL("https://"+cfg.ServerAddress).
+		SetHeader("X-Device-ID", DeviceID()).
+		OnBeforeRequest(startRequestSpan).
+		OnAfterResponse(endRequestSpan).
+		OnError(func(req *resty.Request, err error) {
+			span := trace.SpanFromContext(req.Context())
+			span.SetStatus(codes.Error, err.Error())
+			span.End()
+		})
 
 	token, _ := LoadToken()
 	if token != "" {
@@ -25,3 +58,24 @@ func Api() *resty.Client {
 
 	return rc
 }
+
+// startRequestSpan начинает спан запроса к серверу и добавляет контекст трассировки в заголовки.
+// Параметры запроса в спан не попадают: в них может быть поисковая строка пользователя.
+func startRequestSpan(_ *resty.Client, req *resty.Request) error {
+	path, _, _ := strings.Cut(req.URL, "?")
+	ctx, _ := tracing.Start(req.Context(), req.Method+" "+path, trace.WithSpanKind(trace.SpanKindClient))
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	req.SetContext(ctx)
+	return nil
+}
+
+// endRequestSpan завершает спан запроса, записав в него код ответа сервера.
+func endRequestSpan(_ *resty.Client, resp *resty.Response) error {
+	span := trace.SpanFromContext(resp.Request.Context())
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode()))
+	if resp.StatusCode() >= 500 {
+		span.SetStatus(codes.Error, resp.Status())
+	}
+	span.End()
+	return nil
+}
@@ -13,7 +13,16 @@ func TestAPI_BaseURL(t *testing.T) {
 
 	client := Api()
 
-	assert.Equal(t, "http://localhost:9999", client.BaseURL)
+	assert.Equal(t, "https://localhost:9999", client.BaseURL)
+}
+
+func TestAPI_InvalidTLSConfig(t *testing.T) {
+	t.Setenv("SERVER_ADDRESS", "localhost:9999")
+	t.Setenv("TLS_CA_FILE", filepath.Join(t.TempDir(), "missing.crt"))
+
+	_, err := Api().R().Get("/health")
+
+	assert.ErrorContains(t, err, "read CA bundle", "a broken TLS setup must not fall back to system roots")
 }
 
 func TestAPI_WithToken(t *testing.T) {
@@ -29,3 +38,25 @@ func TestAPI_WithToken(t *testing.T) {
 	authHeader := client.Header.Get("Authorization")
 	assert.Equal(t, "Bearer test-token-123", authHeader)
 }
+
+func TestAPI_DeviceID(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	deviceFile := filepath.Join(home, ".gophkeeper", "device")
+	_ = os.MkdirAll(filepath.Dir(deviceFile), 0700)
+	original, readErr := os.ReadFile(deviceFile)
+	defer func() {
+		if readErr == nil {
+			_ = os.WriteFile(deviceFile, original, 0600)
+		} else {
+			_ = os.Remove(deviceFile)
+		}
+	}()
+
+	_ = os.Remove(deviceFile)
+	id := DeviceID()
+	assert.NotEmpty(t, id)
+	assert.Equal(t, id, DeviceID(), "ID must be stable between calls")
+
+	client := Api()
+	assert.Equal(t, id, client.Header.Get("X-Device-ID"))
+}
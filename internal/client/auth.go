@@ -1,22 +1,20 @@
 package client
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
-	"strings"
+	"path/filepath"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/shekshuev/gophkeeper/internal/certs"
+	"github.com/shekshuev/gophkeeper/internal/config"
 	"github.com/shekshuev/gophkeeper/internal/models"
 )
 
 // prompt отображает текстовый вопрос пользователю и считывает строку с консоли.
 func prompt(label string) string {
-	fmt.Print(label)
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-	return strings.TrimSpace(scanner.Text())
+	return Prompt(label)
 }
 
 // Register — CLI-обёртка для регистрации нового пользователя.
@@ -63,6 +61,8 @@ func Register(rc *resty.Client) {
 //   - пароль
 //
 // Отправляет POST-запрос к API /v1.0/auth/login через переданный HTTP-клиент.
+// Если задан DEVICE_KEY_FILE, вместе с паролем отправляется запрос на сертификат ключа устройства,
+// а выданный сервером сертификат сохраняется в DEVICE_CERT_FILE: следующие соединения предъявляют его серверу.
 //
 // Аргументы:
 //   - rc: настроенный HTTP-клиент (resty.Client)
@@ -71,10 +71,19 @@ func Register(rc *resty.Client) {
 // В случае ошибки (сетевой, HTTP или разбора JSON) выводит сообщение об ошибке.
 // При успешной авторизации вызывает saveTokenFunc и выводит "Вход выполнен."
 func Login(rc *resty.Client, saveTokenFunc func(string) error) {
+	cfg := config.GetConfig()
 	user := models.LoginUserDTO{
 		UserName: prompt("Username: "),
 		Password: prompt("Password: "),
 	}
+	if cfg.DeviceKeyFile != "" {
+		csr, err := deviceCSR(cfg.DeviceKeyFile)
+		if err != nil {
+			fmt.Println("Не удалось подготовить запрос на сертификат устройства:", err)
+			return
+		}
+		user.CSR = csr
+	}
 
 	var tokens models.ReadTokenDTO
 
@@ -95,6 +104,13 @@ func Login(rc *resty.Client, saveTokenFunc func(string) error) {
 		return
 	}
 
+	if tokens.ClientCertificate != "" {
+		if err := saveDeviceCertificate(cfg.DeviceCertFile, tokens.ClientCertificate); err != nil {
+			fmt.Println("Не удалось сохранить сертификат устройства:", err)
+			return
+		}
+	}
+
 	if err := saveTokenFunc(tokens.AccessToken); err != nil {
 		fmt.Println("Не удалось сохранить токен:", err)
 		return
@@ -102,3 +118,27 @@ func Login(rc *resty.Client, saveTokenFunc func(string) error) {
 
 	fmt.Println("Вход выполнен.")
 }
+
+// deviceCSR возвращает запрос на сертификат для ключа устройства из keyFile, создавая ключ при первом входе.
+func deviceCSR(keyFile string) (string, error) {
+	key, err := certs.DeviceKey(keyFile)
+	if err != nil {
+		return "", err
+	}
+	csr, err := certs.CertificateRequest(key, DeviceID())
+	if err != nil {
+		return "", err
+	}
+	return string(csr), nil
+}
+
+// saveDeviceCertificate сохраняет сертификат устройства, выданный при входе, в certFile.
+func saveDeviceCertificate(certFile, certPEM string) error {
+	if certFile == "" {
+		return fmt.Errorf("DEVICE_CERT_FILE is not set")
+	}
+	if err := os.MkdirAll(filepath.Dir(certFile), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(certFile, []byte(certPEM), 0600)
+}
@@ -3,7 +3,11 @@ package client
 import (
 	"bytes"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/go-resty/resty/v2"
@@ -179,3 +183,39 @@ func TestLogin_RequestError(t *testing.T) {
 
 	assert.Contains(t, out.String(), "error:")
 }
+
+func TestLogin_DeviceCertificate(t *testing.T) {
+	defer MockInput("testuser", "secret")()
+	dir := t.TempDir()
+	t.Setenv("DEVICE_KEY_FILE", filepath.Join(dir, "device.key"))
+	t.Setenv("DEVICE_CERT_FILE", filepath.Join(dir, "device.crt"))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var dto models.LoginUserDTO
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&dto))
+		assert.True(t, strings.HasPrefix(dto.CSR, "-----BEGIN CERTIFICATE REQUEST-----"))
+		_ = json.NewEncoder(w).Encode(models.ReadTokenDTO{AccessToken: "bound-token", ClientCertificate: "device-cert"})
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	stdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	Login(resty.New().SetBaseURL(server.URL), func(token string) error {
+		assert.Equal(t, "bound-token", token)
+		return nil
+	})
+
+	w.Close()
+	if _, err := out.ReadFrom(r); err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	os.Stdout = stdout
+
+	assert.Contains(t, out.String(), "Вход выполнен.")
+	cert, err := os.ReadFile(filepath.Join(dir, "device.crt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "device-cert", string(cert))
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/shekshuev/gophkeeper/internal/backup"
+	"github.com/shekshuev/gophkeeper/internal/importer"
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// minBackupPassphraseLen — минимальная длина пароля резервной копии.
+const minBackupPassphraseLen = 8
+
+// ExportBackup — CLI-обёртка для создания зашифрованной резервной копии всего хранилища.
+//
+// Загружает все папки и секреты с данными, запрашивает пароль копии (дважды) и записывает
+// архив в path с правами 0600. Выводит количество секретов и папок и SHA-256 архива,
+// по которому копию можно сверить после переноса. Существующий файл перезаписывается только после подтверждения.
+func ExportBackup(path string, rc *resty.Client) {
+
+	if _, err := os.Stat(path); err == nil && !Confirm("Файл "+path+" уже существует. Перезаписать?") {
+		fmt.Println("Экспорт отменён.")
+		return
+	}
+
+	vault, err := fetchVault(rc)
+	if err != nil {
+		fmt.Println("Ошибка запроса:", err)
+		return
+	}
+
+	passphrase := promptInput("Пароль резервной копии: ")
+	if len([]rune(passphrase)) < minBackupPassphraseLen {
+		fmt.Printf("Пароль должен быть не короче %d символов\n", minBackupPassphraseLen)
+		return
+	}
+	if promptInput("Повторите пароль: ") != passphrase {
+		fmt.Println("Пароли не совпадают")
+		return
+	}
+
+	var buf bytes.Buffer
+	manifest, err := backup.Write(&buf, vault.Folders, vault.Secrets, passphrase)
+	if err != nil {
+		fmt.Println("Ошибка создания резервной копии:", err)
+		return
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		fmt.Println("Не удалось записать файл:", err)
+		return
+	}
+	fmt.Printf("Резервная копия сохранена: секретов %d, папок %d — %s\n", manifest.Secrets, manifest.Folders, path)
+	fmt.Printf("SHA-256 архива: %x\n", sha256.Sum256(buf.Bytes()))
+}
+
+// VerifyBackup — CLI-обёртка для проверки резервной копии без обращения к серверу.
+//
+// Расшифровывает архив, сверяет размеры и контрольные суммы файлов с описью и выводит её содержимое.
+func VerifyBackup(path string) {
+	archive, ok := openBackup(path)
+	if !ok {
+		return
+	}
+	m := archive.Manifest
+	fmt.Printf("Резервная копия от %s: секретов %d, папок %d, файлов %d\n",
+		m.CreatedAt.Local().Format("2006-01-02 15:04:05"), m.Secrets, m.Folders, len(m.Files))
+	fmt.Println("Контрольные суммы совпадают.")
+}
+
+// RestoreBackup — CLI-обёртка для восстановления резервной копии на текущий сервер.
+//
+// Проверяет архив, сравнивает секреты с уже сохранёнными (дубли по типу и названию пропускаются)
+// и после подтверждения воссоздаёт дерево папок и загружает секреты так же, как импорт.
+// Копию можно восстановить как на тот же сервер, так и на другой.
+func RestoreBackup(path string, rc *resty.Client) {
+	archive, ok := openBackup(path)
+	if !ok {
+		return
+	}
+
+	paths := backupFolderPaths(archive.Folders)
+	result := &importer.Result{}
+	for _, s := range archive.Secrets {
+		item := importer.Item{Title: s.Title, Tags: s.Tags, Data: s.Data}
+		if s.FolderID != nil {
+			item.Folder = paths[*s.FolderID]
+		}
+		result.Add(item)
+	}
+
+	existing, err := fetchSummaries(rc)
+	if err != nil {
+		fmt.Println("Ошибка запроса:", err)
+		return
+	}
+	plan := importer.BuildPlan(result.Items, existing)
+	printImportSummary(importer.Format(backup.FormatName), result, plan)
+
+	if len(plan.New) == 0 && len(paths) == 0 {
+		fmt.Println("Нечего восстанавливать.")
+		return
+	}
+	if !Confirm(fmt.Sprintf("Восстановить %d секретов и %d папок?", len(plan.New), len(paths))) {
+		fmt.Println("Восстановление отменено.")
+		return
+	}
+
+	// Создаются все папки копии, включая пустые
+	all := make([][]string, 0, len(paths))
+	for _, path := range paths {
+		all = append(all, path)
+	}
+	folders, err := resolveFolders(rc, all)
+	if err != nil {
+		fmt.Println("Не удалось создать папки:", err)
+		return
+	}
+	uploadItems(rc, plan.New, folders)
+}
+
+// openBackup читает файл резервной копии, запрашивает пароль и проверяет архив.
+func openBackup(path string) (*backup.Archive, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Println("Не удалось прочитать файл:", err)
+		return nil, false
+	}
+	defer f.Close()
+
+	archive, err := backup.Read(f, promptInput("Пароль резервной копии: "))
+	if err != nil {
+		fmt.Println("Резервная копия не прошла проверку:", err)
+		return nil, false
+	}
+	return archive, true
+}
+
+// backupFolderPaths возвращает путь от корня для каждой папки резервной копии.
+// Папки с потерянным родителем или зацикленной цепочкой родителей попадают в корень.
+func backupFolderPaths(folders []models.ReadFolderDTO) map[uint64][]string {
+	byID := make(map[uint64]models.ReadFolderDTO, len(folders))
+	for _, f := range folders {
+		byID[f.ID] = f
+	}
+
+	paths := make(map[uint64][]string, len(folders))
+	for _, f := range folders {
+		path := []string{f.Name}
+		seen := map[uint64]bool{f.ID: true}
+		for parent := f.ParentID; parent != nil && !seen[*parent]; {
+			p, ok := byID[*parent]
+			if !ok {
+				break
+			}
+			seen[p.ID] = true
+			path = append([]string{p.Name}, path...)
+			parent = p.ParentID
+		}
+		paths[f.ID] = path
+	}
+	return paths
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+func TestBackup(t *testing.T) {
+	server := vaultServer()
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "vault.age")
+
+	restore := MockInput("long passphrase", "long passphrase")
+	output := CaptureOutput(func() {
+		ExportBackup(path, resty.New().SetBaseURL(server.URL))
+	})
+	restore()
+	require.Contains(t, output, "Резервная копия сохранена: секретов 2, папок 1")
+	assert.Contains(t, output, "SHA-256 архива:")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	t.Run("Verify", func(t *testing.T) {
+		restore := MockInput("long passphrase")
+		defer restore()
+		output := CaptureOutput(func() {
+			VerifyBackup(path)
+		})
+		assert.Contains(t, output, "секретов 2, папок 1, файлов 2")
+		assert.Contains(t, output, "Контрольные суммы совпадают")
+	})
+
+	t.Run("Verify_wrong_passphrase", func(t *testing.T) {
+		restore := MockInput("wrong passphrase")
+		defer restore()
+		output := CaptureOutput(func() {
+			VerifyBackup(path)
+		})
+		assert.Contains(t, output, "не прошла проверку")
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		srv := &importServer{}
+		target := httptest.NewServer(srv)
+		defer target.Close()
+
+		restore := MockInput("long passphrase", "y")
+		defer restore()
+		output := CaptureOutput(func() {
+			RestoreBackup(path, resty.New().SetBaseURL(target.URL))
+		})
+
+		assert.Contains(t, output, "загружено 2, ошибок 0")
+		require.Len(t, srv.secrets, 2)
+		byTitle := map[string]models.CreateSecretDTO{}
+		for _, s := range srv.secrets {
+			byTitle[s.Title] = s
+		}
+		assert.Equal(t, uint64(1), *byTitle["VPN"].FolderID)
+		assert.Equal(t, "pa55", byTitle["VPN"].Data.LoginPassword.Password)
+		assert.Nil(t, byTitle["Note"].FolderID)
+	})
+}
+
+func TestExportBackup_ShortPassphrase(t *testing.T) {
+	server := vaultServer()
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "vault.age")
+
+	restore := MockInput("short")
+	defer restore()
+	output := CaptureOutput(func() {
+		ExportBackup(path, resty.New().SetBaseURL(server.URL))
+	})
+
+	assert.Contains(t, output, "не короче 8 символов")
+	assert.NoFileExists(t, path)
+}
+
+func TestBackupFolderPaths(t *testing.T) {
+	one, two, lost := uint64(1), uint64(2), uint64(9)
+	paths := backupFolderPaths([]models.ReadFolderDTO{
+		{ID: 1, Name: "Работа"},
+		{ID: 2, ParentID: &one, Name: "VPN"},
+		{ID: 3, ParentID: &two, Name: "Офис"},
+		{ID: 4, ParentID: &lost, Name: "Сирота"},
+	})
+	assert.Equal(t, []string{"Работа", "VPN", "Офис"}, paths[3])
+	assert.Equal(t, []string{"Сирота"}, paths[4])
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const deviceFileName = "device"
+
+// devicePath — возвращает абсолютный путь к файлу с идентификатором устройства.
+// Например: ~/.gophkeeper/device.
+func devicePath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".gophkeeper", deviceFileName)
+}
+
+// DeviceID — возвращает идентификатор этого устройства, который сервер сохраняет как автора версий секретов.
+// При первом вызове создаёт идентификатор вида "<hostname>-<случайный суффикс>" и сохраняет его в файл,
+// чтобы он не менялся между запусками. Если файл сохранить не удалось, идентификатор всё равно возвращается.
+func DeviceID() string {
+	if data, err := os.ReadFile(devicePath()); err == nil {
+		if id := strings.TrimSpace(string(data)); id != "" {
+			return id
+		}
+	}
+
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "device"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	id := host + "-" + hex.EncodeToString(suffix)
+
+	_ = os.MkdirAll(filepath.Dir(devicePath()), 0700)
+	_ = os.WriteFile(devicePath(), []byte(id), 0600)
+	return id
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// eventsRetry — пауза перед переподключением к ленте изменений, пока сервер не прислал свою (поле retry).
+const eventsRetry = 3 * time.Second
+
+// eventActions — описание типов событий ленты для уведомлений.
+var eventActions = map[string]string{
+	models.SecretEventCreated:  "создан",
+	models.SecretEventUpdated:  "изменён",
+	models.SecretEventDeleted:  "перемещён в корзину",
+	models.SecretEventRestored: "восстановлен из корзины",
+	models.SecretEventPurged:   "удалён окончательно",
+}
+
+// eventStream — состояние подписки на ленту изменений между переподключениями.
+type eventStream struct {
+	lastID string        // ID последнего полученного события (для заголовка Last-Event-ID)
+	retry  time.Duration // Пауза перед переподключением
+}
+
+// WatchChanges — фоновая подписка CLI на ленту изменений секретов (GET /v1.0/secrets/events, Server-Sent Events).
+//
+// Пока ctx не отменён, выводит уведомление о каждом изменении секретов пользователя, в том числе сделанном
+// на других устройствах. При разрыве соединения переподключается с заголовком Last-Event-ID, и сервер
+// досылает пропущенные изменения, а если они уже удалены из журнала — присылает событие reset. Завершается, если сервер отклонил токен (сессия закончилась).
+func WatchChanges(ctx context.Context, rc *resty.Client) {
+	stream := &eventStream{retry: eventsRetry}
+	for {
+		req := rc.R().
+			SetContext(ctx).
+			SetDoNotParseResponse(true).
+			SetHeader("Accept", "text/event-stream")
+		if stream.lastID != "" {
+			req.SetHeader("Last-Event-ID", stream.lastID)
+		}
+		resp, err := req.Get("/v1.0/secrets/events")
+		if err == nil {
+			body := resp.RawBody()
+			switch resp.StatusCode() {
+			case http.StatusOK:
+				stream.read(body, printEvent)
+			case http.StatusUnauthorized:
+				body.Close()
+				return
+			}
+			body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(stream.retry):
+		}
+	}
+}
+
+// read разбирает поток Server-Sent Events до его окончания и передаёт события ленты в notify.
+// Запоминает ID последнего события и паузу переподключения, которую задал сервер.
+func (s *eventStream) read(r io.Reader, notify func(models.SecretEventDTO)) {
+	var id, data string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, ":") {
+			continue // Комментарий (keep-alive)
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "":
+			if data == "" {
+				continue
+			}
+			var event models.SecretEventDTO
+			if err := json.Unmarshal([]byte(data), &event); err == nil {
+				notify(event)
+			}
+			if id != "" {
+				s.lastID = id
+			}
+			id, data = "", ""
+		case "id":
+			id = value
+		case "data":
+			data += value
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms > 0 {
+				s.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// printEvent выводит уведомление об изменении секрета.
+// Событие reset означает, что пропущенные изменения уже удалены из журнала сервера и список секретов устарел.
+func printEvent(event models.SecretEventDTO) {
+	if event.Type == models.SecretEventReset {
+		fmt.Println("\n[синхронизация] Журнал изменений на сервере устарел, получите список секретов заново")
+		return
+	}
+	action, ok := eventActions[event.Type]
+	if !ok {
+		action = event.Type
+	}
+	fmt.Printf("\n[синхронизация] Секрет %d %s (%s)\n", event.SecretID, action, event.Time.Local().Format("2006-01-02 15:04:05"))
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+func TestEventStream_Read(t *testing.T) {
+	stream := &eventStream{retry: eventsRetry}
+	var received []models.SecretEventDTO
+	stream.read(strings.NewReader("retry: 500\n\n"+
+		"id: 5\nevent: created\ndata: {\"type\":\"created\",\"secret_id\":7}\n\n"+
+		": keep-alive\n\n"+
+		"event: deleted\ndata: {\"type\":\"deleted\",\n"+
+		"data: \"secret_id\":8}\n\n"+
+		"id: 9\ndata: {\"type\":\"purged\""), func(event models.SecretEventDTO) {
+		received = append(received, event)
+	})
+
+	assert.Equal(t, []models.SecretEventDTO{
+		{Type: models.SecretEventCreated, SecretID: 7},
+		{Type: models.SecretEventDeleted, SecretID: 8},
+	}, received)
+	assert.Equal(t, "5", stream.lastID)
+	assert.Equal(t, 500*time.Millisecond, stream.retry)
+}
+
+func TestWatchChanges(t *testing.T) {
+	t.Run("Reconnect_with_last_event_id", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var (
+			mu      sync.Mutex
+			lastIDs []string
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+			w.Header().Set("Content-Type", "text/event-stream")
+			switch len(lastIDs) {
+			case 1:
+				fmt.Fprint(w, "retry: 10\n\nid: 5\nevent: created\ndata: {\"type\":\"created\",\"secret_id\":7}\n\n")
+			case 2:
+				fmt.Fprint(w, "id: 6\nevent: deleted\ndata: {\"type\":\"deleted\",\"secret_id\":7}\n\n")
+			default:
+				cancel()
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer server.Close()
+
+		output := CaptureOutput(func() {
+			WatchChanges(ctx, resty.New().SetBaseURL(server.URL))
+		})
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, []string{"", "5", "6"}, lastIDs)
+		assert.Contains(t, output, "Секрет 7 создан")
+		assert.Contains(t, output, "Секрет 7 перемещён в корзину")
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		output := CaptureOutput(func() {
+			WatchChanges(context.Background(), newMockClient(401, `{"error":"invalid token"}`))
+		})
+
+		assert.Empty(t, output)
+	})
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// ListFolders — CLI-обёртка для вывода дерева папок пользователя.
+//
+// Выполняет GET-запрос на /v1.0/folders и выводит папки с отступами по уровню вложенности:
+//
+//	1  Работа
+//	  3  Серверы
+//	2  Личное
+func ListFolders(rc *resty.Client) {
+	var folders []models.ReadFolderDTO
+	resp, err := rc.R().
+		SetResult(&folders).
+		Get("/v1.0/folders")
+	if err != nil {
+		fmt.Println("Ошибка запроса:", err)
+		return
+	}
+	if resp.IsError() {
+		fmt.Println(resp.StatusCode(), string(resp.Body()))
+		return
+	}
+	if len(folders) == 0 {
+		fmt.Println("Папок нет.")
+		return
+	}
+
+	known := make(map[uint64]bool, len(folders))
+	for _, f := range folders {
+		known[f.ID] = true
+	}
+	children := make(map[uint64][]models.ReadFolderDTO)
+	for _, f := range folders {
+		var parent uint64
+		if f.ParentID != nil && known[*f.ParentID] {
+			parent = *f.ParentID
+		}
+		children[parent] = append(children[parent], f)
+	}
+	printFolderTree(children, 0, 0)
+}
+
+// printFolderTree рекурсивно выводит папки, вложенные в parent, с отступом по уровню depth.
+func printFolderTree(children map[uint64][]models.ReadFolderDTO, parent uint64, depth int) {
+	for _, f := range children[parent] {
+		fmt.Printf("%s%d  %s\n", strings.Repeat("  ", depth), f.ID, f.Name)
+		printFolderTree(children, f.ID, depth+1)
+	}
+}
+
+// CreateFolder — CLI-обёртка для создания папки.
+//
+// Выполняет POST-запрос на /v1.0/folders. parentID == nil создаёт папку в корне.
+// В случае успеха выводит ID созданной папки, иначе — статус и тело ответа.
+func CreateFolder(name string, parentID *uint64, rc *resty.Client) {
+	var folder models.ReadFolderDTO
+	resp, err := rc.R().
+		SetBody(models.CreateFolderDTO{Name: name, ParentID: parentID}).
+		SetResult(&folder).
+		Post("/v1.0/folders")
+	if err != nil {
+		fmt.Println("Ошибка запроса:", err)
+		return
+	}
+	if resp.IsError() {
+		fmt.Println(resp.StatusCode(), string(resp.Body()))
+		return
+	}
+	fmt.Printf("Папка создана, ID: %d\n", folder.ID)
+}
+
+// RenameFolder — CLI-обёртка для переименования папки.
+//
+// Выполняет PUT-запрос на /v1.0/folders/{id}/name.
+func RenameFolder(id uint64, name string, rc *resty.Client) {
+	resp, err := rc.R().
+		SetBody(models.RenameFolderDTO{Name: name}).
+		Put(fmt.Sprintf("/v1.0/folders/%d/name", id))
+	if err != nil {
+		fmt.Println("Ошибка запроса:", err)
+		return
+	}
+	if resp.IsError() {
+		fmt.Println(resp.StatusCode(), string(resp.Body()))
+		return
+	}
+	fmt.Println("Папка переименована.")
+}
+
+// MoveFolder — CLI-обёртка для перемещения папки.
+//
+// Выполняет PUT-запрос на /v1.0/folders/{id}/parent. parentID == nil переносит папку в корень.
+func MoveFolder(id uint64, parentID *uint64, rc *resty.Client) {
+	resp, err := rc.R().
+		SetBody(models.MoveFolderDTO{ParentID: parentID}).
+		Put(fmt.Sprintf("/v1.0/folders/%d/parent", id))
+	if err != nil {
+		fmt.Println("Ошибка запроса:", err)
+		return
+	}
+	if resp.IsError() {
+		fmt.Println(resp.StatusCode(), string(resp.Body()))
+		return
+	}
+	fmt.Println("Папка перемещена.")
+}
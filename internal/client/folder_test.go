@@ -0,0 +1,70 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListFolders(t *testing.T) {
+	t.Run("Tree", func(t *testing.T) {
+		client := newMockClient(200, `[
+			{"id":1,"name":"Работа","parent_id":null},
+			{"id":2,"name":"Личное","parent_id":null},
+			{"id":3,"name":"Серверы","parent_id":1}
+		]`)
+
+		output := CaptureOutput(func() {
+			ListFolders(client)
+		})
+
+		assert.Equal(t, "1  Работа\n  3  Серверы\n2  Личное\n", output)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		output := CaptureOutput(func() {
+			ListFolders(newMockClient(200, `[]`))
+		})
+		assert.Contains(t, output, "Папок нет")
+	})
+
+	t.Run("HttpError", func(t *testing.T) {
+		client := resty.New()
+		client.SetTransport(&errorRoundTripper{})
+		output := CaptureOutput(func() {
+			ListFolders(client)
+		})
+		assert.Contains(t, output, "Ошибка запроса")
+	})
+}
+
+func TestCreateFolder(t *testing.T) {
+	output := CaptureOutput(func() {
+		CreateFolder("Работа", nil, newMockClient(201, `{"id":5,"name":"Работа"}`))
+	})
+	assert.Contains(t, output, "ID: 5")
+
+	output = CaptureOutput(func() {
+		CreateFolder("Работа", nil, newMockClient(409, `{"error":"folder already exists"}`))
+	})
+	assert.Contains(t, output, "409")
+}
+
+func TestRenameAndMoveFolder(t *testing.T) {
+	output := CaptureOutput(func() {
+		RenameFolder(5, "Новое", newMockClient(200, `{"id":5}`))
+	})
+	assert.Contains(t, output, "Папка переименована")
+
+	parentID := uint64(1)
+	output = CaptureOutput(func() {
+		MoveFolder(5, &parentID, newMockClient(200, `{"id":5}`))
+	})
+	assert.Contains(t, output, "Папка перемещена")
+
+	output = CaptureOutput(func() {
+		MoveFolder(5, &parentID, newMockClient(409, `{"error":"cycle"}`))
+	})
+	assert.Contains(t, output, "409")
+}
@@ -0,0 +1,313 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/shekshuev/gophkeeper/internal/importer"
+	"github.com/shekshuev/gophkeeper/internal/keepass"
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// importBatchSize — сколько секретов загружается на сервер одним пакетным запросом.
+const importBatchSize = 200
+
+// ImportSecrets — CLI-обёртка для импорта секретов из экспорта другого менеджера паролей.
+//
+// Разбирает файл (формат определяется автоматически, если format пуст; для базы KeePass
+// запрашиваются мастер-пароль и ключевой файл), сравнивает записи
+// с уже сохранёнными секретами и выводит сводку: сколько записей каких типов найдено,
+// что пропущено и какие записи являются дублями. Загрузка начинается только после подтверждения.
+// Папки из экспорта создаются при необходимости, секреты загружаются пачками по importBatchSize.
+func ImportSecrets(path, format string, rc *resty.Client) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Println("Не удалось прочитать файл:", err)
+		return
+	}
+	f := importer.Format(format)
+	if f == "" {
+		if f, err = importer.Detect(path, data); err != nil {
+			fmt.Println("Не удалось определить формат файла, укажите его явно:", ImportFormats())
+			return
+		}
+	}
+	result, err := parseExport(f, data)
+	if err != nil {
+		fmt.Println("Ошибка разбора файла:", err)
+		return
+	}
+
+	existing, err := fetchSummaries(rc)
+	if err != nil {
+		fmt.Println("Ошибка запроса:", err)
+		return
+	}
+	plan := importer.BuildPlan(result.Items, existing)
+	printImportSummary(f, result, plan)
+
+	if len(plan.New) == 0 {
+		fmt.Println("Нечего импортировать.")
+		return
+	}
+	if !Confirm(fmt.Sprintf("Импортировать %d секретов?", len(plan.New))) {
+		fmt.Println("Импорт отменён.")
+		return
+	}
+
+	folders, err := resolveFolders(rc, folderPaths(plan.New))
+	if err != nil {
+		fmt.Println("Не удалось создать папки:", err)
+		return
+	}
+	uploadItems(rc, plan.New, folders)
+}
+
+// parseExport разбирает файл экспорта; базу KeePass открывает с мастер-паролем и ключевым файлом, введёнными пользователем.
+func parseExport(format importer.Format, data []byte) (*importer.Result, error) {
+	if format != importer.FormatKeePass {
+		return importer.Parse(format, data)
+	}
+	creds, err := PromptKeePassCredentials(false)
+	if err != nil {
+		return nil, err
+	}
+	return keepass.Import(data, creds)
+}
+
+// printImportSummary выводит сводку предстоящего импорта.
+func printImportSummary(format importer.Format, result *importer.Result, plan importer.Plan) {
+	counts := make(map[string]int)
+	for _, item := range result.Items {
+		counts[item.Type()]++
+	}
+	fmt.Printf("Формат: %s\n", format)
+	fmt.Printf("Найдено записей: %d (логины: %d, карты: %d, заметки: %d, файлы: %d)\n",
+		len(result.Items), counts[models.SecretTypeLogin], counts[models.SecretTypeCard], counts[models.SecretTypeText],
+		counts[models.SecretTypeBinary])
+
+	printList := func(header string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Printf("%s: %d\n", header, len(lines))
+		for _, line := range lines {
+			fmt.Println("  -", line)
+		}
+	}
+	printList("Пропущено при разборе", result.Skipped)
+	printList("Перенесены не полностью", result.Warnings)
+
+	duplicates := make([]string, 0, len(plan.Duplicates))
+	for _, d := range plan.Duplicates {
+		duplicates = append(duplicates, d.Item.Title+": "+d.Reason)
+	}
+	printList("Дубли (не будут импортированы)", duplicates)
+	fmt.Printf("Будет импортировано: %d\n", len(plan.New))
+}
+
+// fetchSummaries загружает метаданные всех секретов пользователя, проходя по страницам списка.
+func fetchSummaries(rc *resty.Client) ([]models.SecretSummaryDTO, error) {
+	var summaries []models.SecretSummaryDTO
+	req := rc.R().SetQueryParam("limit", strconv.Itoa(listPageSize))
+	next := "/v1.0/secrets"
+	for next != "" {
+		var page models.SecretSummaryPageDTO
+		resp, err := req.SetResult(&page).Get(next)
+		if err != nil {
+			return nil, err
+		}
+		if resp.IsError() {
+			return nil, fmt.Errorf("%d %s", resp.StatusCode(), resp.Body())
+		}
+		summaries = append(summaries, page.Items...)
+		next = page.Next
+		req = rc.R()
+	}
+	return summaries, nil
+}
+
+// fetchVault загружает все папки и секреты пользователя с данными.
+// Секреты выгружаются по страницам через GET /v1.0/secrets/export: сервер записывает просмотр каждого в журнал аудита.
+func fetchVault(rc *resty.Client) (keepass.Vault, error) {
+	var vault keepass.Vault
+	resp, err := rc.R().SetResult(&vault.Folders).Get("/v1.0/folders")
+	if err != nil {
+		return vault, err
+	}
+	if resp.IsError() {
+		return vault, fmt.Errorf("%d %s", resp.StatusCode(), resp.Body())
+	}
+
+	req := rc.R().SetQueryParam("limit", strconv.Itoa(listPageSize))
+	next := "/v1.0/secrets/export"
+	for next != "" {
+		var page models.SecretPageDTO
+		resp, err := req.SetResult(&page).Get(next)
+		if err != nil {
+			return vault, err
+		}
+		if resp.IsError() {
+			return vault, fmt.Errorf("%d %s", resp.StatusCode(), resp.Body())
+		}
+		vault.Secrets = append(vault.Secrets, page.Items...)
+		next = page.Next
+		req = rc.R()
+	}
+	return vault, nil
+}
+
+// folderPaths возвращает непустые пути папок импортируемых записей.
+func folderPaths(items []importer.Item) [][]string {
+	var paths [][]string
+	for _, item := range items {
+		if len(item.Folder) > 0 {
+			paths = append(paths, item.Folder)
+		}
+	}
+	return paths
+}
+
+// resolveFolders находит или создаёт папки для всех путей.
+// Возвращает ID папки по пути, склеенному через «/».
+func resolveFolders(rc *resty.Client, paths [][]string) (map[string]uint64, error) {
+	resolved := make(map[string]uint64)
+	if len(paths) == 0 {
+		return resolved, nil
+	}
+
+	var folders []models.ReadFolderDTO
+	resp, err := rc.R().SetResult(&folders).Get("/v1.0/folders")
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("%d %s", resp.StatusCode(), resp.Body())
+	}
+	byParent := make(map[string]uint64, len(folders))
+	for _, f := range folders {
+		byParent[folderKey(f.ParentID, f.Name)] = f.ID
+	}
+
+	for _, folderPath := range paths {
+		var parent *uint64
+		for i, name := range folderPath {
+			path := strings.Join(folderPath[:i+1], "/")
+			id, ok := resolved[path]
+			if !ok {
+				if id, ok = byParent[folderKey(parent, name)]; !ok {
+					var folder models.ReadFolderDTO
+					resp, err := rc.R().
+						SetBody(models.CreateFolderDTO{Name: name, ParentID: parent}).
+						SetResult(&folder).
+						Post("/v1.0/folders")
+					if err != nil {
+						return nil, err
+					}
+					if resp.IsError() {
+						return nil, fmt.Errorf("%s: %d %s", path, resp.StatusCode(), resp.Body())
+					}
+					id = folder.ID
+					byParent[folderKey(parent, name)] = id
+				}
+				resolved[path] = id
+			}
+			parent = &id
+		}
+	}
+	return resolved, nil
+}
+
+// folderKey — ключ папки по родительской папке и названию.
+func folderKey(parentID *uint64, name string) string {
+	var parent uint64
+	if parentID != nil {
+		parent = *parentID
+	}
+	return strconv.FormatUint(parent, 10) + "/" + name
+}
+
+// uploadItems загружает записи на сервер пакетными запросами (POST /v1.0/secrets/batch) и выводит прогресс и итог.
+// Пакеты неатомарные: ошибка одной записи не отменяет загрузку остальных.
+func uploadItems(rc *resty.Client, items []importer.Item, folders map[string]uint64) {
+	var failed []string
+	for start := 0; start < len(items); start += importBatchSize {
+		end := min(start+importBatchSize, len(items))
+		failed = append(failed, uploadBatch(rc, items[start:end], folders)...)
+		fmt.Printf("Обработано %d из %d\n", end, len(items))
+	}
+
+	fmt.Printf("Импорт завершён: загружено %d, ошибок %d\n", len(items)-len(failed), len(failed))
+	for _, f := range failed {
+		fmt.Println("  -", f)
+	}
+}
+
+// uploadBatch создаёт на сервере секреты из пачки импортируемых записей одним пакетным запросом.
+// Возвращает описания записей, которые не удалось загрузить.
+func uploadBatch(rc *resty.Client, items []importer.Item, folders map[string]uint64) []string {
+	var failed []string
+	var sent []importer.Item
+	req := models.BatchRequestDTO{}
+	for _, item := range items {
+		op := models.BatchOperationDTO{
+			Op:    models.BatchOpCreate,
+			Title: item.Title,
+			Data:  item.Data,
+			Tags:  item.Tags,
+		}
+		if len(item.Folder) > 0 {
+			id, ok := folders[strings.Join(item.Folder, "/")]
+			if !ok {
+				failed = append(failed, item.Title+": папка не найдена")
+				continue
+			}
+			op.FolderID = &id
+		}
+		req.Operations = append(req.Operations, op)
+		sent = append(sent, item)
+	}
+	if len(sent) == 0 {
+		return failed
+	}
+
+	var result models.BatchResponseDTO
+	resp, err := rc.R().
+		SetHeader(idempotencyKeyHeader, newIdempotencyKey()).
+		SetBody(req).
+		SetResult(&result).
+		Post("/v1.0/secrets/batch")
+	if err == nil && resp.IsError() {
+		err = fmt.Errorf("%d %s", resp.StatusCode(), strings.TrimSpace(string(resp.Body())))
+	}
+	if err == nil && len(result.Results) != len(sent) {
+		err = errors.New("сервер вернул неполный ответ")
+	}
+	if err != nil {
+		for _, item := range sent {
+			failed = append(failed, item.Title+": "+err.Error())
+		}
+		return failed
+	}
+
+	for i, r := range result.Results {
+		if r.Error != "" {
+			failed = append(failed, fmt.Sprintf("%s: %d %s", sent[i].Title, r.Status, r.Error))
+		}
+	}
+	return failed
+}
+
+// ImportFormats возвращает список поддерживаемых форматов через запятую.
+func ImportFormats() string {
+	var names []string
+	for _, f := range importer.Formats() {
+		names = append(names, string(f))
+	}
+	return strings.Join(names, ", ")
+}
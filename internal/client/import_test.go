@@ -0,0 +1,165 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shekshuev/gophkeeper/internal/importer"
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// importServer — тестовый сервер, принимающий запросы импорта.
+type importServer struct {
+	mu      sync.Mutex
+	folders []models.CreateFolderDTO
+	secrets []models.CreateSecretDTO
+	keys    []string
+}
+
+func (s *importServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v1.0/secrets":
+		_, _ = w.Write([]byte(`{"items":[{"id":9,"title":"Mail","type":"login"}]}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/v1.0/folders":
+		_, _ = w.Write([]byte(`[{"id":1,"name":"Work"}]`))
+	case r.Method == http.MethodPost && r.URL.Path == "/v1.0/folders":
+		var dto models.CreateFolderDTO
+		_ = json.NewDecoder(r.Body).Decode(&dto)
+		s.folders = append(s.folders, dto)
+		_, _ = w.Write([]byte(`{"id":2,"name":"` + dto.Name + `"}`))
+	case r.Method == http.MethodPost && r.URL.Path == "/v1.0/secrets/batch":
+		var dto models.BatchRequestDTO
+		_ = json.NewDecoder(r.Body).Decode(&dto)
+		s.keys = append(s.keys, r.Header.Get("Idempotency-Key"))
+		resp := models.BatchResponseDTO{Committed: true}
+		for i, op := range dto.Operations {
+			s.secrets = append(s.secrets, models.CreateSecretDTO{Title: op.Title, Data: op.Data, FolderID: op.FolderID, Tags: op.Tags})
+			resp.Results = append(resp.Results, models.BatchResultDTO{Index: i, Op: op.Op, Status: http.StatusCreated, ID: uint64(100 + i)})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func writeExport(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+const importLastPassCSV = `url,username,password,totp,extra,name,grouping,fav
+https://mail.example.com,john,pa55,,,Mail,Work,0
+https://vpn.example.com,john,pa55,,,VPN,Work\Servers,0
+https://vpn.example.com,john,pa55,,,VPN,Work\Servers,0
+`
+
+func TestImportSecrets(t *testing.T) {
+	t.Run("Confirmed", func(t *testing.T) {
+		srv := &importServer{}
+		server := httptest.NewServer(srv)
+		defer server.Close()
+		path := writeExport(t, "lastpass.csv", importLastPassCSV)
+
+		restore := MockInput("y")
+		defer restore()
+		output := CaptureOutput(func() {
+			ImportSecrets(path, "", resty.New().SetBaseURL(server.URL))
+		})
+
+		assert.Contains(t, output, "Формат: lastpass")
+		assert.Contains(t, output, "Найдено записей: 3 (логины: 3, карты: 0, заметки: 0, файлы: 0)")
+		assert.Contains(t, output, "Дубли (не будут импортированы): 2")
+		assert.Contains(t, output, "Будет импортировано: 1")
+		assert.Contains(t, output, "загружено 1, ошибок 0")
+
+		require.Len(t, srv.folders, 1)
+		assert.Equal(t, "Servers", srv.folders[0].Name)
+		assert.Equal(t, uint64(1), *srv.folders[0].ParentID)
+		require.Len(t, srv.secrets, 1)
+		assert.Equal(t, "VPN", srv.secrets[0].Title)
+		assert.Equal(t, uint64(2), *srv.secrets[0].FolderID)
+		assert.Equal(t, "https://vpn.example.com", srv.secrets[0].Data.LoginPassword.URL)
+		require.Len(t, srv.keys, 1)
+		assert.Len(t, srv.keys[0], 32)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		srv := &importServer{}
+		server := httptest.NewServer(srv)
+		defer server.Close()
+		path := writeExport(t, "lastpass.csv", importLastPassCSV)
+
+		restore := MockInput("n")
+		defer restore()
+		output := CaptureOutput(func() {
+			ImportSecrets(path, "lastpass", resty.New().SetBaseURL(server.URL))
+		})
+
+		assert.Contains(t, output, "Импорт отменён")
+		assert.Empty(t, srv.secrets)
+	})
+
+	t.Run("Unknown_format", func(t *testing.T) {
+		path := writeExport(t, "export.txt", "hello")
+
+		output := CaptureOutput(func() {
+			ImportSecrets(path, "", resty.New())
+		})
+
+		assert.Contains(t, output, "Не удалось определить формат")
+	})
+
+	t.Run("Missing_file", func(t *testing.T) {
+		output := CaptureOutput(func() {
+			ImportSecrets(filepath.Join(t.TempDir(), "none.csv"), "", resty.New())
+		})
+
+		assert.Contains(t, output, "Не удалось прочитать файл")
+	})
+
+	t.Run("Server_error", func(t *testing.T) {
+		path := writeExport(t, "lastpass.csv", importLastPassCSV)
+
+		output := CaptureOutput(func() {
+			ImportSecrets(path, "", newMockClient(500, `{"error":"db"}`))
+		})
+
+		assert.Contains(t, output, "Ошибка запроса")
+	})
+}
+
+func TestUploadBatch(t *testing.T) {
+	items := []importer.Item{
+		{Title: "Mail"},
+		{Title: "VPN", Folder: []string{"Missing"}},
+		{Title: "Card", Folder: []string{"Work"}},
+	}
+
+	t.Run("Item_errors", func(t *testing.T) {
+		client := newMockClient(http.StatusOK, `{"committed":true,"results":[`+
+			`{"index":0,"op":"create","status":201,"id":10},`+
+			`{"index":1,"op":"create","status":404,"error":"folder not found"}]}`)
+
+		failed := uploadBatch(client, items, map[string]uint64{"Work": 1})
+		assert.Equal(t, []string{"VPN: папка не найдена", "Card: 404 folder not found"}, failed)
+	})
+
+	t.Run("Request_error", func(t *testing.T) {
+		failed := uploadBatch(newMockClient(http.StatusInternalServerError, `{"error":"db"}`), items[:1], nil)
+		assert.Equal(t, []string{`Mail: 500 {"error":"db"}`}, failed)
+	})
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/shekshuev/gophkeeper/internal/keepass"
+)
+
+// PromptKeePassCredentials запрашивает мастер-пароль базы KeePass и необязательный путь к ключевому файлу.
+// При confirm = true пароль запрашивается повторно и должен совпасть.
+func PromptKeePassCredentials(confirm bool) (keepass.Credentials, error) {
+	creds := keepass.Credentials{Password: promptInput("Мастер-пароль базы KeePass: ")}
+	if confirm && promptInput("Повторите мастер-пароль: ") != creds.Password {
+		return creds, errors.New("пароли не совпадают")
+	}
+	if path := promptInput("Путь к ключевому файлу (Enter — без ключевого файла): "); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return creds, fmt.Errorf("не удалось прочитать ключевой файл: %w", err)
+		}
+		creds.KeyFile = data
+	}
+	if creds.Password == "" && creds.KeyFile == nil {
+		return creds, errors.New("нужен мастер-пароль или ключевой файл")
+	}
+	return creds, nil
+}
+
+// ExportKeePass — CLI-обёртка для выгрузки всего хранилища в базу KeePass KDBX 4.
+//
+// Загружает папки (GET /v1.0/folders) и все секреты с данными (GET /v1.0/secrets/export по страницам),
+// запрашивает мастер-пароль новой базы и ключевой файл и записывает базу в path с правами 0600.
+// Существующий файл перезаписывается только после подтверждения.
+func ExportKeePass(path string, rc *resty.Client) {
+
+	if _, err := os.Stat(path); err == nil && !Confirm("Файл "+path+" уже существует. Перезаписать?") {
+		fmt.Println("Экспорт отменён.")
+		return
+	}
+
+	vault, err := fetchVault(rc)
+	if err != nil {
+		fmt.Println("Ошибка запроса:", err)
+		return
+	}
+
+	creds, err := PromptKeePassCredentials(true)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := keepass.Export(&buf, vault, creds); err != nil {
+		fmt.Println("Ошибка экспорта:", err)
+		return
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		fmt.Println("Не удалось записать файл:", err)
+		return
+	}
+	fmt.Printf("Экспортировано секретов: %d, папок: %d — %s\n", len(vault.Secrets), len(vault.Folders), path)
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shekshuev/gophkeeper/internal/keepass"
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+func vaultServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v1.0/folders":
+			_, _ = w.Write([]byte(`[{"id":1,"name":"Work"}]`))
+		case r.URL.Path == "/v1.0/secrets/export" && r.URL.Query().Get("cursor") == "":
+			_, _ = w.Write([]byte(`{"items":[{"id":1,"title":"VPN","folder_id":1,"data":{"login_password":{"login":"john","password":"pa55"}}}],` +
+				`"next":"/v1.0/secrets/export?limit=100&cursor=abc"}`))
+		case r.URL.Path == "/v1.0/secrets/export":
+			_, _ = w.Write([]byte(`{"items":[{"id":2,"title":"Note","data":{"text":"hello"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestExportKeePass(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		server := vaultServer()
+		defer server.Close()
+		path := filepath.Join(t.TempDir(), "vault.kdbx")
+
+		restore := MockInput("master", "master", "")
+		defer restore()
+		output := CaptureOutput(func() {
+			ExportKeePass(path, resty.New().SetBaseURL(server.URL))
+		})
+
+		assert.Contains(t, output, "Экспортировано секретов: 2, папок: 1")
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		result, err := keepass.Import(data, keepass.Credentials{Password: "master"})
+		require.NoError(t, err)
+		require.Len(t, result.Items, 2)
+		assert.Equal(t, "VPN", result.Items[1].Title)
+		assert.Equal(t, []string{"Work"}, result.Items[1].Folder)
+		assert.Equal(t, "pa55", result.Items[1].Data.LoginPassword.Password)
+	})
+
+	t.Run("Password_mismatch", func(t *testing.T) {
+		server := vaultServer()
+		defer server.Close()
+		path := filepath.Join(t.TempDir(), "vault.kdbx")
+
+		restore := MockInput("master", "other")
+		defer restore()
+		output := CaptureOutput(func() {
+			ExportKeePass(path, resty.New().SetBaseURL(server.URL))
+		})
+
+		assert.Contains(t, output, "пароли не совпадают")
+		assert.NoFileExists(t, path)
+	})
+
+	t.Run("HttpError", func(t *testing.T) {
+		client := resty.New()
+		client.SetTransport(&errorRoundTripper{})
+
+		output := CaptureOutput(func() {
+			ExportKeePass(filepath.Join(t.TempDir(), "vault.kdbx"), client)
+		})
+
+		assert.Contains(t, output, "Ошибка запроса")
+	})
+}
+
+func TestImportSecrets_KeePass(t *testing.T) {
+	text := "hello"
+	var buf bytes.Buffer
+	require.NoError(t, keepass.Export(&buf, keepass.Vault{Secrets: []models.ReadSecretDTO{
+		{Title: "Mail", Data: models.SecretDataDTO{LoginPassword: &models.LoginPasswordData{Login: "john", Password: "pa55"}}},
+		{Title: "Note", Data: models.SecretDataDTO{Text: &text}},
+	}}, keepass.Credentials{Password: "master"}))
+	path := writeExport(t, "vault.kdbx", buf.String())
+
+	t.Run("Confirmed", func(t *testing.T) {
+		srv := &importServer{}
+		server := httptest.NewServer(srv)
+		defer server.Close()
+
+		restore := MockInput("master", "", "y")
+		defer restore()
+		output := CaptureOutput(func() {
+			ImportSecrets(path, "", resty.New().SetBaseURL(server.URL))
+		})
+
+		assert.Contains(t, output, "Формат: keepass")
+		assert.Contains(t, output, "Дубли (не будут импортированы): 1")
+		require.Len(t, srv.secrets, 1)
+		assert.Equal(t, "Note", srv.secrets[0].Title)
+	})
+
+	t.Run("Wrong_password", func(t *testing.T) {
+		restore := MockInput("wrong", "")
+		defer restore()
+		output := CaptureOutput(func() {
+			ImportSecrets(path, "", resty.New())
+		})
+
+		assert.Contains(t, output, "invalid master password")
+	})
+}
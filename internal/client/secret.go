@@ -2,10 +2,14 @@ package client
 
 import (
 	"bufio"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/shekshuev/gophkeeper/internal/models"
@@ -20,10 +24,84 @@ import (
 //	[3] Банковская карта
 //	[4] Бинарные данные (в hex или base64, пока не поддержано)
 //
-// Пользователь пошагово вводит данные через консоль, затем выполняется POST-запрос на /v1.0/secrets.
+// Пользователь пошагово вводит данные через консоль, затем (необязательно) ID папки и теги,
+// после чего выполняется POST-запрос на /v1.0/secrets.
 // В случае успеха выводится HTTP-статус и тело ответа.
 func CreateSecret(title string, rc *resty.Client) {
 
+	secretData, ok := promptSecretData()
+	if !ok {
+		return
+	}
+
+	folderID, err := ParseOptionalID(promptInput("ID папки (Enter — без папки): "))
+	if err != nil {
+		fmt.Println("Некорректный ID папки")
+		return
+	}
+
+	payload := models.CreateSecretDTO{
+		Title:    title,
+		Data:     *secretData,
+		FolderID: folderID,
+		Tags:     parseTags(promptInput("Теги через запятую (Enter — без тегов): ")),
+	}
+
+	resp, err := rc.R().
+		SetHeader(idempotencyKeyHeader, newIdempotencyKey()).
+		SetBody(payload).
+		Post("/v1.0/secrets")
+	if err != nil {
+		fmt.Println("Ошибка запроса:", err)
+		return
+	}
+	fmt.Println(resp.StatusCode(), string(resp.Body()))
+}
+
+// UpdateSecret — CLI-обёртка для изменения секрета.
+//
+// Пользователь заново вводит тип и данные секрета, ID папки и теги, после чего выполняется PUT-запрос
+// на /v1.0/secrets/{id}. Предыдущее значение остаётся в истории версий на сервере.
+// В случае успеха выводит новый номер версии.
+func UpdateSecret(id uint64, title string, rc *resty.Client) {
+
+	secretData, ok := promptSecretData()
+	if !ok {
+		return
+	}
+
+	folderID, err := ParseOptionalID(promptInput("ID папки (Enter — без папки): "))
+	if err != nil {
+		fmt.Println("Некорректный ID папки")
+		return
+	}
+
+	payload := models.UpdateSecretDTO{
+		Title:    title,
+		Data:     *secretData,
+		FolderID: folderID,
+		Tags:     parseTags(promptInput("Теги через запятую (Enter — без тегов): ")),
+	}
+
+	var secret models.ReadSecretDTO
+	resp, err := rc.R().
+		SetBody(payload).
+		SetResult(&secret).
+		Put(fmt.Sprintf("/v1.0/secrets/%d", id))
+	if err != nil {
+		fmt.Println("Ошибка запроса:", err)
+		return
+	}
+	if resp.IsError() {
+		fmt.Println(resp.StatusCode(), string(resp.Body()))
+		return
+	}
+	fmt.Printf("Секрет изменён, версия %d\n", secret.Version)
+}
+
+// promptSecretData — пошагово запрашивает у пользователя тип и данные секрета.
+// Возвращает ok = false, если выбран неверный или ещё не поддерживаемый тип.
+func promptSecretData() (*models.SecretDataDTO, bool) {
 	fmt.Println(`[1] Произвольный текст
 [2] Логин + пароль
 [3] Банковская карта
@@ -60,26 +138,14 @@ func CreateSecret(title string, rc *resty.Client) {
 
 	case "4":
 		fmt.Println("Бинарные данные пока не реализованы.")
-		return
+		return nil, false
 
 	default:
 		fmt.Println("Неверный тип.")
-		return
+		return nil, false
 	}
 
-	payload := models.CreateSecretDTO{
-		Title: title,
-		Data:  secretData,
-	}
-
-	resp, err := rc.R().
-		SetBody(payload).
-		Post("/v1.0/secrets")
-	if err != nil {
-		fmt.Println("Ошибка запроса:", err)
-		return
-	}
-	fmt.Println(resp.StatusCode(), string(resp.Body()))
+	return &secretData, true
 }
 
 // GetSecret — CLI-обёртка для получения одного секрета по ID.
@@ -106,25 +172,83 @@ func GetSecret(id uint64, rc *resty.Client) {
 	fmt.Println(string(j))
 }
 
-// ListSecrets — CLI-обёртка для получения всех секретов пользователя.
+// listPageSize — размер страницы, которой CLI запрашивает список секретов.
+const listPageSize = 100
+
+// ListSecrets — CLI-обёртка для получения секретов пользователя.
 //
-// Получает user_id с помощью функции getUserID (обычно из access-токена).
-// Затем выполняет GET-запрос на /v1.0/secrets/user/{user_id}.
-// В случае успеха выводит ID и название каждого секрета построчно.
-func ListSecrets(rc *resty.Client, getUserID func() (string, error)) {
+// Выполняет GET-запрос на /v1.0/secrets, передавая фильтр (папка, тег, поиск по названию, тип) в query-параметрах,
+// и последовательно загружает страницы по ссылке next, пока она есть.
+// Список содержит только метаданные: данные секрета можно посмотреть через GetSecret.
+// Выводит ID, название, тип и теги каждого секрета построчно по мере получения страниц.
+func ListSecrets(rc *resty.Client, filter models.SecretFilterDTO) {
 
-	userID, err := getUserID()
-	if err != nil {
-		fmt.Println("Ошибка авторизации:", err)
-		return
+	params := map[string]string{"limit": strconv.Itoa(listPageSize)}
+	if filter.FolderID != nil {
+		params["folder_id"] = strconv.FormatUint(*filter.FolderID, 10)
+	}
+	if filter.Tag != "" {
+		params["tag"] = filter.Tag
+	}
+	if filter.Query != "" {
+		params["q"] = filter.Query
+	}
+	if filter.Type != "" {
+		params["type"] = filter.Type
+	}
+	if filter.Sort != "" {
+		params["sort"] = filter.Sort
 	}
 
-	var secrets []models.ReadSecretDTO
+	count := 0
+	req := rc.R().SetQueryParams(params)
+	next := "/v1.0/secrets"
+	for next != "" {
+		var page models.SecretSummaryPageDTO
+		resp, err := req.
+			SetResult(&page).
+			Get(next)
+		if err != nil {
+			fmt.Println("Ошибка запроса:", err)
+			return
+		}
+		if resp.IsError() {
+			fmt.Println(resp.StatusCode(), string(resp.Body()))
+			return
+		}
+
+		for _, s := range page.Items {
+			line := fmt.Sprintf("%d  %s", s.ID, s.Title)
+			if s.Type != "" {
+				line += "  (" + s.Type + ")"
+			}
+			if len(s.Tags) > 0 {
+				line += "  [" + strings.Join(s.Tags, ", ") + "]"
+			}
+			fmt.Println(line)
+		}
+		count += len(page.Items)
+		next = page.Next
+		// Ссылка next уже содержит все параметры запроса
+		req = rc.R()
+	}
+
+	if count == 0 {
+		fmt.Println("Секретов нет.")
+	}
+}
+
+// SecretAudit — CLI-обёртка для просмотра журнала аудита секрета.
+//
+// Выполняет GET-запрос на /v1.0/secrets/{id}/audit и выводит время, действие, IP-адрес и User-Agent каждой записи.
+func SecretAudit(id uint64, rc *resty.Client) {
+
+	var records []models.ReadSecretAuditDTO
 	resp, err := rc.R().
-		SetResult(&secrets).
-		Get("/v1.0/secrets/user/" + userID)
+		SetResult(&records).
+		Get(fmt.Sprintf("/v1.0/secrets/%d/audit", id))
 	if err != nil {
-		fmt.Println("Ошибка запроса:", err)
+		fmt.Println("Ошибка:", err)
 		return
 	}
 	if resp.IsError() {
@@ -132,15 +256,19 @@ func ListSecrets(rc *resty.Client, getUserID func() (string, error)) {
 		return
 	}
 
-	for _, s := range secrets {
-		fmt.Printf("%d  %s\n", s.ID, s.Title)
+	if len(records) == 0 {
+		fmt.Println("Записей нет.")
+		return
+	}
+	for _, rec := range records {
+		fmt.Printf("%s  %s  %s  %s\n", rec.CreatedAt.Local().Format("2006-01-02 15:04:05"), rec.Action, rec.IP, rec.UserAgent)
 	}
 }
 
 // DeleteSecret — CLI-обёртка для удаления секрета по ID.
 //
-// Выполняет DELETE-запрос на /v1.0/secrets/{id}.
-// В случае успеха выводит HTTP-статус. В случае ошибки — сообщение об ошибке.
+// Выполняет DELETE-запрос на /v1.0/secrets/{id}. Сервер не удаляет секрет сразу,
+// а перемещает его в корзину, откуда его можно восстановить.
 func DeleteSecret(id uint64, rc *resty.Client) {
 
 	resp, err := rc.R().
@@ -149,7 +277,22 @@ func DeleteSecret(id uint64, rc *resty.Client) {
 		fmt.Println("Ошибка:", err)
 		return
 	}
-	fmt.Println(resp.StatusCode())
+	if resp.IsError() {
+		fmt.Println(resp.StatusCode(), string(resp.Body()))
+		return
+	}
+	fmt.Println("Секрет перемещён в корзину.")
+}
+
+// Confirm запрашивает у пользователя подтверждение действия.
+// Возвращает true, только если пользователь явно ответил «y»/«yes» или «д»/«да».
+func Confirm(label string) bool {
+	switch strings.ToLower(promptInput(label + " [y/N]: ")) {
+	case "y", "yes", "д", "да":
+		return true
+	default:
+		return false
+	}
 }
 
 // promptInput — вспомогательная функция для запроса строки от пользователя через консоль.
@@ -157,8 +300,63 @@ func DeleteSecret(id uint64, rc *resty.Client) {
 // Выводит переданную метку и считывает строку ввода.
 // Возвращает trimmed-значение строки.
 func promptInput(label string) string {
+	return Prompt(label)
+}
+
+// Общий буферизованный читатель консоли. Отдельный сканер на каждый вопрос терял строки,
+// введённые или вставленные заранее: первый сканер забирал их в свой буфер.
+var (
+	stdinMu     sync.Mutex
+	stdinFile   *os.File
+	stdinReader *bufio.Reader
+)
+
+// Prompt выводит метку и считывает строку с консоли через общий буфер.
+// Возвращает trimmed-значение строки; читатель пересоздаётся, если os.Stdin подменён.
+func Prompt(label string) string {
 	fmt.Print(label)
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-	return strings.TrimSpace(scanner.Text())
+
+	stdinMu.Lock()
+	defer stdinMu.Unlock()
+	if stdinFile != os.Stdin {
+		stdinFile = os.Stdin
+		stdinReader = bufio.NewReader(os.Stdin)
+	}
+	line, _ := stdinReader.ReadString('\n')
+	return strings.TrimSpace(line)
+}
+
+// ParseOptionalID разбирает необязательный числовой ID, введённый пользователем.
+// Пустая строка означает отсутствие значения (nil).
+func ParseOptionalID(s string) (*uint64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// parseTags разбирает список тегов, введённых через запятую, отбрасывая пустые значения.
+func parseTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// idempotencyKeyHeader — заголовок, по которому сервер распознаёт повтор уже выполненного запроса.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// newIdempotencyKey возвращает случайный ключ идемпотентности для создающего запроса:
+// повтор запроса с тем же ключом после обрыва связи не создаст секрет второй раз.
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
 }
@@ -1,10 +1,12 @@
 package client
 
 import (
-	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/shekshuev/gophkeeper/internal/models"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -61,41 +63,108 @@ func TestListSecrets(t *testing.T) {
 		client := resty.New()
 		client.SetTransport(&mockRoundTripper{
 			statusCode: 200,
-			body:       `[{"id":1,"title":"First"},{"id":2,"title":"Second"}]`,
+			body:       `{"items":[{"id":1,"title":"First"},{"id":2,"title":"Second","type":"login","tags":["work","vpn"]}]}`,
 		})
 
 		output := CaptureOutput(func() {
-			ListSecrets(client, func() (string, error) {
-				return "42", nil
-			})
+			ListSecrets(client, models.SecretFilterDTO{})
 		})
 
 		assert.Contains(t, output, "1  First")
-		assert.Contains(t, output, "2  Second")
+		assert.Contains(t, output, "2  Second  (login)  [work, vpn]")
 	})
 
-	t.Run("Unauthorized", func(t *testing.T) {
+	t.Run("Follows_next", func(t *testing.T) {
+		var paths, queries []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			queries = append(queries, r.URL.RawQuery)
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Query().Get("cursor") == "" {
+				_, _ = w.Write([]byte(`{"items":[{"id":1,"title":"First"}],"next":"/v1.0/secrets?limit=100&q=fi&cursor=abc"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"items":[{"id":2,"title":"Fifth"}]}`))
+		}))
+		defer server.Close()
+
 		output := CaptureOutput(func() {
-			ListSecrets(resty.New(), func() (string, error) {
-				return "", fmt.Errorf("unauthorized")
-			})
+			ListSecrets(resty.New().SetBaseURL(server.URL), models.SecretFilterDTO{Query: "fi"})
 		})
 
-		assert.Contains(t, output, "Ошибка авторизации")
+		assert.Equal(t, []string{"/v1.0/secrets", "/v1.0/secrets"}, paths)
+		assert.Equal(t, []string{"limit=100&q=fi", "limit=100&q=fi&cursor=abc"}, queries)
+		assert.Contains(t, output, "1  First")
+		assert.Contains(t, output, "2  Fifth")
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		var query string
+		client := resty.New()
+		client.SetTransport(&mockRoundTripper{statusCode: 200, body: `{"items":[]}`})
+		client.OnBeforeRequest(func(_ *resty.Client, r *resty.Request) error {
+			query = r.QueryParam.Encode()
+			return nil
+		})
+		folderID := uint64(3)
+
+		output := CaptureOutput(func() {
+			ListSecrets(client, models.SecretFilterDTO{FolderID: &folderID, Tag: "work", Type: "card"})
+		})
+
+		assert.Equal(t, "folder_id=3&limit=100&tag=work&type=card", query)
+		assert.Contains(t, output, "Секретов нет")
+	})
+
+	t.Run("ServerError", func(t *testing.T) {
+		client := newMockClient(400, `{"error":"invalid query parameters"}`)
+		output := CaptureOutput(func() {
+			ListSecrets(client, models.SecretFilterDTO{})
+		})
+		assert.Contains(t, output, "400")
 	})
 
 	t.Run("HttpError", func(t *testing.T) {
 		client := resty.New()
 		client.SetTransport(&errorRoundTripper{})
 		output := CaptureOutput(func() {
-			ListSecrets(client, func() (string, error) {
-				return "42", nil
-			})
+			ListSecrets(client, models.SecretFilterDTO{})
 		})
 		assert.Contains(t, output, "Ошибка запроса")
 	})
 }
 
+func TestSecretAudit(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		client := newMockClient(200, `[{"id":1,"secret_id":7,"action":"reveal","ip":"10.0.0.1","user_agent":"cli","created_at":"2025-07-16T00:00:00Z"}]`)
+
+		output := CaptureOutput(func() {
+			SecretAudit(7, client)
+		})
+
+		assert.Contains(t, output, "reveal  10.0.0.1  cli")
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		output := CaptureOutput(func() {
+			SecretAudit(7, newMockClient(200, `[]`))
+		})
+
+		assert.Contains(t, output, "Записей нет")
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		client := resty.New()
+		client.SetTransport(&errorRoundTripper{})
+
+		output := CaptureOutput(func() {
+			SecretAudit(7, client)
+		})
+
+		assert.Contains(t, output, "Ошибка:")
+	})
+}
+
 func TestDeleteSecret(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		client := newMockClient(204, ``)
@@ -104,7 +173,15 @@ func TestDeleteSecret(t *testing.T) {
 			DeleteSecret(123, client)
 		})
 
-		assert.Contains(t, output, "204")
+		assert.Contains(t, output, "перемещён в корзину")
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		output := CaptureOutput(func() {
+			DeleteSecret(123, newMockClient(404, `{"error":"secret not found"}`))
+		})
+
+		assert.Contains(t, output, "404")
 	})
 
 	t.Run("Error", func(t *testing.T) {
@@ -187,3 +264,76 @@ func TestCreateSecret(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateSecret(t *testing.T) {
+	tcs := []struct {
+		name   string
+		inputs []string
+		status int
+		body   string
+		expect string
+	}{
+		{
+			name:   "Text",
+			inputs: []string{"1", "new text"},
+			status: 200,
+			body:   `{"id":7,"version":3}`,
+			expect: "версия 3",
+		},
+		{
+			name:   "NotFound",
+			inputs: []string{"1", "new text"},
+			status: 404,
+			body:   `{"error":"secret not found"}`,
+			expect: "404",
+		},
+		{
+			name:   "InvalidType",
+			inputs: []string{"999"},
+			expect: "Неверный тип",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			restore := MockInput(tc.inputs...)
+			defer restore()
+
+			output := CaptureOutput(func() {
+				UpdateSecret(7, "SecretTitle", newMockClient(tc.status, tc.body))
+			})
+
+			assert.Contains(t, output, tc.expect)
+		})
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	for input, expected := range map[string]bool{"y": true, "Да": true, "n": false, "": false} {
+		restore := MockInput(input)
+		var got bool
+		CaptureOutput(func() {
+			got = Confirm("Удалить?")
+		})
+		restore()
+		assert.Equal(t, expected, got, input)
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	assert.Equal(t, []string{"work", "vpn"}, parseTags(" work, ,vpn "))
+	assert.Nil(t, parseTags(""))
+}
+
+func TestParseOptionalID(t *testing.T) {
+	id, err := ParseOptionalID("")
+	assert.NoError(t, err)
+	assert.Nil(t, id)
+
+	id, err = ParseOptionalID("7")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), *id)
+
+	_, err = ParseOptionalID("abc")
+	assert.Error(t, err)
+}
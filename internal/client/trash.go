@@ -0,0 +1,76 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// ListTrash — CLI-обёртка для просмотра корзины.
+//
+// Выполняет GET-запрос на /v1.0/trash и выводит ID, название, время удаления
+// и срок окончательного удаления каждого секрета (без данных).
+func ListTrash(rc *resty.Client) {
+
+	var trash []models.TrashedSecretDTO
+	resp, err := rc.R().
+		SetResult(&trash).
+		Get("/v1.0/trash")
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		return
+	}
+	if resp.IsError() {
+		fmt.Println(resp.StatusCode(), string(resp.Body()))
+		return
+	}
+
+	if len(trash) == 0 {
+		fmt.Println("Корзина пуста.")
+		return
+	}
+	for _, s := range trash {
+		purge := "хранится бессрочно"
+		if s.PurgeAt != nil {
+			purge = "будет удалён " + s.PurgeAt.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Printf("%d  %s  удалён %s, %s\n", s.ID, s.Title, s.DeletedAt.Local().Format("2006-01-02 15:04"), purge)
+	}
+}
+
+// RestoreFromTrash — CLI-обёртка для восстановления секрета из корзины.
+//
+// Выполняет POST-запрос на /v1.0/trash/{id}/restore.
+func RestoreFromTrash(id uint64, rc *resty.Client) {
+
+	resp, err := rc.R().
+		Post(fmt.Sprintf("/v1.0/trash/%d/restore", id))
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		return
+	}
+	if resp.IsError() {
+		fmt.Println(resp.StatusCode(), string(resp.Body()))
+		return
+	}
+	fmt.Println("Секрет восстановлен.")
+}
+
+// PurgeSecret — CLI-обёртка для окончательного удаления секрета из корзины.
+//
+// Выполняет DELETE-запрос на /v1.0/trash/{id}. Восстановить секрет после этого нельзя.
+func PurgeSecret(id uint64, rc *resty.Client) {
+
+	resp, err := rc.R().
+		Delete(fmt.Sprintf("/v1.0/trash/%d", id))
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		return
+	}
+	if resp.IsError() {
+		fmt.Println(resp.StatusCode(), string(resp.Body()))
+		return
+	}
+	fmt.Println("Секрет удалён окончательно.")
+}
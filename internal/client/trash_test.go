@@ -0,0 +1,80 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListTrash(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		client := newMockClient(200, `[{"id":7,"title":"VPN","deleted_at":"2025-07-16T00:00:00Z","purge_at":"2025-08-15T00:00:00Z"},{"id":3,"title":"Note","deleted_at":"2025-07-15T00:00:00Z"}]`)
+
+		output := CaptureOutput(func() {
+			ListTrash(client)
+		})
+
+		assert.Contains(t, output, "7  VPN  удалён")
+		assert.Contains(t, output, "будет удалён 2025-08-1")
+		assert.Contains(t, output, "хранится бессрочно")
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		output := CaptureOutput(func() {
+			ListTrash(newMockClient(200, `[]`))
+		})
+
+		assert.Contains(t, output, "Корзина пуста")
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		client := resty.New()
+		client.SetTransport(&errorRoundTripper{})
+
+		output := CaptureOutput(func() {
+			ListTrash(client)
+		})
+
+		assert.Contains(t, output, "Ошибка:")
+	})
+}
+
+func TestRestoreFromTrash(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		output := CaptureOutput(func() {
+			RestoreFromTrash(7, newMockClient(204, ``))
+		})
+
+		assert.Contains(t, output, "Секрет восстановлен")
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		output := CaptureOutput(func() {
+			RestoreFromTrash(7, newMockClient(404, `{"error":"secret not found"}`))
+		})
+
+		assert.Contains(t, output, "404")
+	})
+}
+
+func TestPurgeSecret(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		output := CaptureOutput(func() {
+			PurgeSecret(7, newMockClient(204, ``))
+		})
+
+		assert.Contains(t, output, "удалён окончательно")
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		client := resty.New()
+		client.SetTransport(&errorRoundTripper{})
+
+		output := CaptureOutput(func() {
+			PurgeSecret(7, client)
+		})
+
+		assert.Contains(t, output, "Ошибка:")
+	})
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// ListVersions — CLI-обёртка для просмотра истории версий секрета.
+//
+// Выполняет GET-запрос на /v1.0/secrets/{id}/versions и выводит номер, время сохранения,
+// устройство автора и название каждой предыдущей версии (без данных).
+func ListVersions(id uint64, rc *resty.Client) {
+
+	var versions []models.SecretVersionDTO
+	resp, err := rc.R().
+		SetResult(&versions).
+		Get(fmt.Sprintf("/v1.0/secrets/%d/versions", id))
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		return
+	}
+	if resp.IsError() {
+		fmt.Println(resp.StatusCode(), string(resp.Body()))
+		return
+	}
+
+	if len(versions) == 0 {
+		fmt.Println("Предыдущих версий нет.")
+		return
+	}
+	for _, v := range versions {
+		device := v.Device
+		if device == "" {
+			device = "-"
+		}
+		fmt.Printf("v%d  %s  %s  %s\n", v.Version, v.CreatedAt.Local().Format("2006-01-02 15:04:05"), device, v.Title)
+	}
+}
+
+// GetSecretVersion — CLI-обёртка для просмотра данных предыдущей версии секрета.
+//
+// Выполняет GET-запрос на /v1.0/secrets/{id}/versions/{version} и выводит отформатированный JSON.
+func GetSecretVersion(id uint64, version int, rc *resty.Client) {
+
+	var v models.ReadSecretVersionDTO
+	resp, err := rc.R().
+		SetResult(&v).
+		Get(fmt.Sprintf("/v1.0/secrets/%d/versions/%d", id, version))
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		return
+	}
+	if resp.IsError() {
+		fmt.Println(resp.StatusCode(), string(resp.Body()))
+		return
+	}
+
+	j, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(j))
+}
+
+// RestoreSecretVersion — CLI-обёртка для восстановления предыдущей версии секрета.
+//
+// Выполняет POST-запрос на /v1.0/secrets/{id}/versions/{version}/restore.
+// Текущее значение секрета при этом сохраняется в истории, поэтому восстановление можно отменить.
+func RestoreSecretVersion(id uint64, version int, rc *resty.Client) {
+
+	var secret models.ReadSecretDTO
+	resp, err := rc.R().
+		SetResult(&secret).
+		Post(fmt.Sprintf("/v1.0/secrets/%d/versions/%d/restore", id, version))
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		return
+	}
+	if resp.IsError() {
+		fmt.Println(resp.StatusCode(), string(resp.Body()))
+		return
+	}
+	fmt.Printf("Версия %d восстановлена, текущая версия секрета — %d\n", version, secret.Version)
+}
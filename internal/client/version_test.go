@@ -0,0 +1,82 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListVersions(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		client := newMockClient(200, `[{"version":2,"title":"VPN","device":"laptop","created_at":"2025-07-16T00:00:00Z"},{"version":1,"title":"VPN old","created_at":"2025-07-15T00:00:00Z"}]`)
+
+		output := CaptureOutput(func() {
+			ListVersions(7, client)
+		})
+
+		assert.Contains(t, output, "v2")
+		assert.Contains(t, output, "laptop  VPN")
+		assert.Contains(t, output, "-  VPN old")
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		output := CaptureOutput(func() {
+			ListVersions(7, newMockClient(200, `[]`))
+		})
+
+		assert.Contains(t, output, "Предыдущих версий нет")
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		client := resty.New()
+		client.SetTransport(&errorRoundTripper{})
+
+		output := CaptureOutput(func() {
+			ListVersions(7, client)
+		})
+
+		assert.Contains(t, output, "Ошибка:")
+	})
+}
+
+func TestGetSecretVersion(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		client := newMockClient(200, `{"secret_id":7,"version":2,"title":"VPN","data":{"text":"old"}}`)
+
+		output := CaptureOutput(func() {
+			GetSecretVersion(7, 2, client)
+		})
+
+		assert.Contains(t, output, `"text": "old"`)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		output := CaptureOutput(func() {
+			GetSecretVersion(7, 9, newMockClient(404, `{"error":"secret version not found"}`))
+		})
+
+		assert.Contains(t, output, "404")
+	})
+}
+
+func TestRestoreSecretVersion(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		output := CaptureOutput(func() {
+			RestoreSecretVersion(7, 2, newMockClient(200, `{"id":7,"version":6}`))
+		})
+
+		assert.Contains(t, output, "Версия 2 восстановлена, текущая версия секрета — 6")
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		client := resty.New()
+		client.SetTransport(&errorRoundTripper{})
+
+		output := CaptureOutput(func() {
+			RestoreSecretVersion(7, 2, client)
+		})
+
+		assert.Contains(t, output, "Ошибка:")
+	})
+}
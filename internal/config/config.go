@@ -8,14 +8,101 @@ import (
 	"go.uber.org/zap"
 )
 
+// Хранилища данных сервера (см. Config.Storage).
+const (
+	StorageDatabase = "database" // База данных из DatabaseDSN: PostgreSQL или SQLite
+	StorageMemory   = "memory"   // Память процесса: данные теряются при остановке сервера
+)
+
+// Режимы проверки сертификатов устройств (см. Config.DeviceCertMode).
+const (
+	DeviceCertOff      = "off"      // Сертификаты устройствам не выпускаются
+	DeviceCertOptional = "optional" // Сертификат выпускается устройству, приславшему запрос на сертификат при входе
+	DeviceCertRequired = "required" // Без сертификата устройства вход и запросы с access-токеном отклоняются
+)
+
 // Config содержит настройки приложения, включая параметры сервера, базы данных и токенов авторизации.
 type Config struct {
 	// ServerAddress — адрес и порт, на котором запускается сервер (например, "localhost:8080").
 	ServerAddress string `env:"SERVER_ADDRESS"`
 
+	// TLSCertFile и TLSKeyFile — сертификат (PEM, может содержать цепочку) и закрытый ключ сервера.
+	// Файлы перечитываются при замене; если их нет, сервер не запускается (см. TLSSelfSigned).
+	// Если пути не заданы, самоподписанный сертификат создаётся в памяти при каждом запуске.
+	TLSCertFile string `env:"TLS_CERT_FILE"`
+	TLSKeyFile  string `env:"TLS_KEY_FILE"`
+
+	// TLSSelfSigned — режим разработки: если файлов TLSCertFile и TLSKeyFile нет, сервер выпускает
+	// самоподписанный сертификат и сохраняет его по этим путям.
+	TLSSelfSigned bool `env:"TLS_SELF_SIGNED"`
+
+	// TLSReloadInterval — как часто сервер проверяет, не заменены ли файлы сертификата (0 — не проверять).
+	TLSReloadInterval time.Duration `env:"TLS_RELOAD_INTERVAL" envDefault:"1m"`
+
+	// TLSHosts — имена и IP-адреса, на которые выпускается самоподписанный сертификат (через запятую).
+	TLSHosts []string `env:"TLS_HOSTS" envSeparator:"," envDefault:"localhost,127.0.0.1"`
+
+	// TLSCAFile — сертификаты центров сертификации (PEM), по которым клиент проверяет сервер вместо системных.
+	TLSCAFile string `env:"TLS_CA_FILE"`
+
+	// TLSPinnedFingerprints — отпечатки SHA-256 сертификата сервера, которым доверяет клиент (через запятую).
+	// Без TLSCAFile совпадение отпечатка заменяет проверку цепочки сертификатов.
+	TLSPinnedFingerprints []string `env:"TLS_PINNED_FINGERPRINTS" envSeparator:","`
+
+	// DeviceCertMode — взаимная аутентификация TLS устройств: DeviceCertOff (по умолчанию), DeviceCertOptional
+	// или DeviceCertRequired. Токены, выданные вместе с сертификатом устройства, принимаются только от этого устройства.
+	DeviceCertMode string `env:"DEVICE_CERT_MODE" envDefault:"off"`
+
+	// DeviceCACertFile и DeviceCAKeyFile — сертификат и ключ центра сертификации, которым сервер подписывает
+	// сертификаты устройств. Если файлов нет, сервер создаёт центр сертификации и сохраняет его по этим путям;
+	// если пути не заданы, центр сертификации создаётся в памяти и выданные сертификаты теряют силу при перезапуске.
+	DeviceCACertFile string `env:"DEVICE_CA_CERT_FILE"`
+	DeviceCAKeyFile  string `env:"DEVICE_CA_KEY_FILE"`
+
+	// DeviceCertValidity — срок действия сертификата устройства; после него устройство входит заново.
+	DeviceCertValidity time.Duration `env:"DEVICE_CERT_VALIDITY" envDefault:"2160h"`
+
+	// DeviceCertFile и DeviceKeyFile — где клиент хранит сертификат и закрытый ключ своего устройства.
+	// Если задан DeviceKeyFile, клиент при входе просит сервер выпустить сертификат для этого ключа.
+	DeviceCertFile string `env:"DEVICE_CERT_FILE"`
+	DeviceKeyFile  string `env:"DEVICE_KEY_FILE"`
+
+	// AdminUsers — логины администраторов, которым доступны сертификаты устройств всех пользователей (через запятую).
+	AdminUsers []string `env:"ADMIN_USERS" envSeparator:","`
+
+	// GRPCAddress — адрес и порт gRPC API (например, "localhost:9090"). Пусто — gRPC API не запускается.
+	GRPCAddress string `env:"GRPC_ADDRESS"`
+
+	// Storage — где сервер хранит данные: StorageDatabase (по умолчанию) или StorageMemory для демонстраций
+	// и быстрых интеграционных тестов. Задаётся также флагом сервера --storage.
+	Storage string `env:"STORAGE" envDefault:"database"`
+
 	// DatabaseDSN — строка подключения к базе данных (например, "host=localhost user=postgres dbname=gophkeeper sslmode=disable").
+	// Строка со схемой sqlite:// выбирает SQLite: "sqlite:///var/lib/gophkeeper/data.db".
+	// Схему SQLite сервер создаёт и обновляет сам при запуске.
 	DatabaseDSN string `env:"DATABASE_DSN"`
 
+	// DatabaseMaxOpenConns — максимальное число открытых соединений в пуле (0 — без ограничения).
+	DatabaseMaxOpenConns int `env:"DATABASE_MAX_OPEN_CONNS" envDefault:"20"`
+
+	// DatabaseMaxIdleConns — сколько простаивающих соединений пул держит открытыми.
+	DatabaseMaxIdleConns int `env:"DATABASE_MAX_IDLE_CONNS" envDefault:"10"`
+
+	// DatabaseConnMaxLifetime — через сколько соединение закрывается и заменяется новым (0 — бессрочно).
+	DatabaseConnMaxLifetime time.Duration `env:"DATABASE_CONN_MAX_LIFETIME" envDefault:"30m"`
+
+	// DatabaseConnMaxIdleTime — через сколько простоя соединение закрывается (0 — бессрочно).
+	DatabaseConnMaxIdleTime time.Duration `env:"DATABASE_CONN_MAX_IDLE_TIME" envDefault:"5m"`
+
+	// DatabaseStatementTimeout — statement_timeout каждого соединения: запросы дольше прерываются базой (0 — без ограничения).
+	DatabaseStatementTimeout time.Duration `env:"DATABASE_STATEMENT_TIMEOUT" envDefault:"30s"`
+
+	// DatabaseTxMaxAttempts — сколько раз выполняется транзакция, прерванная конфликтом сериализации или взаимной блокировкой.
+	DatabaseTxMaxAttempts int `env:"DATABASE_TX_MAX_ATTEMPTS" envDefault:"3"`
+
+	// DatabaseTxRetryDelay — пауза перед повтором транзакции; с каждой попыткой растёт линейно.
+	DatabaseTxRetryDelay time.Duration `env:"DATABASE_TX_RETRY_DELAY" envDefault:"20ms"`
+
 	// AccessTokenExpires — время жизни access токена (например, "15m", "1h").
 	AccessTokenExpires time.Duration `env:"ACCESS_TOKEN_EXPIRES"`
 
@@ -27,6 +114,70 @@ type Config struct {
 
 	// RefreshTokenSecret — секретный ключ для подписи refresh токенов.
 	RefreshTokenSecret string `env:"REFRESH_TOKEN_SECRET"`
+
+	// SecretVersionsRetention — сколько предыдущих версий каждого секрета хранить (0 — без ограничения).
+	SecretVersionsRetention int `env:"SECRET_VERSIONS_RETENTION" envDefault:"10"`
+
+	// TrashRetention — сколько удалённые секреты хранятся в корзине до окончательного удаления (0 — бессрочно).
+	TrashRetention time.Duration `env:"TRASH_RETENTION" envDefault:"720h"`
+
+	// SecretChangesRetention — сколько хранится журнал изменений секретов, по которому ленты изменений
+	// догоняют пропущенное после переподключения (0 — бессрочно). Очищается вместе с корзиной.
+	SecretChangesRetention time.Duration `env:"SECRET_CHANGES_RETENTION" envDefault:"720h"`
+
+	// TrashPurgeInterval — как часто сервер удаляет из корзины секреты с истёкшим сроком хранения.
+	TrashPurgeInterval time.Duration `env:"TRASH_PURGE_INTERVAL" envDefault:"1h"`
+
+	// IdempotencyKeyTTL — сколько сервер помнит ключ Idempotency-Key и повторяет сохранённый ответ на повторные запросы.
+	IdempotencyKeyTTL time.Duration `env:"IDEMPOTENCY_KEY_TTL" envDefault:"24h"`
+
+	// IdempotencyLease — сколько ключ остаётся за запросом, ответ на который ещё не сохранён. Если сервер
+	// остановился посреди запроса, по истечении этого срока запрос с тем же ключом выполняется заново.
+	// Должен быть больше времени выполнения самого долгого запроса.
+	IdempotencyLease time.Duration `env:"IDEMPOTENCY_LEASE" envDefault:"5m"`
+
+	// WebhookMaxAttempts — сколько раз сервер пытается доставить событие вебхуку, прежде чем пометить доставку неудачной.
+	WebhookMaxAttempts int `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+
+	// WebhookPollInterval — как часто сервер проверяет очередь доставок вебхуков.
+	WebhookPollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL" envDefault:"5s"`
+
+	// WebhookTimeout — сколько сервер ждёт ответа получателя вебхука.
+	WebhookTimeout time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+
+	// LoginFailureBurst — после скольких неудачных попыток входа за LoginFailureWindow отправляется событие login.failure_burst.
+	LoginFailureBurst int `env:"LOGIN_FAILURE_BURST" envDefault:"5"`
+
+	// LoginFailureWindow — окно, в котором считаются неудачные попытки входа.
+	LoginFailureWindow time.Duration `env:"LOGIN_FAILURE_WINDOW" envDefault:"15m"`
+
+	// DefaultFolders — папки, которые создаются каждому новому пользователю при регистрации (через запятую).
+	DefaultFolders []string `env:"DEFAULT_FOLDERS" envSeparator:","`
+
+	// ReadinessTimeout — сколько проверка готовности (/ready) ждёт ответа базы данных.
+	ReadinessTimeout time.Duration `env:"READINESS_TIMEOUT" envDefault:"2s"`
+
+	// DatabaseConnectAttempts — сколько раз сервер при старте пытается дождаться базы данных, прежде чем завершиться.
+	DatabaseConnectAttempts int `env:"DATABASE_CONNECT_ATTEMPTS" envDefault:"10"`
+
+	// DatabaseConnectInterval — пауза между попытками подключиться к базе данных при старте.
+	DatabaseConnectInterval time.Duration `env:"DATABASE_CONNECT_INTERVAL" envDefault:"3s"`
+
+	// LogLevel — минимальный уровень записей журнала: "debug", "info", "warn" или "error".
+	LogLevel string `env:"LOG_LEVEL" envDefault:"info"`
+
+	// LogFormat — формат журнала: "json" (по умолчанию) или "console" для чтения человеком.
+	LogFormat string `env:"LOG_FORMAT" envDefault:"json"`
+
+	// LogFile — файл, в который пишется журнал. Пусто — стандартный поток ошибок.
+	LogFile string `env:"LOG_FILE"`
+
+	// TracingExporter — куда отправлять спаны OpenTelemetry: "otlp", "stdout" или пусто (трассировка выключена).
+	TracingExporter string `env:"TRACING_EXPORTER"`
+
+	// TracingEndpoint — адрес коллектора OTLP/HTTP (например, "http://localhost:4318").
+	// Пусто — используются стандартные переменные OTEL_EXPORTER_OTLP_*.
+	TracingEndpoint string `env:"TRACING_ENDPOINT"`
 }
 
 // GetConfig загружает конфигурацию из переменных окружения.
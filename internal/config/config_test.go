@@ -35,8 +35,20 @@ func TestGetConfig(t *testing.T) {
 	cfg := GetConfig()
 	assert.Equal(t, serverAddress, cfg.ServerAddress)
 	assert.Equal(t, databaseDSN, cfg.DatabaseDSN)
+	assert.Equal(t, StorageDatabase, cfg.Storage)
+	assert.Equal(t, time.Minute, cfg.TLSReloadInterval)
+	assert.False(t, cfg.TLSSelfSigned)
+	assert.Equal(t, []string{"localhost", "127.0.0.1"}, cfg.TLSHosts)
+	assert.Equal(t, DeviceCertOff, cfg.DeviceCertMode)
+	assert.Equal(t, 90*24*time.Hour, cfg.DeviceCertValidity)
 	assert.Equal(t, 15*time.Minute, cfg.AccessTokenExpires)
 	assert.Equal(t, 30*24*time.Hour, cfg.RefreshTokenExpires)
 	assert.Equal(t, accessTokenSecret, cfg.AccessTokenSecret)
 	assert.Equal(t, refreshTokenSecret, cfg.RefreshTokenSecret)
+	assert.Equal(t, 10, cfg.SecretVersionsRetention)
+	assert.Equal(t, 720*time.Hour, cfg.TrashRetention)
+	assert.Equal(t, 720*time.Hour, cfg.SecretChangesRetention)
+	assert.Equal(t, time.Hour, cfg.TrashPurgeInterval)
+	assert.Equal(t, 24*time.Hour, cfg.IdempotencyKeyTTL)
+	assert.Equal(t, 5*time.Minute, cfg.IdempotencyLease)
 }
@@ -0,0 +1,104 @@
+// Package database создаёт общий для всех репозиториев пул соединений с PostgreSQL или SQLite.
+package database
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/stdlib"
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/metrics"
+)
+
+// SQLiteScheme — схема строки подключения, выбирающая SQLite вместо PostgreSQL.
+const SQLiteScheme = "sqlite://"
+
+// SQLiteTimeLayout — формат, в котором SQLite хранит время: UTC с указанием смещения.
+// В нём же время записывает драйвер (параметр _time_format=sqlite) и возвращает функция now().
+const SQLiteTimeLayout = "2006-01-02 15:04:05.999999999-07:00"
+
+// sqliteDriver — имя драйвера SQLite (modernc.org/sqlite) в database/sql.
+const sqliteDriver = "sqlite"
+
+// IsSQLite сообщает, выбирает ли строка подключения dsn базу SQLite.
+func IsSQLite(dsn string) bool {
+	return strings.HasPrefix(dsn, SQLiteScheme)
+}
+
+// Open создаёт пул соединений с базой из cfg.DatabaseDSN и настраивает его:
+// число открытых и простаивающих соединений, время их жизни и statement_timeout каждого соединения.
+// Пул один на сервер; его передают во все репозитории и закрывают при остановке сервера.
+// Соединения открываются лениво, доступность базы проверяет HealthService.WaitForDatabase.
+// Строка подключения со схемой sqlite:// открывает файл базы SQLite (см. openSQLite).
+func Open(cfg *config.Config) (*sql.DB, error) {
+	if IsSQLite(cfg.DatabaseDSN) {
+		return openSQLite(cfg)
+	}
+	driverConfig := &stdlib.DriverConfig{ConnConfig: pgx.ConnConfig{RuntimeParams: runtimeParams(cfg)}}
+	stdlib.RegisterDriverConfig(driverConfig)
+
+	db, err := sql.Open("pgx", driverConfig.ConnectionString(cfg.DatabaseDSN))
+	if err != nil {
+		stdlib.UnregisterDriverConfig(driverConfig)
+		return nil, err
+	}
+	configurePool(db, cfg)
+
+	logger.NewLogger().Log.Info("Создан пул соединений с базой данных",
+		zap.Int("max_open", cfg.DatabaseMaxOpenConns),
+		zap.Int("max_idle", cfg.DatabaseMaxIdleConns),
+		zap.Duration("statement_timeout", cfg.DatabaseStatementTimeout))
+	return db, nil
+}
+
+// openSQLite открывает базу SQLite по пути из cfg.DatabaseDSN (после схемы sqlite://).
+// Каждое соединение включает внешние ключи и журнал WAL и ждёт освобождения блокировки до 5 секунд;
+// транзакции сразу берут блокировку записи, поэтому одновременные транзакции выполняются по очереди.
+// statement_timeout в SQLite не поддерживается.
+func openSQLite(cfg *config.Config) (*sql.DB, error) {
+	db, err := sql.Open(sqliteDriver, sqliteDSN(cfg.DatabaseDSN))
+	if err != nil {
+		return nil, err
+	}
+	configurePool(db, cfg)
+
+	logger.NewLogger().Log.Info("Открыта база данных SQLite",
+		zap.String("path", strings.TrimPrefix(cfg.DatabaseDSN, SQLiteScheme)),
+		zap.Int("max_open", cfg.DatabaseMaxOpenConns))
+	return db, nil
+}
+
+// sqliteDSN преобразует строку подключения sqlite://<путь>[?параметры] в строку драйвера SQLite.
+// Параметры из исходной строки сохраняются и дополняются настройками соединения.
+func sqliteDSN(dsn string) string {
+	path, query, _ := strings.Cut(strings.TrimPrefix(dsn, SQLiteScheme), "?")
+	params := "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite&_txlock=immediate"
+	if query != "" {
+		params = query + "&" + params
+	}
+	return "file:" + path + "?" + params
+}
+
+// configurePool применяет к пулу настройки из cfg и регистрирует его метрики.
+func configurePool(db *sql.DB, cfg *config.Config) {
+	db.SetMaxOpenConns(cfg.DatabaseMaxOpenConns)
+	db.SetMaxIdleConns(cfg.DatabaseMaxIdleConns)
+	db.SetConnMaxLifetime(cfg.DatabaseConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.DatabaseConnMaxIdleTime)
+	metrics.RegisterDB("gophkeeper", db)
+}
+
+// runtimeParams возвращает параметры сессии, которые устанавливаются на каждом новом соединении.
+// Параметры, явно указанные в DSN, имеют приоритет.
+func runtimeParams(cfg *config.Config) map[string]string {
+	params := map[string]string{"application_name": "gophkeeper"}
+	if cfg.DatabaseStatementTimeout > 0 {
+		params["statement_timeout"] = strconv.FormatInt(cfg.DatabaseStatementTimeout.Milliseconds(), 10)
+	}
+	return params
+}
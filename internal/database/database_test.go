@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+)
+
+func TestOpen(t *testing.T) {
+	db, err := Open(&config.Config{
+		DatabaseDSN:          "host=localhost user=test dbname=test sslmode=disable",
+		DatabaseMaxOpenConns: 7,
+	})
+	require.NoError(t, err)
+	defer db.Close()
+
+	assert.Equal(t, 7, db.Stats().MaxOpenConnections)
+}
+
+func TestRuntimeParams(t *testing.T) {
+	assert.Equal(t, map[string]string{"application_name": "gophkeeper", "statement_timeout": "1500"},
+		runtimeParams(&config.Config{DatabaseStatementTimeout: 1500 * time.Millisecond}))
+	assert.NotContains(t, runtimeParams(&config.Config{}), "statement_timeout")
+}
+
+func TestIsSQLite(t *testing.T) {
+	assert.True(t, IsSQLite("sqlite:///var/lib/gophkeeper/data.db"))
+	assert.False(t, IsSQLite("postgres://user@localhost/gophkeeper"))
+	assert.False(t, IsSQLite("host=localhost user=test dbname=test"))
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	const params = "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite&_txlock=immediate"
+	assert.Equal(t, "file:/var/lib/gophkeeper/data.db?"+params, sqliteDSN("sqlite:///var/lib/gophkeeper/data.db"))
+	assert.Equal(t, "file:data.db?mode=rwc&"+params, sqliteDSN("sqlite://data.db?mode=rwc"))
+}
+
+func TestOpen_SQLite(t *testing.T) {
+	db, err := Open(&config.Config{DatabaseDSN: SQLiteScheme + filepath.Join(t.TempDir(), "data.db"), DatabaseMaxOpenConns: 1})
+	require.NoError(t, err)
+	defer db.Close()
+
+	var now string
+	require.NoError(t, db.QueryRow("select now()").Scan(&now))
+	parsed, err := time.Parse(SQLiteTimeLayout, now)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), parsed, time.Minute)
+
+	var foreignKeys int
+	require.NoError(t, db.QueryRow("pragma foreign_keys").Scan(&foreignKeys))
+	assert.Equal(t, 1, foreignKeys)
+}
+
+func TestMigrateSQLite(t *testing.T) {
+	db, err := Open(&config.Config{DatabaseDSN: SQLiteScheme + filepath.Join(t.TempDir(), "data.db"), DatabaseMaxOpenConns: 1})
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	require.NoError(t, MigrateSQLite(ctx, db))
+	var (
+		version uint
+		dirty   bool
+	)
+	require.NoError(t, db.QueryRow("select version, dirty from schema_migrations").Scan(&version, &dirty))
+	assert.Equal(t, uint(3), version)
+	assert.False(t, dirty)
+
+	t.Run("Already_applied", func(t *testing.T) {
+		require.NoError(t, MigrateSQLite(ctx, db))
+		var count int
+		require.NoError(t, db.QueryRow("select count(*) from schema_migrations").Scan(&count))
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("Dirty", func(t *testing.T) {
+		_, err := db.Exec("update schema_migrations set dirty = true")
+		require.NoError(t, err)
+		assert.EqualError(t, MigrateSQLite(ctx, db), "migration 3 is dirty")
+	})
+}
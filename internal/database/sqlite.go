@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"modernc.org/sqlite"
+
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/migrations"
+)
+
+// init регистрирует в драйвере SQLite функцию now(): запросы репозиториев пишутся для обеих баз
+// и используют её, как в PostgreSQL. Время возвращается в UTC в формате SQLiteTimeLayout,
+// поэтому его можно сравнивать со значениями, записанными драйвером.
+func init() {
+	sqlite.MustRegisterScalarFunction("now", 0, func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error) {
+		return time.Now().UTC().Format(SQLiteTimeLayout), nil
+	})
+}
+
+// MigrateSQLite применяет к базе SQLite встроенные миграции (migrations.SQLite), которые ещё не применены.
+// Версия схемы хранится в таблице schema_migrations в формате golang-migrate, поэтому её читает
+// проверка готовности. Каждая миграция выполняется в своей транзакции вместе с записью новой версии.
+// Возвращает ошибку, если предыдущая миграция прервана (dirty) или очередная миграция не применилась.
+func MigrateSQLite(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, "create table if not exists schema_migrations (version bigint not null primary key, dirty boolean not null);"); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	var (
+		current uint64
+		dirty   bool
+	)
+	err := db.QueryRowContext(ctx, "select version, dirty from schema_migrations limit 1;").Scan(&current, &dirty)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", current)
+	}
+
+	files, err := fs.Glob(migrations.SQLite, "sqlite/*.up.sql")
+	if err != nil {
+		return err
+	}
+	log := logger.NewLogger()
+	for _, file := range files {
+		name := strings.TrimPrefix(file, "sqlite/")
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return fmt.Errorf("migration %s: invalid version: %w", name, err)
+		}
+		if version <= current {
+			continue
+		}
+		schema, err := fs.ReadFile(migrations.SQLite, file)
+		if err != nil {
+			return err
+		}
+		if err := applySQLiteMigration(ctx, db, version, string(schema)); err != nil {
+			return fmt.Errorf("migration %s: %w", name, err)
+		}
+		log.Log.Info("Применена миграция SQLite", zap.String("migration", name))
+		current = version
+	}
+	return nil
+}
+
+// applySQLiteMigration выполняет миграцию schema и записывает её версию в одной транзакции.
+func applySQLiteMigration(ctx context.Context, db *sql.DB, version uint64, schema string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, schema); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "delete from schema_migrations;"); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "insert into schema_migrations (version, dirty) values ($1, false);", version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,114 @@
+// Package events рассылает изменения секретов подписчикам.
+// Сервис секретов публикует события после успешных изменений, а потоковые API
+// (gRPC WatchSecrets, SSE GET /v1.0/secrets/events) подписываются на события своего пользователя.
+// Broker доставляет события внутри процесса, RelayBroker — всем экземплярам сервера через Relay.
+//
+// Relay — общий канал сообщений между экземплярами сервера: через него, кроме изменений секретов,
+// рассылаются отзывы сертификатов устройств, чтобы экземпляры сбросили их из кэша.
+// LocalRelay работает внутри процесса, PostgresRelay — через Postgres LISTEN/NOTIFY.
+package events
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// Publisher публикует изменения секретов.
+type Publisher interface {
+	// Publish рассылает событие подписчикам владельца секрета. Не блокируется.
+	Publish(event models.SecretEventDTO)
+}
+
+// Subscriber подписывает на изменения секретов пользователя.
+type Subscriber interface {
+	// Subscribe возвращает канал событий пользователя и функцию отмены подписки.
+	// Если подписчик не успевает читать события, канал закрывается: подписчик должен
+	// перечитать изменения из базы и подписаться заново.
+	Subscribe(userID uint64) (<-chan models.SecretEventDTO, func())
+}
+
+// subscriberBuffer — сколько непрочитанных событий может накопить подписчик, прежде чем его канал будет закрыт.
+const subscriberBuffer = 64
+
+// subscription — подписка одного клиента.
+type subscription struct {
+	ch     chan models.SecretEventDTO
+	closed bool
+}
+
+// Broker реализует Publisher и Subscriber в памяти процесса.
+type Broker struct {
+	mu     sync.Mutex
+	subs   map[uint64]map[*subscription]struct{} // Подписки по ID пользователя
+	logger *logger.Logger
+}
+
+// NewBroker создаёт брокер событий без подписчиков.
+func NewBroker() *Broker {
+	return &Broker{
+		subs:   make(map[uint64]map[*subscription]struct{}),
+		logger: logger.NewLogger(),
+	}
+}
+
+// Publish рассылает событие всем подписчикам пользователя.
+// Подписчик с переполненным буфером отключается, чтобы медленный клиент не задерживал остальных.
+func (b *Broker) Publish(event models.SecretEventDTO) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subs[event.UserID] {
+		select {
+		case sub.ch <- event:
+		default:
+			b.logger.Log.Warn("Подписчик не успевает читать события и отключён", zap.Uint64("user_id", event.UserID))
+			b.remove(event.UserID, sub)
+		}
+	}
+}
+
+// Subscribe подписывает на события пользователя.
+func (b *Broker) Subscribe(userID uint64) (<-chan models.SecretEventDTO, func()) {
+	sub := &subscription{ch: make(chan models.SecretEventDTO, subscriberBuffer)}
+
+	b.mu.Lock()
+	if b.subs[userID] == nil {
+		b.subs[userID] = make(map[*subscription]struct{})
+	}
+	b.subs[userID][sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub.ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(userID, sub)
+	}
+}
+
+// Reset отключает всех подписчиков. Вызывается, когда часть событий могла быть потеряна:
+// подписчики, как и при переполнении буфера, перечитывают изменения из базы и подписываются заново.
+func (b *Broker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for userID, subs := range b.subs {
+		for sub := range subs {
+			b.remove(userID, sub)
+		}
+	}
+}
+
+// remove удаляет подписку и закрывает её канал. Вызывается под b.mu.
+func (b *Broker) remove(userID uint64, sub *subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.ch)
+	delete(b.subs[userID], sub)
+	if len(b.subs[userID]) == 0 {
+		delete(b.subs, userID)
+	}
+}
@@ -0,0 +1,60 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+func TestBroker_PublishSubscribe(t *testing.T) {
+	broker := NewBroker()
+	events, cancel := broker.Subscribe(1)
+	other, cancelOther := broker.Subscribe(2)
+	defer cancelOther()
+
+	broker.Publish(models.SecretEventDTO{Type: models.SecretEventCreated, UserID: 1, SecretID: 10})
+
+	assert.Equal(t, uint64(10), (<-events).SecretID)
+	assert.Empty(t, other)
+
+	cancel()
+	_, ok := <-events
+	assert.False(t, ok)
+	cancel()
+	broker.Publish(models.SecretEventDTO{UserID: 1})
+}
+
+func TestBroker_SlowSubscriber(t *testing.T) {
+	broker := NewBroker()
+	events, cancel := broker.Subscribe(1)
+	defer cancel()
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		broker.Publish(models.SecretEventDTO{UserID: 1, SecretID: uint64(i)})
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received)
+	assert.Empty(t, broker.subs)
+}
+
+func TestBroker_Reset(t *testing.T) {
+	broker := NewBroker()
+	first, cancelFirst := broker.Subscribe(1)
+	defer cancelFirst()
+	second, cancelSecond := broker.Subscribe(2)
+	defer cancelSecond()
+
+	broker.Reset()
+
+	_, ok := <-first
+	assert.False(t, ok)
+	_, ok = <-second
+	assert.False(t, ok)
+	assert.Empty(t, broker.subs)
+}
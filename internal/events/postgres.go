@@ -0,0 +1,177 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx"
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+)
+
+// NotifyChannel — канал Postgres LISTEN/NOTIFY, через который экземпляры сервера обмениваются сообщениями.
+const NotifyChannel = "gophkeeper_events"
+
+// Параметры доставки сообщений через Postgres.
+const (
+	outboxSize        = 1024             // Сколько сообщений может ждать отправки в Postgres
+	notifyTimeout     = 5 * time.Second  // Таймаут отправки одного сообщения
+	reconnectDelayMin = time.Second      // Пауза перед первой попыткой переподключения слушателя
+	reconnectDelayMax = 30 * time.Second // Максимальная пауза между попытками переподключения
+)
+
+// listenConn — соединение, подписанное на NotifyChannel. Реализуется *pgx.Conn.
+type listenConn interface {
+	WaitForNotification(ctx context.Context) (*pgx.Notification, error)
+	Close() error
+}
+
+// PostgresRelay реализует Relay поверх Postgres LISTEN/NOTIFY.
+//
+// Publish ставит сообщение в очередь, Run отправляет его командой pg_notify и одновременно слушает канал:
+// каждый экземпляр, в том числе отправивший, получает сообщение из Postgres и передаёт его своим обработчикам.
+// Если отправить сообщение не удалось, оно доставляется только обработчикам этого экземпляра.
+// После потери соединения слушатель переподключается и передаёт обработчикам сообщение KindReconnect.
+type PostgresRelay struct {
+	*handlers
+	db     *sql.DB                    // Соединение для pg_notify
+	dial   func() (listenConn, error) // Открывает соединение для LISTEN
+	outbox chan Message               // Сообщения, ожидающие отправки
+}
+
+// NewPostgresRelay создаёт Relay поверх общего пула соединений db: через него отправляется pg_notify,
+// а для LISTEN открывается отдельное соединение по cfg.DatabaseDSN. Доставка начинается после запуска Run.
+func NewPostgresRelay(db *sql.DB, cfg *config.Config) *PostgresRelay {
+	return newPostgresRelay(db, func() (listenConn, error) {
+		return listen(cfg.DatabaseDSN)
+	})
+}
+
+// newPostgresRelay создаёт Relay с заданными соединениями.
+func newPostgresRelay(db *sql.DB, dial func() (listenConn, error)) *PostgresRelay {
+	return &PostgresRelay{
+		handlers: newHandlers(),
+		db:       db,
+		dial:     dial,
+		outbox:   make(chan Message, outboxSize),
+	}
+}
+
+// listen открывает отдельное соединение с базой и подписывает его на NotifyChannel.
+func listen(dsn string) (listenConn, error) {
+	connConfig, err := pgx.ParseConnectionString(dsn)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := pgx.Connect(connConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Listen(NotifyChannel); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Publish ставит сообщение в очередь отправки в Postgres. Не блокируется:
+// если очередь заполнена, сообщение доставляется только обработчикам этого экземпляра.
+func (r *PostgresRelay) Publish(kind string, payload any) {
+	msg, err := newMessage(kind, payload)
+	if err != nil {
+		r.logger.Log.Error("Не удалось закодировать сообщение", zap.String("kind", kind), zap.Error(err))
+		return
+	}
+	select {
+	case r.outbox <- msg:
+	default:
+		r.logger.Log.Warn("Очередь отправки сообщений переполнена, сообщение доставлено только локально", zap.String("kind", kind))
+		r.dispatch(msg)
+	}
+}
+
+// Run отправляет сообщения из очереди и слушает канал NotifyChannel, пока ctx не отменён.
+func (r *PostgresRelay) Run(ctx context.Context) {
+	go r.send(ctx)
+	r.receive(ctx)
+}
+
+// send отправляет сообщения из очереди командой pg_notify.
+func (r *PostgresRelay) send(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-r.outbox:
+			if err := r.notify(ctx, msg); err != nil {
+				r.logger.Log.Error("Не удалось отправить сообщение другим экземплярам, сообщение доставлено только локально", zap.String("kind", msg.Kind), zap.Error(err))
+				r.dispatch(msg)
+			}
+		}
+	}
+}
+
+// notify отправляет одно сообщение в NotifyChannel.
+func (r *PostgresRelay) notify(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	_, err = r.db.ExecContext(ctx, "select pg_notify($1, $2)", NotifyChannel, string(payload))
+	return err
+}
+
+// receive слушает NotifyChannel и переподключается с растущей паузой, если соединение потеряно.
+func (r *PostgresRelay) receive(ctx context.Context) {
+	delay := reconnectDelayMin
+	connected := false
+	for {
+		conn, err := r.dial()
+		if err == nil {
+			if connected {
+				r.logger.Log.Info("Соединение с каналом сообщений восстановлено, обработчики сбрасывают состояние")
+				r.dispatch(Message{Kind: KindReconnect})
+			}
+			connected = true
+			delay = reconnectDelayMin
+			err = r.consume(ctx, conn)
+			conn.Close()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		r.logger.Log.Warn("Нет соединения с каналом сообщений", zap.Duration("retry_in", delay), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, reconnectDelayMax)
+	}
+}
+
+// consume передаёт уведомления из соединения обработчикам до первой ошибки.
+func (r *PostgresRelay) consume(ctx context.Context, conn listenConn) error {
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var msg Message
+		if err := json.Unmarshal([]byte(notification.Payload), &msg); err != nil {
+			r.logger.Log.Warn("Некорректное сообщение в канале", zap.Error(err))
+			continue
+		}
+		if msg.Kind == KindReconnect {
+			r.logger.Log.Warn("Пропущено служебное сообщение из канала", zap.String("kind", msg.Kind))
+			continue
+		}
+		r.dispatch(msg)
+	}
+}
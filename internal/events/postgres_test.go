@@ -0,0 +1,141 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// fakeConn — соединение LISTEN, уведомления в которое подаются через канал.
+// Закрытие канала имитирует разрыв соединения.
+type fakeConn struct {
+	notifications chan *pgx.Notification
+}
+
+func (c *fakeConn) WaitForNotification(ctx context.Context) (*pgx.Notification, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case n, ok := <-c.notifications:
+		if !ok {
+			return nil, errors.New("connection lost")
+		}
+		return n, nil
+	}
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func TestPostgresRelay_Publish(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	relay := newPostgresRelay(db, nil)
+	received := make(chan json.RawMessage, 1)
+	unsubscribe := relay.Subscribe(KindDeviceRevoked, func(payload json.RawMessage) { received <- payload })
+	defer unsubscribe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go relay.send(ctx)
+
+	notifyQuery := regexp.QuoteMeta("select pg_notify($1, $2)")
+
+	t.Run("Notify", func(t *testing.T) {
+		mock.ExpectExec(notifyQuery).
+			WithArgs(NotifyChannel, `{"kind":"device_revoked","payload":{"serial":"0a"}}`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		relay.Publish(KindDeviceRevoked, models.DeviceRevokedDTO{Serial: "0a"})
+
+		assert.Eventually(t, func() bool { return mock.ExpectationsWereMet() == nil }, time.Second, 10*time.Millisecond)
+		assert.Empty(t, received, "message must come back through LISTEN, not directly")
+	})
+
+	t.Run("Notify_error_delivers_locally", func(t *testing.T) {
+		mock.ExpectExec(notifyQuery).WillReturnError(errors.New("connection refused"))
+
+		relay.Publish(KindDeviceRevoked, models.DeviceRevokedDTO{Serial: "0b"})
+
+		select {
+		case payload := <-received:
+			assert.JSONEq(t, `{"serial":"0b"}`, string(payload))
+		case <-time.After(time.Second):
+			t.Fatal("message was not delivered locally")
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPostgresRelay_Receive(t *testing.T) {
+	first := &fakeConn{notifications: make(chan *pgx.Notification, 4)}
+	second := &fakeConn{notifications: make(chan *pgx.Notification)}
+	var (
+		mu    sync.Mutex
+		dials int
+	)
+	relay := newPostgresRelay(nil, func() (listenConn, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		dials++
+		switch dials {
+		case 1:
+			return first, nil
+		case 2:
+			return nil, errors.New("connection refused")
+		default:
+			return second, nil
+		}
+	})
+	broker := NewRelayBroker(relay)
+	feed, unsubscribe := broker.Subscribe(1)
+	defer unsubscribe()
+	other, unsubscribeOther := broker.Subscribe(2)
+	defer unsubscribeOther()
+
+	first.notifications <- &pgx.Notification{Payload: `not json`}
+	first.notifications <- &pgx.Notification{Payload: `{"kind":"session_revoked","payload":{"user_id":1}}`}
+	first.notifications <- &pgx.Notification{Payload: `{"kind":"reconnect"}`}
+	first.notifications <- &pgx.Notification{Payload: `{"kind":"secret","payload":{"user_id":1,"event":{"id":13,"type":"updated","secret_id":7,"version":3,"time":"2025-01-01T00:00:00Z"}}}`}
+	close(first.notifications)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relay.receive(ctx)
+		close(done)
+	}()
+
+	event := <-feed
+	assert.Equal(t, models.SecretEventDTO{ID: 13, Type: models.SecretEventUpdated, UserID: 1, SecretID: 7, Version: 3, Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}, event)
+	assert.Empty(t, other)
+
+	// После разрыва и неудачной попытки слушатель переподключается, и брокер отключает подписчиков.
+	select {
+	case _, ok := <-feed:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscribers were not reset after reconnect")
+	}
+	_, ok := <-other
+	assert.False(t, ok)
+
+	cancel()
+	<-done
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 3, dials)
+}
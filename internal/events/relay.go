@@ -0,0 +1,164 @@
+package events
+
+import (
+	"encoding/json"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// Виды сообщений, которыми обмениваются экземпляры сервера через Relay. Экземпляр, получивший сообщение
+// неизвестного вида (например, от более новой версии сервера), пропускает его.
+const (
+	KindSecret        = "secret"         // Изменение секрета (см. RelayBroker)
+	KindDeviceRevoked = "device_revoked" // Отзыв сертификата устройства (models.DeviceRevokedDTO)
+
+	// KindReconnect — сообщение, которое Relay передаёт только обработчикам своего экземпляра после
+	// восстановления связи с другими экземплярами. Сообщения за время разрыва могли быть потеряны,
+	// поэтому обработчики сбрасывают состояние, которое могло устареть. Данных у сообщения нет.
+	KindReconnect = "reconnect"
+)
+
+// Message — сообщение, которым обмениваются экземпляры сервера: вид и данные в JSON.
+type Message struct {
+	Kind    string          `json:"kind"`              // Вид сообщения (см. Kind*)
+	Payload json.RawMessage `json:"payload,omitempty"` // Данные сообщения; их формат определяется видом
+}
+
+// Relay рассылает сообщения всем экземплярам сервера, в том числе отправившему.
+// Через него экземпляры согласуют состояние в памяти: ленты изменений, кэши, ограничители.
+type Relay interface {
+	// Publish рассылает сообщение вида kind с данными payload в JSON. Не блокируется.
+	Publish(kind string, payload any)
+	// Subscribe вызывает handler для каждого сообщения вида kind, пока не вызвана возвращённая функция отмены.
+	// Обработчики вызываются по одному в порядке получения сообщений и не должны блокироваться.
+	Subscribe(kind string, handler func(payload json.RawMessage)) func()
+}
+
+// handler — обработчик сообщений одного вида.
+type handler struct {
+	fn func(payload json.RawMessage)
+}
+
+// handlers — обработчики сообщений по видам. Общая часть реализаций Relay.
+type handlers struct {
+	mu     sync.RWMutex
+	byKind map[string]map[*handler]struct{}
+	logger *logger.Logger
+}
+
+// newHandlers создаёт пустой набор обработчиков.
+func newHandlers() *handlers {
+	return &handlers{
+		byKind: make(map[string]map[*handler]struct{}),
+		logger: logger.NewLogger(),
+	}
+}
+
+// Subscribe добавляет обработчик сообщений вида kind.
+func (h *handlers) Subscribe(kind string, fn func(payload json.RawMessage)) func() {
+	sub := &handler{fn: fn}
+	h.mu.Lock()
+	if h.byKind[kind] == nil {
+		h.byKind[kind] = make(map[*handler]struct{})
+	}
+	h.byKind[kind][sub] = struct{}{}
+	h.mu.Unlock()
+
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.byKind[kind], sub)
+		if len(h.byKind[kind]) == 0 {
+			delete(h.byKind, kind)
+		}
+	}
+}
+
+// dispatch передаёт сообщение обработчикам его вида.
+func (h *handlers) dispatch(msg Message) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	subs, ok := h.byKind[msg.Kind]
+	if !ok {
+		h.logger.Log.Debug("Пропущено сообщение без обработчиков", zap.String("kind", msg.Kind))
+		return
+	}
+	for sub := range subs {
+		sub.fn(msg.Payload)
+	}
+}
+
+// newMessage кодирует данные payload в сообщение вида kind.
+func newMessage(kind string, payload any) (Message, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{Kind: kind, Payload: raw}, nil
+}
+
+// LocalRelay реализует Relay внутри одного процесса. Используется, когда экземпляр сервера один
+// (SQLite и хранилище в памяти). Сообщения кодируются в JSON так же, как в PostgresRelay,
+// поэтому обработчики ведут себя одинаково с любой реализацией.
+type LocalRelay struct {
+	*handlers
+}
+
+// NewLocalRelay создаёт Relay без обработчиков, работающий в памяти процесса.
+func NewLocalRelay() *LocalRelay {
+	return &LocalRelay{handlers: newHandlers()}
+}
+
+// Publish сразу передаёт сообщение обработчикам этого процесса.
+func (r *LocalRelay) Publish(kind string, payload any) {
+	msg, err := newMessage(kind, payload)
+	if err != nil {
+		r.logger.Log.Error("Не удалось закодировать сообщение", zap.String("kind", kind), zap.Error(err))
+		return
+	}
+	r.dispatch(msg)
+}
+
+// secretMessage — данные сообщения KindSecret. ID владельца передаётся отдельно,
+// потому что в JSON-представлении события его нет.
+type secretMessage struct {
+	UserID uint64                `json:"user_id"` // Владелец секрета
+	Event  models.SecretEventDTO `json:"event"`   // Изменение секрета
+}
+
+// RelayBroker рассылает изменения секретов подписчикам всех экземпляров сервера через Relay.
+// Publish отправляет событие сообщением KindSecret, а полученные сообщения передаются локальному Broker.
+// После KindReconnect все подписчики отключаются, чтобы перечитать из базы изменения, пропущенные за время разрыва.
+type RelayBroker struct {
+	*Broker
+	relay Relay
+}
+
+// NewRelayBroker создаёт брокер событий поверх relay и подписывает его на сообщения KindSecret и KindReconnect.
+func NewRelayBroker(relay Relay) *RelayBroker {
+	b := &RelayBroker{Broker: NewBroker(), relay: relay}
+	relay.Subscribe(KindSecret, b.receive)
+	relay.Subscribe(KindReconnect, func(json.RawMessage) { b.Broker.Reset() })
+	return b
+}
+
+// Publish рассылает событие всем экземплярам сервера.
+func (b *RelayBroker) Publish(event models.SecretEventDTO) {
+	b.relay.Publish(KindSecret, secretMessage{UserID: event.UserID, Event: event})
+}
+
+// receive передаёт полученное изменение секрета подписчикам этого экземпляра.
+func (b *RelayBroker) receive(payload json.RawMessage) {
+	var msg secretMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		b.logger.Log.Warn("Некорректное сообщение об изменении секрета", zap.Error(err))
+		return
+	}
+	event := msg.Event
+	event.UserID = msg.UserID
+	b.Broker.Publish(event)
+}
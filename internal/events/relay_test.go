@@ -0,0 +1,51 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+func TestLocalRelay_PublishSubscribe(t *testing.T) {
+	relay := NewLocalRelay()
+	var revoked, other []string
+	unsubscribe := relay.Subscribe(KindDeviceRevoked, func(payload json.RawMessage) {
+		var msg models.DeviceRevokedDTO
+		assert.NoError(t, json.Unmarshal(payload, &msg))
+		revoked = append(revoked, msg.Serial)
+	})
+	relay.Subscribe("other", func(payload json.RawMessage) { other = append(other, string(payload)) })
+
+	relay.Publish(KindDeviceRevoked, models.DeviceRevokedDTO{Serial: "0a"})
+	relay.Publish("unknown", map[string]int{"a": 1})
+	unsubscribe()
+	relay.Publish(KindDeviceRevoked, models.DeviceRevokedDTO{Serial: "0b"})
+	relay.Publish(KindDeviceRevoked, func() {})
+
+	assert.Equal(t, []string{"0a"}, revoked)
+	assert.Empty(t, other)
+}
+
+func TestRelayBroker(t *testing.T) {
+	relay := NewLocalRelay()
+	broker := NewRelayBroker(relay)
+	feed, unsubscribe := broker.Subscribe(1)
+	defer unsubscribe()
+	other, unsubscribeOther := broker.Subscribe(2)
+	defer unsubscribeOther()
+
+	broker.Publish(models.SecretEventDTO{ID: 5, Type: models.SecretEventCreated, UserID: 1, SecretID: 10})
+	relay.Publish(KindSecret, "not an event")
+
+	assert.Equal(t, models.SecretEventDTO{ID: 5, Type: models.SecretEventCreated, UserID: 1, SecretID: 10}, <-feed)
+	assert.Empty(t, other)
+
+	relay.Publish(KindReconnect, nil)
+	_, ok := <-feed
+	assert.False(t, ok)
+	_, ok = <-other
+	assert.False(t, ok)
+}
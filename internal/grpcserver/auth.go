@@ -0,0 +1,81 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/service"
+	"github.com/shekshuev/gophkeeper/pkg/pb"
+)
+
+// authServer реализует pb.AuthServiceServer.
+type authServer struct {
+	pb.UnimplementedAuthServiceServer
+	base
+	auth service.AuthService
+}
+
+// Login проверяет логин и пароль пользователя и возвращает пару токенов.
+// Если в поле csr передан запрос на сертификат устройства, выпущенный сертификат возвращается
+// в поле client_certificate, а токены привязываются к нему.
+//
+// Возвращает коды:
+//   - InvalidArgument — если данные не прошли валидацию, запрос на сертификат невалиден или сервер его требует
+//   - Unauthenticated — если пользователь не найден или пароль неверный
+func (s *authServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.TokenPair, error) {
+	dto := models.LoginUserDTO{UserName: req.UserName, Password: req.Password, CSR: string(req.Csr)}
+	if err := s.validate.Struct(dto); err != nil {
+		s.logger.For(ctx).Warn("Ошибка валидации входных данных", zap.Error(err))
+		return nil, status.Error(codes.InvalidArgument, ErrValidationError.Error())
+	}
+
+	meta := requestMeta(ctx)
+	dto.Device, dto.IP = meta.Device, meta.IP
+	tokens, err := s.auth.Login(ctx, dto)
+	if errors.Is(err, service.ErrUserNotFound) || errors.Is(err, service.ErrWrongPassword) {
+		s.logger.For(ctx).Warn("Ошибка входа пользователя", zap.String("user_name", dto.UserName), zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка входа пользователя", zap.String("user_name", dto.UserName), zap.Error(err))
+		return nil, statusError(err)
+	}
+	return &pb.TokenPair{
+		AccessToken:       tokens.AccessToken,
+		RefreshToken:      tokens.RefreshToken,
+		ClientCertificate: []byte(tokens.ClientCertificate),
+	}, nil
+}
+
+// Register создаёт пользователя и возвращает пару токенов.
+//
+// Возвращает коды:
+//   - InvalidArgument — если данные не прошли валидацию
+//   - AlreadyExists — если пользователь с таким логином уже есть
+func (s *authServer) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.TokenPair, error) {
+	dto := models.RegisterUserDTO{
+		UserName:        req.UserName,
+		Password:        req.Password,
+		PasswordConfirm: req.PasswordConfirm,
+		FirstName:       req.FirstName,
+		LastName:        req.LastName,
+	}
+	if err := s.validate.Struct(dto); err != nil {
+		s.logger.For(ctx).Warn("Ошибка валидации данных при регистрации", zap.Error(err))
+		return nil, status.Error(codes.InvalidArgument, ErrValidationError.Error())
+	}
+
+	meta := requestMeta(ctx)
+	dto.Device, dto.IP = meta.Device, meta.IP
+	tokens, err := s.auth.Register(ctx, dto)
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка регистрации пользователя", zap.String("user_name", dto.UserName), zap.Error(err))
+		return nil, statusError(err)
+	}
+	return &pb.TokenPair{AccessToken: tokens.AccessToken, RefreshToken: tokens.RefreshToken}, nil
+}
@@ -0,0 +1,124 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/shekshuev/gophkeeper/internal/service"
+	"github.com/shekshuev/gophkeeper/pkg/pb"
+)
+
+func TestAuthServer_Login(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auth := mocks.NewMockAuthService(ctrl)
+	conn, _, _ := startServer(t, nil, auth, nil, nil, nil)
+	client := pb.NewAuthServiceClient(conn)
+	req := &pb.LoginRequest{UserName: "user_1", Password: "Passw0rd!"}
+
+	t.Run("Success", func(t *testing.T) {
+		auth.EXPECT().
+			Login(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, dto models.LoginUserDTO) (*models.ReadTokenDTO, error) {
+				assert.Equal(t, "user_1", dto.UserName)
+				assert.Equal(t, "Passw0rd!", dto.Password)
+				assert.Equal(t, "laptop", dto.Device)
+				return &models.ReadTokenDTO{AccessToken: "access", RefreshToken: "refresh"}, nil
+			})
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), DeviceMetadataKey, "laptop")
+		resp, err := client.Login(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, "access", resp.AccessToken)
+		assert.Equal(t, "refresh", resp.RefreshToken)
+	})
+
+	t.Run("Device_certificate", func(t *testing.T) {
+		auth.EXPECT().
+			Login(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, dto models.LoginUserDTO) (*models.ReadTokenDTO, error) {
+				assert.Equal(t, "csr", dto.CSR)
+				return &models.ReadTokenDTO{AccessToken: "access", RefreshToken: "refresh", ClientCertificate: "cert"}, nil
+			})
+
+		resp, err := client.Login(context.Background(), &pb.LoginRequest{UserName: "user_1", Password: "Passw0rd!", Csr: []byte("csr")})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("cert"), resp.ClientCertificate)
+	})
+
+	t.Run("Invalid_CSR", func(t *testing.T) {
+		auth.EXPECT().Login(gomock.Any(), gomock.Any()).Return(nil, service.ErrInvalidCSR)
+		_, err := client.Login(context.Background(), &pb.LoginRequest{UserName: "user_1", Password: "Passw0rd!", Csr: []byte("garbage")})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Wrong_password", func(t *testing.T) {
+		auth.EXPECT().Login(gomock.Any(), gomock.Any()).Return(nil, service.ErrWrongPassword)
+		_, err := client.Login(context.Background(), req)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("User_not_found", func(t *testing.T) {
+		auth.EXPECT().Login(gomock.Any(), gomock.Any()).Return(nil, service.ErrUserNotFound)
+		_, err := client.Login(context.Background(), req)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Validation_error", func(t *testing.T) {
+		_, err := client.Login(context.Background(), &pb.LoginRequest{UserName: "u"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestAuthServer_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auth := mocks.NewMockAuthService(ctrl)
+	conn, _, _ := startServer(t, nil, auth, nil, nil, nil)
+	client := pb.NewAuthServiceClient(conn)
+	req := &pb.RegisterRequest{
+		UserName:        "user_1",
+		Password:        "Passw0rd!",
+		PasswordConfirm: "Passw0rd!",
+		FirstName:       "John",
+		LastName:        "Doe",
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		auth.EXPECT().Register(gomock.Any(), gomock.Any()).Return(&models.ReadTokenDTO{AccessToken: "access"}, nil)
+		resp, err := client.Register(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, "access", resp.AccessToken)
+	})
+
+	t.Run("User_exists", func(t *testing.T) {
+		auth.EXPECT().Register(gomock.Any(), gomock.Any()).Return(nil, repository.ErrUserExists)
+		_, err := client.Register(context.Background(), req)
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	})
+
+	t.Run("Service_error", func(t *testing.T) {
+		auth.EXPECT().Register(gomock.Any(), gomock.Any()).Return(nil, errors.New("db error"))
+		_, err := client.Register(context.Background(), req)
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+
+	t.Run("Passwords_mismatch", func(t *testing.T) {
+		_, err := client.Register(context.Background(), &pb.RegisterRequest{
+			UserName: "user_1", Password: "Passw0rd!", PasswordConfirm: "Other0rd!", FirstName: "John", LastName: "Doe",
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
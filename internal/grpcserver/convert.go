@@ -0,0 +1,112 @@
+package grpcserver
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/pkg/pb"
+)
+
+// secretDataFromPB преобразует данные секрета из protobuf в DTO.
+func secretDataFromPB(data *pb.SecretData) models.SecretDataDTO {
+	var dto models.SecretDataDTO
+	if data == nil {
+		return dto
+	}
+	if lp := data.GetLoginPassword(); lp != nil {
+		dto.LoginPassword = &models.LoginPasswordData{Login: lp.Login, Password: lp.Password, URL: lp.Url, Notes: lp.Notes}
+	}
+	dto.Text = data.Text
+	dto.Binary = data.Binary
+	if card := data.GetCard(); card != nil {
+		dto.Card = &models.CardData{Number: card.Number, Holder: card.Holder, ExpireDate: card.ExpireDate, CVV: card.Cvv}
+	}
+	return dto
+}
+
+// secretDataToPB преобразует данные секрета из DTO в protobuf.
+func secretDataToPB(dto models.SecretDataDTO) *pb.SecretData {
+	data := &pb.SecretData{Text: dto.Text, Binary: dto.Binary}
+	if lp := dto.LoginPassword; lp != nil {
+		data.LoginPassword = &pb.LoginPassword{Login: lp.Login, Password: lp.Password, Url: lp.URL, Notes: lp.Notes}
+	}
+	if card := dto.Card; card != nil {
+		data.Card = &pb.Card{Number: card.Number, Holder: card.Holder, ExpireDate: card.ExpireDate, Cvv: card.CVV}
+	}
+	return data
+}
+
+// secretToPB преобразует секрет с данными в protobuf.
+func secretToPB(dto *models.ReadSecretDTO) *pb.Secret {
+	return &pb.Secret{
+		Id:        dto.ID,
+		Title:     dto.Title,
+		Data:      secretDataToPB(dto.Data),
+		FolderId:  dto.FolderID,
+		Tags:      dto.Tags,
+		Version:   int32(dto.Version),
+		CreatedAt: timestamppb.New(dto.CreatedAt),
+		UpdatedAt: timestamppb.New(dto.UpdatedAt),
+	}
+}
+
+// secretSummaryToPB преобразует метаданные секрета в protobuf.
+func secretSummaryToPB(dto models.SecretSummaryDTO) *pb.SecretSummary {
+	return &pb.SecretSummary{
+		Id:        dto.ID,
+		Title:     dto.Title,
+		Type:      dto.Type,
+		FolderId:  dto.FolderID,
+		Tags:      dto.Tags,
+		Version:   int32(dto.Version),
+		CreatedAt: timestamppb.New(dto.CreatedAt),
+		UpdatedAt: timestamppb.New(dto.UpdatedAt),
+	}
+}
+
+// userToPB преобразует пользователя в protobuf.
+func userToPB(dto *models.ReadUserDTO) *pb.User {
+	return &pb.User{
+		Id:        dto.ID,
+		UserName:  dto.UserName,
+		FirstName: dto.FirstName,
+		LastName:  dto.LastName,
+		CreatedAt: timestamppb.New(dto.CreatedAt),
+		UpdatedAt: timestamppb.New(dto.UpdatedAt),
+	}
+}
+
+// deviceToPB преобразует сертификат устройства в protobuf.
+func deviceToPB(dto *models.DeviceCertificateDTO) *pb.Device {
+	device := &pb.Device{
+		Id:         dto.ID,
+		Device:     dto.Device,
+		Serial:     dto.Serial,
+		Thumbprint: dto.Thumbprint,
+		CreatedAt:  timestamppb.New(dto.CreatedAt),
+		ExpiresAt:  timestamppb.New(dto.ExpiresAt),
+	}
+	if dto.RevokedAt != nil {
+		device.RevokedAt = timestamppb.New(*dto.RevokedAt)
+	}
+	return device
+}
+
+// eventTypes сопоставляет типы событий ленты изменений с protobuf.
+var eventTypes = map[string]pb.SecretEvent_Type{
+	models.SecretEventCreated:  pb.SecretEvent_TYPE_CREATED,
+	models.SecretEventUpdated:  pb.SecretEvent_TYPE_UPDATED,
+	models.SecretEventDeleted:  pb.SecretEvent_TYPE_DELETED,
+	models.SecretEventRestored: pb.SecretEvent_TYPE_RESTORED,
+	models.SecretEventPurged:   pb.SecretEvent_TYPE_PURGED,
+}
+
+// eventToPB преобразует событие ленты изменений в protobuf.
+func eventToPB(event models.SecretEventDTO) *pb.SecretEvent {
+	return &pb.SecretEvent{
+		Type:     eventTypes[event.Type],
+		SecretId: event.SecretID,
+		Version:  int32(event.Version),
+		Time:     timestamppb.New(event.Time),
+	}
+}
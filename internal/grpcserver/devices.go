@@ -0,0 +1,60 @@
+package grpcserver
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/shekshuev/gophkeeper/internal/service"
+	"github.com/shekshuev/gophkeeper/pkg/pb"
+)
+
+// deviceServer реализует pb.DeviceServiceServer.
+type deviceServer struct {
+	pb.UnimplementedDeviceServiceServer
+	base
+	devices service.DeviceService
+}
+
+// ListDevices возвращает сертификаты устройств пользователя из токена, начиная с новых.
+//
+// Возвращает коды:
+//   - Internal — если ошибка на уровне сервиса
+func (s *deviceServer) ListDevices(ctx context.Context, _ *emptypb.Empty) (*pb.ListDevicesResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := s.devices.GetAllByUser(ctx, userID)
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка при получении сертификатов устройств", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, statusError(err)
+	}
+	resp := &pb.ListDevicesResponse{Items: make([]*pb.Device, 0, len(devices))}
+	for i := range devices {
+		resp.Items = append(resp.Items, deviceToPB(&devices[i]))
+	}
+	return resp, nil
+}
+
+// RevokeDevice отзывает сертификат одного из устройств пользователя из токена.
+//
+// Возвращает коды:
+//   - NotFound — если сертификат не найден или выдан другому пользователю
+func (s *deviceServer) RevokeDevice(ctx context.Context, req *pb.RevokeDeviceRequest) (*pb.Device, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	device, err := s.devices.RevokeByUser(ctx, userID, req.Serial)
+	if err != nil {
+		s.logger.For(ctx).Warn("Ошибка при отзыве сертификата устройства", zap.String("serial", req.Serial), zap.Error(err))
+		return nil, statusError(err)
+	}
+
+	s.logger.For(ctx).Info("Пользователь отозвал сертификат устройства", zap.String("serial", req.Serial))
+	return deviceToPB(device), nil
+}
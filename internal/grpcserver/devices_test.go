@@ -0,0 +1,81 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/service"
+	"github.com/shekshuev/gophkeeper/pkg/pb"
+)
+
+func TestDeviceServer_ListDevices(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	devices := mocks.NewMockDeviceService(ctrl)
+	conn, _, cfg := startServer(t, nil, nil, nil, devices, nil)
+	client := pb.NewDeviceServiceClient(conn)
+	ctx := authorized(t, cfg, "42")
+
+	t.Run("Success", func(t *testing.T) {
+		now := time.Now().UTC()
+		devices.EXPECT().GetAllByUser(gomock.Any(), uint64(42)).Return([]models.DeviceCertificateDTO{
+			{ID: 2, UserID: 42, Device: "phone", Serial: "0b", CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+			{ID: 1, UserID: 42, Device: "laptop", Serial: "0a", CreatedAt: now, ExpiresAt: now.Add(time.Hour), RevokedAt: &now},
+		}, nil)
+
+		resp, err := client.ListDevices(ctx, &emptypb.Empty{})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Items, 2)
+		assert.Equal(t, "phone", resp.Items[0].Device)
+		assert.Nil(t, resp.Items[0].RevokedAt)
+		assert.Equal(t, now, resp.Items[1].RevokedAt.AsTime())
+	})
+
+	t.Run("Service_error", func(t *testing.T) {
+		devices.EXPECT().GetAllByUser(gomock.Any(), uint64(42)).Return(nil, assert.AnError)
+		_, err := client.ListDevices(ctx, &emptypb.Empty{})
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		_, err := client.ListDevices(context.Background(), &emptypb.Empty{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestDeviceServer_RevokeDevice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	devices := mocks.NewMockDeviceService(ctrl)
+	conn, _, cfg := startServer(t, nil, nil, nil, devices, nil)
+	client := pb.NewDeviceServiceClient(conn)
+	ctx := authorized(t, cfg, "42")
+
+	t.Run("Success", func(t *testing.T) {
+		now := time.Now().UTC()
+		devices.EXPECT().RevokeByUser(gomock.Any(), uint64(42), "0a").
+			Return(&models.DeviceCertificateDTO{ID: 1, UserID: 42, Device: "laptop", Serial: "0a", RevokedAt: &now}, nil)
+
+		device, err := client.RevokeDevice(ctx, &pb.RevokeDeviceRequest{Serial: "0a"})
+		assert.NoError(t, err)
+		assert.Equal(t, "0a", device.Serial)
+		assert.Equal(t, now, device.RevokedAt.AsTime())
+	})
+
+	t.Run("Not_found", func(t *testing.T) {
+		devices.EXPECT().RevokeByUser(gomock.Any(), uint64(42), "ff").Return(nil, service.ErrDeviceCertificateNotFound)
+		_, err := client.RevokeDevice(ctx, &pb.RevokeDeviceRequest{Serial: "ff"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
@@ -0,0 +1,218 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/shekshuev/gophkeeper/internal/events"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/service"
+	"github.com/shekshuev/gophkeeper/internal/utils"
+	"github.com/shekshuev/gophkeeper/pkg/pb"
+)
+
+// secretServer реализует pb.SecretServiceServer.
+type secretServer struct {
+	pb.UnimplementedSecretServiceServer
+	base
+	secrets  service.SecretService
+	events   events.Subscriber
+	shutdown <-chan struct{} // Закрывается при остановке сервера
+}
+
+// CreateSecret сохраняет новый секрет пользователя из токена.
+//
+// Возвращает коды:
+//   - InvalidArgument — если данные не прошли валидацию
+//   - NotFound — если указанная папка не найдена
+func (s *secretServer) CreateSecret(ctx context.Context, req *pb.CreateSecretRequest) (*pb.CreateSecretResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dto := models.CreateSecretDTO{
+		UserID:   userID,
+		Title:    req.Title,
+		Data:     secretDataFromPB(req.Data),
+		FolderID: req.FolderId,
+		Tags:     req.Tags,
+		Device:   requestMeta(ctx).Device,
+	}
+	if err := s.validate.Struct(dto); err != nil {
+		s.logger.For(ctx).Warn("Ошибка валидации секрета", zap.Error(err))
+		return nil, status.Error(codes.InvalidArgument, ErrValidationError.Error())
+	}
+
+	id, err := s.secrets.Create(ctx, dto)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.CreateSecretResponse{Id: id}, nil
+}
+
+// GetSecret возвращает секрет пользователя с данными и записывает просмотр в журнал аудита.
+//
+// Возвращает коды:
+//   - NotFound — если секрет не найден или принадлежит другому пользователю
+func (s *secretServer) GetSecret(ctx context.Context, req *pb.GetSecretRequest) (*pb.Secret, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := s.secrets.Reveal(ctx, userID, req.Id, requestMeta(ctx))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return secretToPB(secret), nil
+}
+
+// ListSecrets возвращает страницу метаданных секретов пользователя без полезных данных.
+// Фильтры, сортировка и курсор устроены так же, как в GET /v1.0/secrets.
+//
+// Возвращает коды:
+//   - InvalidArgument — если параметры не прошли валидацию или курсор повреждён
+func (s *secretServer) ListSecrets(ctx context.Context, req *pb.ListSecretsRequest) (*pb.ListSecretsResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filter := models.SecretFilterDTO{
+		FolderID: req.FolderId,
+		Tag:      req.Tag,
+		Query:    req.Query,
+		Type:     req.Type,
+		Sort:     req.Sort,
+		Limit:    int(req.PageSize),
+	}
+	if req.PageToken != "" {
+		var cursor models.SecretCursorDTO
+		if err := utils.DecodeCursor(req.PageToken, &cursor); err != nil {
+			return nil, status.Error(codes.InvalidArgument, service.ErrInvalidCursor.Error())
+		}
+		filter.After = &cursor
+	}
+	if err := s.validate.Struct(filter); err != nil {
+		s.logger.For(ctx).Warn("Невалидные параметры списка секретов", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, status.Error(codes.InvalidArgument, ErrValidationError.Error())
+	}
+
+	page, err := s.secrets.GetSummaryPageByUser(ctx, userID, filter)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	resp := &pb.ListSecretsResponse{NextPageToken: page.NextCursor}
+	for _, item := range page.Items {
+		resp.Items = append(resp.Items, secretSummaryToPB(item))
+	}
+	return resp, nil
+}
+
+// UpdateSecret изменяет секрет пользователя, сохраняя предыдущее состояние в истории версий.
+//
+// Возвращает коды:
+//   - InvalidArgument — если данные не прошли валидацию
+//   - NotFound — если секрет или папка не найдены
+func (s *secretServer) UpdateSecret(ctx context.Context, req *pb.UpdateSecretRequest) (*pb.Secret, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dto := models.UpdateSecretDTO{
+		ID:       req.Id,
+		UserID:   userID,
+		Title:    req.Title,
+		Data:     secretDataFromPB(req.Data),
+		FolderID: req.FolderId,
+		Tags:     req.Tags,
+		Device:   requestMeta(ctx).Device,
+	}
+	if err := s.validate.Struct(dto); err != nil {
+		s.logger.For(ctx).Warn("Ошибка валидации секрета", zap.Error(err))
+		return nil, status.Error(codes.InvalidArgument, ErrValidationError.Error())
+	}
+
+	secret, err := s.secrets.Update(ctx, dto)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return secretToPB(secret), nil
+}
+
+// DeleteSecret перемещает секрет пользователя в корзину.
+//
+// Возвращает коды:
+//   - NotFound — если секрет не найден или уже в корзине
+func (s *secretServer) DeleteSecret(ctx context.Context, req *pb.DeleteSecretRequest) (*emptypb.Empty, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.secrets.DeleteByID(ctx, userID, req.Id); err != nil {
+		return nil, statusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// WatchSecrets передаёт ленту изменений секретов пользователя.
+//
+// Подписка оформляется до чтения базы, поэтому изменения, случившиеся во время выборки, не теряются
+// (но могут прийти дважды). Если задан since, сначала передаются изменения из журнала, записанные
+// не раньше этого момента (см. SecretService.GetChangesSince), затем — новые.
+//
+// Возвращает коды:
+//   - Aborted — если клиент не успевает читать ленту и должен переподключиться
+//   - Unavailable — если сервер останавливается
+func (s *secretServer) WatchSecrets(req *pb.WatchSecretsRequest, stream pb.SecretService_WatchSecretsServer) error {
+	ctx := stream.Context()
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	feed, unsubscribe := s.events.Subscribe(userID)
+	defer unsubscribe()
+
+	if req.Since != nil {
+		if err := s.sendChangedSince(stream, userID, req.Since.AsTime()); err != nil {
+			return err
+		}
+	}
+
+	s.logger.For(ctx).Info("Клиент подписан на ленту изменений", zap.Uint64("user_id", userID))
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.shutdown:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case event, ok := <-feed:
+			if !ok {
+				return status.Error(codes.Aborted, "change feed overflow, resubscribe")
+			}
+			if err := stream.Send(eventToPB(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendChangedSince передаёт изменения секретов из журнала, записанные не раньше since, по мере чтения журнала.
+func (s *secretServer) sendChangedSince(stream pb.SecretService_WatchSecretsServer, userID uint64, since time.Time) error {
+	var sendErr error
+	err := s.secrets.GetChangesSince(stream.Context(), userID, since, func(event models.SecretEventDTO) error {
+		sendErr = stream.Send(eventToPB(event))
+		return sendErr
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		return statusError(err)
+	}
+	return nil
+}
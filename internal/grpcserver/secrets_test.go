@@ -0,0 +1,257 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/shekshuev/gophkeeper/internal/events"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/service"
+	"github.com/shekshuev/gophkeeper/pkg/pb"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestSecretServer_CreateSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secrets := mocks.NewMockSecretService(ctrl)
+	conn, _, cfg := startServer(t, nil, nil, secrets, nil, nil)
+	client := pb.NewSecretServiceClient(conn)
+	ctx := authorized(t, cfg, "42")
+
+	t.Run("Success", func(t *testing.T) {
+		secrets.EXPECT().Create(gomock.Any(), models.CreateSecretDTO{
+			UserID:   42,
+			Title:    "Mail",
+			Data:     models.SecretDataDTO{LoginPassword: &models.LoginPasswordData{Login: "me", Password: "pass"}},
+			FolderID: ptr(uint64(5)),
+			Tags:     []string{"work"},
+			Device:   "laptop",
+		}).Return(uint64(10), nil)
+
+		resp, err := client.CreateSecret(ctx, &pb.CreateSecretRequest{
+			Title:    "Mail",
+			Data:     &pb.SecretData{LoginPassword: &pb.LoginPassword{Login: "me", Password: "pass"}},
+			FolderId: ptr(uint64(5)),
+			Tags:     []string{"work"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(10), resp.Id)
+	})
+
+	t.Run("Folder_not_found", func(t *testing.T) {
+		secrets.EXPECT().Create(gomock.Any(), gomock.Any()).Return(uint64(0), service.ErrFolderNotFound)
+		_, err := client.CreateSecret(ctx, &pb.CreateSecretRequest{Title: "Mail", FolderId: ptr(uint64(6))})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Validation_error", func(t *testing.T) {
+		_, err := client.CreateSecret(ctx, &pb.CreateSecretRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		_, err := client.CreateSecret(context.Background(), &pb.CreateSecretRequest{Title: "Mail"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestSecretServer_GetUpdateDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secrets := mocks.NewMockSecretService(ctrl)
+	conn, _, cfg := startServer(t, nil, nil, secrets, nil, nil)
+	client := pb.NewSecretServiceClient(conn)
+	ctx := authorized(t, cfg, "42")
+	now := time.Now().UTC()
+	secret := &models.ReadSecretDTO{
+		ID:        7,
+		UserID:    42,
+		Title:     "Card",
+		Data:      models.SecretDataDTO{Card: &models.CardData{Number: "4111", CVV: "123"}, Text: ptr("note")},
+		Tags:      []string{"bank"},
+		Version:   3,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	t.Run("GetSecret", func(t *testing.T) {
+		secrets.EXPECT().
+			Reveal(gomock.Any(), uint64(42), uint64(7), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ uint64, meta models.RequestMetaDTO) (*models.ReadSecretDTO, error) {
+				assert.Equal(t, "laptop", meta.Device)
+				assert.NotEmpty(t, meta.UserAgent)
+				return secret, nil
+			})
+
+		resp, err := client.GetSecret(ctx, &pb.GetSecretRequest{Id: 7})
+		require.NoError(t, err)
+		assert.Equal(t, "4111", resp.Data.Card.Number)
+		assert.Equal(t, "note", resp.Data.GetText())
+		assert.Nil(t, resp.FolderId)
+		assert.Equal(t, int32(3), resp.Version)
+	})
+
+	t.Run("GetSecret_not_found", func(t *testing.T) {
+		secrets.EXPECT().Reveal(gomock.Any(), uint64(42), uint64(8), gomock.Any()).Return(nil, service.ErrSecretNotFound)
+		_, err := client.GetSecret(ctx, &pb.GetSecretRequest{Id: 8})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("UpdateSecret", func(t *testing.T) {
+		secrets.EXPECT().
+			Update(gomock.Any(), models.UpdateSecretDTO{
+				ID:     7,
+				UserID: 42,
+				Title:  "Card",
+				Data:   models.SecretDataDTO{Card: &models.CardData{Number: "4111", CVV: "123"}},
+				Device: "laptop",
+			}).
+			Return(secret, nil)
+
+		resp, err := client.UpdateSecret(ctx, &pb.UpdateSecretRequest{
+			Id:    7,
+			Title: "Card",
+			Data:  &pb.SecretData{Card: &pb.Card{Number: "4111", Cvv: "123"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(7), resp.Id)
+	})
+
+	t.Run("DeleteSecret", func(t *testing.T) {
+		secrets.EXPECT().DeleteByID(gomock.Any(), uint64(42), uint64(7)).Return(nil)
+		_, err := client.DeleteSecret(ctx, &pb.DeleteSecretRequest{Id: 7})
+		assert.NoError(t, err)
+
+		secrets.EXPECT().DeleteByID(gomock.Any(), uint64(42), uint64(8)).Return(service.ErrSecretNotFound)
+		_, err = client.DeleteSecret(ctx, &pb.DeleteSecretRequest{Id: 8})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestSecretServer_ListSecrets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secrets := mocks.NewMockSecretService(ctrl)
+	conn, _, cfg := startServer(t, nil, nil, secrets, nil, nil)
+	client := pb.NewSecretServiceClient(conn)
+	ctx := authorized(t, cfg, "42")
+
+	t.Run("Success", func(t *testing.T) {
+		secrets.EXPECT().
+			GetSummaryPageByUser(gomock.Any(), uint64(42), models.SecretFilterDTO{FolderID: ptr(uint64(0)), Type: "card", Sort: "title", Limit: 2}).
+			Return(&models.SecretSummaryPageDTO{
+				Items:      []models.SecretSummaryDTO{{ID: 1, Title: "A", Type: "card"}, {ID: 2, Title: "B", Type: "card"}},
+				NextCursor: "next",
+			}, nil)
+
+		resp, err := client.ListSecrets(ctx, &pb.ListSecretsRequest{FolderId: ptr(uint64(0)), Type: "card", Sort: "title", PageSize: 2})
+		require.NoError(t, err)
+		assert.Len(t, resp.Items, 2)
+		assert.Equal(t, "next", resp.NextPageToken)
+	})
+
+	t.Run("Invalid_sort", func(t *testing.T) {
+		_, err := client.ListSecrets(ctx, &pb.ListSecretsRequest{Sort: "password"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Invalid_page_token", func(t *testing.T) {
+		_, err := client.ListSecrets(ctx, &pb.ListSecretsRequest{PageToken: "%%%"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestSecretServer_WatchSecrets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secrets := mocks.NewMockSecretService(ctrl)
+	broker := events.NewBroker()
+	conn, _, cfg := startServer(t, nil, nil, secrets, nil, broker)
+	client := pb.NewSecretServiceClient(conn)
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Changes_since_and_live", func(t *testing.T) {
+		secrets.EXPECT().
+			GetChangesSince(gomock.Any(), uint64(42), since, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint64, _ time.Time, send func(models.SecretEventDTO) error) error {
+				for _, change := range []models.SecretEventDTO{
+					{Type: models.SecretEventUpdated, UserID: 42, SecretID: 1, Version: 2, Time: since.Add(time.Hour)},
+					{Type: models.SecretEventCreated, UserID: 42, SecretID: 2, Version: 1, Time: since.Add(2 * time.Hour)},
+				} {
+					if err := send(change); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+
+		ctx, cancel := context.WithCancel(authorized(t, cfg, "42"))
+		defer cancel()
+		stream, err := client.WatchSecrets(ctx, &pb.WatchSecretsRequest{Since: timestamppb.New(since)})
+		require.NoError(t, err)
+
+		event, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, pb.SecretEvent_TYPE_UPDATED, event.Type)
+		assert.Equal(t, uint64(1), event.SecretId)
+
+		event, err = stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, pb.SecretEvent_TYPE_CREATED, event.Type)
+		assert.Equal(t, uint64(2), event.SecretId)
+
+		broker.Publish(models.SecretEventDTO{Type: models.SecretEventCreated, UserID: 7, SecretID: 99})
+		broker.Publish(models.SecretEventDTO{Type: models.SecretEventDeleted, UserID: 42, SecretID: 3, Time: since})
+
+		event, err = stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, pb.SecretEvent_TYPE_DELETED, event.Type)
+		assert.Equal(t, uint64(3), event.SecretId)
+		assert.Equal(t, since, event.Time.AsTime())
+
+		cancel()
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Canceled, status.Code(err))
+	})
+
+	t.Run("Overflow", func(t *testing.T) {
+		stream, err := client.WatchSecrets(authorized(t, cfg, "43"), &pb.WatchSecretsRequest{})
+		require.NoError(t, err)
+		time.Sleep(100 * time.Millisecond)
+
+		for i := 0; i < 10000; i++ {
+			broker.Publish(models.SecretEventDTO{Type: models.SecretEventUpdated, UserID: 43, SecretID: uint64(i)})
+		}
+		for {
+			_, err = stream.Recv()
+			if err != nil {
+				break
+			}
+		}
+		assert.Equal(t, codes.Aborted, status.Code(err))
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		stream, err := client.WatchSecrets(context.Background(), &pb.WatchSecretsRequest{})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
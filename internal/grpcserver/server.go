@@ -0,0 +1,168 @@
+// Package grpcserver реализует gRPC API GophKeeper (см. api/proto/gophkeeper.proto)
+// поверх тех же сервисов, что и REST API в пакете handler.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/events"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/middleware"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/shekshuev/gophkeeper/internal/service"
+	"github.com/shekshuev/gophkeeper/internal/utils"
+	"github.com/shekshuev/gophkeeper/pkg/pb"
+)
+
+// DeviceMetadataKey — ключ метаданных, в котором клиент передаёт идентификатор своего устройства
+// (аналог заголовка X-Device-ID в REST API).
+const DeviceMetadataKey = "x-device-id"
+
+// publicMethods — префиксы методов, не требующих access-токена.
+var publicMethods = []string{"/" + pb.AuthService_ServiceDesc.ServiceName + "/"}
+
+var (
+	ErrValidationError  = errors.New("validation error")
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// Server — gRPC-сервер приложения с сервисами AuthService, UserService, SecretService и DeviceService.
+// Вызовы, кроме AuthService, проверяются перехватчиками middleware.UnaryAuth и middleware.StreamAuth.
+// Каждый вызов трассируется (middleware.UnaryTracing, middleware.StreamTracing) и получает
+// логгер вызова в контексте (middleware.UnaryLogging, middleware.StreamLogging). Сертификат устройства
+// из TLS-соединения сопоставляется с устройством перехватчиками middleware.UnaryDevice и middleware.StreamDevice.
+type Server struct {
+	GRPC     *grpc.Server
+	shutdown chan struct{} // Закрывается при остановке сервера, чтобы завершить открытые ленты изменений
+}
+
+// NewServer создаёт gRPC-сервер и регистрирует на нём все сервисы.
+// Лента WatchSecrets получает изменения из subscriber. Параметры opts дополняют настройки сервера,
+// например задают TLS (grpc.Creds).
+func NewServer(users service.UserService, auth service.AuthService, secrets service.SecretService, devices service.DeviceService, subscriber events.Subscriber, cfg *config.Config, opts ...grpc.ServerOption) *Server {
+	shutdown := make(chan struct{})
+	base := base{validate: utils.NewValidator(), cfg: cfg, logger: logger.NewLogger()}
+	requireDevice := cfg.DeviceCertMode == config.DeviceCertRequired
+	server := grpc.NewServer(append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			middleware.UnaryTracing(), middleware.UnaryLogging(), middleware.UnaryDevice(devices),
+			middleware.UnaryAuth(cfg.AccessTokenSecret, requireDevice, publicMethods...),
+		),
+		grpc.ChainStreamInterceptor(
+			middleware.StreamTracing(), middleware.StreamLogging(), middleware.StreamDevice(devices),
+			middleware.StreamAuth(cfg.AccessTokenSecret, requireDevice, publicMethods...),
+		),
+	}, opts...)...)
+	pb.RegisterAuthServiceServer(server, &authServer{base: base, auth: auth})
+	pb.RegisterUserServiceServer(server, &userServer{base: base, users: users})
+	pb.RegisterSecretServiceServer(server, &secretServer{base: base, secrets: secrets, events: subscriber, shutdown: shutdown})
+	pb.RegisterDeviceServiceServer(server, &deviceServer{base: base, devices: devices})
+	return &Server{GRPC: server, shutdown: shutdown}
+}
+
+// Shutdown завершает открытые ленты изменений и ждёт окончания текущих вызовов.
+// Если ctx истекает раньше, оставшиеся вызовы прерываются.
+func (s *Server) Shutdown(ctx context.Context) {
+	close(s.shutdown)
+	stopped := make(chan struct{})
+	go func() {
+		s.GRPC.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.GRPC.Stop()
+	}
+}
+
+// base — общие зависимости реализаций gRPC-сервисов.
+type base struct {
+	validate *validator.Validate
+	cfg      *config.Config
+	logger   *logger.Logger
+}
+
+// userIDFromContext извлекает ID пользователя из claims, которые добавил перехватчик авторизации.
+func userIDFromContext(ctx context.Context) (uint64, error) {
+	claims, ok := utils.GetClaimsFromContext(ctx)
+	if !ok {
+		return 0, status.Error(codes.Unauthenticated, ErrInvalidToken.Error())
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return 0, status.Error(codes.Unauthenticated, ErrInvalidToken.Error())
+	}
+	return userID, nil
+}
+
+// requestMeta возвращает сведения об источнике вызова для журнала аудита:
+// адрес клиента, user-agent и устройство — из сертификата устройства, а если клиент его не предъявил, из метаданных.
+// Значения обрезаются до размеров колонок в БД.
+func requestMeta(ctx context.Context) models.RequestMetaDTO {
+	var meta models.RequestMetaDTO
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		meta.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(meta.IP); err == nil {
+			meta.IP = host
+		}
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("user-agent"); len(values) > 0 {
+		meta.UserAgent = values[0]
+	}
+	if values := md.Get(DeviceMetadataKey); len(values) > 0 {
+		meta.Device = values[0]
+	}
+	if device, ok := utils.GetDeviceFromContext(ctx); ok {
+		meta.Device = device.Device
+	}
+	meta.IP = truncate(meta.IP, 64)
+	meta.UserAgent = truncate(meta.UserAgent, 255)
+	meta.Device = truncate(meta.Device, 100)
+	return meta
+}
+
+// truncate обрезает строку до n символов.
+func truncate(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
+
+// statusError преобразует ошибку сервиса в gRPC-статус.
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrSecretNotFound),
+		errors.Is(err, service.ErrSecretVersionNotFound),
+		errors.Is(err, service.ErrFolderNotFound),
+		errors.Is(err, service.ErrUserNotFound),
+		errors.Is(err, service.ErrDeviceCertificateNotFound),
+		errors.Is(err, repository.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrWrongPassword):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, repository.ErrUserExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, service.ErrInvalidCursor),
+		errors.Is(err, service.ErrInvalidCSR),
+		errors.Is(err, service.ErrDeviceCertificateRequired):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
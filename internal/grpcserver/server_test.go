@@ -0,0 +1,90 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/events"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/shekshuev/gophkeeper/internal/service"
+	"github.com/shekshuev/gophkeeper/internal/utils"
+	"github.com/shekshuev/gophkeeper/pkg/pb"
+)
+
+// startServer запускает сервер на bufconn и возвращает клиентское соединение.
+func startServer(t *testing.T, users service.UserService, auth service.AuthService, secrets service.SecretService, devices service.DeviceService, subscriber events.Subscriber) (*grpc.ClientConn, *Server, *config.Config) {
+	os.Setenv("ACCESS_TOKEN_SECRET", "test")
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	server := NewServer(users, auth, secrets, devices, subscriber, &cfg)
+
+	listener := bufconn.Listen(1024 * 1024)
+	go func() { _ = server.GRPC.Serve(listener) }()
+	t.Cleanup(server.GRPC.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn, server, &cfg
+}
+
+// authorized добавляет в контекст access-токен пользователя userID и устройство.
+func authorized(t *testing.T, cfg *config.Config, userID string) context.Context {
+	token, err := utils.CreateToken(cfg.AccessTokenSecret, userID, cfg.AccessTokenExpires)
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token, DeviceMetadataKey, "laptop")
+}
+
+func TestStatusError(t *testing.T) {
+	testCases := []struct {
+		err  error
+		code codes.Code
+	}{
+		{service.ErrSecretNotFound, codes.NotFound},
+		{service.ErrFolderNotFound, codes.NotFound},
+		{repository.ErrNotFound, codes.NotFound},
+		{service.ErrWrongPassword, codes.Unauthenticated},
+		{repository.ErrUserExists, codes.AlreadyExists},
+		{service.ErrInvalidCursor, codes.InvalidArgument},
+		{service.ErrInvalidCSR, codes.InvalidArgument},
+		{service.ErrDeviceCertificateRequired, codes.InvalidArgument},
+		{service.ErrDeviceCertificateNotFound, codes.NotFound},
+		{errors.New("db error"), codes.Internal},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.code, status.Code(statusError(tc.err)), tc.err.Error())
+	}
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	broker := events.NewBroker()
+	conn, server, cfg := startServer(t, nil, nil, nil, nil, broker)
+
+	stream, err := pb.NewSecretServiceClient(conn).WatchSecrets(authorized(t, cfg, "42"), &pb.WatchSecretsRequest{})
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	server.Shutdown(ctx)
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
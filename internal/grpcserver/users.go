@@ -0,0 +1,42 @@
+package grpcserver
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/shekshuev/gophkeeper/internal/service"
+	"github.com/shekshuev/gophkeeper/pkg/pb"
+)
+
+// userServer реализует pb.UserServiceServer.
+type userServer struct {
+	pb.UnimplementedUserServiceServer
+	base
+	users service.UserService
+}
+
+// GetUser возвращает пользователя по ID. Как и в REST API, пользователь может получить только себя.
+//
+// Возвращает коды:
+//   - PermissionDenied — если ID не совпадает с пользователем из токена
+//   - NotFound — если пользователь не найден
+func (s *userServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Id != userID {
+		s.logger.For(ctx).Warn("Запрошен чужой пользователь", zap.Uint64("user_id", userID), zap.Uint64("requested_id", req.Id))
+		return nil, status.Error(codes.PermissionDenied, ErrPermissionDenied.Error())
+	}
+
+	user, err := s.users.GetUserByID(ctx, req.Id)
+	if err != nil {
+		s.logger.For(ctx).Warn("Пользователь не найден", zap.Uint64("user_id", req.Id), zap.Error(err))
+		return nil, statusError(err)
+	}
+	return userToPB(user), nil
+}
@@ -0,0 +1,54 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/shekshuev/gophkeeper/pkg/pb"
+)
+
+func TestUserServer_GetUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	users := mocks.NewMockUserService(ctrl)
+	conn, _, cfg := startServer(t, users, nil, nil, nil, nil)
+	client := pb.NewUserServiceClient(conn)
+	ctx := authorized(t, cfg, "42")
+
+	t.Run("Success", func(t *testing.T) {
+		now := time.Now().UTC()
+		users.EXPECT().GetUserByID(gomock.Any(), uint64(42)).
+			Return(&models.ReadUserDTO{ID: 42, UserName: "user_1", FirstName: "John", CreatedAt: now}, nil)
+
+		user, err := client.GetUser(ctx, &pb.GetUserRequest{Id: 42})
+		assert.NoError(t, err)
+		assert.Equal(t, "user_1", user.UserName)
+		assert.Equal(t, now, user.CreatedAt.AsTime())
+	})
+
+	t.Run("Not_found", func(t *testing.T) {
+		users.EXPECT().GetUserByID(gomock.Any(), uint64(42)).Return(nil, repository.ErrNotFound)
+		_, err := client.GetUser(ctx, &pb.GetUserRequest{Id: 42})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Other_user", func(t *testing.T) {
+		_, err := client.GetUser(ctx, &pb.GetUserRequest{Id: 7})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		_, err := client.GetUser(context.Background(), &pb.GetUserRequest{Id: 42})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
@@ -23,14 +23,16 @@ func TestHandler_Login(t *testing.T) {
 	defer ctrl.Finish()
 	auth := mocks.NewMockAuthService(ctrl)
 	cfg := config.GetConfig()
-	handler := NewHandler(nil, auth, nil, &cfg)
+	handler := NewHandler(nil, auth, nil, nil, nil, nil, nil, nil, nil, &cfg)
 
 	t.Run("Success login", func(t *testing.T) {
 		dto := models.LoginUserDTO{UserName: "test_user", Password: "test123!"}
-		auth.EXPECT().Login(gomock.Any(), dto).Return(&models.ReadTokenDTO{AccessToken: "access", RefreshToken: "refresh"}, nil)
+		auth.EXPECT().Login(gomock.Any(), models.LoginUserDTO{UserName: "test_user", Password: "test123!", Device: "laptop", IP: "192.0.2.1"}).
+			Return(&models.ReadTokenDTO{AccessToken: "access", RefreshToken: "refresh"}, nil)
 		body, _ := json.Marshal(dto)
 		req := httptest.NewRequest(http.MethodPost, "/v1.0/auth/login", bytes.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(DeviceHeader, "laptop")
 		rr := httptest.NewRecorder()
 		handler.Login(rr, req)
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -38,7 +40,7 @@ func TestHandler_Login(t *testing.T) {
 
 	t.Run("Wrong password", func(t *testing.T) {
 		dto := models.LoginUserDTO{UserName: "test_user", Password: "WrongPassword123!"}
-		auth.EXPECT().Login(gomock.Any(), dto).Return(nil, service.ErrWrongPassword)
+		auth.EXPECT().Login(gomock.Any(), gomock.Any()).Return(nil, service.ErrWrongPassword)
 		body, _ := json.Marshal(dto)
 		req := httptest.NewRequest(http.MethodPost, "/v1.0/auth/login", bytes.NewReader(body))
 		rr := httptest.NewRecorder()
@@ -48,7 +50,7 @@ func TestHandler_Login(t *testing.T) {
 
 	t.Run("User not found", func(t *testing.T) {
 		dto := models.LoginUserDTO{UserName: "ghost", Password: "test123!"}
-		auth.EXPECT().Login(gomock.Any(), dto).Return(nil, service.ErrUserNotFound)
+		auth.EXPECT().Login(gomock.Any(), gomock.Any()).Return(nil, service.ErrUserNotFound)
 		body, _ := json.Marshal(dto)
 		req := httptest.NewRequest(http.MethodPost, "/v1.0/auth/login", bytes.NewReader(body))
 		rr := httptest.NewRecorder()
@@ -95,16 +97,19 @@ func TestHandler_Register(t *testing.T) {
 	defer ctrl.Finish()
 	auth := mocks.NewMockAuthService(ctrl)
 	cfg := config.GetConfig()
-	handler := NewHandler(nil, auth, nil, &cfg)
+	handler := NewHandler(nil, auth, nil, nil, nil, nil, nil, nil, nil, &cfg)
 
 	t.Run("Success register", func(t *testing.T) {
 		dto := models.RegisterUserDTO{
 			UserName: "test_user", Password: "test123!", PasswordConfirm: "test123!", FirstName: "John", LastName: "Doe",
 		}
-		auth.EXPECT().Register(gomock.Any(), dto).Return(&models.ReadTokenDTO{AccessToken: "access", RefreshToken: "refresh"}, nil)
+		expected := dto
+		expected.Device, expected.IP = "laptop", "192.0.2.1"
+		auth.EXPECT().Register(gomock.Any(), expected).Return(&models.ReadTokenDTO{AccessToken: "access", RefreshToken: "refresh"}, nil)
 		body, _ := json.Marshal(dto)
 		req := httptest.NewRequest(http.MethodPost, "/v1.0/auth/register", bytes.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(DeviceHeader, "laptop")
 		rr := httptest.NewRecorder()
 		handler.Register(rr, req)
 		assert.Equal(t, http.StatusCreated, rr.Code)
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/service"
+)
+
+// BatchSecrets — обработчик пакетного создания, изменения и удаления секретов.
+// Принимает JSON {"atomic": bool, "operations": [...]} с числом операций до models.MaxBatchOperations;
+// все операции выполняются в одной транзакции в порядке запроса. Каждая операция получает результат
+// со статусом, который вернул бы одиночный запрос: 201 — создан, 200 — изменён, 204 — перемещён в корзину,
+// 404 — секрет или папка не найдены, 422 — операция не прошла валидацию.
+// В атомарном режиме ошибка любой операции отменяет весь пакет, а остальные операции получают 424 Failed Dependency.
+//
+// Возвращает:
+//   - 200 OK — изменения зафиксированы (в неатомарном режиме часть операций может завершиться ошибкой)
+//   - 400 Bad Request — если JSON невалиден
+//   - 401 Unauthorized — если токен невалиден
+//   - 409 Conflict — если атомарный пакет отменён; по результатам видно, какая операция не прошла
+//   - 422 Unprocessable Entity — если список операций пуст или слишком длинный
+//   - 500 Internal Server Error — если не удалось выполнить транзакцию
+func (h *Handler) BatchSecrets(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.userIDFromRequest(r)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Токен отсутствует или невалиден", zap.Error(err))
+		h.JSONError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var dto models.BatchRequestDTO
+	if !h.decodeJSONBody(w, r, &dto) {
+		return
+	}
+
+	// Невалидные операции не передаются в сервис, но получают свой результат
+	outcomes := make([]models.BatchOutcome, len(dto.Operations))
+	valid := make([]models.BatchOperationDTO, 0, len(dto.Operations))
+	index := make([]int, 0, len(dto.Operations))
+	for i, op := range dto.Operations {
+		if err := h.validate.Struct(op); err != nil {
+			h.logger.For(r.Context()).Warn("Ошибка валидации операции пакета", zap.Int("index", i), zap.Error(err))
+			outcomes[i] = models.BatchOutcome{ID: op.ID, Err: ErrValidationError}
+			continue
+		}
+		valid = append(valid, op)
+		index = append(index, i)
+	}
+
+	switch {
+	case dto.Atomic && len(valid) < len(dto.Operations):
+		for _, i := range index {
+			outcomes[i].Err = service.ErrBatchRolledBack
+		}
+	case len(valid) > 0:
+		applied, err := h.secrets.Batch(r.Context(), userID, valid, dto.Atomic, requestMeta(r).Device)
+		if err != nil {
+			h.logger.For(r.Context()).Error("Ошибка при выполнении пакета операций", zap.Uint64("user_id", userID), zap.Error(err))
+			h.JSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		for j, outcome := range applied {
+			outcomes[index[j]] = outcome
+		}
+	}
+
+	resp := models.BatchResponseDTO{Committed: true, Results: make([]models.BatchResultDTO, len(outcomes))}
+	for i, outcome := range outcomes {
+		op := dto.Operations[i].Op
+		result := models.BatchResultDTO{Index: i, Op: op, Status: batchSuccessStatus(op), ID: outcome.ID, Version: outcome.Version}
+		if outcome.Err != nil {
+			result.Status = batchErrorStatus(outcome.Err)
+			result.Error = outcome.Err.Error()
+			resp.Committed = resp.Committed && !dto.Atomic
+		}
+		resp.Results[i] = result
+	}
+
+	if !resp.Committed {
+		h.logger.For(r.Context()).Warn("Атомарный пакет операций отменён", zap.Uint64("user_id", userID))
+		h.writeJSON(w, http.StatusConflict, resp)
+		return
+	}
+	h.logger.For(r.Context()).Info("Пакет операций выполнен", zap.Uint64("user_id", userID), zap.Int("count", len(outcomes)))
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// batchSuccessStatus возвращает статус успешно выполненной операции пакета.
+func batchSuccessStatus(op string) int {
+	switch op {
+	case models.BatchOpCreate:
+		return http.StatusCreated
+	case models.BatchOpDelete:
+		return http.StatusNoContent
+	default:
+		return http.StatusOK
+	}
+}
+
+// batchErrorStatus сопоставляет ошибку операции пакета с HTTP-статусом.
+func batchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrValidationError):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrBatchRolledBack):
+		return http.StatusFailedDependency
+	default:
+		return secretErrorStatus(err)
+	}
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/service"
+	"github.com/shekshuev/gophkeeper/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_BatchSecrets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secrets := mocks.NewMockSecretService(ctrl)
+	os.Setenv("ACCESS_TOKEN_SECRET", "test")
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	handler := NewHandler(nil, nil, secrets, nil, nil, nil, nil, nil, nil, &cfg)
+	server := httptest.NewServer(handler.Router)
+	defer server.Close()
+
+	accessToken, _ := utils.CreateToken(cfg.AccessTokenSecret, "42", cfg.AccessTokenExpires)
+	body := `{"operations":[
+		{"op":"create","title":"Mail","data":{"text":"x"}},
+		{"op":"update","title":"VPN"},
+		{"op":"delete","id":3},
+		{"op":"update","id":7,"title":"VPN"}
+	]}`
+	send := func(body string) (*resty.Response, models.BatchResponseDTO) {
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			SetHeader(DeviceHeader, "laptop").
+			SetBody(body).
+			Post(server.URL + "/v1.0/secrets/batch")
+		require.NoError(t, err)
+		var result models.BatchResponseDTO
+		_ = json.Unmarshal(resp.Body(), &result)
+		return resp, result
+	}
+
+	t.Run("Partial", func(t *testing.T) {
+		secrets.EXPECT().
+			Batch(gomock.Any(), uint64(42), gomock.Len(3), false, "laptop").
+			Return([]models.BatchOutcome{{ID: 10, Version: 1}, {ID: 3, Err: service.ErrSecretNotFound}, {ID: 7, Version: 4}}, nil)
+
+		resp, result := send(body)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.True(t, result.Committed)
+		assert.Equal(t, []models.BatchResultDTO{
+			{Index: 0, Op: "create", Status: http.StatusCreated, ID: 10, Version: 1},
+			{Index: 1, Op: "update", Status: http.StatusUnprocessableEntity, Error: ErrValidationError.Error()},
+			{Index: 2, Op: "delete", Status: http.StatusNotFound, ID: 3, Error: service.ErrSecretNotFound.Error()},
+			{Index: 3, Op: "update", Status: http.StatusOK, ID: 7, Version: 4},
+		}, result.Results)
+	})
+
+	t.Run("Atomic_invalid_operation", func(t *testing.T) {
+		resp, result := send(`{"atomic":true,` + body[1:])
+		assert.Equal(t, http.StatusConflict, resp.StatusCode())
+		assert.False(t, result.Committed)
+		assert.Equal(t, http.StatusUnprocessableEntity, result.Results[1].Status)
+		for _, i := range []int{0, 2, 3} {
+			assert.Equal(t, http.StatusFailedDependency, result.Results[i].Status)
+		}
+	})
+
+	t.Run("Atomic_rolled_back", func(t *testing.T) {
+		secrets.EXPECT().
+			Batch(gomock.Any(), uint64(42), gomock.Len(1), true, "laptop").
+			Return([]models.BatchOutcome{{ID: 3, Err: service.ErrSecretNotFound}}, nil)
+
+		resp, result := send(`{"atomic":true,"operations":[{"op":"delete","id":3}]}`)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode())
+		assert.False(t, result.Committed)
+		assert.Equal(t, http.StatusNotFound, result.Results[0].Status)
+	})
+
+	t.Run("Service_error", func(t *testing.T) {
+		secrets.EXPECT().
+			Batch(gomock.Any(), uint64(42), gomock.Any(), false, "laptop").
+			Return(nil, errors.New("tx error"))
+
+		resp, _ := send(`{"operations":[{"op":"delete","id":3}]}`)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		resp, _ := send(`{"operations":[]}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode())
+	})
+
+	t.Run("Invalid_JSON", func(t *testing.T) {
+		resp, _ := send(`{`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		resp, err := resty.New().R().SetBody(body).Post(server.URL + "/v1.0/secrets/batch")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+	})
+}
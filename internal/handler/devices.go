@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/service"
+)
+
+// ErrForbidden возвращается, если действие доступно только администраторам.
+var ErrForbidden = errors.New("forbidden")
+
+// GetDevices — обработчик получения сертификатов устройств текущего пользователя, начиная с новых.
+//
+// Возвращает:
+//   - 200 OK — если сертификаты получены
+//   - 401 Unauthorized — если токен невалиден или не содержит userID
+//   - 500 Internal Server Error — если ошибка на уровне сервиса
+func (h *Handler) GetDevices(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.userIDFromRequest(r)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Токен отсутствует или невалиден при получении сертификатов устройств", zap.Error(err))
+		h.JSONError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	h.writeDevices(w, r, userID)
+}
+
+// RevokeDevice — обработчик отзыва сертификата одного из устройств текущего пользователя, например потерянного.
+// Токены, привязанные к отозванному сертификату, перестают приниматься сразу.
+//
+// Возвращает:
+//   - 200 OK — если сертификат отозван (в ответе запись о сертификате)
+//   - 401 Unauthorized — если токен невалиден
+//   - 404 Not Found — если сертификат не найден или выдан другому пользователю
+//   - 500 Internal Server Error — если ошибка на уровне сервиса
+func (h *Handler) RevokeDevice(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.userIDFromRequest(r)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Токен отсутствует или невалиден при отзыве сертификата устройства", zap.Error(err))
+		h.JSONError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	serial := chi.URLParam(r, "serial")
+
+	device, err := h.devices.RevokeByUser(r.Context(), userID, serial)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Ошибка при отзыве сертификата устройства", zap.String("serial", serial), zap.Error(err))
+		h.JSONError(w, deviceErrorStatus(err), err.Error())
+		return
+	}
+
+	h.logger.For(r.Context()).Info("Пользователь отозвал сертификат устройства", zap.String("serial", serial))
+	h.writeJSON(w, http.StatusOK, device)
+}
+
+// GetDeviceRevocationList — обработчик получения списка отзыва сертификатов устройств (CRL) в PEM.
+// Доступен без авторизации: по нему прокси и другие серверы проверяют сертификаты устройств.
+//
+// Возвращает:
+//   - 200 OK — список отзыва, подписанный центром сертификации устройств
+//   - 404 Not Found — если сертификаты устройств выключены
+//   - 500 Internal Server Error — если ошибка на уровне сервиса
+func (h *Handler) GetDeviceRevocationList(w http.ResponseWriter, r *http.Request) {
+	crl, err := h.devices.RevocationList(r.Context())
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Ошибка при получении списка отзыва сертификатов устройств", zap.Error(err))
+		h.JSONError(w, deviceErrorStatus(err), err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-pem-file")
+	if _, err := w.Write(crl); err != nil {
+		h.logger.For(r.Context()).Error("Ошибка отправки ответа клиенту", zap.Error(err))
+	}
+}
+
+// GetUserDevices — обработчик получения сертификатов устройств любого пользователя (для администраторов).
+//
+// Возвращает:
+//   - 200 OK — если сертификаты получены
+//   - 403 Forbidden — если пользователь не администратор
+//   - 404 Not Found — если ID невалиден
+//   - 500 Internal Server Error — если ошибка на уровне сервиса
+func (h *Handler) GetUserDevices(w http.ResponseWriter, r *http.Request) {
+	idParam := chi.URLParam(r, "id")
+	userID, err := strconv.ParseUint(idParam, 10, 64)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Невалидный ID пользователя", zap.String("id_param", idParam), zap.Error(err))
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	h.writeDevices(w, r, userID)
+}
+
+// AdminRevokeDevice — обработчик отзыва сертификата устройства любого пользователя (для администраторов).
+//
+// Возвращает:
+//   - 200 OK — если сертификат отозван (в ответе запись о сертификате)
+//   - 403 Forbidden — если пользователь не администратор
+//   - 404 Not Found — если сертификат не найден
+//   - 500 Internal Server Error — если ошибка на уровне сервиса
+func (h *Handler) AdminRevokeDevice(w http.ResponseWriter, r *http.Request) {
+	serial := chi.URLParam(r, "serial")
+
+	device, err := h.devices.Revoke(r.Context(), serial)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Ошибка при отзыве сертификата устройства администратором", zap.String("serial", serial), zap.Error(err))
+		h.JSONError(w, deviceErrorStatus(err), err.Error())
+		return
+	}
+
+	h.logger.For(r.Context()).Info("Администратор отозвал сертификат устройства", zap.String("serial", serial), zap.Uint64("device_user_id", device.UserID))
+	h.writeJSON(w, http.StatusOK, device)
+}
+
+// requireAdmin — middleware, пропускающий только пользователей из cfg.AdminUsers.
+// Подключается после RequestAuth.
+func (h *Handler) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := h.userIDFromRequest(r)
+		if err != nil {
+			h.JSONError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		user, err := h.users.GetUserByID(r.Context(), userID)
+		if err != nil || !slices.Contains(h.cfg.AdminUsers, user.UserName) {
+			h.logger.For(r.Context()).Warn("Попытка действия администратора без прав", zap.Error(err))
+			h.JSONError(w, http.StatusForbidden, ErrForbidden.Error())
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeDevices отправляет сертификаты устройств пользователя userID.
+func (h *Handler) writeDevices(w http.ResponseWriter, r *http.Request, userID uint64) {
+	devices, err := h.devices.GetAllByUser(r.Context(), userID)
+	if err != nil {
+		h.logger.For(r.Context()).Error("Ошибка при получении сертификатов устройств", zap.Uint64("user_id", userID), zap.Error(err))
+		h.JSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if devices == nil {
+		devices = []models.DeviceCertificateDTO{}
+	}
+
+	h.logger.For(r.Context()).Info("Сертификаты устройств получены", zap.Uint64("user_id", userID), zap.Int("count", len(devices)))
+	h.writeJSON(w, http.StatusOK, devices)
+}
+
+// deviceErrorStatus сопоставляет ошибку сервиса сертификатов устройств с HTTP-статусом.
+func deviceErrorStatus(err error) int {
+	if errors.Is(err, service.ErrDeviceCertificateNotFound) || errors.Is(err, service.ErrDeviceCertsDisabled) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
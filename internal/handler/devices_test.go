@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shekshuev/gophkeeper/internal/certs"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/service"
+	"github.com/shekshuev/gophkeeper/internal/utils"
+)
+
+func TestHandler_Devices(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	users := mocks.NewMockUserService(ctrl)
+	devices := mocks.NewMockDeviceService(ctrl)
+	os.Setenv("ACCESS_TOKEN_SECRET", "test")
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	cfg.AdminUsers = []string{"admin"}
+	handler := NewHandler(users, nil, nil, nil, nil, nil, nil, devices, nil, &cfg)
+	server := httptest.NewServer(handler.Router)
+	defer server.Close()
+
+	accessToken, _ := utils.CreateToken(cfg.AccessTokenSecret, "1", cfg.AccessTokenExpires)
+	device := models.DeviceCertificateDTO{ID: 1, UserID: 1, Device: "laptop", Serial: "0a1b", Thumbprint: "thumb"}
+
+	t.Run("List_success", func(t *testing.T) {
+		devices.EXPECT().GetAllByUser(gomock.Any(), uint64(1)).Return(nil, nil)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Get(server.URL + "/v1.0/devices/")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Equal(t, "[]", resp.String())
+	})
+
+	t.Run("Revoke_success", func(t *testing.T) {
+		devices.EXPECT().RevokeByUser(gomock.Any(), uint64(1), "0a1b").Return(&device, nil)
+
+		var result models.DeviceCertificateDTO
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			SetResult(&result).
+			Delete(server.URL + "/v1.0/devices/0a1b")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Equal(t, "laptop", result.Device)
+	})
+
+	t.Run("Revoke_not_found", func(t *testing.T) {
+		devices.EXPECT().RevokeByUser(gomock.Any(), uint64(1), "ffff").Return(nil, service.ErrDeviceCertificateNotFound)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Delete(server.URL + "/v1.0/devices/ffff")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+	})
+
+	t.Run("Revocation_list", func(t *testing.T) {
+		devices.EXPECT().RevocationList(gomock.Any()).Return([]byte("crl"), nil)
+
+		resp, err := resty.New().R().Get(server.URL + "/v1.0/devices/crl")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Equal(t, "application/x-pem-file", resp.Header().Get("Content-Type"))
+		assert.Equal(t, "crl", resp.String())
+	})
+
+	t.Run("Admin_forbidden", func(t *testing.T) {
+		users.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&models.ReadUserDTO{ID: 1, UserName: "john"}, nil)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Post(server.URL + "/v1.0/admin/devices/0a1b/revoke")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode())
+	})
+
+	t.Run("Admin_revoke", func(t *testing.T) {
+		adminToken, _ := utils.CreateToken(cfg.AccessTokenSecret, "2", cfg.AccessTokenExpires)
+		users.EXPECT().GetUserByID(gomock.Any(), uint64(2)).Return(&models.ReadUserDTO{ID: 2, UserName: "admin"}, nil).Times(2)
+		devices.EXPECT().Revoke(gomock.Any(), "0a1b").Return(&device, nil)
+		devices.EXPECT().GetAllByUser(gomock.Any(), uint64(1)).Return([]models.DeviceCertificateDTO{device}, nil)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+adminToken).
+			Post(server.URL + "/v1.0/admin/devices/0a1b/revoke")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+
+		var result []models.DeviceCertificateDTO
+		resp, err = resty.New().R().
+			SetHeader("Authorization", "Bearer "+adminToken).
+			SetResult(&result).
+			Get(server.URL + "/v1.0/admin/users/1/devices")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Len(t, result, 1)
+	})
+}
+
+func TestHandler_DeviceBoundToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	devices := mocks.NewMockDeviceService(ctrl)
+	os.Setenv("ACCESS_TOKEN_SECRET", "test")
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	cfg.DeviceCertMode = config.DeviceCertRequired
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, devices, nil, &cfg)
+
+	ca, err := certs.LoadDeviceCA(&config.Config{DeviceCertValidity: time.Hour})
+	require.NoError(t, err)
+	key, err := certs.DeviceKey(filepath.Join(t.TempDir(), "device.key"))
+	require.NoError(t, err)
+	csr, err := certs.CertificateRequest(key, "laptop")
+	require.NoError(t, err)
+	cert, _, err := ca.Issue(string(csr), "john", "laptop", time.Now())
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(handler.Router)
+	server.TLS = &tls.Config{ClientAuth: tls.VerifyClientCertIfGiven, ClientCAs: ca.Pool()}
+	server.StartTLS()
+	defer server.Close()
+
+	device := models.DeviceCertificateDTO{ID: 1, UserID: 1, Device: "laptop", Serial: certs.Serial(cert), Thumbprint: certs.Thumbprint(cert)}
+	bound, _ := utils.CreateBoundToken(cfg.AccessTokenSecret, "1", device.Thumbprint, cfg.AccessTokenExpires)
+	unbound, _ := utils.CreateToken(cfg.AccessTokenSecret, "1", cfg.AccessTokenExpires)
+	client := func(withCert bool) *resty.Client {
+		tlsConfig := &tls.Config{InsecureSkipVerify: true}
+		if withCert {
+			tlsConfig.Certificates = []tls.Certificate{{Certificate: [][]byte{cert.Raw}, PrivateKey: key, Leaf: cert}}
+		}
+		return resty.New().SetTLSClientConfig(tlsConfig)
+	}
+
+	t.Run("Bound_token_from_device", func(t *testing.T) {
+		devices.EXPECT().Authenticate(gomock.Any(), gomock.Any()).Return(&device, nil)
+		devices.EXPECT().GetAllByUser(gomock.Any(), uint64(1)).Return(nil, nil)
+
+		resp, err := client(true).R().SetHeader("Authorization", "Bearer "+bound).Get(server.URL + "/v1.0/devices/")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+	})
+
+	t.Run("Bound_token_without_certificate", func(t *testing.T) {
+		resp, err := client(false).R().SetHeader("Authorization", "Bearer "+bound).Get(server.URL + "/v1.0/devices/")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+	})
+
+	t.Run("Bound_token_revoked_certificate", func(t *testing.T) {
+		devices.EXPECT().Authenticate(gomock.Any(), gomock.Any()).Return(nil, service.ErrDeviceCertificateRevoked)
+
+		resp, err := client(true).R().SetHeader("Authorization", "Bearer "+bound).Get(server.URL + "/v1.0/devices/")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+	})
+
+	t.Run("Unbound_token_required_mode", func(t *testing.T) {
+		devices.EXPECT().Authenticate(gomock.Any(), gomock.Any()).Return(&device, nil)
+
+		resp, err := client(true).R().SetHeader("Authorization", "Bearer "+unbound).Get(server.URL + "/v1.0/devices/")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+	})
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// LastEventIDHeader — заголовок, в котором клиент SSE при переподключении передаёт ID последнего полученного события.
+const LastEventIDHeader = "Last-Event-ID"
+
+// ErrInvalidLastEventID возвращается, если заголовок Last-Event-ID не является ID события ленты.
+var ErrInvalidLastEventID = errors.New("invalid Last-Event-ID")
+
+// Параметры потока Server-Sent Events.
+const (
+	sseRetry     = 3 * time.Second  // Через сколько клиенту переподключаться после разрыва
+	sseKeepAlive = 30 * time.Second // Как часто отправлять комментарий, чтобы прокси не закрывали простаивающее соединение
+)
+
+// SecretEvents — обработчик ленты изменений секретов пользователя в формате Server-Sent Events.
+//
+// Каждое событие передаётся с полями event (тип, см. models.SecretEvent*), id и data (SecretEventDTO в JSON).
+// ID события — ID записи в журнале изменений секретов: он назначается базой и растёт в порядке фиксации изменений.
+// Если клиент переподключается с заголовком Last-Event-ID, сначала передаются изменения с большими ID
+// (см. SecretService.GetChangesAfter) по мере чтения журнала, затем — новые. Подписка оформляется до чтения базы,
+// поэтому события не теряются; события ленты, уже переданные из журнала, пропускаются.
+// Если изменения после Last-Event-ID уже удалены из журнала (старше SECRET_CHANGES_RETENTION), вместо них
+// передаётся событие reset: клиент должен заново получить все секреты и продолжить с ID этого события.
+//
+// Если клиент не успевает читать ленту или сервер останавливается, поток завершается:
+// клиент переподключается с Last-Event-ID и догоняет пропущенное.
+//
+// Возвращает:
+//   - 200 OK — поток событий text/event-stream
+//   - 400 Bad Request — если заголовок Last-Event-ID невалиден
+//   - 401 Unauthorized — если токен невалиден
+//   - 500 Internal Server Error — если ошибка на уровне сервиса до отправки первого события
+func (h *Handler) SecretEvents(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.userIDFromRequest(r)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Токен отсутствует или невалиден", zap.Error(err))
+		h.JSONError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	var afterID *uint64
+	if lastID := r.Header.Get(LastEventIDHeader); lastID != "" {
+		id, err := strconv.ParseUint(lastID, 10, 64)
+		if err != nil {
+			h.logger.For(r.Context()).Warn("Невалидный заголовок Last-Event-ID", zap.String("last_event_id", lastID))
+			h.JSONError(w, http.StatusBadRequest, ErrInvalidLastEventID.Error())
+			return
+		}
+		afterID = &id
+	}
+
+	feed, unsubscribe := h.events.Subscribe(userID)
+	defer unsubscribe()
+
+	rc := http.NewResponseController(w)
+	started := false
+	// start отправляет заголовки потока; до первого события ошибку чтения журнала ещё можно вернуть статусом ответа.
+	start := func() error {
+		if started {
+			return nil
+		}
+		started = true
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		_, err := fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+		return err
+	}
+
+	var lastID uint64
+	if afterID != nil {
+		lastID = *afterID
+		var writeErr error
+		err := h.secrets.GetChangesAfter(r.Context(), userID, lastID, func(event models.SecretEventDTO) error {
+			if writeErr = start(); writeErr != nil {
+				return writeErr
+			}
+			if writeErr = writeEvent(w, event); writeErr != nil {
+				return writeErr
+			}
+			lastID = event.ID
+			return nil
+		})
+		if writeErr != nil {
+			return
+		}
+		if err != nil {
+			h.logger.For(r.Context()).Error("Ошибка при получении изменений секретов", zap.Uint64("user_id", userID), zap.Error(err))
+			if !started {
+				h.JSONError(w, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+	}
+	if err := start(); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		h.logger.For(r.Context()).Error("Поток событий не поддерживается", zap.Error(err))
+		return
+	}
+
+	h.logger.For(r.Context()).Info("Клиент подписан на ленту изменений", zap.Uint64("user_id", userID))
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.streams:
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-feed:
+			if !ok {
+				h.logger.For(r.Context()).Warn("Клиент не успевает читать ленту изменений", zap.Uint64("user_id", userID))
+				return
+			}
+			if event.ID <= lastID {
+				continue
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+			lastID = event.ID
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent записывает событие ленты в формате Server-Sent Events.
+func writeEvent(w http.ResponseWriter, event models.SecretEventDTO) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+// CloseStreams завершает открытые потоки событий. Вызывается при остановке сервера,
+// иначе http.Server.Shutdown ждал бы, пока клиенты сами отключатся.
+func (h *Handler) CloseStreams() {
+	h.closeStreams.Do(func() {
+		close(h.streams)
+	})
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/events"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sseEvent — событие, прочитанное из потока Server-Sent Events.
+type sseEvent struct {
+	ID    string
+	Event string
+	Data  models.SecretEventDTO
+}
+
+// readSSEEvent читает из потока следующее событие, пропуская служебные блоки (retry, комментарии).
+func readSSEEvent(t *testing.T, r *bufio.Reader) (sseEvent, error) {
+	var event sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return event, err
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && event.Event != "":
+			return event, nil
+		case strings.HasPrefix(line, "id: "):
+			event.ID = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			event.Event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event.Data))
+		}
+	}
+}
+
+func TestHandler_SecretEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secrets := mocks.NewMockSecretService(ctrl)
+	broker := events.NewBroker()
+	os.Setenv("ACCESS_TOKEN_SECRET", "test")
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	handler := NewHandler(nil, nil, secrets, nil, nil, nil, nil, nil, broker, &cfg)
+	server := httptest.NewServer(handler.Router)
+	defer server.Close()
+
+	accessToken, _ := utils.CreateToken(cfg.AccessTokenSecret, "42", cfg.AccessTokenExpires)
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	subscribe := func(lastEventID string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/v1.0/secrets/events", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		if lastEventID != "" {
+			req.Header.Set(LastEventIDHeader, lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("Resume_and_live", func(t *testing.T) {
+		secrets.EXPECT().
+			GetChangesAfter(gomock.Any(), uint64(42), uint64(10), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ uint64, send func(models.SecretEventDTO) error) error {
+				return send(models.SecretEventDTO{ID: 11, Type: models.SecretEventUpdated, UserID: 42, SecretID: 1, Version: 2, Time: since.Add(time.Hour)})
+			})
+
+		resp := subscribe("10")
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		stream := bufio.NewReader(resp.Body)
+
+		event, err := readSSEEvent(t, stream)
+		require.NoError(t, err)
+		assert.Equal(t, "11", event.ID)
+		assert.Equal(t, models.SecretEventUpdated, event.Event)
+		assert.Equal(t, uint64(1), event.Data.SecretID)
+		assert.Equal(t, 2, event.Data.Version)
+
+		broker.Publish(models.SecretEventDTO{ID: 12, Type: models.SecretEventCreated, UserID: 7, SecretID: 99, Time: since})
+		broker.Publish(models.SecretEventDTO{ID: 11, Type: models.SecretEventUpdated, UserID: 42, SecretID: 1, Version: 2, Time: since.Add(time.Hour)})
+		broker.Publish(models.SecretEventDTO{ID: 13, Type: models.SecretEventDeleted, UserID: 42, SecretID: 3, Time: since.Add(2 * time.Hour)})
+
+		event, err = readSSEEvent(t, stream)
+		require.NoError(t, err)
+		assert.Equal(t, "13", event.ID, "event already replayed from the change log must be skipped")
+		assert.Equal(t, models.SecretEventDeleted, event.Event)
+		assert.Equal(t, uint64(3), event.Data.SecretID)
+		assert.Equal(t, since.Add(2*time.Hour), event.Data.Time)
+	})
+
+	t.Run("Invalid_last_event_id", func(t *testing.T) {
+		for _, lastEventID := range []string{"yesterday", "-1"} {
+			resp := subscribe(lastEventID)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, lastEventID)
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		secrets.EXPECT().
+			GetChangesAfter(gomock.Any(), uint64(42), uint64(3), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ uint64, send func(models.SecretEventDTO) error) error {
+				return send(models.SecretEventDTO{ID: 20, Type: models.SecretEventReset, UserID: 42, Time: since})
+			})
+
+		resp := subscribe("3")
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		stream := bufio.NewReader(resp.Body)
+
+		event, err := readSSEEvent(t, stream)
+		require.NoError(t, err)
+		assert.Equal(t, "20", event.ID)
+		assert.Equal(t, models.SecretEventReset, event.Event)
+
+		broker.Publish(models.SecretEventDTO{ID: 19, Type: models.SecretEventUpdated, UserID: 42, SecretID: 1, Time: since})
+		broker.Publish(models.SecretEventDTO{ID: 21, Type: models.SecretEventCreated, UserID: 42, SecretID: 5, Time: since})
+
+		event, err = readSSEEvent(t, stream)
+		require.NoError(t, err)
+		assert.Equal(t, "21", event.ID, "events already covered by the resync must be skipped")
+	})
+
+	t.Run("Service_error", func(t *testing.T) {
+		secrets.EXPECT().GetChangesAfter(gomock.Any(), uint64(42), uint64(1), gomock.Any()).Return(errors.New("db error"))
+
+		resp := subscribe("1")
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+
+	t.Run("Service_error_after_first_event", func(t *testing.T) {
+		secrets.EXPECT().
+			GetChangesAfter(gomock.Any(), uint64(42), uint64(1), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ uint64, send func(models.SecretEventDTO) error) error {
+				require.NoError(t, send(models.SecretEventDTO{ID: 2, Type: models.SecretEventUpdated, UserID: 42, SecretID: 1, Time: since}))
+				return errors.New("db error")
+			})
+
+		resp := subscribe("1")
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		stream := bufio.NewReader(resp.Body)
+
+		event, err := readSSEEvent(t, stream)
+		require.NoError(t, err)
+		assert.Equal(t, "2", event.ID)
+		_, err = readSSEEvent(t, stream)
+		assert.ErrorIs(t, err, io.EOF, "the stream must end so the client resumes from the last event")
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/v1.0/secrets/events")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Server_shutdown", func(t *testing.T) {
+		resp := subscribe("")
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		handler.CloseStreams()
+		_, err := readSSEEvent(t, bufio.NewReader(resp.Body))
+		assert.ErrorIs(t, err, io.EOF)
+	})
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/service"
+)
+
+// CreateFolder — обработчик создания новой папки.
+// Принимает JSON с полями name и parent_id (необязательно) в теле запроса.
+//
+// Возвращает:
+//   - 201 Created — если папка создана
+//   - 400 Bad Request — если JSON невалиден
+//   - 401 Unauthorized — если токен невалиден или не содержит userID
+//   - 404 Not Found — если родительская папка не найдена
+//   - 409 Conflict — если в родительской папке уже есть папка с таким названием
+//   - 422 Unprocessable Entity — если входные данные не прошли валидацию
+func (h *Handler) CreateFolder(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.userIDFromRequest(r)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Токен отсутствует или невалиден при создании папки", zap.Error(err))
+		h.JSONError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var dto models.CreateFolderDTO
+	if !h.decodeJSONBody(w, r, &dto) {
+		return
+	}
+	dto.UserID = userID
+
+	folder, err := h.folders.Create(r.Context(), dto)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Ошибка при создании папки", zap.Uint64("user_id", userID), zap.Error(err))
+		h.JSONError(w, folderErrorStatus(err), err.Error())
+		return
+	}
+
+	h.logger.For(r.Context()).Info("Папка успешно создана", zap.Uint64("folder_id", folder.ID), zap.Uint64("user_id", userID))
+	h.writeJSON(w, http.StatusCreated, folder)
+}
+
+// GetFolders — обработчик получения всех папок текущего пользователя.
+// Возвращает плоский JSON-массив папок; иерархия восстанавливается по полю parent_id.
+//
+// Возвращает:
+//   - 200 OK — если папки получены
+//   - 401 Unauthorized — если токен невалиден или не содержит userID
+//   - 500 Internal Server Error — если ошибка на уровне сервиса
+func (h *Handler) GetFolders(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.userIDFromRequest(r)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Токен отсутствует или невалиден при получении папок", zap.Error(err))
+		h.JSONError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	folders, err := h.folders.GetAllByUser(r.Context(), userID)
+	if err != nil {
+		h.logger.For(r.Context()).Error("Ошибка при получении папок", zap.Uint64("user_id", userID), zap.Error(err))
+		h.JSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if folders == nil {
+		folders = []models.ReadFolderDTO{}
+	}
+
+	h.logger.For(r.Context()).Info("Папки успешно получены", zap.Uint64("user_id", userID), zap.Int("count", len(folders)))
+	h.writeJSON(w, http.StatusOK, folders)
+}
+
+// RenameFolder — обработчик переименования папки.
+// Принимает JSON с полем name в теле запроса.
+//
+// Возвращает:
+//   - 200 OK — обновлённую папку
+//   - 400 Bad Request — если JSON невалиден
+//   - 401 Unauthorized — если токен невалиден
+//   - 404 Not Found — если ID невалиден или папка не найдена
+//   - 409 Conflict — если в родительской папке уже есть папка с таким названием
+//   - 422 Unprocessable Entity — если входные данные не прошли валидацию
+func (h *Handler) RenameFolder(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.folderRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var dto models.RenameFolderDTO
+	if !h.decodeJSONBody(w, r, &dto) {
+		return
+	}
+
+	folder, err := h.folders.Rename(r.Context(), userID, id, dto)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Ошибка при переименовании папки", zap.Uint64("folder_id", id), zap.Error(err))
+		h.JSONError(w, folderErrorStatus(err), err.Error())
+		return
+	}
+
+	h.logger.For(r.Context()).Info("Папка успешно переименована", zap.Uint64("folder_id", id))
+	h.writeJSON(w, http.StatusOK, folder)
+}
+
+// MoveFolder — обработчик перемещения папки.
+// Принимает JSON с полем parent_id в теле запроса (null — перенос в корень).
+//
+// Возвращает:
+//   - 200 OK — обновлённую папку
+//   - 400 Bad Request — если JSON невалиден
+//   - 401 Unauthorized — если токен невалиден
+//   - 404 Not Found — если ID невалиден, папка или новая родительская папка не найдены
+//   - 409 Conflict — если папку пытаются переместить внутрь самой себя или при конфликте названий
+func (h *Handler) MoveFolder(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.folderRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var dto models.MoveFolderDTO
+	if !h.decodeJSONBody(w, r, &dto) {
+		return
+	}
+
+	folder, err := h.folders.Move(r.Context(), userID, id, dto)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Ошибка при перемещении папки", zap.Uint64("folder_id", id), zap.Error(err))
+		h.JSONError(w, folderErrorStatus(err), err.Error())
+		return
+	}
+
+	h.logger.For(r.Context()).Info("Папка успешно перемещена", zap.Uint64("folder_id", id))
+	h.writeJSON(w, http.StatusOK, folder)
+}
+
+// folderRequestIDs извлекает ID пользователя из токена и ID папки из URL.
+// При ошибке сам отправляет ответ и возвращает ok = false.
+func (h *Handler) folderRequestIDs(w http.ResponseWriter, r *http.Request) (userID, id uint64, ok bool) {
+	userID, err := h.userIDFromRequest(r)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Токен отсутствует или невалиден", zap.Error(err))
+		h.JSONError(w, http.StatusUnauthorized, err.Error())
+		return 0, 0, false
+	}
+	idStr := chi.URLParam(r, "id")
+	id, err = strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Невалидный ID папки", zap.String("id", idStr), zap.Error(err))
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return 0, 0, false
+	}
+	return userID, id, true
+}
+
+// folderErrorStatus сопоставляет ошибку сервиса папок с HTTP-статусом.
+func folderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrFolderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrFolderCycle), errors.Is(err, service.ErrFolderExists):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/service"
+	"github.com/shekshuev/gophkeeper/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Folders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	folders := mocks.NewMockFolderService(ctrl)
+	os.Setenv("ACCESS_TOKEN_SECRET", "test")
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	handler := NewHandler(nil, nil, nil, folders, nil, nil, nil, nil, nil, &cfg)
+	server := httptest.NewServer(handler.Router)
+	defer server.Close()
+
+	accessToken, _ := utils.CreateToken(cfg.AccessTokenSecret, "1", cfg.AccessTokenExpires)
+	parentID := uint64(3)
+
+	t.Run("Create_success", func(t *testing.T) {
+		folders.EXPECT().
+			Create(gomock.Any(), models.CreateFolderDTO{UserID: 1, ParentID: &parentID, Name: "Work"}).
+			Return(&models.ReadFolderDTO{ID: 4, UserID: 1, ParentID: &parentID, Name: "Work"}, nil)
+
+		var result models.ReadFolderDTO
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			SetBody(`{"name":"Work","parent_id":3}`).
+			SetResult(&result).
+			Post(server.URL + "/v1.0/folders/")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode())
+		assert.Equal(t, uint64(4), result.ID)
+	})
+
+	t.Run("Create_validation_error", func(t *testing.T) {
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			SetBody(`{"name":""}`).
+			Post(server.URL + "/v1.0/folders/")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode())
+	})
+
+	t.Run("Create_duplicate", func(t *testing.T) {
+		folders.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, service.ErrFolderExists)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			SetBody(`{"name":"Work"}`).
+			Post(server.URL + "/v1.0/folders/")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode())
+	})
+
+	t.Run("Unauthorized_no_token", func(t *testing.T) {
+		resp, err := resty.New().R().Get(server.URL + "/v1.0/folders/")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+	})
+
+	t.Run("List_success", func(t *testing.T) {
+		folders.EXPECT().GetAllByUser(gomock.Any(), uint64(1)).Return(nil, nil)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Get(server.URL + "/v1.0/folders/")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.JSONEq(t, `[]`, string(resp.Body()))
+	})
+
+	t.Run("Rename_not_found", func(t *testing.T) {
+		folders.EXPECT().
+			Rename(gomock.Any(), uint64(1), uint64(9), models.RenameFolderDTO{Name: "New"}).
+			Return(nil, service.ErrFolderNotFound)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			SetBody(`{"name":"New"}`).
+			Put(server.URL + "/v1.0/folders/9/name")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+	})
+
+	t.Run("Move_to_root", func(t *testing.T) {
+		folders.EXPECT().
+			Move(gomock.Any(), uint64(1), uint64(4), models.MoveFolderDTO{}).
+			Return(&models.ReadFolderDTO{ID: 4}, nil)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			SetBody(`{"parent_id":null}`).
+			Put(server.URL + "/v1.0/folders/4/parent")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+	})
+
+	t.Run("Move_cycle", func(t *testing.T) {
+		folders.EXPECT().Move(gomock.Any(), uint64(1), uint64(3), gomock.Any()).Return(nil, service.ErrFolderCycle)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			SetBody(`{"parent_id":4}`).
+			Put(server.URL + "/v1.0/folders/3/parent")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode())
+	})
+}
@@ -3,16 +3,23 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"log"
+	"net"
 	"net/http"
+	"strconv"
+	"sync"
 
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/go-playground/validator/v10"
 	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/events"
 	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/metrics"
 	"github.com/shekshuev/gophkeeper/internal/middleware"
+	"github.com/shekshuev/gophkeeper/internal/models"
 	"github.com/shekshuev/gophkeeper/internal/service"
 	"github.com/shekshuev/gophkeeper/internal/utils"
 	"go.uber.org/zap"
@@ -24,15 +31,35 @@ import (
 // Регистрирует маршруты:
 //   - /v1.0/auth/login    — POST: логин пользователя
 //   - /v1.0/auth/register — POST: регистрация пользователя
-//   - /v1.0/users/{id}    — GET: получение пользователя по ID (требует JWT)
+//   - /v1.0/users/{user_id} — GET: получение пользователя по ID (требует JWT того же пользователя)
+//   - /v1.0/secrets/*     — создание, поиск, получение, изменение, история версий, удаление и пакетные операции над секретами (требует JWT)
+//   - /v1.0/secrets/events — GET: лента изменений секретов в формате Server-Sent Events (требует JWT)
+//   - /v1.0/secrets/export — GET: постраничная выгрузка секретов с данными и записью в журнал аудита (требует JWT)
+//   - /v1.0/trash/*       — корзина: просмотр, восстановление и окончательное удаление секретов (требует JWT)
+//   - /v1.0/folders/*     — создание, переименование и перемещение папок (требует JWT)
+//   - /v1.0/webhooks/*    — регистрация и удаление вебхуков, журнал доставок (требует JWT)
+//   - /v1.0/devices/*     — сертификаты устройств пользователя и их отзыв (требует JWT); /v1.0/devices/crl — список отзыва
+//   - /v1.0/admin/*       — сертификаты устройств всех пользователей (требует JWT администратора, см. config.Config.AdminUsers)
+//   - /openapi.json       — GET: спецификация OpenAPI 3 перечисленных маршрутов
+//   - /health             — GET: проверка живости, всегда "ok"
+//   - /ready              — GET: проверка готовности (база данных, миграции)
+//   - /metrics            — GET: метрики сервера в формате Prometheus
 type Handler struct {
-	users    service.UserService
-	secrets  service.SecretService
-	auth     service.AuthService
-	Router   *chi.Mux
-	validate *validator.Validate
-	cfg      *config.Config
-	logger   *logger.Logger
+	users        service.UserService
+	secrets      service.SecretService
+	folders      service.FolderService
+	idempotency  service.IdempotencyService
+	webhooks     service.WebhookService
+	health       service.HealthService
+	devices      service.DeviceService
+	auth         service.AuthService
+	events       events.Subscriber
+	streams      chan struct{} // Закрывается при остановке сервера, чтобы завершить потоки событий
+	closeStreams sync.Once
+	Router       *chi.Mux
+	validate     *validator.Validate
+	cfg          *config.Config
+	logger       *logger.Logger
 }
 
 type ErrorResponse struct {
@@ -43,16 +70,29 @@ var ErrValidationError = errors.New("validation error")
 var ErrInvalidID = errors.New("invalid ID")
 var ErrInvalidToken = errors.New("invalid token")
 var ErrNotFound = errors.New("not found")
+var ErrInvalidQuery = errors.New("invalid query parameters")
+
+// DeviceHeader — заголовок, в котором клиент передаёт идентификатор своего устройства.
+const DeviceHeader = "X-Device-ID"
 
 // NewHandler создаёт и настраивает HTTP-обработчик со всеми маршрутами и middleware.
 // Использует:
 //   - стандартные middleware chi (RequestID, Logger, Recoverer и др.)
+//   - учёт запросов в метриках Prometheus и трассировку OpenTelemetry
+//   - логгер запроса с ID запроса, маршрутом, пользователем и трассой (см. logger.Logger.For)
 //   - CORS (разрешает все источники)
-//   - JWT-аутентификацию для защищённых маршрутов
+//   - JWT-аутентификацию для защищённых маршрутов и проверку сертификата устройства из TLS-соединения
+//   - заголовок Idempotency-Key для изменяющих запросов к секретам, корзине и папкам
 func NewHandler(
 	users service.UserService,
 	auth service.AuthService,
 	secrets service.SecretService,
+	folders service.FolderService,
+	idempotency service.IdempotencyService,
+	webhooks service.WebhookService,
+	health service.HealthService,
+	devices service.DeviceService,
+	subscriber events.Subscriber,
 	cfg *config.Config,
 ) *Handler {
 	router := chi.NewRouter()
@@ -60,24 +100,93 @@ func NewHandler(
 	router.Use(chiMiddleware.RequestID)
 	router.Use(chiMiddleware.RealIP)
 	router.Use(chiMiddleware.Logger)
+	router.Use(middleware.Metrics)
+	router.Use(middleware.Tracing)
+	router.Use(middleware.RequestLogger)
+	router.Use(middleware.DeviceCertificate(devices))
 	router.Use(chiMiddleware.SetHeader("Content-Type", "application/json"))
 	router.Use(chiMiddleware.Recoverer)
 	router.Use(cors.AllowAll().Handler)
-	h := &Handler{users: users, auth: auth, secrets: secrets, Router: router, validate: validate, cfg: cfg, logger: logger.NewLogger()}
+	h := &Handler{
+		users:       users,
+		auth:        auth,
+		secrets:     secrets,
+		folders:     folders,
+		idempotency: idempotency,
+		webhooks:    webhooks,
+		health:      health,
+		devices:     devices,
+		events:      subscriber,
+		streams:     make(chan struct{}),
+		Router:      router,
+		validate:    validate,
+		cfg:         cfg,
+		logger:      logger.NewLogger(),
+	}
+	requireDevice := cfg.DeviceCertMode == config.DeviceCertRequired
 
 	h.Router.Route("/v1.0/users", func(r chi.Router) {
-		r.Use(middleware.RequestAuth(cfg.AccessTokenSecret))
-
-		r.Route("/{id}", func(r chi.Router) {
-			r.Get("/", h.GetUserByID)
-		})
+		r.With(middleware.RequestAuthSameID(cfg.AccessTokenSecret, requireDevice)).Get("/{user_id}", h.GetUserByID)
 	})
 
 	h.Router.Route("/v1.0/secrets", func(r chi.Router) {
-		r.With(middleware.RequestAuth(cfg.AccessTokenSecret)).Post("/", h.CreateSecret)
-		r.With(middleware.RequestAuth(cfg.AccessTokenSecret)).Get("/{id:[0-9]+}", h.GetSecretByID)
-		r.With(middleware.RequestAuth(cfg.AccessTokenSecret)).Delete("/{id:[0-9]+}", h.DeleteSecretByID)
-		r.With(middleware.RequestAuthSameID(cfg.AccessTokenSecret)).Get("/user/{user_id:[0-9]+}", h.GetAllSecretsByUserID)
+		r.With(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice), h.idempotent).Post("/", h.CreateSecret)
+		r.With(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice)).Get("/", h.GetSecrets)
+		r.With(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice)).Get("/summaries", h.GetSecretSummaries)
+		r.With(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice)).Get("/export", h.ExportSecrets)
+		r.With(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice), h.idempotent).Post("/batch", h.BatchSecrets)
+		r.With(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice)).Get("/events", h.SecretEvents)
+		r.With(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice)).Get("/{id:[0-9]+}", h.GetSecretByID)
+		r.With(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice), h.idempotent).Put("/{id:[0-9]+}", h.UpdateSecret)
+		r.With(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice)).Get("/{id:[0-9]+}/audit", h.GetSecretAudit)
+		r.With(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice)).Get("/{id:[0-9]+}/versions", h.GetSecretVersions)
+		r.With(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice)).Get("/{id:[0-9]+}/versions/{version:[0-9]+}", h.GetSecretVersion)
+		r.With(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice), h.idempotent).Post("/{id:[0-9]+}/versions/{version:[0-9]+}/restore", h.RestoreSecretVersion)
+		r.With(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice), h.idempotent).Delete("/{id:[0-9]+}", h.DeleteSecretByID)
+		r.With(middleware.RequestAuthSameID(cfg.AccessTokenSecret, requireDevice)).Get("/user/{user_id:[0-9]+}", h.GetAllSecretsByUserID)
+	})
+
+	h.Router.Route("/v1.0/trash", func(r chi.Router) {
+		r.Use(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice))
+		r.Use(h.idempotent)
+
+		r.Get("/", h.GetTrash)
+		r.Post("/{id:[0-9]+}/restore", h.RestoreFromTrash)
+		r.Delete("/{id:[0-9]+}", h.PurgeSecret)
+	})
+
+	h.Router.Route("/v1.0/folders", func(r chi.Router) {
+		r.Use(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice))
+		r.Use(h.idempotent)
+
+		r.Post("/", h.CreateFolder)
+		r.Get("/", h.GetFolders)
+		r.Put("/{id:[0-9]+}/name", h.RenameFolder)
+		r.Put("/{id:[0-9]+}/parent", h.MoveFolder)
+	})
+
+	h.Router.Route("/v1.0/webhooks", func(r chi.Router) {
+		r.Use(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice))
+		r.Use(h.idempotent)
+
+		r.Post("/", h.CreateWebhook)
+		r.Get("/", h.GetWebhooks)
+		r.Delete("/{id:[0-9]+}", h.DeleteWebhook)
+		r.Get("/{id:[0-9]+}/deliveries", h.GetWebhookDeliveries)
+	})
+
+	h.Router.Route("/v1.0/devices", func(r chi.Router) {
+		r.Get("/crl", h.GetDeviceRevocationList)
+		r.With(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice)).Get("/", h.GetDevices)
+		r.With(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice)).Delete("/{serial:[0-9a-f]+}", h.RevokeDevice)
+	})
+
+	h.Router.Route("/v1.0/admin", func(r chi.Router) {
+		r.Use(middleware.RequestAuth(cfg.AccessTokenSecret, requireDevice))
+		r.Use(h.requireAdmin)
+
+		r.Get("/users/{id:[0-9]+}/devices", h.GetUserDevices)
+		r.Post("/devices/{serial:[0-9a-f]+}/revoke", h.AdminRevokeDevice)
 	})
 
 	h.Router.Route("/v1.0/auth", func(r chi.Router) {
@@ -85,9 +194,15 @@ func NewHandler(
 		r.Post("/register", h.Register)
 	})
 
-	h.Router.Get("/health", func(w http.ResponseWriter, _ *http.Request) {
+	h.Router.Get("/openapi.json", h.OpenAPI)
+	h.Router.Method(http.MethodGet, "/metrics", metrics.Handler())
+
+	h.Router.Get("/ready", h.Ready)
+
+	h.Router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 		if _, err := w.Write([]byte("ok")); err != nil {
-			h.logger.Log.Error("failed to write health response", zap.Error(err))
+			h.logger.For(r.Context()).Error("failed to write health response", zap.Error(err))
 		}
 	})
 
@@ -106,3 +221,82 @@ func (h *Handler) JSONError(w http.ResponseWriter, statusCode int, errMessage st
 		log.Fatalf("Error encoding JSON: %v", err)
 	}
 }
+
+// userIDFromRequest извлекает ID пользователя из JWT claims, которые middleware авторизации положил в контекст запроса.
+func (h *Handler) userIDFromRequest(r *http.Request) (uint64, error) {
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		return 0, ErrInvalidToken
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	return userID, nil
+}
+
+// requestMeta возвращает сведения об источнике запроса для журнала аудита.
+// IP-адрес берётся из RemoteAddr, который middleware RealIP заполняет по заголовкам прокси,
+// устройство — из сертификата устройства, а если клиент его не предъявил, из заголовка X-Device-ID.
+// Значения обрезаются до размеров колонок в БД.
+func requestMeta(r *http.Request) models.RequestMetaDTO {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+	device := r.Header.Get(DeviceHeader)
+	if cert, ok := utils.GetDeviceFromContext(r.Context()); ok {
+		device = cert.Device
+	}
+	return models.RequestMetaDTO{
+		IP:        truncate(ip, 64),
+		UserAgent: truncate(r.UserAgent(), 255),
+		Device:    truncate(device, 100),
+	}
+}
+
+// truncate обрезает строку до n символов.
+func truncate(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
+
+// decodeJSONBody читает и валидирует JSON-тело запроса.
+// При ошибке сам отправляет ответ и возвращает false.
+func (h *Handler) decodeJSONBody(w http.ResponseWriter, r *http.Request, dto any) bool {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.logger.For(r.Context()).Error("Не удалось прочитать тело запроса", zap.Error(err))
+		h.JSONError(w, http.StatusBadRequest, "cannot read body")
+		return false
+	}
+	defer r.Body.Close()
+
+	if err := json.Unmarshal(body, dto); err != nil {
+		h.logger.For(r.Context()).Warn("Невалидный JSON в теле запроса", zap.Error(err))
+		h.JSONError(w, http.StatusBadRequest, "invalid JSON")
+		return false
+	}
+	if err := h.validate.Struct(dto); err != nil {
+		h.logger.For(r.Context()).Warn("Ошибка валидации входных данных", zap.Error(err))
+		h.JSONError(w, http.StatusUnprocessableEntity, ErrValidationError.Error())
+		return false
+	}
+	return true
+}
+
+// writeJSON сериализует ответ в JSON и отправляет его с указанным статусом.
+func (h *Handler) writeJSON(w http.ResponseWriter, statusCode int, v any) {
+	resp, err := json.Marshal(v)
+	if err != nil {
+		h.logger.Log.Error("Ошибка сериализации ответа", zap.Error(err))
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.WriteHeader(statusCode)
+	if _, err = w.Write(resp); err != nil {
+		h.logger.Log.Error("Ошибка отправки ответа клиенту", zap.Error(err))
+	}
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// Ready — проверка готовности сервера принимать запросы: доступность базы данных и состояние миграций.
+// В отличие от /health (проверки живости) обращается к базе, поэтому оркестратор по ней
+// решает, направлять ли трафик на экземпляр.
+//
+// Возвращает:
+//   - 200 OK — все зависимости доступны
+//   - 503 Service Unavailable — хотя бы одна зависимость недоступна; в теле — результаты проверок
+func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
+	result := h.health.Check(r.Context())
+	status := http.StatusOK
+	if result.Status != models.HealthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	h.writeJSON(w, status, result)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Ready(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	health := mocks.NewMockHealthService(ctrl)
+	cfg := config.GetConfig()
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, health, nil, nil, &cfg)
+	server := httptest.NewServer(handler.Router)
+	defer server.Close()
+
+	t.Run("Ready", func(t *testing.T) {
+		health.EXPECT().Check(gomock.Any()).Return(&models.ReadinessDTO{
+			Status:     models.HealthStatusOK,
+			Database:   models.HealthCheckDTO{Status: models.HealthStatusOK},
+			Migrations: models.MigrationStatusDTO{Status: models.HealthStatusOK, Version: 8},
+			Keys:       models.KeyStatusDTO{Status: models.HealthStatusOK},
+		})
+
+		var result models.ReadinessDTO
+		resp, err := resty.New().R().SetResult(&result).Get(server.URL + "/ready")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Equal(t, uint(8), result.Migrations.Version)
+	})
+
+	t.Run("Not_ready", func(t *testing.T) {
+		health.EXPECT().Check(gomock.Any()).Return(&models.ReadinessDTO{
+			Status:   models.HealthStatusUnavailable,
+			Database: models.HealthCheckDTO{Status: models.HealthStatusUnavailable, Error: "connection refused"},
+		})
+
+		resp, err := resty.New().R().Get(server.URL + "/ready")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode())
+		assert.Contains(t, string(resp.Body()), "connection refused")
+	})
+
+	t.Run("Keys_not_loaded", func(t *testing.T) {
+		health.EXPECT().Check(gomock.Any()).Return(&models.ReadinessDTO{
+			Status:     models.HealthStatusUnavailable,
+			Database:   models.HealthCheckDTO{Status: models.HealthStatusOK},
+			Migrations: models.MigrationStatusDTO{Status: models.HealthStatusOK, Version: 8},
+			Keys:       models.KeyStatusDTO{Status: models.HealthStatusUnavailable, Error: "device CA: not loaded"},
+		})
+
+		var result models.ReadinessDTO
+		resp, err := resty.New().R().SetError(&result).Get(server.URL + "/ready")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode())
+		assert.Equal(t, models.KeyStatusDTO{Status: models.HealthStatusUnavailable, Error: "device CA: not loaded"}, result.Keys)
+	})
+}
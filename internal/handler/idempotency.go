@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/service"
+)
+
+// IdempotencyKeyHeader — заголовок, в котором клиент передаёт ключ идемпотентности изменяющего запроса.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader — заголовок, которым помечается ответ, повторённый по ключу идемпотентности.
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLen — максимальная длина ключа идемпотентности (совпадает с размером колонки в БД).
+const maxIdempotencyKeyLen = 255
+
+// idempotent — middleware для изменяющих запросов с заголовком Idempotency-Key.
+// Первый запрос с ключом выполняется, а его ответ сохраняется; повтор того же запроса (метод, путь и тело)
+// с тем же ключом получает сохранённый ответ без повторного выполнения. Ответы 5xx не сохраняются,
+// чтобы запрос можно было повторить; паника обработчика и ошибка сохранения ответа тоже освобождают ключ.
+// Запросы без заголовка и безопасные методы проходят без изменений, как и все запросы, если срок хранения
+// ключей (cfg.IdempotencyKeyTTL) не задан.
+// Должен подключаться после middleware авторизации: ключи хранятся отдельно для каждого пользователя.
+//
+// Возвращает:
+//   - 400 Bad Request — если ключ длиннее 255 символов
+//   - 409 Conflict — если запрос с тем же ключом ещё выполняется
+//   - 422 Unprocessable Entity — если ключ уже использован для другого запроса
+func (h *Handler) idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || h.cfg.IdempotencyKeyTTL <= 0 || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len([]rune(key)) > maxIdempotencyKeyLen {
+			h.JSONError(w, http.StatusBadRequest, "idempotency key is too long")
+			return
+		}
+		userID, err := h.userIDFromRequest(r)
+		if err != nil {
+			h.logger.For(r.Context()).Warn("Токен отсутствует или невалиден", zap.Error(err))
+			h.JSONError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			h.logger.For(r.Context()).Error("Не удалось прочитать тело запроса", zap.Error(err))
+			h.JSONError(w, http.StatusBadRequest, "cannot read body")
+			return
+		}
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		rec, err := h.idempotency.Begin(r.Context(), userID, key, requestHash)
+		switch {
+		case errors.Is(err, service.ErrIdempotencyKeyInProgress):
+			h.JSONError(w, http.StatusConflict, err.Error())
+			return
+		case errors.Is(err, service.ErrIdempotencyKeyReused):
+			h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		case err != nil:
+			h.logger.For(r.Context()).Error("Ошибка при проверке ключа идемпотентности", zap.Uint64("user_id", userID), zap.Error(err))
+			h.JSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		case rec != nil:
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(rec.StatusCode)
+			if _, err := w.Write(rec.Response); err != nil {
+				h.logger.For(r.Context()).Error("Ошибка отправки сохранённого ответа", zap.Error(err))
+			}
+			return
+		}
+
+		// Если обработчик запаниковал, ключ освобождается сразу, а не остаётся занятым до истечения срока хранения
+		defer func() {
+			if p := recover(); p != nil {
+				if err := h.idempotency.Release(context.WithoutCancel(r.Context()), userID, key); err != nil {
+					h.logger.For(r.Context()).Error("Не удалось освободить ключ идемпотентности после паники", zap.Uint64("user_id", userID), zap.Error(err))
+				}
+				panic(p)
+			}
+		}()
+
+		var resp bytes.Buffer
+		ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		ww.Tee(&resp)
+		next.ServeHTTP(ww, r)
+
+		// Ответ сохраняется, даже если клиент уже отключился и контекст запроса отменён
+		ctx := context.WithoutCancel(r.Context())
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if status < http.StatusInternalServerError {
+			err := h.idempotency.Complete(ctx, userID, key, status, resp.Bytes())
+			if err == nil {
+				return
+			}
+			h.logger.For(r.Context()).Error("Не удалось сохранить ответ по ключу идемпотентности, ключ освобождается", zap.Uint64("user_id", userID), zap.Error(err))
+		}
+		// Без сохранённого ответа ключ освобождается, чтобы повтор запроса не получал 409 до истечения аренды
+		if err := h.idempotency.Release(ctx, userID, key); err != nil {
+			h.logger.For(r.Context()).Error("Не удалось освободить ключ идемпотентности", zap.Uint64("user_id", userID), zap.Error(err))
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/service"
+	"github.com/shekshuev/gophkeeper/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Idempotency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secrets := mocks.NewMockSecretService(ctrl)
+	idempotency := mocks.NewMockIdempotencyService(ctrl)
+	os.Setenv("ACCESS_TOKEN_SECRET", "test")
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	handler := NewHandler(nil, nil, secrets, nil, idempotency, nil, nil, nil, nil, &cfg)
+	server := httptest.NewServer(handler.Router)
+	defer server.Close()
+
+	accessToken, _ := utils.CreateToken(cfg.AccessTokenSecret, "42", cfg.AccessTokenExpires)
+	body := `{"title":"Mail","data":{"text":"x"}}`
+	create := func(key string) *resty.Response {
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			SetHeader(IdempotencyKeyHeader, key).
+			SetBody(body).
+			Post(server.URL + "/v1.0/secrets")
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("First_request", func(t *testing.T) {
+		idempotency.EXPECT().Begin(gomock.Any(), uint64(42), "key-1", gomock.Any()).Return(nil, nil)
+		secrets.EXPECT().Create(gomock.Any(), gomock.Any()).Return(uint64(7), nil)
+		idempotency.EXPECT().Complete(gomock.Any(), uint64(42), "key-1", http.StatusCreated, []byte(`{"id":7}`)).Return(nil)
+
+		resp := create("key-1")
+		assert.Equal(t, http.StatusCreated, resp.StatusCode())
+		assert.Empty(t, resp.Header().Get(IdempotentReplayedHeader))
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		idempotency.EXPECT().
+			Begin(gomock.Any(), uint64(42), "key-1", gomock.Any()).
+			Return(&models.IdempotencyRecordDTO{StatusCode: http.StatusCreated, Response: []byte(`{"id":7}`)}, nil)
+
+		resp := create("key-1")
+		assert.Equal(t, http.StatusCreated, resp.StatusCode())
+		assert.Equal(t, `{"id":7}`, resp.String())
+		assert.Equal(t, "true", resp.Header().Get(IdempotentReplayedHeader))
+	})
+
+	t.Run("Same_request_same_hash", func(t *testing.T) {
+		var hashes []string
+		idempotency.EXPECT().Begin(gomock.Any(), uint64(42), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ any, _ uint64, _ string, hash string) (*models.IdempotencyRecordDTO, error) {
+				hashes = append(hashes, hash)
+				return nil, service.ErrIdempotencyKeyInProgress
+			}).Times(2)
+
+		assert.Equal(t, http.StatusConflict, create("key-2").StatusCode())
+		assert.Equal(t, http.StatusConflict, create("key-3").StatusCode())
+		assert.Equal(t, hashes[0], hashes[1])
+	})
+
+	t.Run("Reused", func(t *testing.T) {
+		idempotency.EXPECT().Begin(gomock.Any(), uint64(42), "key-1", gomock.Any()).Return(nil, service.ErrIdempotencyKeyReused)
+		assert.Equal(t, http.StatusUnprocessableEntity, create("key-1").StatusCode())
+	})
+
+	t.Run("Server_error_releases_key", func(t *testing.T) {
+		idempotency.EXPECT().Begin(gomock.Any(), uint64(42), "key-4", gomock.Any()).Return(nil, nil)
+		secrets.EXPECT().Create(gomock.Any(), gomock.Any()).Return(uint64(0), errors.New("db error"))
+		idempotency.EXPECT().Release(gomock.Any(), uint64(42), "key-4").Return(nil)
+
+		assert.Equal(t, http.StatusInternalServerError, create("key-4").StatusCode())
+	})
+
+	t.Run("Complete_error_releases_key", func(t *testing.T) {
+		idempotency.EXPECT().Begin(gomock.Any(), uint64(42), "key-6", gomock.Any()).Return(nil, nil)
+		secrets.EXPECT().Create(gomock.Any(), gomock.Any()).Return(uint64(9), nil)
+		idempotency.EXPECT().Complete(gomock.Any(), uint64(42), "key-6", http.StatusCreated, gomock.Any()).Return(errors.New("db error"))
+		idempotency.EXPECT().Release(gomock.Any(), uint64(42), "key-6").Return(nil)
+
+		assert.Equal(t, http.StatusCreated, create("key-6").StatusCode())
+	})
+
+	t.Run("Panic_releases_key", func(t *testing.T) {
+		idempotency.EXPECT().Begin(gomock.Any(), uint64(42), "key-5", gomock.Any()).Return(nil, nil)
+		secrets.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ models.CreateSecretDTO) (uint64, error) {
+			panic("boom")
+		})
+		idempotency.EXPECT().Release(gomock.Any(), uint64(42), "key-5").Return(nil)
+
+		assert.Equal(t, http.StatusInternalServerError, create("key-5").StatusCode())
+	})
+
+	t.Run("Begin_error", func(t *testing.T) {
+		idempotency.EXPECT().Begin(gomock.Any(), uint64(42), "key-5", gomock.Any()).Return(nil, errors.New("db error"))
+		assert.Equal(t, http.StatusInternalServerError, create("key-5").StatusCode())
+	})
+
+	t.Run("Key_too_long", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, create(strings.Repeat("k", 256)).StatusCode())
+	})
+
+	t.Run("Without_key", func(t *testing.T) {
+		secrets.EXPECT().Create(gomock.Any(), gomock.Any()).Return(uint64(8), nil)
+		assert.Equal(t, http.StatusCreated, create("").StatusCode())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		ttl := cfg.IdempotencyKeyTTL
+		cfg.IdempotencyKeyTTL = 0
+		defer func() { cfg.IdempotencyKeyTTL = ttl }()
+		secrets.EXPECT().Create(gomock.Any(), gomock.Any()).Return(uint64(10), nil)
+
+		assert.Equal(t, http.StatusCreated, create("key-7").StatusCode(), "keys are not reserved when IDEMPOTENCY_KEY_TTL is 0")
+	})
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	_ "embed"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// openAPISpec — спецификация OpenAPI 3 всех маршрутов сервера.
+// При добавлении или изменении маршрута спецификацию нужно обновить: TestOpenAPI_RoutesDocumented
+// проверяет, что описан каждый маршрут, а TestOpenAPI_Responses — что ответы соответствуют схемам.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// OpenAPI — обработчик, отдающий спецификацию OpenAPI 3 для генерации клиентов.
+//
+// Возвращает:
+//   - 200 OK — документ OpenAPI в формате JSON
+func (h *Handler) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	if _, err := w.Write(openAPISpec); err != nil {
+		h.logger.For(r.Context()).Error("Ошибка отправки спецификации OpenAPI", zap.Error(err))
+	}
+}
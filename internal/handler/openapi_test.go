@@ -0,0 +1,347 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/service"
+	"github.com/shekshuev/gophkeeper/internal/utils"
+)
+
+// loadOpenAPI загружает и проверяет встроенную спецификацию.
+func loadOpenAPI(t *testing.T) *openapi3.T {
+	doc, err := openapi3.NewLoader().LoadFromData(openAPISpec)
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+	return doc
+}
+
+// routeParam убирает регулярные выражения из параметров маршрутов chi: {id:[0-9]+} -> {id}.
+var routeParam = regexp.MustCompile(`\{([a-z_]+):[^}]+\}`)
+
+// openAPIPath преобразует шаблон маршрута chi в путь спецификации.
+func openAPIPath(pattern string) string {
+	path := routeParam.ReplaceAllString(pattern, "{$1}")
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+func TestOpenAPI_Served(t *testing.T) {
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+	server := httptest.NewServer(handler.Router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/openapi.json")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, openAPISpec, body)
+	loadOpenAPI(t)
+}
+
+func TestOpenAPI_RoutesDocumented(t *testing.T) {
+	doc := loadOpenAPI(t)
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+
+	registered := make(map[string]bool)
+	err := chi.Walk(handler.Router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		path := openAPIPath(route)
+		registered[method+" "+path] = true
+		item := doc.Paths.Value(path)
+		if assert.NotNil(t, item, "route %s is not documented", path) {
+			assert.NotNil(t, item.GetOperation(method), "operation %s %s is not documented", method, path)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			assert.True(t, registered[method+" "+path], "documented operation %s %s is not registered", method, path)
+		}
+	}
+}
+
+// findOperation ищет в спецификации путь, которому соответствует URL запроса, и извлекает параметры пути.
+// Если URL подходит под несколько путей (/v1.0/devices/crl и /v1.0/devices/{serial}), выбирается путь
+// с меньшим числом параметров, как и в маршрутизаторе chi.
+func findOperation(doc *openapi3.T, urlPath string) (string, map[string]string) {
+	found, foundParams := "", map[string]string(nil)
+	for path := range doc.Paths.Map() {
+		names := pathParam.FindAllStringSubmatch(path, -1)
+		re := regexp.MustCompile("^" + pathParam.ReplaceAllString(regexp.QuoteMeta(path), `([^/]+)`) + "$")
+		if match := re.FindStringSubmatch(urlPath); match != nil && (found == "" || len(names) < len(foundParams)) {
+			params := make(map[string]string)
+			for i, name := range names {
+				params[name[1]] = match[i+1]
+			}
+			found, foundParams = path, params
+		}
+	}
+	return found, foundParams
+}
+
+// pathParam — параметр пути в спецификации: {id} (в том числе после regexp.QuoteMeta).
+var pathParam = regexp.MustCompile(`\\?\{([a-z_]+)\\?\}`)
+
+// closedFeed — подписка на ленту изменений, которая сразу закрыта: поток событий завершается
+// после изменений, восстановленных по Last-Event-ID, и ответ можно проверить целиком.
+type closedFeed struct{}
+
+func (closedFeed) Subscribe(uint64) (<-chan models.SecretEventDTO, func()) {
+	feed := make(chan models.SecretEventDTO)
+	close(feed)
+	return feed, func() {}
+}
+
+// responseValidator пропускает запросы через маршрутизатор и проверяет ответы по спецификации.
+type responseValidator struct {
+	t       *testing.T
+	doc     *openapi3.T
+	handler http.Handler
+	covered map[string]bool // Операции, для которых проверен успешный ответ
+}
+
+func (v *responseValidator) do(method, target, body string, headers ...string) {
+	v.t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	v.handler.ServeHTTP(rec, req)
+
+	path, params := findOperation(v.doc, req.URL.Path)
+	require.NotEmpty(v.t, path, "%s %s: route is not documented", method, target)
+	item := v.doc.Paths.Value(path)
+	operation := item.GetOperation(method)
+	require.NotNil(v.t, operation, "%s %s: operation is not documented", method, target)
+
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: params,
+			Route:      &routers.Route{Spec: v.doc, Path: path, PathItem: item, Method: method, Operation: operation},
+		},
+		Status:  rec.Code,
+		Header:  rec.Header(),
+		Body:    io.NopCloser(bytes.NewReader(rec.Body.Bytes())),
+		Options: &openapi3filter.Options{IncludeResponseStatus: true},
+	}
+	err := openapi3filter.ValidateResponse(context.Background(), input)
+	assert.NoError(v.t, err, "%s %s: %d %s", method, target, rec.Code, rec.Body.String())
+	if rec.Code < 300 {
+		v.covered[method+" "+path] = true
+	}
+}
+
+func TestOpenAPI_Responses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	users := mocks.NewMockUserService(ctrl)
+	auth := mocks.NewMockAuthService(ctrl)
+	secrets := mocks.NewMockSecretService(ctrl)
+	folders := mocks.NewMockFolderService(ctrl)
+	idempotency := mocks.NewMockIdempotencyService(ctrl)
+	webhooks := mocks.NewMockWebhookService(ctrl)
+	health := mocks.NewMockHealthService(ctrl)
+	devices := mocks.NewMockDeviceService(ctrl)
+	os.Setenv("ACCESS_TOKEN_SECRET", "test")
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	cfg.AdminUsers = []string{"user_1"}
+	handler := NewHandler(users, auth, secrets, folders, idempotency, webhooks, health, devices, closedFeed{}, &cfg)
+	openapi3filter.RegisterBodyDecoder("text/event-stream", openapi3filter.FileBodyDecoder)
+	defer openapi3filter.UnregisterBodyDecoder("text/event-stream")
+	openapi3filter.RegisterBodyDecoder("application/x-pem-file", openapi3filter.FileBodyDecoder)
+	defer openapi3filter.UnregisterBodyDecoder("application/x-pem-file")
+	v := &responseValidator{t: t, doc: loadOpenAPI(t), handler: handler.Router, covered: make(map[string]bool)}
+
+	token, _ := utils.CreateToken(cfg.AccessTokenSecret, "42", cfg.AccessTokenExpires)
+	bearer := []string{"Authorization", "Bearer " + token, DeviceHeader, "laptop"}
+	now := time.Now().UTC()
+	folderID := uint64(5)
+	secret := &models.ReadSecretDTO{
+		ID: 7, UserID: 42, Title: "Mail", Version: 2, FolderID: &folderID, Tags: []string{"work"}, CreatedAt: now, UpdatedAt: now,
+		Data: models.SecretDataDTO{LoginPassword: &models.LoginPasswordData{Login: "me", Password: "pass"}, Binary: []byte{1, 2}},
+	}
+	summary := models.SecretSummaryDTO{ID: 7, Title: "Mail", Type: models.SecretTypeLogin, Tags: []string{}, Version: 2, CreatedAt: now, UpdatedAt: now}
+	folder := &models.ReadFolderDTO{ID: 5, UserID: 42, Name: "Work", CreatedAt: now, UpdatedAt: now}
+	tokens := &models.ReadTokenDTO{AccessToken: "access", RefreshToken: "refresh"}
+	webhook := &models.ReadWebhookDTO{ID: 3, UserID: 42, URL: "https://example.com/hook", Events: []string{models.WebhookEventSecretChanged}, CreatedAt: now}
+	responseStatus := http.StatusBadGateway
+	device := models.DeviceCertificateDTO{ID: 1, UserID: 42, Device: "laptop", Serial: "0a1b", Thumbprint: "thumb", CreatedAt: now, ExpiresAt: now, RevokedAt: &now}
+
+	users.EXPECT().GetUserByID(gomock.Any(), uint64(42)).Return(&models.ReadUserDTO{ID: 42, UserName: "user_1", CreatedAt: now, UpdatedAt: now}, nil).AnyTimes()
+	users.EXPECT().GetUserByID(gomock.Any(), uint64(404)).Return(nil, service.ErrUserNotFound).AnyTimes()
+	auth.EXPECT().Login(gomock.Any(), gomock.Any()).Return(tokens, nil)
+	auth.EXPECT().Login(gomock.Any(), gomock.Any()).Return(nil, service.ErrWrongPassword)
+	auth.EXPECT().Register(gomock.Any(), gomock.Any()).Return(tokens, nil)
+	secrets.EXPECT().Create(gomock.Any(), gomock.Any()).Return(uint64(7), nil)
+	secrets.EXPECT().Create(gomock.Any(), gomock.Any()).Return(uint64(0), service.ErrFolderNotFound)
+	secrets.EXPECT().GetSummaryPageByUser(gomock.Any(), uint64(42), gomock.Any()).Return(&models.SecretSummaryPageDTO{Items: []models.SecretSummaryDTO{summary}, NextCursor: "abc"}, nil)
+	secrets.EXPECT().GetSummaryPageByUser(gomock.Any(), uint64(42), gomock.Any()).Return(&models.SecretSummaryPageDTO{Items: []models.SecretSummaryDTO{summary}}, nil)
+	secrets.EXPECT().ExportPageByUser(gomock.Any(), uint64(42), gomock.Any(), gomock.Any()).Return(&models.SecretPageDTO{Items: []models.ReadSecretDTO{*secret}, NextCursor: "abc"}, nil)
+	secrets.EXPECT().GetAllByUser(gomock.Any(), uint64(42), gomock.Any()).Return(nil, nil)
+	secrets.EXPECT().Reveal(gomock.Any(), uint64(42), uint64(7), gomock.Any()).Return(secret, nil)
+	secrets.EXPECT().Reveal(gomock.Any(), uint64(42), uint64(8), gomock.Any()).Return(nil, service.ErrSecretNotFound)
+	secrets.EXPECT().Update(gomock.Any(), gomock.Any()).Return(secret, nil)
+	secrets.EXPECT().DeleteByID(gomock.Any(), uint64(42), uint64(7)).Return(nil)
+	secrets.EXPECT().GetAuditBySecret(gomock.Any(), uint64(42), uint64(7)).
+		Return([]models.ReadSecretAuditDTO{{ID: 1, SecretID: 7, Action: models.SecretActionReveal, IP: "127.0.0.1", CreatedAt: now}}, nil)
+	secrets.EXPECT().GetVersions(gomock.Any(), uint64(42), uint64(7)).
+		Return([]models.SecretVersionDTO{{Version: 1, Title: "Mail", Type: models.SecretTypeLogin, CreatedAt: now, ArchivedAt: now}}, nil)
+	secrets.EXPECT().GetVersion(gomock.Any(), uint64(42), uint64(7), 1, gomock.Any()).
+		Return(&models.ReadSecretVersionDTO{SecretID: 7, Version: 1, Title: "Mail", Data: secret.Data, CreatedAt: now, ArchivedAt: now}, nil)
+	secrets.EXPECT().Restore(gomock.Any(), uint64(42), uint64(7), 1, "laptop").Return(secret, nil)
+	secrets.EXPECT().Batch(gomock.Any(), uint64(42), gomock.Any(), false, "laptop").
+		Return([]models.BatchOutcome{{ID: 10, Version: 1}, {ID: 3, Err: service.ErrSecretNotFound}}, nil)
+	secrets.EXPECT().Batch(gomock.Any(), uint64(42), gomock.Any(), true, "laptop").
+		Return([]models.BatchOutcome{{ID: 3, Err: service.ErrSecretNotFound}}, nil)
+	secrets.EXPECT().GetChangesAfter(gomock.Any(), uint64(42), uint64(1), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ uint64, send func(models.SecretEventDTO) error) error {
+			return send(models.SecretEventDTO{ID: 2, Type: models.SecretEventUpdated, UserID: 42, SecretID: 7, Version: 2, Time: now})
+		})
+	secrets.EXPECT().GetTrash(gomock.Any(), uint64(42)).
+		Return([]models.TrashedSecretDTO{{ID: 7, Title: "Mail", Type: models.SecretTypeText, Version: 1, CreatedAt: now, UpdatedAt: now, DeletedAt: now}}, nil)
+	secrets.EXPECT().RestoreFromTrash(gomock.Any(), uint64(42), uint64(7)).Return(nil)
+	secrets.EXPECT().Purge(gomock.Any(), uint64(42), uint64(7)).Return(nil)
+	secrets.EXPECT().Purge(gomock.Any(), uint64(42), uint64(8)).Return(service.ErrSecretNotFound)
+	folders.EXPECT().Create(gomock.Any(), gomock.Any()).Return(folder, nil)
+	folders.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, service.ErrFolderExists)
+	folders.EXPECT().GetAllByUser(gomock.Any(), uint64(42)).Return([]models.ReadFolderDTO{*folder}, nil)
+	folders.EXPECT().Rename(gomock.Any(), uint64(42), uint64(5), gomock.Any()).Return(folder, nil)
+	folders.EXPECT().Move(gomock.Any(), uint64(42), uint64(5), gomock.Any()).Return(folder, nil)
+	folders.EXPECT().Move(gomock.Any(), uint64(42), uint64(6), gomock.Any()).Return(nil, service.ErrFolderCycle)
+	webhooks.EXPECT().Create(gomock.Any(), gomock.Any()).Return(&models.ReadWebhookDTO{ID: 3, UserID: 42, URL: webhook.URL, Events: webhook.Events, Secret: "key", CreatedAt: now}, nil)
+	webhooks.EXPECT().GetAllByUser(gomock.Any(), uint64(42)).Return([]models.ReadWebhookDTO{*webhook}, nil)
+	webhooks.EXPECT().DeleteByID(gomock.Any(), uint64(42), uint64(3)).Return(nil)
+	webhooks.EXPECT().DeleteByID(gomock.Any(), uint64(42), uint64(4)).Return(service.ErrWebhookNotFound)
+	webhooks.EXPECT().GetDeliveries(gomock.Any(), uint64(42), uint64(3)).Return([]models.WebhookDeliveryDTO{
+		{ID: 1, WebhookID: 3, Event: models.WebhookEventSecretChanged, Status: models.WebhookDeliveryDelivered, Attempts: 1, CreatedAt: now, DeliveredAt: &now},
+		{ID: 2, WebhookID: 3, Event: models.WebhookEventSecretChanged, Status: models.WebhookDeliveryPending, Attempts: 1, ResponseStatus: &responseStatus, Error: "unexpected status 502", NextAttemptAt: &now, CreatedAt: now},
+	}, nil)
+	health.EXPECT().Check(gomock.Any()).Return(&models.ReadinessDTO{
+		Status:     models.HealthStatusOK,
+		Database:   models.HealthCheckDTO{Status: models.HealthStatusOK, LatencyMS: 1},
+		Migrations: models.MigrationStatusDTO{Status: models.HealthStatusOK, Version: 8},
+		Keys:       models.KeyStatusDTO{Status: models.HealthStatusOK},
+	})
+	health.EXPECT().Check(gomock.Any()).Return(&models.ReadinessDTO{
+		Status:     models.HealthStatusUnavailable,
+		Database:   models.HealthCheckDTO{Status: models.HealthStatusUnavailable, LatencyMS: 2000, Error: "context deadline exceeded"},
+		Migrations: models.MigrationStatusDTO{Status: models.HealthStatusUnavailable, Error: "context deadline exceeded"},
+		Keys:       models.KeyStatusDTO{Status: models.HealthStatusUnavailable, Error: "device CA: not loaded"},
+	})
+	devices.EXPECT().GetAllByUser(gomock.Any(), uint64(42)).Return([]models.DeviceCertificateDTO{device}, nil).Times(2)
+	devices.EXPECT().RevokeByUser(gomock.Any(), uint64(42), "0a1b").Return(&device, nil)
+	devices.EXPECT().RevokeByUser(gomock.Any(), uint64(42), "ffff").Return(nil, service.ErrDeviceCertificateNotFound)
+	devices.EXPECT().RevocationList(gomock.Any()).Return([]byte("-----BEGIN X509 CRL-----\n-----END X509 CRL-----\n"), nil)
+	devices.EXPECT().RevocationList(gomock.Any()).Return(nil, service.ErrDeviceCertsDisabled)
+	devices.EXPECT().Revoke(gomock.Any(), "0a1b").Return(&device, nil)
+	auth.EXPECT().Login(gomock.Any(), gomock.Any()).Return(nil, service.ErrInvalidCSR)
+	idempotency.EXPECT().Begin(gomock.Any(), uint64(42), "replayed", gomock.Any()).
+		Return(&models.IdempotencyRecordDTO{StatusCode: http.StatusCreated, Response: []byte(`{"id":7}`)}, nil)
+	idempotency.EXPECT().Begin(gomock.Any(), uint64(42), "busy", gomock.Any()).Return(nil, service.ErrIdempotencyKeyInProgress)
+
+	login := `{"user_name":"user_1","password":"Passw0rd!"}`
+	secretBody := `{"title":"Mail","data":{"text":"x"},"folder_id":5,"tags":["work"]}`
+
+	v.do(http.MethodGet, "/health", "")
+	v.do(http.MethodGet, "/ready", "")
+	v.do(http.MethodGet, "/ready", "")
+	v.do(http.MethodGet, "/openapi.json", "")
+	v.do(http.MethodGet, "/metrics", "")
+
+	v.do(http.MethodPost, "/v1.0/auth/login", login)
+	v.do(http.MethodPost, "/v1.0/auth/login", login)
+	v.do(http.MethodPost, "/v1.0/auth/login", `{"user_name":"u"}`)
+	v.do(http.MethodPost, "/v1.0/auth/login", `{"user_name":"user_1","password":"Passw0rd!","csr":"garbage"}`)
+	v.do(http.MethodPost, "/v1.0/auth/register", `{"user_name":"user_1","password":"Passw0rd!","password_confirm":"Passw0rd!","first_name":"John","last_name":"Doe"}`)
+
+	v.do(http.MethodGet, "/v1.0/users/42", "", bearer...)
+	v.do(http.MethodGet, "/v1.0/users/404", "", bearer...)
+	v.do(http.MethodGet, "/v1.0/users/42", "")
+
+	v.do(http.MethodPost, "/v1.0/secrets", secretBody, bearer...)
+	v.do(http.MethodPost, "/v1.0/secrets", secretBody, bearer...)
+	v.do(http.MethodPost, "/v1.0/secrets", `{`, bearer...)
+	v.do(http.MethodPost, "/v1.0/secrets", `{}`, bearer...)
+	v.do(http.MethodPost, "/v1.0/secrets", secretBody, append(bearer, IdempotencyKeyHeader, "replayed")...)
+	v.do(http.MethodPost, "/v1.0/secrets", secretBody, append(bearer, IdempotencyKeyHeader, "busy")...)
+	v.do(http.MethodGet, "/v1.0/secrets?limit=1&sort=title", "", bearer...)
+	v.do(http.MethodGet, "/v1.0/secrets?limit=0", "", bearer...)
+	v.do(http.MethodGet, "/v1.0/secrets/summaries", "", bearer...)
+	v.do(http.MethodGet, "/v1.0/secrets/export?limit=1", "", bearer...)
+	v.do(http.MethodGet, "/v1.0/secrets/user/42", "", bearer...)
+	v.do(http.MethodGet, "/v1.0/secrets/7", "", bearer...)
+	v.do(http.MethodGet, "/v1.0/secrets/8", "", bearer...)
+	v.do(http.MethodPut, "/v1.0/secrets/7", secretBody, bearer...)
+	v.do(http.MethodDelete, "/v1.0/secrets/7", "", bearer...)
+	v.do(http.MethodGet, "/v1.0/secrets/7/audit", "", bearer...)
+	v.do(http.MethodGet, "/v1.0/secrets/7/versions", "", bearer...)
+	v.do(http.MethodGet, "/v1.0/secrets/7/versions/1", "", bearer...)
+	v.do(http.MethodPost, "/v1.0/secrets/7/versions/1/restore", "", bearer...)
+	v.do(http.MethodPost, "/v1.0/secrets/batch", `{"operations":[{"op":"create","title":"A"},{"op":"delete","id":3},{"op":"update"}]}`, bearer...)
+	v.do(http.MethodGet, "/v1.0/secrets/events", "", append(bearer, LastEventIDHeader, "1")...)
+	v.do(http.MethodGet, "/v1.0/secrets/events", "", append(bearer, LastEventIDHeader, "yesterday")...)
+	v.do(http.MethodPost, "/v1.0/secrets/batch", `{"atomic":true,"operations":[{"op":"delete","id":3}]}`, bearer...)
+
+	v.do(http.MethodGet, "/v1.0/trash", "", bearer...)
+	v.do(http.MethodPost, "/v1.0/trash/7/restore", "", bearer...)
+	v.do(http.MethodDelete, "/v1.0/trash/7", "", bearer...)
+	v.do(http.MethodDelete, "/v1.0/trash/8", "", bearer...)
+
+	v.do(http.MethodPost, "/v1.0/folders", `{"name":"Work"}`, bearer...)
+	v.do(http.MethodPost, "/v1.0/folders", `{"name":"Work"}`, bearer...)
+	v.do(http.MethodGet, "/v1.0/folders", "", bearer...)
+	v.do(http.MethodPut, "/v1.0/folders/5/name", `{"name":"Job"}`, bearer...)
+	v.do(http.MethodPut, "/v1.0/folders/5/parent", `{"parent_id":null}`, bearer...)
+	v.do(http.MethodPut, "/v1.0/folders/6/parent", `{"parent_id":7}`, bearer...)
+
+	v.do(http.MethodPost, "/v1.0/webhooks", `{"url":"https://example.com/hook","events":["secret.changed"]}`, bearer...)
+	v.do(http.MethodPost, "/v1.0/webhooks", `{"url":"ftp://example.com","events":["secret.changed"]}`, bearer...)
+	v.do(http.MethodGet, "/v1.0/webhooks", "", bearer...)
+	v.do(http.MethodDelete, "/v1.0/webhooks/3", "", bearer...)
+	v.do(http.MethodDelete, "/v1.0/webhooks/4", "", bearer...)
+	v.do(http.MethodGet, "/v1.0/webhooks/3/deliveries", "", bearer...)
+
+	v.do(http.MethodGet, "/v1.0/devices", "", bearer...)
+	v.do(http.MethodDelete, "/v1.0/devices/0a1b", "", bearer...)
+	v.do(http.MethodDelete, "/v1.0/devices/ffff", "", bearer...)
+	v.do(http.MethodGet, "/v1.0/devices/crl", "")
+	v.do(http.MethodGet, "/v1.0/devices/crl", "")
+	v.do(http.MethodGet, "/v1.0/admin/users/42/devices", "", bearer...)
+	v.do(http.MethodPost, "/v1.0/admin/devices/0a1b/revoke", "", bearer...)
+
+	for path, item := range v.doc.Paths.Map() {
+		for method := range item.Operations() {
+			assert.True(t, v.covered[method+" "+path], "no successful response checked for %s %s", method, path)
+		}
+	}
+}
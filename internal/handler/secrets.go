@@ -2,106 +2,325 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 
 	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/service"
 	"github.com/shekshuev/gophkeeper/internal/utils"
 )
 
-// GetSecretByID — обработчик для получения секрета по его ID.
+// GetSecretByID — обработчик для получения секрета текущего пользователя вместе с данными.
+// Каждый успешный просмотр записывается в журнал аудита (IP-адрес и User-Agent клиента).
 // Возвращает JSON с данными секрета или ошибку:
-//   - 404, если ID невалиден или секрет не найден
-//   - 400, если произошла ошибка сериализации
+//   - 401, если токен невалиден или не содержит userID
+//   - 404, если ID невалиден, секрет не найден или принадлежит другому пользователю
+//   - 500, если не удалось получить секрет или записать просмотр в журнал
 func (h *Handler) GetSecretByID(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		h.logger.Log.Warn("Невалидный ID секрета", zap.String("id", idStr), zap.Error(err))
+		h.logger.For(r.Context()).Warn("Невалидный ID секрета", zap.String("id", idStr), zap.Error(err))
 		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
 		return
 	}
 
-	secret, err := h.secrets.GetByID(r.Context(), id)
+	userID, err := h.userIDFromRequest(r)
 	if err != nil {
-		h.logger.Log.Warn("Ошибка получения секрета", zap.Uint64("secret_id", id), zap.Error(err))
-		h.JSONError(w, http.StatusNotFound, err.Error())
+		h.logger.For(r.Context()).Warn("Токен отсутствует или невалиден при получении секрета", zap.Error(err))
+		h.JSONError(w, http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	if secret == nil {
-		h.logger.Log.Warn("Секрет не найден", zap.Uint64("secret_id", id))
+	secret, err := h.secrets.Reveal(r.Context(), userID, id, requestMeta(r))
+	if errors.Is(err, service.ErrSecretNotFound) {
+		h.logger.For(r.Context()).Warn("Секрет не найден", zap.Uint64("secret_id", id), zap.Uint64("user_id", userID))
 		h.JSONError(w, http.StatusNotFound, ErrNotFound.Error())
 		return
 	}
+	if err != nil {
+		h.logger.For(r.Context()).Error("Ошибка получения секрета", zap.Uint64("secret_id", id), zap.Error(err))
+		h.JSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.logger.For(r.Context()).Info("Секрет успешно получен", zap.Uint64("secret_id", id))
+	h.writeJSON(w, http.StatusOK, secret)
+}
 
-	resp, err := json.Marshal(secret)
+// GetSecretAudit — обработчик получения журнала аудита секрета текущего пользователя.
+// Возвращает JSON с массивом записей (последние — первыми) или ошибку:
+//   - 401, если токен невалиден или не содержит userID
+//   - 404, если ID невалиден
+//   - 500, если ошибка на уровне сервиса
+func (h *Handler) GetSecretAudit(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		h.logger.Log.Error("Ошибка сериализации секрета", zap.Uint64("secret_id", id), zap.Error(err))
-		h.JSONError(w, http.StatusBadRequest, err.Error())
+		h.logger.For(r.Context()).Warn("Невалидный ID секрета", zap.String("id", idStr), zap.Error(err))
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
 		return
 	}
 
-	h.logger.Log.Info("Секрет успешно получен", zap.Uint64("secret_id", id))
-	_, err = w.Write(resp)
+	userID, err := h.userIDFromRequest(r)
 	if err != nil {
-		h.logger.Log.Error("Ошибка отправки секрета клиенту", zap.Uint64("secret_id", id), zap.Error(err))
-		h.JSONError(w, http.StatusBadRequest, err.Error())
+		h.logger.For(r.Context()).Warn("Токен отсутствует или невалиден при получении журнала аудита", zap.Error(err))
+		h.JSONError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	records, err := h.secrets.GetAuditBySecret(r.Context(), userID, id)
+	if err != nil {
+		h.logger.For(r.Context()).Error("Ошибка при получении журнала аудита", zap.Uint64("secret_id", id), zap.Error(err))
+		h.JSONError(w, http.StatusInternalServerError, err.Error())
+		return
 	}
+	if records == nil {
+		records = []models.ReadSecretAuditDTO{}
+	}
+
+	h.logger.For(r.Context()).Info("Журнал аудита секрета получен", zap.Uint64("secret_id", id))
+	h.writeJSON(w, http.StatusOK, records)
 }
 
-// GetAllSecretsByUserID — обработчик для получения всех секретов пользователя по его ID.
-// Возвращает JSON с массивом секретов или ошибку:
+// GetAllSecretsByUserID — обработчик для получения метаданных всех секретов пользователя по его ID (без данных).
+// Поддерживает те же query-параметры фильтрации, что и GetSecrets.
+//
+// Возвращает JSON с массивом метаданных секретов или ошибку:
 //   - 404, если ID невалиден или ошибка при получении данных
-//   - 400, если произошла ошибка сериализации
+//   - 400, если query-параметры невалидны или произошла ошибка сериализации
 func (h *Handler) GetAllSecretsByUserID(w http.ResponseWriter, r *http.Request) {
 	userIDStr := chi.URLParam(r, "user_id")
 	userID, err := strconv.ParseUint(userIDStr, 10, 64)
 	if err != nil {
-		h.logger.Log.Warn("Невалидный user_id", zap.String("user_id", userIDStr), zap.Error(err))
+		h.logger.For(r.Context()).Warn("Невалидный user_id", zap.String("user_id", userIDStr), zap.Error(err))
 		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
 		return
 	}
 
-	secrets, err := h.secrets.GetAllByUser(r.Context(), userID)
+	filter, err := h.parseSecretFilter(r)
 	if err != nil {
-		h.logger.Log.Warn("Ошибка при получении секретов", zap.Uint64("user_id", userID), zap.Error(err))
+		h.logger.For(r.Context()).Warn("Невалидные параметры списка секретов", zap.Uint64("user_id", userID), zap.Error(err))
+		h.JSONError(w, http.StatusBadRequest, ErrInvalidQuery.Error())
+		return
+	}
+
+	secrets, err := h.secrets.GetAllByUser(r.Context(), userID, filter)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Ошибка при получении секретов", zap.Uint64("user_id", userID), zap.Error(err))
 		h.JSONError(w, http.StatusNotFound, err.Error())
 		return
 	}
 
 	resp, err := json.Marshal(secrets)
 	if err != nil {
-		h.logger.Log.Error("Ошибка сериализации списка секретов", zap.Uint64("user_id", userID), zap.Error(err))
+		h.logger.For(r.Context()).Error("Ошибка сериализации списка секретов", zap.Uint64("user_id", userID), zap.Error(err))
 		h.JSONError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	h.logger.Log.Info("Секреты успешно получены", zap.Uint64("user_id", userID))
+	h.logger.For(r.Context()).Info("Секреты успешно получены", zap.Uint64("user_id", userID))
 	_, err = w.Write(resp)
 	if err != nil {
-		h.logger.Log.Error("Ошибка отправки ответа клиенту", zap.Uint64("user_id", userID), zap.Error(err))
+		h.logger.For(r.Context()).Error("Ошибка отправки ответа клиенту", zap.Uint64("user_id", userID), zap.Error(err))
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// GetSecrets — обработчик постраничного получения метаданных секретов текущего пользователя.
+// Данные секретов в список не попадают: их можно получить по ID (GetSecretByID) или выгрузкой (ExportSecrets),
+// и каждый такой просмотр записывается в журнал аудита.
+// Поддерживает необязательные query-параметры:
+//   - q — подстрока названия (без учёта регистра)
+//   - type — тип секрета: login, card, text, binary
+//   - folder_id — ID папки (0 — только секреты вне папок)
+//   - tag — тег секрета
+//   - created_from, created_to, updated_from, updated_to — границы дат в RFC 3339 (нижняя включительно)
+//   - sort — поле сортировки: created_at, updated_at, title; с префиксом "-" — по убыванию (по умолчанию -created_at)
+//   - limit — размер страницы от 1 до 500 (по умолчанию 50)
+//   - cursor — курсор из ссылки next предыдущей страницы
+//
+// Возвращает JSON вида {"items": [...], "next": "/v1.0/secrets?...&cursor=..."}; next отсутствует на последней странице.
+// Ошибки:
+//   - 400 Bad Request — если query-параметры или курсор невалидны
+//   - 401 Unauthorized — если токен невалиден или не содержит userID
+//   - 500 Internal Server Error — если ошибка на уровне сервиса
+func (h *Handler) GetSecrets(w http.ResponseWriter, r *http.Request) {
+	h.writeSecretSummaryPage(w, r, "/v1.0/secrets")
+}
+
+// GetSecretSummaries — обработчик постраничного получения метаданных секретов текущего пользователя
+// по прежнему адресу /v1.0/secrets/summaries. Работает так же, как GetSecrets.
+//
+// Deprecated: адрес оставлен для старых клиентов; используйте GET /v1.0/secrets.
+func (h *Handler) GetSecretSummaries(w http.ResponseWriter, r *http.Request) {
+	h.writeSecretSummaryPage(w, r, "/v1.0/secrets/summaries")
+}
+
+// ExportSecrets — обработчик постраничной выгрузки секретов текущего пользователя вместе с данными,
+// например для резервной копии или экспорта в KeePass.
+// Поддерживает те же query-параметры, что и GetSecrets; ссылка next ведёт на следующую страницу выгрузки.
+// Просмотр каждого выгруженного секрета записывается в журнал аудита (IP-адрес и User-Agent клиента).
+//
+// Ошибки:
+//   - 400 Bad Request — если query-параметры или курсор невалидны
+//   - 401 Unauthorized — если токен невалиден или не содержит userID
+//   - 500 Internal Server Error — если не удалось получить секреты или записать просмотр в журнал
+func (h *Handler) ExportSecrets(w http.ResponseWriter, r *http.Request) {
+	userID, filter, ok := h.secretPageRequest(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.secrets.ExportPageByUser(r.Context(), userID, filter, requestMeta(r))
+	if !h.checkSecretPageError(w, r, userID, err) {
+		return
+	}
+	page.Next = nextPageLink(r, "/v1.0/secrets/export", page.NextCursor)
+
+	h.logger.For(r.Context()).Info("Страница секретов с данными выгружена", zap.Uint64("user_id", userID), zap.Int("count", len(page.Items)))
+	h.writeJSON(w, http.StatusOK, page)
+}
+
+// writeSecretSummaryPage отправляет страницу метаданных секретов текущего пользователя.
+// Ссылка next строится от пути path.
+func (h *Handler) writeSecretSummaryPage(w http.ResponseWriter, r *http.Request, path string) {
+	userID, filter, ok := h.secretPageRequest(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.secrets.GetSummaryPageByUser(r.Context(), userID, filter)
+	if !h.checkSecretPageError(w, r, userID, err) {
+		return
+	}
+	page.Next = nextPageLink(r, path, page.NextCursor)
+
+	h.logger.For(r.Context()).Info("Список секретов успешно получен", zap.Uint64("user_id", userID), zap.Int("count", len(page.Items)))
+	h.writeJSON(w, http.StatusOK, page)
+}
+
+// secretPageRequest извлекает из запроса ID текущего пользователя и фильтр списка секретов.
+// Если это не удалось, отправляет ошибку и возвращает false.
+func (h *Handler) secretPageRequest(w http.ResponseWriter, r *http.Request) (uint64, models.SecretFilterDTO, bool) {
+	userID, err := h.userIDFromRequest(r)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Токен отсутствует или невалиден при получении списка секретов", zap.Error(err))
+		h.JSONError(w, http.StatusUnauthorized, err.Error())
+		return 0, models.SecretFilterDTO{}, false
+	}
+
+	filter, err := h.parseSecretFilter(r)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Невалидные параметры списка секретов", zap.Uint64("user_id", userID), zap.Error(err))
+		h.JSONError(w, http.StatusBadRequest, ErrInvalidQuery.Error())
+		return 0, models.SecretFilterDTO{}, false
+	}
+	return userID, filter, true
+}
+
+// checkSecretPageError отправляет ошибку получения страницы секретов, если она есть, и возвращает false.
+func (h *Handler) checkSecretPageError(w http.ResponseWriter, r *http.Request, userID uint64, err error) bool {
+	if errors.Is(err, service.ErrInvalidCursor) {
+		h.logger.For(r.Context()).Warn("Невалидный курсор списка секретов", zap.Uint64("user_id", userID))
 		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	if err != nil {
+		h.logger.For(r.Context()).Error("Ошибка при получении страницы секретов", zap.Uint64("user_id", userID), zap.Error(err))
+		h.JSONError(w, http.StatusInternalServerError, err.Error())
+		return false
+	}
+	return true
+}
+
+// nextPageLink формирует ссылку на следующую страницу: исходные query-параметры запроса с подставленным курсором.
+// Возвращает пустую строку, если курсора нет.
+func nextPageLink(r *http.Request, path, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+	query := r.URL.Query()
+	query.Set("cursor", cursor)
+	return path + "?" + query.Encode()
+}
+
+// parseSecretFilter разбирает query-параметры фильтрации, сортировки и постраничной выборки секретов.
+// Возвращает ошибку, если значение параметра имеет неверный формат или не проходит валидацию.
+func (h *Handler) parseSecretFilter(r *http.Request) (models.SecretFilterDTO, error) {
+	query := r.URL.Query()
+	filter := models.SecretFilterDTO{
+		Tag:   query.Get("tag"),
+		Query: query.Get("q"),
+		Type:  query.Get("type"),
+		Sort:  query.Get("sort"),
+	}
+
+	if s := query.Get("folder_id"); s != "" {
+		folderID, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("folder_id: %w", err)
+		}
+		filter.FolderID = &folderID
+	}
+	if s := query.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 {
+			return filter, fmt.Errorf("limit: invalid value %q", s)
+		}
+		filter.Limit = limit
+	}
+	for name, dst := range map[string]**time.Time{
+		"created_from": &filter.CreatedFrom,
+		"created_to":   &filter.CreatedTo,
+		"updated_from": &filter.UpdatedFrom,
+		"updated_to":   &filter.UpdatedTo,
+	} {
+		if s := query.Get(name); s != "" {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return filter, fmt.Errorf("%s: %w", name, err)
+			}
+			t = t.UTC()
+			*dst = &t
+		}
+	}
+	if s := query.Get("cursor"); s != "" {
+		var cursor models.SecretCursorDTO
+		if err := utils.DecodeCursor(s, &cursor); err != nil {
+			return filter, err
+		}
+		filter.After = &cursor
 	}
+
+	if err := h.validate.Struct(filter); err != nil {
+		return filter, err
+	}
+	return filter, nil
 }
 
 // CreateSecret — обработчик создания нового секрета.
-// Принимает JSON с полями title и data в теле запроса.
+// Принимает JSON с полями title, data, folder_id и tags в теле запроса.
 // Требует авторизации (по токену). Возвращает ID созданного секрета.
 //
 // Возвращает:
 //   - 201 Created — если успешно
 //   - 400 Bad Request — если JSON невалиден или ошибка сериализации
 //   - 401 Unauthorized — если токен невалиден или не содержит userID
+//   - 404 Not Found — если указанная папка не найдена
+//   - 422 Unprocessable Entity — если входные данные не прошли валидацию
 //   - 500 Internal Server Error — если ошибка на уровне сервиса
 func (h *Handler) CreateSecret(w http.ResponseWriter, r *http.Request) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		h.logger.Log.Error("Не удалось прочитать тело запроса", zap.Error(err))
+		h.logger.For(r.Context()).Error("Не удалось прочитать тело запроса", zap.Error(err))
 		h.JSONError(w, http.StatusBadRequest, "cannot read body")
 		return
 	}
@@ -109,39 +328,51 @@ func (h *Handler) CreateSecret(w http.ResponseWriter, r *http.Request) {
 
 	var dto models.CreateSecretDTO
 	if err := json.Unmarshal(body, &dto); err != nil {
-		h.logger.Log.Warn("Невалидный JSON при создании секрета", zap.Error(err))
+		h.logger.For(r.Context()).Warn("Невалидный JSON при создании секрета", zap.Error(err))
 		h.JSONError(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
 
+	if err := h.validate.Struct(dto); err != nil {
+		h.logger.For(r.Context()).Warn("Ошибка валидации секрета", zap.Error(err))
+		h.JSONError(w, http.StatusUnprocessableEntity, ErrValidationError.Error())
+		return
+	}
+
 	claims, ok := utils.GetClaimsFromContext(r.Context())
 	if !ok {
-		h.logger.Log.Warn("Токен отсутствует или невалиден при создании секрета")
+		h.logger.For(r.Context()).Warn("Токен отсутствует или невалиден при создании секрета")
 		h.JSONError(w, http.StatusUnauthorized, "missing or invalid token")
 		return
 	}
 
 	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
 	if err != nil {
-		h.logger.Log.Warn("Некорректный user ID в токене", zap.String("sub", claims.Subject), zap.Error(err))
+		h.logger.For(r.Context()).Warn("Некорректный user ID в токене", zap.String("sub", claims.Subject), zap.Error(err))
 		h.JSONError(w, http.StatusUnauthorized, "invalid user ID in token")
 		return
 	}
 
 	dto.UserID = userID
+	dto.Device = requestMeta(r).Device
 	id, err := h.secrets.Create(r.Context(), dto)
+	if errors.Is(err, service.ErrFolderNotFound) {
+		h.logger.For(r.Context()).Warn("Папка секрета не найдена", zap.Uint64("user_id", userID), zap.Error(err))
+		h.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
 	if err != nil {
-		h.logger.Log.Error("Ошибка при создании секрета", zap.Uint64("user_id", userID), zap.Error(err))
+		h.logger.For(r.Context()).Error("Ошибка при создании секрета", zap.Uint64("user_id", userID), zap.Error(err))
 		h.JSONError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	h.logger.Log.Info("Секрет успешно создан", zap.Uint64("secret_id", id), zap.Uint64("user_id", userID))
+	h.logger.For(r.Context()).Info("Секрет успешно создан", zap.Uint64("secret_id", id), zap.Uint64("user_id", userID))
 
 	resp := map[string]uint64{"id": id}
 	encoded, err := json.Marshal(resp)
 	if err != nil {
-		h.logger.Log.Error("Ошибка сериализации ID созданного секрета", zap.Uint64("secret_id", id), zap.Error(err))
+		h.logger.For(r.Context()).Error("Ошибка сериализации ID созданного секрета", zap.Uint64("secret_id", id), zap.Error(err))
 		h.JSONError(w, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -149,32 +380,99 @@ func (h *Handler) CreateSecret(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 	_, err = w.Write(encoded)
 	if err != nil {
-		h.logger.Log.Error("Ошибка отправки ответа при создании секрета", zap.Uint64("secret_id", id), zap.Error(err))
+		h.logger.For(r.Context()).Error("Ошибка отправки ответа при создании секрета", zap.Uint64("secret_id", id), zap.Error(err))
 		h.JSONError(w, http.StatusBadRequest, err.Error())
 	}
 }
 
-// DeleteSecretByID — обработчик удаления секрета по ID.
+// UpdateSecret — обработчик изменения секрета текущего пользователя.
+// Принимает JSON с полями title, data, folder_id и tags; предыдущее состояние секрета сохраняется в истории версий
+// вместе с устройством автора (заголовок X-Device-ID).
+//
 // Возвращает:
-//   - 204 No Content — если удаление прошло успешно
-//   - 404 Not Found — если ID невалиден
+//   - 200 OK — обновлённый секрет с новым номером версии
+//   - 400 Bad Request — если JSON невалиден
+//   - 401 Unauthorized — если токен невалиден
+//   - 404 Not Found — если ID невалиден, секрет или папка не найдены
+//   - 422 Unprocessable Entity — если входные данные не прошли валидацию
 //   - 500 Internal Server Error — если ошибка на уровне сервиса
-func (h *Handler) DeleteSecretByID(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdateSecret(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.secretRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var dto models.UpdateSecretDTO
+	if !h.decodeJSONBody(w, r, &dto) {
+		return
+	}
+	dto.ID = id
+	dto.UserID = userID
+	dto.Device = requestMeta(r).Device
+
+	secret, err := h.secrets.Update(r.Context(), dto)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Ошибка при изменении секрета", zap.Uint64("secret_id", id), zap.Error(err))
+		h.JSONError(w, secretErrorStatus(err), err.Error())
+		return
+	}
+
+	h.logger.For(r.Context()).Info("Секрет успешно изменён", zap.Uint64("secret_id", id), zap.Int("version", secret.Version))
+	h.writeJSON(w, http.StatusOK, secret)
+}
+
+// secretRequestIDs извлекает ID пользователя из токена и ID секрета из URL.
+// При ошибке сам отправляет ответ и возвращает ok = false.
+func (h *Handler) secretRequestIDs(w http.ResponseWriter, r *http.Request) (userID, id uint64, ok bool) {
+	userID, err := h.userIDFromRequest(r)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Токен отсутствует или невалиден", zap.Error(err))
+		h.JSONError(w, http.StatusUnauthorized, err.Error())
+		return 0, 0, false
+	}
 	idStr := chi.URLParam(r, "id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
+	id, err = strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		h.logger.Log.Warn("Невалидный ID секрета при удалении", zap.String("id", idStr), zap.Error(err))
+		h.logger.For(r.Context()).Warn("Невалидный ID секрета", zap.String("id", idStr), zap.Error(err))
 		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return 0, 0, false
+	}
+	return userID, id, true
+}
+
+// secretErrorStatus сопоставляет ошибку сервиса секретов с HTTP-статусом.
+func secretErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrSecretNotFound),
+		errors.Is(err, service.ErrSecretVersionNotFound),
+		errors.Is(err, service.ErrFolderNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// DeleteSecretByID — обработчик удаления секрета по ID.
+// Секрет не удаляется сразу, а перемещается в корзину, откуда его можно восстановить.
+//
+// Возвращает:
+//   - 204 No Content — если секрет перемещён в корзину
+//   - 401 Unauthorized — если токен невалиден
+//   - 404 Not Found — если ID невалиден или секрет не найден
+//   - 500 Internal Server Error — если ошибка на уровне сервиса
+func (h *Handler) DeleteSecretByID(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.secretRequestIDs(w, r)
+	if !ok {
 		return
 	}
 
-	err = h.secrets.DeleteByID(r.Context(), id)
+	err := h.secrets.DeleteByID(r.Context(), userID, id)
 	if err != nil {
-		h.logger.Log.Error("Ошибка при удалении секрета", zap.Uint64("secret_id", id), zap.Error(err))
-		h.JSONError(w, http.StatusInternalServerError, err.Error())
+		h.logger.For(r.Context()).Warn("Ошибка при удалении секрета", zap.Uint64("secret_id", id), zap.Error(err))
+		h.JSONError(w, secretErrorStatus(err), err.Error())
 		return
 	}
 
-	h.logger.Log.Info("Секрет успешно удалён", zap.Uint64("secret_id", id))
+	h.logger.For(r.Context()).Info("Секрет перемещён в корзину", zap.Uint64("secret_id", id))
 	w.WriteHeader(http.StatusNoContent)
 }
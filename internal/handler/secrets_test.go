@@ -2,17 +2,21 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/golang/mock/gomock"
 	"github.com/shekshuev/gophkeeper/internal/config"
 	"github.com/shekshuev/gophkeeper/internal/mocks"
 	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/service"
 	"github.com/shekshuev/gophkeeper/internal/utils"
 	"github.com/stretchr/testify/assert"
 )
@@ -25,7 +29,7 @@ func TestHandler_GetSecretByID(t *testing.T) {
 	os.Setenv("ACCESS_TOKEN_SECRET", "test")
 	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
 	cfg := config.GetConfig()
-	handler := NewHandler(nil, nil, secrets, &cfg)
+	handler := NewHandler(nil, nil, secrets, nil, nil, nil, nil, nil, nil, &cfg)
 	server := httptest.NewServer(handler.Router)
 	defer server.Close()
 
@@ -33,7 +37,7 @@ func TestHandler_GetSecretByID(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
 		secrets.EXPECT().
-			GetByID(gomock.Any(), gomock.Any()).
+			Reveal(gomock.Any(), uint64(1), uint64(1), models.RequestMetaDTO{IP: "127.0.0.1", UserAgent: "cli-test"}).
 			Return(&models.ReadSecretDTO{
 				ID:     1,
 				UserID: 1,
@@ -42,6 +46,7 @@ func TestHandler_GetSecretByID(t *testing.T) {
 
 		resp, err := resty.New().R().
 			SetHeader("Authorization", "Bearer "+accessToken).
+			SetHeader("User-Agent", "cli-test").
 			Get(server.URL + "/v1.0/secrets/1")
 
 		assert.NoError(t, err)
@@ -59,8 +64,8 @@ func TestHandler_GetSecretByID(t *testing.T) {
 
 	t.Run("Secret_not_found", func(t *testing.T) {
 		secrets.EXPECT().
-			GetByID(gomock.Any(), gomock.Any()).
-			Return(nil, assert.AnError)
+			Reveal(gomock.Any(), uint64(1), uint64(2), gomock.Any()).
+			Return(nil, service.ErrSecretNotFound)
 
 		resp, err := resty.New().R().
 			SetHeader("Authorization", "Bearer "+accessToken).
@@ -70,6 +75,19 @@ func TestHandler_GetSecretByID(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, resp.StatusCode())
 	})
 
+	t.Run("Audit_error", func(t *testing.T) {
+		secrets.EXPECT().
+			Reveal(gomock.Any(), uint64(1), uint64(3), gomock.Any()).
+			Return(nil, assert.AnError)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Get(server.URL + "/v1.0/secrets/3")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode())
+	})
+
 }
 
 func TestHandler_CreateSecret(t *testing.T) {
@@ -81,7 +99,7 @@ func TestHandler_CreateSecret(t *testing.T) {
 	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
 	cfg := config.GetConfig()
 
-	handler := NewHandler(nil, nil, secrets, &cfg)
+	handler := NewHandler(nil, nil, secrets, nil, nil, nil, nil, nil, nil, &cfg)
 	server := httptest.NewServer(handler.Router)
 	defer server.Close()
 
@@ -149,7 +167,7 @@ func TestHandler_GetAllSecretsByUserID(t *testing.T) {
 	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
 	cfg := config.GetConfig()
 
-	handler := NewHandler(nil, nil, secrets, &cfg)
+	handler := NewHandler(nil, nil, secrets, nil, nil, nil, nil, nil, nil, &cfg)
 	server := httptest.NewServer(handler.Router)
 	defer server.Close()
 
@@ -158,10 +176,10 @@ func TestHandler_GetAllSecretsByUserID(t *testing.T) {
 
 	t.Run("Success_same_user_ID", func(t *testing.T) {
 		secrets.EXPECT().
-			GetAllByUser(gomock.Any(), uint64(10)).
-			Return([]models.ReadSecretDTO{
-				{ID: 1, UserID: 10, Title: "First"},
-				{ID: 2, UserID: 10, Title: "Second"},
+			GetAllByUser(gomock.Any(), uint64(10), gomock.Any()).
+			Return([]models.SecretSummaryDTO{
+				{ID: 1, Title: "First"},
+				{ID: 2, Title: "Second"},
 			}, nil)
 
 		resp, err := resty.New().R().
@@ -172,6 +190,29 @@ func TestHandler_GetAllSecretsByUserID(t *testing.T) {
 		assert.Equal(t, http.StatusOK, resp.StatusCode())
 	})
 
+	t.Run("Filter_by_folder_and_tag", func(t *testing.T) {
+		folderID := uint64(3)
+		secrets.EXPECT().
+			GetAllByUser(gomock.Any(), uint64(10), models.SecretFilterDTO{FolderID: &folderID, Tag: "work"}).
+			Return([]models.SecretSummaryDTO{}, nil)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessTokenUser10).
+			Get(server.URL + "/v1.0/secrets/user/10?folder_id=3&tag=work")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+	})
+
+	t.Run("Invalid_folder_id", func(t *testing.T) {
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessTokenUser10).
+			Get(server.URL + "/v1.0/secrets/user/10?folder_id=abc")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	})
+
 	t.Run("Unauthorized_no_token", func(t *testing.T) {
 		resp, err := resty.New().R().
 			Get(server.URL + "/v1.0/secrets/user/10")
@@ -199,6 +240,285 @@ func TestHandler_GetAllSecretsByUserID(t *testing.T) {
 	})
 }
 
+func TestHandler_GetSecrets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secrets := mocks.NewMockSecretService(ctrl)
+	os.Setenv("ACCESS_TOKEN_SECRET", "test")
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	handler := NewHandler(nil, nil, secrets, nil, nil, nil, nil, nil, nil, &cfg)
+	server := httptest.NewServer(handler.Router)
+	defer server.Close()
+
+	accessToken, _ := utils.CreateToken(cfg.AccessTokenSecret, "42", cfg.AccessTokenExpires)
+
+	t.Run("Success_with_next", func(t *testing.T) {
+		from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		cursor, _ := utils.EncodeCursor(models.SecretCursorDTO{Sort: "title", Value: "A", ID: 1})
+		secrets.EXPECT().
+			GetSummaryPageByUser(gomock.Any(), uint64(42), models.SecretFilterDTO{
+				Query:       "bank",
+				Type:        models.SecretTypeCard,
+				CreatedFrom: &from,
+				Sort:        "title",
+				Limit:       2,
+				After:       &models.SecretCursorDTO{Sort: "title", Value: "A", ID: 1},
+			}).
+			Return(&models.SecretSummaryPageDTO{
+				Items:      []models.SecretSummaryDTO{{ID: 2, Title: "Bank 1"}, {ID: 3, Title: "Bank 2"}},
+				NextCursor: "next-cursor",
+			}, nil)
+
+		var page models.SecretSummaryPageDTO
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			SetQueryParams(map[string]string{
+				"q":            "bank",
+				"type":         "card",
+				"created_from": "2025-01-01T03:00:00+03:00",
+				"sort":         "title",
+				"limit":        "2",
+				"cursor":       cursor,
+			}).
+			SetResult(&page).
+			Get(server.URL + "/v1.0/secrets")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Len(t, page.Items, 2)
+		next, err := url.Parse(page.Next)
+		assert.NoError(t, err)
+		assert.Equal(t, "/v1.0/secrets", next.Path)
+		assert.Equal(t, "next-cursor", next.Query().Get("cursor"))
+		assert.Equal(t, "bank", next.Query().Get("q"))
+	})
+
+	t.Run("Last_page", func(t *testing.T) {
+		secrets.EXPECT().
+			GetSummaryPageByUser(gomock.Any(), uint64(42), models.SecretFilterDTO{}).
+			Return(&models.SecretSummaryPageDTO{Items: []models.SecretSummaryDTO{}}, nil)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Get(server.URL + "/v1.0/secrets/")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.JSONEq(t, `{"items": []}`, string(resp.Body()))
+	})
+
+	t.Run("Invalid_query", func(t *testing.T) {
+		for _, query := range []string{"type=note", "sort=id", "limit=0", "limit=501", "created_to=yesterday", "cursor=***"} {
+			resp, err := resty.New().R().
+				SetHeader("Authorization", "Bearer "+accessToken).
+				SetQueryString(query).
+				Get(server.URL + "/v1.0/secrets")
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode(), query)
+		}
+	})
+
+	t.Run("Cursor_for_other_sort", func(t *testing.T) {
+		secrets.EXPECT().
+			GetSummaryPageByUser(gomock.Any(), uint64(42), gomock.Any()).
+			Return(nil, service.ErrInvalidCursor)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Get(server.URL + "/v1.0/secrets")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		resp, err := resty.New().R().Get(server.URL + "/v1.0/secrets")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+	})
+}
+
+func TestHandler_ExportSecrets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secrets := mocks.NewMockSecretService(ctrl)
+	os.Setenv("ACCESS_TOKEN_SECRET", "test")
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	handler := NewHandler(nil, nil, secrets, nil, nil, nil, nil, nil, nil, &cfg)
+	server := httptest.NewServer(handler.Router)
+	defer server.Close()
+
+	accessToken, _ := utils.CreateToken(cfg.AccessTokenSecret, "42", cfg.AccessTokenExpires)
+
+	t.Run("Success_with_next", func(t *testing.T) {
+		secrets.EXPECT().
+			ExportPageByUser(gomock.Any(), uint64(42), models.SecretFilterDTO{Limit: 1}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint64, _ models.SecretFilterDTO, meta models.RequestMetaDTO) (*models.SecretPageDTO, error) {
+				assert.Equal(t, "test-agent", meta.UserAgent)
+				return &models.SecretPageDTO{
+					Items:      []models.ReadSecretDTO{{ID: 2, Title: "Mail", Data: models.SecretDataDTO{Text: ptr("hello")}}},
+					NextCursor: "next-cursor",
+				}, nil
+			})
+
+		var page models.SecretPageDTO
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			SetHeader("User-Agent", "test-agent").
+			SetQueryParam("limit", "1").
+			SetResult(&page).
+			Get(server.URL + "/v1.0/secrets/export")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Equal(t, "hello", *page.Items[0].Data.Text)
+		next, err := url.Parse(page.Next)
+		assert.NoError(t, err)
+		assert.Equal(t, "/v1.0/secrets/export", next.Path)
+		assert.Equal(t, "next-cursor", next.Query().Get("cursor"))
+	})
+
+	t.Run("Audit_error", func(t *testing.T) {
+		secrets.EXPECT().
+			ExportPageByUser(gomock.Any(), uint64(42), gomock.Any(), gomock.Any()).
+			Return(nil, assert.AnError)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Get(server.URL + "/v1.0/secrets/export")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode())
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		resp, err := resty.New().R().Get(server.URL + "/v1.0/secrets/export")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+	})
+}
+
+func TestHandler_GetSecretSummaries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secrets := mocks.NewMockSecretService(ctrl)
+	os.Setenv("ACCESS_TOKEN_SECRET", "test")
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	handler := NewHandler(nil, nil, secrets, nil, nil, nil, nil, nil, nil, &cfg)
+	server := httptest.NewServer(handler.Router)
+	defer server.Close()
+
+	accessToken, _ := utils.CreateToken(cfg.AccessTokenSecret, "42", cfg.AccessTokenExpires)
+
+	t.Run("Success", func(t *testing.T) {
+		secrets.EXPECT().
+			GetSummaryPageByUser(gomock.Any(), uint64(42), models.SecretFilterDTO{Tag: "work", Limit: 1}).
+			Return(&models.SecretSummaryPageDTO{
+				Items:      []models.SecretSummaryDTO{{ID: 1, Title: "VPN", Type: models.SecretTypeLogin, Tags: []string{"work"}, Version: 2}},
+				NextCursor: "next-cursor",
+			}, nil)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			SetQueryString("tag=work&limit=1").
+			Get(server.URL + "/v1.0/secrets/summaries")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.NotContains(t, string(resp.Body()), `"data"`)
+
+		var page models.SecretSummaryPageDTO
+		assert.NoError(t, json.Unmarshal(resp.Body(), &page))
+		assert.Equal(t, models.SecretTypeLogin, page.Items[0].Type)
+		assert.Equal(t, "/v1.0/secrets/summaries?cursor=next-cursor&limit=1&tag=work", page.Next)
+	})
+
+	t.Run("Invalid_cursor", func(t *testing.T) {
+		secrets.EXPECT().
+			GetSummaryPageByUser(gomock.Any(), uint64(42), gomock.Any()).
+			Return(nil, service.ErrInvalidCursor)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Get(server.URL + "/v1.0/secrets/summaries")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		resp, err := resty.New().R().Get(server.URL + "/v1.0/secrets/summaries")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+	})
+}
+
+func TestHandler_GetSecretAudit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secrets := mocks.NewMockSecretService(ctrl)
+	os.Setenv("ACCESS_TOKEN_SECRET", "test")
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	handler := NewHandler(nil, nil, secrets, nil, nil, nil, nil, nil, nil, &cfg)
+	server := httptest.NewServer(handler.Router)
+	defer server.Close()
+
+	accessToken, _ := utils.CreateToken(cfg.AccessTokenSecret, "42", cfg.AccessTokenExpires)
+
+	t.Run("Success", func(t *testing.T) {
+		secrets.EXPECT().
+			GetAuditBySecret(gomock.Any(), uint64(42), uint64(7)).
+			Return([]models.ReadSecretAuditDTO{{ID: 1, SecretID: 7, Action: models.SecretActionReveal}}, nil)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Get(server.URL + "/v1.0/secrets/7/audit")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Contains(t, string(resp.Body()), `"action":"reveal"`)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		secrets.EXPECT().
+			GetAuditBySecret(gomock.Any(), uint64(42), uint64(8)).
+			Return(nil, nil)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Get(server.URL + "/v1.0/secrets/8/audit")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Equal(t, "[]", string(resp.Body()))
+	})
+
+	t.Run("Service_error", func(t *testing.T) {
+		secrets.EXPECT().
+			GetAuditBySecret(gomock.Any(), uint64(42), uint64(9)).
+			Return(nil, assert.AnError)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Get(server.URL + "/v1.0/secrets/9/audit")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode())
+	})
+}
+
 func TestHandler_DeleteSecretByID(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -210,12 +530,12 @@ func TestHandler_DeleteSecretByID(t *testing.T) {
 
 	accessToken, _ := utils.CreateToken(cfg.AccessTokenSecret, "77", cfg.AccessTokenExpires)
 
-	handler := NewHandler(nil, nil, secrets, &cfg)
+	handler := NewHandler(nil, nil, secrets, nil, nil, nil, nil, nil, nil, &cfg)
 	httpSrv := httptest.NewServer(handler.Router)
 	defer httpSrv.Close()
 
 	secrets.EXPECT().
-		DeleteByID(gomock.Any(), uint64(77)).
+		DeleteByID(gomock.Any(), uint64(77), uint64(77)).
 		Return(nil)
 
 	resp, err := resty.New().R().
@@ -224,6 +544,17 @@ func TestHandler_DeleteSecretByID(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusNoContent, resp.StatusCode())
+
+	secrets.EXPECT().
+		DeleteByID(gomock.Any(), uint64(77), uint64(78)).
+		Return(service.ErrSecretNotFound)
+
+	resp, err = resty.New().R().
+		SetHeader("Authorization", "Bearer "+accessToken).
+		Delete(httpSrv.URL + "/v1.0/secrets/78")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode())
 }
 
 func ptr(s string) *string {
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// GetTrash — обработчик получения списка секретов в корзине (без данных).
+//
+// Возвращает:
+//   - 200 OK — массив секретов, начиная с последних удалённых
+//   - 401 Unauthorized — если токен невалиден
+//   - 500 Internal Server Error — если ошибка на уровне сервиса
+func (h *Handler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.userIDFromRequest(r)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Токен отсутствует или невалиден", zap.Error(err))
+		h.JSONError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	trash, err := h.secrets.GetTrash(r.Context(), userID)
+	if err != nil {
+		h.logger.For(r.Context()).Error("Ошибка при получении корзины", zap.Uint64("user_id", userID), zap.Error(err))
+		h.JSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if trash == nil {
+		trash = []models.TrashedSecretDTO{}
+	}
+
+	h.logger.For(r.Context()).Info("Корзина успешно получена", zap.Uint64("user_id", userID))
+	h.writeJSON(w, http.StatusOK, trash)
+}
+
+// RestoreFromTrash — обработчик восстановления секрета из корзины.
+//
+// Возвращает:
+//   - 204 No Content — если секрет восстановлен
+//   - 401 Unauthorized — если токен невалиден
+//   - 404 Not Found — если ID невалиден или секрета нет в корзине
+//   - 500 Internal Server Error — если ошибка на уровне сервиса
+func (h *Handler) RestoreFromTrash(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.secretRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.secrets.RestoreFromTrash(r.Context(), userID, id); err != nil {
+		h.logger.For(r.Context()).Warn("Ошибка при восстановлении секрета из корзины", zap.Uint64("secret_id", id), zap.Error(err))
+		h.JSONError(w, secretErrorStatus(err), err.Error())
+		return
+	}
+
+	h.logger.For(r.Context()).Info("Секрет восстановлен из корзины", zap.Uint64("secret_id", id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PurgeSecret — обработчик окончательного удаления секрета из корзины.
+// Секрет, не перемещённый предварительно в корзину, таким запросом удалить нельзя.
+//
+// Возвращает:
+//   - 204 No Content — если секрет удалён
+//   - 401 Unauthorized — если токен невалиден
+//   - 404 Not Found — если ID невалиден или секрета нет в корзине
+//   - 500 Internal Server Error — если ошибка на уровне сервиса
+func (h *Handler) PurgeSecret(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.secretRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.secrets.Purge(r.Context(), userID, id); err != nil {
+		h.logger.For(r.Context()).Warn("Ошибка при окончательном удалении секрета", zap.Uint64("secret_id", id), zap.Error(err))
+		h.JSONError(w, secretErrorStatus(err), err.Error())
+		return
+	}
+
+	h.logger.For(r.Context()).Info("Секрет окончательно удалён", zap.Uint64("secret_id", id))
+	w.WriteHeader(http.StatusNoContent)
+}
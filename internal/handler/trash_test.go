@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/service"
+	"github.com/shekshuev/gophkeeper/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Trash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secrets := mocks.NewMockSecretService(ctrl)
+	os.Setenv("ACCESS_TOKEN_SECRET", "test")
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	handler := NewHandler(nil, nil, secrets, nil, nil, nil, nil, nil, nil, &cfg)
+	server := httptest.NewServer(handler.Router)
+	defer server.Close()
+
+	accessToken, _ := utils.CreateToken(cfg.AccessTokenSecret, "42", cfg.AccessTokenExpires)
+
+	t.Run("GetTrash", func(t *testing.T) {
+		purgeAt := time.Date(2025, 8, 15, 0, 0, 0, 0, time.UTC)
+		secrets.EXPECT().
+			GetTrash(gomock.Any(), uint64(42)).
+			Return([]models.TrashedSecretDTO{{ID: 7, Title: "VPN", PurgeAt: &purgeAt}}, nil)
+
+		var trash []models.TrashedSecretDTO
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			SetResult(&trash).
+			Get(server.URL + "/v1.0/trash")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Len(t, trash, 1)
+		assert.Equal(t, purgeAt, *trash[0].PurgeAt)
+	})
+
+	t.Run("GetTrash_empty", func(t *testing.T) {
+		secrets.EXPECT().GetTrash(gomock.Any(), uint64(42)).Return(nil, nil)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Get(server.URL + "/v1.0/trash")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Equal(t, "[]", resp.String())
+	})
+
+	t.Run("GetTrash_error", func(t *testing.T) {
+		secrets.EXPECT().GetTrash(gomock.Any(), uint64(42)).Return(nil, errors.New("db error"))
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Get(server.URL + "/v1.0/trash")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode())
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		resp, err := resty.New().R().Get(server.URL + "/v1.0/trash")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		secrets.EXPECT().RestoreFromTrash(gomock.Any(), uint64(42), uint64(7)).Return(nil)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Post(server.URL + "/v1.0/trash/7/restore")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode())
+	})
+
+	t.Run("Restore_not_found", func(t *testing.T) {
+		secrets.EXPECT().RestoreFromTrash(gomock.Any(), uint64(42), uint64(8)).Return(service.ErrSecretNotFound)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Post(server.URL + "/v1.0/trash/8/restore")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		secrets.EXPECT().Purge(gomock.Any(), uint64(42), uint64(7)).Return(nil)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Delete(server.URL + "/v1.0/trash/7")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode())
+	})
+
+	t.Run("Purge_not_found", func(t *testing.T) {
+		secrets.EXPECT().Purge(gomock.Any(), uint64(42), uint64(8)).Return(service.ErrSecretNotFound)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Delete(server.URL + "/v1.0/trash/8")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+	})
+}
@@ -29,7 +29,7 @@ func TestHandler_GetUserByID(t *testing.T) {
 		cfg.AccessTokenExpires,
 	)
 	assert.NoError(t, err, "error creating token")
-	handler := NewHandler(users, nil, nil, &cfg)
+	handler := NewHandler(users, nil, nil, nil, nil, nil, nil, nil, nil, &cfg)
 	httpSrv := httptest.NewServer(handler.Router)
 	defer httpSrv.Close()
 
@@ -60,11 +60,19 @@ func TestHandler_GetUserByID(t *testing.T) {
 		{
 			name:          "User not found",
 			expectedCode:  http.StatusNotFound,
-			userID:        "2",
+			userID:        "1",
 			responseDTO:   nil,
 			serviceError:  assert.AnError,
 			serviceCalled: true,
 		},
+		{
+			name:          "Other user",
+			expectedCode:  http.StatusUnauthorized,
+			userID:        "2",
+			responseDTO:   nil,
+			serviceError:  nil,
+			serviceCalled: false,
+		},
 	}
 
 	for _, tc := range testCases {
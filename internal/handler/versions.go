@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// GetSecretVersions — обработчик получения списка предыдущих версий секрета (без данных).
+//
+// Возвращает:
+//   - 200 OK — массив версий, начиная с последней
+//   - 401 Unauthorized — если токен невалиден
+//   - 404 Not Found — если ID невалиден
+//   - 500 Internal Server Error — если ошибка на уровне сервиса
+func (h *Handler) GetSecretVersions(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.secretRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	versions, err := h.secrets.GetVersions(r.Context(), userID, id)
+	if err != nil {
+		h.logger.For(r.Context()).Error("Ошибка при получении версий секрета", zap.Uint64("secret_id", id), zap.Error(err))
+		h.JSONError(w, secretErrorStatus(err), err.Error())
+		return
+	}
+	if versions == nil {
+		versions = []models.SecretVersionDTO{}
+	}
+
+	h.logger.For(r.Context()).Info("Версии секрета успешно получены", zap.Uint64("secret_id", id))
+	h.writeJSON(w, http.StatusOK, versions)
+}
+
+// GetSecretVersion — обработчик получения предыдущей версии секрета вместе с данными.
+// Просмотр записывается в журнал аудита.
+//
+// Возвращает:
+//   - 200 OK — версию секрета
+//   - 401 Unauthorized — если токен невалиден
+//   - 404 Not Found — если ID или номер версии невалидны либо версия не найдена
+//   - 500 Internal Server Error — если ошибка на уровне сервиса
+func (h *Handler) GetSecretVersion(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.secretRequestIDs(w, r)
+	if !ok {
+		return
+	}
+	version, ok := h.versionParam(w, r)
+	if !ok {
+		return
+	}
+
+	v, err := h.secrets.GetVersion(r.Context(), userID, id, version, requestMeta(r))
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Ошибка при получении версии секрета", zap.Uint64("secret_id", id), zap.Int("version", version), zap.Error(err))
+		h.JSONError(w, secretErrorStatus(err), err.Error())
+		return
+	}
+
+	h.logger.For(r.Context()).Info("Версия секрета успешно получена", zap.Uint64("secret_id", id), zap.Int("version", version))
+	h.writeJSON(w, http.StatusOK, v)
+}
+
+// RestoreSecretVersion — обработчик восстановления предыдущей версии секрета.
+// Текущее состояние секрета при этом тоже сохраняется в истории.
+//
+// Возвращает:
+//   - 200 OK — секрет после восстановления (с новым номером версии)
+//   - 401 Unauthorized — если токен невалиден
+//   - 404 Not Found — если ID или номер версии невалидны, секрет или версия не найдены
+//   - 500 Internal Server Error — если ошибка на уровне сервиса
+func (h *Handler) RestoreSecretVersion(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.secretRequestIDs(w, r)
+	if !ok {
+		return
+	}
+	version, ok := h.versionParam(w, r)
+	if !ok {
+		return
+	}
+
+	secret, err := h.secrets.Restore(r.Context(), userID, id, version, requestMeta(r).Device)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Ошибка при восстановлении версии секрета", zap.Uint64("secret_id", id), zap.Int("version", version), zap.Error(err))
+		h.JSONError(w, secretErrorStatus(err), err.Error())
+		return
+	}
+
+	h.logger.For(r.Context()).Info("Версия секрета восстановлена", zap.Uint64("secret_id", id), zap.Int("version", version))
+	h.writeJSON(w, http.StatusOK, secret)
+}
+
+// versionParam извлекает номер версии секрета из URL.
+// При ошибке сам отправляет ответ и возвращает ok = false.
+func (h *Handler) versionParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	versionStr := chi.URLParam(r, "version")
+	version, err := strconv.Atoi(versionStr)
+	if err != nil || version < 1 {
+		h.logger.For(r.Context()).Warn("Невалидный номер версии секрета", zap.String("version", versionStr))
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/service"
+	"github.com/shekshuev/gophkeeper/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_UpdateSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secrets := mocks.NewMockSecretService(ctrl)
+	os.Setenv("ACCESS_TOKEN_SECRET", "test")
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	handler := NewHandler(nil, nil, secrets, nil, nil, nil, nil, nil, nil, &cfg)
+	server := httptest.NewServer(handler.Router)
+	defer server.Close()
+
+	accessToken, _ := utils.CreateToken(cfg.AccessTokenSecret, "42", cfg.AccessTokenExpires)
+
+	t.Run("Success", func(t *testing.T) {
+		secrets.EXPECT().
+			Update(gomock.Any(), models.UpdateSecretDTO{
+				ID:     7,
+				UserID: 42,
+				Title:  "VPN",
+				Data:   models.SecretDataDTO{Text: ptr("new")},
+				Device: "laptop",
+			}).
+			Return(&models.ReadSecretDTO{ID: 7, Title: "VPN", Version: 3}, nil)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			SetHeader(DeviceHeader, "laptop").
+			SetBody(`{"title":"VPN","data":{"text":"new"}}`).
+			Put(server.URL + "/v1.0/secrets/7")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Contains(t, string(resp.Body()), `"version":3`)
+	})
+
+	t.Run("Validation_error", func(t *testing.T) {
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			SetBody(`{"title":""}`).
+			Put(server.URL + "/v1.0/secrets/7")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode())
+	})
+
+	t.Run("Not_found", func(t *testing.T) {
+		secrets.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil, service.ErrSecretNotFound)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			SetBody(`{"title":"VPN"}`).
+			Put(server.URL + "/v1.0/secrets/8")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		resp, err := resty.New().R().
+			SetBody(`{"title":"VPN"}`).
+			Put(server.URL + "/v1.0/secrets/7")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+	})
+}
+
+func TestHandler_SecretVersions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secrets := mocks.NewMockSecretService(ctrl)
+	os.Setenv("ACCESS_TOKEN_SECRET", "test")
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	handler := NewHandler(nil, nil, secrets, nil, nil, nil, nil, nil, nil, &cfg)
+	server := httptest.NewServer(handler.Router)
+	defer server.Close()
+
+	accessToken, _ := utils.CreateToken(cfg.AccessTokenSecret, "42", cfg.AccessTokenExpires)
+	request := func() *resty.Request {
+		return resty.New().R().SetHeader("Authorization", "Bearer "+accessToken)
+	}
+
+	t.Run("List", func(t *testing.T) {
+		secrets.EXPECT().
+			GetVersions(gomock.Any(), uint64(42), uint64(7)).
+			Return([]models.SecretVersionDTO{{Version: 2, Title: "VPN", Device: "laptop"}}, nil)
+
+		resp, err := request().Get(server.URL + "/v1.0/secrets/7/versions")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Contains(t, string(resp.Body()), `"device":"laptop"`)
+		assert.NotContains(t, string(resp.Body()), `"data"`)
+	})
+
+	t.Run("List_empty", func(t *testing.T) {
+		secrets.EXPECT().GetVersions(gomock.Any(), uint64(42), uint64(8)).Return(nil, nil)
+
+		resp, err := request().Get(server.URL + "/v1.0/secrets/8/versions")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "[]", string(resp.Body()))
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		secrets.EXPECT().
+			GetVersion(gomock.Any(), uint64(42), uint64(7), 2, gomock.Any()).
+			Return(&models.ReadSecretVersionDTO{SecretID: 7, Version: 2, Data: models.SecretDataDTO{Text: ptr("old")}}, nil)
+
+		resp, err := request().Get(server.URL + "/v1.0/secrets/7/versions/2")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Contains(t, string(resp.Body()), `"text":"old"`)
+	})
+
+	t.Run("Get_not_found", func(t *testing.T) {
+		secrets.EXPECT().
+			GetVersion(gomock.Any(), uint64(42), uint64(7), 9, gomock.Any()).
+			Return(nil, service.ErrSecretVersionNotFound)
+
+		resp, err := request().Get(server.URL + "/v1.0/secrets/7/versions/9")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+	})
+
+	t.Run("Get_zero_version", func(t *testing.T) {
+		resp, err := request().Get(server.URL + "/v1.0/secrets/7/versions/0")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		secrets.EXPECT().
+			Restore(gomock.Any(), uint64(42), uint64(7), 2, "phone").
+			Return(&models.ReadSecretDTO{ID: 7, Version: 6}, nil)
+
+		resp, err := request().
+			SetHeader(DeviceHeader, "phone").
+			Post(server.URL + "/v1.0/secrets/7/versions/2/restore")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Contains(t, string(resp.Body()), `"version":6`)
+	})
+
+	t.Run("Restore_error", func(t *testing.T) {
+		secrets.EXPECT().
+			Restore(gomock.Any(), uint64(42), uint64(7), 2, "").
+			Return(nil, assert.AnError)
+
+		resp, err := request().Post(server.URL + "/v1.0/secrets/7/versions/2/restore")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode())
+	})
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/service"
+)
+
+// CreateWebhook — обработчик регистрации вебхука.
+// Принимает JSON с полями url и events в теле запроса.
+// В ответе возвращается ключ подписи secret: позже его получить нельзя.
+//
+// Возвращает:
+//   - 201 Created — если вебхук зарегистрирован
+//   - 400 Bad Request — если JSON невалиден
+//   - 401 Unauthorized — если токен невалиден или не содержит userID
+//   - 422 Unprocessable Entity — если входные данные не прошли валидацию
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.userIDFromRequest(r)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Токен отсутствует или невалиден при регистрации вебхука", zap.Error(err))
+		h.JSONError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var dto models.CreateWebhookDTO
+	if !h.decodeJSONBody(w, r, &dto) {
+		return
+	}
+	dto.UserID = userID
+
+	webhook, err := h.webhooks.Create(r.Context(), dto)
+	if err != nil {
+		h.logger.For(r.Context()).Error("Ошибка при регистрации вебхука", zap.Uint64("user_id", userID), zap.Error(err))
+		h.JSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.logger.For(r.Context()).Info("Вебхук успешно зарегистрирован", zap.Uint64("webhook_id", webhook.ID), zap.Uint64("user_id", userID))
+	h.writeJSON(w, http.StatusCreated, webhook)
+}
+
+// GetWebhooks — обработчик получения вебхуков текущего пользователя (без ключей подписи).
+//
+// Возвращает:
+//   - 200 OK — если вебхуки получены
+//   - 401 Unauthorized — если токен невалиден или не содержит userID
+//   - 500 Internal Server Error — если ошибка на уровне сервиса
+func (h *Handler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.userIDFromRequest(r)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Токен отсутствует или невалиден при получении вебхуков", zap.Error(err))
+		h.JSONError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	webhooks, err := h.webhooks.GetAllByUser(r.Context(), userID)
+	if err != nil {
+		h.logger.For(r.Context()).Error("Ошибка при получении вебхуков", zap.Uint64("user_id", userID), zap.Error(err))
+		h.JSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if webhooks == nil {
+		webhooks = []models.ReadWebhookDTO{}
+	}
+
+	h.logger.For(r.Context()).Info("Вебхуки успешно получены", zap.Uint64("user_id", userID), zap.Int("count", len(webhooks)))
+	h.writeJSON(w, http.StatusOK, webhooks)
+}
+
+// DeleteWebhook — обработчик удаления вебхука вместе с журналом его доставок.
+//
+// Возвращает:
+//   - 204 No Content — если вебхук удалён
+//   - 401 Unauthorized — если токен невалиден
+//   - 404 Not Found — если ID невалиден или вебхук не найден
+//   - 500 Internal Server Error — если ошибка на уровне сервиса
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.webhookRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.webhooks.DeleteByID(r.Context(), userID, id); err != nil {
+		h.logger.For(r.Context()).Warn("Ошибка при удалении вебхука", zap.Uint64("webhook_id", id), zap.Error(err))
+		h.JSONError(w, webhookErrorStatus(err), err.Error())
+		return
+	}
+
+	h.logger.For(r.Context()).Info("Вебхук удалён", zap.Uint64("webhook_id", id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetWebhookDeliveries — обработчик получения журнала доставок вебхука, начиная с последних.
+//
+// Возвращает:
+//   - 200 OK — если журнал получен
+//   - 401 Unauthorized — если токен невалиден
+//   - 404 Not Found — если ID невалиден или вебхук не найден
+//   - 500 Internal Server Error — если ошибка на уровне сервиса
+func (h *Handler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.webhookRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.webhooks.GetDeliveries(r.Context(), userID, id)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Ошибка при получении журнала доставок вебхука", zap.Uint64("webhook_id", id), zap.Error(err))
+		h.JSONError(w, webhookErrorStatus(err), err.Error())
+		return
+	}
+	if deliveries == nil {
+		deliveries = []models.WebhookDeliveryDTO{}
+	}
+
+	h.logger.For(r.Context()).Info("Журнал доставок вебхука получен", zap.Uint64("webhook_id", id), zap.Int("count", len(deliveries)))
+	h.writeJSON(w, http.StatusOK, deliveries)
+}
+
+// webhookRequestIDs извлекает ID пользователя из токена и ID вебхука из URL.
+// При ошибке сам отправляет ответ и возвращает ok = false.
+func (h *Handler) webhookRequestIDs(w http.ResponseWriter, r *http.Request) (userID, id uint64, ok bool) {
+	userID, err := h.userIDFromRequest(r)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Токен отсутствует или невалиден", zap.Error(err))
+		h.JSONError(w, http.StatusUnauthorized, err.Error())
+		return 0, 0, false
+	}
+	idStr := chi.URLParam(r, "id")
+	id, err = strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		h.logger.For(r.Context()).Warn("Невалидный ID вебхука", zap.String("id", idStr), zap.Error(err))
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return 0, 0, false
+	}
+	return userID, id, true
+}
+
+// webhookErrorStatus сопоставляет ошибку сервиса вебхуков с HTTP-статусом.
+func webhookErrorStatus(err error) int {
+	if errors.Is(err, service.ErrWebhookNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
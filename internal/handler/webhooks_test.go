@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/service"
+	"github.com/shekshuev/gophkeeper/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Webhooks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	webhooks := mocks.NewMockWebhookService(ctrl)
+	os.Setenv("ACCESS_TOKEN_SECRET", "test")
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	handler := NewHandler(nil, nil, nil, nil, nil, webhooks, nil, nil, nil, &cfg)
+	server := httptest.NewServer(handler.Router)
+	defer server.Close()
+
+	accessToken, _ := utils.CreateToken(cfg.AccessTokenSecret, "1", cfg.AccessTokenExpires)
+
+	t.Run("Create_success", func(t *testing.T) {
+		events := []string{models.WebhookEventSecretChanged, models.WebhookEventLoginNewDevice}
+		webhooks.EXPECT().
+			Create(gomock.Any(), models.CreateWebhookDTO{UserID: 1, URL: "https://example.com/hook", Events: events}).
+			Return(&models.ReadWebhookDTO{ID: 3, UserID: 1, URL: "https://example.com/hook", Events: events, Secret: "key"}, nil)
+
+		var result models.ReadWebhookDTO
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			SetBody(`{"url":"https://example.com/hook","events":["secret.changed","login.new_device"]}`).
+			SetResult(&result).
+			Post(server.URL + "/v1.0/webhooks/")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode())
+		assert.Equal(t, uint64(3), result.ID)
+		assert.Equal(t, "key", result.Secret)
+	})
+
+	t.Run("Create_validation_error", func(t *testing.T) {
+		for _, body := range []string{
+			`{"url":"ftp://example.com","events":["secret.changed"]}`,
+			`{"url":"https://example.com/hook","events":[]}`,
+			`{"url":"https://example.com/hook","events":["secret.viewed"]}`,
+		} {
+			resp, err := resty.New().R().
+				SetHeader("Authorization", "Bearer "+accessToken).
+				SetBody(body).
+				Post(server.URL + "/v1.0/webhooks/")
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode(), body)
+		}
+	})
+
+	t.Run("Unauthorized_no_token", func(t *testing.T) {
+		resp, err := resty.New().R().Get(server.URL + "/v1.0/webhooks/")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+	})
+
+	t.Run("List_success", func(t *testing.T) {
+		webhooks.EXPECT().GetAllByUser(gomock.Any(), uint64(1)).Return(nil, nil)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Get(server.URL + "/v1.0/webhooks/")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.JSONEq(t, `[]`, string(resp.Body()))
+	})
+
+	t.Run("Delete_success", func(t *testing.T) {
+		webhooks.EXPECT().DeleteByID(gomock.Any(), uint64(1), uint64(3)).Return(nil)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Delete(server.URL + "/v1.0/webhooks/3")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode())
+	})
+
+	t.Run("Delete_not_found", func(t *testing.T) {
+		webhooks.EXPECT().DeleteByID(gomock.Any(), uint64(1), uint64(4)).Return(service.ErrWebhookNotFound)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Delete(server.URL + "/v1.0/webhooks/4")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+	})
+
+	t.Run("Deliveries_success", func(t *testing.T) {
+		webhooks.EXPECT().GetDeliveries(gomock.Any(), uint64(1), uint64(3)).Return([]models.WebhookDeliveryDTO{
+			{ID: 9, WebhookID: 3, Event: models.WebhookEventSecretChanged, Status: models.WebhookDeliveryFailed, Attempts: 8, Error: "unexpected status 500"},
+		}, nil)
+
+		var result []models.WebhookDeliveryDTO
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			SetResult(&result).
+			Get(server.URL + "/v1.0/webhooks/3/deliveries")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		if assert.Len(t, result, 1) {
+			assert.Equal(t, models.WebhookDeliveryFailed, result[0].Status)
+		}
+	})
+
+	t.Run("Deliveries_not_found", func(t *testing.T) {
+		webhooks.EXPECT().GetDeliveries(gomock.Any(), uint64(1), uint64(4)).Return(nil, service.ErrWebhookNotFound)
+
+		resp, err := resty.New().R().
+			SetHeader("Authorization", "Bearer "+accessToken).
+			Get(server.URL + "/v1.0/webhooks/4/deliveries")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+	})
+}
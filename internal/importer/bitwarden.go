@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Типы записей Bitwarden.
+const (
+	bitwardenLogin    = 1
+	bitwardenNote     = 2
+	bitwardenCard     = 3
+	bitwardenIdentity = 4
+	bitwardenSSHKey   = 5
+)
+
+// bitwardenExport — структура незашифрованного JSON-экспорта Bitwarden.
+type bitwardenExport struct {
+	Encrypted bool `json:"encrypted"`
+	Folders   []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"folders"`
+	Items []struct {
+		Type     int    `json:"type"`
+		Name     string `json:"name"`
+		Notes    string `json:"notes"`
+		FolderID string `json:"folderId"`
+		Login    *struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+			URIs     []struct {
+				URI string `json:"uri"`
+			} `json:"uris"`
+		} `json:"login"`
+		Card *struct {
+			CardholderName string `json:"cardholderName"`
+			Number         string `json:"number"`
+			ExpMonth       string `json:"expMonth"`
+			ExpYear        string `json:"expYear"`
+			Code           string `json:"code"`
+		} `json:"card"`
+		Identity map[string]any `json:"identity"`
+		SSHKey   *struct {
+			PrivateKey  string `json:"privateKey"`
+			PublicKey   string `json:"publicKey"`
+			Fingerprint string `json:"keyFingerprint"`
+		} `json:"sshKey"`
+		Fields []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"fields"`
+	} `json:"items"`
+}
+
+// bitwardenIdentityFields — поля удостоверения Bitwarden в порядке вывода.
+var bitwardenIdentityFields = []string{
+	"title", "firstName", "middleName", "lastName", "company", "email", "phone", "username",
+	"address1", "address2", "address3", "city", "state", "postalCode", "country",
+	"ssn", "passportNumber", "licenseNumber",
+}
+
+// ParseBitwarden разбирает незашифрованный JSON-экспорт Bitwarden.
+// Логины, заметки и карты переносятся в соответствующие типы секретов, удостоверения и SSH-ключи —
+// в текстовые секреты. Папки Bitwarden с «/» в названии считаются вложенными.
+func ParseBitwarden(data []byte) (*Result, error) {
+	var export bitwardenExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("parse bitwarden export: %w", err)
+	}
+	if export.Encrypted {
+		return nil, ErrEncryptedExport
+	}
+
+	folders := make(map[string][]string, len(export.Folders))
+	for _, f := range export.Folders {
+		folders[f.ID] = strings.Split(f.Name, "/")
+	}
+
+	result := &Result{}
+	for _, it := range export.Items {
+		notes := it.Notes
+		if len(it.Fields) > 0 {
+			var pairs []string
+			for _, f := range it.Fields {
+				pairs = append(pairs, f.Name, f.Value)
+			}
+			notes = strings.TrimSpace(notes + "\n" + joinFields(pairs...))
+		}
+
+		var item Item
+		switch {
+		case it.Type == bitwardenLogin && it.Login != nil:
+			var uri string
+			if len(it.Login.URIs) > 0 {
+				uri = it.Login.URIs[0].URI
+			}
+			item = loginItem(it.Name, it.Login.Username, it.Login.Password, uri, notes)
+		case it.Type == bitwardenNote:
+			item = noteItem(it.Name, notes)
+		case it.Type == bitwardenCard && it.Card != nil:
+			item = cardItem(it.Name, it.Card.Number, it.Card.CardholderName, it.Card.ExpMonth, it.Card.ExpYear, it.Card.Code)
+			if notes != "" {
+				result.Warn(it.Name, "заметки к карте не перенесены")
+			}
+		case it.Type == bitwardenIdentity && it.Identity != nil:
+			var pairs []string
+			for _, key := range bitwardenIdentityFields {
+				if v, ok := it.Identity[key].(string); ok {
+					pairs = append(pairs, key, v)
+				}
+			}
+			item = noteItem(it.Name, strings.TrimSpace(joinFields(pairs...)+"\n"+notes))
+		case it.Type == bitwardenSSHKey && it.SSHKey != nil:
+			text := joinFields("fingerprint", it.SSHKey.Fingerprint, "public key", it.SSHKey.PublicKey) + "\n\n" + it.SSHKey.PrivateKey
+			item = noteItem(it.Name, strings.TrimSpace(text+"\n"+notes))
+		default:
+			result.Skip(it.Name, fmt.Sprintf("неподдерживаемый тип записи %d", it.Type))
+			continue
+		}
+
+		item.Folder = folders[it.FolderID]
+		result.Add(item)
+	}
+	return result, nil
+}
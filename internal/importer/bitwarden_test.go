@@ -0,0 +1,59 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bitwardenJSON = `{
+  "encrypted": false,
+  "folders": [{"id": "f1", "name": "Work/Servers"}],
+  "items": [
+    {"type": 1, "name": "VPN", "folderId": "f1", "notes": "office",
+     "login": {"username": "admin", "password": "secret", "uris": [{"uri": "https://vpn.example.com"}]},
+     "fields": [{"name": "pin", "value": "1234"}]},
+    {"type": 2, "name": "Wi-Fi", "folderId": null, "notes": "password: qwerty"},
+    {"type": 3, "name": "Visa", "notes": "main card",
+     "card": {"cardholderName": "JOHN DOE", "number": "4111 1111 1111 1111", "expMonth": "7", "expYear": "2027", "code": "123"}},
+    {"type": 4, "name": "Passport", "identity": {"firstName": "John", "lastName": "Doe", "passportNumber": "AB123"}},
+    {"type": 9, "name": "Unknown"}
+  ]
+}`
+
+func TestParseBitwarden(t *testing.T) {
+	result, err := ParseBitwarden([]byte(bitwardenJSON))
+	require.NoError(t, err)
+	require.Len(t, result.Items, 4)
+
+	login := result.Items[0]
+	assert.Equal(t, "VPN", login.Title)
+	assert.Equal(t, []string{"Work", "Servers"}, login.Folder)
+	assert.Equal(t, "admin", login.Data.LoginPassword.Login)
+	assert.Equal(t, "secret", login.Data.LoginPassword.Password)
+	assert.Equal(t, "https://vpn.example.com", login.Data.LoginPassword.URL)
+	assert.Equal(t, "office\npin: 1234", login.Data.LoginPassword.Notes)
+
+	assert.Equal(t, "password: qwerty", *result.Items[1].Data.Text)
+	assert.Empty(t, result.Items[1].Folder)
+
+	card := result.Items[2].Data.Card
+	assert.Equal(t, "4111111111111111", card.Number)
+	assert.Equal(t, "JOHN DOE", card.Holder)
+	assert.Equal(t, "07/27", card.ExpireDate)
+	assert.Equal(t, "123", card.CVV)
+
+	assert.Equal(t, "firstName: John\nlastName: Doe\npassportNumber: AB123", *result.Items[3].Data.Text)
+
+	assert.Len(t, result.Skipped, 1)
+	assert.Len(t, result.Warnings, 1)
+}
+
+func TestParseBitwarden_Errors(t *testing.T) {
+	_, err := ParseBitwarden([]byte(`{"encrypted": true}`))
+	assert.ErrorIs(t, err, ErrEncryptedExport)
+
+	_, err = ParseBitwarden([]byte(`not json`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,26 @@
+package importer
+
+import "fmt"
+
+// ParseBrowserCSV разбирает CSV-экспорт паролей Chrome (name, url, username, password, note)
+// или Firefox (url, username, password, ...). Если названия нет, используется имя хоста.
+func ParseBrowserCSV(data []byte) (*Result, error) {
+	table, err := readCSV(data)
+	if err != nil {
+		return nil, err
+	}
+	if !table.has("url", "username", "password") {
+		return nil, fmt.Errorf("parse browser export: %w", ErrUnknownFormat)
+	}
+
+	result := &Result{}
+	for _, row := range table.rows {
+		uri := table.get(row, "url")
+		password := table.get(row, "password")
+		if uri == "" && password == "" {
+			continue
+		}
+		result.Add(loginItem(table.get(row, "name"), table.get(row, "username"), password, uri, table.get(row, "note")))
+	}
+	return result, nil
+}
@@ -0,0 +1,39 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBrowserCSV(t *testing.T) {
+	t.Run("Chrome", func(t *testing.T) {
+		data := "name,url,username,password,note\nexample.com,https://example.com/login,bob,pw,shared\n"
+
+		result, err := ParseBrowserCSV([]byte(data))
+		require.NoError(t, err)
+		require.Len(t, result.Items, 1)
+		assert.Equal(t, "example.com", result.Items[0].Title)
+		assert.Equal(t, "bob", result.Items[0].Data.LoginPassword.Login)
+		assert.Equal(t, "shared", result.Items[0].Data.LoginPassword.Notes)
+	})
+
+	t.Run("Firefox", func(t *testing.T) {
+		data := "\ufeff" + `"url","username","password","httpRealm","formActionOrigin","guid","timeCreated","timeLastUsed","timePasswordChanged"
+"https://accounts.example.org","alice","pw2",,"https://accounts.example.org","{1}","1","1","1"
+`
+
+		result, err := ParseBrowserCSV([]byte(data))
+		require.NoError(t, err)
+		require.Len(t, result.Items, 1)
+		assert.Equal(t, "accounts.example.org", result.Items[0].Title)
+		assert.Equal(t, "https://accounts.example.org", result.Items[0].Data.LoginPassword.URL)
+		assert.Equal(t, "pw2", result.Items[0].Data.LoginPassword.Password)
+	})
+
+	t.Run("Wrong_columns", func(t *testing.T) {
+		_, err := ParseBrowserCSV([]byte("a,b\n1,2\n"))
+		assert.ErrorIs(t, err, ErrUnknownFormat)
+	})
+}
@@ -0,0 +1,52 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// csvTable — CSV-файл с заголовком, поля которого доступны по названию колонки.
+type csvTable struct {
+	columns map[string]int
+	rows    [][]string
+}
+
+// readCSV читает CSV-файл с заголовком. Названия колонок приводятся к нижнему регистру.
+func readCSV(data []byte) (*csvTable, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse csv: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("parse csv: %w", ErrUnknownFormat)
+	}
+
+	table := &csvTable{columns: make(map[string]int, len(records[0])), rows: records[1:]}
+	for i, name := range records[0] {
+		table.columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	return table, nil
+}
+
+// has сообщает, есть ли в файле все перечисленные колонки.
+func (t *csvTable) has(names ...string) bool {
+	for _, name := range names {
+		if _, ok := t.columns[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// get возвращает значение колонки в строке или пустую строку, если колонки нет.
+func (t *csvTable) get(row []string, name string) string {
+	if i, ok := t.columns[name]; ok && i < len(row) {
+		return row[i]
+	}
+	return ""
+}
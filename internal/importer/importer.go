@@ -0,0 +1,232 @@
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// Format — формат файла экспорта другого менеджера паролей.
+type Format string
+
+// Поддерживаемые форматы импорта.
+const (
+	FormatBitwarden   Format = "bitwarden"   // JSON-экспорт Bitwarden (незашифрованный)
+	FormatOnePassword Format = "1password"   // Архив 1PUX из 1Password 8
+	FormatLastPass    Format = "lastpass"    // CSV-экспорт LastPass
+	FormatBrowser     Format = "browser-csv" // CSV-экспорт паролей Chrome или Firefox
+	FormatKeePass     Format = "keepass"     // База KeePass KDBX 4 (открывается пакетом keepass)
+)
+
+// Ограничения сервера на поля секрета.
+const (
+	maxTitleLen = 100
+	maxTags     = 20
+	maxTagLen   = 50
+)
+
+// ErrUnknownFormat возвращается, если формат файла не удалось определить.
+var ErrUnknownFormat = errors.New("unknown import format")
+
+// ErrEncryptedExport возвращается для зашифрованных экспортов, которые нельзя прочитать без пароля менеджера.
+var ErrEncryptedExport = errors.New("encrypted exports are not supported, export unencrypted data")
+
+// ErrCredentialsRequired возвращается Parse для форматов, которые открываются только с мастер-паролем.
+var ErrCredentialsRequired = errors.New("format requires a master password")
+
+// Item — запись, подготовленная к импорту в виде секрета.
+type Item struct {
+	Title  string               // Название секрета
+	Folder []string             // Путь папки от корня (пустой — вне папок)
+	Tags   []string             // Теги секрета
+	Data   models.SecretDataDTO // Данные секрета
+}
+
+// Type возвращает тип секрета записи (см. models.SecretType*).
+func (i Item) Type() string {
+	switch {
+	case i.Data.LoginPassword != nil:
+		return models.SecretTypeLogin
+	case i.Data.Card != nil:
+		return models.SecretTypeCard
+	case i.Data.Binary != nil:
+		return models.SecretTypeBinary
+	default:
+		return models.SecretTypeText
+	}
+}
+
+// Result — результат разбора файла экспорта.
+type Result struct {
+	Items    []Item   // Записи, которые можно импортировать
+	Skipped  []string // Записи, пропущенные при разборе, с причиной
+	Warnings []string // Записи, часть данных которых не удалось перенести
+}
+
+// Formats возвращает список поддерживаемых форматов.
+func Formats() []Format {
+	return []Format{FormatBitwarden, FormatOnePassword, FormatLastPass, FormatBrowser, FormatKeePass}
+}
+
+// Detect определяет формат экспорта по расширению и содержимому файла.
+func Detect(name string, data []byte) (Format, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".1pux":
+		return FormatOnePassword, nil
+	case ".json":
+		return FormatBitwarden, nil
+	case ".kdbx":
+		return FormatKeePass, nil
+	}
+
+	header, _, _ := bytes.Cut(bytes.TrimPrefix(data, []byte("\ufeff")), []byte("\n"))
+	columns := make(map[string]bool)
+	for _, c := range strings.Split(strings.ToLower(strings.TrimSpace(string(header))), ",") {
+		columns[strings.Trim(c, `" `)] = true
+	}
+	switch {
+	case columns["grouping"] && columns["extra"]:
+		return FormatLastPass, nil
+	case columns["url"] && columns["username"] && columns["password"]:
+		return FormatBrowser, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// Parse разбирает файл экспорта в указанном формате.
+// Для баз KeePass возвращает ErrCredentialsRequired: они открываются через keepass.Import.
+func Parse(format Format, data []byte) (*Result, error) {
+	switch format {
+	case FormatBitwarden:
+		return ParseBitwarden(data)
+	case FormatOnePassword:
+		return ParseOnePassword(data)
+	case FormatLastPass:
+		return ParseLastPass(data)
+	case FormatBrowser:
+		return ParseBrowserCSV(data)
+	case FormatKeePass:
+		return nil, ErrCredentialsRequired
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
+
+// Add добавляет запись в результат, приводя название и теги к ограничениям сервера.
+func (r *Result) Add(item Item) {
+	item.Title = strings.TrimSpace(item.Title)
+	if item.Title == "" {
+		item.Title = "Без названия"
+	}
+	item.Title = truncate(item.Title, maxTitleLen)
+
+	var tags []string
+	for _, tag := range item.Tags {
+		if tag = truncate(strings.TrimSpace(tag), maxTagLen); tag != "" && len(tags) < maxTags {
+			tags = append(tags, tag)
+		}
+	}
+	item.Tags = tags
+
+	var folder []string
+	for _, name := range item.Folder {
+		if name = truncate(strings.TrimSpace(name), maxTitleLen); name != "" {
+			folder = append(folder, name)
+		}
+	}
+	item.Folder = folder
+
+	r.Items = append(r.Items, item)
+}
+
+// Skip отмечает запись как пропущенную.
+func (r *Result) Skip(title, reason string) {
+	r.Skipped = append(r.Skipped, fmt.Sprintf("%s: %s", title, reason))
+}
+
+// Warn добавляет предупреждение о частично перенесённой записи.
+func (r *Result) Warn(title, reason string) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf("%s: %s", title, reason))
+}
+
+// loginItem создаёт запись с логином и паролем.
+func loginItem(title, login, password, uri, notes string) Item {
+	if title == "" {
+		title = hostOf(uri)
+	}
+	return Item{
+		Title: title,
+		Data: models.SecretDataDTO{LoginPassword: &models.LoginPasswordData{
+			Login:    login,
+			Password: password,
+			URL:      uri,
+			Notes:    notes,
+		}},
+	}
+}
+
+// noteItem создаёт текстовую запись.
+func noteItem(title, text string) Item {
+	return Item{Title: title, Data: models.SecretDataDTO{Text: &text}}
+}
+
+// cardItem создаёт запись с данными банковской карты.
+// Срок действия приводится к виду ММ/ГГ.
+func cardItem(title, number, holder, month, year, cvv string) Item {
+	return Item{
+		Title: title,
+		Data: models.SecretDataDTO{Card: &models.CardData{
+			Number:     strings.ReplaceAll(number, " ", ""),
+			Holder:     holder,
+			ExpireDate: expireDate(month, year),
+			CVV:        cvv,
+		}},
+	}
+}
+
+// expireDate собирает срок действия карты в виде ММ/ГГ из месяца и года в произвольной записи.
+func expireDate(month, year string) string {
+	month, year = strings.TrimSpace(month), strings.TrimSpace(year)
+	if month == "" && year == "" {
+		return ""
+	}
+	if len(month) == 1 {
+		month = "0" + month
+	}
+	if len(year) > 2 {
+		year = year[len(year)-2:]
+	}
+	return month + "/" + year
+}
+
+// hostOf возвращает имя хоста из адреса или сам адрес, если разобрать его не удалось.
+func hostOf(uri string) string {
+	if u, err := url.Parse(uri); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return uri
+}
+
+// joinFields собирает непустые пары «название: значение» в многострочный текст.
+func joinFields(pairs ...string) string {
+	var lines []string
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if v := strings.TrimSpace(pairs[i+1]); v != "" {
+			lines = append(lines, pairs[i]+": "+v)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncate обрезает строку до n символов.
+func truncate(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
@@ -0,0 +1,86 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+func TestDetect(t *testing.T) {
+	tcs := []struct {
+		name   string
+		file   string
+		data   string
+		format Format
+	}{
+		{name: "1PUX", file: "export.1pux", format: FormatOnePassword},
+		{name: "KeePass", file: "Passwords.kdbx", format: FormatKeePass},
+		{name: "Bitwarden", file: "bitwarden_export.JSON", format: FormatBitwarden},
+		{name: "LastPass", file: "lastpass.csv", data: lastPassCSV, format: FormatLastPass},
+		{name: "Chrome", file: "Chrome Passwords.csv", data: "name,url,username,password\n", format: FormatBrowser},
+		{name: "Firefox", file: "logins.csv", data: "\ufeff\"url\",\"username\",\"password\",\"httpRealm\"\n", format: FormatBrowser},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			format, err := Detect(tc.file, []byte(tc.data))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.format, format)
+		})
+	}
+
+	_, err := Detect("notes.txt", []byte("hello"))
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestParse_UnknownFormat(t *testing.T) {
+	_, err := Parse("dashlane", nil)
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+
+	_, err = Parse(FormatKeePass, nil)
+	assert.ErrorIs(t, err, ErrCredentialsRequired)
+}
+
+func TestResult_Add(t *testing.T) {
+	var result Result
+	tags := make([]string, 25)
+	for i := range tags {
+		tags[i] = "t"
+	}
+	tags[0] = strings.Repeat("x", 60)
+
+	result.Add(Item{Title: strings.Repeat("я", 120), Tags: tags, Folder: []string{" Work ", ""}})
+	result.Add(loginItem("", "u", "p", "https://site.example/login", ""))
+
+	assert.Len(t, []rune(result.Items[0].Title), 100)
+	assert.Len(t, result.Items[0].Tags, 20)
+	assert.Len(t, result.Items[0].Tags[0], 50)
+	assert.Equal(t, []string{"Work"}, result.Items[0].Folder)
+	assert.Equal(t, "site.example", result.Items[1].Title)
+}
+
+func TestBuildPlan(t *testing.T) {
+	items := []Item{
+		loginItem("GitHub", "octocat", "1", "https://github.com", ""),
+		loginItem("GitHub", "octocat", "2", "https://github.com", ""),
+		loginItem("GitHub", "work", "3", "https://github.com", ""),
+		noteItem("Wi-Fi", "qwerty"),
+		cardItem("Visa", "4111", "", "", "", ""),
+	}
+	existing := []models.SecretSummaryDTO{
+		{ID: 5, Title: "wi-fi", Type: models.SecretTypeText},
+		{ID: 6, Title: "Visa", Type: models.SecretTypeLogin},
+	}
+
+	plan := BuildPlan(items, existing)
+
+	assert.Len(t, plan.New, 3)
+	assert.Equal(t, "work", plan.New[1].Data.LoginPassword.Login)
+	assert.Equal(t, "Visa", plan.New[2].Title)
+	if assert.Len(t, plan.Duplicates, 2) {
+		assert.Contains(t, plan.Duplicates[0].Reason, "«GitHub»")
+		assert.Contains(t, plan.Duplicates[1].Reason, "ID 5")
+	}
+}
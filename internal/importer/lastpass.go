@@ -0,0 +1,84 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// lastPassNoteURL — адрес, которым LastPass помечает защищённые заметки.
+const lastPassNoteURL = "http://sn"
+
+// ParseLastPass разбирает CSV-экспорт LastPass (колонки url, username, password, extra, name, grouping).
+// Защищённые заметки переносятся как текст, заметки типа «Credit Card» — как карты.
+// Группы LastPass становятся папками, вложенность задаётся символом «\».
+func ParseLastPass(data []byte) (*Result, error) {
+	table, err := readCSV(data)
+	if err != nil {
+		return nil, err
+	}
+	if !table.has("url", "username", "password", "extra", "name", "grouping") {
+		return nil, fmt.Errorf("parse lastpass export: %w", ErrUnknownFormat)
+	}
+
+	result := &Result{}
+	for _, row := range table.rows {
+		title := table.get(row, "name")
+		extra := table.get(row, "extra")
+
+		var item Item
+		if table.get(row, "url") == lastPassNoteURL {
+			fields, noteType := lastPassNoteFields(extra)
+			if noteType == "Credit Card" {
+				month, year := lastPassExpiration(fields["Expiration Date"])
+				item = cardItem(title, fields["Number"], fields["Name on Card"], month, year, fields["Security Code"])
+				if fields["Notes"] != "" {
+					result.Warn(title, "заметки к карте не перенесены")
+				}
+			} else {
+				item = noteItem(title, extra)
+			}
+		} else {
+			item = loginItem(title, table.get(row, "username"), table.get(row, "password"), table.get(row, "url"), extra)
+		}
+
+		if group := table.get(row, "grouping"); group != "" {
+			item.Folder = strings.Split(group, `\`)
+		}
+		result.Add(item)
+	}
+	return result, nil
+}
+
+// lastPassNoteFields разбирает структурированную заметку LastPass вида
+// «NoteType:Credit Card\nName on Card:...\nNumber:...». Возвращает поля и тип заметки.
+func lastPassNoteFields(extra string) (map[string]string, string) {
+	fields := make(map[string]string)
+	lines := strings.Split(extra, "\n")
+	for i, line := range lines {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		if key == "Notes" {
+			// Поле заметок последнее и может быть многострочным
+			fields[key] = strings.TrimSpace(strings.Join(append([]string{value}, lines[i+1:]...), "\n"))
+			break
+		}
+		fields[key] = strings.TrimSpace(value)
+	}
+	return fields, fields["NoteType"]
+}
+
+// lastPassExpiration разбирает срок действия карты LastPass вида «January,2027».
+func lastPassExpiration(value string) (month, year string) {
+	name, year, ok := strings.Cut(value, ",")
+	if !ok {
+		return "", ""
+	}
+	t, err := time.Parse("January", strings.TrimSpace(name))
+	if err != nil {
+		return "", year
+	}
+	return fmt.Sprintf("%02d", int(t.Month())), year
+}
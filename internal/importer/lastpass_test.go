@@ -0,0 +1,50 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lastPassCSV = `url,username,password,totp,extra,name,grouping,fav
+https://mail.example.com,john,pa55,,work mail,Mail,Work\Accounts,0
+http://sn,,,,"NoteType:Credit Card
+Language:en-US
+Name on Card:John Doe
+Type:Visa
+Number:4111111111111111
+Security Code:999
+Start Date:,
+Expiration Date:March,2028
+Notes:",Visa,,0
+http://sn,,,,door code 4242,Office,Work,0
+`
+
+func TestParseLastPass(t *testing.T) {
+	result, err := ParseLastPass([]byte(lastPassCSV))
+	require.NoError(t, err)
+	require.Len(t, result.Items, 3)
+
+	login := result.Items[0]
+	assert.Equal(t, "Mail", login.Title)
+	assert.Equal(t, []string{"Work", "Accounts"}, login.Folder)
+	assert.Equal(t, "john", login.Data.LoginPassword.Login)
+	assert.Equal(t, "work mail", login.Data.LoginPassword.Notes)
+
+	card := result.Items[1].Data.Card
+	require.NotNil(t, card)
+	assert.Equal(t, "4111111111111111", card.Number)
+	assert.Equal(t, "John Doe", card.Holder)
+	assert.Equal(t, "03/28", card.ExpireDate)
+	assert.Equal(t, "999", card.CVV)
+	assert.Empty(t, result.Warnings)
+
+	assert.Equal(t, "door code 4242", *result.Items[2].Data.Text)
+	assert.Equal(t, []string{"Work"}, result.Items[2].Folder)
+}
+
+func TestParseLastPass_WrongColumns(t *testing.T) {
+	_, err := ParseLastPass([]byte("name,url,username,password\n"))
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
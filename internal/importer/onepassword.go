@@ -0,0 +1,171 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Категории записей 1Password.
+const (
+	onePasswordLogin    = "001"
+	onePasswordCard     = "002"
+	onePasswordNote     = "003"
+	onePasswordPassword = "005"
+)
+
+// onePasswordDataFile — имя файла с данными внутри архива 1PUX.
+const onePasswordDataFile = "export.data"
+
+// onePasswordExport — структура файла export.data из архива 1PUX.
+type onePasswordExport struct {
+	Accounts []struct {
+		Vaults []struct {
+			Attrs struct {
+				Name string `json:"name"`
+			} `json:"attrs"`
+			Items []onePasswordItem `json:"items"`
+		} `json:"vaults"`
+	} `json:"accounts"`
+}
+
+// onePasswordItem — запись 1Password.
+type onePasswordItem struct {
+	State        string `json:"state"`
+	CategoryUUID string `json:"categoryUuid"`
+	Overview     struct {
+		Title string   `json:"title"`
+		URL   string   `json:"url"`
+		Tags  []string `json:"tags"`
+	} `json:"overview"`
+	Details struct {
+		LoginFields []struct {
+			Value       string `json:"value"`
+			Designation string `json:"designation"`
+		} `json:"loginFields"`
+		NotesPlain string `json:"notesPlain"`
+		Password   string `json:"password"`
+		Sections   []struct {
+			Fields []struct {
+				Title string                     `json:"title"`
+				ID    string                     `json:"id"`
+				Value map[string]json.RawMessage `json:"value"`
+			} `json:"fields"`
+		} `json:"sections"`
+	} `json:"details"`
+}
+
+// ParseOnePassword разбирает архив 1PUX, экспортированный из 1Password 8.
+// Хранилища 1Password переносятся как папки, теги записей сохраняются. Архивные записи пропускаются.
+func ParseOnePassword(data []byte) (*Result, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("open 1pux archive: %w", err)
+	}
+	file, err := archive.Open(onePasswordDataFile)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", onePasswordDataFile, err)
+	}
+	defer file.Close()
+	raw, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", onePasswordDataFile, err)
+	}
+
+	var export onePasswordExport
+	if err := json.Unmarshal(raw, &export); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", onePasswordDataFile, err)
+	}
+
+	result := &Result{}
+	for _, account := range export.Accounts {
+		for _, vault := range account.Vaults {
+			for _, it := range vault.Items {
+				title := it.Overview.Title
+				if it.State == "archived" {
+					result.Skip(title, "запись в архиве")
+					continue
+				}
+				item := onePasswordToItem(it)
+				if item.Data.Card != nil && it.Details.NotesPlain != "" {
+					result.Warn(title, "заметки к карте не перенесены")
+				}
+				item.Folder = []string{vault.Attrs.Name}
+				item.Tags = it.Overview.Tags
+				result.Add(item)
+			}
+		}
+	}
+	return result, nil
+}
+
+// onePasswordToItem преобразует запись 1Password в запись для импорта.
+// Записи категорий, для которых нет отдельного типа секрета, переносятся как текст из всех полей.
+func onePasswordToItem(it onePasswordItem) Item {
+	fields := make(map[string]string)
+	var pairs []string
+	for _, section := range it.Details.Sections {
+		for _, f := range section.Fields {
+			v := onePasswordValue(f.Value)
+			fields[f.ID] = v
+			name := f.Title
+			if name == "" {
+				name = f.ID
+			}
+			pairs = append(pairs, name, v)
+		}
+	}
+
+	title := it.Overview.Title
+	switch it.CategoryUUID {
+	case onePasswordLogin, onePasswordPassword:
+		var login, password string
+		for _, f := range it.Details.LoginFields {
+			switch f.Designation {
+			case "username":
+				login = f.Value
+			case "password":
+				password = f.Value
+			}
+		}
+		if password == "" {
+			password = it.Details.Password
+		}
+		notes := strings.TrimSpace(it.Details.NotesPlain + "\n" + joinFields(pairs...))
+		return loginItem(title, login, password, it.Overview.URL, notes)
+	case onePasswordCard:
+		month, year := "", ""
+		if expiry := fields["expiry"]; len(expiry) == 6 {
+			year, month = expiry[:4], expiry[4:]
+		}
+		return cardItem(title, fields["ccnum"], fields["cardholder"], month, year, fields["cvv"])
+	case onePasswordNote:
+		return noteItem(title, it.Details.NotesPlain)
+	default:
+		return noteItem(title, strings.TrimSpace(joinFields(pairs...)+"\n"+it.Details.NotesPlain))
+	}
+}
+
+// onePasswordValue возвращает значение поля 1Password в виде строки.
+// Значение хранится как объект с одним ключом, зависящим от типа поля: {"string": "..."},
+// {"concealed": "..."}, {"creditCardNumber": "..."}, {"monthYear": 202712} и т.д.
+func onePasswordValue(value map[string]json.RawMessage) string {
+	for _, raw := range value {
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			return s
+		}
+		var n json.Number
+		if err := json.Unmarshal(raw, &n); err == nil {
+			if i, err := strconv.ParseInt(n.String(), 10, 64); err == nil {
+				return strconv.FormatInt(i, 10)
+			}
+			return n.String()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const onePasswordData = `{
+  "accounts": [{
+    "vaults": [{
+      "attrs": {"name": "Personal"},
+      "items": [
+        {"state": "active", "categoryUuid": "001",
+         "overview": {"title": "GitHub", "url": "https://github.com", "tags": ["dev"]},
+         "details": {"loginFields": [
+           {"value": "octocat", "designation": "username"},
+           {"value": "hunter2", "designation": "password"}
+         ], "notesPlain": "2fa on"}},
+        {"state": "active", "categoryUuid": "002",
+         "overview": {"title": "Mastercard"},
+         "details": {"sections": [{"fields": [
+           {"title": "cardholder name", "id": "cardholder", "value": {"string": "Jane Doe"}},
+           {"title": "number", "id": "ccnum", "value": {"creditCardNumber": "5500000000000004"}},
+           {"title": "verification number", "id": "cvv", "value": {"concealed": "321"}},
+           {"title": "expiry date", "id": "expiry", "value": {"monthYear": 202612}}
+         ]}]}},
+        {"state": "active", "categoryUuid": "003",
+         "overview": {"title": "Recovery codes"},
+         "details": {"notesPlain": "aaaa-bbbb"}},
+        {"state": "archived", "categoryUuid": "001", "overview": {"title": "Old"}}
+      ]
+    }]
+  }]
+}`
+
+func onePasswordArchive(t *testing.T, data string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create("export.data")
+	require.NoError(t, err)
+	_, err = f.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestParseOnePassword(t *testing.T) {
+	result, err := ParseOnePassword(onePasswordArchive(t, onePasswordData))
+	require.NoError(t, err)
+	require.Len(t, result.Items, 3)
+
+	login := result.Items[0]
+	assert.Equal(t, []string{"Personal"}, login.Folder)
+	assert.Equal(t, []string{"dev"}, login.Tags)
+	assert.Equal(t, "octocat", login.Data.LoginPassword.Login)
+	assert.Equal(t, "hunter2", login.Data.LoginPassword.Password)
+	assert.Equal(t, "https://github.com", login.Data.LoginPassword.URL)
+	assert.Equal(t, "2fa on", login.Data.LoginPassword.Notes)
+
+	card := result.Items[1].Data.Card
+	assert.Equal(t, "5500000000000004", card.Number)
+	assert.Equal(t, "Jane Doe", card.Holder)
+	assert.Equal(t, "12/26", card.ExpireDate)
+	assert.Equal(t, "321", card.CVV)
+
+	assert.Equal(t, "aaaa-bbbb", *result.Items[2].Data.Text)
+	assert.Len(t, result.Skipped, 1)
+}
+
+func TestParseOnePassword_Errors(t *testing.T) {
+	_, err := ParseOnePassword([]byte("not a zip"))
+	assert.Error(t, err)
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	require.NoError(t, w.Close())
+	_, err = ParseOnePassword(buf.Bytes())
+	assert.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// Duplicate — запись, которая не будет импортирована, потому что повторяет уже существующую.
+type Duplicate struct {
+	Item   Item   // Повторяющаяся запись
+	Reason string // Описание, с чем совпала запись
+}
+
+// Plan — план импорта: какие записи будут загружены, а какие пропущены как дубли.
+type Plan struct {
+	New        []Item      // Записи для загрузки
+	Duplicates []Duplicate // Дубли внутри файла и уже сохранённых секретов
+}
+
+// BuildPlan отбирает записи для загрузки.
+// Дублем внутри файла считается запись с тем же типом, названием и содержимым (логин и адрес,
+// номер карты или текст), что и одна из предыдущих. Дублем хранилища — запись, для которой
+// у пользователя уже есть секрет того же типа с тем же названием без учёта регистра.
+func BuildPlan(items []Item, existing []models.SecretSummaryDTO) Plan {
+	stored := make(map[string]uint64, len(existing))
+	for _, s := range existing {
+		stored[titleKey(s.Type, s.Title)] = s.ID
+	}
+
+	var plan Plan
+	seen := make(map[string]string, len(items))
+	for _, item := range items {
+		if id, ok := stored[titleKey(item.Type(), item.Title)]; ok {
+			plan.Duplicates = append(plan.Duplicates, Duplicate{
+				Item:   item,
+				Reason: fmt.Sprintf("в хранилище уже есть секрет с таким названием (ID %d)", id),
+			})
+			continue
+		}
+		key := fingerprint(item)
+		if first, ok := seen[key]; ok {
+			plan.Duplicates = append(plan.Duplicates, Duplicate{
+				Item:   item,
+				Reason: fmt.Sprintf("повторяет запись «%s» из того же файла", first),
+			})
+			continue
+		}
+		seen[key] = item.Title
+		plan.New = append(plan.New, item)
+	}
+	return plan
+}
+
+// titleKey — ключ сравнения записи с сохранёнными секретами.
+func titleKey(typ, title string) string {
+	return typ + "\x00" + strings.ToLower(strings.TrimSpace(title))
+}
+
+// fingerprint — ключ сравнения записей внутри одного файла.
+func fingerprint(item Item) string {
+	parts := []string{item.Type(), strings.ToLower(item.Title)}
+	switch d := item.Data; {
+	case d.LoginPassword != nil:
+		parts = append(parts, d.LoginPassword.Login, strings.ToLower(d.LoginPassword.URL))
+	case d.Card != nil:
+		parts = append(parts, d.Card.Number)
+	case d.Text != nil:
+		parts = append(parts, *d.Text)
+	}
+	return strings.Join(parts, "\x00")
+}
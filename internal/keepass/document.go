@@ -0,0 +1,217 @@
+package keepass
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Стандартные поля записи KeePass.
+const (
+	fieldTitle    = "Title"
+	fieldUserName = "UserName"
+	fieldPassword = "Password"
+	fieldURL      = "URL"
+	fieldNotes    = "Notes"
+)
+
+// xmlDocument — XML-документ базы KeePass. Описаны только элементы, нужные для импорта и экспорта.
+type xmlDocument struct {
+	XMLName xml.Name `xml:"KeePassFile"`
+	Meta    xmlMeta  `xml:"Meta"`
+	Root    xmlRoot  `xml:"Root"`
+}
+
+// xmlMeta — метаданные базы.
+type xmlMeta struct {
+	Generator         string `xml:"Generator"`
+	DatabaseName      string `xml:"DatabaseName"`
+	RecycleBinEnabled string `xml:"RecycleBinEnabled,omitempty"`
+	RecycleBinUUID    string `xml:"RecycleBinUUID,omitempty"`
+}
+
+// xmlRoot — корень дерева групп.
+type xmlRoot struct {
+	Group xmlGroup `xml:"Group"`
+}
+
+// xmlGroup — группа записей.
+type xmlGroup struct {
+	UUID    string     `xml:"UUID"`
+	Name    string     `xml:"Name"`
+	Times   xmlTimes   `xml:"Times"`
+	Entries []xmlEntry `xml:"Entry"`
+	Groups  []xmlGroup `xml:"Group"`
+}
+
+// xmlEntry — запись базы. История изменений записи не переносится.
+type xmlEntry struct {
+	UUID     string      `xml:"UUID"`
+	Tags     string      `xml:"Tags,omitempty"`
+	Times    xmlTimes    `xml:"Times"`
+	Strings  []xmlString `xml:"String"`
+	Binaries []xmlBinary `xml:"Binary"`
+}
+
+// xmlTimes — даты создания и изменения элемента.
+type xmlTimes struct {
+	CreationTime         string `xml:"CreationTime,omitempty"`
+	LastModificationTime string `xml:"LastModificationTime,omitempty"`
+	LastAccessTime       string `xml:"LastAccessTime,omitempty"`
+	ExpiryTime           string `xml:"ExpiryTime,omitempty"`
+	Expires              string `xml:"Expires,omitempty"`
+	UsageCount           int    `xml:"UsageCount"`
+	LocationChanged      string `xml:"LocationChanged,omitempty"`
+}
+
+// xmlString — строковое поле записи.
+type xmlString struct {
+	Key   string   `xml:"Key"`
+	Value xmlValue `xml:"Value"`
+}
+
+// xmlValue — значение поля; защищённые значения хранятся зашифрованными потоком из внутреннего заголовка.
+type xmlValue struct {
+	Protected string `xml:"Protected,attr,omitempty"`
+	Content   string `xml:",chardata"`
+}
+
+// xmlBinary — ссылка записи на вложение из внутреннего заголовка.
+type xmlBinary struct {
+	Key   string `xml:"Key"`
+	Value struct {
+		Ref int `xml:"Ref,attr"`
+	} `xml:"Value"`
+}
+
+// field возвращает значение поля записи по названию.
+func (e xmlEntry) field(key string) string {
+	for _, s := range e.Strings {
+		if s.Key == key {
+			return s.Value.Content
+		}
+	}
+	return ""
+}
+
+// parseDocument расшифровывает защищённые значения и разбирает XML-документ.
+//
+// Защищённые значения шифруются одним потоком в порядке следования в документе (включая историю записей),
+// поэтому они расшифровываются последовательным проходом по токенам до разбора в структуры.
+func parseDocument(data []byte, stream cipher.Stream) (*xmlDocument, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var out bytes.Buffer
+	enc := xml.NewEncoder(&out)
+	protected := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+		}
+
+		switch t := tok.(type) {
+		case xml.ProcInst:
+			continue
+		case xml.StartElement:
+			protected = false
+			if t.Name.Local == "Value" {
+				attrs := t.Attr[:0:0]
+				for _, a := range t.Attr {
+					if a.Name.Local == "Protected" {
+						protected = strings.EqualFold(a.Value, "true")
+						continue
+					}
+					attrs = append(attrs, a)
+				}
+				t.Attr = attrs
+			}
+			tok = t
+		case xml.CharData:
+			if protected {
+				raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(t)))
+				if err != nil {
+					return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+				}
+				stream.XORKeyStream(raw, raw)
+				tok = xml.CharData(raw)
+				protected = false
+			}
+		case xml.EndElement:
+			protected = false
+		}
+		if err := enc.EncodeToken(xml.CopyToken(tok)); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+
+	var doc xmlDocument
+	if err := xml.Unmarshal(out.Bytes(), &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	return &doc, nil
+}
+
+// marshalDocument шифрует защищённые значения и кодирует XML-документ.
+// Значения шифруются в том же порядке, в котором их записывает xml.Marshal.
+func marshalDocument(doc *xmlDocument, stream cipher.Stream) ([]byte, error) {
+	protectGroup(&doc.Root.Group, stream)
+	data, err := xml.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(`<?xml version="1.0" encoding="utf-8" standalone="yes"?>`+"\n"), data...), nil
+}
+
+// protectGroup шифрует защищённые значения записей группы и вложенных групп.
+func protectGroup(g *xmlGroup, stream cipher.Stream) {
+	for i := range g.Entries {
+		for j := range g.Entries[i].Strings {
+			v := &g.Entries[i].Strings[j].Value
+			if v.Protected == "True" {
+				raw := []byte(v.Content)
+				stream.XORKeyStream(raw, raw)
+				v.Content = base64.StdEncoding.EncodeToString(raw)
+			}
+		}
+	}
+	for i := range g.Groups {
+		protectGroup(&g.Groups[i], stream)
+	}
+}
+
+// epochOffset — число секунд от 0001-01-01 до начала эпохи Unix.
+const epochOffset = 62135596800
+
+// formatTime кодирует дату так, как её хранит KDBX 4: секунды от 0001-01-01 в base64.
+func formatTime(t time.Time) string {
+	return base64.StdEncoding.EncodeToString(binary.LittleEndian.AppendUint64(nil, uint64(t.Unix()+epochOffset)))
+}
+
+// newTimes возвращает даты элемента с указанными временем создания и изменения.
+func newTimes(created, updated time.Time) xmlTimes {
+	return xmlTimes{
+		CreationTime:         formatTime(created),
+		LastModificationTime: formatTime(updated),
+		LastAccessTime:       formatTime(updated),
+		ExpiryTime:           formatTime(updated),
+		Expires:              "False",
+		LocationChanged:      formatTime(updated),
+	}
+}
+
+// newUUID возвращает случайный идентификатор элемента в base64.
+func newUUID() string {
+	return base64.StdEncoding.EncodeToString(randomBytes(16))
+}
@@ -0,0 +1,119 @@
+package keepass
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// rootGroupName — название корневой группы экспортируемой базы.
+const rootGroupName = "GophKeeper"
+
+// Vault — содержимое хранилища для экспорта.
+type Vault struct {
+	Folders []models.ReadFolderDTO // Все папки пользователя
+	Secrets []models.ReadSecretDTO // Все секреты пользователя с данными
+}
+
+// Export записывает хранилище в базу KeePass KDBX 4, зашифрованную AES-256 с ключом из Argon2d.
+//
+// Папки становятся группами, секреты — записями: логин и пароль записываются в стандартные поля,
+// карта — в поля «Card Number», «Card Holder», «Expire Date» и «CVV», текст — в заметки,
+// бинарные данные — во вложение записи. Пароль и CVV хранятся как защищённые поля.
+// Секреты из папок, которых нет в списке, записываются в корневую группу.
+func Export(w io.Writer, vault Vault, creds Credentials) error {
+	return export(w, vault, creds, defaultSettings)
+}
+
+// export записывает хранилище с указанными параметрами шифрования.
+func export(w io.Writer, vault Vault, creds Credentials, s settings) error {
+	folders := make(map[uint64]bool, len(vault.Folders))
+	children := make(map[uint64][]models.ReadFolderDTO)
+	for _, f := range vault.Folders {
+		folders[f.ID] = true
+	}
+	for _, f := range vault.Folders {
+		children[parentKey(f.ParentID, folders)] = append(children[parentKey(f.ParentID, folders)], f)
+	}
+	secrets := make(map[uint64][]models.ReadSecretDTO)
+	for _, secret := range vault.Secrets {
+		secrets[parentKey(secret.FolderID, folders)] = append(secrets[parentKey(secret.FolderID, folders)], secret)
+	}
+
+	db := &database{}
+	now := time.Now()
+	root := xmlGroup{UUID: newUUID(), Name: rootGroupName, Times: newTimes(now, now)}
+	fillGroup(db, &root, 0, children, secrets, make(map[uint64]bool))
+	db.doc = &xmlDocument{
+		Meta: xmlMeta{Generator: "GophKeeper", DatabaseName: rootGroupName, RecycleBinEnabled: "False"},
+		Root: xmlRoot{Group: root},
+	}
+	return encode(w, db, creds, s)
+}
+
+// fillGroup добавляет в группу записи секретов папки и вложенные группы.
+// visited защищает от зацикливания при повреждённом дереве папок.
+func fillGroup(db *database, g *xmlGroup, folderID uint64, children map[uint64][]models.ReadFolderDTO,
+	secrets map[uint64][]models.ReadSecretDTO, visited map[uint64]bool) {
+	visited[folderID] = true
+	for _, secret := range secrets[folderID] {
+		g.Entries = append(g.Entries, secretEntry(db, secret))
+	}
+	for _, f := range children[folderID] {
+		if visited[f.ID] {
+			continue
+		}
+		sub := xmlGroup{UUID: newUUID(), Name: f.Name, Times: newTimes(f.CreatedAt, f.UpdatedAt)}
+		fillGroup(db, &sub, f.ID, children, secrets, visited)
+		g.Groups = append(g.Groups, sub)
+	}
+}
+
+// secretEntry преобразует секрет в запись KeePass; бинарные данные добавляются во вложения базы.
+func secretEntry(db *database, secret models.ReadSecretDTO) xmlEntry {
+	e := xmlEntry{
+		UUID:  newUUID(),
+		Tags:  strings.Join(secret.Tags, ";"),
+		Times: newTimes(secret.CreatedAt, secret.UpdatedAt),
+	}
+	add := func(key, value string, protected bool) {
+		s := xmlString{Key: key, Value: xmlValue{Content: value}}
+		if protected {
+			s.Value.Protected = "True"
+		}
+		e.Strings = append(e.Strings, s)
+	}
+
+	add(fieldTitle, secret.Title, false)
+	data := secret.Data
+	switch {
+	case data.LoginPassword != nil:
+		add(fieldUserName, data.LoginPassword.Login, false)
+		add(fieldPassword, data.LoginPassword.Password, true)
+		add(fieldURL, data.LoginPassword.URL, false)
+		add(fieldNotes, data.LoginPassword.Notes, false)
+	case data.Card != nil:
+		add(fieldCardNumber, data.Card.Number, false)
+		add(fieldCardHolder, data.Card.Holder, false)
+		add(fieldCardExpire, data.Card.ExpireDate, false)
+		add(fieldCardCVV, data.Card.CVV, true)
+	case data.Text != nil:
+		add(fieldNotes, *data.Text, false)
+	case data.Binary != nil:
+		b := xmlBinary{Key: secret.Title}
+		b.Value.Ref = len(db.binaries)
+		db.binaries = append(db.binaries, data.Binary)
+		e.Binaries = append(e.Binaries, b)
+	}
+	return e
+}
+
+// parentKey возвращает ID папки для группировки; 0 — корень или папка, которой нет в хранилище.
+func parentKey(id *uint64, folders map[uint64]bool) uint64 {
+	if id == nil || !folders[*id] {
+		return 0
+	}
+	return *id
+}
@@ -0,0 +1,149 @@
+package keepass
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/shekshuev/gophkeeper/internal/importer"
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// Поля записи с банковской картой, которые записывает Export.
+const (
+	fieldCardNumber = "Card Number"
+	fieldCardHolder = "Card Holder"
+	fieldCardExpire = "Expire Date"
+	fieldCardCVV    = "CVV"
+)
+
+// Import открывает базу KeePass и преобразует её содержимое в записи для импорта.
+//
+// Группы становятся папками (корневая группа базы не переносится), записи с логином или паролем —
+// секретами с логином и паролем, записи с полями карты — картами, остальные — текстовыми секретами.
+// Дополнительные поля записи переносятся в заметки, теги записи — в теги секрета.
+// Каждое вложение становится отдельным бинарным секретом «Запись — файл» в той же папке;
+// запись, состоящая из одного вложения, — бинарным секретом с названием записи.
+// Записи из корзины KeePass и история изменений записей не импортируются.
+func Import(data []byte, creds Credentials) (*importer.Result, error) {
+	db, err := decode(data, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	recycleBin := db.doc.Meta.RecycleBinUUID
+	if strings.EqualFold(db.doc.Meta.RecycleBinEnabled, "false") {
+		recycleBin = ""
+	}
+
+	result := &importer.Result{}
+	importGroup(result, db, db.doc.Root.Group, nil, recycleBin)
+	return result, nil
+}
+
+// importGroup добавляет в результат записи группы и вложенных групп.
+func importGroup(result *importer.Result, db *database, g xmlGroup, path []string, recycleBin string) {
+	for _, e := range g.Entries {
+		importEntry(result, db, e, path)
+	}
+	for _, sub := range g.Groups {
+		if recycleBin != "" && sub.UUID == recycleBin {
+			continue
+		}
+		importGroup(result, db, sub, append(slices.Clone(path), sub.Name), recycleBin)
+	}
+}
+
+// importEntry добавляет в результат запись и её вложения.
+func importEntry(result *importer.Result, db *database, e xmlEntry, path []string) {
+	title := strings.TrimSpace(e.field(fieldTitle))
+	login, password := e.field(fieldUserName), e.field(fieldPassword)
+	uri, notes := e.field(fieldURL), e.field(fieldNotes)
+	tags := splitTags(e.Tags)
+
+	var custom []string
+	for _, s := range e.Strings {
+		switch s.Key {
+		case fieldTitle, fieldUserName, fieldPassword, fieldURL, fieldNotes:
+			continue
+		}
+		if v := strings.TrimSpace(s.Value.Content); v != "" {
+			custom = append(custom, s.Key+": "+v)
+		}
+	}
+
+	item := importer.Item{Title: title, Folder: path, Tags: tags}
+	switch {
+	case login != "" || password != "":
+		item.Data.LoginPassword = &models.LoginPasswordData{
+			Login:    login,
+			Password: password,
+			URL:      uri,
+			Notes:    joinLines(append([]string{notes}, custom...)...),
+		}
+	case e.field(fieldCardNumber) != "":
+		item.Data.Card = &models.CardData{
+			Number:     strings.ReplaceAll(e.field(fieldCardNumber), " ", ""),
+			Holder:     e.field(fieldCardHolder),
+			ExpireDate: e.field(fieldCardExpire),
+			CVV:        e.field(fieldCardCVV),
+		}
+	default:
+		lines := []string{notes}
+		if uri != "" {
+			lines = append(lines, "URL: "+uri)
+		}
+		text := joinLines(append(lines, custom...)...)
+		if text == "" && len(e.Binaries) == 0 {
+			result.Skip(title, "пустая запись")
+			return
+		}
+		if text != "" {
+			item.Data.Text = &text
+		}
+	}
+	hasData := item.Data.LoginPassword != nil || item.Data.Card != nil || item.Data.Text != nil
+	if hasData {
+		result.Add(item)
+	}
+
+	for _, b := range e.Binaries {
+		if b.Value.Ref < 0 || b.Value.Ref >= len(db.binaries) {
+			result.Warn(title, fmt.Sprintf("вложение %s не найдено в базе", b.Key))
+			continue
+		}
+		// Запись из единственного вложения становится бинарным секретом с названием записи
+		attachmentTitle := title + " — " + b.Key
+		if !hasData && len(e.Binaries) == 1 && title != "" {
+			attachmentTitle = title
+		}
+		result.Add(importer.Item{
+			Title:  attachmentTitle,
+			Folder: path,
+			Tags:   tags,
+			Data:   models.SecretDataDTO{Binary: db.binaries[b.Value.Ref]},
+		})
+	}
+}
+
+// splitTags разбирает теги записи, разделённые точкой с запятой или запятой.
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == ',' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// joinLines склеивает непустые строки через перевод строки.
+func joinLines(lines ...string) string {
+	var out []string
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,538 @@
+// Package keepass читает и записывает базы KeePass в формате KDBX 4 без внешних программ и библиотек KeePass.
+//
+// Поддерживаются шифры AES-256, ChaCha20 и Twofish, функции формирования ключа AES-KDF, Argon2d и Argon2id,
+// мастер-пароль и ключевой файл (XML версий 1.0 и 2.0, 32-байтовый, hex или произвольный файл).
+package keepass
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20"
+	"golang.org/x/crypto/twofish"
+)
+
+// Сигнатура и версия формата файла.
+const (
+	signature1   uint32 = 0x9AA2D903
+	signature2   uint32 = 0xB54BFB67
+	versionMajor uint16 = 4
+)
+
+// Поля внешнего заголовка KDBX 4.
+const (
+	headerEnd           = 0
+	headerCipherID      = 2
+	headerCompression   = 3
+	headerMasterSeed    = 4
+	headerEncryptionIV  = 7
+	headerKdfParameters = 11
+)
+
+// Поля внутреннего заголовка KDBX 4.
+const (
+	innerHeaderEnd = 0
+	innerStreamID  = 1
+	innerStreamKey = 2
+	innerBinary    = 3
+)
+
+// innerStreamChaCha20 — идентификатор шифра защищённых значений ChaCha20 во внутреннем заголовке.
+const innerStreamChaCha20 = 3
+
+// blockSize — размер блока данных при записи файла.
+const blockSize = 1 << 20
+
+// Идентификаторы шифров.
+var (
+	cipherAES256   = mustUUID("31c1f2e6bf714350be5805216afc5aff")
+	cipherChaCha20 = mustUUID("d6038a2b8b6f4cb5a524339a31dbb59a")
+	cipherTwofish  = mustUUID("ad68f29f576f4bb9a36ad47af965346c")
+)
+
+var (
+	// ErrInvalidFile возвращается, если файл не является базой KeePass.
+	ErrInvalidFile = errors.New("not a KeePass database")
+	// ErrUnsupportedVersion возвращается для баз версий, отличных от KDBX 4.
+	ErrUnsupportedVersion = errors.New("unsupported database version, only KDBX 4 is supported")
+	// ErrUnsupportedCipher возвращается для неизвестного шифра базы.
+	ErrUnsupportedCipher = errors.New("unsupported database cipher")
+	// ErrUnsupportedKDF возвращается для неизвестной функции формирования ключа или её параметров.
+	ErrUnsupportedKDF = errors.New("unsupported key derivation function")
+	// ErrInvalidCredentials возвращается, если мастер-пароль или ключевой файл не подходят к базе.
+	ErrInvalidCredentials = errors.New("invalid master password or key file")
+	// ErrNoCredentials возвращается, если не задан ни мастер-пароль, ни ключевой файл.
+	ErrNoCredentials = errors.New("master password or key file is required")
+	// ErrCorrupted возвращается, если структура файла повреждена.
+	ErrCorrupted = errors.New("database is corrupted")
+)
+
+// Credentials — данные для открытия базы.
+type Credentials struct {
+	Password string // Мастер-пароль (пустой — без пароля)
+	KeyFile  []byte // Содержимое ключевого файла (nil — без ключевого файла)
+}
+
+// compositeKey собирает составной ключ базы из мастер-пароля и ключевого файла.
+func (c Credentials) compositeKey() ([]byte, error) {
+	if c.Password == "" && c.KeyFile == nil {
+		return nil, ErrNoCredentials
+	}
+	h := sha256.New()
+	if c.Password != "" {
+		p := sha256.Sum256([]byte(c.Password))
+		h.Write(p[:])
+	}
+	if c.KeyFile != nil {
+		key, err := keyFileKey(c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		h.Write(key)
+	}
+	return h.Sum(nil), nil
+}
+
+// settings — параметры шифрования при записи базы.
+type settings struct {
+	cipher []byte // Идентификатор шифра
+	kdf    kdfSettings
+}
+
+// database — расшифрованное содержимое базы.
+type database struct {
+	doc      *xmlDocument // Дерево групп и записей
+	binaries [][]byte     // Вложения из внутреннего заголовка; записи ссылаются на них по индексу
+}
+
+// header — разобранный внешний заголовок.
+type header struct {
+	cipher      []byte
+	compression uint32
+	masterSeed  []byte
+	iv          []byte
+	kdf         *variantDict
+}
+
+// decode расшифровывает и разбирает файл KDBX 4.
+func decode(data []byte, creds Credentials) (*database, error) {
+	h, headerLen, err := readHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerLen+64 {
+		return nil, ErrCorrupted
+	}
+	headerBytes := data[:headerLen]
+	if sum := sha256.Sum256(headerBytes); !bytes.Equal(sum[:], data[headerLen:headerLen+32]) {
+		return nil, ErrCorrupted
+	}
+
+	composite, err := creds.compositeKey()
+	if err != nil {
+		return nil, err
+	}
+	transformed, err := transformKey(composite, h.kdf)
+	if err != nil {
+		return nil, err
+	}
+	hmacKey := hmacBaseKey(h.masterSeed, transformed)
+	if !hmac.Equal(headerHMAC(hmacKey, headerBytes), data[headerLen+32:headerLen+64]) {
+		return nil, ErrInvalidCredentials
+	}
+
+	payload, err := readBlocks(data[headerLen+64:], hmacKey)
+	if err != nil {
+		return nil, err
+	}
+	payload, err = decryptPayload(h, encryptionKey(h.masterSeed, transformed), payload)
+	if err != nil {
+		return nil, err
+	}
+	if h.compression == 1 {
+		zr, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+		}
+		// Некоторые программы дописывают данные после сжатого потока, KeePass их игнорирует
+		zr.Multistream(false)
+		if payload, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+		}
+	}
+
+	stream, binaries, rest, err := readInnerHeader(payload)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseDocument(rest, stream)
+	if err != nil {
+		return nil, err
+	}
+	return &database{doc: doc, binaries: binaries}, nil
+}
+
+// readHeader разбирает сигнатуру и внешний заголовок. Возвращает заголовок и его длину в байтах.
+func readHeader(data []byte) (*header, int, error) {
+	if len(data) < 12 ||
+		binary.LittleEndian.Uint32(data[0:4]) != signature1 ||
+		binary.LittleEndian.Uint32(data[4:8]) != signature2 {
+		return nil, 0, ErrInvalidFile
+	}
+	if binary.LittleEndian.Uint16(data[10:12]) != versionMajor {
+		return nil, 0, ErrUnsupportedVersion
+	}
+
+	h := &header{}
+	pos := 12
+	for {
+		if len(data) < pos+5 {
+			return nil, 0, ErrCorrupted
+		}
+		id := data[pos]
+		size := int(binary.LittleEndian.Uint32(data[pos+1 : pos+5]))
+		pos += 5
+		if size < 0 || len(data) < pos+size {
+			return nil, 0, ErrCorrupted
+		}
+		value := data[pos : pos+size]
+		pos += size
+
+		switch id {
+		case headerEnd:
+			if h.cipher == nil || h.masterSeed == nil || h.iv == nil || h.kdf == nil {
+				return nil, 0, ErrCorrupted
+			}
+			return h, pos, nil
+		case headerCipherID:
+			h.cipher = value
+		case headerCompression:
+			if size != 4 {
+				return nil, 0, ErrCorrupted
+			}
+			h.compression = binary.LittleEndian.Uint32(value)
+		case headerMasterSeed:
+			if size != 32 {
+				return nil, 0, ErrCorrupted
+			}
+			h.masterSeed = value
+		case headerEncryptionIV:
+			h.iv = value
+		case headerKdfParameters:
+			kdf, err := parseVariantDict(value)
+			if err != nil {
+				return nil, 0, err
+			}
+			h.kdf = kdf
+		}
+	}
+}
+
+// readBlocks проверяет целостность блоков данных и склеивает их содержимое.
+// Каждый блок — HMAC-SHA256, длина и данные; последний блок имеет нулевую длину.
+func readBlocks(data []byte, hmacKey []byte) ([]byte, error) {
+	var out bytes.Buffer
+	for index := uint64(0); ; index++ {
+		if len(data) < 36 {
+			return nil, ErrCorrupted
+		}
+		mac := data[:32]
+		size := int(int32(binary.LittleEndian.Uint32(data[32:36])))
+		if size < 0 || len(data) < 36+size {
+			return nil, ErrCorrupted
+		}
+		block := data[36 : 36+size]
+		if !hmac.Equal(mac, blockHMAC(hmacKey, index, block)) {
+			return nil, ErrCorrupted
+		}
+		if size == 0 {
+			return out.Bytes(), nil
+		}
+		out.Write(block)
+		data = data[36+size:]
+	}
+}
+
+// decryptPayload расшифровывает данные базы шифром из заголовка.
+func decryptPayload(h *header, key, payload []byte) ([]byte, error) {
+	switch {
+	case bytes.Equal(h.cipher, cipherChaCha20):
+		stream, err := chacha20.NewUnauthenticatedCipher(key, h.iv)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+		}
+		stream.XORKeyStream(payload, payload)
+		return payload, nil
+	case bytes.Equal(h.cipher, cipherAES256), bytes.Equal(h.cipher, cipherTwofish):
+		block, err := newBlockCipher(h.cipher, key)
+		if err != nil {
+			return nil, err
+		}
+		if len(h.iv) != block.BlockSize() || len(payload) == 0 || len(payload)%block.BlockSize() != 0 {
+			return nil, ErrCorrupted
+		}
+		cipher.NewCBCDecrypter(block, h.iv).CryptBlocks(payload, payload)
+		return unpad(payload)
+	default:
+		return nil, ErrUnsupportedCipher
+	}
+}
+
+// readInnerHeader разбирает внутренний заголовок: ключ шифра защищённых значений и вложения.
+// Возвращает поток для расшифровки защищённых значений, вложения и оставшийся XML-документ.
+func readInnerHeader(data []byte) (cipher.Stream, [][]byte, []byte, error) {
+	var (
+		streamID uint32
+		key      []byte
+		binaries [][]byte
+	)
+	pos := 0
+	for {
+		if len(data) < pos+5 {
+			return nil, nil, nil, ErrCorrupted
+		}
+		id := data[pos]
+		size := int(binary.LittleEndian.Uint32(data[pos+1 : pos+5]))
+		pos += 5
+		if size < 0 || len(data) < pos+size {
+			return nil, nil, nil, ErrCorrupted
+		}
+		value := data[pos : pos+size]
+		pos += size
+
+		switch id {
+		case innerHeaderEnd:
+			if streamID != innerStreamChaCha20 || key == nil {
+				return nil, nil, nil, fmt.Errorf("%w: inner stream %d", ErrUnsupportedCipher, streamID)
+			}
+			stream, err := innerStream(key)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			return stream, binaries, data[pos:], nil
+		case innerStreamID:
+			if size != 4 {
+				return nil, nil, nil, ErrCorrupted
+			}
+			streamID = binary.LittleEndian.Uint32(value)
+		case innerStreamKey:
+			key = value
+		case innerBinary:
+			if size < 1 {
+				return nil, nil, nil, ErrCorrupted
+			}
+			// Первый байт — флаги вложения, далее содержимое
+			binaries = append(binaries, value[1:])
+		}
+	}
+}
+
+// encode шифрует и записывает базу в формате KDBX 4.
+func encode(w io.Writer, db *database, creds Credentials, s settings) error {
+	composite, err := creds.compositeKey()
+	if err != nil {
+		return err
+	}
+
+	masterSeed := randomBytes(32)
+	iv := randomBytes(16)
+	if bytes.Equal(s.cipher, cipherChaCha20) {
+		iv = randomBytes(12)
+	}
+	innerKey := randomBytes(64)
+	kdf := s.kdf.params()
+
+	var hdr bytes.Buffer
+	_ = binary.Write(&hdr, binary.LittleEndian, signature1)
+	_ = binary.Write(&hdr, binary.LittleEndian, signature2)
+	_ = binary.Write(&hdr, binary.LittleEndian, uint16(0))
+	_ = binary.Write(&hdr, binary.LittleEndian, versionMajor)
+	writeField(&hdr, headerCipherID, s.cipher)
+	writeField(&hdr, headerCompression, binary.LittleEndian.AppendUint32(nil, 1))
+	writeField(&hdr, headerMasterSeed, masterSeed)
+	writeField(&hdr, headerEncryptionIV, iv)
+	writeField(&hdr, headerKdfParameters, kdf.marshal())
+	writeField(&hdr, headerEnd, []byte("\r\n\r\n"))
+
+	transformed, err := transformKey(composite, kdf)
+	if err != nil {
+		return err
+	}
+	hmacKey := hmacBaseKey(masterSeed, transformed)
+
+	stream, err := innerStream(innerKey)
+	if err != nil {
+		return err
+	}
+	xmlData, err := marshalDocument(db.doc, stream)
+	if err != nil {
+		return err
+	}
+
+	var inner bytes.Buffer
+	writeField(&inner, innerStreamID, binary.LittleEndian.AppendUint32(nil, innerStreamChaCha20))
+	writeField(&inner, innerStreamKey, innerKey)
+	for _, b := range db.binaries {
+		writeField(&inner, innerBinary, append([]byte{0}, b...))
+	}
+	writeField(&inner, innerHeaderEnd, nil)
+	inner.Write(xmlData)
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(inner.Bytes()); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	payload, err := encryptPayload(s.cipher, encryptionKey(masterSeed, transformed), iv, compressed.Bytes())
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	out.Write(hdr.Bytes())
+	sum := sha256.Sum256(hdr.Bytes())
+	out.Write(sum[:])
+	out.Write(headerHMAC(hmacKey, hdr.Bytes()))
+	writeBlocks(&out, payload, hmacKey)
+
+	_, err = w.Write(out.Bytes())
+	return err
+}
+
+// encryptPayload шифрует данные базы выбранным шифром.
+func encryptPayload(id, key, iv, payload []byte) ([]byte, error) {
+	if bytes.Equal(id, cipherChaCha20) {
+		stream, err := chacha20.NewUnauthenticatedCipher(key, iv)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, len(payload))
+		stream.XORKeyStream(out, payload)
+		return out, nil
+	}
+	block, err := newBlockCipher(id, key)
+	if err != nil {
+		return nil, err
+	}
+	out := pad(payload, block.BlockSize())
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(out, out)
+	return out, nil
+}
+
+// writeBlocks записывает данные блоками с HMAC и завершающий пустой блок.
+func writeBlocks(w *bytes.Buffer, data []byte, hmacKey []byte) {
+	for index := uint64(0); ; index++ {
+		n := min(len(data), blockSize)
+		block := data[:n]
+		w.Write(blockHMAC(hmacKey, index, block))
+		_ = binary.Write(w, binary.LittleEndian, int32(n))
+		w.Write(block)
+		if n == 0 {
+			return
+		}
+		data = data[n:]
+	}
+}
+
+// writeField записывает поле заголовка: идентификатор, длину и значение.
+func writeField(w *bytes.Buffer, id byte, value []byte) {
+	w.WriteByte(id)
+	_ = binary.Write(w, binary.LittleEndian, uint32(len(value)))
+	w.Write(value)
+}
+
+// newBlockCipher создаёт блочный шифр AES-256 или Twofish.
+func newBlockCipher(id, key []byte) (cipher.Block, error) {
+	switch {
+	case bytes.Equal(id, cipherAES256):
+		return aes.NewCipher(key)
+	case bytes.Equal(id, cipherTwofish):
+		return twofish.NewCipher(key)
+	default:
+		return nil, ErrUnsupportedCipher
+	}
+}
+
+// innerStream создаёт поток ChaCha20 для защищённых значений по ключу из внутреннего заголовка.
+func innerStream(key []byte) (cipher.Stream, error) {
+	h := sha512.Sum512(key)
+	return chacha20.NewUnauthenticatedCipher(h[:32], h[32:44])
+}
+
+// encryptionKey вычисляет ключ шифрования данных базы.
+func encryptionKey(masterSeed, transformed []byte) []byte {
+	h := sha256.New()
+	h.Write(masterSeed)
+	h.Write(transformed)
+	return h.Sum(nil)
+}
+
+// hmacBaseKey вычисляет базовый ключ, из которого выводятся ключи HMAC заголовка и блоков.
+func hmacBaseKey(masterSeed, transformed []byte) []byte {
+	h := sha512.New()
+	h.Write(masterSeed)
+	h.Write(transformed)
+	h.Write([]byte{1})
+	return h.Sum(nil)
+}
+
+// blockKey вычисляет ключ HMAC блока с указанным индексом.
+func blockKey(base []byte, index uint64) []byte {
+	h := sha512.New()
+	_ = binary.Write(h, binary.LittleEndian, index)
+	h.Write(base)
+	return h.Sum(nil)
+}
+
+// headerHMAC вычисляет HMAC заголовка (индекс блока — максимальное значение uint64).
+func headerHMAC(base, headerBytes []byte) []byte {
+	mac := hmac.New(sha256.New, blockKey(base, ^uint64(0)))
+	mac.Write(headerBytes)
+	return mac.Sum(nil)
+}
+
+// blockHMAC вычисляет HMAC блока данных.
+func blockHMAC(base []byte, index uint64, block []byte) []byte {
+	mac := hmac.New(sha256.New, blockKey(base, index))
+	_ = binary.Write(mac, binary.LittleEndian, index)
+	_ = binary.Write(mac, binary.LittleEndian, int32(len(block)))
+	mac.Write(block)
+	return mac.Sum(nil)
+}
+
+// pad дополняет данные до размера блока по PKCS #7.
+func pad(data []byte, size int) []byte {
+	n := size - len(data)%size
+	return append(append(make([]byte, 0, len(data)+n), data...), bytes.Repeat([]byte{byte(n)}, n)...)
+}
+
+// unpad удаляет дополнение PKCS #7.
+func unpad(data []byte) ([]byte, error) {
+	n := int(data[len(data)-1])
+	if n == 0 || n > len(data) || !bytes.Equal(data[len(data)-n:], bytes.Repeat([]byte{byte(n)}, n)) {
+		return nil, ErrCorrupted
+	}
+	return data[:len(data)-n], nil
+}
+
+// randomBytes возвращает n случайных байт.
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
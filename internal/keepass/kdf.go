@@ -0,0 +1,239 @@
+package keepass
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/tobischo/argon2"
+)
+
+// Идентификаторы функций формирования ключа.
+var (
+	kdfAES      = mustUUID("c9d9f39a628a4460bf740d08c18a4fea")
+	kdfAESKDBX3 = mustUUID("7c02bb8279a74ac0927d114a00648238")
+	kdfArgon2d  = mustUUID("ef636ddf8c29444b91f7a9a403e30a0c")
+	kdfArgon2id = mustUUID("9e298b1956db4773b23dfc3ec6f0a1e6")
+)
+
+// argon2Version — единственная поддерживаемая версия Argon2 (1.3).
+const argon2Version = 0x13
+
+// kdfSettings — параметры функции формирования ключа для новой базы.
+type kdfSettings struct {
+	uuid        []byte // AES-KDF, Argon2d или Argon2id
+	rounds      uint64 // Число раундов AES-KDF
+	iterations  uint64
+	memory      uint64 // Объём памяти в байтах
+	parallelism uint32
+}
+
+// defaultSettings — параметры, с которыми записываются экспортируемые базы.
+// AES-256 и Argon2d поддерживаются всеми программами, читающими KDBX 4.
+var defaultSettings = settings{
+	cipher: cipherAES256,
+	kdf: kdfSettings{
+		uuid:        kdfArgon2d,
+		iterations:  10,
+		memory:      64 << 20,
+		parallelism: 2,
+	},
+}
+
+// params собирает параметры функции формирования ключа для заголовка со случайной солью.
+func (s kdfSettings) params() *variantDict {
+	d := &variantDict{}
+	d.setBytes("$UUID", s.uuid)
+	if bytes.Equal(s.uuid, kdfAES) {
+		d.setUint64("R", s.rounds)
+		d.setBytes("S", randomBytes(32))
+		return d
+	}
+	d.setUint32("V", argon2Version)
+	d.setBytes("S", randomBytes(32))
+	d.setUint32("P", s.parallelism)
+	d.setUint64("M", s.memory)
+	d.setUint64("I", s.iterations)
+	return d
+}
+
+// transformKey вычисляет ключ базы из составного ключа по параметрам из заголовка.
+func transformKey(composite []byte, params *variantDict) ([]byte, error) {
+	id, _ := params.bytes("$UUID")
+	salt, ok := params.bytes("S")
+	if !ok {
+		return nil, fmt.Errorf("%w: no salt", ErrUnsupportedKDF)
+	}
+
+	switch {
+	case bytes.Equal(id, kdfAES), bytes.Equal(id, kdfAESKDBX3):
+		rounds, ok := params.uint64("R")
+		if !ok {
+			return nil, fmt.Errorf("%w: no rounds", ErrUnsupportedKDF)
+		}
+		block, err := aes.NewCipher(salt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedKDF, err)
+		}
+		key := bytes.Clone(composite)
+		for i := uint64(0); i < rounds; i++ {
+			block.Encrypt(key[:16], key[:16])
+			block.Encrypt(key[16:], key[16:])
+		}
+		sum := sha256.Sum256(key)
+		return sum[:], nil
+
+	case bytes.Equal(id, kdfArgon2d), bytes.Equal(id, kdfArgon2id):
+		version, _ := params.uint64("V")
+		iterations, _ := params.uint64("I")
+		memory, _ := params.uint64("M")
+		parallelism, _ := params.uint64("P")
+		secret, _ := params.bytes("K")
+		data, _ := params.bytes("A")
+		if version != argon2Version || len(secret) > 0 || len(data) > 0 {
+			return nil, fmt.Errorf("%w: argon2 version %#x", ErrUnsupportedKDF, version)
+		}
+		if iterations == 0 || iterations > 1<<32-1 || parallelism == 0 || parallelism > 255 ||
+			memory/1024 < 8*parallelism || memory/1024 > 1<<32-1 {
+			return nil, fmt.Errorf("%w: invalid argon2 parameters", ErrUnsupportedKDF)
+		}
+		derive := argon2.DKey
+		if bytes.Equal(id, kdfArgon2id) {
+			derive = argon2.IDKey
+		}
+		return derive(composite, salt, uint32(iterations), uint32(memory/1024), uint8(parallelism), 32), nil
+
+	default:
+		return nil, fmt.Errorf("%w: %x", ErrUnsupportedKDF, id)
+	}
+}
+
+// Типы значений словаря параметров (VariantDictionary).
+const (
+	variantEnd    = 0x00
+	variantUint32 = 0x04
+	variantUint64 = 0x05
+	variantBytes  = 0x42
+)
+
+// variantVersion — версия формата словаря параметров.
+const variantVersion = 0x0100
+
+// variantItem — значение словаря параметров.
+type variantItem struct {
+	typ   byte
+	name  string
+	value []byte
+}
+
+// variantDict — словарь параметров KDBX 4 с сохранением порядка ключей.
+type variantDict struct {
+	items []variantItem
+}
+
+// parseVariantDict разбирает словарь параметров.
+func parseVariantDict(data []byte) (*variantDict, error) {
+	if len(data) < 2 || binary.LittleEndian.Uint16(data)&0xFF00 != variantVersion&0xFF00 {
+		return nil, ErrCorrupted
+	}
+	d := &variantDict{}
+	pos := 2
+	for {
+		if len(data) < pos+1 {
+			return nil, ErrCorrupted
+		}
+		typ := data[pos]
+		pos++
+		if typ == variantEnd {
+			return d, nil
+		}
+		var fields [2][]byte
+		for i := range fields {
+			if len(data) < pos+4 {
+				return nil, ErrCorrupted
+			}
+			size := int(int32(binary.LittleEndian.Uint32(data[pos:])))
+			pos += 4
+			if size < 0 || len(data) < pos+size {
+				return nil, ErrCorrupted
+			}
+			fields[i] = data[pos : pos+size]
+			pos += size
+		}
+		d.items = append(d.items, variantItem{typ: typ, name: string(fields[0]), value: fields[1]})
+	}
+}
+
+// marshal кодирует словарь параметров.
+func (d *variantDict) marshal() []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(variantVersion))
+	for _, item := range d.items {
+		buf.WriteByte(item.typ)
+		_ = binary.Write(&buf, binary.LittleEndian, int32(len(item.name)))
+		buf.WriteString(item.name)
+		_ = binary.Write(&buf, binary.LittleEndian, int32(len(item.value)))
+		buf.Write(item.value)
+	}
+	buf.WriteByte(variantEnd)
+	return buf.Bytes()
+}
+
+// get возвращает значение по ключу.
+func (d *variantDict) get(name string) (variantItem, bool) {
+	for _, item := range d.items {
+		if item.name == name {
+			return item, true
+		}
+	}
+	return variantItem{}, false
+}
+
+// bytes возвращает значение-массив байт.
+func (d *variantDict) bytes(name string) ([]byte, bool) {
+	item, ok := d.get(name)
+	if !ok || item.typ != variantBytes {
+		return nil, false
+	}
+	return item.value, true
+}
+
+// uint64 возвращает беззнаковое целое значение UInt32 или UInt64.
+func (d *variantDict) uint64(name string) (uint64, bool) {
+	item, ok := d.get(name)
+	switch {
+	case ok && item.typ == variantUint32 && len(item.value) == 4:
+		return uint64(binary.LittleEndian.Uint32(item.value)), true
+	case ok && item.typ == variantUint64 && len(item.value) == 8:
+		return binary.LittleEndian.Uint64(item.value), true
+	default:
+		return 0, false
+	}
+}
+
+// setBytes добавляет значение-массив байт.
+func (d *variantDict) setBytes(name string, value []byte) {
+	d.items = append(d.items, variantItem{typ: variantBytes, name: name, value: value})
+}
+
+// setUint32 добавляет значение UInt32.
+func (d *variantDict) setUint32(name string, value uint32) {
+	d.items = append(d.items, variantItem{typ: variantUint32, name: name, value: binary.LittleEndian.AppendUint32(nil, value)})
+}
+
+// setUint64 добавляет значение UInt64.
+func (d *variantDict) setUint64(name string, value uint64) {
+	d.items = append(d.items, variantItem{typ: variantUint64, name: name, value: binary.LittleEndian.AppendUint64(nil, value)})
+}
+
+// mustUUID разбирает идентификатор из шестнадцатеричной записи.
+func mustUUID(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 16 {
+		panic("keepass: invalid uuid " + s)
+	}
+	return b
+}
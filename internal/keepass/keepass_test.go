@@ -0,0 +1,232 @@
+package keepass
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fastKDF — параметры Argon2, при которых тесты выполняются быстро.
+var fastKDF = kdfSettings{uuid: kdfArgon2id, iterations: 1, memory: 64 << 10, parallelism: 1}
+
+func testVault() Vault {
+	folder := uint64(1)
+	sub := uint64(2)
+	missing := uint64(99)
+	text := "заметка"
+	created := time.Date(2025, 7, 16, 10, 0, 0, 0, time.UTC)
+	return Vault{
+		Folders: []models.ReadFolderDTO{
+			{ID: 1, Name: "Работа"},
+			{ID: 2, ParentID: &folder, Name: "VPN"},
+		},
+		Secrets: []models.ReadSecretDTO{
+			{Title: "Почта", Tags: []string{"mail", "личное"}, CreatedAt: created, UpdatedAt: created,
+				Data: models.SecretDataDTO{LoginPassword: &models.LoginPasswordData{Login: "bob", Password: "p@ss", URL: "https://mail.example.com", Notes: "основной"}}},
+			{Title: "Карта", FolderID: &folder,
+				Data: models.SecretDataDTO{Card: &models.CardData{Number: "4111111111111111", Holder: "BOB", ExpireDate: "12/26", CVV: "123"}}},
+			{Title: "Ключ", FolderID: &sub, Data: models.SecretDataDTO{Binary: []byte{0, 1, 2}}},
+			{Title: "Текст", FolderID: &missing, Data: models.SecretDataDTO{Text: &text}},
+		},
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	tcs := []struct {
+		name   string
+		cipher []byte
+		kdf    kdfSettings
+	}{
+		{name: "AES_Argon2d", cipher: cipherAES256, kdf: kdfSettings{uuid: kdfArgon2d, iterations: 1, memory: 64 << 10, parallelism: 2}},
+		{name: "ChaCha20_Argon2id", cipher: cipherChaCha20, kdf: fastKDF},
+		{name: "Twofish_AESKDF", cipher: cipherTwofish, kdf: kdfSettings{uuid: kdfAES, rounds: 1000}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			creds := Credentials{Password: "master"}
+			require.NoError(t, export(&buf, testVault(), creds, settings{cipher: tc.cipher, kdf: tc.kdf}))
+
+			result, err := Import(buf.Bytes(), creds)
+			require.NoError(t, err)
+			require.Len(t, result.Items, 4)
+
+			login := result.Items[0]
+			assert.Equal(t, "Почта", login.Title)
+			assert.Empty(t, login.Folder)
+			assert.Equal(t, []string{"mail", "личное"}, login.Tags)
+			assert.Equal(t, &models.LoginPasswordData{Login: "bob", Password: "p@ss", URL: "https://mail.example.com", Notes: "основной"}, login.Data.LoginPassword)
+
+			text := result.Items[1]
+			assert.Equal(t, "Текст", text.Title)
+			assert.Equal(t, "заметка", *text.Data.Text)
+
+			card := result.Items[2]
+			assert.Equal(t, []string{"Работа"}, card.Folder)
+			assert.Equal(t, &models.CardData{Number: "4111111111111111", Holder: "BOB", ExpireDate: "12/26", CVV: "123"}, card.Data.Card)
+
+			file := result.Items[3]
+			assert.Equal(t, "Ключ", file.Title)
+			assert.Equal(t, []string{"Работа", "VPN"}, file.Folder)
+			assert.Equal(t, []byte{0, 1, 2}, file.Data.Binary)
+		})
+	}
+}
+
+func TestImport_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, export(&buf, testVault(), Credentials{Password: "master"}, settings{cipher: cipherAES256, kdf: fastKDF}))
+	data := buf.Bytes()
+
+	_, err := Import(data, Credentials{Password: "wrong"})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = Import(data, Credentials{})
+	assert.ErrorIs(t, err, ErrNoCredentials)
+
+	_, err = Import([]byte("not a database"), Credentials{Password: "master"})
+	assert.ErrorIs(t, err, ErrInvalidFile)
+
+	kdbx3 := bytes.Clone(data)
+	binary.LittleEndian.PutUint16(kdbx3[10:12], 3)
+	_, err = Import(kdbx3, Credentials{Password: "master"})
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+
+	tampered := bytes.Clone(data)
+	tampered[len(tampered)-40] ^= 0xFF
+	_, err = Import(tampered, Credentials{Password: "master"})
+	assert.ErrorIs(t, err, ErrCorrupted)
+}
+
+func TestImport_KeyFile(t *testing.T) {
+	keyFile := []byte("произвольный файл в качестве ключа")
+	var buf bytes.Buffer
+	require.NoError(t, export(&buf, testVault(), Credentials{Password: "master", KeyFile: keyFile}, settings{cipher: cipherAES256, kdf: fastKDF}))
+
+	_, err := Import(buf.Bytes(), Credentials{Password: "master"})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	result, err := Import(buf.Bytes(), Credentials{Password: "master", KeyFile: keyFile})
+	require.NoError(t, err)
+	assert.Len(t, result.Items, 4)
+}
+
+func TestImport_Entries(t *testing.T) {
+	recycleBin := newUUID()
+	value := func(s string) xmlValue { return xmlValue{Content: s} }
+	doc := &xmlDocument{
+		Meta: xmlMeta{RecycleBinEnabled: "True", RecycleBinUUID: recycleBin},
+		Root: xmlRoot{Group: xmlGroup{
+			Name: "База",
+			Entries: []xmlEntry{
+				{Tags: "a; b,c", Strings: []xmlString{
+					{Key: fieldTitle, Value: value("Сервер")},
+					{Key: fieldPassword, Value: xmlValue{Content: "root", Protected: "True"}},
+					{Key: "PIN", Value: xmlValue{Content: "1234", Protected: "True"}},
+				}},
+				{Strings: []xmlString{
+					{Key: fieldTitle, Value: value("Документы")},
+					{Key: fieldNotes, Value: value("сканы")},
+				}, Binaries: []xmlBinary{binaryRef("паспорт.pdf", 0), binaryRef("потерян.pdf", 5)}},
+				{Strings: []xmlString{{Key: fieldTitle, Value: value("Пусто")}}},
+			},
+			Groups: []xmlGroup{
+				{UUID: recycleBin, Name: "Корзина", Entries: []xmlEntry{
+					{Strings: []xmlString{{Key: fieldTitle, Value: value("Удалённая")}, {Key: fieldNotes, Value: value("x")}}},
+				}},
+			},
+		}},
+	}
+	var buf bytes.Buffer
+	creds := Credentials{Password: "master"}
+	require.NoError(t, encode(&buf, &database{doc: doc, binaries: [][]byte{[]byte("pdf")}}, creds, settings{cipher: cipherChaCha20, kdf: fastKDF}))
+
+	result, err := Import(buf.Bytes(), creds)
+	require.NoError(t, err)
+	require.Len(t, result.Items, 3)
+
+	assert.Equal(t, []string{"a", "b", "c"}, result.Items[0].Tags)
+	assert.Equal(t, &models.LoginPasswordData{Password: "root", Notes: "PIN: 1234"}, result.Items[0].Data.LoginPassword)
+	assert.Equal(t, "сканы", *result.Items[1].Data.Text)
+	assert.Equal(t, "Документы — паспорт.pdf", result.Items[2].Title)
+	assert.Equal(t, []byte("pdf"), result.Items[2].Data.Binary)
+	assert.Equal(t, []string{"Пусто: пустая запись"}, result.Skipped)
+	assert.Equal(t, []string{"Документы: вложение потерян.pdf не найдено в базе"}, result.Warnings)
+}
+
+func TestParseDocument_History(t *testing.T) {
+	key := randomBytes(64)
+	stream, err := innerStream(key)
+	require.NoError(t, err)
+	protect := func(s string) string {
+		raw := []byte(s)
+		stream.XORKeyStream(raw, raw)
+		return base64.StdEncoding.EncodeToString(raw)
+	}
+	// Пароль из истории зашифрован раньше текущего пароля следующей записи
+	xmlData := `<?xml version="1.0" encoding="utf-8"?><KeePassFile><Root><Group><Name>Root</Name>` +
+		`<Entry><String><Key>Password</Key><Value Protected="True">` + protect("first") + `</Value></String>` +
+		`<History><Entry><String><Key>Password</Key><Value Protected="True">` + protect("old") + `</Value></String></Entry></History></Entry>` +
+		`<Entry><String><Key>Password</Key><Value Protected="True">` + protect("second") + `</Value></String></Entry>` +
+		`</Group></Root></KeePassFile>`
+
+	stream, err = innerStream(key)
+	require.NoError(t, err)
+	doc, err := parseDocument([]byte(xmlData), stream)
+	require.NoError(t, err)
+	require.Len(t, doc.Root.Group.Entries, 2)
+	assert.Equal(t, "first", doc.Root.Group.Entries[0].field(fieldPassword))
+	assert.Equal(t, "second", doc.Root.Group.Entries[1].field(fieldPassword))
+}
+
+func TestKeyFileKey(t *testing.T) {
+	raw := bytes.Repeat([]byte{7}, 32)
+	sum := sha256.Sum256(raw)
+
+	tcs := []struct {
+		name string
+		data []byte
+		want []byte
+	}{
+		{name: "Binary", data: raw, want: raw},
+		{name: "Hex", data: []byte(hex.EncodeToString(raw)), want: raw},
+		{name: "Other", data: []byte("short"), want: sha256Of("short")},
+		{name: "XML_v1", data: []byte(`<?xml version="1.0"?><KeyFile><Meta><Version>1.00</Version></Meta><Key><Data>` +
+			base64.StdEncoding.EncodeToString(raw) + `</Data></Key></KeyFile>`), want: raw},
+		{name: "XML_v2", data: []byte(`<?xml version="1.0"?><KeyFile><Meta><Version>2.0</Version></Meta><Key><Data Hash="` +
+			hex.EncodeToString(sum[:4]) + `">07070707 07070707 07070707 07070707
+			07070707 07070707 07070707 07070707</Data></Key></KeyFile>`), want: raw},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := keyFileKey(tc.data)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, key)
+		})
+	}
+
+	t.Run("XML_v2_BadHash", func(t *testing.T) {
+		_, err := keyFileKey([]byte(`<KeyFile><Meta><Version>2.0</Version></Meta><Key><Data Hash="00000000">` +
+			hex.EncodeToString(raw) + `</Data></Key></KeyFile>`))
+		assert.ErrorIs(t, err, ErrInvalidKeyFile)
+	})
+}
+
+func binaryRef(name string, ref int) xmlBinary {
+	b := xmlBinary{Key: name}
+	b.Value.Ref = ref
+	return b
+}
+
+func sha256Of(s string) []byte {
+	sum := sha256.Sum256([]byte(s))
+	return sum[:]
+}
@@ -0,0 +1,72 @@
+package keepass
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidKeyFile возвращается для повреждённого XML-ключевого файла.
+var ErrInvalidKeyFile = errors.New("invalid key file")
+
+// xmlKeyFile — ключевой файл KeePass в формате XML.
+type xmlKeyFile struct {
+	XMLName xml.Name `xml:"KeyFile"`
+	Version string   `xml:"Meta>Version"`
+	Data    struct {
+		Hash  string `xml:"Hash,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"Key>Data"`
+}
+
+// keyFileKey вычисляет 32-байтовый ключ из содержимого ключевого файла так же, как KeePass:
+// XML версий 1.0 и 2.0, 32 байта как есть, 64 шестнадцатеричных символа или SHA-256 от произвольного файла.
+func keyFileKey(data []byte) ([]byte, error) {
+	if bytes.Contains(data[:min(len(data), 512)], []byte("<KeyFile")) {
+		var kf xmlKeyFile
+		if err := xml.Unmarshal(data, &kf); err == nil {
+			return xmlKeyFileKey(kf)
+		}
+	}
+	switch {
+	case len(data) == 32:
+		return data, nil
+	case len(data) == 64:
+		if key, err := hex.DecodeString(string(data)); err == nil {
+			return key, nil
+		}
+	}
+	sum := sha256.Sum256(data)
+	return sum[:], nil
+}
+
+// xmlKeyFileKey извлекает ключ из XML-ключевого файла.
+func xmlKeyFileKey(kf xmlKeyFile) ([]byte, error) {
+	value := strings.Join(strings.Fields(kf.Data.Value), "")
+	switch {
+	case strings.HasPrefix(kf.Version, "1."):
+		key, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, ErrInvalidKeyFile
+		}
+		return key, nil
+	case strings.HasPrefix(kf.Version, "2."):
+		key, err := hex.DecodeString(value)
+		if err != nil {
+			return nil, ErrInvalidKeyFile
+		}
+		if kf.Data.Hash != "" {
+			sum := sha256.Sum256(key)
+			if !strings.EqualFold(hex.EncodeToString(sum[:4]), kf.Data.Hash) {
+				return nil, ErrInvalidKeyFile
+			}
+		}
+		return key, nil
+	default:
+		return nil, ErrInvalidKeyFile
+	}
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"log"
 	"sync"
 
@@ -13,32 +14,56 @@ type Logger struct {
 	Log *zap.Logger // Встроенный zap-логгер
 }
 
+// Форматы вывода журнала.
+const (
+	FormatJSON    = "json"    // Одна JSON-запись на строку — для сборщиков логов
+	FormatConsole = "console" // Человекочитаемый вывод — для локальной отладки
+)
+
 var (
 	instance *Logger   // глобальный синглтон
 	once     sync.Once // обеспечивает однократную инициализацию
 )
 
+// contextKey — ключ, под которым логгер запроса хранится в context.Context.
+type contextKey struct{}
+
 // NewLogger возвращает синглтон-инстанс логгера.
-// По умолчанию инициализирует zap в режиме Production с уровнем "info".
+// По умолчанию инициализирует zap в режиме Production с уровнем "info"; настройки меняет Configure.
 func NewLogger() *Logger {
 	once.Do(func() {
 		instance = &Logger{Log: zap.NewNop()} // временный "пустой" логгер, пока инициализация не завершится
-		if err := instance.initialize("info"); err != nil {
+		if err := instance.initialize("info", FormatJSON, ""); err != nil {
 			log.Fatalf("Error initializing zap logger: %v", err)
 		}
 	})
 	return instance
 }
 
-// initialize конфигурирует zap.Logger с заданным уровнем логирования.
-// Например: "debug", "info", "warn", "error".
-func (l *Logger) initialize(level string) error {
+// Configure перенастраивает синглтон: уровень ("debug", "info", "warn", "error"),
+// формат (FormatJSON или FormatConsole) и файл, в который пишется журнал (пусто — stderr).
+// Вызывается один раз при старте, до того как логгер начнут использовать другие горутины.
+func Configure(level, format, output string) error {
+	return NewLogger().initialize(level, format, output)
+}
+
+// initialize конфигурирует zap.Logger с заданным уровнем, форматом и файлом вывода.
+func (l *Logger) initialize(level, format, output string) error {
 	lvl, err := zap.ParseAtomicLevel(level)
 	if err != nil {
 		return err
 	}
 	cfg := zap.NewProductionConfig()
 	cfg.Level = lvl
+	if format != "" {
+		cfg.Encoding = format
+	}
+	if format == FormatConsole {
+		cfg.EncoderConfig = zap.NewDevelopmentEncoderConfig()
+	}
+	if output != "" {
+		cfg.OutputPaths = []string{output}
+	}
 	zl, err := cfg.Build()
 	if err != nil {
 		return err
@@ -46,3 +71,22 @@ func (l *Logger) initialize(level string) error {
 	l.Log = zl
 	return nil
 }
+
+// For возвращает логгер запроса из ctx (с ID запроса, маршрутом, пользователем и трассой),
+// а если его нет — общий логгер. Используется во всех слоях, куда передаётся контекст запроса.
+func (l *Logger) For(ctx context.Context) *zap.Logger {
+	if zl, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+		return zl
+	}
+	return l.Log
+}
+
+// NewContext возвращает копию ctx с логгером запроса zl.
+func NewContext(ctx context.Context, zl *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, zl)
+}
+
+// WithContext возвращает копию ctx, в которой логгер запроса дополнен полями fields.
+func WithContext(ctx context.Context, fields ...zap.Field) context.Context {
+	return NewContext(ctx, NewLogger().For(ctx).With(fields...))
+}
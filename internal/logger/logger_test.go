@@ -1,10 +1,14 @@
 package logger
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestNewLogger_ReturnsSingleton(t *testing.T) {
@@ -18,13 +22,47 @@ func TestNewLogger_ReturnsSingleton(t *testing.T) {
 
 func TestLogger_Initialize_InvalidLevel(t *testing.T) {
 	l := &Logger{}
-	err := l.initialize("invalid-level")
+	err := l.initialize("invalid-level", FormatJSON, "")
 	assert.Error(t, err)
 }
 
 func TestLogger_Initialize_ValidLevel(t *testing.T) {
 	l := &Logger{}
-	err := l.initialize("debug")
+	err := l.initialize("debug", FormatConsole, "")
 	assert.NoError(t, err)
 	assert.IsType(t, &zap.Logger{}, l.Log)
 }
+
+func TestLogger_Initialize_OutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	l := &Logger{}
+	assert.NoError(t, l.initialize("info", FormatJSON, path))
+
+	l.Log.Info("hello", zap.String("request_id", "req-1"))
+	assert.NoError(t, l.Log.Sync())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"request_id":"req-1"`)
+}
+
+func TestLogger_Initialize_InvalidFormat(t *testing.T) {
+	l := &Logger{}
+	assert.Error(t, l.initialize("info", "xml", ""))
+}
+
+func TestLogger_For(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	l := &Logger{Log: zap.New(core)}
+	ctx := context.Background()
+
+	assert.Same(t, l.Log, l.For(ctx))
+
+	ctx = NewContext(ctx, l.Log.With(zap.String("request_id", "req-1")))
+	ctx = WithContext(ctx, zap.String("user_id", "42"))
+	l.For(ctx).Info("secret created")
+
+	if assert.Equal(t, 1, logs.Len()) {
+		assert.Equal(t, map[string]any{"request_id": "req-1", "user_id": "42"}, logs.All()[0].ContextMap())
+	}
+}
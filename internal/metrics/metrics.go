@@ -0,0 +1,115 @@
+// Package metrics содержит метрики сервера в формате Prometheus:
+// счётчики и гистограммы HTTP-запросов, попыток аутентификации, операций с секретами,
+// статистику пулов соединений с базой данных и информацию о сборке.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "gophkeeper"
+
+// Результаты попыток аутентификации.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Registry — реестр метрик сервера. Отдельный реестр вместо глобального
+// позволяет не зависеть от метрик, которые регистрируют сторонние библиотеки.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Количество обработанных HTTP-запросов.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Время обработки HTTP-запросов в секундах.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	authAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "attempts_total",
+		Help:      "Количество попыток входа и регистрации.",
+	}, []string{"operation", "result"})
+
+	secretOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "secrets",
+		Name:      "operations_total",
+		Help:      "Количество успешных операций с секретами.",
+	}, []string{"operation"})
+
+	buildInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "build_info",
+		Help:      "Информация о сборке сервера, значение всегда 1.",
+	}, []string{"version", "commit"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		authAttempts,
+		secretOperations,
+		buildInfo,
+	)
+}
+
+// Handler возвращает HTTP-обработчик, отдающий метрики в текстовом формате Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// ObserveHTTPRequest учитывает обработанный HTTP-запрос.
+// route — шаблон маршрута chi, а не фактический путь, чтобы число серий оставалось ограниченным.
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, code).Inc()
+	httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// AuthAttempt учитывает попытку аутентификации (operation — login или register).
+func AuthAttempt(operation string, success bool) {
+	result := ResultFailure
+	if success {
+		result = ResultSuccess
+	}
+	authAttempts.WithLabelValues(operation, result).Inc()
+}
+
+// SecretOperation учитывает успешную операцию с секретом.
+func SecretOperation(operation string) {
+	secretOperations.WithLabelValues(operation).Inc()
+}
+
+// SetBuildInfo публикует версию и коммит сборки.
+func SetBuildInfo(version, commit string) {
+	buildInfo.Reset()
+	buildInfo.WithLabelValues(version, commit).Set(1)
+}
+
+// RegisterDB регистрирует сборщик статистики пула соединений sql.DB под именем name.
+// Повторная регистрация с тем же именем игнорируется: учитывается первый зарегистрированный пул.
+func RegisterDB(name string, db *sql.DB) {
+	_ = Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveHTTPRequest(t *testing.T) {
+	before := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/v1.0/secrets/{id}", "404"))
+	ObserveHTTPRequest("GET", "/v1.0/secrets/{id}", http.StatusNotFound, 10*time.Millisecond)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/v1.0/secrets/{id}", "404")))
+}
+
+func TestAuthAttempt(t *testing.T) {
+	success := testutil.ToFloat64(authAttempts.WithLabelValues("login", ResultSuccess))
+	failure := testutil.ToFloat64(authAttempts.WithLabelValues("login", ResultFailure))
+	AuthAttempt("login", true)
+	AuthAttempt("login", false)
+	AuthAttempt("login", false)
+
+	assert.Equal(t, success+1, testutil.ToFloat64(authAttempts.WithLabelValues("login", ResultSuccess)))
+	assert.Equal(t, failure+2, testutil.ToFloat64(authAttempts.WithLabelValues("login", ResultFailure)))
+}
+
+func TestHandler(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	SetBuildInfo("v1.2.3", "abc123")
+	SecretOperation("created")
+	RegisterDB("test", db)
+	RegisterDB("test", db)
+
+	body := scrape(t)
+	assert.Contains(t, body, `gophkeeper_build_info{commit="abc123",version="v1.2.3"} 1`)
+	assert.Contains(t, body, `gophkeeper_secrets_operations_total{operation="created"}`)
+	assert.Contains(t, body, `go_sql_open_connections{db_name="test"}`)
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func scrape(t *testing.T) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
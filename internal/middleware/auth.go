@@ -5,12 +5,17 @@ import (
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/shekshuev/gophkeeper/internal/logger"
 	"github.com/shekshuev/gophkeeper/internal/utils"
+	"go.uber.org/zap"
 )
 
 // RequestAuth — middleware, проверяющий наличие и валидность access-токена в заголовке Authorization.
-// Если токен валиден, добавляет claims в context.Context и передаёт управление следующему обработчику.
-func RequestAuth(secret string) func(http.Handler) http.Handler {
+// Если токен валиден, добавляет claims в context.Context, дополняет логгер запроса полем user_id
+// и передаёт управление следующему обработчику.
+// Токен, привязанный к сертификату устройства, принимается только от этого устройства (см. DeviceCertificate);
+// если requireDevice равен true, токены без такой привязки отклоняются.
+func RequestAuth(secret string, requireDevice bool) func(http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			tokenString, err := utils.GetRawAccessToken(r)
@@ -23,7 +28,12 @@ func RequestAuth(secret string) func(http.Handler) http.Handler {
 				w.WriteHeader(http.StatusUnauthorized)
 				return
 			}
+			if err := utils.CheckDevice(r.Context(), *claims, requireDevice); err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
 			ctx := utils.PutClaimsToContext(r.Context(), *claims)
+			ctx = logger.WithContext(ctx, zap.String("user_id", claims.Subject))
 			h.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -31,7 +41,8 @@ func RequestAuth(secret string) func(http.Handler) http.Handler {
 
 // RequestAuthSameID — middleware, проверяющий валидность токена и соответствие subject токена и ID в URL.
 // Используется, когда доступ к ресурсу должен быть ограничен только его владельцем.
-func RequestAuthSameID(secret string) func(http.Handler) http.Handler {
+// Привязка токена к устройству проверяется так же, как в RequestAuth.
+func RequestAuthSameID(secret string, requireDevice bool) func(http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			tokenString, err := utils.GetRawAccessToken(r)
@@ -44,6 +55,10 @@ func RequestAuthSameID(secret string) func(http.Handler) http.Handler {
 				w.WriteHeader(http.StatusUnauthorized)
 				return
 			}
+			if err := utils.CheckDevice(r.Context(), *claims, requireDevice); err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
 			strId := chi.URLParam(r, "user_id")
 			_, err = strconv.Atoi(strId)
 			if err != nil {
@@ -54,7 +69,7 @@ func RequestAuthSameID(secret string) func(http.Handler) http.Handler {
 				w.WriteHeader(http.StatusUnauthorized)
 				return
 			}
-			h.ServeHTTP(w, r)
+			h.ServeHTTP(w, r.WithContext(logger.WithContext(r.Context(), zap.String("user_id", claims.Subject))))
 		})
 	}
 }
@@ -30,7 +30,7 @@ func TestRequestAuth_Success(t *testing.T) {
 	req.Header.Set("Authorization", "Bearer "+token)
 	resp := httptest.NewRecorder()
 
-	mw := RequestAuth(secret)
+	mw := RequestAuth(secret, false)
 	mw(handler).ServeHTTP(resp, req)
 
 	assert.Equal(t, http.StatusOK, resp.Code)
@@ -42,7 +42,7 @@ func TestRequestAuth_InvalidToken(t *testing.T) {
 	req.Header.Set("Authorization", "Bearer invalid_token")
 	resp := httptest.NewRecorder()
 
-	mw := RequestAuth("secret")
+	mw := RequestAuth("secret", false)
 	mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		t.Fatal("handler should not be called")
 	})).ServeHTTP(resp, req)
@@ -64,7 +64,7 @@ func TestRequestAuthSameID_Success(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	called := false
-	mw := RequestAuthSameID(secret)
+	mw := RequestAuthSameID(secret, false)
 	mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		called = true
 		w.WriteHeader(http.StatusOK)
@@ -86,7 +86,7 @@ func TestRequestAuthSameID_Unauthorized(t *testing.T) {
 	req.Header.Set("Authorization", "Bearer "+token)
 	resp := httptest.NewRecorder()
 
-	mw := RequestAuthSameID(secret)
+	mw := RequestAuthSameID(secret, false)
 	mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		t.Fatal("handler should not be called")
 	})).ServeHTTP(resp, req)
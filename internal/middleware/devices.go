@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/utils"
+)
+
+// DeviceAuthenticator сопоставляет сертификат, предъявленный клиентом, с устройством (см. service.DeviceService).
+type DeviceAuthenticator interface {
+	Authenticate(ctx context.Context, cert *x509.Certificate) (*models.DeviceCertificateDTO, error)
+}
+
+// DeviceCertificate — middleware, сопоставляющий сертификат клиента из TLS-соединения с устройством.
+// Действующий сертификат добавляется в context.Context (см. utils.GetDeviceFromContext), а логгер запроса
+// дополняется полем device. Сам middleware запросы не отклоняет: неизвестный или отозванный сертификат
+// просто не попадает в контекст, и привязанные к нему токены отклонит RequestAuth.
+func DeviceCertificate(devices DeviceAuthenticator) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, r.WithContext(deviceContext(r.Context(), devices, r.TLS)))
+		})
+	}
+}
+
+// UnaryDevice — gRPC-перехватчик, аналог DeviceCertificate.
+func UnaryDevice(devices DeviceAuthenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(deviceContext(ctx, devices, peerTLSState(ctx)), req)
+	}
+}
+
+// StreamDevice — gRPC-перехватчик потоковых вызовов, аналог DeviceCertificate.
+func StreamDevice(devices DeviceAuthenticator) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := deviceContext(ss.Context(), devices, peerTLSState(ss.Context()))
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// deviceContext возвращает контекст с устройством, сертификат которого клиент предъявил в соединении state.
+// Если сертификата нет или он не принят, контекст возвращается без изменений.
+func deviceContext(ctx context.Context, devices DeviceAuthenticator, state *tls.ConnectionState) context.Context {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return ctx
+	}
+	device, err := devices.Authenticate(ctx, state.PeerCertificates[0])
+	if err != nil {
+		return ctx
+	}
+	ctx = logger.WithContext(ctx, zap.String("device", device.Device))
+	return utils.PutDeviceToContext(ctx, *device)
+}
+
+// peerTLSState возвращает состояние TLS-соединения gRPC-вызова или nil, если соединение без TLS.
+func peerTLSState(ctx context.Context) *tls.ConnectionState {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil
+	}
+	return &info.State
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/utils"
+)
+
+// testDevices принимает только сертификат с серийным номером 1.
+type testDevices struct{}
+
+func (testDevices) Authenticate(_ context.Context, cert *x509.Certificate) (*models.DeviceCertificateDTO, error) {
+	if cert.SerialNumber.Cmp(big.NewInt(1)) != 0 {
+		return nil, assert.AnError
+	}
+	return &models.DeviceCertificateDTO{UserID: 42, Device: "laptop", Thumbprint: "thumb"}, nil
+}
+
+// peerState возвращает состояние TLS-соединения, в котором клиент предъявил сертификат с серийным номером serial.
+func peerState(serial int64) *tls.ConnectionState {
+	return &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{SerialNumber: big.NewInt(serial)}}}
+}
+
+func TestDeviceCertificate(t *testing.T) {
+	secret := "secret"
+	bound, err := utils.CreateBoundToken(secret, "42", "thumb", time.Minute)
+	assert.NoError(t, err)
+	unbound, err := utils.CreateToken(secret, "42", time.Minute)
+	assert.NoError(t, err)
+
+	call := func(token string, state *tls.ConnectionState, requireDevice bool) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.TLS = state
+		resp := httptest.NewRecorder()
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			device, ok := utils.GetDeviceFromContext(r.Context())
+			assert.True(t, ok)
+			assert.Equal(t, "laptop", device.Device)
+		})
+		DeviceCertificate(testDevices{})(RequestAuth(secret, requireDevice)(handler)).ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	assert.Equal(t, http.StatusOK, call(bound, peerState(1), true))
+	assert.Equal(t, http.StatusUnauthorized, call(bound, nil, false), "bound token without certificate")
+	assert.Equal(t, http.StatusUnauthorized, call(bound, peerState(2), false), "bound token with unknown certificate")
+	assert.Equal(t, http.StatusUnauthorized, call(unbound, peerState(1), true), "unbound token when certificate is required")
+}
+
+func TestUnaryDevice(t *testing.T) {
+	secret := "secret"
+	bound, err := utils.CreateBoundToken(secret, "42", "thumb", time.Minute)
+	assert.NoError(t, err)
+
+	interceptors := []grpc.UnaryServerInterceptor{UnaryDevice(testDevices{}), UnaryAuth(secret, true)}
+	call := func(state *tls.ConnectionState) error {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+bound))
+		if state != nil {
+			ctx = peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: *state}})
+		}
+		info := &grpc.UnaryServerInfo{FullMethod: "/gophkeeper.v1.SecretService/GetSecret"}
+		_, err := interceptors[0](ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+			return interceptors[1](ctx, req, info, func(ctx context.Context, _ any) (any, error) {
+				device, ok := utils.GetDeviceFromContext(ctx)
+				assert.True(t, ok)
+				return device.Device, nil
+			})
+		})
+		return err
+	}
+
+	assert.NoError(t, call(peerState(1)))
+	assert.Equal(t, codes.Unauthenticated, status.Code(call(nil)))
+	assert.Equal(t, codes.Unauthenticated, status.Code(call(peerState(2))))
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/utils"
+)
+
+// UnaryAuth — gRPC-перехватчик, аналог RequestAuth: проверяет access-токен из метаданных
+// "authorization: Bearer <token>" и добавляет claims в контекст вызова.
+// Методы, имена которых начинаются с одного из префиксов public (например, "/gophkeeper.v1.AuthService/"),
+// вызываются без проверки. Привязка токена к сертификату устройства проверяется так же, как в RequestAuth.
+func UnaryAuth(secret string, requireDevice bool, public ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if isPublicMethod(info.FullMethod, public) {
+			return handler(ctx, req)
+		}
+		ctx, err := authContext(ctx, secret, requireDevice)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuth — gRPC-перехватчик потоковых вызовов, проверяющий access-токен так же, как UnaryAuth.
+func StreamAuth(secret string, requireDevice bool, public ...string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if isPublicMethod(info.FullMethod, public) {
+			return handler(srv, ss)
+		}
+		ctx, err := authContext(ss.Context(), secret, requireDevice)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authContext проверяет токен из метаданных вызова и возвращает контекст с claims
+// и логгером, дополненным полем user_id.
+// Если токена нет, он невалиден или предъявлен не тем устройством, возвращает ошибку с кодом Unauthenticated.
+func authContext(ctx context.Context, secret string, requireDevice bool) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || !strings.HasPrefix(values[0], "Bearer ") {
+		return nil, status.Error(codes.Unauthenticated, utils.ErrTokenInvalid.Error())
+	}
+	claims, err := utils.GetToken(strings.TrimPrefix(values[0], "Bearer "), secret)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err := utils.CheckDevice(ctx, *claims, requireDevice); err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	ctx = logger.WithContext(ctx, zap.String("user_id", claims.Subject))
+	return utils.PutClaimsToContext(ctx, *claims), nil
+}
+
+// isPublicMethod проверяет, относится ли метод к вызовам без авторизации.
+func isPublicMethod(method string, public []string) bool {
+	for _, prefix := range public {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// contextServerStream подменяет контекст потока (на контекст с claims или со спаном вызова).
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context возвращает контекст потока с claims.
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/shekshuev/gophkeeper/internal/utils"
+)
+
+func TestUnaryAuth(t *testing.T) {
+	secret := "secret"
+	token, err := utils.CreateToken(secret, "42", time.Minute)
+	assert.NoError(t, err)
+
+	interceptor := UnaryAuth(secret, false, "/gophkeeper.v1.AuthService/")
+	handler := func(ctx context.Context, req any) (any, error) {
+		claims, ok := utils.GetClaimsFromContext(ctx)
+		if !ok {
+			return "anonymous", nil
+		}
+		return claims.Subject, nil
+	}
+	call := func(ctx context.Context, method string) (any, error) {
+		return interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	}
+	withToken := func(value string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", value))
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		resp, err := call(withToken("Bearer "+token), "/gophkeeper.v1.SecretService/GetSecret")
+		assert.NoError(t, err)
+		assert.Equal(t, "42", resp)
+	})
+
+	t.Run("Public_method", func(t *testing.T) {
+		resp, err := call(context.Background(), "/gophkeeper.v1.AuthService/Login")
+		assert.NoError(t, err)
+		assert.Equal(t, "anonymous", resp)
+	})
+
+	t.Run("Missing_token", func(t *testing.T) {
+		_, err := call(context.Background(), "/gophkeeper.v1.SecretService/GetSecret")
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Invalid_token", func(t *testing.T) {
+		_, err := call(withToken("Bearer invalid_token"), "/gophkeeper.v1.SecretService/GetSecret")
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamAuth(t *testing.T) {
+	secret := "secret"
+	token, err := utils.CreateToken(secret, "42", time.Minute)
+	assert.NoError(t, err)
+
+	interceptor := StreamAuth(secret, false)
+	info := &grpc.StreamServerInfo{FullMethod: "/gophkeeper.v1.SecretService/WatchSecrets"}
+
+	t.Run("Success", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+		err := interceptor(nil, &testServerStream{ctx: ctx}, info, func(srv any, ss grpc.ServerStream) error {
+			claims, ok := utils.GetClaimsFromContext(ss.Context())
+			assert.True(t, ok)
+			assert.Equal(t, "42", claims.Subject)
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Missing_token", func(t *testing.T) {
+		err := interceptor(nil, &testServerStream{ctx: context.Background()}, info, func(srv any, ss grpc.ServerStream) error {
+			t.Fatal("handler should not be called")
+			return nil
+		})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
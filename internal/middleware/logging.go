@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+
+	"github.com/shekshuev/gophkeeper/internal/logger"
+)
+
+// RequestLogger — middleware, кладущий в контекст логгер запроса с полями request_id, method,
+// route и trace_id. Middleware авторизации дополняет его полем user_id. Подключается после
+// RequestID и Tracing, чтобы ID запроса и трассы уже были в контексте.
+func RequestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log := logger.NewLogger().For(r.Context())
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			log = log.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+				return routeCore{Core: core, rctx: rctx}
+			}))
+		}
+		fields := []zap.Field{
+			zap.String("request_id", chiMiddleware.GetReqID(r.Context())),
+			zap.String("method", r.Method),
+		}
+		log = log.With(append(fields, traceFields(r.Context())...)...)
+		next.ServeHTTP(w, r.WithContext(logger.NewContext(r.Context(), log)))
+	})
+}
+
+// UnaryLogging — gRPC-перехватчик, кладущий в контекст логгер вызова с полями grpc_method и trace_id.
+func UnaryLogging() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(rpcLoggerContext(ctx, info.FullMethod), req)
+	}
+}
+
+// StreamLogging — gRPC-перехватчик потоковых вызовов, аналог UnaryLogging.
+func StreamLogging() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: rpcLoggerContext(ss.Context(), info.FullMethod)})
+	}
+}
+
+// rpcLoggerContext возвращает контекст с логгером gRPC-вызова method.
+func rpcLoggerContext(ctx context.Context, method string) context.Context {
+	return logger.WithContext(ctx, append([]zap.Field{zap.String("grpc_method", method)}, traceFields(ctx)...)...)
+}
+
+// traceFields возвращает поле trace_id, если в контексте есть спан.
+func traceFields(ctx context.Context) []zap.Field {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.HasTraceID() {
+		return nil
+	}
+	return []zap.Field{zap.String("trace_id", spanCtx.TraceID().String())}
+}
+
+// routeCore добавляет к каждой записи поле route — шаблон маршрута chi. Шаблон становится известен
+// только после маршрутизации, поэтому он читается в момент записи, а не при создании логгера.
+type routeCore struct {
+	zapcore.Core
+	rctx *chi.Context
+}
+
+// With возвращает ядро с дополнительными полями, сохраняя добавление маршрута.
+func (c routeCore) With(fields []zapcore.Field) zapcore.Core {
+	return routeCore{Core: c.Core.With(fields), rctx: c.rctx}
+}
+
+// Check регистрирует routeCore для записи, если уровень записи включён.
+func (c routeCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+// Write дописывает к записи шаблон маршрута.
+func (c routeCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, append(fields, zap.String("route", c.rctx.RoutePattern())))
+}
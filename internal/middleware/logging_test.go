@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/utils"
+)
+
+// observeLogs подменяет общий логгер на логгер, сохраняющий записи в памяти, до конца теста.
+func observeLogs(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+	core, logs := observer.New(zap.InfoLevel)
+	l := logger.NewLogger()
+	original := l.Log
+	l.Log = zap.New(core)
+	t.Cleanup(func() { l.Log = original })
+	return logs
+}
+
+func TestRequestLogger(t *testing.T) {
+	logs := observeLogs(t)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	token, err := utils.CreateToken("secret", "42", time.Minute)
+	assert.NoError(t, err)
+
+	router := chi.NewRouter()
+	router.Use(chiMiddleware.RequestID, Tracing, RequestLogger)
+	router.Route("/v1.0/items", func(r chi.Router) {
+		r.Use(RequestAuth("secret", false))
+		r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+			logger.NewLogger().For(r.Context()).Info("item requested")
+		})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1.0/items/7", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("traceparent", testTraceParent)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if assert.Equal(t, 1, logs.Len()) {
+		fields := logs.All()[0].ContextMap()
+		assert.NotEmpty(t, fields["request_id"])
+		assert.Equal(t, http.MethodGet, fields["method"])
+		assert.Equal(t, "/v1.0/items/{id}", fields["route"])
+		assert.Equal(t, "42", fields["user_id"])
+		assert.Equal(t, testTraceID, fields["trace_id"])
+	}
+}
+
+func TestUnaryLogging(t *testing.T) {
+	logs := observeLogs(t)
+	token, err := utils.CreateToken("secret", "42", time.Minute)
+	assert.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	info := &grpc.UnaryServerInfo{FullMethod: "/gophkeeper.v1.SecretService/Get"}
+	_, err = UnaryLogging()(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+		return UnaryAuth("secret", false)(ctx, req, info, func(ctx context.Context, _ any) (any, error) {
+			logger.NewLogger().For(ctx).Info("secret requested")
+			return nil, nil
+		})
+	})
+	assert.NoError(t, err)
+
+	if assert.Equal(t, 1, logs.Len()) {
+		fields := logs.All()[0].ContextMap()
+		assert.Equal(t, info.FullMethod, fields["grpc_method"])
+		assert.Equal(t, "42", fields["user_id"])
+	}
+}
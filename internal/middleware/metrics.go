@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/shekshuev/gophkeeper/internal/metrics"
+)
+
+// unmatchedRoute — метка маршрута для запросов, не попавших ни в один зарегистрированный маршрут.
+const unmatchedRoute = "unmatched"
+
+// otherMethod — метка метода для запросов с нестандартным методом.
+const otherMethod = "other"
+
+// knownMethods — стандартные методы HTTP, которые попадают в метку method как есть.
+// Метод задаёт клиент, поэтому остальные значения сводятся к otherMethod: иначе любой запрос
+// мог бы создать новый временной ряд.
+var knownMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// Metrics — middleware, учитывающий число и длительность HTTP-запросов в метриках Prometheus.
+// В метку route попадает шаблон маршрута chi (например, /v1.0/secrets/{id}), а не фактический путь,
+// в метку method — стандартный метод HTTP или otherMethod.
+// Должен подключаться к корневому роутеру: шаблон маршрута известен только после обработки запроса.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		method := r.Method
+		if !knownMethods[method] {
+			method = otherMethod
+		}
+		metrics.ObserveHTTPRequest(method, route, status, time.Since(start))
+	})
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shekshuev/gophkeeper/internal/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(Metrics)
+	router.Get("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	for _, path := range []string{"/items/1", "/items/2", "/missing"} {
+		resp, err := http.Get(server.URL + path)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	assert.Contains(t, body, `gophkeeper_http_requests_total{method="GET",route="/items/{id}",status="418"} 2`)
+	assert.Contains(t, body, `gophkeeper_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `gophkeeper_http_request_duration_seconds_count{method="GET",route="/items/{id}",status="418"} 2`)
+}
+
+func TestMetrics_UnknownMethod(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(Metrics)
+	router.Get("/methods", func(w http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	for _, method := range []string{"BREW", "PROPFIND", http.MethodGet} {
+		req, err := http.NewRequest(method, server.URL+"/methods", nil)
+		assert.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	assert.Contains(t, body, `gophkeeper_http_requests_total{method="other",route="unmatched",status="405"} 2`)
+	assert.Contains(t, body, `gophkeeper_http_requests_total{method="GET",route="/methods",status="200"} 1`)
+	assert.NotContains(t, body, `method="BREW"`)
+}
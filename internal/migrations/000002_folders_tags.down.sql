@@ -0,0 +1,7 @@
+drop index if exists idx__secrets__tags;
+drop index if exists idx__secrets__user_id__folder_id;
+alter table secrets drop constraint if exists fk__secrets__folder;
+alter table secrets drop column if exists tags;
+alter table secrets drop column if exists folder_id;
+drop index if exists idx__folders__user_id__parent_id__name;
+drop table if exists folders;
\ No newline at end of file
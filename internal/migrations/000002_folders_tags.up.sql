@@ -0,0 +1,20 @@
+create table if not exists folders (
+    id bigserial,
+    user_id bigint not null,
+    parent_id bigint,
+    name varchar(100) not null,
+    created_at timestamp not null default now(),
+    updated_at timestamp not null default now(),
+    constraint pk__folders primary key(id),
+    constraint fk__folders__user foreign key(user_id) references users(id) on delete cascade,
+    constraint fk__folders__parent foreign key(parent_id) references folders(id) on delete cascade
+);
+
+create unique index idx__folders__user_id__parent_id__name on folders(user_id, coalesce(parent_id, 0), name);
+
+alter table secrets add column if not exists folder_id bigint;
+alter table secrets add column if not exists tags jsonb not null default '[]';
+alter table secrets add constraint fk__secrets__folder foreign key(folder_id) references folders(id) on delete set null;
+
+create index idx__secrets__user_id__folder_id on secrets(user_id, folder_id);
+create index idx__secrets__tags on secrets using gin(tags);
\ No newline at end of file
@@ -0,0 +1,6 @@
+drop index if exists idx__secrets__title__trgm;
+drop index if exists idx__secrets__user_id__type;
+drop index if exists idx__secrets__user_id__title;
+drop index if exists idx__secrets__user_id__updated_at;
+drop index if exists idx__secrets__user_id__created_at;
+alter table secrets drop column if exists type;
\ No newline at end of file
@@ -0,0 +1,16 @@
+create extension if not exists pg_trgm;
+
+alter table secrets add column if not exists type varchar(16) generated always as (
+    case
+        when data ? 'login_password' then 'login'
+        when data ? 'card' then 'card'
+        when data ? 'text' then 'text'
+        when data ? 'binary' then 'binary'
+    end
+) stored;
+
+create index idx__secrets__user_id__created_at on secrets(user_id, created_at, id);
+create index idx__secrets__user_id__updated_at on secrets(user_id, updated_at, id);
+create index idx__secrets__user_id__title on secrets(user_id, title, id);
+create index idx__secrets__user_id__type on secrets(user_id, type);
+create index idx__secrets__title__trgm on secrets using gin(title gin_trgm_ops);
\ No newline at end of file
@@ -0,0 +1,3 @@
+drop index if exists idx__secret_audit__user_id__secret_id;
+drop table if exists secret_audit;
+alter table secrets drop column if exists version;
\ No newline at end of file
@@ -0,0 +1,15 @@
+alter table secrets add column if not exists version integer not null default 1;
+
+create table if not exists secret_audit (
+    id bigserial,
+    user_id bigint not null,
+    secret_id bigint not null,
+    action varchar(16) not null,
+    ip varchar(64) not null default '',
+    user_agent varchar(255) not null default '',
+    created_at timestamp not null default now(),
+    constraint pk__secret_audit primary key(id),
+    constraint fk__secret_audit__user foreign key(user_id) references users(id) on delete cascade
+);
+
+create index idx__secret_audit__user_id__secret_id on secret_audit(user_id, secret_id, created_at);
\ No newline at end of file
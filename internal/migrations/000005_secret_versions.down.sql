@@ -0,0 +1,3 @@
+drop index if exists idx__secret_versions__secret_id__version;
+drop table if exists secret_versions;
+alter table secrets drop column if exists device;
\ No newline at end of file
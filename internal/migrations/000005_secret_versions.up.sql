@@ -0,0 +1,28 @@
+alter table secrets add column if not exists device varchar(100) not null default '';
+
+create table if not exists secret_versions (
+    id bigserial,
+    secret_id bigint not null,
+    user_id bigint not null,
+    version integer not null,
+    title varchar(100) not null,
+    data jsonb not null,
+    folder_id bigint,
+    tags jsonb not null default '[]',
+    device varchar(100) not null default '',
+    type varchar(16) generated always as (
+        case
+            when data ? 'login_password' then 'login'
+            when data ? 'card' then 'card'
+            when data ? 'text' then 'text'
+            when data ? 'binary' then 'binary'
+        end
+    ) stored,
+    created_at timestamp not null,
+    archived_at timestamp not null default now(),
+    constraint pk__secret_versions primary key(id),
+    constraint fk__secret_versions__secret foreign key(secret_id) references secrets(id) on delete cascade,
+    constraint fk__secret_versions__folder foreign key(folder_id) references folders(id) on delete set null
+);
+
+create unique index idx__secret_versions__secret_id__version on secret_versions(secret_id, version);
\ No newline at end of file
@@ -0,0 +1,2 @@
+drop index if exists idx__secrets__deleted_at;
+alter table secrets drop column if exists deleted_at;
\ No newline at end of file
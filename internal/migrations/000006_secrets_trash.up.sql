@@ -0,0 +1,3 @@
+alter table secrets add column if not exists deleted_at timestamp;
+
+create index if not exists idx__secrets__deleted_at on secrets(deleted_at) where (deleted_at is not null);
\ No newline at end of file
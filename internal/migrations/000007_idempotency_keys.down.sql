@@ -0,0 +1,2 @@
+drop index if exists idx__idempotency_keys__created_at;
+drop table if exists idempotency_keys;
\ No newline at end of file
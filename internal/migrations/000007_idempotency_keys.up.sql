@@ -0,0 +1,12 @@
+create table if not exists idempotency_keys (
+    user_id bigint not null,
+    key varchar(255) not null,
+    request_hash varchar(64) not null,
+    status_code integer,
+    response bytea,
+    created_at timestamp not null default now(),
+    constraint pk__idempotency_keys primary key(user_id, key),
+    constraint fk__idempotency_keys__user foreign key(user_id) references users(id) on delete cascade
+);
+
+create index idx__idempotency_keys__created_at on idempotency_keys(created_at);
\ No newline at end of file
@@ -0,0 +1,6 @@
+drop table if exists login_attempts;
+drop index if exists idx__webhook_deliveries__pending;
+drop index if exists idx__webhook_deliveries__webhook_id;
+drop table if exists webhook_deliveries;
+drop index if exists idx__webhooks__user_id;
+drop table if exists webhooks;
\ No newline at end of file
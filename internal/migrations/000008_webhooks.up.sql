@@ -0,0 +1,44 @@
+create table if not exists webhooks (
+    id bigserial,
+    user_id bigint not null,
+    url varchar(2048) not null,
+    secret varchar(64) not null,
+    events jsonb not null default '[]',
+    created_at timestamp not null default now(),
+    constraint pk__webhooks primary key(id),
+    constraint fk__webhooks__user foreign key(user_id) references users(id) on delete cascade
+);
+
+create index idx__webhooks__user_id on webhooks(user_id);
+
+create table if not exists webhook_deliveries (
+    id bigserial,
+    webhook_id bigint not null,
+    event varchar(64) not null,
+    payload jsonb not null,
+    status varchar(16) not null default 'pending',
+    attempts integer not null default 0,
+    response_status integer,
+    error varchar(255) not null default '',
+    next_attempt_at timestamp not null default now(),
+    created_at timestamp not null default now(),
+    delivered_at timestamp,
+    constraint pk__webhook_deliveries primary key(id),
+    constraint fk__webhook_deliveries__webhook foreign key(webhook_id) references webhooks(id) on delete cascade
+);
+
+create index idx__webhook_deliveries__webhook_id on webhook_deliveries(webhook_id, created_at);
+create index idx__webhook_deliveries__pending on webhook_deliveries(next_attempt_at) where status = 'pending';
+
+create table if not exists login_attempts (
+    id bigserial,
+    user_id bigint not null,
+    success boolean not null,
+    device varchar(100) not null default '',
+    ip varchar(64) not null default '',
+    created_at timestamp not null default now(),
+    constraint pk__login_attempts primary key(id),
+    constraint fk__login_attempts__user foreign key(user_id) references users(id) on delete cascade
+);
+
+create index idx__login_attempts__user_id on login_attempts(user_id, success, created_at);
\ No newline at end of file
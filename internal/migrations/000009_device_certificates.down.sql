@@ -0,0 +1,3 @@
+drop index if exists idx__device_certificates__revoked;
+drop index if exists idx__device_certificates__user_id;
+drop table if exists device_certificates;
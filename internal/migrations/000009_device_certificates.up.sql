@@ -0,0 +1,16 @@
+create table if not exists device_certificates (
+    id bigserial,
+    user_id bigint not null,
+    device varchar(100) not null default '',
+    serial varchar(40) not null,
+    thumbprint varchar(64) not null,
+    created_at timestamp not null default now(),
+    expires_at timestamp not null,
+    revoked_at timestamp,
+    constraint pk__device_certificates primary key(id),
+    constraint uq__device_certificates__serial unique(serial),
+    constraint fk__device_certificates__user foreign key(user_id) references users(id) on delete cascade
+);
+
+create index idx__device_certificates__user_id on device_certificates(user_id, created_at);
+create index idx__device_certificates__revoked on device_certificates(expires_at) where revoked_at is not null;
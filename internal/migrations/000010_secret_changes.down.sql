@@ -0,0 +1,3 @@
+drop index if exists idx__secret_changes__created_at;
+drop index if exists idx__secret_changes__user_id;
+drop table if exists secret_changes;
@@ -0,0 +1,13 @@
+create table if not exists secret_changes (
+    id bigserial,
+    user_id bigint not null,
+    secret_id bigint not null,
+    type varchar(16) not null,
+    version integer not null default 0,
+    created_at timestamp not null default now(),
+    constraint pk__secret_changes primary key(id),
+    constraint fk__secret_changes__user foreign key(user_id) references users(id) on delete cascade
+);
+
+create index idx__secret_changes__user_id on secret_changes(user_id, id);
+create index idx__secret_changes__created_at on secret_changes(created_at);
@@ -0,0 +1,10 @@
+// Package migrations содержит миграции схемы базы данных. Миграции PostgreSQL применяются
+// внешней утилитой golang-migrate, миграции SQLite встроены в сервер (см. database.MigrateSQLite).
+package migrations
+
+import "embed"
+
+// SQLite — миграции схемы SQLite (каталог sqlite) в формате golang-migrate.
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
@@ -0,0 +1,9 @@
+drop table if exists login_attempts;
+drop table if exists webhook_deliveries;
+drop table if exists webhooks;
+drop table if exists idempotency_keys;
+drop table if exists secret_audit;
+drop table if exists secret_versions;
+drop table if exists secrets;
+drop table if exists folders;
+drop table if exists users;
@@ -0,0 +1,152 @@
+create table if not exists users (
+    id integer primary key autoincrement,
+    user_name varchar(30) not null,
+    first_name varchar(30) not null,
+    last_name varchar(30) not null,
+    password_hash varchar(72) not null,
+    status smallint default 1,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    deleted_at timestamp,
+    constraint chk__users__status check(status in (0, 1))
+);
+
+create unique index idx__users__user_name on users(user_name) where (deleted_at is null);
+
+create table if not exists folders (
+    id integer primary key autoincrement,
+    user_id bigint not null,
+    parent_id bigint,
+    name varchar(100) not null,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    constraint fk__folders__user foreign key(user_id) references users(id) on delete cascade,
+    constraint fk__folders__parent foreign key(parent_id) references folders(id) on delete cascade
+);
+
+create unique index idx__folders__user_id__parent_id__name on folders(user_id, coalesce(parent_id, 0), name);
+
+create table if not exists secrets (
+    id integer primary key autoincrement,
+    user_id bigint not null,
+    title varchar(100) not null,
+    data text not null,
+    folder_id bigint,
+    tags text not null default '[]',
+    version integer not null default 1,
+    device varchar(100) not null default '',
+    type varchar(16) generated always as (
+        case
+            when json_type(data, '$.login_password') is not null then 'login'
+            when json_type(data, '$.card') is not null then 'card'
+            when json_type(data, '$.text') is not null then 'text'
+            when json_type(data, '$.binary') is not null then 'binary'
+        end
+    ) stored,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    deleted_at timestamp,
+    constraint fk__secrets__user foreign key(user_id) references users(id) on delete cascade,
+    constraint fk__secrets__folder foreign key(folder_id) references folders(id) on delete set null
+);
+
+create index idx__secrets__user_id__folder_id on secrets(user_id, folder_id);
+create index idx__secrets__user_id__created_at on secrets(user_id, created_at, id);
+create index idx__secrets__user_id__updated_at on secrets(user_id, updated_at, id);
+create index idx__secrets__user_id__title on secrets(user_id, title, id);
+create index idx__secrets__user_id__type on secrets(user_id, type);
+create index idx__secrets__deleted_at on secrets(deleted_at) where (deleted_at is not null);
+
+create table if not exists secret_versions (
+    id integer primary key autoincrement,
+    secret_id bigint not null,
+    user_id bigint not null,
+    version integer not null,
+    title varchar(100) not null,
+    data text not null,
+    folder_id bigint,
+    tags text not null default '[]',
+    device varchar(100) not null default '',
+    type varchar(16) generated always as (
+        case
+            when json_type(data, '$.login_password') is not null then 'login'
+            when json_type(data, '$.card') is not null then 'card'
+            when json_type(data, '$.text') is not null then 'text'
+            when json_type(data, '$.binary') is not null then 'binary'
+        end
+    ) stored,
+    created_at timestamp not null,
+    archived_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    constraint fk__secret_versions__secret foreign key(secret_id) references secrets(id) on delete cascade,
+    constraint fk__secret_versions__folder foreign key(folder_id) references folders(id) on delete set null
+);
+
+create unique index idx__secret_versions__secret_id__version on secret_versions(secret_id, version);
+
+create table if not exists secret_audit (
+    id integer primary key autoincrement,
+    user_id bigint not null,
+    secret_id bigint not null,
+    action varchar(16) not null,
+    ip varchar(64) not null default '',
+    user_agent varchar(255) not null default '',
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    constraint fk__secret_audit__user foreign key(user_id) references users(id) on delete cascade
+);
+
+create index idx__secret_audit__user_id__secret_id on secret_audit(user_id, secret_id, created_at);
+
+create table if not exists idempotency_keys (
+    user_id bigint not null,
+    key varchar(255) not null,
+    request_hash varchar(64) not null,
+    status_code integer,
+    response blob,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    constraint pk__idempotency_keys primary key(user_id, key),
+    constraint fk__idempotency_keys__user foreign key(user_id) references users(id) on delete cascade
+);
+
+create index idx__idempotency_keys__created_at on idempotency_keys(created_at);
+
+create table if not exists webhooks (
+    id integer primary key autoincrement,
+    user_id bigint not null,
+    url varchar(2048) not null,
+    secret varchar(64) not null,
+    events text not null default '[]',
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    constraint fk__webhooks__user foreign key(user_id) references users(id) on delete cascade
+);
+
+create index idx__webhooks__user_id on webhooks(user_id);
+
+create table if not exists webhook_deliveries (
+    id integer primary key autoincrement,
+    webhook_id bigint not null,
+    event varchar(64) not null,
+    payload text not null,
+    status varchar(16) not null default 'pending',
+    attempts integer not null default 0,
+    response_status integer,
+    error varchar(255) not null default '',
+    next_attempt_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    delivered_at timestamp,
+    constraint fk__webhook_deliveries__webhook foreign key(webhook_id) references webhooks(id) on delete cascade
+);
+
+create index idx__webhook_deliveries__webhook_id on webhook_deliveries(webhook_id, created_at);
+create index idx__webhook_deliveries__pending on webhook_deliveries(next_attempt_at) where status = 'pending';
+
+create table if not exists login_attempts (
+    id integer primary key autoincrement,
+    user_id bigint not null,
+    success boolean not null,
+    device varchar(100) not null default '',
+    ip varchar(64) not null default '',
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    constraint fk__login_attempts__user foreign key(user_id) references users(id) on delete cascade
+);
+
+create index idx__login_attempts__user_id on login_attempts(user_id, success, created_at);
@@ -0,0 +1,15 @@
+create table if not exists device_certificates (
+    id integer primary key autoincrement,
+    user_id bigint not null,
+    device varchar(100) not null default '',
+    serial varchar(40) not null,
+    thumbprint varchar(64) not null,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    expires_at timestamp not null,
+    revoked_at timestamp,
+    constraint uq__device_certificates__serial unique(serial),
+    constraint fk__device_certificates__user foreign key(user_id) references users(id) on delete cascade
+);
+
+create index idx__device_certificates__user_id on device_certificates(user_id, created_at);
+create index idx__device_certificates__revoked on device_certificates(expires_at) where revoked_at is not null;
@@ -10,11 +10,49 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/shekshuev/gophkeeper/internal/models"
 )
 
+// MockTxManager is a mock of TxManager interface.
+type MockTxManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxManagerMockRecorder
+}
+
+// MockTxManagerMockRecorder is the mock recorder for MockTxManager.
+type MockTxManagerMockRecorder struct {
+	mock *MockTxManager
+}
+
+// NewMockTxManager creates a new mock instance.
+func NewMockTxManager(ctrl *gomock.Controller) *MockTxManager {
+	mock := &MockTxManager{ctrl: ctrl}
+	mock.recorder = &MockTxManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTxManager) EXPECT() *MockTxManagerMockRecorder {
+	return m.recorder
+}
+
+// WithinTx mocks base method.
+func (m *MockTxManager) WithinTx(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTx indicates an expected call of WithinTx.
+func (mr *MockTxManagerMockRecorder) WithinTx(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTx", reflect.TypeOf((*MockTxManager)(nil).WithinTx), ctx, fn)
+}
+
 // MockUserRepository is a mock of UserRepository interface.
 type MockUserRepository struct {
 	ctrl     *gomock.Controller
@@ -106,6 +144,21 @@ func (m *MockSecretRepository) EXPECT() *MockSecretRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ApplyBatch mocks base method.
+func (m *MockSecretRepository) ApplyBatch(ctx context.Context, userID uint64, ops []models.BatchOperationDTO, device string, atomic bool) ([]models.BatchOutcome, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyBatch", ctx, userID, ops, device, atomic)
+	ret0, _ := ret[0].([]models.BatchOutcome)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyBatch indicates an expected call of ApplyBatch.
+func (mr *MockSecretRepositoryMockRecorder) ApplyBatch(ctx, userID, ops, device, atomic interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyBatch", reflect.TypeOf((*MockSecretRepository)(nil).ApplyBatch), ctx, userID, ops, device, atomic)
+}
+
 // Create mocks base method.
 func (m *MockSecretRepository) Create(ctx context.Context, dto models.CreateSecretDTO) (uint64, error) {
 	m.ctrl.T.Helper()
@@ -121,33 +174,48 @@ func (mr *MockSecretRepositoryMockRecorder) Create(ctx, dto interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSecretRepository)(nil).Create), ctx, dto)
 }
 
+// CreateChange mocks base method.
+func (m *MockSecretRepository) CreateChange(ctx context.Context, event models.SecretEventDTO) (*models.SecretEventDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateChange", ctx, event)
+	ret0, _ := ret[0].(*models.SecretEventDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateChange indicates an expected call of CreateChange.
+func (mr *MockSecretRepositoryMockRecorder) CreateChange(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChange", reflect.TypeOf((*MockSecretRepository)(nil).CreateChange), ctx, event)
+}
+
 // DeleteByID mocks base method.
-func (m *MockSecretRepository) DeleteByID(ctx context.Context, id uint64) error {
+func (m *MockSecretRepository) DeleteByID(ctx context.Context, userID, id uint64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteByID", ctx, id)
+	ret := m.ctrl.Call(m, "DeleteByID", ctx, userID, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteByID indicates an expected call of DeleteByID.
-func (mr *MockSecretRepositoryMockRecorder) DeleteByID(ctx, id interface{}) *gomock.Call {
+func (mr *MockSecretRepositoryMockRecorder) DeleteByID(ctx, userID, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockSecretRepository)(nil).DeleteByID), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockSecretRepository)(nil).DeleteByID), ctx, userID, id)
 }
 
 // GetAllByUser mocks base method.
-func (m *MockSecretRepository) GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadSecretDTO, error) {
+func (m *MockSecretRepository) GetAllByUser(ctx context.Context, userID uint64, filter models.SecretFilterDTO) ([]models.ReadSecretDTO, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllByUser", ctx, userID)
+	ret := m.ctrl.Call(m, "GetAllByUser", ctx, userID, filter)
 	ret0, _ := ret[0].([]models.ReadSecretDTO)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllByUser indicates an expected call of GetAllByUser.
-func (mr *MockSecretRepositoryMockRecorder) GetAllByUser(ctx, userID interface{}) *gomock.Call {
+func (mr *MockSecretRepositoryMockRecorder) GetAllByUser(ctx, userID, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUser", reflect.TypeOf((*MockSecretRepository)(nil).GetAllByUser), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUser", reflect.TypeOf((*MockSecretRepository)(nil).GetAllByUser), ctx, userID, filter)
 }
 
 // GetByID mocks base method.
@@ -164,3 +232,742 @@ func (mr *MockSecretRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockSecretRepository)(nil).GetByID), ctx, id)
 }
+
+// GetChangeRange mocks base method.
+func (m *MockSecretRepository) GetChangeRange(ctx context.Context, userID uint64) (uint64, uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChangeRange", ctx, userID)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(uint64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetChangeRange indicates an expected call of GetChangeRange.
+func (mr *MockSecretRepositoryMockRecorder) GetChangeRange(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChangeRange", reflect.TypeOf((*MockSecretRepository)(nil).GetChangeRange), ctx, userID)
+}
+
+// GetChanges mocks base method.
+func (m *MockSecretRepository) GetChanges(ctx context.Context, userID uint64, filter models.SecretChangeFilterDTO) ([]models.SecretEventDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChanges", ctx, userID, filter)
+	ret0, _ := ret[0].([]models.SecretEventDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChanges indicates an expected call of GetChanges.
+func (mr *MockSecretRepositoryMockRecorder) GetChanges(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChanges", reflect.TypeOf((*MockSecretRepository)(nil).GetChanges), ctx, userID, filter)
+}
+
+// GetSummariesByUser mocks base method.
+func (m *MockSecretRepository) GetSummariesByUser(ctx context.Context, userID uint64, filter models.SecretFilterDTO) ([]models.SecretSummaryDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSummariesByUser", ctx, userID, filter)
+	ret0, _ := ret[0].([]models.SecretSummaryDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSummariesByUser indicates an expected call of GetSummariesByUser.
+func (mr *MockSecretRepositoryMockRecorder) GetSummariesByUser(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSummariesByUser", reflect.TypeOf((*MockSecretRepository)(nil).GetSummariesByUser), ctx, userID, filter)
+}
+
+// GetTrashByUser mocks base method.
+func (m *MockSecretRepository) GetTrashByUser(ctx context.Context, userID uint64) ([]models.TrashedSecretDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrashByUser", ctx, userID)
+	ret0, _ := ret[0].([]models.TrashedSecretDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrashByUser indicates an expected call of GetTrashByUser.
+func (mr *MockSecretRepositoryMockRecorder) GetTrashByUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrashByUser", reflect.TypeOf((*MockSecretRepository)(nil).GetTrashByUser), ctx, userID)
+}
+
+// GetVersion mocks base method.
+func (m *MockSecretRepository) GetVersion(ctx context.Context, userID, secretID uint64, version int) (*models.ReadSecretVersionDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersion", ctx, userID, secretID, version)
+	ret0, _ := ret[0].(*models.ReadSecretVersionDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersion indicates an expected call of GetVersion.
+func (mr *MockSecretRepositoryMockRecorder) GetVersion(ctx, userID, secretID, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersion", reflect.TypeOf((*MockSecretRepository)(nil).GetVersion), ctx, userID, secretID, version)
+}
+
+// GetVersions mocks base method.
+func (m *MockSecretRepository) GetVersions(ctx context.Context, userID, secretID uint64) ([]models.SecretVersionDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersions", ctx, userID, secretID)
+	ret0, _ := ret[0].([]models.SecretVersionDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersions indicates an expected call of GetVersions.
+func (mr *MockSecretRepositoryMockRecorder) GetVersions(ctx, userID, secretID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersions", reflect.TypeOf((*MockSecretRepository)(nil).GetVersions), ctx, userID, secretID)
+}
+
+// PurgeByID mocks base method.
+func (m *MockSecretRepository) PurgeByID(ctx context.Context, userID, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeByID", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeByID indicates an expected call of PurgeByID.
+func (mr *MockSecretRepositoryMockRecorder) PurgeByID(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeByID", reflect.TypeOf((*MockSecretRepository)(nil).PurgeByID), ctx, userID, id)
+}
+
+// PurgeChangesBefore mocks base method.
+func (m *MockSecretRepository) PurgeChangesBefore(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeChangesBefore", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeChangesBefore indicates an expected call of PurgeChangesBefore.
+func (mr *MockSecretRepositoryMockRecorder) PurgeChangesBefore(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeChangesBefore", reflect.TypeOf((*MockSecretRepository)(nil).PurgeChangesBefore), ctx, before)
+}
+
+// PurgeDeletedBefore mocks base method.
+func (m *MockSecretRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedBefore", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedBefore indicates an expected call of PurgeDeletedBefore.
+func (mr *MockSecretRepositoryMockRecorder) PurgeDeletedBefore(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedBefore", reflect.TypeOf((*MockSecretRepository)(nil).PurgeDeletedBefore), ctx, before)
+}
+
+// RestoreFromTrash mocks base method.
+func (m *MockSecretRepository) RestoreFromTrash(ctx context.Context, userID, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreFromTrash", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreFromTrash indicates an expected call of RestoreFromTrash.
+func (mr *MockSecretRepositoryMockRecorder) RestoreFromTrash(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreFromTrash", reflect.TypeOf((*MockSecretRepository)(nil).RestoreFromTrash), ctx, userID, id)
+}
+
+// Update mocks base method.
+func (m *MockSecretRepository) Update(ctx context.Context, dto models.UpdateSecretDTO) (*models.ReadSecretDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, dto)
+	ret0, _ := ret[0].(*models.ReadSecretDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockSecretRepositoryMockRecorder) Update(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockSecretRepository)(nil).Update), ctx, dto)
+}
+
+// MockSecretAuditRepository is a mock of SecretAuditRepository interface.
+type MockSecretAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecretAuditRepositoryMockRecorder
+}
+
+// MockSecretAuditRepositoryMockRecorder is the mock recorder for MockSecretAuditRepository.
+type MockSecretAuditRepositoryMockRecorder struct {
+	mock *MockSecretAuditRepository
+}
+
+// NewMockSecretAuditRepository creates a new mock instance.
+func NewMockSecretAuditRepository(ctrl *gomock.Controller) *MockSecretAuditRepository {
+	mock := &MockSecretAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockSecretAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSecretAuditRepository) EXPECT() *MockSecretAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockSecretAuditRepository) Create(ctx context.Context, dto models.CreateSecretAuditDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockSecretAuditRepositoryMockRecorder) Create(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSecretAuditRepository)(nil).Create), ctx, dto)
+}
+
+// GetBySecret mocks base method.
+func (m *MockSecretAuditRepository) GetBySecret(ctx context.Context, userID, secretID uint64) ([]models.ReadSecretAuditDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBySecret", ctx, userID, secretID)
+	ret0, _ := ret[0].([]models.ReadSecretAuditDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBySecret indicates an expected call of GetBySecret.
+func (mr *MockSecretAuditRepositoryMockRecorder) GetBySecret(ctx, userID, secretID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBySecret", reflect.TypeOf((*MockSecretAuditRepository)(nil).GetBySecret), ctx, userID, secretID)
+}
+
+// MockFolderRepository is a mock of FolderRepository interface.
+type MockFolderRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFolderRepositoryMockRecorder
+}
+
+// MockFolderRepositoryMockRecorder is the mock recorder for MockFolderRepository.
+type MockFolderRepositoryMockRecorder struct {
+	mock *MockFolderRepository
+}
+
+// NewMockFolderRepository creates a new mock instance.
+func NewMockFolderRepository(ctrl *gomock.Controller) *MockFolderRepository {
+	mock := &MockFolderRepository{ctrl: ctrl}
+	mock.recorder = &MockFolderRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFolderRepository) EXPECT() *MockFolderRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockFolderRepository) Create(ctx context.Context, dto models.CreateFolderDTO) (*models.ReadFolderDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, dto)
+	ret0, _ := ret[0].(*models.ReadFolderDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockFolderRepositoryMockRecorder) Create(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFolderRepository)(nil).Create), ctx, dto)
+}
+
+// GetAllByUser mocks base method.
+func (m *MockFolderRepository) GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadFolderDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByUser", ctx, userID)
+	ret0, _ := ret[0].([]models.ReadFolderDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByUser indicates an expected call of GetAllByUser.
+func (mr *MockFolderRepositoryMockRecorder) GetAllByUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUser", reflect.TypeOf((*MockFolderRepository)(nil).GetAllByUser), ctx, userID)
+}
+
+// GetByID mocks base method.
+func (m *MockFolderRepository) GetByID(ctx context.Context, userID, id uint64) (*models.ReadFolderDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, userID, id)
+	ret0, _ := ret[0].(*models.ReadFolderDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockFolderRepositoryMockRecorder) GetByID(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockFolderRepository)(nil).GetByID), ctx, userID, id)
+}
+
+// Move mocks base method.
+func (m *MockFolderRepository) Move(ctx context.Context, userID, id uint64, parentID *uint64) (*models.ReadFolderDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Move", ctx, userID, id, parentID)
+	ret0, _ := ret[0].(*models.ReadFolderDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Move indicates an expected call of Move.
+func (mr *MockFolderRepositoryMockRecorder) Move(ctx, userID, id, parentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Move", reflect.TypeOf((*MockFolderRepository)(nil).Move), ctx, userID, id, parentID)
+}
+
+// Rename mocks base method.
+func (m *MockFolderRepository) Rename(ctx context.Context, userID, id uint64, name string) (*models.ReadFolderDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rename", ctx, userID, id, name)
+	ret0, _ := ret[0].(*models.ReadFolderDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rename indicates an expected call of Rename.
+func (mr *MockFolderRepositoryMockRecorder) Rename(ctx, userID, id, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rename", reflect.TypeOf((*MockFolderRepository)(nil).Rename), ctx, userID, id, name)
+}
+
+// MockIdempotencyRepository is a mock of IdempotencyRepository interface.
+type MockIdempotencyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyRepositoryMockRecorder
+}
+
+// MockIdempotencyRepositoryMockRecorder is the mock recorder for MockIdempotencyRepository.
+type MockIdempotencyRepositoryMockRecorder struct {
+	mock *MockIdempotencyRepository
+}
+
+// NewMockIdempotencyRepository creates a new mock instance.
+func NewMockIdempotencyRepository(ctrl *gomock.Controller) *MockIdempotencyRepository {
+	mock := &MockIdempotencyRepository{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyRepository) EXPECT() *MockIdempotencyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Complete mocks base method.
+func (m *MockIdempotencyRepository) Complete(ctx context.Context, userID uint64, key string, statusCode int, response []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, userID, key, statusCode, response)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockIdempotencyRepositoryMockRecorder) Complete(ctx, userID, key, statusCode, response interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockIdempotencyRepository)(nil).Complete), ctx, userID, key, statusCode, response)
+}
+
+// DeleteExpired mocks base method.
+func (m *MockIdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockIdempotencyRepositoryMockRecorder) DeleteExpired(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockIdempotencyRepository)(nil).DeleteExpired), ctx, before)
+}
+
+// Release mocks base method.
+func (m *MockIdempotencyRepository) Release(ctx context.Context, userID uint64, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, userID, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockIdempotencyRepositoryMockRecorder) Release(ctx, userID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockIdempotencyRepository)(nil).Release), ctx, userID, key)
+}
+
+// Reserve mocks base method.
+func (m *MockIdempotencyRepository) Reserve(ctx context.Context, userID uint64, key, requestHash string, expiredBefore, leaseExpiredBefore time.Time) (*models.IdempotencyRecordDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reserve", ctx, userID, key, requestHash, expiredBefore, leaseExpiredBefore)
+	ret0, _ := ret[0].(*models.IdempotencyRecordDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reserve indicates an expected call of Reserve.
+func (mr *MockIdempotencyRepositoryMockRecorder) Reserve(ctx, userID, key, requestHash, expiredBefore, leaseExpiredBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserve", reflect.TypeOf((*MockIdempotencyRepository)(nil).Reserve), ctx, userID, key, requestHash, expiredBefore, leaseExpiredBefore)
+}
+
+// MockWebhookRepository is a mock of WebhookRepository interface.
+type MockWebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepositoryMockRecorder
+}
+
+// MockWebhookRepositoryMockRecorder is the mock recorder for MockWebhookRepository.
+type MockWebhookRepositoryMockRecorder struct {
+	mock *MockWebhookRepository
+}
+
+// NewMockWebhookRepository creates a new mock instance.
+func NewMockWebhookRepository(ctrl *gomock.Controller) *MockWebhookRepository {
+	mock := &MockWebhookRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepository) EXPECT() *MockWebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimDue mocks base method.
+func (m *MockWebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookTaskDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDue", ctx, limit, lease)
+	ret0, _ := ret[0].([]models.WebhookTaskDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDue indicates an expected call of ClaimDue.
+func (mr *MockWebhookRepositoryMockRecorder) ClaimDue(ctx, limit, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDue", reflect.TypeOf((*MockWebhookRepository)(nil).ClaimDue), ctx, limit, lease)
+}
+
+// Create mocks base method.
+func (m *MockWebhookRepository) Create(ctx context.Context, dto models.CreateWebhookDTO, secret string) (*models.ReadWebhookDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, dto, secret)
+	ret0, _ := ret[0].(*models.ReadWebhookDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWebhookRepositoryMockRecorder) Create(ctx, dto, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWebhookRepository)(nil).Create), ctx, dto, secret)
+}
+
+// DeleteByID mocks base method.
+func (m *MockWebhookRepository) DeleteByID(ctx context.Context, userID, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByID", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByID indicates an expected call of DeleteByID.
+func (mr *MockWebhookRepositoryMockRecorder) DeleteByID(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockWebhookRepository)(nil).DeleteByID), ctx, userID, id)
+}
+
+// Enqueue mocks base method.
+func (m *MockWebhookRepository) Enqueue(ctx context.Context, userID uint64, event string, payload []byte) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", ctx, userID, event, payload)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockWebhookRepositoryMockRecorder) Enqueue(ctx, userID, event, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockWebhookRepository)(nil).Enqueue), ctx, userID, event, payload)
+}
+
+// GetAllByUser mocks base method.
+func (m *MockWebhookRepository) GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadWebhookDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByUser", ctx, userID)
+	ret0, _ := ret[0].([]models.ReadWebhookDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByUser indicates an expected call of GetAllByUser.
+func (mr *MockWebhookRepositoryMockRecorder) GetAllByUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUser", reflect.TypeOf((*MockWebhookRepository)(nil).GetAllByUser), ctx, userID)
+}
+
+// GetDeliveries mocks base method.
+func (m *MockWebhookRepository) GetDeliveries(ctx context.Context, userID, webhookID uint64, limit int) ([]models.WebhookDeliveryDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveries", ctx, userID, webhookID, limit)
+	ret0, _ := ret[0].([]models.WebhookDeliveryDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveries indicates an expected call of GetDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) GetDeliveries(ctx, userID, webhookID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).GetDeliveries), ctx, userID, webhookID, limit)
+}
+
+// SaveAttempt mocks base method.
+func (m *MockWebhookRepository) SaveAttempt(ctx context.Context, id uint64, attempt models.WebhookAttemptDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAttempt", ctx, id, attempt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAttempt indicates an expected call of SaveAttempt.
+func (mr *MockWebhookRepositoryMockRecorder) SaveAttempt(ctx, id, attempt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAttempt", reflect.TypeOf((*MockWebhookRepository)(nil).SaveAttempt), ctx, id, attempt)
+}
+
+// MockLoginAttemptRepository is a mock of LoginAttemptRepository interface.
+type MockLoginAttemptRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginAttemptRepositoryMockRecorder
+}
+
+// MockLoginAttemptRepositoryMockRecorder is the mock recorder for MockLoginAttemptRepository.
+type MockLoginAttemptRepositoryMockRecorder struct {
+	mock *MockLoginAttemptRepository
+}
+
+// NewMockLoginAttemptRepository creates a new mock instance.
+func NewMockLoginAttemptRepository(ctrl *gomock.Controller) *MockLoginAttemptRepository {
+	mock := &MockLoginAttemptRepository{ctrl: ctrl}
+	mock.recorder = &MockLoginAttemptRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginAttemptRepository) EXPECT() *MockLoginAttemptRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountFailures mocks base method.
+func (m *MockLoginAttemptRepository) CountFailures(ctx context.Context, userID uint64, since time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountFailures", ctx, userID, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountFailures indicates an expected call of CountFailures.
+func (mr *MockLoginAttemptRepositoryMockRecorder) CountFailures(ctx, userID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountFailures", reflect.TypeOf((*MockLoginAttemptRepository)(nil).CountFailures), ctx, userID, since)
+}
+
+// IsKnownDevice mocks base method.
+func (m *MockLoginAttemptRepository) IsKnownDevice(ctx context.Context, userID uint64, device string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsKnownDevice", ctx, userID, device)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsKnownDevice indicates an expected call of IsKnownDevice.
+func (mr *MockLoginAttemptRepositoryMockRecorder) IsKnownDevice(ctx, userID, device interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsKnownDevice", reflect.TypeOf((*MockLoginAttemptRepository)(nil).IsKnownDevice), ctx, userID, device)
+}
+
+// Record mocks base method.
+func (m *MockLoginAttemptRepository) Record(ctx context.Context, dto models.LoginAttemptDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockLoginAttemptRepositoryMockRecorder) Record(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockLoginAttemptRepository)(nil).Record), ctx, dto)
+}
+
+// MockDeviceCertificateRepository is a mock of DeviceCertificateRepository interface.
+type MockDeviceCertificateRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeviceCertificateRepositoryMockRecorder
+}
+
+// MockDeviceCertificateRepositoryMockRecorder is the mock recorder for MockDeviceCertificateRepository.
+type MockDeviceCertificateRepositoryMockRecorder struct {
+	mock *MockDeviceCertificateRepository
+}
+
+// NewMockDeviceCertificateRepository creates a new mock instance.
+func NewMockDeviceCertificateRepository(ctrl *gomock.Controller) *MockDeviceCertificateRepository {
+	mock := &MockDeviceCertificateRepository{ctrl: ctrl}
+	mock.recorder = &MockDeviceCertificateRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeviceCertificateRepository) EXPECT() *MockDeviceCertificateRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockDeviceCertificateRepository) Create(ctx context.Context, dto models.CreateDeviceCertificateDTO) (*models.DeviceCertificateDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, dto)
+	ret0, _ := ret[0].(*models.DeviceCertificateDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockDeviceCertificateRepositoryMockRecorder) Create(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockDeviceCertificateRepository)(nil).Create), ctx, dto)
+}
+
+// GetAllByUser mocks base method.
+func (m *MockDeviceCertificateRepository) GetAllByUser(ctx context.Context, userID uint64) ([]models.DeviceCertificateDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByUser", ctx, userID)
+	ret0, _ := ret[0].([]models.DeviceCertificateDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByUser indicates an expected call of GetAllByUser.
+func (mr *MockDeviceCertificateRepositoryMockRecorder) GetAllByUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUser", reflect.TypeOf((*MockDeviceCertificateRepository)(nil).GetAllByUser), ctx, userID)
+}
+
+// GetBySerial mocks base method.
+func (m *MockDeviceCertificateRepository) GetBySerial(ctx context.Context, serial string) (*models.DeviceCertificateDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBySerial", ctx, serial)
+	ret0, _ := ret[0].(*models.DeviceCertificateDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBySerial indicates an expected call of GetBySerial.
+func (mr *MockDeviceCertificateRepositoryMockRecorder) GetBySerial(ctx, serial interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBySerial", reflect.TypeOf((*MockDeviceCertificateRepository)(nil).GetBySerial), ctx, serial)
+}
+
+// GetRevoked mocks base method.
+func (m *MockDeviceCertificateRepository) GetRevoked(ctx context.Context, now time.Time) ([]models.DeviceCertificateDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevoked", ctx, now)
+	ret0, _ := ret[0].([]models.DeviceCertificateDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevoked indicates an expected call of GetRevoked.
+func (mr *MockDeviceCertificateRepositoryMockRecorder) GetRevoked(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevoked", reflect.TypeOf((*MockDeviceCertificateRepository)(nil).GetRevoked), ctx, now)
+}
+
+// Revoke mocks base method.
+func (m *MockDeviceCertificateRepository) Revoke(ctx context.Context, serial string) (*models.DeviceCertificateDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, serial)
+	ret0, _ := ret[0].(*models.DeviceCertificateDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockDeviceCertificateRepositoryMockRecorder) Revoke(ctx, serial interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockDeviceCertificateRepository)(nil).Revoke), ctx, serial)
+}
+
+// MockHealthRepository is a mock of HealthRepository interface.
+type MockHealthRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthRepositoryMockRecorder
+}
+
+// MockHealthRepositoryMockRecorder is the mock recorder for MockHealthRepository.
+type MockHealthRepositoryMockRecorder struct {
+	mock *MockHealthRepository
+}
+
+// NewMockHealthRepository creates a new mock instance.
+func NewMockHealthRepository(ctrl *gomock.Controller) *MockHealthRepository {
+	mock := &MockHealthRepository{ctrl: ctrl}
+	mock.recorder = &MockHealthRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHealthRepository) EXPECT() *MockHealthRepositoryMockRecorder {
+	return m.recorder
+}
+
+// MigrationVersion mocks base method.
+func (m *MockHealthRepository) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrationVersion", ctx)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// MigrationVersion indicates an expected call of MigrationVersion.
+func (mr *MockHealthRepositoryMockRecorder) MigrationVersion(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrationVersion", reflect.TypeOf((*MockHealthRepository)(nil).MigrationVersion), ctx)
+}
+
+// Ping mocks base method.
+func (m *MockHealthRepository) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockHealthRepositoryMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockHealthRepository)(nil).Ping), ctx)
+}
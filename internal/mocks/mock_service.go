@@ -9,7 +9,9 @@ package mocks
 
 import (
 	context "context"
+	x509 "crypto/x509"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/shekshuev/gophkeeper/internal/models"
@@ -129,6 +131,21 @@ func (m *MockSecretService) EXPECT() *MockSecretServiceMockRecorder {
 	return m.recorder
 }
 
+// Batch mocks base method.
+func (m *MockSecretService) Batch(ctx context.Context, userID uint64, ops []models.BatchOperationDTO, atomic bool, device string) ([]models.BatchOutcome, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Batch", ctx, userID, ops, atomic, device)
+	ret0, _ := ret[0].([]models.BatchOutcome)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Batch indicates an expected call of Batch.
+func (mr *MockSecretServiceMockRecorder) Batch(ctx, userID, ops, atomic, device interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Batch", reflect.TypeOf((*MockSecretService)(nil).Batch), ctx, userID, ops, atomic, device)
+}
+
 // Create mocks base method.
 func (m *MockSecretService) Create(ctx context.Context, dto models.CreateSecretDTO) (uint64, error) {
 	m.ctrl.T.Helper()
@@ -145,45 +162,631 @@ func (mr *MockSecretServiceMockRecorder) Create(ctx, dto interface{}) *gomock.Ca
 }
 
 // DeleteByID mocks base method.
-func (m *MockSecretService) DeleteByID(ctx context.Context, id uint64) error {
+func (m *MockSecretService) DeleteByID(ctx context.Context, userID, id uint64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteByID", ctx, id)
+	ret := m.ctrl.Call(m, "DeleteByID", ctx, userID, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteByID indicates an expected call of DeleteByID.
-func (mr *MockSecretServiceMockRecorder) DeleteByID(ctx, id interface{}) *gomock.Call {
+func (mr *MockSecretServiceMockRecorder) DeleteByID(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockSecretService)(nil).DeleteByID), ctx, userID, id)
+}
+
+// ExportPageByUser mocks base method.
+func (m *MockSecretService) ExportPageByUser(ctx context.Context, userID uint64, filter models.SecretFilterDTO, meta models.RequestMetaDTO) (*models.SecretPageDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportPageByUser", ctx, userID, filter, meta)
+	ret0, _ := ret[0].(*models.SecretPageDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportPageByUser indicates an expected call of ExportPageByUser.
+func (mr *MockSecretServiceMockRecorder) ExportPageByUser(ctx, userID, filter, meta interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockSecretService)(nil).DeleteByID), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportPageByUser", reflect.TypeOf((*MockSecretService)(nil).ExportPageByUser), ctx, userID, filter, meta)
 }
 
 // GetAllByUser mocks base method.
-func (m *MockSecretService) GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadSecretDTO, error) {
+func (m *MockSecretService) GetAllByUser(ctx context.Context, userID uint64, filter models.SecretFilterDTO) ([]models.SecretSummaryDTO, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllByUser", ctx, userID)
-	ret0, _ := ret[0].([]models.ReadSecretDTO)
+	ret := m.ctrl.Call(m, "GetAllByUser", ctx, userID, filter)
+	ret0, _ := ret[0].([]models.SecretSummaryDTO)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllByUser indicates an expected call of GetAllByUser.
-func (mr *MockSecretServiceMockRecorder) GetAllByUser(ctx, userID interface{}) *gomock.Call {
+func (mr *MockSecretServiceMockRecorder) GetAllByUser(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUser", reflect.TypeOf((*MockSecretService)(nil).GetAllByUser), ctx, userID, filter)
+}
+
+// GetAuditBySecret mocks base method.
+func (m *MockSecretService) GetAuditBySecret(ctx context.Context, userID, id uint64) ([]models.ReadSecretAuditDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditBySecret", ctx, userID, id)
+	ret0, _ := ret[0].([]models.ReadSecretAuditDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditBySecret indicates an expected call of GetAuditBySecret.
+func (mr *MockSecretServiceMockRecorder) GetAuditBySecret(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditBySecret", reflect.TypeOf((*MockSecretService)(nil).GetAuditBySecret), ctx, userID, id)
+}
+
+// GetChangesAfter mocks base method.
+func (m *MockSecretService) GetChangesAfter(ctx context.Context, userID, afterID uint64, send func(models.SecretEventDTO) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChangesAfter", ctx, userID, afterID, send)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetChangesAfter indicates an expected call of GetChangesAfter.
+func (mr *MockSecretServiceMockRecorder) GetChangesAfter(ctx, userID, afterID, send interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChangesAfter", reflect.TypeOf((*MockSecretService)(nil).GetChangesAfter), ctx, userID, afterID, send)
+}
+
+// GetChangesSince mocks base method.
+func (m *MockSecretService) GetChangesSince(ctx context.Context, userID uint64, since time.Time, send func(models.SecretEventDTO) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChangesSince", ctx, userID, since, send)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetChangesSince indicates an expected call of GetChangesSince.
+func (mr *MockSecretServiceMockRecorder) GetChangesSince(ctx, userID, since, send interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChangesSince", reflect.TypeOf((*MockSecretService)(nil).GetChangesSince), ctx, userID, since, send)
+}
+
+// GetSummaryPageByUser mocks base method.
+func (m *MockSecretService) GetSummaryPageByUser(ctx context.Context, userID uint64, filter models.SecretFilterDTO) (*models.SecretSummaryPageDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSummaryPageByUser", ctx, userID, filter)
+	ret0, _ := ret[0].(*models.SecretSummaryPageDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSummaryPageByUser indicates an expected call of GetSummaryPageByUser.
+func (mr *MockSecretServiceMockRecorder) GetSummaryPageByUser(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSummaryPageByUser", reflect.TypeOf((*MockSecretService)(nil).GetSummaryPageByUser), ctx, userID, filter)
+}
+
+// GetTrash mocks base method.
+func (m *MockSecretService) GetTrash(ctx context.Context, userID uint64) ([]models.TrashedSecretDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrash", ctx, userID)
+	ret0, _ := ret[0].([]models.TrashedSecretDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrash indicates an expected call of GetTrash.
+func (mr *MockSecretServiceMockRecorder) GetTrash(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrash", reflect.TypeOf((*MockSecretService)(nil).GetTrash), ctx, userID)
+}
+
+// GetVersion mocks base method.
+func (m *MockSecretService) GetVersion(ctx context.Context, userID, id uint64, version int, meta models.RequestMetaDTO) (*models.ReadSecretVersionDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersion", ctx, userID, id, version, meta)
+	ret0, _ := ret[0].(*models.ReadSecretVersionDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersion indicates an expected call of GetVersion.
+func (mr *MockSecretServiceMockRecorder) GetVersion(ctx, userID, id, version, meta interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUser", reflect.TypeOf((*MockSecretService)(nil).GetAllByUser), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersion", reflect.TypeOf((*MockSecretService)(nil).GetVersion), ctx, userID, id, version, meta)
 }
 
-// GetByID mocks base method.
-func (m *MockSecretService) GetByID(ctx context.Context, id uint64) (*models.ReadSecretDTO, error) {
+// GetVersions mocks base method.
+func (m *MockSecretService) GetVersions(ctx context.Context, userID, id uint64) ([]models.SecretVersionDTO, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret := m.ctrl.Call(m, "GetVersions", ctx, userID, id)
+	ret0, _ := ret[0].([]models.SecretVersionDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersions indicates an expected call of GetVersions.
+func (mr *MockSecretServiceMockRecorder) GetVersions(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersions", reflect.TypeOf((*MockSecretService)(nil).GetVersions), ctx, userID, id)
+}
+
+// Purge mocks base method.
+func (m *MockSecretService) Purge(ctx context.Context, userID, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockSecretServiceMockRecorder) Purge(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockSecretService)(nil).Purge), ctx, userID, id)
+}
+
+// Restore mocks base method.
+func (m *MockSecretService) Restore(ctx context.Context, userID, id uint64, version int, device string) (*models.ReadSecretDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, userID, id, version, device)
 	ret0, _ := ret[0].(*models.ReadSecretDTO)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetByID indicates an expected call of GetByID.
-func (mr *MockSecretServiceMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+// Restore indicates an expected call of Restore.
+func (mr *MockSecretServiceMockRecorder) Restore(ctx, userID, id, version, device interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockSecretService)(nil).Restore), ctx, userID, id, version, device)
+}
+
+// RestoreFromTrash mocks base method.
+func (m *MockSecretService) RestoreFromTrash(ctx context.Context, userID, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreFromTrash", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreFromTrash indicates an expected call of RestoreFromTrash.
+func (mr *MockSecretServiceMockRecorder) RestoreFromTrash(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreFromTrash", reflect.TypeOf((*MockSecretService)(nil).RestoreFromTrash), ctx, userID, id)
+}
+
+// Reveal mocks base method.
+func (m *MockSecretService) Reveal(ctx context.Context, userID, id uint64, meta models.RequestMetaDTO) (*models.ReadSecretDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reveal", ctx, userID, id, meta)
+	ret0, _ := ret[0].(*models.ReadSecretDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reveal indicates an expected call of Reveal.
+func (mr *MockSecretServiceMockRecorder) Reveal(ctx, userID, id, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reveal", reflect.TypeOf((*MockSecretService)(nil).Reveal), ctx, userID, id, meta)
+}
+
+// Update mocks base method.
+func (m *MockSecretService) Update(ctx context.Context, dto models.UpdateSecretDTO) (*models.ReadSecretDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, dto)
+	ret0, _ := ret[0].(*models.ReadSecretDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockSecretServiceMockRecorder) Update(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockSecretService)(nil).Update), ctx, dto)
+}
+
+// MockFolderService is a mock of FolderService interface.
+type MockFolderService struct {
+	ctrl     *gomock.Controller
+	recorder *MockFolderServiceMockRecorder
+}
+
+// MockFolderServiceMockRecorder is the mock recorder for MockFolderService.
+type MockFolderServiceMockRecorder struct {
+	mock *MockFolderService
+}
+
+// NewMockFolderService creates a new mock instance.
+func NewMockFolderService(ctrl *gomock.Controller) *MockFolderService {
+	mock := &MockFolderService{ctrl: ctrl}
+	mock.recorder = &MockFolderServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFolderService) EXPECT() *MockFolderServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockFolderService) Create(ctx context.Context, dto models.CreateFolderDTO) (*models.ReadFolderDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, dto)
+	ret0, _ := ret[0].(*models.ReadFolderDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockFolderServiceMockRecorder) Create(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFolderService)(nil).Create), ctx, dto)
+}
+
+// GetAllByUser mocks base method.
+func (m *MockFolderService) GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadFolderDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByUser", ctx, userID)
+	ret0, _ := ret[0].([]models.ReadFolderDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByUser indicates an expected call of GetAllByUser.
+func (mr *MockFolderServiceMockRecorder) GetAllByUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUser", reflect.TypeOf((*MockFolderService)(nil).GetAllByUser), ctx, userID)
+}
+
+// Move mocks base method.
+func (m *MockFolderService) Move(ctx context.Context, userID, id uint64, dto models.MoveFolderDTO) (*models.ReadFolderDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Move", ctx, userID, id, dto)
+	ret0, _ := ret[0].(*models.ReadFolderDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Move indicates an expected call of Move.
+func (mr *MockFolderServiceMockRecorder) Move(ctx, userID, id, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Move", reflect.TypeOf((*MockFolderService)(nil).Move), ctx, userID, id, dto)
+}
+
+// Rename mocks base method.
+func (m *MockFolderService) Rename(ctx context.Context, userID, id uint64, dto models.RenameFolderDTO) (*models.ReadFolderDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rename", ctx, userID, id, dto)
+	ret0, _ := ret[0].(*models.ReadFolderDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rename indicates an expected call of Rename.
+func (mr *MockFolderServiceMockRecorder) Rename(ctx, userID, id, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rename", reflect.TypeOf((*MockFolderService)(nil).Rename), ctx, userID, id, dto)
+}
+
+// MockIdempotencyService is a mock of IdempotencyService interface.
+type MockIdempotencyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyServiceMockRecorder
+}
+
+// MockIdempotencyServiceMockRecorder is the mock recorder for MockIdempotencyService.
+type MockIdempotencyServiceMockRecorder struct {
+	mock *MockIdempotencyService
+}
+
+// NewMockIdempotencyService creates a new mock instance.
+func NewMockIdempotencyService(ctrl *gomock.Controller) *MockIdempotencyService {
+	mock := &MockIdempotencyService{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyService) EXPECT() *MockIdempotencyServiceMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockIdempotencyService) Begin(ctx context.Context, userID uint64, key, requestHash string) (*models.IdempotencyRecordDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", ctx, userID, key, requestHash)
+	ret0, _ := ret[0].(*models.IdempotencyRecordDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockIdempotencyServiceMockRecorder) Begin(ctx, userID, key, requestHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockIdempotencyService)(nil).Begin), ctx, userID, key, requestHash)
+}
+
+// Complete mocks base method.
+func (m *MockIdempotencyService) Complete(ctx context.Context, userID uint64, key string, statusCode int, response []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, userID, key, statusCode, response)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockIdempotencyServiceMockRecorder) Complete(ctx, userID, key, statusCode, response interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockIdempotencyService)(nil).Complete), ctx, userID, key, statusCode, response)
+}
+
+// Release mocks base method.
+func (m *MockIdempotencyService) Release(ctx context.Context, userID uint64, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, userID, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockIdempotencyServiceMockRecorder) Release(ctx, userID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockIdempotencyService)(nil).Release), ctx, userID, key)
+}
+
+// MockWebhookService is a mock of WebhookService interface.
+type MockWebhookService struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookServiceMockRecorder
+}
+
+// MockWebhookServiceMockRecorder is the mock recorder for MockWebhookService.
+type MockWebhookServiceMockRecorder struct {
+	mock *MockWebhookService
+}
+
+// NewMockWebhookService creates a new mock instance.
+func NewMockWebhookService(ctrl *gomock.Controller) *MockWebhookService {
+	mock := &MockWebhookService{ctrl: ctrl}
+	mock.recorder = &MockWebhookServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookService) EXPECT() *MockWebhookServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockWebhookService) Create(ctx context.Context, dto models.CreateWebhookDTO) (*models.ReadWebhookDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, dto)
+	ret0, _ := ret[0].(*models.ReadWebhookDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWebhookServiceMockRecorder) Create(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWebhookService)(nil).Create), ctx, dto)
+}
+
+// DeleteByID mocks base method.
+func (m *MockWebhookService) DeleteByID(ctx context.Context, userID, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByID", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByID indicates an expected call of DeleteByID.
+func (mr *MockWebhookServiceMockRecorder) DeleteByID(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockWebhookService)(nil).DeleteByID), ctx, userID, id)
+}
+
+// GetAllByUser mocks base method.
+func (m *MockWebhookService) GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadWebhookDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByUser", ctx, userID)
+	ret0, _ := ret[0].([]models.ReadWebhookDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByUser indicates an expected call of GetAllByUser.
+func (mr *MockWebhookServiceMockRecorder) GetAllByUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUser", reflect.TypeOf((*MockWebhookService)(nil).GetAllByUser), ctx, userID)
+}
+
+// GetDeliveries mocks base method.
+func (m *MockWebhookService) GetDeliveries(ctx context.Context, userID, id uint64) ([]models.WebhookDeliveryDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveries", ctx, userID, id)
+	ret0, _ := ret[0].([]models.WebhookDeliveryDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveries indicates an expected call of GetDeliveries.
+func (mr *MockWebhookServiceMockRecorder) GetDeliveries(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveries", reflect.TypeOf((*MockWebhookService)(nil).GetDeliveries), ctx, userID, id)
+}
+
+// Notify mocks base method.
+func (m *MockWebhookService) Notify(ctx context.Context, userID uint64, event string, data any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notify", ctx, userID, event, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Notify indicates an expected call of Notify.
+func (mr *MockWebhookServiceMockRecorder) Notify(ctx, userID, event, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockWebhookService)(nil).Notify), ctx, userID, event, data)
+}
+
+// MockDeviceService is a mock of DeviceService interface.
+type MockDeviceService struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeviceServiceMockRecorder
+}
+
+// MockDeviceServiceMockRecorder is the mock recorder for MockDeviceService.
+type MockDeviceServiceMockRecorder struct {
+	mock *MockDeviceService
+}
+
+// NewMockDeviceService creates a new mock instance.
+func NewMockDeviceService(ctrl *gomock.Controller) *MockDeviceService {
+	mock := &MockDeviceService{ctrl: ctrl}
+	mock.recorder = &MockDeviceServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeviceService) EXPECT() *MockDeviceServiceMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockDeviceService) Authenticate(ctx context.Context, cert *x509.Certificate) (*models.DeviceCertificateDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, cert)
+	ret0, _ := ret[0].(*models.DeviceCertificateDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockDeviceServiceMockRecorder) Authenticate(ctx, cert interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockDeviceService)(nil).Authenticate), ctx, cert)
+}
+
+// Enroll mocks base method.
+func (m *MockDeviceService) Enroll(ctx context.Context, user models.ReadAuthUserDataDTO, device, csr string) (*models.DeviceCertificateDTO, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enroll", ctx, user, device, csr)
+	ret0, _ := ret[0].(*models.DeviceCertificateDTO)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Enroll indicates an expected call of Enroll.
+func (mr *MockDeviceServiceMockRecorder) Enroll(ctx, user, device, csr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enroll", reflect.TypeOf((*MockDeviceService)(nil).Enroll), ctx, user, device, csr)
+}
+
+// GetAllByUser mocks base method.
+func (m *MockDeviceService) GetAllByUser(ctx context.Context, userID uint64) ([]models.DeviceCertificateDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByUser", ctx, userID)
+	ret0, _ := ret[0].([]models.DeviceCertificateDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByUser indicates an expected call of GetAllByUser.
+func (mr *MockDeviceServiceMockRecorder) GetAllByUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUser", reflect.TypeOf((*MockDeviceService)(nil).GetAllByUser), ctx, userID)
+}
+
+// RevocationList mocks base method.
+func (m *MockDeviceService) RevocationList(ctx context.Context) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevocationList", ctx)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevocationList indicates an expected call of RevocationList.
+func (mr *MockDeviceServiceMockRecorder) RevocationList(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevocationList", reflect.TypeOf((*MockDeviceService)(nil).RevocationList), ctx)
+}
+
+// Revoke mocks base method.
+func (m *MockDeviceService) Revoke(ctx context.Context, serial string) (*models.DeviceCertificateDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, serial)
+	ret0, _ := ret[0].(*models.DeviceCertificateDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockDeviceServiceMockRecorder) Revoke(ctx, serial interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockDeviceService)(nil).Revoke), ctx, serial)
+}
+
+// RevokeByUser mocks base method.
+func (m *MockDeviceService) RevokeByUser(ctx context.Context, userID uint64, serial string) (*models.DeviceCertificateDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeByUser", ctx, userID, serial)
+	ret0, _ := ret[0].(*models.DeviceCertificateDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeByUser indicates an expected call of RevokeByUser.
+func (mr *MockDeviceServiceMockRecorder) RevokeByUser(ctx, userID, serial interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeByUser", reflect.TypeOf((*MockDeviceService)(nil).RevokeByUser), ctx, userID, serial)
+}
+
+// MockHealthService is a mock of HealthService interface.
+type MockHealthService struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthServiceMockRecorder
+}
+
+// MockHealthServiceMockRecorder is the mock recorder for MockHealthService.
+type MockHealthServiceMockRecorder struct {
+	mock *MockHealthService
+}
+
+// NewMockHealthService creates a new mock instance.
+func NewMockHealthService(ctrl *gomock.Controller) *MockHealthService {
+	mock := &MockHealthService{ctrl: ctrl}
+	mock.recorder = &MockHealthServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHealthService) EXPECT() *MockHealthServiceMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockHealthService) Check(ctx context.Context) *models.ReadinessDTO {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx)
+	ret0, _ := ret[0].(*models.ReadinessDTO)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockHealthServiceMockRecorder) Check(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockHealthService)(nil).Check), ctx)
+}
+
+// WaitForDatabase mocks base method.
+func (m *MockHealthService) WaitForDatabase(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForDatabase", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WaitForDatabase indicates an expected call of WaitForDatabase.
+func (mr *MockHealthServiceMockRecorder) WaitForDatabase(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockSecretService)(nil).GetByID), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForDatabase", reflect.TypeOf((*MockHealthService)(nil).WaitForDatabase), ctx)
 }
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Действия с секретами, которые фиксируются в журнале аудита.
+const (
+	SecretActionReveal        = "reveal"         // Просмотр данных секрета
+	SecretActionRevealVersion = "reveal_version" // Просмотр данных предыдущей версии секрета
+)
+
+// RequestMetaDTO описывает источник запроса для записи в журнал аудита.
+type RequestMetaDTO struct {
+	IP        string // IP-адрес клиента
+	UserAgent string // Заголовок User-Agent клиента
+	Device    string // Идентификатор устройства клиента (заголовок X-Device-ID)
+}
+
+// CreateSecretAuditDTO используется для добавления записи в журнал аудита секретов.
+type CreateSecretAuditDTO struct {
+	UserID    uint64 // ID пользователя, выполнившего действие
+	SecretID  uint64 // ID секрета
+	Action    string // Действие (см. SecretAction*)
+	IP        string // IP-адрес клиента
+	UserAgent string // User-Agent клиента
+}
+
+// ReadSecretAuditDTO — запись журнала аудита секрета.
+type ReadSecretAuditDTO struct {
+	ID        uint64    `json:"id"`         // ID записи
+	SecretID  uint64    `json:"secret_id"`  // ID секрета
+	Action    string    `json:"action"`     // Действие
+	IP        string    `json:"ip"`         // IP-адрес клиента
+	UserAgent string    `json:"user_agent"` // User-Agent клиента
+	CreatedAt time.Time `json:"created_at"` // Когда выполнено действие
+}
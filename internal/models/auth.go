@@ -4,6 +4,9 @@ package models
 type LoginUserDTO struct {
 	UserName string `json:"user_name" validate:"required,min=5,max=30,alphanumunderscore,startswithalpha"` // Логин: от 5 до 30 символов, буквы/цифры/подчёркивание, начинается с буквы
 	Password string `json:"password" validate:"required,password"`                                         // Пароль: обязательный, соответствует пользовательским правилам
+	CSR      string `json:"csr,omitempty" validate:"max=16384"`                                            // Запрос на сертификат устройства (PKCS #10 в PEM), если включены сертификаты устройств
+	Device   string `json:"-"`                                                                             // Устройство клиента (заголовок X-Device-ID)
+	IP       string `json:"-"`                                                                             // IP-адрес клиента
 }
 
 // RegisterUserDTO используется при регистрации нового пользователя.
@@ -14,10 +17,14 @@ type RegisterUserDTO struct {
 	PasswordConfirm string `json:"password_confirm" validate:"required,password,eqfield=Password"`                // Подтверждение пароля (должно совпадать с Password)
 	FirstName       string `json:"first_name" validate:"required,min=1,max=30,alphaunicode"`                      // Имя: только буквы (включая Unicode)
 	LastName        string `json:"last_name" validate:"required,min=1,max=30,alphaunicode"`                       // Фамилия: только буквы (включая Unicode)
+	Device          string `json:"-"`                                                                             // Устройство клиента (заголовок X-Device-ID)
+	IP              string `json:"-"`                                                                             // IP-адрес клиента
 }
 
 // ReadTokenDTO содержит access и refresh токены, возвращаемые после успешной аутентификации.
+// Если при входе выпущен сертификат устройства, токены действуют только вместе с ним.
 type ReadTokenDTO struct {
-	AccessToken  string `json:"access_token"`  // JWT access token (короткоживущий)
-	RefreshToken string `json:"refresh_token"` // JWT refresh token (для обновления access токена)
+	AccessToken       string `json:"access_token"`                 // JWT access token (короткоживущий)
+	RefreshToken      string `json:"refresh_token"`                // JWT refresh token (для обновления access токена)
+	ClientCertificate string `json:"client_certificate,omitempty"` // Сертификат устройства в PEM, выпущенный по запросу CSR
 }
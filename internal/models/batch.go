@@ -0,0 +1,51 @@
+package models
+
+// Операции пакетного запроса над секретами.
+const (
+	BatchOpCreate = "create" // Создание секрета
+	BatchOpUpdate = "update" // Изменение секрета (с сохранением предыдущей версии)
+	BatchOpDelete = "delete" // Перемещение секрета в корзину
+)
+
+// MaxBatchOperations — максимальное количество операций в одном пакетном запросе.
+const MaxBatchOperations = 1000
+
+// BatchRequestDTO — пакет операций над секретами, выполняемый в одной транзакции.
+type BatchRequestDTO struct {
+	Atomic     bool                `json:"atomic"`                                        // Всё или ничего: ошибка любой операции отменяет весь пакет
+	Operations []BatchOperationDTO `json:"operations" validate:"required,min=1,max=1000"` // Операции в порядке выполнения
+}
+
+// BatchOperationDTO — одна операция пакета.
+// Для create заполняются title, data, folder_id и tags; для update — ещё и id; для delete — только id.
+type BatchOperationDTO struct {
+	Op       string        `json:"op" validate:"required,oneof=create update delete"`            // Операция (см. BatchOp*)
+	ID       uint64        `json:"id,omitempty" validate:"required_unless=Op create"`            // ID секрета (update, delete)
+	Title    string        `json:"title,omitempty" validate:"required_unless=Op delete,max=100"` // Название секрета
+	Data     SecretDataDTO `json:"data"`                                                         // Полезные данные (json)
+	FolderID *uint64       `json:"folder_id,omitempty"`                                          // ID папки (nil — вне папок)
+	Tags     []string      `json:"tags,omitempty" validate:"max=20,dive,min=1,max=50"`           // Теги секрета
+}
+
+// BatchOutcome — итог выполнения одной операции пакета в репозитории и сервисе.
+type BatchOutcome struct {
+	ID      uint64 // ID созданного, изменённого или удалённого секрета
+	Version int    // Номер версии секрета после операции (create, update)
+	Err     error  // Ошибка операции (nil — операция применена)
+}
+
+// BatchResultDTO — результат одной операции пакета в ответе клиенту.
+type BatchResultDTO struct {
+	Index   int    `json:"index"`             // Номер операции в запросе (с нуля)
+	Op      string `json:"op"`                // Операция
+	Status  int    `json:"status"`            // HTTP-статус, который вернул бы одиночный запрос
+	ID      uint64 `json:"id,omitempty"`      // ID секрета
+	Version int    `json:"version,omitempty"` // Номер версии секрета после операции
+	Error   string `json:"error,omitempty"`   // Текст ошибки
+}
+
+// BatchResponseDTO — ответ на пакетный запрос.
+type BatchResponseDTO struct {
+	Committed bool             `json:"committed"` // Зафиксированы ли изменения
+	Results   []BatchResultDTO `json:"results"`   // Результаты операций в порядке запроса
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// CreateDeviceCertificateDTO — сертификат, выданный устройству при входе, для сохранения в базе.
+type CreateDeviceCertificateDTO struct {
+	UserID     uint64    // ID владельца устройства
+	Device     string    // Устройство (заголовок X-Device-ID при входе)
+	Serial     string    // Серийный номер сертификата в шестнадцатеричном виде
+	Thumbprint string    // Отпечаток сертификата SHA-256 в base64url (x5t#S256, RFC 8705)
+	ExpiresAt  time.Time // Когда истекает срок действия сертификата
+}
+
+// DeviceCertificateDTO — сертификат устройства пользователя.
+// Отозванный сертификат остаётся в базе до истечения срока действия и попадает в список отзыва (CRL).
+type DeviceCertificateDTO struct {
+	ID         uint64     `json:"id"`                   // ID записи
+	UserID     uint64     `json:"user_id"`              // ID владельца устройства
+	Device     string     `json:"device"`               // Устройство, которому выдан сертификат
+	Serial     string     `json:"serial"`               // Серийный номер сертификата в шестнадцатеричном виде
+	Thumbprint string     `json:"thumbprint"`           // Отпечаток сертификата SHA-256 в base64url
+	CreatedAt  time.Time  `json:"created_at"`           // Когда выдан
+	ExpiresAt  time.Time  `json:"expires_at"`           // Когда истекает срок действия
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"` // Когда отозван
+}
+
+// DeviceRevokedDTO — сообщение экземплярам сервера об отзыве сертификата устройства.
+// Получив его, экземпляр удаляет сертификат из кэша.
+type DeviceRevokedDTO struct {
+	Serial string `json:"serial"` // Серийный номер отозванного сертификата
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Типы событий ленты изменений секретов.
+const (
+	SecretEventCreated  = "created"  // Секрет создан
+	SecretEventUpdated  = "updated"  // Секрет изменён или восстановлен из истории версий
+	SecretEventDeleted  = "deleted"  // Секрет перемещён в корзину
+	SecretEventRestored = "restored" // Секрет возвращён из корзины
+	SecretEventPurged   = "purged"   // Секрет окончательно удалён из корзины
+	// SecretEventReset — изменения после места возобновления ленты уже удалены из журнала:
+	// клиенту нужно заново получить все секреты. ID события — ID последней записи журнала пользователя.
+	SecretEventReset = "reset"
+)
+
+// SecretEventDTO — изменение секрета пользователя в ленте синхронизации.
+// Данные секрета в событие не попадают: клиент запрашивает их отдельно.
+type SecretEventDTO struct {
+	ID       uint64    `json:"id"`                // ID записи в журнале изменений; у изменений одного пользователя растёт в порядке фиксации
+	Type     string    `json:"type"`              // Тип события (см. SecretEvent*)
+	UserID   uint64    `json:"-"`                 // ID владельца секрета
+	SecretID uint64    `json:"secret_id"`         // ID секрета
+	Version  int       `json:"version,omitempty"` // Номер версии секрета после изменения
+	Time     time.Time `json:"time"`              // Когда произошло изменение
+}
+
+// SecretChangeFilterDTO — условия выборки из журнала изменений секретов пользователя.
+// Нулевые значения полей не ограничивают выборку.
+type SecretChangeFilterDTO struct {
+	AfterID uint64    // Только записи с ID больше AfterID
+	Since   time.Time // Только изменения, случившиеся не раньше Since
+	Limit   int       // Максимальное количество записей
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// CreateFolderDTO используется для создания новой папки.
+type CreateFolderDTO struct {
+	UserID   uint64  `json:"-"`                                      // ID владельца (берётся из токена)
+	ParentID *uint64 `json:"parent_id"`                              // ID родительской папки (nil — корень)
+	Name     string  `json:"name" validate:"required,min=1,max=100"` // Название папки
+}
+
+// ReadFolderDTO используется для возврата папки клиенту.
+type ReadFolderDTO struct {
+	ID        uint64    `json:"id"`         // ID папки
+	UserID    uint64    `json:"user_id"`    // ID владельца
+	ParentID  *uint64   `json:"parent_id"`  // ID родительской папки (nil — корень)
+	Name      string    `json:"name"`       // Название папки
+	CreatedAt time.Time `json:"created_at"` // Когда создана
+	UpdatedAt time.Time `json:"updated_at"` // Когда обновлена
+}
+
+// RenameFolderDTO используется для переименования папки.
+type RenameFolderDTO struct {
+	Name string `json:"name" validate:"required,min=1,max=100"` // Новое название папки
+}
+
+// MoveFolderDTO используется для перемещения папки в другую родительскую папку.
+type MoveFolderDTO struct {
+	ParentID *uint64 `json:"parent_id"` // ID новой родительской папки (nil — корень)
+}
@@ -0,0 +1,37 @@
+package models
+
+// Статусы проверок готовности.
+const (
+	HealthStatusOK          = "ok"          // Зависимость доступна
+	HealthStatusUnavailable = "unavailable" // Зависимость недоступна или в нерабочем состоянии
+)
+
+// ReadinessDTO — ответ проверки готовности сервера принимать запросы.
+type ReadinessDTO struct {
+	Status     string             `json:"status"`     // ok, если все проверки прошли, иначе unavailable
+	Database   HealthCheckDTO     `json:"database"`   // Доступность базы данных
+	Migrations MigrationStatusDTO `json:"migrations"` // Состояние миграций схемы
+	Keys       KeyStatusDTO       `json:"keys"`       // Состояние ключей и сертификатов сервера
+}
+
+// HealthCheckDTO — результат проверки одной зависимости.
+type HealthCheckDTO struct {
+	Status    string `json:"status"`          // ok или unavailable
+	LatencyMS int64  `json:"latency_ms"`      // Время проверки в миллисекундах
+	Error     string `json:"error,omitempty"` // Причина недоступности
+}
+
+// MigrationStatusDTO — версия схемы базы данных, применённая миграциями.
+type MigrationStatusDTO struct {
+	Status  string `json:"status"`          // ok, если версия известна и последняя миграция завершена
+	Version uint   `json:"version"`         // Номер последней применённой миграции
+	Dirty   bool   `json:"dirty"`           // Последняя миграция прервана и требует ручного исправления
+	Error   string `json:"error,omitempty"` // Причина, по которой версию не удалось получить
+}
+
+// KeyStatusDTO — состояние ключей, которыми сервер подписывает токены и защищает соединения:
+// секретов подписи токенов, сертификата TLS и, если включены сертификаты устройств, их центра сертификации.
+type KeyStatusDTO struct {
+	Status string `json:"status"`          // ok, если все ключи загружены и сертификаты действительны
+	Error  string `json:"error,omitempty"` // Перечень отсутствующих или недействительных ключей
+}
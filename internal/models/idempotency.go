@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// IdempotencyRecordDTO — сохранённый ответ на запрос с заголовком Idempotency-Key.
+// Пока исходный запрос выполняется, StatusCode равен нулю.
+type IdempotencyRecordDTO struct {
+	UserID      uint64    // ID пользователя
+	Key         string    // Значение заголовка Idempotency-Key
+	RequestHash string    // SHA-256 метода, пути и тела исходного запроса в hex
+	StatusCode  int       // HTTP-статус сохранённого ответа (0 — запрос ещё выполняется)
+	Response    []byte    // Тело сохранённого ответа
+	CreatedAt   time.Time // Когда получен исходный запрос
+}
@@ -4,9 +4,24 @@ import "time"
 
 // CreateSecretDTO используется для создания нового секрета.
 type CreateSecretDTO struct {
-	UserID uint64        // ID владельца
-	Title  string        // Название секрета
-	Data   SecretDataDTO // Полезные данные (json)
+	UserID   uint64        // ID владельца
+	Title    string        `validate:"required,max=100"` // Название секрета
+	Data     SecretDataDTO // Полезные данные (json)
+	FolderID *uint64       `json:"folder_id,omitempty"`                                // ID папки (nil — вне папок)
+	Tags     []string      `json:"tags,omitempty" validate:"max=20,dive,min=1,max=50"` // Теги секрета
+	Device   string        `json:"-"`                                                  // Устройство, с которого создан секрет
+}
+
+// UpdateSecretDTO используется для изменения секрета.
+// Предыдущее состояние секрета сохраняется в истории версий.
+type UpdateSecretDTO struct {
+	ID       uint64        `json:"-"`                                                  // ID секрета
+	UserID   uint64        `json:"-"`                                                  // ID владельца
+	Title    string        `json:"title" validate:"required,max=100"`                  // Название секрета
+	Data     SecretDataDTO `json:"data"`                                               // Полезные данные (json)
+	FolderID *uint64       `json:"folder_id,omitempty"`                                // ID папки (nil — вне папок)
+	Tags     []string      `json:"tags,omitempty" validate:"max=20,dive,min=1,max=50"` // Теги секрета
+	Device   string        `json:"-"`                                                  // Устройство, с которого изменён секрет
 }
 
 // ReadSecretDTO используется для возврата секрета клиенту.
@@ -15,10 +30,82 @@ type ReadSecretDTO struct {
 	UserID    uint64        `json:"user_id"`    // ID владельца
 	Title     string        `json:"title"`      // Название секрета
 	Data      SecretDataDTO `json:"data"`       // Данные секрета
+	FolderID  *uint64       `json:"folder_id"`  // ID папки (nil — вне папок)
+	Tags      []string      `json:"tags"`       // Теги секрета
+	Version   int           `json:"version"`    // Номер версии секрета
 	CreatedAt time.Time     `json:"created_at"` // Когда создан
 	UpdatedAt time.Time     `json:"updated_at"` // Когда обновлён
 }
 
+// SecretSummaryDTO — метаданные секрета без полезных данных.
+// Используется в списках, чтобы пароли и реквизиты карт не попадали в ответ без явного запроса.
+type SecretSummaryDTO struct {
+	ID        uint64    `json:"id"`         // ID секрета
+	Title     string    `json:"title"`      // Название секрета
+	Type      string    `json:"type"`       // Тип секрета (см. SecretType*)
+	FolderID  *uint64   `json:"folder_id"`  // ID папки (nil — вне папок)
+	Tags      []string  `json:"tags"`       // Теги секрета
+	Version   int       `json:"version"`    // Номер версии секрета
+	CreatedAt time.Time `json:"created_at"` // Когда создан
+	UpdatedAt time.Time `json:"updated_at"` // Когда обновлён
+}
+
+// SecretSummaryPageDTO — страница списка метаданных секретов.
+type SecretSummaryPageDTO struct {
+	Items      []SecretSummaryDTO `json:"items"`          // Секреты страницы
+	Next       string             `json:"next,omitempty"` // Ссылка на следующую страницу (пусто — страница последняя)
+	NextCursor string             `json:"-"`              // Закодированный курсор следующей страницы
+}
+
+// Типы секретов, вычисляемые по заполненному полю SecretDataDTO.
+const (
+	SecretTypeLogin  = "login"  // Пара логин/пароль
+	SecretTypeCard   = "card"   // Банковская карта
+	SecretTypeText   = "text"   // Произвольный текст
+	SecretTypeBinary = "binary" // Бинарные данные
+)
+
+// Поля сортировки списка секретов. Префикс "-" означает сортировку по убыванию.
+const (
+	SecretSortCreatedAt = "created_at"
+	SecretSortUpdatedAt = "updated_at"
+	SecretSortTitle     = "title"
+)
+
+// SecretCursorTimeLayout — формат значения даты в курсоре списка секретов (с точностью до микросекунд, как в PostgreSQL).
+const SecretCursorTimeLayout = "2006-01-02T15:04:05.999999"
+
+// SecretFilterDTO задаёт условия отбора, сортировку и постраничную выборку секретов пользователя.
+// Пустые значения полей означают отсутствие соответствующего условия.
+type SecretFilterDTO struct {
+	FolderID    *uint64          // ID папки: nil — без фильтра, 0 — только секреты вне папок
+	Tag         string           // Тег
+	Query       string           `validate:"max=100"`                                // Подстрока названия (без учёта регистра)
+	Type        string           `validate:"omitempty,oneof=login card text binary"` // Тип секрета
+	CreatedFrom *time.Time       // Создан не раньше
+	CreatedTo   *time.Time       // Создан раньше
+	UpdatedFrom *time.Time       // Обновлён не раньше
+	UpdatedTo   *time.Time       // Обновлён раньше
+	Sort        string           `validate:"omitempty,oneof=created_at -created_at updated_at -updated_at title -title"` // Поле сортировки
+	Limit       int              `validate:"gte=0,lte=500"`                                                              // Размер страницы: 0 — без ограничения
+	After       *SecretCursorDTO // Курсор: выборка начинается после указанной записи
+}
+
+// SecretCursorDTO описывает позицию в отсортированном списке секретов для постраничной выборки.
+// Клиенту передаётся в закодированном виде (см. utils.EncodeCursor).
+type SecretCursorDTO struct {
+	Sort  string `json:"s"`  // Сортировка, для которой выдан курсор
+	Value string `json:"v"`  // Значение поля сортировки последней записи страницы
+	ID    uint64 `json:"id"` // ID последней записи страницы
+}
+
+// SecretPageDTO — страница выгрузки секретов вместе с данными.
+type SecretPageDTO struct {
+	Items      []ReadSecretDTO `json:"items"`          // Секреты страницы
+	Next       string          `json:"next,omitempty"` // Ссылка на следующую страницу (пусто — страница последняя)
+	NextCursor string          `json:"-"`              // Закодированный курсор следующей страницы
+}
+
 // SecretDataDTO представляет собой обёртку для различных типов приватных данных.
 // Сохраняется как JSONB в базе.
 type SecretDataDTO struct {
@@ -30,8 +117,10 @@ type SecretDataDTO struct {
 
 // LoginPasswordData содержит логин и пароль.
 type LoginPasswordData struct {
-	Login    string `json:"login"`    // Логин
-	Password string `json:"password"` // Пароль
+	Login    string `json:"login"`           // Логин
+	Password string `json:"password"`        // Пароль
+	URL      string `json:"url,omitempty"`   // Адрес сайта, к которому относится пара
+	Notes    string `json:"notes,omitempty"` // Заметки к записи
 }
 
 // CardData содержит данные банковской карты.
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// TrashedSecretDTO — метаданные секрета, перемещённого в корзину, без полезных данных.
+type TrashedSecretDTO struct {
+	ID        uint64     `json:"id"`         // ID секрета
+	Title     string     `json:"title"`      // Название секрета
+	Type      string     `json:"type"`       // Тип секрета (см. SecretType*)
+	FolderID  *uint64    `json:"folder_id"`  // ID папки (nil — вне папок)
+	Tags      []string   `json:"tags"`       // Теги секрета
+	Version   int        `json:"version"`    // Номер версии секрета
+	CreatedAt time.Time  `json:"created_at"` // Когда создан
+	UpdatedAt time.Time  `json:"updated_at"` // Когда обновлён
+	DeletedAt time.Time  `json:"deleted_at"` // Когда перемещён в корзину
+	PurgeAt   *time.Time `json:"purge_at"`   // Когда будет удалён окончательно (nil — хранится бессрочно)
+}
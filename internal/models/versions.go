@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// SecretVersionDTO — метаданные предыдущей версии секрета без полезных данных.
+type SecretVersionDTO struct {
+	Version    int       `json:"version"`     // Номер версии
+	Title      string    `json:"title"`       // Название секрета в этой версии
+	Type       string    `json:"type"`        // Тип секрета в этой версии
+	Device     string    `json:"device"`      // Устройство, с которого была сохранена версия
+	CreatedAt  time.Time `json:"created_at"`  // Когда версия была сохранена
+	ArchivedAt time.Time `json:"archived_at"` // Когда версия была заменена следующей
+}
+
+// ReadSecretVersionDTO — предыдущая версия секрета вместе с данными.
+type ReadSecretVersionDTO struct {
+	SecretID   uint64        `json:"secret_id"`   // ID секрета
+	Version    int           `json:"version"`     // Номер версии
+	Title      string        `json:"title"`       // Название секрета
+	Data       SecretDataDTO `json:"data"`        // Данные секрета
+	FolderID   *uint64       `json:"folder_id"`   // ID папки (nil — вне папок)
+	Tags       []string      `json:"tags"`        // Теги секрета
+	Device     string        `json:"device"`      // Устройство, с которого была сохранена версия
+	CreatedAt  time.Time     `json:"created_at"`  // Когда версия была сохранена
+	ArchivedAt time.Time     `json:"archived_at"` // Когда версия была заменена следующей
+}
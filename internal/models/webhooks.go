@@ -0,0 +1,90 @@
+package models
+
+import "time"
+
+// События, на которые можно подписать вебхук.
+const (
+	WebhookEventSecretChanged     = "secret.changed"      // Секрет создан, изменён, удалён или восстановлен
+	WebhookEventLoginNewDevice    = "login.new_device"    // Вход с устройства, с которого пользователь раньше не входил
+	WebhookEventLoginFailureBurst = "login.failure_burst" // Серия неудачных попыток входа за короткое время
+)
+
+// Статусы доставки вебхука.
+const (
+	WebhookDeliveryPending   = "pending"   // Ожидает отправки (в том числе повторной)
+	WebhookDeliveryDelivered = "delivered" // Получатель ответил статусом 2xx
+	WebhookDeliveryFailed    = "failed"    // Попытки исчерпаны
+)
+
+// CreateWebhookDTO используется для регистрации вебхука.
+type CreateWebhookDTO struct {
+	UserID uint64   `json:"-"`                                                                                                     // ID владельца (берётся из токена)
+	URL    string   `json:"url" validate:"required,max=2048,http_url"`                                                             // Адрес, на который отправляются события
+	Events []string `json:"events" validate:"required,min=1,max=3,dive,oneof=secret.changed login.new_device login.failure_burst"` // События (см. WebhookEvent*)
+}
+
+// ReadWebhookDTO используется для возврата вебхука клиенту.
+// Ключ подписи возвращается только при регистрации.
+type ReadWebhookDTO struct {
+	ID        uint64    `json:"id"`               // ID вебхука
+	UserID    uint64    `json:"user_id"`          // ID владельца
+	URL       string    `json:"url"`              // Адрес получателя
+	Events    []string  `json:"events"`           // События (см. WebhookEvent*)
+	Secret    string    `json:"secret,omitempty"` // Ключ подписи HMAC-SHA256
+	CreatedAt time.Time `json:"created_at"`       // Когда зарегистрирован
+}
+
+// WebhookDeliveryDTO — запись журнала доставки вебхука.
+type WebhookDeliveryDTO struct {
+	ID             uint64     `json:"id"`                        // ID доставки
+	WebhookID      uint64     `json:"webhook_id"`                // ID вебхука
+	Event          string     `json:"event"`                     // Событие (см. WebhookEvent*)
+	Status         string     `json:"status"`                    // Статус (см. WebhookDelivery*)
+	Attempts       int        `json:"attempts"`                  // Сколько попыток сделано
+	ResponseStatus *int       `json:"response_status,omitempty"` // HTTP-статус последнего ответа получателя
+	Error          string     `json:"error,omitempty"`           // Ошибка последней попытки
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"` // Когда будет следующая попытка (для pending)
+	CreatedAt      time.Time  `json:"created_at"`                // Когда событие поставлено в очередь
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`    // Когда доставлено
+}
+
+// WebhookTaskDTO — доставка, взятая в работу отправителем, вместе с адресом и ключом подписи вебхука.
+type WebhookTaskDTO struct {
+	ID       uint64 // ID доставки
+	URL      string // Адрес получателя
+	Secret   string // Ключ подписи
+	Event    string // Событие
+	Payload  []byte // Тело запроса (WebhookPayloadDTO в JSON)
+	Attempts int    // Сколько попыток сделано до этой
+}
+
+// WebhookAttemptDTO — результат попытки доставки.
+type WebhookAttemptDTO struct {
+	Status         string    // Новый статус доставки (см. WebhookDelivery*)
+	ResponseStatus int       // HTTP-статус ответа получателя (0 — ответа нет)
+	Error          string    // Ошибка попытки
+	NextAttemptAt  time.Time // Когда повторить попытку (для pending)
+}
+
+// WebhookPayloadDTO — тело запроса, которое получает вебхук.
+type WebhookPayloadDTO struct {
+	Event  string    `json:"event"`   // Событие (см. WebhookEvent*)
+	UserID uint64    `json:"user_id"` // Пользователь, с которым связано событие
+	Time   time.Time `json:"time"`    // Когда произошло событие
+	Data   any       `json:"data"`    // Подробности события: SecretEventDTO или LoginAlertDTO
+}
+
+// LoginAlertDTO — подробности событий login.new_device и login.failure_burst.
+type LoginAlertDTO struct {
+	Device   string `json:"device,omitempty"`   // Устройство, с которого выполнен вход
+	IP       string `json:"ip,omitempty"`       // IP-адрес клиента
+	Failures int    `json:"failures,omitempty"` // Неудачных попыток за окно наблюдения (login.failure_burst)
+}
+
+// LoginAttemptDTO — попытка входа пользователя, сохраняемая для обнаружения подозрительных входов.
+type LoginAttemptDTO struct {
+	UserID  uint64 // ID пользователя
+	Success bool   // Успешна ли попытка
+	Device  string // Устройство клиента (X-Device-ID)
+	IP      string // IP-адрес клиента
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// SecretAuditRepositoryImpl — реализация интерфейса SecretAuditRepository для хранения журнала аудита в PostgreSQL.
+type SecretAuditRepositoryImpl struct {
+	db     *sql.DB        // соединение с базой данных
+	cfg    *config.Config // конфигурация приложения
+	logger *logger.Logger // логгер
+}
+
+// NewSecretAuditRepositoryImpl создаёт новый экземпляр SecretAuditRepositoryImpl.
+// Работает через общий пул соединений db (см. database.Open).
+func NewSecretAuditRepositoryImpl(db *sql.DB, cfg *config.Config) *SecretAuditRepositoryImpl {
+	return &SecretAuditRepositoryImpl{
+		db:     db,
+		cfg:    cfg,
+		logger: logger.NewLogger(),
+	}
+}
+
+// Create добавляет запись в журнал аудита секретов.
+func (r *SecretAuditRepositoryImpl) Create(ctx context.Context, dto models.CreateSecretAuditDTO) error {
+	ctx, span := tracing.StartDB(ctx, "secret_audit.create")
+	defer span.End()
+
+	query := `
+		insert into secret_audit (user_id, secret_id, action, ip, user_agent)
+		values ($1, $2, $3, $4, $5);
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, dto.UserID, dto.SecretID, dto.Action, dto.IP, dto.UserAgent)
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при записи в журнал аудита", zap.Uint64("secret_id", dto.SecretID), zap.String("action", dto.Action), zap.Error(err))
+		return err
+	}
+
+	r.logger.For(ctx).Info("Действие с секретом записано в журнал аудита", zap.Uint64("secret_id", dto.SecretID), zap.String("action", dto.Action))
+	return nil
+}
+
+// GetBySecret возвращает записи журнала аудита по секрету пользователя, начиная с последних.
+func (r *SecretAuditRepositoryImpl) GetBySecret(ctx context.Context, userID, secretID uint64) ([]models.ReadSecretAuditDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "secret_audit.get_by_secret")
+	defer span.End()
+
+	query := `
+		select id, secret_id, action, ip, user_agent, created_at
+		from secret_audit
+		where user_id = $1 and secret_id = $2
+		order by created_at desc, id desc;
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID, secretID)
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при получении журнала аудита", zap.Uint64("secret_id", secretID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []models.ReadSecretAuditDTO
+	for rows.Next() {
+		var dto models.ReadSecretAuditDTO
+		if err := rows.Scan(&dto.ID, &dto.SecretID, &dto.Action, &dto.IP, &dto.UserAgent, &dto.CreatedAt); err != nil {
+			r.logger.For(ctx).Error("Ошибка при чтении записи журнала аудита", zap.Error(err))
+			return nil, err
+		}
+		records = append(records, dto)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.For(ctx).Error("Ошибка при чтении журнала аудита", zap.Uint64("secret_id", secretID), zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.For(ctx).Info("Журнал аудита секрета получен", zap.Uint64("secret_id", secretID), zap.Int("count", len(records)))
+	return records, nil
+}
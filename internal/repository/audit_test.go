@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretAuditRepositoryImpl_Create(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &SecretAuditRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	dto := models.CreateSecretAuditDTO{UserID: 1, SecretID: 2, Action: models.SecretActionReveal, IP: "10.0.0.1", UserAgent: "cli"}
+	query := regexp.QuoteMeta(`
+		insert into secret_audit (user_id, secret_id, action, ip, user_agent)
+		values ($1, $2, $3, $4, $5);
+	`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(uint64(1), uint64(2), "reveal", "10.0.0.1", "cli").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		assert.NoError(t, repo.Create(context.Background(), dto))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(assert.AnError)
+
+		assert.Error(t, repo.Create(context.Background(), dto))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSecretAuditRepositoryImpl_GetBySecret(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &SecretAuditRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	query := regexp.QuoteMeta(`
+		select id, secret_id, action, ip, user_agent, created_at
+		from secret_audit
+		where user_id = $1 and secret_id = $2
+		order by created_at desc, id desc;
+	`)
+	now := time.Now()
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(uint64(1), uint64(2)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "secret_id", "action", "ip", "user_agent", "created_at"}).
+				AddRow(uint64(5), uint64(2), "reveal", "10.0.0.1", "cli", now))
+
+		records, err := repo.GetBySecret(context.Background(), 1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.ReadSecretAuditDTO{
+			{ID: 5, SecretID: 2, Action: "reveal", IP: "10.0.0.1", UserAgent: "cli", CreatedAt: now},
+		}, records)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Query_error", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(assert.AnError)
+
+		records, err := repo.GetBySecret(context.Background(), 1, 2)
+		assert.Error(t, err)
+		assert.Nil(t, records)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
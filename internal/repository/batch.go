@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// batchSavepoint — точка сохранения, в которой выполняется каждая операция неатомарного пакета.
+const batchSavepoint = "batch_op"
+
+// ApplyBatch выполняет операции пользователя над секретами в одной транзакции.
+//
+// В неатомарном режиме каждая операция выполняется в своей точке сохранения: ошибка откатывает
+// только эту операцию, остальные фиксируются вместе в конце. В атомарном режиме первая ошибка
+// откатывает всю транзакцию, а остальные операции (выполненные и ещё не выполненные) получают ErrBatchRolledBack.
+// Изменение секрета, как и одиночное Update, сохраняет предыдущее состояние в истории версий.
+// Внутри TxManager.WithinTx пакет выполняется во внешней транзакции.
+func (r *SecretRepositoryImpl) ApplyBatch(ctx context.Context, userID uint64, ops []models.BatchOperationDTO, device string, atomic bool) ([]models.BatchOutcome, error) {
+	ctx, span := tracing.StartDB(ctx, "secrets.apply_batch")
+	defer span.End()
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		r.logger.For(ctx).Error("Не удалось начать транзакцию пакета", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	outcomes := make([]models.BatchOutcome, len(ops))
+	for i, op := range ops {
+		if !atomic {
+			if _, err := tx.ExecContext(ctx, "savepoint "+batchSavepoint); err != nil {
+				r.logger.For(ctx).Error("Не удалось создать точку сохранения", zap.Int("index", i), zap.Error(err))
+				return nil, err
+			}
+		}
+
+		outcome, err := r.applyOp(ctx, tx.Tx, userID, op, device)
+		if err == nil {
+			outcomes[i] = outcome
+			if !atomic {
+				if _, err := tx.ExecContext(ctx, "release savepoint "+batchSavepoint); err != nil {
+					r.logger.For(ctx).Error("Не удалось освободить точку сохранения", zap.Int("index", i), zap.Error(err))
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		outcomes[i] = models.BatchOutcome{ID: op.ID, Err: err}
+		if atomic {
+			for j := range outcomes {
+				if j != i {
+					outcomes[j] = models.BatchOutcome{Err: ErrBatchRolledBack}
+				}
+			}
+			r.logger.For(ctx).Warn("Пакет операций отменён", zap.Uint64("user_id", userID), zap.Int("index", i), zap.Error(err))
+			return outcomes, nil
+		}
+		if _, err := tx.ExecContext(ctx, "rollback to savepoint "+batchSavepoint); err != nil {
+			r.logger.For(ctx).Error("Не удалось откатиться к точке сохранения", zap.Int("index", i), zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.For(ctx).Error("Не удалось зафиксировать пакет операций", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.For(ctx).Info("Пакет операций выполнен", zap.Uint64("user_id", userID), zap.Int("count", len(ops)))
+	return outcomes, nil
+}
+
+// applyOp выполняет одну операцию пакета внутри транзакции tx.
+func (r *SecretRepositoryImpl) applyOp(ctx context.Context, tx *sql.Tx, userID uint64, op models.BatchOperationDTO, device string) (models.BatchOutcome, error) {
+	switch op.Op {
+	case models.BatchOpCreate:
+		id, err := r.insert(ctx, tx, models.CreateSecretDTO{
+			UserID:   userID,
+			Title:    op.Title,
+			Data:     op.Data,
+			FolderID: op.FolderID,
+			Tags:     op.Tags,
+			Device:   device,
+		})
+		return models.BatchOutcome{ID: id, Version: 1}, err
+	case models.BatchOpUpdate:
+		secret, err := r.update(ctx, tx, models.UpdateSecretDTO{
+			ID:       op.ID,
+			UserID:   userID,
+			Title:    op.Title,
+			Data:     op.Data,
+			FolderID: op.FolderID,
+			Tags:     op.Tags,
+			Device:   device,
+		})
+		if err != nil {
+			return models.BatchOutcome{}, err
+		}
+		return models.BatchOutcome{ID: secret.ID, Version: secret.Version}, nil
+	case models.BatchOpDelete:
+		return models.BatchOutcome{ID: op.ID}, r.execOne(ctx, tx, moveToTrashQuery, op.ID, userID)
+	default:
+		return models.BatchOutcome{}, fmt.Errorf("unknown batch operation %q", op.Op)
+	}
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+func TestSecretRepositoryImpl_ApplyBatch(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	now := time.Now()
+
+	insertQuery := regexp.QuoteMeta(`insert into secrets (user_id, title, data, folder_id, tags, device)`)
+	lockQuery := regexp.QuoteMeta(`select version`)
+	archiveQuery := regexp.QuoteMeta(`insert into secret_versions`)
+	updateQuery := regexp.QuoteMeta(`update secrets
+		set title = $1`)
+	deleteQuery := regexp.QuoteMeta(`update secrets
+		set deleted_at = now()`)
+
+	ops := []models.BatchOperationDTO{
+		{Op: models.BatchOpCreate, Title: "Mail", Data: models.SecretDataDTO{Text: ptr("note")}},
+		{Op: models.BatchOpDelete, ID: 3},
+		{Op: models.BatchOpUpdate, ID: 7, Title: "VPN", Data: models.SecretDataDTO{Text: ptr("new")}},
+	}
+
+	t.Run("Partial", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("savepoint batch_op").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(insertQuery).
+			WithArgs(uint64(42), "Mail", sqlmock.AnyArg(), nil, []byte("[]"), "laptop").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uint64(10)))
+		mock.ExpectExec("release savepoint batch_op").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("savepoint batch_op").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(deleteQuery).
+			WithArgs(uint64(3), uint64(42)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("rollback to savepoint batch_op").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("savepoint batch_op").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(lockQuery).
+			WithArgs(uint64(7), uint64(42)).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+		mock.ExpectExec(archiveQuery).WithArgs(uint64(7)).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(updateQuery).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "user_id", "title", "data", "folder_id", "tags", "version", "created_at", "updated_at",
+			}).AddRow(uint64(7), uint64(42), "VPN", []byte(`{"text":"new"}`), nil, []byte(`[]`), 2, now, now))
+		mock.ExpectExec("release savepoint batch_op").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		outcomes, err := repo.ApplyBatch(context.Background(), 42, ops, "laptop", false)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.BatchOutcome{
+			{ID: 10, Version: 1},
+			{ID: 3, Err: ErrNotFound},
+			{ID: 7, Version: 2},
+		}, outcomes)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Atomic_rollback", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertQuery).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uint64(10)))
+		mock.ExpectExec(deleteQuery).
+			WithArgs(uint64(3), uint64(42)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		outcomes, err := repo.ApplyBatch(context.Background(), 42, ops, "laptop", true)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.BatchOutcome{
+			{Err: ErrBatchRolledBack},
+			{ID: 3, Err: ErrNotFound},
+			{Err: ErrBatchRolledBack},
+		}, outcomes)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Commit_error", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteQuery).
+			WithArgs(uint64(3), uint64(42)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit().WillReturnError(assert.AnError)
+
+		outcomes, err := repo.ApplyBatch(context.Background(), 42, ops[1:2], "", true)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, outcomes)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Begin_error", func(t *testing.T) {
+		mock.ExpectBegin().WillReturnError(assert.AnError)
+
+		_, err := repo.ApplyBatch(context.Background(), 42, ops, "", false)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,68 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/database"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/shekshuev/gophkeeper/internal/repository/repotest"
+)
+
+// newBackend собирает репозитории пакета repository поверх db.
+func newBackend(db *sql.DB, cfg *config.Config) repotest.Backend {
+	return repotest.Backend{
+		Users:   repository.NewUserRepositoryImpl(db, cfg),
+		Secrets: repository.NewSecretRepositoryImpl(db, cfg),
+		Folders: repository.NewFolderRepositoryImpl(db, cfg),
+		Devices: repository.NewDeviceCertificateRepositoryImpl(db, cfg),
+		Tx:      repository.NewTxManagerImpl(db, cfg),
+	}
+}
+
+// TestSQLite проверяет хранилище SQLite в новой базе во временном каталоге для каждого подтеста.
+// Схема создаётся встроенными миграциями, как при запуске сервера.
+func TestSQLite(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Backend {
+		cfg := &config.Config{
+			DatabaseDSN:           database.SQLiteScheme + filepath.Join(t.TempDir(), "gophkeeper.db"),
+			DatabaseMaxOpenConns:  1,
+			DatabaseTxMaxAttempts: 3,
+		}
+		db, err := database.Open(cfg)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		require.NoError(t, database.MigrateSQLite(context.Background(), db))
+		return newBackend(db, cfg)
+	})
+}
+
+// TestPostgres проверяет хранилище PostgreSQL в базе из TEST_DATABASE_DSN с применёнными миграциями.
+// Перед каждым подтестом все таблицы очищаются, поэтому указывайте отдельную тестовую базу.
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("set TEST_DATABASE_DSN to test the PostgreSQL backend")
+	}
+	cfg := &config.Config{
+		DatabaseDSN:           dsn,
+		DatabaseMaxOpenConns:  4,
+		DatabaseTxMaxAttempts: 3,
+	}
+	db, err := database.Open(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	repotest.Run(t, func(t *testing.T) repotest.Backend {
+		_, err := db.Exec("truncate table users restart identity cascade;")
+		require.NoError(t, err)
+		return newBackend(db, cfg)
+	})
+}
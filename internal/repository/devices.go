@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// deviceCertificateColumns — колонки device_certificates в порядке scanDeviceCertificate.
+const deviceCertificateColumns = "id, user_id, device, serial, thumbprint, created_at, expires_at, revoked_at"
+
+// DeviceCertificateRepositoryImpl — реализация интерфейса DeviceCertificateRepository для хранения сертификатов
+// устройств в PostgreSQL или SQLite.
+type DeviceCertificateRepositoryImpl struct {
+	db      *sql.DB        // соединение с базой данных
+	cfg     *config.Config // конфигурация приложения
+	dialect dialect        // диалект SQL базы
+	logger  *logger.Logger // логгер
+}
+
+// NewDeviceCertificateRepositoryImpl создаёт новый экземпляр DeviceCertificateRepositoryImpl.
+// Работает через общий пул соединений db (см. database.Open).
+func NewDeviceCertificateRepositoryImpl(db *sql.DB, cfg *config.Config) *DeviceCertificateRepositoryImpl {
+	return &DeviceCertificateRepositoryImpl{
+		db:      db,
+		cfg:     cfg,
+		dialect: dialectOf(cfg),
+		logger:  logger.NewLogger(),
+	}
+}
+
+// Create сохраняет сертификат, выданный устройству.
+func (r *DeviceCertificateRepositoryImpl) Create(ctx context.Context, dto models.CreateDeviceCertificateDTO) (*models.DeviceCertificateDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "device_certificates.create")
+	defer span.End()
+
+	query := `
+		insert into device_certificates (user_id, device, serial, thumbprint, expires_at)
+		values ($1, $2, $3, $4, $5)
+		returning ` + deviceCertificateColumns + `;
+	`
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, dto.UserID, dto.Device, dto.Serial, dto.Thumbprint, r.dialect.timeArg(dto.ExpiresAt))
+	cert, err := r.scanDeviceCertificate(row)
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при сохранении сертификата устройства", zap.Uint64("user_id", dto.UserID), zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.For(ctx).Info("Сертификат устройства сохранён", zap.Uint64("user_id", dto.UserID), zap.String("serial", dto.Serial))
+	return cert, nil
+}
+
+// GetBySerial возвращает сертификат по серийному номеру.
+// Если сертификат не найден — возвращает ErrNotFound.
+func (r *DeviceCertificateRepositoryImpl) GetBySerial(ctx context.Context, serial string) (*models.DeviceCertificateDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "device_certificates.get_by_serial")
+	defer span.End()
+
+	query := `
+		select ` + deviceCertificateColumns + `
+		from device_certificates
+		where serial = $1;
+	`
+	cert, err := r.scanDeviceCertificate(conn(ctx, r.db).QueryRowContext(ctx, query, serial))
+	if err == sql.ErrNoRows {
+		r.logger.For(ctx).Warn("Сертификат устройства не найден", zap.String("serial", serial))
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при получении сертификата устройства", zap.String("serial", serial), zap.Error(err))
+		return nil, err
+	}
+	return cert, nil
+}
+
+// GetAllByUser возвращает сертификаты устройств пользователя, начиная с новых.
+func (r *DeviceCertificateRepositoryImpl) GetAllByUser(ctx context.Context, userID uint64) ([]models.DeviceCertificateDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "device_certificates.get_all_by_user")
+	defer span.End()
+
+	query := `
+		select ` + deviceCertificateColumns + `
+		from device_certificates
+		where user_id = $1
+		order by created_at desc, id desc;
+	`
+	certs, err := r.query(ctx, query, userID)
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при получении сертификатов устройств пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.For(ctx).Info("Сертификаты устройств пользователя получены", zap.Uint64("user_id", userID), zap.Int("count", len(certs)))
+	return certs, nil
+}
+
+// Revoke отзывает сертификат и возвращает его. Время отзыва уже отозванного сертификата не меняется.
+// Если сертификат не найден — возвращает ErrNotFound.
+func (r *DeviceCertificateRepositoryImpl) Revoke(ctx context.Context, serial string) (*models.DeviceCertificateDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "device_certificates.revoke")
+	defer span.End()
+
+	query := `
+		update device_certificates
+		set revoked_at = coalesce(revoked_at, now())
+		where serial = $1
+		returning ` + deviceCertificateColumns + `;
+	`
+	cert, err := r.scanDeviceCertificate(conn(ctx, r.db).QueryRowContext(ctx, query, serial))
+	if err == sql.ErrNoRows {
+		r.logger.For(ctx).Warn("Сертификат устройства для отзыва не найден", zap.String("serial", serial))
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при отзыве сертификата устройства", zap.String("serial", serial), zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.For(ctx).Info("Сертификат устройства отозван", zap.String("serial", serial), zap.Uint64("user_id", cert.UserID))
+	return cert, nil
+}
+
+// GetRevoked возвращает отозванные сертификаты, срок действия которых не истёк к моменту now, начиная с первых отозванных.
+func (r *DeviceCertificateRepositoryImpl) GetRevoked(ctx context.Context, now time.Time) ([]models.DeviceCertificateDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "device_certificates.get_revoked")
+	defer span.End()
+
+	query := `
+		select ` + deviceCertificateColumns + `
+		from device_certificates
+		where revoked_at is not null and expires_at > $1
+		order by revoked_at, id;
+	`
+	certs, err := r.query(ctx, query, r.dialect.timeArg(now))
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при получении отозванных сертификатов устройств", zap.Error(err))
+		return nil, err
+	}
+	return certs, nil
+}
+
+// query выполняет запрос, возвращающий колонки deviceCertificateColumns, и читает все строки.
+func (r *DeviceCertificateRepositoryImpl) query(ctx context.Context, query string, args ...any) ([]models.DeviceCertificateDTO, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var certs []models.DeviceCertificateDTO
+	for rows.Next() {
+		cert, err := r.scanDeviceCertificate(rows)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, *cert)
+	}
+	return certs, rows.Err()
+}
+
+// scanDeviceCertificate читает сертификат устройства из строки с колонками deviceCertificateColumns.
+func (r *DeviceCertificateRepositoryImpl) scanDeviceCertificate(row interface{ Scan(dest ...any) error }) (*models.DeviceCertificateDTO, error) {
+	var cert models.DeviceCertificateDTO
+	var revokedAt sql.NullTime
+	err := row.Scan(&cert.ID, &cert.UserID, &cert.Device, &cert.Serial, &cert.Thumbprint, &cert.CreatedAt, &cert.ExpiresAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		cert.RevokedAt = &revokedAt.Time
+	}
+	return &cert, nil
+}
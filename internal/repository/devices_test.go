@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceCertificateRepositoryImpl(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &DeviceCertificateRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	ctx := context.Background()
+	columns := []string{"id", "user_id", "device", "serial", "thumbprint", "created_at", "expires_at", "revoked_at"}
+	created := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	expires := created.Add(90 * 24 * time.Hour)
+	revoked := created.Add(time.Hour)
+
+	t.Run("Create", func(t *testing.T) {
+		mock.ExpectQuery("insert into device_certificates \\(user_id, device, serial, thumbprint, expires_at\\)").
+			WithArgs(uint64(1), "laptop", "0a1b", "thumb", expires).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 1, "laptop", "0a1b", "thumb", created, expires, nil))
+
+		cert, err := repo.Create(ctx, models.CreateDeviceCertificateDTO{UserID: 1, Device: "laptop", Serial: "0a1b", Thumbprint: "thumb", ExpiresAt: expires})
+		assert.NoError(t, err)
+		assert.Equal(t, &models.DeviceCertificateDTO{
+			ID: 1, UserID: 1, Device: "laptop", Serial: "0a1b", Thumbprint: "thumb", CreatedAt: created, ExpiresAt: expires,
+		}, cert)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("GetBySerial", func(t *testing.T) {
+		mock.ExpectQuery("select .+ from device_certificates where serial = \\$1").
+			WithArgs("0a1b").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 1, "laptop", "0a1b", "thumb", created, expires, revoked))
+
+		cert, err := repo.GetBySerial(ctx, "0a1b")
+		assert.NoError(t, err)
+		assert.Equal(t, "laptop", cert.Device)
+		assert.Equal(t, &revoked, cert.RevokedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("GetBySerial_not_found", func(t *testing.T) {
+		mock.ExpectQuery("select .+ from device_certificates where serial = \\$1").
+			WithArgs("ffff").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetBySerial(ctx, "ffff")
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("GetAllByUser", func(t *testing.T) {
+		mock.ExpectQuery("select .+ from device_certificates where user_id = \\$1 order by created_at desc, id desc").
+			WithArgs(uint64(1)).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(2, 1, "phone", "0c2d", "thumb2", created, expires, nil).
+				AddRow(1, 1, "laptop", "0a1b", "thumb", created, expires, revoked))
+
+		certs, err := repo.GetAllByUser(ctx, 1)
+		assert.NoError(t, err)
+		assert.Len(t, certs, 2)
+		assert.Equal(t, "phone", certs[0].Device)
+		assert.Nil(t, certs[0].RevokedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		mock.ExpectQuery("update device_certificates set revoked_at = coalesce\\(revoked_at, now\\(\\)\\) where serial = \\$1").
+			WithArgs("0a1b").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 1, "laptop", "0a1b", "thumb", created, expires, revoked))
+
+		cert, err := repo.Revoke(ctx, "0a1b")
+		assert.NoError(t, err)
+		assert.Equal(t, &revoked, cert.RevokedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Revoke_not_found", func(t *testing.T) {
+		mock.ExpectQuery("update device_certificates").
+			WithArgs("ffff").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.Revoke(ctx, "ffff")
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("GetRevoked", func(t *testing.T) {
+		mock.ExpectQuery("select .+ from device_certificates where revoked_at is not null and expires_at > \\$1").
+			WithArgs(created).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 1, "laptop", "0a1b", "thumb", created, expires, revoked))
+
+		certs, err := repo.GetRevoked(ctx, created)
+		assert.NoError(t, err)
+		assert.Len(t, certs, 1)
+		assert.Equal(t, "0a1b", certs[0].Serial)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("GetRevoked_error", func(t *testing.T) {
+		mock.ExpectQuery("select .+ from device_certificates").WillReturnError(assert.AnError)
+
+		_, err := repo.GetRevoked(ctx, created)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/database"
+)
+
+// dialect — диалект SQL базы, с которой работает репозиторий.
+// Запросы репозиториев общие для PostgreSQL и SQLite; диалект подставляет только те части,
+// которые в SQLite записываются иначе. Функцию now() для SQLite регистрирует пакет database.
+type dialect int
+
+const (
+	dialectPostgres dialect = iota // PostgreSQL (драйвер pgx)
+	dialectSQLite                  // SQLite (строка подключения со схемой sqlite://)
+)
+
+// dialectOf возвращает диалект базы из строки подключения cfg.DatabaseDSN.
+func dialectOf(cfg *config.Config) dialect {
+	if database.IsSQLite(cfg.DatabaseDSN) {
+		return dialectSQLite
+	}
+	return dialectPostgres
+}
+
+// jsonArg готовит JSON-документ к передаче параметром запроса. jsonb в PostgreSQL принимает байты,
+// а SQLite должен получить текст: байты сохранились бы как BLOB, с которым не работают функции json_*.
+func (d dialect) jsonArg(raw []byte) any {
+	if d == dialectSQLite {
+		return string(raw)
+	}
+	return raw
+}
+
+// timeArg готовит время к передаче параметром запроса. SQLite хранит время текстом в UTC
+// (см. database.SQLiteTimeLayout) и сравнивает его как строки, поэтому параметр тоже переводится в UTC.
+func (d dialect) timeArg(t time.Time) any {
+	if d == dialectSQLite {
+		return t.UTC()
+	}
+	return t
+}
+
+// hasTag возвращает условие «среди тегов секрета есть значение параметра arg».
+func (d dialect) hasTag(arg string) string {
+	if d == dialectSQLite {
+		return "exists (select 1 from json_each(tags) where value = " + arg + ")"
+	}
+	return "tags ? " + arg
+}
+
+// titleLike возвращает условие совпадения названия с шаблоном LIKE из параметра arg без учёта регистра.
+// LIKE в SQLite не различает регистр только у латиницы.
+func (d dialect) titleLike(arg string) string {
+	if d == dialectSQLite {
+		return "title like " + arg + ` escape '\'`
+	}
+	return "title ilike " + arg
+}
+
+// lockRow возвращает окончание запроса, блокирующее выбранные строки до конца транзакции.
+// Транзакция SQLite сразу берёт блокировку записи всей базы (см. database.Open), поэтому
+// отдельно блокировать строки не нужно.
+func (d dialect) lockRow() string {
+	if d == dialectSQLite {
+		return ""
+	}
+	return "\n\t\tfor update"
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// sqliteError повторяет ошибку драйвера SQLite: расширенный код доступен через метод Code.
+type sqliteError int
+
+func (e sqliteError) Error() string { return fmt.Sprintf("sqlite error %d", int(e)) }
+
+func (e sqliteError) Code() int { return int(e) }
+
+func TestDialectOf(t *testing.T) {
+	assert.Equal(t, dialectSQLite, dialectOf(&config.Config{DatabaseDSN: "sqlite:///tmp/gophkeeper.db"}))
+	assert.Equal(t, dialectPostgres, dialectOf(&config.Config{DatabaseDSN: "postgres://localhost/gophkeeper"}))
+}
+
+func TestSQLiteErrors(t *testing.T) {
+	assert.True(t, isUniqueViolation(sqliteError(sqliteUniqueViolation)))
+	assert.True(t, isUniqueViolation(fmt.Errorf("insert folder: %w", sqliteError(sqlitePrimaryKeyViolation))))
+	assert.False(t, isUniqueViolation(sqliteError(787)))
+	assert.True(t, isRetryable(sqliteError(517)))
+	assert.False(t, isRetryable(sqliteError(sqliteUniqueViolation)))
+	assert.Equal(t, 0, sqliteCode(assert.AnError))
+}
+
+func TestSecretRepositoryImpl_SQLite(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	cfg := config.Config{DatabaseDSN: "sqlite://gophkeeper.db"}
+	repo := NewSecretRepositoryImpl(db, &cfg)
+	columns := []string{"id", "user_id", "title", "data", "folder_id", "tags", "version", "created_at", "updated_at"}
+
+	t.Run("Create", func(t *testing.T) {
+		text := "hello"
+		mock.ExpectQuery(regexp.QuoteMeta("insert into secrets")).
+			WithArgs(uint64(42), "note", `{"text":"hello"}`, nil, `["work"]`, "").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uint64(1)))
+
+		id, err := repo.Create(context.Background(), models.CreateSecretDTO{
+			UserID: 42,
+			Title:  "note",
+			Data:   models.SecretDataDTO{Text: &text},
+			Tags:   []string{"work"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		cursor := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			where user_id = $1 and deleted_at is null and exists (select 1 from json_each(tags) where value = $2) and title like $3 escape '\' and (updated_at, id) < ($4, $5)
+			order by updated_at desc, id desc
+		`)).
+			WithArgs(uint64(42), "work", `%bank%`, cursor, uint64(9)).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := repo.GetAllByUser(context.Background(), 42, models.SecretFilterDTO{
+			Tag:   "work",
+			Query: "bank",
+			Sort:  "-updated_at",
+			After: &models.SecretCursorDTO{Sort: "-updated_at", Value: cursor.Format(models.SecretCursorTimeLayout), ID: 9},
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Update_without_row_lock", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("where id = $1 and user_id = $2 and deleted_at is null;")).
+			WithArgs(uint64(1), uint64(42)).
+			WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		_, err := repo.Update(context.Background(), models.UpdateSecretDTO{ID: 1, UserID: 42, Title: "note"})
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestWebhookRepositoryImpl_SQLite(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &WebhookRepositoryImpl{cfg: &config.Config{}, db: db, dialect: dialectSQLite, logger: logger.NewLogger()}
+	ctx := context.Background()
+
+	t.Run("Enqueue", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta("where user_id = $1 and exists (select 1 from json_each(events) where value = $2)")).
+			WithArgs(uint64(1), "secret.changed", `{}`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		count, err := repo.Enqueue(ctx, 1, "secret.changed", []byte(`{}`))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ClaimDue", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("set next_attempt_at = $2")).
+			WithArgs(50, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "url", "secret", "event", "payload", "attempts"}).
+				AddRow(uint64(9), "https://example.com/hook", "key", "secret.changed", `{}`, 0))
+
+		tasks, err := repo.ClaimDue(ctx, 50, 20*time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.WebhookTaskDTO{{ID: 9, URL: "https://example.com/hook", Secret: "key", Event: "secret.changed", Payload: []byte(`{}`)}}, tasks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
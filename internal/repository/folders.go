@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// FolderRepositoryImpl — реализация интерфейса FolderRepository для работы с папками в PostgreSQL.
+type FolderRepositoryImpl struct {
+	db     *sql.DB        // соединение с базой данных
+	cfg    *config.Config // конфигурация приложения
+	logger *logger.Logger // логгер
+}
+
+// NewFolderRepositoryImpl создаёт новый экземпляр FolderRepositoryImpl.
+// Работает через общий пул соединений db (см. database.Open).
+func NewFolderRepositoryImpl(db *sql.DB, cfg *config.Config) *FolderRepositoryImpl {
+	return &FolderRepositoryImpl{
+		db:     db,
+		cfg:    cfg,
+		logger: logger.NewLogger(),
+	}
+}
+
+// Create сохраняет новую папку в базу данных.
+// Возвращает созданную папку или ErrFolderExists при совпадении названия в той же родительской папке.
+func (r *FolderRepositoryImpl) Create(ctx context.Context, dto models.CreateFolderDTO) (*models.ReadFolderDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "folders.create")
+	defer span.End()
+
+	query := `
+		insert into folders (user_id, parent_id, name)
+		values ($1, $2, $3)
+		returning id, user_id, parent_id, name, created_at, updated_at;
+	`
+	folder, err := r.scanFolder(conn(ctx, r.db).QueryRowContext(ctx, query, dto.UserID, nullableID(dto.ParentID), dto.Name))
+	if err != nil {
+		if isUniqueViolation(err) {
+			r.logger.For(ctx).Warn("Папка с таким названием уже существует", zap.Uint64("user_id", dto.UserID), zap.String("name", dto.Name))
+			return nil, ErrFolderExists
+		}
+		r.logger.For(ctx).Error("Ошибка при создании папки", zap.Uint64("user_id", dto.UserID), zap.String("name", dto.Name), zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.For(ctx).Info("Папка успешно создана", zap.Uint64("folder_id", folder.ID), zap.Uint64("user_id", dto.UserID))
+	return folder, nil
+}
+
+// GetByID возвращает папку пользователя по её ID.
+// Если папка не найдена или принадлежит другому пользователю — возвращает ErrNotFound.
+func (r *FolderRepositoryImpl) GetByID(ctx context.Context, userID, id uint64) (*models.ReadFolderDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "folders.get_by_id")
+	defer span.End()
+
+	query := `
+		select id, user_id, parent_id, name, created_at, updated_at
+		from folders
+		where id = $1 and user_id = $2;
+	`
+	folder, err := r.scanFolder(conn(ctx, r.db).QueryRowContext(ctx, query, id, userID))
+	if err == sql.ErrNoRows {
+		r.logger.For(ctx).Warn("Папка не найдена", zap.Uint64("folder_id", id), zap.Uint64("user_id", userID))
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при получении папки по ID", zap.Uint64("folder_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.For(ctx).Info("Папка успешно получена", zap.Uint64("folder_id", folder.ID))
+	return folder, nil
+}
+
+// GetAllByUser возвращает все папки пользователя, упорядоченные по названию.
+func (r *FolderRepositoryImpl) GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadFolderDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "folders.get_all_by_user")
+	defer span.End()
+
+	query := `
+		select id, user_id, parent_id, name, created_at, updated_at
+		from folders
+		where user_id = $1
+		order by name, id;
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при получении папок пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var folders []models.ReadFolderDTO
+	for rows.Next() {
+		folder, err := r.scanFolder(rows)
+		if err != nil {
+			r.logger.For(ctx).Error("Ошибка при чтении строки папки", zap.Error(err))
+			return nil, err
+		}
+		folders = append(folders, *folder)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.For(ctx).Error("Ошибка при чтении папок пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.For(ctx).Info("Папки пользователя успешно получены", zap.Uint64("user_id", userID), zap.Int("count", len(folders)))
+	return folders, nil
+}
+
+// Rename изменяет название папки пользователя.
+func (r *FolderRepositoryImpl) Rename(ctx context.Context, userID, id uint64, name string) (*models.ReadFolderDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "folders.rename")
+	defer span.End()
+
+	query := `
+		update folders
+		set name = $1, updated_at = now()
+		where id = $2 and user_id = $3
+		returning id, user_id, parent_id, name, created_at, updated_at;
+	`
+	return r.update(ctx, query, id, name, id, userID)
+}
+
+// Move переносит папку пользователя в другую родительскую папку (nil — в корень).
+// Проверка на циклы выполняется на уровне сервиса.
+func (r *FolderRepositoryImpl) Move(ctx context.Context, userID, id uint64, parentID *uint64) (*models.ReadFolderDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "folders.move")
+	defer span.End()
+
+	query := `
+		update folders
+		set parent_id = $1, updated_at = now()
+		where id = $2 and user_id = $3
+		returning id, user_id, parent_id, name, created_at, updated_at;
+	`
+	return r.update(ctx, query, id, nullableID(parentID), id, userID)
+}
+
+// update выполняет запрос на изменение папки и возвращает её новое состояние.
+func (r *FolderRepositoryImpl) update(ctx context.Context, query string, id uint64, args ...any) (*models.ReadFolderDTO, error) {
+	folder, err := r.scanFolder(conn(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		r.logger.For(ctx).Warn("Папка для изменения не найдена", zap.Uint64("folder_id", id))
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		if isUniqueViolation(err) {
+			r.logger.For(ctx).Warn("Папка с таким названием уже существует", zap.Uint64("folder_id", id))
+			return nil, ErrFolderExists
+		}
+		r.logger.For(ctx).Error("Ошибка при изменении папки", zap.Uint64("folder_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.For(ctx).Info("Папка успешно изменена", zap.Uint64("folder_id", folder.ID))
+	return folder, nil
+}
+
+// scanFolder читает папку из строки результата запроса.
+func (r *FolderRepositoryImpl) scanFolder(row interface{ Scan(dest ...any) error }) (*models.ReadFolderDTO, error) {
+	var folder models.ReadFolderDTO
+	var parentID sql.NullInt64
+	err := row.Scan(&folder.ID, &folder.UserID, &parentID, &folder.Name, &folder.CreatedAt, &folder.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	folder.ParentID = idFromNull(parentID)
+	return &folder, nil
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var folderColumns = []string{"id", "user_id", "parent_id", "name", "created_at", "updated_at"}
+
+func TestFolderRepositoryImpl_Create(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &FolderRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	now := time.Now()
+	parentID := uint64(3)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			insert into folders (user_id, parent_id, name)
+			values ($1, $2, $3)
+			returning id, user_id, parent_id, name, created_at, updated_at
+		`)).
+			WithArgs(uint64(42), int64(3), "Work").
+			WillReturnRows(sqlmock.NewRows(folderColumns).AddRow(uint64(5), uint64(42), int64(3), "Work", now, now))
+
+		folder, err := repo.Create(context.Background(), models.CreateFolderDTO{UserID: 42, ParentID: &parentID, Name: "Work"})
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(5), folder.ID)
+		assert.Equal(t, parentID, *folder.ParentID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Duplicate_name", func(t *testing.T) {
+		mock.ExpectQuery("insert into folders").
+			WithArgs(uint64(42), nil, "Work").
+			WillReturnError(pgx.PgError{Code: "23505"})
+
+		folder, err := repo.Create(context.Background(), models.CreateFolderDTO{UserID: 42, Name: "Work"})
+		assert.ErrorIs(t, err, ErrFolderExists)
+		assert.Nil(t, folder)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Query_error", func(t *testing.T) {
+		mock.ExpectQuery("insert into folders").
+			WithArgs(uint64(42), nil, "Work").
+			WillReturnError(assert.AnError)
+
+		folder, err := repo.Create(context.Background(), models.CreateFolderDTO{UserID: 42, Name: "Work"})
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, folder)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFolderRepositoryImpl_GetByID(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &FolderRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	now := time.Now()
+
+	testCases := []struct {
+		name     string
+		mockSet  func()
+		expected error
+	}{
+		{
+			name: "Success",
+			mockSet: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`where id = $1 and user_id = $2`)).
+					WithArgs(uint64(5), uint64(42)).
+					WillReturnRows(sqlmock.NewRows(folderColumns).AddRow(uint64(5), uint64(42), nil, "Work", now, now))
+			},
+		},
+		{
+			name: "Not_found",
+			mockSet: func() {
+				mock.ExpectQuery("select id, user_id, parent_id").
+					WithArgs(uint64(5), uint64(42)).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expected: ErrNotFound,
+		},
+		{
+			name: "Query_error",
+			mockSet: func() {
+				mock.ExpectQuery("select id, user_id, parent_id").
+					WithArgs(uint64(5), uint64(42)).
+					WillReturnError(assert.AnError)
+			},
+			expected: assert.AnError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockSet()
+			folder, err := repo.GetByID(context.Background(), 42, 5)
+			if tc.expected != nil {
+				assert.ErrorIs(t, err, tc.expected)
+				assert.Nil(t, folder)
+			} else {
+				assert.NoError(t, err)
+				assert.Nil(t, folder.ParentID)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestFolderRepositoryImpl_GetAllByUser(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &FolderRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	now := time.Now()
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			select id, user_id, parent_id, name, created_at, updated_at
+			from folders
+			where user_id = $1
+			order by name, id
+		`)).
+			WithArgs(uint64(42)).
+			WillReturnRows(sqlmock.NewRows(folderColumns).
+				AddRow(uint64(1), uint64(42), nil, "Personal", now, now).
+				AddRow(uint64(2), uint64(42), int64(1), "Banks", now, now))
+
+		folders, err := repo.GetAllByUser(context.Background(), 42)
+		assert.NoError(t, err)
+		assert.Len(t, folders, 2)
+		assert.Equal(t, uint64(1), *folders[1].ParentID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Query_error", func(t *testing.T) {
+		mock.ExpectQuery("select id, user_id, parent_id").
+			WithArgs(uint64(42)).
+			WillReturnError(assert.AnError)
+
+		folders, err := repo.GetAllByUser(context.Background(), 42)
+		assert.Error(t, err)
+		assert.Nil(t, folders)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Scan_error", func(t *testing.T) {
+		mock.ExpectQuery("select id, user_id, parent_id").
+			WithArgs(uint64(42)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		folders, err := repo.GetAllByUser(context.Background(), 42)
+		assert.Error(t, err)
+		assert.Nil(t, folders)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Rows_error", func(t *testing.T) {
+		mock.ExpectQuery("select id, user_id, parent_id").
+			WithArgs(uint64(42)).
+			WillReturnRows(sqlmock.NewRows(folderColumns).
+				AddRow(uint64(1), uint64(42), nil, "Personal", now, now).
+				RowError(0, assert.AnError))
+
+		folders, err := repo.GetAllByUser(context.Background(), 42)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, folders)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFolderRepositoryImpl_RenameAndMove(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &FolderRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	now := time.Now()
+	parentID := uint64(1)
+
+	t.Run("Rename_success", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`set name = $1, updated_at = now()`)).
+			WithArgs("Renamed", uint64(5), uint64(42)).
+			WillReturnRows(sqlmock.NewRows(folderColumns).AddRow(uint64(5), uint64(42), nil, "Renamed", now, now))
+
+		folder, err := repo.Rename(context.Background(), 42, 5, "Renamed")
+		assert.NoError(t, err)
+		assert.Equal(t, "Renamed", folder.Name)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rename_conflict", func(t *testing.T) {
+		mock.ExpectQuery("update folders").
+			WithArgs("Taken", uint64(5), uint64(42)).
+			WillReturnError(pgx.PgError{Code: "23505"})
+
+		_, err := repo.Rename(context.Background(), 42, 5, "Taken")
+		assert.ErrorIs(t, err, ErrFolderExists)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Move_success", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`set parent_id = $1, updated_at = now()`)).
+			WithArgs(int64(1), uint64(5), uint64(42)).
+			WillReturnRows(sqlmock.NewRows(folderColumns).AddRow(uint64(5), uint64(42), int64(1), "Work", now, now))
+
+		folder, err := repo.Move(context.Background(), 42, 5, &parentID)
+		assert.NoError(t, err)
+		assert.Equal(t, parentID, *folder.ParentID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Move_not_found", func(t *testing.T) {
+		mock.ExpectQuery("update folders").
+			WithArgs(nil, uint64(5), uint64(42)).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.Move(context.Background(), 42, 5, nil)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Move_query_error", func(t *testing.T) {
+		mock.ExpectQuery("update folders").
+			WithArgs(nil, uint64(5), uint64(42)).
+			WillReturnError(assert.AnError)
+
+		_, err := repo.Move(context.Background(), 42, 5, nil)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// HealthRepositoryImpl — реализация интерфейса HealthRepository для проверки состояния PostgreSQL.
+type HealthRepositoryImpl struct {
+	db     *sql.DB        // соединение с базой данных
+	cfg    *config.Config // конфигурация приложения
+	logger *logger.Logger // логгер
+}
+
+// NewHealthRepositoryImpl создаёт новый экземпляр HealthRepositoryImpl.
+// Работает через общий пул соединений db (см. database.Open).
+func NewHealthRepositoryImpl(db *sql.DB, cfg *config.Config) *HealthRepositoryImpl {
+	return &HealthRepositoryImpl{
+		db:     db,
+		cfg:    cfg,
+		logger: logger.NewLogger(),
+	}
+}
+
+// Ping проверяет, что база данных принимает соединения.
+func (r *HealthRepositoryImpl) Ping(ctx context.Context) error {
+	ctx, span := tracing.StartDB(ctx, "health.ping")
+	defer span.End()
+
+	return r.db.PingContext(ctx)
+}
+
+// MigrationVersion возвращает номер последней применённой миграции и признак прерванной миграции
+// из таблицы schema_migrations, которую ведёт golang-migrate. Если миграции не применялись, возвращает ErrNotFound.
+func (r *HealthRepositoryImpl) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	ctx, span := tracing.StartDB(ctx, "health.migration_version")
+	defer span.End()
+
+	var (
+		version uint
+		dirty   bool
+	)
+	err := r.db.QueryRowContext(ctx, "select version, dirty from schema_migrations limit 1;").Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, false, ErrNotFound
+	}
+	if err != nil {
+		r.logger.For(ctx).Error("Не удалось получить версию миграций", zap.Error(err))
+		return 0, false, err
+	}
+	return version, dirty, nil
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthRepositoryImpl(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &HealthRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	ctx := context.Background()
+
+	t.Run("Ping", func(t *testing.T) {
+		mock.ExpectPing()
+		assert.NoError(t, repo.Ping(ctx))
+
+		mock.ExpectPing().WillReturnError(assert.AnError)
+		assert.ErrorIs(t, repo.Ping(ctx), assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("MigrationVersion", func(t *testing.T) {
+		mock.ExpectQuery("select version, dirty from schema_migrations").
+			WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(8, false))
+
+		version, dirty, err := repo.MigrationVersion(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(8), version)
+		assert.False(t, dirty)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("MigrationVersion_not_applied", func(t *testing.T) {
+		mock.ExpectQuery("select version, dirty from schema_migrations").WillReturnError(sql.ErrNoRows)
+
+		_, _, err := repo.MigrationVersion(ctx)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// uniqueViolation — код ошибки PostgreSQL при нарушении уникального индекса.
+const uniqueViolation = "23505"
+
+// Коды ошибок SQLite (расширенные коды включены в драйвере).
+const (
+	sqliteBusy                = 5    // База заблокирована другой транзакцией (основной код)
+	sqliteUniqueViolation     = 2067 // SQLITE_CONSTRAINT_UNIQUE
+	sqlitePrimaryKeyViolation = 1555 // SQLITE_CONSTRAINT_PRIMARYKEY
+)
+
+// querier — общие методы *sql.DB и *sql.Tx: запросы можно выполнять как вне транзакции, так и внутри неё.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// nullableID преобразует необязательный ID в значение для параметра SQL-запроса (nil → NULL).
+func nullableID(id *uint64) any {
+	if id == nil {
+		return nil
+	}
+	return int64(*id)
+}
+
+// idFromNull преобразует прочитанный из БД nullable ID в указатель (NULL → nil).
+func idFromNull(id sql.NullInt64) *uint64 {
+	if !id.Valid {
+		return nil
+	}
+	v := uint64(id.Int64)
+	return &v
+}
+
+// marshalTags сериализует теги в JSON-массив для хранения в колонке jsonb.
+// nil сохраняется как пустой массив.
+func marshalTags(tags []string) ([]byte, error) {
+	if tags == nil {
+		tags = []string{}
+	}
+	return json.Marshal(tags)
+}
+
+// unmarshalTags десериализует теги из JSON-массива.
+func unmarshalTags(raw []byte) ([]string, error) {
+	tags := []string{}
+	if len(raw) == 0 {
+		return tags, nil
+	}
+	if err := json.Unmarshal(raw, &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// likeEscaper экранирует спецсимволы шаблона LIKE, чтобы подстрока поиска сравнивалась буквально.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike экранирует строку для подстановки в шаблон LIKE/ILIKE (символ экранирования по умолчанию — \).
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// sqlState возвращает SQLSTATE-код ошибки базы данных или пустую строку,
+// если ошибка пришла не от сервера БД.
+func sqlState(err error) string {
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		return stateErr.SQLState()
+	}
+	return ""
+}
+
+// sqliteCode возвращает расширенный код ошибки SQLite или 0, если ошибка пришла не от SQLite.
+func sqliteCode(err error) int {
+	var codeErr interface{ Code() int }
+	if errors.As(err, &codeErr) {
+		return codeErr.Code()
+	}
+	return 0
+}
+
+// isUniqueViolation сообщает, нарушает ли запрос уникальный индекс — в PostgreSQL или в SQLite.
+func isUniqueViolation(err error) bool {
+	if sqlState(err) == uniqueViolation {
+		return true
+	}
+	code := sqliteCode(err)
+	return code == sqliteUniqueViolation || code == sqlitePrimaryKeyViolation
+}
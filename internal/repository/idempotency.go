@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// IdempotencyRepositoryImpl — реализация интерфейса IdempotencyRepository для хранения ключей идемпотентности в PostgreSQL или SQLite.
+type IdempotencyRepositoryImpl struct {
+	db      *sql.DB        // соединение с базой данных
+	cfg     *config.Config // конфигурация приложения
+	dialect dialect        // диалект SQL базы
+	logger  *logger.Logger // логгер
+}
+
+// NewIdempotencyRepositoryImpl создаёт новый экземпляр IdempotencyRepositoryImpl.
+// Работает через общий пул соединений db (см. database.Open).
+func NewIdempotencyRepositoryImpl(db *sql.DB, cfg *config.Config) *IdempotencyRepositoryImpl {
+	return &IdempotencyRepositoryImpl{
+		db:      db,
+		cfg:     cfg,
+		dialect: dialectOf(cfg),
+		logger:  logger.NewLogger(),
+	}
+}
+
+// Reserve закрепляет ключ за новым запросом пользователя.
+// Если ключа нет, его запись создана раньше expiredBefore или запрос без сохранённого ответа начат раньше
+// leaseExpiredBefore (например, сервер остановился посреди запроса), сохраняет запись без ответа и возвращает nil, nil.
+// Иначе возвращает существующую запись. Вставка и проверка выполняются одним запросом,
+// поэтому из двух одновременных запросов с одним ключом ключ получает только один.
+func (r *IdempotencyRepositoryImpl) Reserve(ctx context.Context, userID uint64, key, requestHash string, expiredBefore, leaseExpiredBefore time.Time) (*models.IdempotencyRecordDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "idempotency_keys.reserve")
+	defer span.End()
+
+	reserveQuery := `
+		insert into idempotency_keys (user_id, key, request_hash)
+		values ($1, $2, $3)
+		on conflict (user_id, key) do update
+		set request_hash = excluded.request_hash, status_code = null, response = null, created_at = now()
+		where idempotency_keys.created_at < $4
+			or idempotency_keys.status_code is null and idempotency_keys.created_at < $5
+		returning user_id;
+	`
+	var id uint64
+	err := conn(ctx, r.db).QueryRowContext(ctx, reserveQuery, userID, key, requestHash,
+		r.dialect.timeArg(expiredBefore), r.dialect.timeArg(leaseExpiredBefore)).Scan(&id)
+	if err == nil {
+		r.logger.For(ctx).Info("Ключ идемпотентности закреплён за запросом", zap.Uint64("user_id", userID), zap.String("key", key))
+		return nil, nil
+	}
+	if err != sql.ErrNoRows {
+		r.logger.For(ctx).Error("Ошибка при сохранении ключа идемпотентности", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+
+	selectQuery := `
+		select request_hash, status_code, response, created_at
+		from idempotency_keys
+		where user_id = $1 and key = $2;
+	`
+	rec := models.IdempotencyRecordDTO{UserID: userID, Key: key}
+	var status sql.NullInt64
+	err = conn(ctx, r.db).QueryRowContext(ctx, selectQuery, userID, key).Scan(&rec.RequestHash, &status, &rec.Response, &rec.CreatedAt)
+	if err == sql.ErrNoRows {
+		r.logger.For(ctx).Warn("Ключ идемпотентности освобождён во время проверки", zap.Uint64("user_id", userID), zap.String("key", key))
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при получении ключа идемпотентности", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	rec.StatusCode = int(status.Int64)
+	return &rec, nil
+}
+
+// Complete сохраняет ответ на запрос, за которым закреплён ключ.
+func (r *IdempotencyRepositoryImpl) Complete(ctx context.Context, userID uint64, key string, statusCode int, response []byte) error {
+	ctx, span := tracing.StartDB(ctx, "idempotency_keys.complete")
+	defer span.End()
+
+	query := `
+		update idempotency_keys
+		set status_code = $3, response = $4
+		where user_id = $1 and key = $2;
+	`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, userID, key, statusCode, response); err != nil {
+		r.logger.For(ctx).Error("Ошибка при сохранении ответа по ключу идемпотентности", zap.Uint64("user_id", userID), zap.String("key", key), zap.Error(err))
+		return err
+	}
+	r.logger.For(ctx).Info("Ответ сохранён по ключу идемпотентности", zap.Uint64("user_id", userID), zap.String("key", key), zap.Int("status", statusCode))
+	return nil
+}
+
+// Release удаляет ключ, чтобы запрос с ним можно было выполнить заново.
+func (r *IdempotencyRepositoryImpl) Release(ctx context.Context, userID uint64, key string) error {
+	ctx, span := tracing.StartDB(ctx, "idempotency_keys.release")
+	defer span.End()
+
+	query := `
+		delete from idempotency_keys
+		where user_id = $1 and key = $2;
+	`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, userID, key); err != nil {
+		r.logger.For(ctx).Error("Ошибка при удалении ключа идемпотентности", zap.Uint64("user_id", userID), zap.String("key", key), zap.Error(err))
+		return err
+	}
+	r.logger.For(ctx).Info("Ключ идемпотентности освобождён", zap.Uint64("user_id", userID), zap.String("key", key))
+	return nil
+}
+
+// DeleteExpired удаляет ключи всех пользователей, созданные раньше before.
+// Возвращает количество удалённых ключей.
+func (r *IdempotencyRepositoryImpl) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := tracing.StartDB(ctx, "idempotency_keys.delete_expired")
+	defer span.End()
+
+	query := `
+		delete from idempotency_keys
+		where created_at < $1;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, r.dialect.timeArg(before))
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при удалении устаревших ключей идемпотентности", zap.Time("before", before), zap.Error(err))
+		return 0, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		r.logger.For(ctx).Error("Не удалось получить количество удалённых ключей идемпотентности", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
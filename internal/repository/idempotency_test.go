@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+)
+
+func TestIdempotencyRepositoryImpl_Reserve(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &IdempotencyRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	expiredBefore := time.Now().Add(-24 * time.Hour)
+	leaseExpiredBefore := time.Now().Add(-5 * time.Minute)
+	reserveQuery := regexp.QuoteMeta(`
+		insert into idempotency_keys (user_id, key, request_hash)
+		values ($1, $2, $3)
+		on conflict (user_id, key) do update
+		set request_hash = excluded.request_hash, status_code = null, response = null, created_at = now()
+		where idempotency_keys.created_at < $4
+			or idempotency_keys.status_code is null and idempotency_keys.created_at < $5
+		returning user_id;
+	`)
+	selectQuery := regexp.QuoteMeta(`
+		select request_hash, status_code, response, created_at
+		from idempotency_keys
+		where user_id = $1 and key = $2;
+	`)
+
+	t.Run("Reserved", func(t *testing.T) {
+		mock.ExpectQuery(reserveQuery).
+			WithArgs(uint64(42), "key-1", "hash", expiredBefore, leaseExpiredBefore).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(42))
+
+		rec, err := repo.Reserve(context.Background(), 42, "key-1", "hash", expiredBefore, leaseExpiredBefore)
+		assert.NoError(t, err)
+		assert.Nil(t, rec)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Completed", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(reserveQuery).WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+		mock.ExpectQuery(selectQuery).
+			WithArgs(uint64(42), "key-1").
+			WillReturnRows(sqlmock.NewRows([]string{"request_hash", "status_code", "response", "created_at"}).
+				AddRow("hash", 201, []byte(`{"id":7}`), now))
+
+		rec, err := repo.Reserve(context.Background(), 42, "key-1", "hash", expiredBefore, leaseExpiredBefore)
+		assert.NoError(t, err)
+		assert.Equal(t, 201, rec.StatusCode)
+		assert.Equal(t, []byte(`{"id":7}`), rec.Response)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("In_progress", func(t *testing.T) {
+		mock.ExpectQuery(reserveQuery).WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+		mock.ExpectQuery(selectQuery).
+			WillReturnRows(sqlmock.NewRows([]string{"request_hash", "status_code", "response", "created_at"}).
+				AddRow("hash", nil, nil, time.Now()))
+
+		rec, err := repo.Reserve(context.Background(), 42, "key-1", "hash", expiredBefore, leaseExpiredBefore)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, rec.StatusCode)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Released_meanwhile", func(t *testing.T) {
+		mock.ExpectQuery(reserveQuery).WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+		mock.ExpectQuery(selectQuery).WillReturnRows(sqlmock.NewRows([]string{"request_hash", "status_code", "response", "created_at"}))
+
+		_, err := repo.Reserve(context.Background(), 42, "key-1", "hash", expiredBefore, leaseExpiredBefore)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mock.ExpectQuery(reserveQuery).WillReturnError(assert.AnError)
+
+		_, err := repo.Reserve(context.Background(), 42, "key-1", "hash", expiredBefore, leaseExpiredBefore)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestIdempotencyRepositoryImpl_CompleteRelease(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &IdempotencyRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+
+	mock.ExpectExec(regexp.QuoteMeta(`
+		update idempotency_keys
+		set status_code = $3, response = $4
+		where user_id = $1 and key = $2;
+	`)).
+		WithArgs(uint64(42), "key-1", 201, []byte(`{"id":7}`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Complete(context.Background(), 42, "key-1", 201, []byte(`{"id":7}`)))
+
+	mock.ExpectExec(regexp.QuoteMeta(`
+		delete from idempotency_keys
+		where user_id = $1 and key = $2;
+	`)).
+		WithArgs(uint64(42), "key-1").
+		WillReturnError(assert.AnError)
+	assert.Error(t, repo.Release(context.Background(), 42, "key-1"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepositoryImpl_DeleteExpired(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &IdempotencyRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	before := time.Now()
+
+	mock.ExpectExec(regexp.QuoteMeta(`
+		delete from idempotency_keys
+		where created_at < $1;
+	`)).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	count, err := repo.DeleteExpired(context.Background(), before)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
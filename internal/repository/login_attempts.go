@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// LoginAttemptRepositoryImpl — реализация интерфейса LoginAttemptRepository для хранения попыток входа в PostgreSQL или SQLite.
+type LoginAttemptRepositoryImpl struct {
+	db      *sql.DB        // соединение с базой данных
+	cfg     *config.Config // конфигурация приложения
+	dialect dialect        // диалект SQL базы
+	logger  *logger.Logger // логгер
+}
+
+// NewLoginAttemptRepositoryImpl создаёт новый экземпляр LoginAttemptRepositoryImpl.
+// Работает через общий пул соединений db (см. database.Open).
+func NewLoginAttemptRepositoryImpl(db *sql.DB, cfg *config.Config) *LoginAttemptRepositoryImpl {
+	return &LoginAttemptRepositoryImpl{
+		db:      db,
+		cfg:     cfg,
+		dialect: dialectOf(cfg),
+		logger:  logger.NewLogger(),
+	}
+}
+
+// Record сохраняет попытку входа пользователя.
+func (r *LoginAttemptRepositoryImpl) Record(ctx context.Context, dto models.LoginAttemptDTO) error {
+	ctx, span := tracing.StartDB(ctx, "login_attempts.record")
+	defer span.End()
+
+	query := `
+		insert into login_attempts (user_id, success, device, ip)
+		values ($1, $2, $3, $4);
+	`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, dto.UserID, dto.Success, dto.Device, dto.IP); err != nil {
+		r.logger.For(ctx).Error("Ошибка при сохранении попытки входа", zap.Uint64("user_id", dto.UserID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// IsKnownDevice сообщает, входил ли пользователь раньше с устройства device.
+func (r *LoginAttemptRepositoryImpl) IsKnownDevice(ctx context.Context, userID uint64, device string) (bool, error) {
+	ctx, span := tracing.StartDB(ctx, "login_attempts.is_known_device")
+	defer span.End()
+
+	query := `
+		select exists (
+			select 1 from login_attempts
+			where user_id = $1 and success and device = $2
+		);
+	`
+	var known bool
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, userID, device).Scan(&known); err != nil {
+		r.logger.For(ctx).Error("Ошибка при поиске устройства пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+		return false, err
+	}
+	return known, nil
+}
+
+// CountFailures возвращает количество неудачных попыток входа пользователя не раньше since.
+func (r *LoginAttemptRepositoryImpl) CountFailures(ctx context.Context, userID uint64, since time.Time) (int, error) {
+	ctx, span := tracing.StartDB(ctx, "login_attempts.count_failures")
+	defer span.End()
+
+	query := `
+		select count(*) from login_attempts
+		where user_id = $1 and not success and created_at >= $2;
+	`
+	var count int
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, userID, r.dialect.timeArg(since)).Scan(&count); err != nil {
+		r.logger.For(ctx).Error("Ошибка при подсчёте неудачных попыток входа", zap.Uint64("user_id", userID), zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginAttemptRepositoryImpl(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &LoginAttemptRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	ctx := context.Background()
+
+	t.Run("Record", func(t *testing.T) {
+		mock.ExpectExec("insert into login_attempts").
+			WithArgs(uint64(1), false, "laptop", "192.0.2.1").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		assert.NoError(t, repo.Record(ctx, models.LoginAttemptDTO{UserID: 1, Device: "laptop", IP: "192.0.2.1"}))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Record_error", func(t *testing.T) {
+		mock.ExpectExec("insert into login_attempts").WillReturnError(assert.AnError)
+
+		assert.ErrorIs(t, repo.Record(ctx, models.LoginAttemptDTO{UserID: 1, Success: true}), assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("IsKnownDevice", func(t *testing.T) {
+		mock.ExpectQuery("select exists .+ where user_id = \\$1 and success and device = \\$2").
+			WithArgs(uint64(1), "laptop").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		known, err := repo.IsKnownDevice(ctx, 1, "laptop")
+		assert.NoError(t, err)
+		assert.True(t, known)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("CountFailures", func(t *testing.T) {
+		since := time.Now().Add(-15 * time.Minute)
+		mock.ExpectQuery("select count\\(\\*\\) from login_attempts where user_id = \\$1 and not success").
+			WithArgs(uint64(1), since).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+
+		failures, err := repo.CountFailures(ctx, 1, since)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, failures)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// auditRecord — запись журнала аудита в памяти.
+type auditRecord struct {
+	models.ReadSecretAuditDTO
+	userID uint64
+}
+
+// SecretAuditRepository — реализация интерфейса repository.SecretAuditRepository в памяти процесса.
+type SecretAuditRepository struct {
+	mu      sync.RWMutex
+	lastID  uint64
+	records []auditRecord // Записи журнала в порядке добавления
+}
+
+// NewSecretAuditRepository создаёт пустой журнал аудита.
+func NewSecretAuditRepository() *SecretAuditRepository {
+	return &SecretAuditRepository{}
+}
+
+// Create добавляет запись в журнал аудита.
+func (r *SecretAuditRepository) Create(ctx context.Context, dto models.CreateSecretAuditDTO) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	id := r.lastID
+	r.records = append(r.records, auditRecord{
+		ReadSecretAuditDTO: models.ReadSecretAuditDTO{
+			ID:        id,
+			SecretID:  dto.SecretID,
+			Action:    dto.Action,
+			IP:        dto.IP,
+			UserAgent: dto.UserAgent,
+			CreatedAt: now(),
+		},
+		userID: dto.UserID,
+	})
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.records = slices.DeleteFunc(r.records, func(rec auditRecord) bool { return rec.ID == id })
+	})
+	return nil
+}
+
+// GetBySecret возвращает записи журнала по секрету пользователя, начиная с последних.
+func (r *SecretAuditRepository) GetBySecret(ctx context.Context, userID, secretID uint64) ([]models.ReadSecretAuditDTO, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var records []models.ReadSecretAuditDTO
+	for _, rec := range r.records {
+		if rec.userID == userID && rec.SecretID == secretID {
+			records = append(records, rec.ReadSecretAuditDTO)
+		}
+	}
+	slices.SortFunc(records, func(a, b models.ReadSecretAuditDTO) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+	return records, nil
+}
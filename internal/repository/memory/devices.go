@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+)
+
+// DeviceCertificateRepository — реализация интерфейса repository.DeviceCertificateRepository в памяти процесса.
+type DeviceCertificateRepository struct {
+	mu     sync.Mutex
+	lastID uint64
+	certs  map[string]*models.DeviceCertificateDTO // Сертификаты по серийному номеру
+}
+
+// NewDeviceCertificateRepository создаёт пустой репозиторий сертификатов устройств.
+func NewDeviceCertificateRepository() *DeviceCertificateRepository {
+	return &DeviceCertificateRepository{certs: make(map[string]*models.DeviceCertificateDTO)}
+}
+
+// Create сохраняет сертификат, выданный устройству.
+func (r *DeviceCertificateRepository) Create(ctx context.Context, dto models.CreateDeviceCertificateDTO) (*models.DeviceCertificateDTO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.certs[dto.Serial]; ok {
+		return nil, fmt.Errorf("device certificate %s already exists", dto.Serial)
+	}
+	r.lastID++
+	cert := &models.DeviceCertificateDTO{
+		ID:         r.lastID,
+		UserID:     dto.UserID,
+		Device:     dto.Device,
+		Serial:     dto.Serial,
+		Thumbprint: dto.Thumbprint,
+		CreatedAt:  now(),
+		ExpiresAt:  dto.ExpiresAt.UTC().Truncate(time.Microsecond),
+	}
+	r.certs[cert.Serial] = cert
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.certs, cert.Serial)
+	})
+	return copyDeviceCertificate(cert), nil
+}
+
+// GetBySerial возвращает сертификат по серийному номеру.
+// Если сертификат не найден, возвращает repository.ErrNotFound.
+func (r *DeviceCertificateRepository) GetBySerial(ctx context.Context, serial string) (*models.DeviceCertificateDTO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cert, ok := r.certs[serial]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return copyDeviceCertificate(cert), nil
+}
+
+// GetAllByUser возвращает сертификаты устройств пользователя, начиная с новых.
+func (r *DeviceCertificateRepository) GetAllByUser(ctx context.Context, userID uint64) ([]models.DeviceCertificateDTO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var certs []models.DeviceCertificateDTO
+	for _, cert := range r.certs {
+		if cert.UserID == userID {
+			certs = append(certs, *copyDeviceCertificate(cert))
+		}
+	}
+	slices.SortFunc(certs, func(a, b models.DeviceCertificateDTO) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+	return certs, nil
+}
+
+// Revoke отзывает сертификат и возвращает его. Время отзыва уже отозванного сертификата не меняется.
+// Если сертификат не найден, возвращает repository.ErrNotFound.
+func (r *DeviceCertificateRepository) Revoke(ctx context.Context, serial string) (*models.DeviceCertificateDTO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cert, ok := r.certs[serial]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if cert.RevokedAt == nil {
+		revoked := now()
+		cert.RevokedAt = &revoked
+		onRollback(ctx, func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			cert.RevokedAt = nil
+		})
+	}
+	return copyDeviceCertificate(cert), nil
+}
+
+// GetRevoked возвращает отозванные сертификаты, срок действия которых не истёк к моменту at,
+// начиная с первых отозванных.
+func (r *DeviceCertificateRepository) GetRevoked(ctx context.Context, at time.Time) ([]models.DeviceCertificateDTO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var certs []models.DeviceCertificateDTO
+	for _, cert := range r.certs {
+		if cert.RevokedAt != nil && cert.ExpiresAt.After(at) {
+			certs = append(certs, *copyDeviceCertificate(cert))
+		}
+	}
+	slices.SortFunc(certs, func(a, b models.DeviceCertificateDTO) int {
+		return cmp.Or(a.RevokedAt.Compare(*b.RevokedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return certs, nil
+}
+
+// copyDeviceCertificate возвращает копию сертификата, чтобы вызывающий не мог изменить хранимую запись.
+func copyDeviceCertificate(cert *models.DeviceCertificateDTO) *models.DeviceCertificateDTO {
+	c := *cert
+	if cert.RevokedAt != nil {
+		revoked := *cert.RevokedAt
+		c.RevokedAt = &revoked
+	}
+	return &c
+}
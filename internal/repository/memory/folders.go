@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+)
+
+// FolderRepository — реализация интерфейса repository.FolderRepository в памяти процесса.
+type FolderRepository struct {
+	mu      sync.RWMutex
+	lastID  uint64
+	folders map[uint64]*models.ReadFolderDTO // Папки по ID; записи заменяются, а не изменяются
+}
+
+// NewFolderRepository создаёт пустой репозиторий папок.
+func NewFolderRepository() *FolderRepository {
+	return &FolderRepository{folders: make(map[uint64]*models.ReadFolderDTO)}
+}
+
+// Create сохраняет новую папку.
+// Возвращает repository.ErrFolderExists, если в родительской папке уже есть папка с таким названием.
+func (r *FolderRepository) Create(ctx context.Context, dto models.CreateFolderDTO) (*models.ReadFolderDTO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.exists(0, dto.UserID, dto.ParentID, dto.Name) {
+		return nil, repository.ErrFolderExists
+	}
+	r.lastID++
+	created := now()
+	folder := &models.ReadFolderDTO{
+		ID:        r.lastID,
+		UserID:    dto.UserID,
+		ParentID:  copyID(dto.ParentID),
+		Name:      dto.Name,
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+	r.folders[folder.ID] = folder
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.folders, folder.ID)
+	})
+	return r.read(folder), nil
+}
+
+// GetByID возвращает папку пользователя по её ID.
+// Если папка не найдена, возвращает repository.ErrNotFound.
+func (r *FolderRepository) GetByID(ctx context.Context, userID, id uint64) (*models.ReadFolderDTO, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	folder, ok := r.folders[id]
+	if !ok || folder.UserID != userID {
+		return nil, repository.ErrNotFound
+	}
+	return r.read(folder), nil
+}
+
+// GetAllByUser возвращает все папки пользователя, упорядоченные по названию.
+func (r *FolderRepository) GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadFolderDTO, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var folders []models.ReadFolderDTO
+	for _, folder := range r.folders {
+		if folder.UserID == userID {
+			folders = append(folders, *r.read(folder))
+		}
+	}
+	slices.SortFunc(folders, func(a, b models.ReadFolderDTO) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+	return folders, nil
+}
+
+// Rename изменяет название папки.
+// Возвращает обновлённую папку, repository.ErrNotFound или repository.ErrFolderExists.
+func (r *FolderRepository) Rename(ctx context.Context, userID, id uint64, name string) (*models.ReadFolderDTO, error) {
+	return r.update(ctx, userID, id, func(folder *models.ReadFolderDTO) { folder.Name = name })
+}
+
+// Move переносит папку в другую родительскую папку (nil — в корень).
+// Возвращает обновлённую папку, repository.ErrNotFound или repository.ErrFolderExists.
+func (r *FolderRepository) Move(ctx context.Context, userID, id uint64, parentID *uint64) (*models.ReadFolderDTO, error) {
+	return r.update(ctx, userID, id, func(folder *models.ReadFolderDTO) { folder.ParentID = copyID(parentID) })
+}
+
+// update заменяет папку пользователя копией, изменённой функцией change.
+func (r *FolderRepository) update(ctx context.Context, userID, id uint64, change func(folder *models.ReadFolderDTO)) (*models.ReadFolderDTO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prev, ok := r.folders[id]
+	if !ok || prev.UserID != userID {
+		return nil, repository.ErrNotFound
+	}
+	folder := *prev
+	change(&folder)
+	if r.exists(id, folder.UserID, folder.ParentID, folder.Name) {
+		return nil, repository.ErrFolderExists
+	}
+	folder.UpdatedAt = now()
+	r.folders[id] = &folder
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.folders[id] = prev
+	})
+	return r.read(&folder), nil
+}
+
+// exists сообщает, есть ли у пользователя другая папка (не except) с названием name в родительской папке parentID.
+// Повторяет уникальный индекс idx__folders__user_id__parent_id__name.
+func (r *FolderRepository) exists(except, userID uint64, parentID *uint64, name string) bool {
+	for id, folder := range r.folders {
+		if id != except && folder.UserID == userID && folder.Name == name && sameID(folder.ParentID, parentID) {
+			return true
+		}
+	}
+	return false
+}
+
+// read возвращает копию папки, которую вызывающий может изменять.
+func (r *FolderRepository) read(folder *models.ReadFolderDTO) *models.ReadFolderDTO {
+	dto := *folder
+	dto.ParentID = copyID(folder.ParentID)
+	return &dto
+}
@@ -0,0 +1,24 @@
+package memory
+
+import (
+	"context"
+)
+
+// HealthRepository — реализация интерфейса repository.HealthRepository для хранилища в памяти:
+// хранилище всегда доступно и не требует миграций.
+type HealthRepository struct{}
+
+// NewHealthRepository создаёт новый экземпляр HealthRepository.
+func NewHealthRepository() *HealthRepository {
+	return &HealthRepository{}
+}
+
+// Ping всегда успешен: хранилище находится в памяти процесса.
+func (r *HealthRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+// MigrationVersion возвращает нулевую версию без признака прерванной миграции.
+func (r *HealthRepository) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	return 0, false, nil
+}
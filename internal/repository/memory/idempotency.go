@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// idempotencyKey — ключ записи идемпотентности: пользователь и значение заголовка Idempotency-Key.
+type idempotencyKey struct {
+	userID uint64
+	key    string
+}
+
+// IdempotencyRepository — реализация интерфейса repository.IdempotencyRepository в памяти процесса.
+type IdempotencyRepository struct {
+	mu      sync.Mutex
+	records map[idempotencyKey]models.IdempotencyRecordDTO
+}
+
+// NewIdempotencyRepository создаёт пустое хранилище ключей идемпотентности.
+func NewIdempotencyRepository() *IdempotencyRepository {
+	return &IdempotencyRepository{records: make(map[idempotencyKey]models.IdempotencyRecordDTO)}
+}
+
+// Reserve закрепляет ключ пользователя за новым запросом и возвращает nil, nil.
+// Если ключ уже есть и создан не раньше expiredBefore, а запрос без сохранённого ответа — не раньше
+// leaseExpiredBefore, возвращает его запись.
+func (r *IdempotencyRepository) Reserve(ctx context.Context, userID uint64, key, requestHash string, expiredBefore, leaseExpiredBefore time.Time) (*models.IdempotencyRecordDTO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := idempotencyKey{userID: userID, key: key}
+	if rec, ok := r.records[k]; ok && !rec.CreatedAt.Before(expiredBefore) && (rec.StatusCode != 0 || !rec.CreatedAt.Before(leaseExpiredBefore)) {
+		rec.Response = slices.Clone(rec.Response)
+		return &rec, nil
+	}
+	r.set(ctx, k, &models.IdempotencyRecordDTO{UserID: userID, Key: key, RequestHash: requestHash, CreatedAt: now()})
+	return nil, nil
+}
+
+// Complete сохраняет HTTP-статус и тело ответа на запрос, за которым закреплён ключ.
+func (r *IdempotencyRepository) Complete(ctx context.Context, userID uint64, key string, statusCode int, response []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := idempotencyKey{userID: userID, key: key}
+	rec, ok := r.records[k]
+	if !ok {
+		return nil
+	}
+	rec.StatusCode = statusCode
+	rec.Response = slices.Clone(response)
+	r.set(ctx, k, &rec)
+	return nil
+}
+
+// Release удаляет ключ, чтобы запрос с ним можно было выполнить заново.
+func (r *IdempotencyRepository) Release(ctx context.Context, userID uint64, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.set(ctx, idempotencyKey{userID: userID, key: key}, nil)
+	return nil
+}
+
+// DeleteExpired удаляет ключи всех пользователей, созданные раньше before.
+// Возвращает количество удалённых ключей.
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var count int64
+	for k, rec := range r.records {
+		if rec.CreatedAt.Before(before) {
+			r.set(ctx, k, nil)
+			count++
+		}
+	}
+	return count, nil
+}
+
+// set заменяет запись ключа k на rec (nil — удаляет) и запоминает прежнюю для отката транзакции.
+// Вызывается под блокировкой.
+func (r *IdempotencyRepository) set(ctx context.Context, k idempotencyKey, rec *models.IdempotencyRecordDTO) {
+	prev, ok := r.records[k]
+	if rec != nil {
+		r.records[k] = *rec
+	} else {
+		delete(r.records, k)
+	}
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if ok {
+			r.records[k] = prev
+		} else {
+			delete(r.records, k)
+		}
+	})
+}
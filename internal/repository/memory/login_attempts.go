@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// loginAttempt — попытка входа в памяти.
+type loginAttempt struct {
+	models.LoginAttemptDTO
+	createdAt time.Time
+}
+
+// LoginAttemptRepository — реализация интерфейса repository.LoginAttemptRepository в памяти процесса.
+type LoginAttemptRepository struct {
+	mu       sync.RWMutex
+	attempts []loginAttempt // Попытки входа в порядке записи
+}
+
+// NewLoginAttemptRepository создаёт пустой журнал попыток входа.
+func NewLoginAttemptRepository() *LoginAttemptRepository {
+	return &LoginAttemptRepository{}
+}
+
+// Record сохраняет попытку входа.
+func (r *LoginAttemptRepository) Record(ctx context.Context, dto models.LoginAttemptDTO) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.attempts = append(r.attempts, loginAttempt{LoginAttemptDTO: dto, createdAt: now()})
+	return nil
+}
+
+// IsKnownDevice сообщает, входил ли пользователь раньше с устройства device.
+func (r *LoginAttemptRepository) IsKnownDevice(ctx context.Context, userID uint64, device string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, a := range r.attempts {
+		if a.UserID == userID && a.Success && a.Device == device {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CountFailures возвращает количество неудачных попыток входа пользователя не раньше since.
+func (r *LoginAttemptRepository) CountFailures(ctx context.Context, userID uint64, since time.Time) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int
+	for _, a := range r.attempts {
+		if a.UserID == userID && !a.Success && !a.createdAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
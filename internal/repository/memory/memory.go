@@ -0,0 +1,86 @@
+// Package memory реализует репозитории сервера в памяти процесса.
+// Хранилище используется в режиме --storage=memory для демонстраций и быстрых интеграционных тестов:
+// данные теряются при остановке сервера. Поведение репозиториев совпадает с реализациями для PostgreSQL
+// и SQLite из пакета repository, что проверяет общий набор тестов repotest.
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// txKey — ключ контекста, под которым хранится транзакция TxManager.
+type txKey struct{}
+
+// tx — журнал отмены изменений транзакции: функции восстанавливают прежнее состояние записей.
+type tx struct {
+	undo []func()
+}
+
+// rollback отменяет изменения транзакции в обратном порядке.
+func (t *tx) rollback() {
+	for _, fn := range slices.Backward(t.undo) {
+		fn()
+	}
+	t.undo = nil
+}
+
+// onRollback добавляет fn в журнал отмены транзакции из ctx. Вне транзакции изменения сразу окончательны.
+func onRollback(ctx context.Context, fn func()) {
+	if t, ok := ctx.Value(txKey{}).(*tx); ok {
+		t.undo = append(t.undo, fn)
+	}
+}
+
+// TxManager — реализация интерфейса repository.TxManager для хранилища в памяти.
+// Транзакции выполняются по очереди; при ошибке изменения, сделанные репозиториями пакета
+// внутри транзакции, отменяются.
+type TxManager struct {
+	mu sync.Mutex // Очередь транзакций
+}
+
+// NewTxManager создаёт новый экземпляр TxManager.
+func NewTxManager() *TxManager {
+	return &TxManager{}
+}
+
+// WithinTx выполняет fn в транзакции. Если fn возвращает ошибку, изменения отменяются.
+// Вложенный вызов выполняется во внешней транзакции.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*tx); ok {
+		return fn(ctx)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := &tx{}
+	if err := fn(context.WithValue(ctx, txKey{}, t)); err != nil {
+		t.rollback()
+		return err
+	}
+	return nil
+}
+
+// now возвращает текущее время в UTC с точностью до микросекунд, как его хранит PostgreSQL.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// copyID возвращает копию необязательного ID, чтобы вызывающий не мог изменить хранимое значение.
+func copyID(id *uint64) *uint64 {
+	if id == nil {
+		return nil
+	}
+	v := *id
+	return &v
+}
+
+// sameID сообщает, совпадают ли необязательные ID (nil — корень или отсутствие папки).
+func sameID(a, b *uint64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,20 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/repository/repotest"
+)
+
+func TestRepositories(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Backend {
+		return repotest.Backend{
+			Users:   NewUserRepository(),
+			Secrets: NewSecretRepository(&config.Config{}),
+			Folders: NewFolderRepository(),
+			Devices: NewDeviceCertificateRepository(),
+			Tx:      NewTxManager(),
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// CreateChange добавляет изменение секрета в журнал изменений и возвращает его с ID и временем записи.
+// Транзакции TxManager выполняются по очереди, поэтому ID растут в порядке фиксации.
+func (r *SecretRepository) CreateChange(ctx context.Context, event models.SecretEventDTO) (*models.SecretEventDTO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changes, changeID := r.changes, r.changeID
+	r.changeID++
+	change := event
+	change.ID = r.changeID
+	change.Time = now()
+	r.changes = append(slices.Clip(r.changes), change)
+	r.keep(ctx, []func(){func() {
+		r.changes, r.changeID = changes, changeID
+	}})
+	return &change, nil
+}
+
+// GetChanges возвращает изменения секретов пользователя из журнала, отобранные по фильтру, в порядке ID.
+func (r *SecretRepository) GetChanges(ctx context.Context, userID uint64, filter models.SecretChangeFilterDTO) ([]models.SecretEventDTO, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var changes []models.SecretEventDTO
+	for _, change := range r.changes {
+		if change.UserID != userID || change.ID <= filter.AfterID || change.Time.Before(filter.Since) {
+			continue
+		}
+		changes = append(changes, change)
+		if filter.Limit > 0 && len(changes) == filter.Limit {
+			break
+		}
+	}
+	return changes, nil
+}
+
+// GetChangeRange возвращает ID самой старой и самой новой записи журнала изменений пользователя.
+// Если записей нет, возвращает нули.
+func (r *SecretRepository) GetChangeRange(ctx context.Context, userID uint64) (uint64, uint64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var oldest, newest uint64
+	for _, change := range r.changes {
+		if change.UserID != userID {
+			continue
+		}
+		if oldest == 0 {
+			oldest = change.ID
+		}
+		newest = change.ID
+	}
+	return oldest, newest, nil
+}
+
+// PurgeChangesBefore удаляет из журнала изменения секретов всех пользователей, записанные раньше before.
+// Возвращает количество удалённых записей.
+func (r *SecretRepository) PurgeChangesBefore(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changes := r.changes
+	r.changes = slices.DeleteFunc(slices.Clone(changes), func(change models.SecretEventDTO) bool {
+		return change.Time.Before(before)
+	})
+	count := int64(len(changes) - len(r.changes))
+	if count > 0 {
+		r.keep(ctx, []func(){func() {
+			r.changes = changes
+		}})
+	}
+	return count, nil
+}
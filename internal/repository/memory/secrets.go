@@ -0,0 +1,612 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+)
+
+// secret — запись секрета в памяти. Записи не изменяются после сохранения:
+// изменение заменяет запись новой, поэтому её можно отдавать в журнал отмены без копирования.
+type secret struct {
+	id        uint64
+	userID    uint64
+	title     string
+	data      []byte // Данные секрета в JSON, как в колонке data
+	typ       string // Тип секрета, вычисленный по данным (см. secretType)
+	folderID  *uint64
+	tags      []string
+	version   int
+	device    string
+	createdAt time.Time
+	updatedAt time.Time
+	deletedAt *time.Time // Когда секрет перемещён в корзину (nil — не в корзине)
+}
+
+// secretVersion — предыдущая версия секрета.
+type secretVersion struct {
+	version    int
+	title      string
+	data       []byte
+	typ        string
+	folderID   *uint64
+	tags       []string
+	device     string
+	createdAt  time.Time
+	archivedAt time.Time
+}
+
+// SecretRepository — реализация интерфейса repository.SecretRepository в памяти процесса.
+type SecretRepository struct {
+	mu       sync.RWMutex
+	cfg      *config.Config             // Сроки хранения версий и корзины
+	lastID   uint64                     // Последний выданный ID секрета
+	secrets  map[uint64]*secret         // Секреты по ID
+	versions map[uint64][]secretVersion // Предыдущие версии по ID секрета, от старых к новым
+	changes  []models.SecretEventDTO    // Журнал изменений секретов в порядке ID
+	changeID uint64                     // Последний выданный ID записи журнала изменений
+}
+
+// NewSecretRepository создаёт пустой репозиторий секретов.
+// Сроки хранения версий и секретов в корзине берутся из cfg, как в repository.SecretRepositoryImpl.
+func NewSecretRepository(cfg *config.Config) *SecretRepository {
+	return &SecretRepository{
+		cfg:      cfg,
+		secrets:  make(map[uint64]*secret),
+		versions: make(map[uint64][]secretVersion),
+	}
+}
+
+// Create сохраняет новый секрет и возвращает его ID.
+func (r *SecretRepository) Create(ctx context.Context, dto models.CreateSecretDTO) (uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var undo []func()
+	id, err := r.create(&undo, dto)
+	r.keep(ctx, undo)
+	return id, err
+}
+
+// Update изменяет секрет пользователя, сохраняя его текущее состояние в истории версий.
+// Версии сверх cfg.SecretVersionsRetention удаляются. Возвращает обновлённый секрет или repository.ErrNotFound.
+func (r *SecretRepository) Update(ctx context.Context, dto models.UpdateSecretDTO) (*models.ReadSecretDTO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var undo []func()
+	secret, err := r.update(&undo, dto)
+	r.keep(ctx, undo)
+	return secret, err
+}
+
+// GetVersions возвращает метаданные предыдущих версий секрета пользователя, начиная с последней.
+func (r *SecretRepository) GetVersions(ctx context.Context, userID, secretID uint64) ([]models.SecretVersionDTO, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.secrets[secretID]
+	if !ok || s.userID != userID {
+		return nil, nil
+	}
+	var versions []models.SecretVersionDTO
+	for _, v := range slices.Backward(r.versions[secretID]) {
+		versions = append(versions, models.SecretVersionDTO{
+			Version:    v.version,
+			Title:      v.title,
+			Type:       v.typ,
+			Device:     v.device,
+			CreatedAt:  v.createdAt,
+			ArchivedAt: v.archivedAt,
+		})
+	}
+	return versions, nil
+}
+
+// GetVersion возвращает предыдущую версию секрета пользователя вместе с данными.
+// Если версия не найдена — возвращает repository.ErrNotFound.
+func (r *SecretRepository) GetVersion(ctx context.Context, userID, secretID uint64, version int) (*models.ReadSecretVersionDTO, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.secrets[secretID]
+	if !ok || s.userID != userID {
+		return nil, repository.ErrNotFound
+	}
+	i := slices.IndexFunc(r.versions[secretID], func(v secretVersion) bool { return v.version == version })
+	if i < 0 {
+		return nil, repository.ErrNotFound
+	}
+	v := r.versions[secretID][i]
+	dto := models.ReadSecretVersionDTO{
+		SecretID:   secretID,
+		Version:    v.version,
+		Title:      v.title,
+		FolderID:   copyID(v.folderID),
+		Tags:       slices.Clone(v.tags),
+		Device:     v.device,
+		CreatedAt:  v.createdAt,
+		ArchivedAt: v.archivedAt,
+	}
+	if err := json.Unmarshal(v.data, &dto.Data); err != nil {
+		return nil, repository.ErrUnmarshalPayload
+	}
+	return &dto, nil
+}
+
+// GetByID возвращает секрет по его ID. Секреты из корзины не возвращаются.
+// Если секрет не найден — возвращает nil, nil.
+func (r *SecretRepository) GetByID(ctx context.Context, id uint64) (*models.ReadSecretDTO, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.secrets[id]
+	if !ok || s.deletedAt != nil {
+		return nil, nil
+	}
+	return s.read()
+}
+
+// GetAllByUser возвращает секреты пользователя, отобранные и упорядоченные по фильтру.
+// Возвращает repository.ErrInvalidCursor, если курсор выдан для другой сортировки или повреждён.
+func (r *SecretRepository) GetAllByUser(ctx context.Context, userID uint64, filter models.SecretFilterDTO) ([]models.ReadSecretDTO, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	found, err := r.find(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	var secrets []models.ReadSecretDTO
+	for _, s := range found {
+		dto, err := s.read()
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, *dto)
+	}
+	return secrets, nil
+}
+
+// GetSummariesByUser возвращает метаданные секретов пользователя без полезных данных.
+// Отбор, сортировка и постраничная выборка выполняются так же, как в GetAllByUser.
+func (r *SecretRepository) GetSummariesByUser(ctx context.Context, userID uint64, filter models.SecretFilterDTO) ([]models.SecretSummaryDTO, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	found, err := r.find(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	var summaries []models.SecretSummaryDTO
+	for _, s := range found {
+		summaries = append(summaries, models.SecretSummaryDTO{
+			ID:        s.id,
+			Title:     s.title,
+			Type:      s.typ,
+			FolderID:  copyID(s.folderID),
+			Tags:      slices.Clone(s.tags),
+			Version:   s.version,
+			CreatedAt: s.createdAt,
+			UpdatedAt: s.updatedAt,
+		})
+	}
+	return summaries, nil
+}
+
+// DeleteByID перемещает секрет пользователя в корзину.
+// Возвращает repository.ErrNotFound, если секрет не найден, принадлежит другому пользователю или уже в корзине.
+func (r *SecretRepository) DeleteByID(ctx context.Context, userID, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var undo []func()
+	err := r.moveToTrash(&undo, userID, id)
+	r.keep(ctx, undo)
+	return err
+}
+
+// GetTrashByUser возвращает метаданные секретов пользователя в корзине, начиная с последних удалённых.
+// Срок окончательного удаления рассчитывается по cfg.TrashRetention.
+func (r *SecretRepository) GetTrashByUser(ctx context.Context, userID uint64) ([]models.TrashedSecretDTO, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var trashed []*secret
+	for _, s := range r.secrets {
+		if s.userID == userID && s.deletedAt != nil {
+			trashed = append(trashed, s)
+		}
+	}
+	slices.SortFunc(trashed, func(a, b *secret) int {
+		return cmp.Or(b.deletedAt.Compare(*a.deletedAt), cmp.Compare(b.id, a.id))
+	})
+
+	var trash []models.TrashedSecretDTO
+	for _, s := range trashed {
+		dto := models.TrashedSecretDTO{
+			ID:        s.id,
+			Title:     s.title,
+			Type:      s.typ,
+			FolderID:  copyID(s.folderID),
+			Tags:      slices.Clone(s.tags),
+			Version:   s.version,
+			CreatedAt: s.createdAt,
+			UpdatedAt: s.updatedAt,
+			DeletedAt: *s.deletedAt,
+		}
+		if retention := r.cfg.TrashRetention; retention > 0 {
+			purgeAt := dto.DeletedAt.Add(retention)
+			dto.PurgeAt = &purgeAt
+		}
+		trash = append(trash, dto)
+	}
+	return trash, nil
+}
+
+// RestoreFromTrash возвращает секрет пользователя из корзины.
+// Возвращает repository.ErrNotFound, если секрета нет в корзине пользователя.
+func (r *SecretRepository) RestoreFromTrash(ctx context.Context, userID, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.secrets[id]
+	if !ok || s.userID != userID || s.deletedAt == nil {
+		return repository.ErrNotFound
+	}
+	var undo []func()
+	r.snapshot(&undo, id)
+	restored := *s
+	restored.deletedAt = nil
+	r.secrets[id] = &restored
+	r.keep(ctx, undo)
+	return nil
+}
+
+// PurgeByID окончательно удаляет секрет пользователя из корзины вместе с историей версий.
+// Возвращает repository.ErrNotFound, если секрета нет в корзине пользователя.
+func (r *SecretRepository) PurgeByID(ctx context.Context, userID, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.secrets[id]
+	if !ok || s.userID != userID || s.deletedAt == nil {
+		return repository.ErrNotFound
+	}
+	var undo []func()
+	r.purge(&undo, id)
+	r.keep(ctx, undo)
+	return nil
+}
+
+// PurgeDeletedBefore окончательно удаляет все секреты, перемещённые в корзину раньше before.
+// Возвращает количество удалённых секретов.
+func (r *SecretRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var undo []func()
+	var count int64
+	for id, s := range r.secrets {
+		if s.deletedAt != nil && s.deletedAt.Before(before) {
+			r.purge(&undo, id)
+			count++
+		}
+	}
+	r.keep(ctx, undo)
+	return count, nil
+}
+
+// ApplyBatch выполняет операции пользователя над секретами. Пока пакет выполняется, другие запросы
+// к репозиторию ждут. В атомарном режиме первая ошибка отменяет уже выполненные операции пакета,
+// а остальные операции получают repository.ErrBatchRolledBack; в неатомарном ошибка затрагивает только свою операцию.
+func (r *SecretRepository) ApplyBatch(ctx context.Context, userID uint64, ops []models.BatchOperationDTO, device string, atomic bool) ([]models.BatchOutcome, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var undo []func()
+	outcomes := make([]models.BatchOutcome, len(ops))
+	for i, op := range ops {
+		outcome, err := r.applyOp(&undo, userID, op, device)
+		if err == nil {
+			outcomes[i] = outcome
+			continue
+		}
+
+		outcomes[i] = models.BatchOutcome{ID: op.ID, Err: err}
+		if atomic {
+			for _, fn := range slices.Backward(undo) {
+				fn()
+			}
+			for j := range outcomes {
+				if j != i {
+					outcomes[j] = models.BatchOutcome{Err: repository.ErrBatchRolledBack}
+				}
+			}
+			return outcomes, nil
+		}
+	}
+	r.keep(ctx, undo)
+	return outcomes, nil
+}
+
+// applyOp выполняет одну операцию пакета. Операция либо выполняется целиком, либо ничего не меняет.
+func (r *SecretRepository) applyOp(undo *[]func(), userID uint64, op models.BatchOperationDTO, device string) (models.BatchOutcome, error) {
+	switch op.Op {
+	case models.BatchOpCreate:
+		id, err := r.create(undo, models.CreateSecretDTO{
+			UserID:   userID,
+			Title:    op.Title,
+			Data:     op.Data,
+			FolderID: op.FolderID,
+			Tags:     op.Tags,
+			Device:   device,
+		})
+		return models.BatchOutcome{ID: id, Version: 1}, err
+	case models.BatchOpUpdate:
+		secret, err := r.update(undo, models.UpdateSecretDTO{
+			ID:       op.ID,
+			UserID:   userID,
+			Title:    op.Title,
+			Data:     op.Data,
+			FolderID: op.FolderID,
+			Tags:     op.Tags,
+			Device:   device,
+		})
+		if err != nil {
+			return models.BatchOutcome{}, err
+		}
+		return models.BatchOutcome{ID: secret.ID, Version: secret.Version}, nil
+	case models.BatchOpDelete:
+		return models.BatchOutcome{ID: op.ID}, r.moveToTrash(undo, userID, op.ID)
+	default:
+		return models.BatchOutcome{}, fmt.Errorf("unknown batch operation %q", op.Op)
+	}
+}
+
+// create сохраняет новый секрет. Вызывается под блокировкой записи.
+func (r *SecretRepository) create(undo *[]func(), dto models.CreateSecretDTO) (uint64, error) {
+	data, err := json.Marshal(dto.Data)
+	if err != nil {
+		return 0, repository.ErrMarshalPayload
+	}
+	r.lastID++
+	created := now()
+	s := &secret{
+		id:        r.lastID,
+		userID:    dto.UserID,
+		title:     dto.Title,
+		data:      data,
+		typ:       secretType(data),
+		folderID:  copyID(dto.FolderID),
+		tags:      cloneTags(dto.Tags),
+		version:   1,
+		device:    dto.Device,
+		createdAt: created,
+		updatedAt: created,
+	}
+	r.snapshot(undo, s.id)
+	r.secrets[s.id] = s
+	return s.id, nil
+}
+
+// update архивирует текущую версию секрета и заменяет её новой. Вызывается под блокировкой записи.
+func (r *SecretRepository) update(undo *[]func(), dto models.UpdateSecretDTO) (*models.ReadSecretDTO, error) {
+	data, err := json.Marshal(dto.Data)
+	if err != nil {
+		return nil, repository.ErrMarshalPayload
+	}
+	s, ok := r.secrets[dto.ID]
+	if !ok || s.userID != dto.UserID || s.deletedAt != nil {
+		return nil, repository.ErrNotFound
+	}
+	r.snapshot(undo, s.id)
+
+	versions := append(slices.Clip(r.versions[s.id]), secretVersion{
+		version:    s.version,
+		title:      s.title,
+		data:       s.data,
+		typ:        s.typ,
+		folderID:   s.folderID,
+		tags:       s.tags,
+		device:     s.device,
+		createdAt:  s.updatedAt,
+		archivedAt: now(),
+	})
+	if retention := r.cfg.SecretVersionsRetention; retention > 0 && s.version > retention {
+		oldest := s.version - retention
+		versions = slices.DeleteFunc(versions, func(v secretVersion) bool { return v.version <= oldest })
+	}
+	r.versions[s.id] = versions
+
+	updated := *s
+	updated.title = dto.Title
+	updated.data = data
+	updated.typ = secretType(data)
+	updated.folderID = copyID(dto.FolderID)
+	updated.tags = cloneTags(dto.Tags)
+	updated.device = dto.Device
+	updated.version++
+	updated.updatedAt = now()
+	r.secrets[s.id] = &updated
+	return updated.read()
+}
+
+// moveToTrash перемещает секрет пользователя в корзину. Вызывается под блокировкой записи.
+func (r *SecretRepository) moveToTrash(undo *[]func(), userID, id uint64) error {
+	s, ok := r.secrets[id]
+	if !ok || s.userID != userID || s.deletedAt != nil {
+		return repository.ErrNotFound
+	}
+	r.snapshot(undo, id)
+	deleted := now()
+	trashed := *s
+	trashed.deletedAt = &deleted
+	r.secrets[id] = &trashed
+	return nil
+}
+
+// purge удаляет секрет вместе с историей версий. Вызывается под блокировкой записи.
+func (r *SecretRepository) purge(undo *[]func(), id uint64) {
+	r.snapshot(undo, id)
+	delete(r.secrets, id)
+	delete(r.versions, id)
+}
+
+// snapshot добавляет в undo восстановление текущего состояния секрета id и его версий.
+// Функции undo выполняются под блокировкой записи.
+func (r *SecretRepository) snapshot(undo *[]func(), id uint64) {
+	s, ok := r.secrets[id]
+	versions, hasVersions := r.versions[id]
+	*undo = append(*undo, func() {
+		if ok {
+			r.secrets[id] = s
+		} else {
+			delete(r.secrets, id)
+		}
+		if hasVersions {
+			r.versions[id] = versions
+		} else {
+			delete(r.versions, id)
+		}
+	})
+}
+
+// keep передаёт изменения undo в журнал отмены транзакции из ctx.
+func (r *SecretRepository) keep(ctx context.Context, undo []func()) {
+	if len(undo) == 0 {
+		return
+	}
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, fn := range slices.Backward(undo) {
+			fn()
+		}
+	})
+}
+
+// find отбирает секреты пользователя не из корзины по фильтру и упорядочивает их, как buildSecretsQuery
+// в пакете repository: по полю сортировки, при равенстве — по ID. Названия сравниваются побайтно.
+func (r *SecretRepository) find(userID uint64, filter models.SecretFilterDTO) ([]*secret, error) {
+	sort := filter.Sort
+	if sort == "" {
+		sort = "-" + models.SecretSortCreatedAt
+	}
+	desc := strings.HasPrefix(sort, "-")
+	compare, ok := secretComparators[strings.TrimPrefix(sort, "-")]
+	if !ok {
+		return nil, repository.ErrInvalidCursor
+	}
+	if desc {
+		asc := compare
+		compare = func(a, b *secret) int { return asc(b, a) }
+	}
+
+	var after *secret
+	if filter.After != nil {
+		if filter.After.Sort != sort {
+			return nil, repository.ErrInvalidCursor
+		}
+		after = &secret{id: filter.After.ID, title: filter.After.Value}
+		if strings.TrimPrefix(sort, "-") != models.SecretSortTitle {
+			t, err := time.Parse(models.SecretCursorTimeLayout, filter.After.Value)
+			if err != nil {
+				return nil, repository.ErrInvalidCursor
+			}
+			after.createdAt, after.updatedAt = t, t
+		}
+	}
+
+	query := strings.ToLower(filter.Query)
+	var found []*secret
+	for _, s := range r.secrets {
+		switch {
+		case s.userID != userID || s.deletedAt != nil:
+		case filter.FolderID != nil && *filter.FolderID == 0 && s.folderID != nil:
+		case filter.FolderID != nil && *filter.FolderID != 0 && !sameID(filter.FolderID, s.folderID):
+		case filter.Tag != "" && !slices.Contains(s.tags, filter.Tag):
+		case query != "" && !strings.Contains(strings.ToLower(s.title), query):
+		case filter.Type != "" && s.typ != filter.Type:
+		case filter.CreatedFrom != nil && s.createdAt.Before(*filter.CreatedFrom):
+		case filter.CreatedTo != nil && !s.createdAt.Before(*filter.CreatedTo):
+		case filter.UpdatedFrom != nil && s.updatedAt.Before(*filter.UpdatedFrom):
+		case filter.UpdatedTo != nil && !s.updatedAt.Before(*filter.UpdatedTo):
+		case after != nil && compare(s, after) <= 0:
+		default:
+			found = append(found, s)
+		}
+	}
+	slices.SortFunc(found, compare)
+	if filter.Limit > 0 && len(found) > filter.Limit {
+		found = found[:filter.Limit]
+	}
+	return found, nil
+}
+
+// secretComparators сравнивают секреты по полю сортировки по возрастанию, при равенстве — по ID.
+var secretComparators = map[string]func(a, b *secret) int{
+	models.SecretSortCreatedAt: func(a, b *secret) int {
+		return cmp.Or(a.createdAt.Compare(b.createdAt), cmp.Compare(a.id, b.id))
+	},
+	models.SecretSortUpdatedAt: func(a, b *secret) int {
+		return cmp.Or(a.updatedAt.Compare(b.updatedAt), cmp.Compare(a.id, b.id))
+	},
+	models.SecretSortTitle: func(a, b *secret) int {
+		return cmp.Or(strings.Compare(a.title, b.title), cmp.Compare(a.id, b.id))
+	},
+}
+
+// read преобразует запись в ReadSecretDTO.
+func (s *secret) read() (*models.ReadSecretDTO, error) {
+	dto := models.ReadSecretDTO{
+		ID:        s.id,
+		UserID:    s.userID,
+		Title:     s.title,
+		FolderID:  copyID(s.folderID),
+		Tags:      slices.Clone(s.tags),
+		Version:   s.version,
+		CreatedAt: s.createdAt,
+		UpdatedAt: s.updatedAt,
+	}
+	if err := json.Unmarshal(s.data, &dto.Data); err != nil {
+		return nil, fmt.Errorf("secret %d: %w", s.id, repository.ErrUnmarshalPayload)
+	}
+	return &dto, nil
+}
+
+// secretTypeKeys — поля данных секрета в порядке, в котором по ним определяется тип (как в колонке secrets.type).
+var secretTypeKeys = []struct{ key, typ string }{
+	{"login_password", models.SecretTypeLogin},
+	{"card", models.SecretTypeCard},
+	{"text", models.SecretTypeText},
+	{"binary", models.SecretTypeBinary},
+}
+
+// secretType возвращает тип секрета по первому заполненному полю данных data (JSON).
+func secretType(data []byte) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return ""
+	}
+	for _, k := range secretTypeKeys {
+		if _, ok := fields[k.key]; ok {
+			return k.typ
+		}
+	}
+	return ""
+}
+
+// cloneTags копирует теги; nil сохраняется как пустой список, как в колонке tags.
+func cloneTags(tags []string) []string {
+	return append([]string{}, tags...)
+}
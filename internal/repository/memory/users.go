@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+)
+
+// user — запись пользователя в памяти.
+type user struct {
+	models.ReadUserDTO
+	passwordHash string
+}
+
+// UserRepository — реализация интерфейса repository.UserRepository в памяти процесса.
+type UserRepository struct {
+	mu     sync.RWMutex
+	lastID uint64
+	users  map[uint64]*user  // Пользователи по ID
+	names  map[string]uint64 // ID пользователей по имени
+}
+
+// NewUserRepository создаёт пустой репозиторий пользователей.
+func NewUserRepository() *UserRepository {
+	return &UserRepository{
+		users: make(map[uint64]*user),
+		names: make(map[string]uint64),
+	}
+}
+
+// CreateUser добавляет нового пользователя.
+// Возвращает repository.ErrUserExists, если имя пользователя занято.
+func (r *UserRepository) CreateUser(ctx context.Context, dto models.CreateUserDTO) (*models.ReadAuthUserDataDTO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.names[dto.UserName]; ok {
+		return nil, repository.ErrUserExists
+	}
+	r.lastID++
+	created := now()
+	u := &user{
+		ReadUserDTO: models.ReadUserDTO{
+			ID:        r.lastID,
+			UserName:  dto.UserName,
+			FirstName: dto.FirstName,
+			LastName:  dto.LastName,
+			CreatedAt: created,
+			UpdatedAt: created,
+		},
+		passwordHash: dto.PasswordHash,
+	}
+	r.users[u.ID] = u
+	r.names[u.UserName] = u.ID
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.users, u.ID)
+		delete(r.names, u.UserName)
+	})
+	return &models.ReadAuthUserDataDTO{ID: u.ID, UserName: u.UserName, PasswordHash: u.passwordHash}, nil
+}
+
+// GetUserByUserName находит пользователя по имени.
+// Возвращает repository.ErrNotFound, если пользователь не найден.
+func (r *UserRepository) GetUserByUserName(ctx context.Context, userName string) (*models.ReadAuthUserDataDTO, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.names[userName]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	u := r.users[id]
+	return &models.ReadAuthUserDataDTO{ID: u.ID, UserName: u.UserName, PasswordHash: u.passwordHash}, nil
+}
+
+// GetUserByID находит пользователя по ID.
+// Возвращает repository.ErrNotFound, если пользователь не найден.
+func (r *UserRepository) GetUserByID(ctx context.Context, id uint64) (*models.ReadUserDTO, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	dto := u.ReadUserDTO
+	return &dto, nil
+}
@@ -0,0 +1,242 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+)
+
+// delivery — доставка события вебхуку в памяти.
+type delivery struct {
+	models.WebhookDeliveryDTO
+	payload       []byte
+	nextAttemptAt time.Time
+}
+
+// WebhookRepository — реализация интерфейса repository.WebhookRepository в памяти процесса.
+type WebhookRepository struct {
+	mu             sync.Mutex
+	lastID         uint64
+	lastDeliveryID uint64
+	webhooks       map[uint64]models.ReadWebhookDTO // Вебхуки по ID вместе с ключами подписи
+	deliveries     map[uint64]*delivery             // Доставки по ID
+}
+
+// NewWebhookRepository создаёт пустой репозиторий вебхуков.
+func NewWebhookRepository() *WebhookRepository {
+	return &WebhookRepository{
+		webhooks:   make(map[uint64]models.ReadWebhookDTO),
+		deliveries: make(map[uint64]*delivery),
+	}
+}
+
+// Create сохраняет новый вебхук с ключом подписи secret.
+func (r *WebhookRepository) Create(ctx context.Context, dto models.CreateWebhookDTO, secret string) (*models.ReadWebhookDTO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	webhook := models.ReadWebhookDTO{
+		ID:        r.lastID,
+		UserID:    dto.UserID,
+		URL:       dto.URL,
+		Events:    cloneTags(dto.Events),
+		Secret:    secret,
+		CreatedAt: now(),
+	}
+	r.webhooks[webhook.ID] = webhook
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.webhooks, webhook.ID)
+	})
+	webhook.Events = slices.Clone(webhook.Events)
+	return &webhook, nil
+}
+
+// GetAllByUser возвращает вебхуки пользователя без ключей подписи.
+func (r *WebhookRepository) GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadWebhookDTO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var webhooks []models.ReadWebhookDTO
+	for _, webhook := range r.webhooks {
+		if webhook.UserID == userID {
+			webhook.Events = slices.Clone(webhook.Events)
+			webhook.Secret = ""
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	slices.SortFunc(webhooks, func(a, b models.ReadWebhookDTO) int { return cmp.Compare(a.ID, b.ID) })
+	return webhooks, nil
+}
+
+// DeleteByID удаляет вебхук пользователя вместе с журналом его доставок.
+// Если вебхук не найден, возвращает repository.ErrNotFound.
+func (r *WebhookRepository) DeleteByID(ctx context.Context, userID, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhook, ok := r.webhooks[id]
+	if !ok || webhook.UserID != userID {
+		return repository.ErrNotFound
+	}
+	deleted := make(map[uint64]*delivery)
+	for deliveryID, d := range r.deliveries {
+		if d.WebhookID == id {
+			deleted[deliveryID] = d
+			delete(r.deliveries, deliveryID)
+		}
+	}
+	delete(r.webhooks, id)
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.webhooks[id] = webhook
+		for deliveryID, d := range deleted {
+			r.deliveries[deliveryID] = d
+		}
+	})
+	return nil
+}
+
+// Enqueue ставит в очередь доставку события всем вебхукам пользователя, подписанным на него.
+// Возвращает количество созданных доставок.
+func (r *WebhookRepository) Enqueue(ctx context.Context, userID uint64, event string, payload []byte) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var created []uint64
+	for _, webhook := range r.webhooks {
+		if webhook.UserID != userID || !slices.Contains(webhook.Events, event) {
+			continue
+		}
+		r.lastDeliveryID++
+		queued := now()
+		r.deliveries[r.lastDeliveryID] = &delivery{
+			WebhookDeliveryDTO: models.WebhookDeliveryDTO{
+				ID:        r.lastDeliveryID,
+				WebhookID: webhook.ID,
+				Event:     event,
+				Status:    models.WebhookDeliveryPending,
+				CreatedAt: queued,
+			},
+			payload:       slices.Clone(payload),
+			nextAttemptAt: queued,
+		}
+		created = append(created, r.lastDeliveryID)
+	}
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, id := range created {
+			delete(r.deliveries, id)
+		}
+	})
+	return int64(len(created)), nil
+}
+
+// ClaimDue берёт в работу до limit доставок, время попытки которых наступило,
+// и откладывает их следующую попытку на lease.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookTaskDTO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	claimed := now()
+	var due []*delivery
+	for _, d := range r.deliveries {
+		if d.Status == models.WebhookDeliveryPending && !d.nextAttemptAt.After(claimed) {
+			due = append(due, d)
+		}
+	}
+	slices.SortFunc(due, func(a, b *delivery) int {
+		return cmp.Or(a.nextAttemptAt.Compare(b.nextAttemptAt), cmp.Compare(a.ID, b.ID))
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	var tasks []models.WebhookTaskDTO
+	for _, d := range due {
+		d.nextAttemptAt = claimed.Add(lease)
+		webhook := r.webhooks[d.WebhookID]
+		tasks = append(tasks, models.WebhookTaskDTO{
+			ID:       d.ID,
+			URL:      webhook.URL,
+			Secret:   webhook.Secret,
+			Event:    d.Event,
+			Payload:  slices.Clone(d.payload),
+			Attempts: d.Attempts,
+		})
+	}
+	return tasks, nil
+}
+
+// SaveAttempt сохраняет результат попытки доставки.
+func (r *WebhookRepository) SaveAttempt(ctx context.Context, id uint64, attempt models.WebhookAttemptDTO) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, ok := r.deliveries[id]
+	if !ok {
+		return nil
+	}
+	d.Status = attempt.Status
+	d.Attempts++
+	d.ResponseStatus = nil
+	if attempt.ResponseStatus != 0 {
+		status := attempt.ResponseStatus
+		d.ResponseStatus = &status
+	}
+	d.Error = attempt.Error
+	d.nextAttemptAt = attempt.NextAttemptAt
+	d.DeliveredAt = nil
+	if attempt.Status == models.WebhookDeliveryDelivered {
+		delivered := now()
+		d.DeliveredAt = &delivered
+	}
+	return nil
+}
+
+// GetDeliveries возвращает последние limit доставок вебхука пользователя, начиная с новых.
+// Если вебхук не найден, возвращает repository.ErrNotFound.
+func (r *WebhookRepository) GetDeliveries(ctx context.Context, userID, webhookID uint64, limit int) ([]models.WebhookDeliveryDTO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if webhook, ok := r.webhooks[webhookID]; !ok || webhook.UserID != userID {
+		return nil, repository.ErrNotFound
+	}
+	var deliveries []models.WebhookDeliveryDTO
+	for _, d := range r.deliveries {
+		if d.WebhookID != webhookID {
+			continue
+		}
+		dto := d.WebhookDeliveryDTO
+		if d.ResponseStatus != nil {
+			status := *d.ResponseStatus
+			dto.ResponseStatus = &status
+		}
+		if d.DeliveredAt != nil {
+			delivered := *d.DeliveredAt
+			dto.DeliveredAt = &delivered
+		}
+		if dto.Status == models.WebhookDeliveryPending {
+			next := d.nextAttemptAt
+			dto.NextAttemptAt = &next
+		}
+		deliveries = append(deliveries, dto)
+	}
+	slices.SortFunc(deliveries, func(a, b models.WebhookDeliveryDTO) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
@@ -3,10 +3,18 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/shekshuev/gophkeeper/internal/models"
 )
 
+// TxManager выполняет работу нескольких репозиториев в одной транзакции.
+type TxManager interface {
+	// WithinTx выполняет fn в транзакции, доступной репозиториям через переданный в fn контекст.
+	// Ошибка fn откатывает транзакцию; транзакция, прерванная конфликтом сериализации, выполняется заново.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 // UserRepository определяет поведение для репозитория пользователей.
 type UserRepository interface {
 	// GetUserByUserName находит пользователя по его userName.
@@ -28,16 +36,190 @@ type SecretRepository interface {
 	// Возвращает ID созданного секрета или ошибку.
 	Create(ctx context.Context, dto models.CreateSecretDTO) (uint64, error)
 
+	// Update изменяет секрет пользователя, сохраняя предыдущее состояние в истории версий.
+	// Возвращает обновлённый секрет или ErrNotFound, если секрет не найден или принадлежит другому пользователю.
+	Update(ctx context.Context, dto models.UpdateSecretDTO) (*models.ReadSecretDTO, error)
+
+	// GetVersions возвращает метаданные предыдущих версий секрета пользователя, начиная с последней.
+	GetVersions(ctx context.Context, userID, secretID uint64) ([]models.SecretVersionDTO, error)
+
+	// GetVersion возвращает предыдущую версию секрета пользователя вместе с данными.
+	// Если версия не найдена, возвращается ошибка ErrNotFound.
+	GetVersion(ctx context.Context, userID, secretID uint64, version int) (*models.ReadSecretVersionDTO, error)
+
 	// GetByID возвращает секрет по его ID.
 	// Если секрет не найден, возвращается ошибка ErrNotFound.
 	GetByID(ctx context.Context, id uint64) (*models.ReadSecretDTO, error)
 
-	// GetAllByUser возвращает все секреты пользователя по его userID.
-	GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadSecretDTO, error)
+	// GetAllByUser возвращает секреты пользователя по его userID, отобранные и упорядоченные по фильтру.
+	// При заданном лимите возвращает не больше filter.Limit записей, начиная после курсора filter.After.
+	// Возвращает ErrInvalidCursor, если курсор не соответствует сортировке.
+	GetAllByUser(ctx context.Context, userID uint64, filter models.SecretFilterDTO) ([]models.ReadSecretDTO, error)
+
+	// GetSummariesByUser возвращает метаданные секретов пользователя без полезных данных.
+	// Отбор, сортировка и постраничная выборка — как в GetAllByUser.
+	GetSummariesByUser(ctx context.Context, userID uint64, filter models.SecretFilterDTO) ([]models.SecretSummaryDTO, error)
+
+	// DeleteByID перемещает секрет пользователя в корзину.
+	// Если секрет не найден, принадлежит другому пользователю или уже в корзине, возвращается ошибка ErrNotFound.
+	DeleteByID(ctx context.Context, userID, id uint64) error
+
+	// GetTrashByUser возвращает метаданные секретов пользователя в корзине, начиная с последних удалённых.
+	GetTrashByUser(ctx context.Context, userID uint64) ([]models.TrashedSecretDTO, error)
+
+	// RestoreFromTrash возвращает секрет пользователя из корзины.
+	// Если секрета нет в корзине пользователя, возвращается ошибка ErrNotFound.
+	RestoreFromTrash(ctx context.Context, userID, id uint64) error
+
+	// PurgeByID окончательно удаляет секрет пользователя из корзины.
+	// Если секрета нет в корзине пользователя, возвращается ошибка ErrNotFound.
+	PurgeByID(ctx context.Context, userID, id uint64) error
+
+	// PurgeDeletedBefore окончательно удаляет секреты всех пользователей, перемещённые в корзину раньше before.
+	// Возвращает количество удалённых секретов.
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// CreateChange добавляет изменение секрета в журнал изменений и возвращает его с ID и временем записи.
+	// ID изменений одного пользователя растут в порядке фиксации транзакций. Вызывается внутри TxManager.WithinTx.
+	CreateChange(ctx context.Context, event models.SecretEventDTO) (*models.SecretEventDTO, error)
+
+	// GetChanges возвращает изменения секретов пользователя из журнала, отобранные по фильтру, в порядке ID.
+	GetChanges(ctx context.Context, userID uint64, filter models.SecretChangeFilterDTO) ([]models.SecretEventDTO, error)
+
+	// GetChangeRange возвращает ID самой старой и самой новой записи журнала изменений пользователя.
+	// Если записей нет, возвращает нули.
+	GetChangeRange(ctx context.Context, userID uint64) (uint64, uint64, error)
 
-	// DeleteByID удаляет секрет по его ID.
-	// Если секрета нет — ничего не делает.
-	DeleteByID(ctx context.Context, id uint64) error
+	// PurgeChangesBefore удаляет из журнала изменения секретов всех пользователей, записанные раньше before.
+	// Возвращает количество удалённых записей.
+	PurgeChangesBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// ApplyBatch выполняет операции пользователя над секретами в одной транзакции.
+	// Ошибки отдельных операций возвращаются в итогах (ErrNotFound, если секрет не найден);
+	// при atomic = true первая же ошибка отменяет весь пакет, а остальные операции получают ErrBatchRolledBack.
+	// Ошибка возвращается, только если не удалось выполнить саму транзакцию.
+	ApplyBatch(ctx context.Context, userID uint64, ops []models.BatchOperationDTO, device string, atomic bool) ([]models.BatchOutcome, error)
+}
+
+// SecretAuditRepository определяет интерфейс журнала аудита действий с секретами.
+type SecretAuditRepository interface {
+	// Create добавляет запись в журнал аудита.
+	Create(ctx context.Context, dto models.CreateSecretAuditDTO) error
+
+	// GetBySecret возвращает записи журнала по секрету пользователя, начиная с последних.
+	GetBySecret(ctx context.Context, userID, secretID uint64) ([]models.ReadSecretAuditDTO, error)
+}
+
+// FolderRepository определяет интерфейс для работы с папками секретов.
+// Все операции, кроме создания, ограничены папками указанного пользователя.
+type FolderRepository interface {
+	// Create сохраняет новую папку.
+	// Возвращает созданную папку или ErrFolderExists, если в родительской папке уже есть папка с таким названием.
+	Create(ctx context.Context, dto models.CreateFolderDTO) (*models.ReadFolderDTO, error)
+
+	// GetByID возвращает папку пользователя по её ID.
+	// Если папка не найдена, возвращается ошибка ErrNotFound.
+	GetByID(ctx context.Context, userID, id uint64) (*models.ReadFolderDTO, error)
+
+	// GetAllByUser возвращает все папки пользователя.
+	GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadFolderDTO, error)
+
+	// Rename изменяет название папки.
+	// Возвращает обновлённую папку, ErrNotFound или ErrFolderExists.
+	Rename(ctx context.Context, userID, id uint64, name string) (*models.ReadFolderDTO, error)
+
+	// Move переносит папку в другую родительскую папку (nil — в корень).
+	// Возвращает обновлённую папку, ErrNotFound или ErrFolderExists.
+	Move(ctx context.Context, userID, id uint64, parentID *uint64) (*models.ReadFolderDTO, error)
+}
+
+// IdempotencyRepository определяет интерфейс хранилища ключей идемпотентности (заголовок Idempotency-Key).
+type IdempotencyRepository interface {
+	// Reserve закрепляет ключ пользователя за новым запросом и возвращает nil, nil.
+	// Если ключ уже есть и создан не раньше expiredBefore, а запрос без сохранённого ответа — не раньше
+	// leaseExpiredBefore, возвращает его запись; ErrNotFound — если ключ был освобождён во время проверки.
+	Reserve(ctx context.Context, userID uint64, key, requestHash string, expiredBefore, leaseExpiredBefore time.Time) (*models.IdempotencyRecordDTO, error)
+
+	// Complete сохраняет HTTP-статус и тело ответа на запрос, за которым закреплён ключ.
+	Complete(ctx context.Context, userID uint64, key string, statusCode int, response []byte) error
+
+	// Release удаляет ключ, чтобы запрос с ним можно было выполнить заново.
+	Release(ctx context.Context, userID uint64, key string) error
+
+	// DeleteExpired удаляет ключи всех пользователей, созданные раньше before.
+	// Возвращает количество удалённых ключей.
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// WebhookRepository определяет интерфейс хранилища вебхуков пользователей и очереди их доставок.
+type WebhookRepository interface {
+	// Create сохраняет новый вебхук с ключом подписи secret.
+	Create(ctx context.Context, dto models.CreateWebhookDTO, secret string) (*models.ReadWebhookDTO, error)
+
+	// GetAllByUser возвращает вебхуки пользователя без ключей подписи.
+	GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadWebhookDTO, error)
+
+	// DeleteByID удаляет вебхук пользователя вместе с журналом его доставок.
+	// Если вебхук не найден, возвращается ошибка ErrNotFound.
+	DeleteByID(ctx context.Context, userID, id uint64) error
+
+	// Enqueue ставит в очередь доставку события всем вебхукам пользователя, подписанным на него.
+	// Возвращает количество созданных доставок.
+	Enqueue(ctx context.Context, userID uint64, event string, payload []byte) (int64, error)
+
+	// ClaimDue берёт в работу до limit доставок, время попытки которых наступило,
+	// и откладывает их следующую попытку на lease.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookTaskDTO, error)
+
+	// SaveAttempt сохраняет результат попытки доставки.
+	SaveAttempt(ctx context.Context, id uint64, attempt models.WebhookAttemptDTO) error
+
+	// GetDeliveries возвращает последние limit доставок вебхука пользователя, начиная с новых.
+	// Если вебхук не найден, возвращается ошибка ErrNotFound.
+	GetDeliveries(ctx context.Context, userID, webhookID uint64, limit int) ([]models.WebhookDeliveryDTO, error)
+}
+
+// LoginAttemptRepository определяет интерфейс журнала попыток входа.
+type LoginAttemptRepository interface {
+	// Record сохраняет попытку входа.
+	Record(ctx context.Context, dto models.LoginAttemptDTO) error
+
+	// IsKnownDevice сообщает, входил ли пользователь раньше с устройства device.
+	IsKnownDevice(ctx context.Context, userID uint64, device string) (bool, error)
+
+	// CountFailures возвращает количество неудачных попыток входа пользователя не раньше since.
+	CountFailures(ctx context.Context, userID uint64, since time.Time) (int, error)
+}
+
+// DeviceCertificateRepository определяет интерфейс хранения сертификатов устройств.
+// Отозванные сертификаты образуют список отзыва (CRL).
+type DeviceCertificateRepository interface {
+	// Create сохраняет сертификат, выданный устройству.
+	Create(ctx context.Context, dto models.CreateDeviceCertificateDTO) (*models.DeviceCertificateDTO, error)
+
+	// GetBySerial возвращает сертификат по серийному номеру.
+	// Если сертификат не найден, возвращается ошибка ErrNotFound.
+	GetBySerial(ctx context.Context, serial string) (*models.DeviceCertificateDTO, error)
+
+	// GetAllByUser возвращает сертификаты устройств пользователя, начиная с новых.
+	GetAllByUser(ctx context.Context, userID uint64) ([]models.DeviceCertificateDTO, error)
+
+	// Revoke отзывает сертификат и возвращает его. Время отзыва уже отозванного сертификата не меняется.
+	// Если сертификат не найден, возвращается ошибка ErrNotFound.
+	Revoke(ctx context.Context, serial string) (*models.DeviceCertificateDTO, error)
+
+	// GetRevoked возвращает отозванные сертификаты, срок действия которых не истёк к моменту now.
+	GetRevoked(ctx context.Context, now time.Time) ([]models.DeviceCertificateDTO, error)
+}
+
+// HealthRepository определяет интерфейс проверки состояния базы данных.
+type HealthRepository interface {
+	// Ping проверяет, что база данных принимает соединения.
+	Ping(ctx context.Context) error
+
+	// MigrationVersion возвращает номер последней применённой миграции и признак прерванной миграции.
+	// Если миграции не применялись, возвращает ErrNotFound.
+	MigrationVersion(ctx context.Context) (uint, bool, error)
 }
 
 // ErrNotFound используется, когда запись не найдена в базе данных.
@@ -46,8 +228,17 @@ var ErrNotFound = fmt.Errorf("not found")
 // ErrUserExists используется, когда попытка создать пользователя с уже существующим userName.
 var ErrUserExists = fmt.Errorf("user already exists")
 
+// ErrFolderExists используется, когда в родительской папке уже есть папка с таким названием.
+var ErrFolderExists = fmt.Errorf("folder already exists")
+
+// ErrInvalidCursor используется, когда курсор постраничной выборки повреждён или выдан для другой сортировки.
+var ErrInvalidCursor = fmt.Errorf("invalid cursor")
+
 // ErrMarshalPayload возникает при ошибке сериализации (marshal) данных секрета в JSON перед сохранением в БД.
 var ErrMarshalPayload = fmt.Errorf("error marshal payload")
 
 // ErrUnmarshalPayload возникает при ошибке десериализации (unmarshal) JSON-данных секрета, полученных из БД.
 var ErrUnmarshalPayload = fmt.Errorf("error unmarshal payload")
+
+// ErrBatchRolledBack используется для операций атомарного пакета, отменённых из-за ошибки другой операции.
+var ErrBatchRolledBack = fmt.Errorf("batch rolled back")
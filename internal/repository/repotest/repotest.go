@@ -0,0 +1,676 @@
+// Package repotest содержит общий набор тестов хранилища: его проходит каждая реализация репозиториев
+// (PostgreSQL и SQLite из пакета repository, память процесса из пакета memory), поэтому сервисы и
+// обработчики ведут себя одинаково с любым хранилищем.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+)
+
+// Backend — набор репозиториев одного хранилища, которые проверяет Run.
+type Backend struct {
+	Users   repository.UserRepository
+	Secrets repository.SecretRepository
+	Folders repository.FolderRepository
+	Devices repository.DeviceCertificateRepository
+	Tx      repository.TxManager
+}
+
+// Run проверяет хранилище общим набором тестов. Функция open вызывается в начале каждого подтеста
+// и должна возвращать пустое хранилище. Сортировка по названию проверяется только на строчных
+// латинских названиях, порядок которых не зависит от правил сравнения строк в базе.
+func Run(t *testing.T, open func(t *testing.T) Backend) {
+	t.Run("Users", func(t *testing.T) { testUsers(t, open(t)) })
+	t.Run("Folders", func(t *testing.T) { testFolders(t, open(t)) })
+	t.Run("Secrets", func(t *testing.T) { testSecrets(t, open(t)) })
+	t.Run("Filter", func(t *testing.T) { testFilter(t, open(t)) })
+	t.Run("Pagination", func(t *testing.T) { testPagination(t, open(t)) })
+	t.Run("Versions", func(t *testing.T) { testVersions(t, open(t)) })
+	t.Run("Trash", func(t *testing.T) { testTrash(t, open(t)) })
+	t.Run("Batch", func(t *testing.T) { testBatch(t, open(t)) })
+	t.Run("Transactions", func(t *testing.T) { testTransactions(t, open(t)) })
+	t.Run("Changes", func(t *testing.T) { testChanges(t, open(t)) })
+	t.Run("Devices", func(t *testing.T) { testDevices(t, open(t)) })
+}
+
+func testUsers(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	created, err := b.Users.CreateUser(ctx, models.CreateUserDTO{UserName: "john", FirstName: "John", LastName: "Doe", PasswordHash: "hash"})
+	require.NoError(t, err)
+	assert.NotZero(t, created.ID)
+	assert.Equal(t, "john", created.UserName)
+	assert.Equal(t, "hash", created.PasswordHash)
+
+	auth, err := b.Users.GetUserByUserName(ctx, "john")
+	require.NoError(t, err)
+	assert.Equal(t, *created, *auth)
+
+	user, err := b.Users.GetUserByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "john", user.UserName)
+	assert.Equal(t, "John", user.FirstName)
+	assert.Equal(t, "Doe", user.LastName)
+	assert.WithinDuration(t, time.Now(), user.CreatedAt, time.Minute)
+
+	_, err = b.Users.CreateUser(ctx, models.CreateUserDTO{UserName: "john", PasswordHash: "other"})
+	assert.ErrorIs(t, err, repository.ErrUserExists)
+	_, err = b.Users.GetUserByUserName(ctx, "jane")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = b.Users.GetUserByID(ctx, created.ID+100)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func testFolders(t *testing.T, b Backend) {
+	ctx := context.Background()
+	userID := createUser(t, b, "john")
+	otherID := createUser(t, b, "jane")
+
+	bank, err := b.Folders.Create(ctx, models.CreateFolderDTO{UserID: userID, Name: "bank"})
+	require.NoError(t, err)
+	assert.Nil(t, bank.ParentID)
+	_, err = b.Folders.Create(ctx, models.CreateFolderDTO{UserID: userID, Name: "bank"})
+	assert.ErrorIs(t, err, repository.ErrFolderExists)
+	_, err = b.Folders.Create(ctx, models.CreateFolderDTO{UserID: otherID, Name: "bank"})
+	assert.NoError(t, err)
+
+	nested, err := b.Folders.Create(ctx, models.CreateFolderDTO{UserID: userID, ParentID: &bank.ID, Name: "bank"})
+	require.NoError(t, err)
+	assert.Equal(t, &bank.ID, nested.ParentID)
+	archive, err := b.Folders.Create(ctx, models.CreateFolderDTO{UserID: userID, Name: "archive"})
+	require.NoError(t, err)
+
+	folder, err := b.Folders.GetByID(ctx, userID, nested.ID)
+	require.NoError(t, err)
+	assert.Equal(t, *nested, *folder)
+	_, err = b.Folders.GetByID(ctx, otherID, nested.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	folders, err := b.Folders.GetAllByUser(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, folders, 3)
+	assert.Equal(t, []uint64{archive.ID, bank.ID, nested.ID}, []uint64{folders[0].ID, folders[1].ID, folders[2].ID})
+
+	_, err = b.Folders.Rename(ctx, userID, archive.ID, "bank")
+	assert.ErrorIs(t, err, repository.ErrFolderExists)
+	_, err = b.Folders.Rename(ctx, otherID, archive.ID, "old")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	renamed, err := b.Folders.Rename(ctx, userID, archive.ID, "old")
+	require.NoError(t, err)
+	assert.Equal(t, "old", renamed.Name)
+
+	_, err = b.Folders.Move(ctx, userID, nested.ID, nil)
+	assert.ErrorIs(t, err, repository.ErrFolderExists)
+	moved, err := b.Folders.Move(ctx, userID, archive.ID, &bank.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &bank.ID, moved.ParentID)
+	moved, err = b.Folders.Move(ctx, userID, archive.ID, nil)
+	require.NoError(t, err)
+	assert.Nil(t, moved.ParentID)
+}
+
+func testSecrets(t *testing.T, b Backend) {
+	ctx := context.Background()
+	userID := createUser(t, b, "john")
+	folder, err := b.Folders.Create(ctx, models.CreateFolderDTO{UserID: userID, Name: "bank"})
+	require.NoError(t, err)
+
+	id, err := b.Secrets.Create(ctx, models.CreateSecretDTO{
+		UserID:   userID,
+		Title:    "card",
+		Data:     text("1234"),
+		FolderID: &folder.ID,
+		Tags:     []string{"work", "bank"},
+		Device:   "laptop",
+	})
+	require.NoError(t, err)
+
+	secret, err := b.Secrets.GetByID(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, secret)
+	assert.Equal(t, id, secret.ID)
+	assert.Equal(t, userID, secret.UserID)
+	assert.Equal(t, "card", secret.Title)
+	assert.Equal(t, text("1234"), secret.Data)
+	assert.Equal(t, &folder.ID, secret.FolderID)
+	assert.Equal(t, []string{"work", "bank"}, secret.Tags)
+	assert.Equal(t, 1, secret.Version)
+	assert.Equal(t, secret.CreatedAt, secret.UpdatedAt)
+
+	id, err = b.Secrets.Create(ctx, models.CreateSecretDTO{UserID: userID, Title: "login", Data: models.SecretDataDTO{
+		LoginPassword: &models.LoginPasswordData{Login: "john", Password: "secret"},
+	}})
+	require.NoError(t, err)
+	secret, err = b.Secrets.GetByID(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, secret)
+	assert.Equal(t, "secret", secret.Data.LoginPassword.Password)
+	assert.Nil(t, secret.FolderID)
+	assert.Equal(t, []string{}, secret.Tags)
+
+	secret, err = b.Secrets.GetByID(ctx, id+100)
+	assert.NoError(t, err)
+	assert.Nil(t, secret)
+}
+
+func testFilter(t *testing.T, b Backend) {
+	ctx := context.Background()
+	userID := createUser(t, b, "john")
+	otherID := createUser(t, b, "jane")
+	folder, err := b.Folders.Create(ctx, models.CreateFolderDTO{UserID: userID, Name: "bank"})
+	require.NoError(t, err)
+
+	card := createSecret(t, b, models.CreateSecretDTO{UserID: userID, Title: "Bank card", Data: text("1234"), FolderID: &folder.ID, Tags: []string{"work", "bank"}})
+	mail := createSecret(t, b, models.CreateSecretDTO{UserID: userID, Title: "Mail", Data: models.SecretDataDTO{
+		LoginPassword: &models.LoginPasswordData{Login: "john", Password: "secret"},
+	}, Tags: []string{"work"}})
+	diary := createSecret(t, b, models.CreateSecretDTO{UserID: userID, Title: "Diary 100%", Data: text("dear diary")})
+	createSecret(t, b, models.CreateSecretDTO{UserID: otherID, Title: "Bank card", Data: text("5678"), Tags: []string{"work"}})
+
+	root := uint64(0)
+	hourAgo := time.Now().Add(-time.Hour)
+	testCases := []struct {
+		name   string
+		filter models.SecretFilterDTO
+		want   []uint64
+	}{
+		{name: "All", filter: models.SecretFilterDTO{}, want: []uint64{diary, mail, card}},
+		{name: "Tag", filter: models.SecretFilterDTO{Tag: "work"}, want: []uint64{mail, card}},
+		{name: "Unknown tag", filter: models.SecretFilterDTO{Tag: "home"}},
+		{name: "Query ignores case", filter: models.SecretFilterDTO{Query: "CARD"}, want: []uint64{card}},
+		{name: "Query escapes wildcards", filter: models.SecretFilterDTO{Query: "0%"}, want: []uint64{diary}},
+		{name: "Type", filter: models.SecretFilterDTO{Type: models.SecretTypeLogin}, want: []uint64{mail}},
+		{name: "Folder", filter: models.SecretFilterDTO{FolderID: &folder.ID}, want: []uint64{card}},
+		{name: "Root folder", filter: models.SecretFilterDTO{FolderID: &root}, want: []uint64{diary, mail}},
+		{name: "Created from", filter: models.SecretFilterDTO{CreatedFrom: &hourAgo}, want: []uint64{diary, mail, card}},
+		{name: "Created to", filter: models.SecretFilterDTO{CreatedTo: &hourAgo}},
+		{name: "Updated from", filter: models.SecretFilterDTO{UpdatedFrom: &hourAgo, Tag: "bank"}, want: []uint64{card}},
+		{name: "Updated to", filter: models.SecretFilterDTO{UpdatedTo: &hourAgo}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			secrets, err := b.Secrets.GetAllByUser(ctx, userID, tc.filter)
+			require.NoError(t, err)
+			var ids []uint64
+			for _, s := range secrets {
+				ids = append(ids, s.ID)
+			}
+			assert.Equal(t, tc.want, ids)
+
+			summaries, err := b.Secrets.GetSummariesByUser(ctx, userID, tc.filter)
+			require.NoError(t, err)
+			ids = nil
+			for _, s := range summaries {
+				ids = append(ids, s.ID)
+			}
+			assert.Equal(t, tc.want, ids)
+		})
+	}
+
+	summaries, err := b.Secrets.GetSummariesByUser(ctx, userID, models.SecretFilterDTO{Tag: "bank"})
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "Bank card", summaries[0].Title)
+	assert.Equal(t, models.SecretTypeText, summaries[0].Type)
+	assert.Equal(t, &folder.ID, summaries[0].FolderID)
+	assert.Equal(t, []string{"work", "bank"}, summaries[0].Tags)
+	assert.Equal(t, 1, summaries[0].Version)
+}
+
+func testPagination(t *testing.T, b Backend) {
+	ctx := context.Background()
+	userID := createUser(t, b, "john")
+	gamma := createSecret(t, b, models.CreateSecretDTO{UserID: userID, Title: "gamma", Data: text("g")})
+	alpha := createSecret(t, b, models.CreateSecretDTO{UserID: userID, Title: "alpha", Data: text("a")})
+	beta := createSecret(t, b, models.CreateSecretDTO{UserID: userID, Title: "beta", Data: text("b")})
+
+	t.Run("Title", func(t *testing.T) {
+		page, err := b.Secrets.GetAllByUser(ctx, userID, models.SecretFilterDTO{Sort: models.SecretSortTitle, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		assert.Equal(t, []uint64{alpha, beta}, []uint64{page[0].ID, page[1].ID})
+
+		after := &models.SecretCursorDTO{Sort: models.SecretSortTitle, Value: page[1].Title, ID: page[1].ID}
+		page, err = b.Secrets.GetAllByUser(ctx, userID, models.SecretFilterDTO{Sort: models.SecretSortTitle, Limit: 2, After: after})
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		assert.Equal(t, gamma, page[0].ID)
+
+		page, err = b.Secrets.GetAllByUser(ctx, userID, models.SecretFilterDTO{Sort: "-" + models.SecretSortTitle, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		assert.Equal(t, gamma, page[0].ID)
+	})
+
+	t.Run("Created at", func(t *testing.T) {
+		sort := "-" + models.SecretSortCreatedAt
+		var ids []uint64
+		var after *models.SecretCursorDTO
+		for range 3 {
+			page, err := b.Secrets.GetSummariesByUser(ctx, userID, models.SecretFilterDTO{Sort: sort, Limit: 1, After: after})
+			require.NoError(t, err)
+			require.Len(t, page, 1)
+			ids = append(ids, page[0].ID)
+			after = &models.SecretCursorDTO{Sort: sort, Value: page[0].CreatedAt.UTC().Format(models.SecretCursorTimeLayout), ID: page[0].ID}
+		}
+		assert.ElementsMatch(t, []uint64{alpha, beta, gamma}, ids)
+
+		page, err := b.Secrets.GetSummariesByUser(ctx, userID, models.SecretFilterDTO{Sort: sort, Limit: 1, After: after})
+		require.NoError(t, err)
+		assert.Empty(t, page)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		filters := []models.SecretFilterDTO{
+			{Sort: "size"},
+			{Sort: models.SecretSortTitle, After: &models.SecretCursorDTO{Sort: models.SecretSortUpdatedAt, Value: "beta", ID: beta}},
+			{Sort: models.SecretSortUpdatedAt, After: &models.SecretCursorDTO{Sort: models.SecretSortUpdatedAt, Value: "yesterday", ID: beta}},
+		}
+		for _, filter := range filters {
+			_, err := b.Secrets.GetAllByUser(ctx, userID, filter)
+			assert.ErrorIs(t, err, repository.ErrInvalidCursor)
+		}
+	})
+}
+
+func testVersions(t *testing.T, b Backend) {
+	ctx := context.Background()
+	userID := createUser(t, b, "john")
+	otherID := createUser(t, b, "jane")
+	folder, err := b.Folders.Create(ctx, models.CreateFolderDTO{UserID: userID, Name: "bank"})
+	require.NoError(t, err)
+	id := createSecret(t, b, models.CreateSecretDTO{UserID: userID, Title: "card", Data: text("1234"), FolderID: &folder.ID, Tags: []string{"bank"}, Device: "laptop"})
+
+	updated, err := b.Secrets.Update(ctx, models.UpdateSecretDTO{ID: id, UserID: userID, Title: "note", Data: text("5678"), Device: "phone"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.Version)
+	assert.Equal(t, "note", updated.Title)
+	assert.Equal(t, text("5678"), updated.Data)
+	assert.Nil(t, updated.FolderID)
+	assert.Equal(t, []string{}, updated.Tags)
+	assert.False(t, updated.UpdatedAt.Before(updated.CreatedAt))
+
+	updated, err = b.Secrets.Update(ctx, models.UpdateSecretDTO{ID: id, UserID: userID, Title: "login", Data: models.SecretDataDTO{
+		LoginPassword: &models.LoginPasswordData{Login: "john", Password: "secret"},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, 3, updated.Version)
+
+	_, err = b.Secrets.Update(ctx, models.UpdateSecretDTO{ID: id, UserID: otherID, Title: "stolen", Data: text("")})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = b.Secrets.Update(ctx, models.UpdateSecretDTO{ID: id + 100, UserID: userID, Title: "missing", Data: text("")})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	versions, err := b.Secrets.GetVersions(ctx, userID, id)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, 2, versions[0].Version)
+	assert.Equal(t, "note", versions[0].Title)
+	assert.Equal(t, models.SecretTypeText, versions[0].Type)
+	assert.Equal(t, "phone", versions[0].Device)
+	assert.Equal(t, 1, versions[1].Version)
+	assert.Equal(t, "card", versions[1].Title)
+
+	version, err := b.Secrets.GetVersion(ctx, userID, id, 1)
+	require.NoError(t, err)
+	assert.Equal(t, id, version.SecretID)
+	assert.Equal(t, text("1234"), version.Data)
+	assert.Equal(t, &folder.ID, version.FolderID)
+	assert.Equal(t, []string{"bank"}, version.Tags)
+	assert.Equal(t, "laptop", version.Device)
+	assert.False(t, version.ArchivedAt.Before(version.CreatedAt))
+
+	_, err = b.Secrets.GetVersion(ctx, userID, id, 3)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = b.Secrets.GetVersion(ctx, otherID, id, 1)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	versions, err = b.Secrets.GetVersions(ctx, otherID, id)
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+}
+
+func testTrash(t *testing.T, b Backend) {
+	ctx := context.Background()
+	userID := createUser(t, b, "john")
+	otherID := createUser(t, b, "jane")
+	first := createSecret(t, b, models.CreateSecretDTO{UserID: userID, Title: "first", Data: text("1")})
+	second := createSecret(t, b, models.CreateSecretDTO{UserID: userID, Title: "second", Data: text("2")})
+
+	assert.ErrorIs(t, b.Secrets.DeleteByID(ctx, otherID, first), repository.ErrNotFound)
+	require.NoError(t, b.Secrets.DeleteByID(ctx, userID, first))
+	assert.ErrorIs(t, b.Secrets.DeleteByID(ctx, userID, first), repository.ErrNotFound)
+
+	secret, err := b.Secrets.GetByID(ctx, first)
+	assert.NoError(t, err)
+	assert.Nil(t, secret)
+	secrets, err := b.Secrets.GetAllByUser(ctx, userID, models.SecretFilterDTO{})
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+	assert.Equal(t, second, secrets[0].ID)
+	_, err = b.Secrets.Update(ctx, models.UpdateSecretDTO{ID: first, UserID: userID, Title: "changed", Data: text("1")})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	trash, err := b.Secrets.GetTrashByUser(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.Equal(t, first, trash[0].ID)
+	assert.Equal(t, "first", trash[0].Title)
+	assert.WithinDuration(t, time.Now(), trash[0].DeletedAt, time.Minute)
+	trash, err = b.Secrets.GetTrashByUser(ctx, otherID)
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+
+	assert.ErrorIs(t, b.Secrets.RestoreFromTrash(ctx, otherID, first), repository.ErrNotFound)
+	require.NoError(t, b.Secrets.RestoreFromTrash(ctx, userID, first))
+	assert.ErrorIs(t, b.Secrets.RestoreFromTrash(ctx, userID, first), repository.ErrNotFound)
+	secret, err = b.Secrets.GetByID(ctx, first)
+	require.NoError(t, err)
+	assert.NotNil(t, secret)
+
+	assert.ErrorIs(t, b.Secrets.PurgeByID(ctx, userID, first), repository.ErrNotFound)
+	require.NoError(t, b.Secrets.DeleteByID(ctx, userID, first))
+	assert.ErrorIs(t, b.Secrets.PurgeByID(ctx, otherID, first), repository.ErrNotFound)
+	require.NoError(t, b.Secrets.PurgeByID(ctx, userID, first))
+	assert.ErrorIs(t, b.Secrets.RestoreFromTrash(ctx, userID, first), repository.ErrNotFound)
+	versions, err := b.Secrets.GetVersions(ctx, userID, first)
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+
+	require.NoError(t, b.Secrets.DeleteByID(ctx, userID, second))
+	count, err := b.Secrets.PurgeDeletedBefore(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	count, err = b.Secrets.PurgeDeletedBefore(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	trash, err = b.Secrets.GetTrashByUser(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+}
+
+func testBatch(t *testing.T, b Backend) {
+	ctx := context.Background()
+	userID := createUser(t, b, "john")
+	existing := createSecret(t, b, models.CreateSecretDTO{UserID: userID, Title: "existing", Data: text("1")})
+	missing := existing + 100
+
+	t.Run("Atomic", func(t *testing.T) {
+		outcomes, err := b.Secrets.ApplyBatch(ctx, userID, []models.BatchOperationDTO{
+			{Op: models.BatchOpCreate, Title: "created", Data: text("2")},
+			{Op: models.BatchOpUpdate, ID: existing, Title: "changed", Data: text("3")},
+			{Op: models.BatchOpDelete, ID: missing},
+		}, "laptop", true)
+		require.NoError(t, err)
+		require.Len(t, outcomes, 3)
+		assert.ErrorIs(t, outcomes[0].Err, repository.ErrBatchRolledBack)
+		assert.ErrorIs(t, outcomes[1].Err, repository.ErrBatchRolledBack)
+		assert.ErrorIs(t, outcomes[2].Err, repository.ErrNotFound)
+		assert.Equal(t, missing, outcomes[2].ID)
+
+		secrets, err := b.Secrets.GetAllByUser(ctx, userID, models.SecretFilterDTO{})
+		require.NoError(t, err)
+		require.Len(t, secrets, 1)
+		assert.Equal(t, "existing", secrets[0].Title)
+		assert.Equal(t, 1, secrets[0].Version)
+	})
+
+	t.Run("Partial", func(t *testing.T) {
+		outcomes, err := b.Secrets.ApplyBatch(ctx, userID, []models.BatchOperationDTO{
+			{Op: models.BatchOpCreate, Title: "created", Data: text("2"), Tags: []string{"batch"}},
+			{Op: models.BatchOpUpdate, ID: missing, Title: "missing", Data: text("3")},
+			{Op: models.BatchOpUpdate, ID: existing, Title: "changed", Data: text("3")},
+			{Op: "rename", ID: existing},
+		}, "laptop", false)
+		require.NoError(t, err)
+		require.Len(t, outcomes, 4)
+		require.NoError(t, outcomes[0].Err)
+		assert.NotZero(t, outcomes[0].ID)
+		assert.Equal(t, 1, outcomes[0].Version)
+		assert.ErrorIs(t, outcomes[1].Err, repository.ErrNotFound)
+		require.NoError(t, outcomes[2].Err)
+		assert.Equal(t, existing, outcomes[2].ID)
+		assert.Equal(t, 2, outcomes[2].Version)
+		assert.Error(t, outcomes[3].Err)
+
+		created, err := b.Secrets.GetByID(ctx, outcomes[0].ID)
+		require.NoError(t, err)
+		require.NotNil(t, created)
+		assert.Equal(t, []string{"batch"}, created.Tags)
+		versions, err := b.Secrets.GetVersions(ctx, userID, existing)
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+		assert.Equal(t, "existing", versions[0].Title)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		outcomes, err := b.Secrets.ApplyBatch(ctx, userID, []models.BatchOperationDTO{
+			{Op: models.BatchOpDelete, ID: existing},
+		}, "laptop", true)
+		require.NoError(t, err)
+		require.Len(t, outcomes, 1)
+		require.NoError(t, outcomes[0].Err)
+		assert.Equal(t, existing, outcomes[0].ID)
+
+		trash, err := b.Secrets.GetTrashByUser(ctx, userID)
+		require.NoError(t, err)
+		require.Len(t, trash, 1)
+		assert.Equal(t, existing, trash[0].ID)
+	})
+}
+
+func testTransactions(t *testing.T, b Backend) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	t.Run("Rollback", func(t *testing.T) {
+		var secretID uint64
+		err := b.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			user, err := b.Users.CreateUser(ctx, models.CreateUserDTO{UserName: "ghost", PasswordHash: "hash"})
+			if err != nil {
+				return err
+			}
+			if _, err := b.Folders.Create(ctx, models.CreateFolderDTO{UserID: user.ID, Name: "bank"}); err != nil {
+				return err
+			}
+			if secretID, err = b.Secrets.Create(ctx, models.CreateSecretDTO{UserID: user.ID, Title: "card", Data: text("1")}); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		assert.ErrorIs(t, err, errAbort)
+
+		_, err = b.Users.GetUserByUserName(ctx, "ghost")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		secret, err := b.Secrets.GetByID(ctx, secretID)
+		assert.NoError(t, err)
+		assert.Nil(t, secret)
+	})
+
+	t.Run("Rollback restores updates", func(t *testing.T) {
+		userID := createUser(t, b, "john")
+		id := createSecret(t, b, models.CreateSecretDTO{UserID: userID, Title: "card", Data: text("1")})
+		err := b.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			if _, err := b.Secrets.Update(ctx, models.UpdateSecretDTO{ID: id, UserID: userID, Title: "changed", Data: text("2")}); err != nil {
+				return err
+			}
+			if err := b.Secrets.DeleteByID(ctx, userID, id); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		assert.ErrorIs(t, err, errAbort)
+
+		secret, err := b.Secrets.GetByID(ctx, id)
+		require.NoError(t, err)
+		require.NotNil(t, secret)
+		assert.Equal(t, "card", secret.Title)
+		assert.Equal(t, 1, secret.Version)
+		versions, err := b.Secrets.GetVersions(ctx, userID, id)
+		require.NoError(t, err)
+		assert.Empty(t, versions)
+	})
+
+	t.Run("Commit", func(t *testing.T) {
+		err := b.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			user, err := b.Users.CreateUser(ctx, models.CreateUserDTO{UserName: "jane", PasswordHash: "hash"})
+			if err != nil {
+				return err
+			}
+			return b.Tx.WithinTx(ctx, func(ctx context.Context) error {
+				_, err := b.Folders.Create(ctx, models.CreateFolderDTO{UserID: user.ID, Name: "bank"})
+				return err
+			})
+		})
+		require.NoError(t, err)
+
+		user, err := b.Users.GetUserByUserName(ctx, "jane")
+		require.NoError(t, err)
+		folders, err := b.Folders.GetAllByUser(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Len(t, folders, 1)
+	})
+}
+
+func testChanges(t *testing.T, b Backend) {
+	ctx := context.Background()
+	userID := createUser(t, b, "john")
+	otherID := createUser(t, b, "jane")
+	record := func(ctx context.Context, event models.SecretEventDTO) *models.SecretEventDTO {
+		t.Helper()
+		change, err := b.Secrets.CreateChange(ctx, event)
+		require.NoError(t, err)
+		return change
+	}
+
+	first := record(ctx, models.SecretEventDTO{Type: models.SecretEventCreated, UserID: userID, SecretID: 1, Version: 1})
+	assert.NotZero(t, first.ID)
+	assert.Equal(t, models.SecretEventCreated, first.Type)
+	assert.WithinDuration(t, time.Now(), first.Time, time.Minute)
+	record(ctx, models.SecretEventDTO{Type: models.SecretEventCreated, UserID: otherID, SecretID: 2, Version: 1})
+	second := record(ctx, models.SecretEventDTO{Type: models.SecretEventDeleted, UserID: userID, SecretID: 1})
+	assert.Greater(t, second.ID, first.ID)
+
+	err := b.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		record(ctx, models.SecretEventDTO{Type: models.SecretEventRestored, UserID: userID, SecretID: 1})
+		return errors.New("abort")
+	})
+	assert.Error(t, err)
+
+	changes, err := b.Secrets.GetChanges(ctx, userID, models.SecretChangeFilterDTO{})
+	require.NoError(t, err)
+	require.Len(t, changes, 2, "rolled back change must not be kept")
+	assert.Equal(t, *first, changes[0])
+	assert.Equal(t, second.ID, changes[1].ID)
+	assert.Equal(t, models.SecretEventDeleted, changes[1].Type)
+
+	changes, err = b.Secrets.GetChanges(ctx, userID, models.SecretChangeFilterDTO{AfterID: first.ID})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, second.ID, changes[0].ID)
+	changes, err = b.Secrets.GetChanges(ctx, userID, models.SecretChangeFilterDTO{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, first.ID, changes[0].ID)
+	changes, err = b.Secrets.GetChanges(ctx, userID, models.SecretChangeFilterDTO{Since: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	oldest, newest, err := b.Secrets.GetChangeRange(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, oldest)
+	assert.Equal(t, second.ID, newest)
+
+	count, err := b.Secrets.PurgeChangesBefore(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	count, err = b.Secrets.PurgeChangesBefore(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	changes, err = b.Secrets.GetChanges(ctx, userID, models.SecretChangeFilterDTO{})
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	oldest, newest, err = b.Secrets.GetChangeRange(ctx, userID)
+	require.NoError(t, err)
+	assert.Zero(t, oldest)
+	assert.Zero(t, newest)
+}
+
+func testDevices(t *testing.T, b Backend) {
+	ctx := context.Background()
+	userID := createUser(t, b, "john")
+	otherID := createUser(t, b, "jane")
+	expires := time.Now().Add(90 * 24 * time.Hour).UTC().Truncate(time.Second)
+
+	laptop, err := b.Devices.Create(ctx, models.CreateDeviceCertificateDTO{UserID: userID, Device: "laptop", Serial: "0a", Thumbprint: "t1", ExpiresAt: expires})
+	require.NoError(t, err)
+	assert.NotZero(t, laptop.ID)
+	assert.True(t, expires.Equal(laptop.ExpiresAt))
+	assert.WithinDuration(t, time.Now(), laptop.CreatedAt, time.Minute)
+	assert.Nil(t, laptop.RevokedAt)
+	_, err = b.Devices.Create(ctx, models.CreateDeviceCertificateDTO{UserID: userID, Device: "phone", Serial: "0b", Thumbprint: "t2", ExpiresAt: expires})
+	require.NoError(t, err)
+	_, err = b.Devices.Create(ctx, models.CreateDeviceCertificateDTO{UserID: otherID, Device: "tablet", Serial: "0c", Thumbprint: "t3", ExpiresAt: expires})
+	require.NoError(t, err)
+	_, err = b.Devices.Create(ctx, models.CreateDeviceCertificateDTO{UserID: otherID, Device: "copy", Serial: "0c", Thumbprint: "t4", ExpiresAt: expires})
+	assert.Error(t, err)
+
+	found, err := b.Devices.GetBySerial(ctx, "0a")
+	require.NoError(t, err)
+	assert.Equal(t, laptop.ID, found.ID)
+	assert.Equal(t, "t1", found.Thumbprint)
+	_, err = b.Devices.GetBySerial(ctx, "ff")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	certs, err := b.Devices.GetAllByUser(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, certs, 2)
+	assert.Equal(t, "phone", certs[0].Device)
+	assert.Equal(t, "laptop", certs[1].Device)
+
+	revoked, err := b.Devices.Revoke(ctx, "0a")
+	require.NoError(t, err)
+	require.NotNil(t, revoked.RevokedAt)
+	assert.WithinDuration(t, time.Now(), *revoked.RevokedAt, time.Minute)
+	again, err := b.Devices.Revoke(ctx, "0a")
+	require.NoError(t, err)
+	assert.True(t, revoked.RevokedAt.Equal(*again.RevokedAt))
+	_, err = b.Devices.Revoke(ctx, "ff")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	crl, err := b.Devices.GetRevoked(ctx, time.Now())
+	require.NoError(t, err)
+	require.Len(t, crl, 1)
+	assert.Equal(t, "0a", crl[0].Serial)
+	crl, err = b.Devices.GetRevoked(ctx, expires.Add(time.Second))
+	require.NoError(t, err)
+	assert.Empty(t, crl)
+}
+
+// createUser создаёт пользователя и возвращает его ID.
+func createUser(t *testing.T, b Backend, userName string) uint64 {
+	t.Helper()
+	user, err := b.Users.CreateUser(context.Background(), models.CreateUserDTO{UserName: userName, PasswordHash: "hash"})
+	require.NoError(t, err)
+	return user.ID
+}
+
+// createSecret создаёт секрет и возвращает его ID.
+func createSecret(t *testing.T, b Backend, dto models.CreateSecretDTO) uint64 {
+	t.Helper()
+	id, err := b.Secrets.Create(context.Background(), dto)
+	require.NoError(t, err)
+	return id
+}
+
+// text возвращает данные текстового секрета.
+func text(s string) models.SecretDataDTO {
+	return models.SecretDataDTO{Text: &s}
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// CreateChange добавляет изменение секрета в журнал изменений и возвращает его с ID и временем записи.
+// Перед записью блокируется строка владельца: транзакции одного пользователя, меняющие секреты,
+// фиксируются по очереди, и ID его изменений растут в порядке фиксации. Поэтому клиент, получивший
+// изменение с некоторым ID, уже не увидит позже изменение с меньшим ID. Вызывается внутри TxManager.WithinTx.
+// Возвращает ErrNotFound, если пользователь не найден.
+func (r *SecretRepositoryImpl) CreateChange(ctx context.Context, event models.SecretEventDTO) (*models.SecretEventDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "secret_changes.create")
+	defer span.End()
+
+	q := conn(ctx, r.db)
+	lockQuery := `
+		select id
+		from users
+		where id = $1` + r.dialect.lockRow() + `;
+	`
+	var userID uint64
+	err := q.QueryRowContext(ctx, lockQuery, event.UserID).Scan(&userID)
+	if err == sql.ErrNoRows {
+		r.logger.For(ctx).Warn("Владелец изменённого секрета не найден", zap.Uint64("user_id", event.UserID))
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при блокировке пользователя для журнала изменений", zap.Uint64("user_id", event.UserID), zap.Error(err))
+		return nil, err
+	}
+
+	query := `
+		insert into secret_changes (user_id, secret_id, type, version)
+		values ($1, $2, $3, $4)
+		returning id, created_at;
+	`
+	change := event
+	if err := q.QueryRowContext(ctx, query, event.UserID, event.SecretID, event.Type, event.Version).Scan(&change.ID, &change.Time); err != nil {
+		r.logger.For(ctx).Error("Ошибка при записи в журнал изменений секретов", zap.Uint64("secret_id", event.SecretID), zap.String("type", event.Type), zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.For(ctx).Info("Изменение секрета записано в журнал", zap.Uint64("change_id", change.ID), zap.Uint64("secret_id", change.SecretID), zap.String("type", change.Type))
+	return &change, nil
+}
+
+// GetChanges возвращает изменения секретов пользователя из журнала, отобранные по фильтру, в порядке ID.
+func (r *SecretRepositoryImpl) GetChanges(ctx context.Context, userID uint64, filter models.SecretChangeFilterDTO) ([]models.SecretEventDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "secret_changes.get")
+	defer span.End()
+
+	conditions := []string{"user_id = $1", "id > $2"}
+	args := []any{userID, filter.AfterID}
+	if !filter.Since.IsZero() {
+		args = append(args, r.dialect.timeArg(filter.Since))
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	query := `
+		select id, secret_id, type, version, created_at
+		from secret_changes
+		where ` + strings.Join(conditions, " and ") + `
+		order by id`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf("\n\t\tlimit $%d", len(args))
+	}
+	query += ";"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при получении журнала изменений секретов", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []models.SecretEventDTO
+	for rows.Next() {
+		change := models.SecretEventDTO{UserID: userID}
+		if err := rows.Scan(&change.ID, &change.SecretID, &change.Type, &change.Version, &change.Time); err != nil {
+			r.logger.For(ctx).Error("Ошибка при чтении записи журнала изменений", zap.Error(err))
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.For(ctx).Error("Ошибка при чтении журнала изменений секретов", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	return changes, nil
+}
+
+// GetChangeRange возвращает ID самой старой и самой новой записи журнала изменений пользователя.
+// Если записей нет, возвращает нули.
+func (r *SecretRepositoryImpl) GetChangeRange(ctx context.Context, userID uint64) (uint64, uint64, error) {
+	ctx, span := tracing.StartDB(ctx, "secret_changes.get_range")
+	defer span.End()
+
+	query := `
+		select coalesce(min(id), 0), coalesce(max(id), 0)
+		from secret_changes
+		where user_id = $1;
+	`
+	var oldest, newest uint64
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, userID).Scan(&oldest, &newest); err != nil {
+		r.logger.For(ctx).Error("Ошибка при получении границ журнала изменений секретов", zap.Uint64("user_id", userID), zap.Error(err))
+		return 0, 0, err
+	}
+	return oldest, newest, nil
+}
+
+// PurgeChangesBefore удаляет из журнала изменения секретов всех пользователей, записанные раньше before.
+// Возвращает количество удалённых записей.
+func (r *SecretRepositoryImpl) PurgeChangesBefore(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := tracing.StartDB(ctx, "secret_changes.purge_before")
+	defer span.End()
+
+	query := `
+		delete from secret_changes
+		where created_at < $1;
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, r.dialect.timeArg(before))
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при очистке журнала изменений секретов", zap.Error(err))
+		return 0, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при получении количества удалённых записей журнала", zap.Error(err))
+		return 0, err
+	}
+
+	r.logger.For(ctx).Info("Журнал изменений секретов очищен", zap.Int64("count", count))
+	return count, nil
+}
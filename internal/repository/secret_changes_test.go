@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretRepositoryImpl_CreateChange(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	lockQuery := regexp.QuoteMeta(`
+		select id
+		from users
+		where id = $1
+		for update;
+	`)
+	insertQuery := regexp.QuoteMeta(`
+		insert into secret_changes (user_id, secret_id, type, version)
+		values ($1, $2, $3, $4)
+		returning id, created_at;
+	`)
+	event := models.SecretEventDTO{Type: models.SecretEventUpdated, UserID: 1, SecretID: 7, Version: 3}
+	now := time.Now()
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(lockQuery).WithArgs(uint64(1)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(insertQuery).
+			WithArgs(uint64(1), uint64(7), models.SecretEventUpdated, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(42, now))
+
+		change, err := repo.CreateChange(context.Background(), event)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(42), change.ID)
+		assert.Equal(t, now, change.Time)
+		assert.Equal(t, uint64(7), change.SecretID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("User_not_found", func(t *testing.T) {
+		mock.ExpectQuery(lockQuery).WithArgs(uint64(1)).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.CreateChange(context.Background(), event)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Insert_error", func(t *testing.T) {
+		mock.ExpectQuery(lockQuery).WithArgs(uint64(1)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(insertQuery).WillReturnError(assert.AnError)
+
+		_, err := repo.CreateChange(context.Background(), event)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSecretRepositoryImpl_GetChanges(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	columns := []string{"id", "secret_id", "type", "version", "created_at"}
+	now := time.Now()
+
+	t.Run("After_id", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`
+		select id, secret_id, type, version, created_at
+		from secret_changes
+		where user_id = $1 and id > $2
+		order by id
+		limit $3;`)).
+			WithArgs(uint64(1), uint64(10), 500).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(11, 7, models.SecretEventCreated, 1, now).AddRow(12, 7, models.SecretEventDeleted, 0, now))
+
+		changes, err := repo.GetChanges(context.Background(), 1, models.SecretChangeFilterDTO{AfterID: 10, Limit: 500})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.SecretEventDTO{
+			{ID: 11, Type: models.SecretEventCreated, UserID: 1, SecretID: 7, Version: 1, Time: now},
+			{ID: 12, Type: models.SecretEventDeleted, UserID: 1, SecretID: 7, Time: now},
+		}, changes)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Since", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`where user_id = $1 and id > $2 and created_at >= $3
+		order by id;`)).
+			WithArgs(uint64(1), uint64(0), now).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		changes, err := repo.GetChanges(context.Background(), 1, models.SecretChangeFilterDTO{Since: now})
+		assert.NoError(t, err)
+		assert.Empty(t, changes)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rows_error", func(t *testing.T) {
+		mock.ExpectQuery("from secret_changes").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(11, 7, models.SecretEventCreated, 1, now).RowError(0, assert.AnError))
+
+		_, err := repo.GetChanges(context.Background(), 1, models.SecretChangeFilterDTO{})
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSecretRepositoryImpl_GetChangeRange(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	query := regexp.QuoteMeta(`
+		select coalesce(min(id), 0), coalesce(max(id), 0)
+		from secret_changes
+		where user_id = $1;
+	`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(uint64(1)).WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).AddRow(5, 12))
+
+		oldest, newest, err := repo.GetChangeRange(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(5), oldest)
+		assert.Equal(t, uint64(12), newest)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(uint64(1)).WillReturnError(assert.AnError)
+
+		_, _, err := repo.GetChangeRange(context.Background(), 1)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSecretRepositoryImpl_PurgeChangesBefore(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	query := regexp.QuoteMeta(`
+		delete from secret_changes
+		where created_at < $1;
+	`)
+	before := time.Now()
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 4))
+
+		count, err := repo.PurgeChangesBefore(context.Background(), before)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(assert.AnError)
+
+		_, err := repo.PurgeChangesBefore(context.Background(), before)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
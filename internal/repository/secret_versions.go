@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// GetVersions возвращает метаданные предыдущих версий секрета пользователя, начиная с последней.
+func (r *SecretRepositoryImpl) GetVersions(ctx context.Context, userID, secretID uint64) ([]models.SecretVersionDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "secrets.get_versions")
+	defer span.End()
+
+	query := `
+		select version, title, type, device, created_at, archived_at
+		from secret_versions
+		where user_id = $1 and secret_id = $2
+		order by version desc;
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID, secretID)
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при получении версий секрета", zap.Uint64("secret_id", secretID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []models.SecretVersionDTO
+	for rows.Next() {
+		var dto models.SecretVersionDTO
+		var typ sql.NullString
+		if err := rows.Scan(&dto.Version, &dto.Title, &typ, &dto.Device, &dto.CreatedAt, &dto.ArchivedAt); err != nil {
+			r.logger.For(ctx).Error("Ошибка при чтении версии секрета", zap.Error(err))
+			return nil, err
+		}
+		dto.Type = typ.String
+		versions = append(versions, dto)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.For(ctx).Error("Ошибка при чтении версий секрета", zap.Uint64("secret_id", secretID), zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.For(ctx).Info("Версии секрета успешно получены", zap.Uint64("secret_id", secretID), zap.Int("count", len(versions)))
+	return versions, nil
+}
+
+// GetVersion возвращает предыдущую версию секрета пользователя вместе с данными.
+// Если версия не найдена — возвращает ErrNotFound.
+func (r *SecretRepositoryImpl) GetVersion(ctx context.Context, userID, secretID uint64, version int) (*models.ReadSecretVersionDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "secrets.get_version")
+	defer span.End()
+
+	query := `
+		select secret_id, version, title, data, folder_id, tags, device, created_at, archived_at
+		from secret_versions
+		where user_id = $1 and secret_id = $2 and version = $3;
+	`
+
+	var dto models.ReadSecretVersionDTO
+	var rawData, rawTags []byte
+	var folderID sql.NullInt64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, userID, secretID, version).
+		Scan(&dto.SecretID, &dto.Version, &dto.Title, &rawData, &folderID, &rawTags, &dto.Device, &dto.CreatedAt, &dto.ArchivedAt)
+	if err == sql.ErrNoRows {
+		r.logger.For(ctx).Warn("Версия секрета не найдена", zap.Uint64("secret_id", secretID), zap.Int("version", version))
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при получении версии секрета", zap.Uint64("secret_id", secretID), zap.Int("version", version), zap.Error(err))
+		return nil, err
+	}
+
+	if err := json.Unmarshal(rawData, &dto.Data); err != nil {
+		r.logger.For(ctx).Error("Ошибка при анмаршалинге данных версии секрета", zap.Uint64("secret_id", secretID), zap.Error(err))
+		return nil, ErrUnmarshalPayload
+	}
+	if dto.Tags, err = unmarshalTags(rawTags); err != nil {
+		r.logger.For(ctx).Error("Ошибка при анмаршалинге тегов версии секрета", zap.Uint64("secret_id", secretID), zap.Error(err))
+		return nil, ErrUnmarshalPayload
+	}
+	dto.FolderID = idFromNull(folderID)
+
+	r.logger.For(ctx).Info("Версия секрета успешно получена", zap.Uint64("secret_id", secretID), zap.Int("version", version))
+	return &dto, nil
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretRepositoryImpl_Update(t *testing.T) {
+	cfg := config.GetConfig()
+	cfg.SecretVersionsRetention = 3
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	dto := models.UpdateSecretDTO{
+		ID:     7,
+		UserID: 42,
+		Title:  "VPN",
+		Data:   models.SecretDataDTO{Text: ptr("new password")},
+		Tags:   []string{"work"},
+		Device: "laptop",
+	}
+	dataBytes, _ := json.Marshal(dto.Data)
+	now := time.Now()
+
+	lockQuery := regexp.QuoteMeta(`
+		select version
+		from secrets
+		where id = $1 and user_id = $2 and deleted_at is null
+		for update;
+	`)
+	archiveQuery := regexp.QuoteMeta(`
+		insert into secret_versions (secret_id, user_id, version, title, data, folder_id, tags, device, created_at)
+		select id, user_id, version, title, data, folder_id, tags, device, updated_at
+		from secrets
+		where id = $1;
+	`)
+	updateQuery := regexp.QuoteMeta(`
+		update secrets
+		set title = $1, data = $2, folder_id = $3, tags = $4, device = $5, version = version + 1, updated_at = now()
+		where id = $6
+		returning id, user_id, title, data, folder_id, tags, version, created_at, updated_at;
+	`)
+	pruneQuery := regexp.QuoteMeta(`
+		delete from secret_versions
+		where secret_id = $1 and version <= $2;
+	`)
+
+	t.Run("Success_with_prune", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).
+			WithArgs(uint64(7), uint64(42)).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(5))
+		mock.ExpectExec(archiveQuery).
+			WithArgs(uint64(7)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(updateQuery).
+			WithArgs("VPN", dataBytes, nil, []byte(`["work"]`), "laptop", uint64(7)).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "user_id", "title", "data", "folder_id", "tags", "version", "created_at", "updated_at",
+			}).AddRow(uint64(7), uint64(42), "VPN", dataBytes, nil, []byte(`["work"]`), 6, now, now))
+		mock.ExpectExec(pruneQuery).
+			WithArgs(uint64(7), 2).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		secret, err := repo.Update(context.Background(), dto)
+		assert.NoError(t, err)
+		assert.Equal(t, 6, secret.Version)
+		assert.Equal(t, "new password", *secret.Data.Text)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Within_retention", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).
+			WithArgs(uint64(7), uint64(42)).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+		mock.ExpectExec(archiveQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(updateQuery).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "user_id", "title", "data", "folder_id", "tags", "version", "created_at", "updated_at",
+			}).AddRow(uint64(7), uint64(42), "VPN", dataBytes, nil, []byte(`["work"]`), 2, now, now))
+		mock.ExpectCommit()
+
+		secret, err := repo.Update(context.Background(), dto)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, secret.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not_found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).
+			WithArgs(uint64(7), uint64(42)).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		secret, err := repo.Update(context.Background(), dto)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Nil(t, secret)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Archive_error", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+		mock.ExpectExec(archiveQuery).WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		secret, err := repo.Update(context.Background(), dto)
+		assert.Error(t, err)
+		assert.Nil(t, secret)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSecretRepositoryImpl_GetVersions(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	query := regexp.QuoteMeta(`
+		select version, title, type, device, created_at, archived_at
+		from secret_versions
+		where user_id = $1 and secret_id = $2
+		order by version desc;
+	`)
+	now := time.Now()
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(uint64(42), uint64(7)).
+			WillReturnRows(sqlmock.NewRows([]string{"version", "title", "type", "device", "created_at", "archived_at"}).
+				AddRow(2, "VPN", "text", "laptop", now, now).
+				AddRow(1, "VPN old", nil, "", now, now))
+
+		versions, err := repo.GetVersions(context.Background(), 42, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.SecretVersionDTO{
+			{Version: 2, Title: "VPN", Type: "text", Device: "laptop", CreatedAt: now, ArchivedAt: now},
+			{Version: 1, Title: "VPN old", CreatedAt: now, ArchivedAt: now},
+		}, versions)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Query_error", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(assert.AnError)
+
+		versions, err := repo.GetVersions(context.Background(), 42, 7)
+		assert.Error(t, err)
+		assert.Nil(t, versions)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSecretRepositoryImpl_GetVersion(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	query := regexp.QuoteMeta(`
+		select secret_id, version, title, data, folder_id, tags, device, created_at, archived_at
+		from secret_versions
+		where user_id = $1 and secret_id = $2 and version = $3;
+	`)
+	columns := []string{"secret_id", "version", "title", "data", "folder_id", "tags", "device", "created_at", "archived_at"}
+	dataBytes, _ := json.Marshal(models.SecretDataDTO{Text: ptr("old password")})
+	now := time.Now()
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(uint64(42), uint64(7), 2).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(uint64(7), 2, "VPN", dataBytes, int64(3), []byte(`["work"]`), "laptop", now, now))
+
+		version, err := repo.GetVersion(context.Background(), 42, 7, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "old password", *version.Data.Text)
+		assert.Equal(t, uint64(3), *version.FolderID)
+		assert.Equal(t, []string{"work"}, version.Tags)
+		assert.Equal(t, "laptop", version.Device)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not_found", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(uint64(42), uint64(7), 9).
+			WillReturnError(sql.ErrNoRows)
+
+		version, err := repo.GetVersion(context.Background(), 42, 7, 9)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Nil(t, version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Broken_data", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(uint64(7), 2, "VPN", []byte("broken"), nil, []byte(`[]`), "", now, now))
+
+		version, err := repo.GetVersion(context.Background(), 42, 7, 2)
+		assert.ErrorIs(t, err, ErrUnmarshalPayload)
+		assert.Nil(t, version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
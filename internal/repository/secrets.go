@@ -5,148 +5,415 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
-	_ "github.com/jackc/pgx/stdlib"
 	"go.uber.org/zap"
 
 	"github.com/shekshuev/gophkeeper/internal/config"
 	"github.com/shekshuev/gophkeeper/internal/logger"
 	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
 )
 
-// SecretRepositoryImpl — реализация интерфейса SecretRepository для работы с секретами в PostgreSQL.
+// SecretRepositoryImpl — реализация интерфейса SecretRepository для работы с секретами в PostgreSQL или SQLite.
 type SecretRepositoryImpl struct {
-	db     *sql.DB        // соединение с базой данных
-	cfg    *config.Config // конфигурация приложения
-	logger *logger.Logger // логгер
+	db      *sql.DB        // соединение с базой данных
+	cfg     *config.Config // конфигурация приложения
+	dialect dialect        // диалект SQL базы
+	logger  *logger.Logger // логгер
 }
 
 // NewSecretRepositoryImpl создаёт новый экземпляр SecretRepositoryImpl.
-// Устанавливает соединение с базой данных на основе переданного DSN из конфигурации.
-func NewSecretRepositoryImpl(cfg *config.Config) *SecretRepositoryImpl {
-	log := logger.NewLogger()
-
-	db, err := sql.Open("pgx", cfg.DatabaseDSN)
-	if err != nil {
-		log.Log.Fatal("Не удалось подключиться к базе данных", zap.Error(err))
-	}
-	log.Log.Info("Установлено соединение с базой данных (secrets)")
-
+// Работает через общий пул соединений db (см. database.Open).
+func NewSecretRepositoryImpl(db *sql.DB, cfg *config.Config) *SecretRepositoryImpl {
 	return &SecretRepositoryImpl{
-		db:     db,
-		cfg:    cfg,
-		logger: log,
+		db:      db,
+		cfg:     cfg,
+		dialect: dialectOf(cfg),
+		logger:  logger.NewLogger(),
 	}
 }
 
 // Create сохраняет новый секрет в базу данных.
-// Принимает DTO с userID, названием и данными (в виде map).
+// Принимает DTO с userID, названием, данными (в виде map), папкой, тегами и устройством автора.
 // Возвращает ID созданного секрета или ошибку.
 func (r *SecretRepositoryImpl) Create(ctx context.Context, dto models.CreateSecretDTO) (uint64, error) {
+	ctx, span := tracing.StartDB(ctx, "secrets.create")
+	defer span.End()
+
+	id, err := r.insert(ctx, conn(ctx, r.db), dto)
+	if err != nil {
+		return 0, err
+	}
+
+	r.logger.For(ctx).Info("Секрет успешно создан", zap.Uint64("secret_id", id), zap.Uint64("user_id", dto.UserID))
+	return id, nil
+}
+
+// insert добавляет секрет через q (соединение или транзакцию) и возвращает его ID.
+func (r *SecretRepositoryImpl) insert(ctx context.Context, q querier, dto models.CreateSecretDTO) (uint64, error) {
 	dataBytes, err := json.Marshal(dto.Data)
 	if err != nil {
-		r.logger.Log.Error("Ошибка маршалинга данных секрета", zap.Error(err))
+		r.logger.For(ctx).Error("Ошибка маршалинга данных секрета", zap.Error(err))
+		return 0, ErrMarshalPayload
+	}
+	tagsBytes, err := marshalTags(dto.Tags)
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка маршалинга тегов секрета", zap.Error(err))
 		return 0, ErrMarshalPayload
 	}
 
 	query := `
-		insert into secrets (user_id, title, data)
-		values ($1, $2, $3)
+		insert into secrets (user_id, title, data, folder_id, tags, device)
+		values ($1, $2, $3, $4, $5, $6)
 		returning id;
 	`
 	var id uint64
-	err = r.db.QueryRowContext(ctx, query, dto.UserID, dto.Title, dataBytes).Scan(&id)
+	err = q.QueryRowContext(ctx, query, dto.UserID, dto.Title, r.dialect.jsonArg(dataBytes), nullableID(dto.FolderID), r.dialect.jsonArg(tagsBytes), dto.Device).Scan(&id)
 	if err != nil {
-		r.logger.Log.Error("Ошибка при вставке секрета", zap.Uint64("user_id", dto.UserID), zap.String("title", dto.Title), zap.Error(err))
+		r.logger.For(ctx).Error("Ошибка при вставке секрета", zap.Uint64("user_id", dto.UserID), zap.String("title", dto.Title), zap.Error(err))
 		return 0, fmt.Errorf("insert secret: %w", err)
 	}
-
-	r.logger.Log.Info("Секрет успешно создан", zap.Uint64("secret_id", id), zap.Uint64("user_id", dto.UserID))
 	return id, nil
 }
 
-// GetByID возвращает секрет по его ID.
+// Update изменяет секрет пользователя, сохраняя его текущее состояние в истории версий.
+// Все шаги выполняются в одной транзакции: строка секрета блокируется, текущая версия копируется
+// в secret_versions, секрет обновляется с увеличением номера версии, а версии сверх
+// cfg.SecretVersionsRetention удаляются. Внутри TxManager.WithinTx шаги выполняются во внешней транзакции.
+// Возвращает обновлённый секрет или ErrNotFound.
+func (r *SecretRepositoryImpl) Update(ctx context.Context, dto models.UpdateSecretDTO) (*models.ReadSecretDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "secrets.update")
+	defer span.End()
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		r.logger.For(ctx).Error("Не удалось начать транзакцию", zap.Uint64("secret_id", dto.ID), zap.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	secret, err := r.update(ctx, tx.Tx, dto)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.For(ctx).Error("Не удалось зафиксировать изменение секрета", zap.Uint64("secret_id", dto.ID), zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.For(ctx).Info("Секрет успешно изменён", zap.Uint64("secret_id", secret.ID), zap.Int("version", secret.Version))
+	return secret, nil
+}
+
+// update изменяет секрет внутри транзакции tx: блокирует строку, архивирует текущую версию,
+// обновляет секрет и удаляет версии сверх срока хранения. Фиксация транзакции остаётся за вызывающим.
+func (r *SecretRepositoryImpl) update(ctx context.Context, tx *sql.Tx, dto models.UpdateSecretDTO) (*models.ReadSecretDTO, error) {
+	dataBytes, err := json.Marshal(dto.Data)
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка маршалинга данных секрета", zap.Error(err))
+		return nil, ErrMarshalPayload
+	}
+	tagsBytes, err := marshalTags(dto.Tags)
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка маршалинга тегов секрета", zap.Error(err))
+		return nil, ErrMarshalPayload
+	}
+
+	lockQuery := `
+		select version
+		from secrets
+		where id = $1 and user_id = $2 and deleted_at is null` + r.dialect.lockRow() + `;
+	`
+	var version int
+	err = tx.QueryRowContext(ctx, lockQuery, dto.ID, dto.UserID).Scan(&version)
+	if err == sql.ErrNoRows {
+		r.logger.For(ctx).Warn("Секрет для изменения не найден", zap.Uint64("secret_id", dto.ID), zap.Uint64("user_id", dto.UserID))
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при блокировке секрета", zap.Uint64("secret_id", dto.ID), zap.Error(err))
+		return nil, err
+	}
+
+	archiveQuery := `
+		insert into secret_versions (secret_id, user_id, version, title, data, folder_id, tags, device, created_at)
+		select id, user_id, version, title, data, folder_id, tags, device, updated_at
+		from secrets
+		where id = $1;
+	`
+	if _, err = tx.ExecContext(ctx, archiveQuery, dto.ID); err != nil {
+		r.logger.For(ctx).Error("Ошибка при сохранении версии секрета", zap.Uint64("secret_id", dto.ID), zap.Error(err))
+		return nil, err
+	}
+
+	updateQuery := `
+		update secrets
+		set title = $1, data = $2, folder_id = $3, tags = $4, device = $5, version = version + 1, updated_at = now()
+		where id = $6
+		returning ` + secretColumns + `;
+	`
+	secret, err := scanSecret(tx.QueryRowContext(ctx, updateQuery, dto.Title, r.dialect.jsonArg(dataBytes), nullableID(dto.FolderID), r.dialect.jsonArg(tagsBytes), dto.Device, dto.ID))
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при изменении секрета", zap.Uint64("secret_id", dto.ID), zap.Error(err))
+		return nil, err
+	}
+
+	if retention := r.cfg.SecretVersionsRetention; retention > 0 && version > retention {
+		pruneQuery := `
+			delete from secret_versions
+			where secret_id = $1 and version <= $2;
+		`
+		if _, err = tx.ExecContext(ctx, pruneQuery, dto.ID, version-retention); err != nil {
+			r.logger.For(ctx).Error("Ошибка при удалении старых версий секрета", zap.Uint64("secret_id", dto.ID), zap.Error(err))
+			return nil, err
+		}
+	}
+	return secret, nil
+}
+
+// GetByID возвращает секрет по его ID. Секреты из корзины не возвращаются.
 // Если секрет не найден — возвращает nil, nil.
 func (r *SecretRepositoryImpl) GetByID(ctx context.Context, id uint64) (*models.ReadSecretDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "secrets.get_by_id")
+	defer span.End()
+
 	query := `
-		select id, user_id, title, data, created_at, updated_at
+		select ` + secretColumns + `
 		from secrets
-		where id = $1;
+		where id = $1 and deleted_at is null;
 	`
 
-	var dto models.ReadSecretDTO
-	var rawData []byte
-	err := r.db.QueryRowContext(ctx, query, id).
-		Scan(&dto.ID, &dto.UserID, &dto.Title, &rawData, &dto.CreatedAt, &dto.UpdatedAt)
+	dto, err := scanSecret(conn(ctx, r.db).QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
-		r.logger.Log.Warn("Секрет не найден по ID", zap.Uint64("secret_id", id))
+		r.logger.For(ctx).Warn("Секрет не найден по ID", zap.Uint64("secret_id", id))
 		return nil, nil
 	}
 	if err != nil {
-		r.logger.Log.Error("Ошибка при получении секрета по ID", zap.Uint64("secret_id", id), zap.Error(err))
+		r.logger.For(ctx).Error("Ошибка при получении секрета по ID", zap.Uint64("secret_id", id), zap.Error(err))
 		return nil, err
 	}
 
-	if err := json.Unmarshal(rawData, &dto.Data); err != nil {
-		r.logger.Log.Error("Ошибка при анмаршалинге данных секрета", zap.Uint64("secret_id", id), zap.Error(err))
-		return nil, ErrUnmarshalPayload
-	}
+	r.logger.For(ctx).Info("Секрет успешно получен", zap.Uint64("secret_id", dto.ID))
+	return dto, nil
+}
 
-	r.logger.Log.Info("Секрет успешно получен", zap.Uint64("secret_id", dto.ID))
-	return &dto, nil
+// secretSortColumns — допустимые поля сортировки списка секретов и соответствующие им колонки.
+// Значение из запроса никогда не подставляется в SQL напрямую.
+var secretSortColumns = map[string]string{
+	models.SecretSortCreatedAt: "created_at",
+	models.SecretSortUpdatedAt: "updated_at",
+	models.SecretSortTitle:     "title",
 }
 
-// GetAllByUser возвращает все секреты, принадлежащие пользователю.
-func (r *SecretRepositoryImpl) GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadSecretDTO, error) {
-	query := `
-		select id, user_id, title, data, created_at, updated_at
-		from secrets
-		where user_id = $1
-		order by created_at desc;
-	`
+// GetAllByUser возвращает секреты, принадлежащие пользователю.
+// Условия фильтра добавляются к запросу, только если они заданы. Постраничная выборка выполняется
+// по ключу (поле сортировки, id): следующая страница начинается строго после записи из курсора,
+// поэтому запрос использует индексы (user_id, <поле>, id) и не зависит от глубины пролистывания.
+// Возвращает ErrInvalidCursor, если курсор выдан для другой сортировки или повреждён.
+func (r *SecretRepositoryImpl) GetAllByUser(ctx context.Context, userID uint64, filter models.SecretFilterDTO) ([]models.ReadSecretDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "secrets.get_all_by_user")
+	defer span.End()
+
+	query, args, err := buildSecretsQuery(r.dialect, secretColumns, userID, filter)
+	if err != nil {
+		r.logger.For(ctx).Warn("Невалидный курсор списка секретов", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
-		r.logger.Log.Error("Ошибка при получении всех секретов пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+		r.logger.For(ctx).Error("Ошибка при получении всех секретов пользователя", zap.Uint64("user_id", userID), zap.Error(err))
 		return nil, err
 	}
 	defer rows.Close()
 
 	var secrets []models.ReadSecretDTO
 	for rows.Next() {
-		var dto models.ReadSecretDTO
-		var rawData []byte
-
-		if err := rows.Scan(&dto.ID, &dto.UserID, &dto.Title, &rawData, &dto.CreatedAt, &dto.UpdatedAt); err != nil {
-			r.logger.Log.Error("Ошибка при чтении строки секрета", zap.Error(err))
+		dto, err := scanSecret(rows)
+		if err != nil {
+			r.logger.For(ctx).Error("Ошибка при чтении строки секрета", zap.Error(err))
 			return nil, err
 		}
+		secrets = append(secrets, *dto)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.For(ctx).Error("Ошибка при чтении секретов пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.For(ctx).Info("Секреты пользователя успешно получены", zap.Uint64("user_id", userID), zap.Int("count", len(secrets)))
+	return secrets, nil
+}
+
+// GetSummariesByUser возвращает метаданные секретов пользователя без полезных данных.
+// Отбор, сортировка и постраничная выборка выполняются так же, как в GetAllByUser.
+func (r *SecretRepositoryImpl) GetSummariesByUser(ctx context.Context, userID uint64, filter models.SecretFilterDTO) ([]models.SecretSummaryDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "secrets.get_summaries_by_user")
+	defer span.End()
+
+	query, args, err := buildSecretsQuery(r.dialect, summaryColumns, userID, filter)
+	if err != nil {
+		r.logger.For(ctx).Warn("Невалидный курсор списка секретов", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
 
-		if err := json.Unmarshal(rawData, &dto.Data); err != nil {
-			r.logger.Log.Error("Ошибка при анмаршалинге данных секрета", zap.Uint64("secret_id", dto.ID), zap.Error(err))
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при получении списка секретов пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []models.SecretSummaryDTO
+	for rows.Next() {
+		var dto models.SecretSummaryDTO
+		var typ sql.NullString
+		var rawTags []byte
+		var folderID sql.NullInt64
+
+		if err := rows.Scan(&dto.ID, &dto.Title, &typ, &folderID, &rawTags, &dto.Version, &dto.CreatedAt, &dto.UpdatedAt); err != nil {
+			r.logger.For(ctx).Error("Ошибка при чтении строки секрета", zap.Error(err))
+			return nil, err
+		}
+		if dto.Tags, err = unmarshalTags(rawTags); err != nil {
+			r.logger.For(ctx).Error("Ошибка при анмаршалинге тегов секрета", zap.Uint64("secret_id", dto.ID), zap.Error(err))
 			return nil, ErrUnmarshalPayload
 		}
+		dto.Type = typ.String
+		dto.FolderID = idFromNull(folderID)
 
-		secrets = append(secrets, dto)
+		summaries = append(summaries, dto)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.For(ctx).Error("Ошибка при чтении списка секретов пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
 	}
 
-	r.logger.Log.Info("Секреты пользователя успешно получены", zap.Uint64("user_id", userID), zap.Int("count", len(secrets)))
-	return secrets, nil
+	r.logger.For(ctx).Info("Список секретов пользователя успешно получен", zap.Uint64("user_id", userID), zap.Int("count", len(summaries)))
+	return summaries, nil
+}
+
+// scanSecret читает секрет из строки результата запроса, выбранной по колонкам secretColumns.
+// Возвращает ErrUnmarshalPayload, если данные или теги секрета повреждены.
+func scanSecret(row interface{ Scan(dest ...any) error }) (*models.ReadSecretDTO, error) {
+	var dto models.ReadSecretDTO
+	var rawData, rawTags []byte
+	var folderID sql.NullInt64
+	err := row.Scan(&dto.ID, &dto.UserID, &dto.Title, &rawData, &folderID, &rawTags, &dto.Version, &dto.CreatedAt, &dto.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(rawData, &dto.Data); err != nil {
+		return nil, fmt.Errorf("secret %d: %w", dto.ID, ErrUnmarshalPayload)
+	}
+	if dto.Tags, err = unmarshalTags(rawTags); err != nil {
+		return nil, fmt.Errorf("secret %d: %w", dto.ID, ErrUnmarshalPayload)
+	}
+	dto.FolderID = idFromNull(folderID)
+	return &dto, nil
 }
 
-// DeleteByID удаляет секрет по его ID.
-func (r *SecretRepositoryImpl) DeleteByID(ctx context.Context, id uint64) error {
+// Наборы колонок для выборки секретов: полные данные и метаданные без полезных данных.
+const (
+	secretColumns  = "id, user_id, title, data, folder_id, tags, version, created_at, updated_at"
+	summaryColumns = "id, title, type, folder_id, tags, version, created_at, updated_at"
+)
+
+// buildSecretsQuery собирает запрос списка секретов пользователя по фильтру.
+// Параметры добавляются по порядку, поэтому номера плейсхолдеров зависят от набора заданных условий.
+// Условия по тегу и названию записываются в диалекте d.
+func buildSecretsQuery(d dialect, columns string, userID uint64, filter models.SecretFilterDTO) (string, []any, error) {
 	query := `
-		delete from secrets
-		where id = $1;
-	`
+		select ` + columns + `
+		from secrets
+		where user_id = $1 and deleted_at is null`
+	args := []any{userID}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
 
-	_, err := r.db.ExecContext(ctx, query, id)
-	if err != nil {
-		r.logger.Log.Error("Ошибка при удалении секрета", zap.Uint64("secret_id", id), zap.Error(err))
-		return err
+	if filter.FolderID != nil {
+		if *filter.FolderID == 0 {
+			query += ` and folder_id is null`
+		} else {
+			query += " and folder_id = " + arg(*filter.FolderID)
+		}
+	}
+	if filter.Tag != "" {
+		query += " and " + d.hasTag(arg(filter.Tag))
+	}
+	if filter.Query != "" {
+		query += " and " + d.titleLike(arg("%"+escapeLike(filter.Query)+"%"))
+	}
+	if filter.Type != "" {
+		query += " and type = " + arg(filter.Type)
+	}
+	if filter.CreatedFrom != nil {
+		query += " and created_at >= " + arg(d.timeArg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		query += " and created_at < " + arg(d.timeArg(*filter.CreatedTo))
+	}
+	if filter.UpdatedFrom != nil {
+		query += " and updated_at >= " + arg(d.timeArg(*filter.UpdatedFrom))
+	}
+	if filter.UpdatedTo != nil {
+		query += " and updated_at < " + arg(d.timeArg(*filter.UpdatedTo))
 	}
 
-	r.logger.Log.Info("Секрет успешно удалён", zap.Uint64("secret_id", id))
-	return nil
+	sort := filter.Sort
+	if sort == "" {
+		sort = "-" + models.SecretSortCreatedAt
+	}
+	desc := strings.HasPrefix(sort, "-")
+	column, ok := secretSortColumns[strings.TrimPrefix(sort, "-")]
+	if !ok {
+		return "", nil, ErrInvalidCursor
+	}
+	direction, cmp := "asc", ">"
+	if desc {
+		direction, cmp = "desc", "<"
+	}
+
+	if filter.After != nil {
+		if filter.After.Sort != sort {
+			return "", nil, ErrInvalidCursor
+		}
+		var value any = filter.After.Value
+		if column != "title" {
+			t, err := time.Parse(models.SecretCursorTimeLayout, filter.After.Value)
+			if err != nil {
+				return "", nil, ErrInvalidCursor
+			}
+			value = d.timeArg(t)
+		}
+		query += fmt.Sprintf(" and (%s, id) %s (%s, %s)", column, cmp, arg(value), arg(filter.After.ID))
+	}
+
+	query += fmt.Sprintf("\n\t\torder by %s %s, id %s", column, direction, direction)
+	if filter.Limit > 0 {
+		query += "\n\t\tlimit " + arg(filter.Limit)
+	}
+	query += ";\n\t"
+	return query, args, nil
+}
+
+// DeleteByID перемещает секрет пользователя в корзину, проставляя deleted_at.
+// Возвращает ErrNotFound, если секрет не найден, принадлежит другому пользователю или уже в корзине.
+func (r *SecretRepositoryImpl) DeleteByID(ctx context.Context, userID, id uint64) error {
+	ctx, span := tracing.StartDB(ctx, "secrets.delete_by_id")
+	defer span.End()
+
+	return r.execOne(ctx, conn(ctx, r.db), moveToTrashQuery, id, userID)
 }
+
+// moveToTrashQuery перемещает секрет пользователя в корзину; параметры — ID секрета и ID пользователя.
+const moveToTrashQuery = `
+		update secrets
+		set deleted_at = now()
+		where id = $1 and user_id = $2 and deleted_at is null;
+	`
@@ -32,16 +32,17 @@ func TestSecretRepositoryImpl_Create(t *testing.T) {
 				Password: "1234",
 			},
 		},
+		Device: "laptop",
 	}
 
 	dataBytes, _ := json.Marshal(dto.Data)
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		insert into secrets (user_id, title, data)
-		values ($1, $2, $3)
+		insert into secrets (user_id, title, data, folder_id, tags, device)
+		values ($1, $2, $3, $4, $5, $6)
 		returning id
 	`)).
-		WithArgs(dto.UserID, dto.Title, dataBytes).
+		WithArgs(dto.UserID, dto.Title, dataBytes, nil, []byte("[]"), "laptop").
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uint64(1)))
 
 	id, err := repo.Create(context.Background(), dto)
@@ -64,7 +65,7 @@ func TestSecretRepositoryImpl_Create_QueryError(t *testing.T) {
 	dataBytes, _ := json.Marshal(dto.Data)
 
 	mock.ExpectQuery("insert into secrets").
-		WithArgs(dto.UserID, dto.Title, dataBytes).
+		WithArgs(dto.UserID, dto.Title, dataBytes, nil, []byte("[]"), "").
 		WillReturnError(assert.AnError)
 
 	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
@@ -89,15 +90,15 @@ func TestSecretRepositoryImpl_GetByID(t *testing.T) {
 	dataBytes, _ := json.Marshal(rawData)
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		select id, user_id, title, data, created_at, updated_at
+		select id, user_id, title, data, folder_id, tags, version, created_at, updated_at
 		from secrets
-		where id = $1
+		where id = $1 and deleted_at is null
 	`)).
 		WithArgs(uint64(1)).
 		WillReturnRows(sqlmock.NewRows([]string{
-			"id", "user_id", "title", "data", "created_at", "updated_at",
+			"id", "user_id", "title", "data", "folder_id", "tags", "version", "created_at", "updated_at",
 		}).AddRow(
-			uint64(1), uint64(42), "Note", dataBytes, now, now,
+			uint64(1), uint64(42), "Note", dataBytes, nil, []byte(`["personal"]`), 2, now, now,
 		))
 
 	secret, err := repo.GetByID(context.Background(), 1)
@@ -107,6 +108,9 @@ func TestSecretRepositoryImpl_GetByID(t *testing.T) {
 	assert.Equal(t, "Note", secret.Title)
 	assert.NotNil(t, secret.Data.Text)
 	assert.Equal(t, "some secret text", *secret.Data.Text)
+	assert.Nil(t, secret.FolderID)
+	assert.Equal(t, []string{"personal"}, secret.Tags)
+	assert.Equal(t, 2, secret.Version)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -154,8 +158,8 @@ func TestSecretRepositoryImpl_GetByID_UnmarshalError(t *testing.T) {
 	mock.ExpectQuery("select id, user_id, title").
 		WithArgs(uint64(1)).
 		WillReturnRows(sqlmock.NewRows([]string{
-			"id", "user_id", "title", "data", "created_at", "updated_at",
-		}).AddRow(1, 42, "Broken", []byte("not-json"), now, now))
+			"id", "user_id", "title", "data", "folder_id", "tags", "version", "created_at", "updated_at",
+		}).AddRow(1, 42, "Broken", []byte("not-json"), nil, []byte("[]"), 2, now, now))
 
 	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
 	secret, err := repo.GetByID(context.Background(), 1)
@@ -184,28 +188,196 @@ func TestSecretRepositoryImpl_GetAllByUser(t *testing.T) {
 	dataBytes, _ := json.Marshal(data)
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		select id, user_id, title, data, created_at, updated_at
+		select id, user_id, title, data, folder_id, tags, version, created_at, updated_at
 		from secrets
-		where user_id = $1
-		order by created_at desc
+		where user_id = $1 and deleted_at is null
+		order by created_at desc, id desc;
 	`)).
 		WithArgs(uint64(42)).
 		WillReturnRows(sqlmock.NewRows([]string{
-			"id", "user_id", "title", "data", "created_at", "updated_at",
+			"id", "user_id", "title", "data", "folder_id", "tags", "version", "created_at", "updated_at",
 		}).
-			AddRow(uint64(1), uint64(42), "Card 1", dataBytes, now, now).
-			AddRow(uint64(2), uint64(42), "Card 2", dataBytes, now, now),
+			AddRow(uint64(1), uint64(42), "Card 1", dataBytes, int64(7), []byte(`["bank"]`), 2, now, now).
+			AddRow(uint64(2), uint64(42), "Card 2", dataBytes, nil, []byte("[]"), 2, now, now),
 		)
 
-	secrets, err := repo.GetAllByUser(context.Background(), 42)
+	secrets, err := repo.GetAllByUser(context.Background(), 42, models.SecretFilterDTO{})
 	assert.NoError(t, err)
 	assert.Len(t, secrets, 2)
 	assert.Equal(t, "Card 1", secrets[0].Title)
 	assert.NotNil(t, secrets[0].Data.Card)
 	assert.Equal(t, "John Doe", secrets[0].Data.Card.Holder)
+	assert.Equal(t, uint64(7), *secrets[0].FolderID)
+	assert.Equal(t, []string{"bank"}, secrets[0].Tags)
+	assert.Nil(t, secrets[1].FolderID)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestSecretRepositoryImpl_GetAllByUser_Filter(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	columns := []string{"id", "user_id", "title", "data", "folder_id", "tags", "version", "created_at", "updated_at"}
+	folderID := uint64(7)
+	rootID := uint64(0)
+
+	t.Run("Folder_and_tag", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`where user_id = $1 and deleted_at is null and folder_id = $2 and tags ? $3`)).
+			WithArgs(uint64(42), folderID, "work").
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		secrets, err := repo.GetAllByUser(context.Background(), 42, models.SecretFilterDTO{FolderID: &folderID, Tag: "work"})
+		assert.NoError(t, err)
+		assert.Empty(t, secrets)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Root_folder", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`where user_id = $1 and deleted_at is null and folder_id is null`)).
+			WithArgs(uint64(42)).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := repo.GetAllByUser(context.Background(), 42, models.SecretFilterDTO{FolderID: &rootID})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSecretRepositoryImpl_GetAllByUser_SearchAndPage(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	columns := []string{"id", "user_id", "title", "data", "folder_id", "tags", "version", "created_at", "updated_at"}
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Search_type_and_ranges", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			where user_id = $1 and deleted_at is null and title ilike $2 and type = $3 and created_at >= $4 and created_at < $5 and updated_at >= $6
+			order by title asc, id asc
+			limit $7;
+		`)).
+			WithArgs(uint64(42), `%100\%\_ok%`, models.SecretTypeCard, from, to, from, 11).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := repo.GetAllByUser(context.Background(), 42, models.SecretFilterDTO{
+			Query:       "100%_ok",
+			Type:        models.SecretTypeCard,
+			CreatedFrom: &from,
+			CreatedTo:   &to,
+			UpdatedFrom: &from,
+			Sort:        "title",
+			Limit:       11,
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Time_cursor", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			where user_id = $1 and deleted_at is null and (updated_at, id) < ($2, $3)
+			order by updated_at desc, id desc
+			limit $4;
+		`)).
+			WithArgs(uint64(42), time.Date(2025, 1, 2, 3, 4, 5, 123456000, time.UTC), uint64(9), 51).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := repo.GetAllByUser(context.Background(), 42, models.SecretFilterDTO{
+			Sort:  "-updated_at",
+			Limit: 51,
+			After: &models.SecretCursorDTO{Sort: "-updated_at", Value: "2025-01-02T03:04:05.123456", ID: 9},
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Title_cursor", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`where user_id = $1 and deleted_at is null and (title, id) > ($2, $3)`)).
+			WithArgs(uint64(42), "Bank", uint64(3)).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := repo.GetAllByUser(context.Background(), 42, models.SecretFilterDTO{
+			Sort:  "title",
+			After: &models.SecretCursorDTO{Sort: "title", Value: "Bank", ID: 3},
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Cursor_for_other_sort", func(t *testing.T) {
+		_, err := repo.GetAllByUser(context.Background(), 42, models.SecretFilterDTO{
+			After: &models.SecretCursorDTO{Sort: "title", Value: "Bank", ID: 3},
+		})
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("Broken_cursor_value", func(t *testing.T) {
+		_, err := repo.GetAllByUser(context.Background(), 42, models.SecretFilterDTO{
+			After: &models.SecretCursorDTO{Sort: "-created_at", Value: "yesterday", ID: 3},
+		})
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+}
+
+func TestSecretRepositoryImpl_GetSummariesByUser(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	columns := []string{"id", "title", "type", "folder_id", "tags", "version", "created_at", "updated_at"}
+	now := time.Now()
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			select id, title, type, folder_id, tags, version, created_at, updated_at
+			from secrets
+			where user_id = $1 and deleted_at is null and type = $2
+			order by created_at desc, id desc
+			limit $3;
+		`)).
+			WithArgs(uint64(42), models.SecretTypeLogin, 51).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(uint64(1), "VPN", "login", int64(7), []byte(`["work"]`), 3, now, now).
+				AddRow(uint64(2), "Empty", nil, nil, []byte("[]"), 1, now, now))
+
+		summaries, err := repo.GetSummariesByUser(context.Background(), 42, models.SecretFilterDTO{Type: models.SecretTypeLogin, Limit: 51})
+		assert.NoError(t, err)
+		assert.Len(t, summaries, 2)
+		assert.Equal(t, models.SecretSummaryDTO{
+			ID: 1, Title: "VPN", Type: "login", FolderID: uptr(7), Tags: []string{"work"}, Version: 3, CreatedAt: now, UpdatedAt: now,
+		}, summaries[0])
+		assert.Empty(t, summaries[1].Type)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Query_error", func(t *testing.T) {
+		mock.ExpectQuery("select id, title, type").
+			WithArgs(uint64(42)).
+			WillReturnError(assert.AnError)
+
+		summaries, err := repo.GetSummariesByUser(context.Background(), 42, models.SecretFilterDTO{})
+		assert.Error(t, err)
+		assert.Nil(t, summaries)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid_cursor", func(t *testing.T) {
+		summaries, err := repo.GetSummariesByUser(context.Background(), 42, models.SecretFilterDTO{
+			After: &models.SecretCursorDTO{Sort: "title"},
+		})
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+		assert.Nil(t, summaries)
+	})
+}
+
 func TestSecretRepositoryImpl_GetAllByUser_QueryError(t *testing.T) {
 	cfg := config.GetConfig()
 	db, mock, err := sqlmock.New()
@@ -217,7 +389,7 @@ func TestSecretRepositoryImpl_GetAllByUser_QueryError(t *testing.T) {
 		WillReturnError(assert.AnError)
 
 	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
-	secrets, err := repo.GetAllByUser(context.Background(), 1)
+	secrets, err := repo.GetAllByUser(context.Background(), 1, models.SecretFilterDTO{})
 	assert.Error(t, err)
 	assert.Nil(t, secrets)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -236,7 +408,7 @@ func TestSecretRepositoryImpl_GetAllByUser_ScanError(t *testing.T) {
 		}).AddRow(1, 42))
 
 	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
-	secrets, err := repo.GetAllByUser(context.Background(), 42)
+	secrets, err := repo.GetAllByUser(context.Background(), 42, models.SecretFilterDTO{})
 	assert.Error(t, err)
 	assert.Nil(t, secrets)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -253,11 +425,11 @@ func TestSecretRepositoryImpl_GetAllByUser_UnmarshalError(t *testing.T) {
 	mock.ExpectQuery("select id, user_id, title").
 		WithArgs(uint64(42)).
 		WillReturnRows(sqlmock.NewRows([]string{
-			"id", "user_id", "title", "data", "created_at", "updated_at",
-		}).AddRow(1, 42, "Bad", []byte("broken-json"), now, now))
+			"id", "user_id", "title", "data", "folder_id", "tags", "version", "created_at", "updated_at",
+		}).AddRow(1, 42, "Bad", []byte("broken-json"), nil, []byte("[]"), 2, now, now))
 
 	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
-	secrets, err := repo.GetAllByUser(context.Background(), 42)
+	secrets, err := repo.GetAllByUser(context.Background(), 42, models.SecretFilterDTO{})
 	assert.ErrorIs(t, err, ErrUnmarshalPayload)
 	assert.Nil(t, secrets)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -272,29 +444,46 @@ func TestSecretRepositoryImpl_DeleteByID(t *testing.T) {
 	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
 
 	mock.ExpectExec(regexp.QuoteMeta(`
-		delete from secrets
-		where id = $1
+		update secrets
+		set deleted_at = now()
+		where id = $1 and user_id = $2 and deleted_at is null;
 	`)).
-		WithArgs(uint64(1)).
+		WithArgs(uint64(1), uint64(42)).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = repo.DeleteByID(context.Background(), 1)
+	err = repo.DeleteByID(context.Background(), 42, 1)
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestSecretRepositoryImpl_DeleteByID_NotFound(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("update secrets").
+		WithArgs(uint64(1), uint64(42)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	err = repo.DeleteByID(context.Background(), 42, 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestSecretRepositoryImpl_DeleteByID_Error(t *testing.T) {
 	cfg := config.GetConfig()
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectExec("delete from secrets").
-		WithArgs(uint64(123)).
+	mock.ExpectExec("update secrets").
+		WithArgs(uint64(123), uint64(42)).
 		WillReturnError(assert.AnError)
 
 	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
-	err = repo.DeleteByID(context.Background(), 123)
+	err = repo.DeleteByID(context.Background(), 42, 123)
 	assert.Error(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -302,3 +491,7 @@ func TestSecretRepositoryImpl_DeleteByID_Error(t *testing.T) {
 func ptr(s string) *string {
 	return &s
 }
+
+func uptr(v uint64) *uint64 {
+	return &v
+}
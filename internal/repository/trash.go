@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// GetTrashByUser возвращает метаданные секретов пользователя, находящихся в корзине, начиная с последних удалённых.
+// Срок окончательного удаления рассчитывается по cfg.TrashRetention.
+func (r *SecretRepositoryImpl) GetTrashByUser(ctx context.Context, userID uint64) ([]models.TrashedSecretDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "secrets.get_trash_by_user")
+	defer span.End()
+
+	query := `
+		select id, title, type, folder_id, tags, version, created_at, updated_at, deleted_at
+		from secrets
+		where user_id = $1 and deleted_at is not null
+		order by deleted_at desc, id desc;
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при получении корзины пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trash []models.TrashedSecretDTO
+	for rows.Next() {
+		var dto models.TrashedSecretDTO
+		var typ sql.NullString
+		var rawTags []byte
+		var folderID sql.NullInt64
+
+		if err := rows.Scan(&dto.ID, &dto.Title, &typ, &folderID, &rawTags, &dto.Version, &dto.CreatedAt, &dto.UpdatedAt, &dto.DeletedAt); err != nil {
+			r.logger.For(ctx).Error("Ошибка при чтении строки корзины", zap.Error(err))
+			return nil, err
+		}
+		if dto.Tags, err = unmarshalTags(rawTags); err != nil {
+			r.logger.For(ctx).Error("Ошибка при анмаршалинге тегов секрета", zap.Uint64("secret_id", dto.ID), zap.Error(err))
+			return nil, ErrUnmarshalPayload
+		}
+		dto.Type = typ.String
+		dto.FolderID = idFromNull(folderID)
+		if retention := r.cfg.TrashRetention; retention > 0 {
+			purgeAt := dto.DeletedAt.Add(retention)
+			dto.PurgeAt = &purgeAt
+		}
+
+		trash = append(trash, dto)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.For(ctx).Error("Ошибка при чтении корзины пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.For(ctx).Info("Корзина пользователя успешно получена", zap.Uint64("user_id", userID), zap.Int("count", len(trash)))
+	return trash, nil
+}
+
+// RestoreFromTrash возвращает секрет пользователя из корзины.
+// Возвращает ErrNotFound, если секрета нет в корзине пользователя.
+func (r *SecretRepositoryImpl) RestoreFromTrash(ctx context.Context, userID, id uint64) error {
+	ctx, span := tracing.StartDB(ctx, "secrets.restore_from_trash")
+	defer span.End()
+
+	query := `
+		update secrets
+		set deleted_at = null
+		where id = $1 and user_id = $2 and deleted_at is not null;
+	`
+	return r.execOne(ctx, conn(ctx, r.db), query, id, userID)
+}
+
+// PurgeByID окончательно удаляет секрет пользователя из корзины вместе с историей версий.
+// Возвращает ErrNotFound, если секрета нет в корзине пользователя.
+func (r *SecretRepositoryImpl) PurgeByID(ctx context.Context, userID, id uint64) error {
+	ctx, span := tracing.StartDB(ctx, "secrets.purge_by_id")
+	defer span.End()
+
+	query := `
+		delete from secrets
+		where id = $1 and user_id = $2 and deleted_at is not null;
+	`
+	return r.execOne(ctx, conn(ctx, r.db), query, id, userID)
+}
+
+// PurgeDeletedBefore окончательно удаляет все секреты, перемещённые в корзину раньше указанного момента.
+// Возвращает количество удалённых секретов.
+func (r *SecretRepositoryImpl) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := tracing.StartDB(ctx, "secrets.purge_deleted_before")
+	defer span.End()
+
+	query := `
+		delete from secrets
+		where deleted_at is not null and deleted_at < $1;
+	`
+
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, r.dialect.timeArg(before))
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при очистке корзины", zap.Time("before", before), zap.Error(err))
+		return 0, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		r.logger.For(ctx).Error("Не удалось получить количество удалённых секретов", zap.Error(err))
+		return 0, err
+	}
+
+	r.logger.For(ctx).Info("Корзина очищена от устаревших секретов", zap.Int64("count", count))
+	return count, nil
+}
+
+// execOne выполняет запрос, изменяющий один секрет пользователя, через q (соединение или транзакцию).
+// Возвращает ErrNotFound, если запрос не затронул ни одной строки.
+func (r *SecretRepositoryImpl) execOne(ctx context.Context, q querier, query string, id, userID uint64) error {
+	res, err := q.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при изменении секрета", zap.Uint64("secret_id", id), zap.Error(err))
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		r.logger.For(ctx).Error("Не удалось получить количество изменённых строк", zap.Uint64("secret_id", id), zap.Error(err))
+		return err
+	}
+	if count == 0 {
+		r.logger.For(ctx).Warn("Секрет не найден", zap.Uint64("secret_id", id), zap.Uint64("user_id", userID))
+		return ErrNotFound
+	}
+
+	r.logger.For(ctx).Info("Секрет успешно изменён", zap.Uint64("secret_id", id))
+	return nil
+}
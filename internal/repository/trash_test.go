@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+)
+
+func TestSecretRepositoryImpl_GetTrashByUser(t *testing.T) {
+	cfg := config.GetConfig()
+	cfg.TrashRetention = 24 * time.Hour
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	now := time.Now()
+	deletedAt := now.Add(-time.Hour)
+
+	rows := sqlmock.NewRows([]string{"id", "title", "type", "folder_id", "tags", "version", "created_at", "updated_at", "deleted_at"}).
+		AddRow(3, "VPN", "login", 5, []byte(`["work"]`), 2, now, now, deletedAt).
+		AddRow(1, "Note", nil, nil, []byte(`[]`), 1, now, now, deletedAt.Add(-time.Hour))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`
+		select id, title, type, folder_id, tags, version, created_at, updated_at, deleted_at
+		from secrets
+		where user_id = $1 and deleted_at is not null
+		order by deleted_at desc, id desc;
+	`)).
+		WithArgs(uint64(42)).
+		WillReturnRows(rows)
+
+	trash, err := repo.GetTrashByUser(context.Background(), 42)
+	assert.NoError(t, err)
+	assert.Len(t, trash, 2)
+	assert.Equal(t, "login", trash[0].Type)
+	assert.Equal(t, uint64(5), *trash[0].FolderID)
+	assert.Equal(t, []string{"work"}, trash[0].Tags)
+	assert.Equal(t, deletedAt.Add(24*time.Hour), *trash[0].PurgeAt)
+	assert.Nil(t, trash[1].FolderID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	t.Run("No_retention", func(t *testing.T) {
+		cfg.TrashRetention = 0
+		mock.ExpectQuery("from secrets").
+			WithArgs(uint64(42)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "type", "folder_id", "tags", "version", "created_at", "updated_at", "deleted_at"}).
+				AddRow(3, "VPN", "login", nil, []byte(`[]`), 1, now, now, deletedAt))
+
+		trash, err := repo.GetTrashByUser(context.Background(), 42)
+		assert.NoError(t, err)
+		assert.Nil(t, trash[0].PurgeAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mock.ExpectQuery("from secrets").
+			WithArgs(uint64(42)).
+			WillReturnError(assert.AnError)
+
+		trash, err := repo.GetTrashByUser(context.Background(), 42)
+		assert.Error(t, err)
+		assert.Nil(t, trash)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSecretRepositoryImpl_RestoreFromTrash(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+
+	mock.ExpectExec(regexp.QuoteMeta(`
+		update secrets
+		set deleted_at = null
+		where id = $1 and user_id = $2 and deleted_at is not null;
+	`)).
+		WithArgs(uint64(7), uint64(42)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.RestoreFromTrash(context.Background(), 42, 7))
+
+	mock.ExpectExec("update secrets").
+		WithArgs(uint64(8), uint64(42)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.RestoreFromTrash(context.Background(), 42, 8), ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSecretRepositoryImpl_PurgeByID(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+
+	mock.ExpectExec(regexp.QuoteMeta(`
+		delete from secrets
+		where id = $1 and user_id = $2 and deleted_at is not null;
+	`)).
+		WithArgs(uint64(7), uint64(42)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.PurgeByID(context.Background(), 42, 7))
+
+	mock.ExpectExec("delete from secrets").
+		WithArgs(uint64(8), uint64(42)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.PurgeByID(context.Background(), 42, 8), ErrNotFound)
+
+	mock.ExpectExec("delete from secrets").
+		WithArgs(uint64(9), uint64(42)).
+		WillReturnError(assert.AnError)
+	assert.ErrorIs(t, repo.PurgeByID(context.Background(), 42, 9), assert.AnError)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSecretRepositoryImpl_PurgeDeletedBefore(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &SecretRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	before := time.Now()
+
+	mock.ExpectExec(regexp.QuoteMeta(`
+		delete from secrets
+		where deleted_at is not null and deleted_at < $1;
+	`)).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	count, err := repo.PurgeDeletedBefore(context.Background(), before)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	mock.ExpectExec("delete from secrets").
+		WithArgs(before).
+		WillReturnError(assert.AnError)
+
+	count, err = repo.PurgeDeletedBefore(context.Background(), before)
+	assert.Error(t, err)
+	assert.Zero(t, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
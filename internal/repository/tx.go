@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// Коды ошибок PostgreSQL, после которых транзакцию можно выполнить повторно.
+const (
+	serializationFailure = "40001" // Конфликт сериализации
+	deadlockDetected     = "40P01" // Взаимная блокировка
+)
+
+// nestedSavepoint — точка сохранения, в которой метод репозитория выполняет свою транзакцию внутри внешней.
+const nestedSavepoint = "repository_tx"
+
+// txKey — ключ, под которым транзакция TxManager хранится в context.Context.
+type txKey struct{}
+
+// TxManagerImpl — реализация TxManager поверх общего пула соединений.
+type TxManagerImpl struct {
+	db     *sql.DB        // Пул соединений с базой
+	cfg    *config.Config // Конфигурация (число попыток и пауза между ними)
+	logger *logger.Logger // Логгер
+}
+
+// NewTxManagerImpl создаёт менеджер транзакций.
+// Работает через общий пул соединений db (см. database.Open).
+func NewTxManagerImpl(db *sql.DB, cfg *config.Config) *TxManagerImpl {
+	return &TxManagerImpl{
+		db:     db,
+		cfg:    cfg,
+		logger: logger.NewLogger(),
+	}
+}
+
+// WithinTx выполняет fn в транзакции уровня serializable. Транзакция передаётся в fn через контекст,
+// и все репозитории, получившие этот контекст, выполняют запросы в ней.
+// Если fn вернула ошибку, транзакция откатывается и ошибка возвращается как есть. Если транзакция
+// прервана конфликтом сериализации или взаимной блокировкой, fn выполняется заново — всего не больше
+// cfg.DatabaseTxMaxAttempts раз, поэтому fn не должна иметь побочных эффектов вне базы.
+// Вызов внутри другой WithinTx просто выполняет fn во внешней транзакции.
+func (m *TxManagerImpl) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := txFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	ctx, span := tracing.StartDB(ctx, "tx.within")
+	defer span.End()
+
+	attempts := max(m.cfg.DatabaseTxMaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		err := m.run(ctx, fn)
+		if attempt >= attempts || !isRetryable(err) {
+			return err
+		}
+		m.logger.For(ctx).Warn("Транзакция прервана, повторяем", zap.Int("attempt", attempt), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * m.cfg.DatabaseTxRetryDelay):
+		}
+	}
+}
+
+// run выполняет одну попытку транзакции.
+func (m *TxManagerImpl) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		m.logger.For(ctx).Error("Не удалось начать транзакцию", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		m.logger.For(ctx).Error("Не удалось зафиксировать транзакцию", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// isRetryable сообщает, прервана ли транзакция ошибкой, после которой её можно повторить.
+// В SQLite это ошибка занятой базы: блокировку не удалось получить за busy_timeout.
+func isRetryable(err error) bool {
+	state := sqlState(err)
+	return state == serializationFailure || state == deadlockDetected || sqliteCode(err)&0xff == sqliteBusy
+}
+
+// txFromContext возвращает транзакцию TxManager из ctx.
+func txFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return tx, ok
+}
+
+// conn возвращает транзакцию из ctx, если запрос выполняется внутри TxManager.WithinTx, иначе — пул db.
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx
+	}
+	return db
+}
+
+// repoTx — транзакция, которую метод репозитория открывает для нескольких своих запросов.
+// Внутри TxManager.WithinTx это точка сохранения во внешней транзакции: откат отменяет только
+// шаги метода, а фиксацию выполняет внешняя транзакция.
+type repoTx struct {
+	*sql.Tx
+	nested bool // Транзакция — точка сохранения во внешней транзакции
+	done   bool // Точка сохранения уже освобождена или отменена
+}
+
+// beginTx начинает транзакцию метода репозитория: новую в пуле db или точку сохранения в транзакции из ctx.
+func beginTx(ctx context.Context, db *sql.DB) (*repoTx, error) {
+	outer, ok := txFromContext(ctx)
+	if !ok {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &repoTx{Tx: tx}, nil
+	}
+	if _, err := outer.ExecContext(ctx, "savepoint "+nestedSavepoint); err != nil {
+		return nil, err
+	}
+	return &repoTx{Tx: outer, nested: true}, nil
+}
+
+// Commit фиксирует транзакцию или освобождает точку сохранения.
+func (t *repoTx) Commit() error {
+	if !t.nested {
+		return t.Tx.Commit()
+	}
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	_, err := t.Tx.Exec("release savepoint " + nestedSavepoint)
+	return err
+}
+
+// Rollback откатывает транзакцию или изменения после точки сохранения.
+// Как и у *sql.Tx, вызов после Commit ничего не делает и возвращает sql.ErrTxDone.
+func (t *repoTx) Rollback() error {
+	if !t.nested {
+		return t.Tx.Rollback()
+	}
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	_, err := t.Tx.Exec("rollback to savepoint " + nestedSavepoint)
+	return err
+}
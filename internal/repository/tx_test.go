@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTxManager(t *testing.T) (*TxManagerImpl, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	cfg := config.Config{DatabaseTxMaxAttempts: 3}
+	return NewTxManagerImpl(db, &cfg), mock
+}
+
+func TestTxManagerImpl_WithinTx(t *testing.T) {
+	m, mock := newTestTxManager(t)
+	ctx := context.Background()
+
+	t.Run("Commit", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("insert into folders")).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		err := m.WithinTx(ctx, func(ctx context.Context) error {
+			_, err := conn(ctx, m.db).ExecContext(ctx, "insert into folders default values;")
+			return err
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rollback on error", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		err := m.WithinTx(ctx, func(ctx context.Context) error { return assert.AnError })
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Retry on serialization failure", func(t *testing.T) {
+		conflict := pgx.PgError{Code: serializationFailure}
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		mock.ExpectCommit().WillReturnError(pgx.PgError{Code: deadlockDetected})
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		calls := 0
+		err := m.WithinTx(ctx, func(ctx context.Context) error {
+			calls++
+			if calls == 1 {
+				return conflict
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Attempts exhausted", func(t *testing.T) {
+		for range 3 {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+		}
+
+		calls := 0
+		err := m.WithinTx(ctx, func(ctx context.Context) error {
+			calls++
+			return pgx.PgError{Code: serializationFailure}
+		})
+		assert.Equal(t, serializationFailure, sqlState(err))
+		assert.Equal(t, 3, calls)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Nested call joins outer transaction", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		err := m.WithinTx(ctx, func(ctx context.Context) error {
+			outer, _ := txFromContext(ctx)
+			return m.WithinTx(ctx, func(ctx context.Context) error {
+				inner, _ := txFromContext(ctx)
+				assert.Same(t, outer, inner)
+				return nil
+			})
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTxManagerImpl_WithinTx_RepositoryUpdate(t *testing.T) {
+	m, mock := newTestTxManager(t)
+	cfg := config.Config{}
+	r := NewSecretRepositoryImpl(m.db, &cfg)
+	dto := models.UpdateSecretDTO{ID: 1, UserID: 2, Title: "title", Data: models.SecretDataDTO{}}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("savepoint " + nestedSavepoint)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("select version")).WithArgs(dto.ID, dto.UserID).WillReturnError(assert.AnError)
+	mock.ExpectExec(regexp.QuoteMeta("rollback to savepoint " + nestedSavepoint)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := m.WithinTx(context.Background(), func(ctx context.Context) error {
+		_, err := r.Update(ctx, dto)
+		return err
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -4,12 +4,12 @@ import (
 	"context"
 	"database/sql"
 
-	_ "github.com/jackc/pgx/stdlib"
 	"go.uber.org/zap"
 
 	"github.com/shekshuev/gophkeeper/internal/config"
 	"github.com/shekshuev/gophkeeper/internal/logger"
 	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
 )
 
 // UserRepositoryImpl — реализация интерфейса UserRepository для работы с пользователями через SQL.
@@ -20,20 +20,12 @@ type UserRepositoryImpl struct {
 }
 
 // NewUserRepositoryImpl создаёт новый экземпляр UserRepositoryImpl.
-// Устанавливает соединение с базой данных на основе переданного DSN из конфигурации.
-func NewUserRepositoryImpl(cfg *config.Config) *UserRepositoryImpl {
-	log := logger.NewLogger()
-
-	db, err := sql.Open("pgx", cfg.DatabaseDSN)
-	if err != nil {
-		log.Log.Fatal("Не удалось подключиться к базе данных", zap.Error(err))
-	}
-	log.Log.Info("Установлено соединение с базой данных")
-
+// Работает через общий пул соединений db (см. database.Open).
+func NewUserRepositoryImpl(db *sql.DB, cfg *config.Config) *UserRepositoryImpl {
 	return &UserRepositoryImpl{
 		db:     db,
 		cfg:    cfg,
-		logger: log,
+		logger: logger.NewLogger(),
 	}
 }
 
@@ -41,26 +33,36 @@ func NewUserRepositoryImpl(cfg *config.Config) *UserRepositoryImpl {
 // Принимает DTO с необходимыми полями, возвращает DTO с ID, userName и хешем пароля.
 // В случае ошибки возвращает её.
 func (r *UserRepositoryImpl) CreateUser(ctx context.Context, dto models.CreateUserDTO) (*models.ReadAuthUserDataDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "users.create_user")
+	defer span.End()
+
 	query := `
 		insert into users (user_name, first_name, last_name, password_hash) values ($1, $2, $3, $4)
 		returning id, user_name, password_hash;
 	`
 
 	var user models.ReadAuthUserDataDTO
-	err := r.db.QueryRowContext(ctx, query, dto.UserName, dto.FirstName, dto.LastName, dto.PasswordHash).
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, dto.UserName, dto.FirstName, dto.LastName, dto.PasswordHash).
 		Scan(&user.ID, &user.UserName, &user.PasswordHash)
+	if isUniqueViolation(err) {
+		r.logger.For(ctx).Warn("Пользователь с таким именем уже существует", zap.String("user_name", dto.UserName))
+		return nil, ErrUserExists
+	}
 	if err != nil {
-		r.logger.Log.Error("Ошибка при создании пользователя", zap.String("user_name", dto.UserName), zap.Error(err))
+		r.logger.For(ctx).Error("Ошибка при создании пользователя", zap.String("user_name", dto.UserName), zap.Error(err))
 		return nil, err
 	}
 
-	r.logger.Log.Info("Пользователь успешно создан", zap.Uint64("user_id", user.ID), zap.String("user_name", user.UserName))
+	r.logger.For(ctx).Info("Пользователь успешно создан", zap.Uint64("user_id", user.ID), zap.String("user_name", user.UserName))
 	return &user, nil
 }
 
 // GetUserByUserName получает пользователя по его userName, если он не помечен как удалённый.
 // Возвращает ReadAuthUserDataDTO или ErrNotFound, если пользователь не найден.
 func (r *UserRepositoryImpl) GetUserByUserName(ctx context.Context, userName string) (*models.ReadAuthUserDataDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "users.get_user_by_user_name")
+	defer span.End()
+
 	query := `
 		select id, user_name, password_hash 
 		from users 
@@ -68,24 +70,27 @@ func (r *UserRepositoryImpl) GetUserByUserName(ctx context.Context, userName str
 	`
 
 	var user models.ReadAuthUserDataDTO
-	err := r.db.QueryRowContext(ctx, query, userName).
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, userName).
 		Scan(&user.ID, &user.UserName, &user.PasswordHash)
 	if err == sql.ErrNoRows {
-		r.logger.Log.Warn("Пользователь не найден", zap.String("user_name", userName))
+		r.logger.For(ctx).Warn("Пользователь не найден", zap.String("user_name", userName))
 		return nil, ErrNotFound
 	}
 	if err != nil {
-		r.logger.Log.Error("Ошибка при получении пользователя по user_name", zap.String("user_name", userName), zap.Error(err))
+		r.logger.For(ctx).Error("Ошибка при получении пользователя по user_name", zap.String("user_name", userName), zap.Error(err))
 		return nil, err
 	}
 
-	r.logger.Log.Info("Пользователь найден", zap.Uint64("user_id", user.ID), zap.String("user_name", user.UserName))
+	r.logger.For(ctx).Info("Пользователь найден", zap.Uint64("user_id", user.ID), zap.String("user_name", user.UserName))
 	return &user, nil
 }
 
 // GetUserByID получает пользователя по его уникальному идентификатору, если он не помечен как удалённый.
 // Возвращает ReadUserDTO или ErrNotFound, если пользователь не найден.
 func (r *UserRepositoryImpl) GetUserByID(ctx context.Context, id uint64) (*models.ReadUserDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "users.get_user_by_id")
+	defer span.End()
+
 	query := `
 		select id, user_name, first_name, last_name, created_at, updated_at 
 		from users 
@@ -93,17 +98,17 @@ func (r *UserRepositoryImpl) GetUserByID(ctx context.Context, id uint64) (*model
 	`
 
 	var user models.ReadUserDTO
-	err := r.db.QueryRowContext(ctx, query, id).
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).
 		Scan(&user.ID, &user.UserName, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt)
 	if err == sql.ErrNoRows {
-		r.logger.Log.Warn("Пользователь по ID не найден", zap.Uint64("user_id", id))
+		r.logger.For(ctx).Warn("Пользователь по ID не найден", zap.Uint64("user_id", id))
 		return nil, ErrNotFound
 	}
 	if err != nil {
-		r.logger.Log.Error("Ошибка при получении пользователя по ID", zap.Uint64("user_id", id), zap.Error(err))
+		r.logger.For(ctx).Error("Ошибка при получении пользователя по ID", zap.Uint64("user_id", id), zap.Error(err))
 		return nil, err
 	}
 
-	r.logger.Log.Info("Пользователь успешно получен", zap.Uint64("user_id", user.ID))
+	r.logger.For(ctx).Info("Пользователь успешно получен", zap.Uint64("user_id", user.ID))
 	return &user, nil
 }
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx"
 	"github.com/shekshuev/gophkeeper/internal/config"
 	"github.com/shekshuev/gophkeeper/internal/logger"
 	"github.com/shekshuev/gophkeeper/internal/models"
@@ -102,6 +103,30 @@ func TestUserRepositoryImpl_CreateUser(t *testing.T) {
 	}
 }
 
+func TestUserRepositoryImpl_CreateUser_Exists(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+	r := &UserRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`insert into users`)).
+		WithArgs("john", "John", "Doe", "password").
+		WillReturnError(pgx.PgError{Code: "23505"})
+
+	user, err := r.CreateUser(context.Background(), models.CreateUserDTO{
+		UserName:     "john",
+		FirstName:    "John",
+		LastName:     "Doe",
+		PasswordHash: "password",
+	})
+	assert.ErrorIs(t, err, ErrUserExists)
+	assert.Nil(t, user)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUserRepositoryImpl_GetUserByUserName(t *testing.T) {
 	testCases := []struct {
 		name     string
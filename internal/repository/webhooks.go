@@ -0,0 +1,328 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// WebhookRepositoryImpl — реализация интерфейса WebhookRepository для хранения вебхуков и очереди их доставок
+// в PostgreSQL или SQLite.
+type WebhookRepositoryImpl struct {
+	db      *sql.DB        // соединение с базой данных
+	cfg     *config.Config // конфигурация приложения
+	dialect dialect        // диалект SQL базы
+	logger  *logger.Logger // логгер
+}
+
+// NewWebhookRepositoryImpl создаёт новый экземпляр WebhookRepositoryImpl.
+// Работает через общий пул соединений db (см. database.Open).
+func NewWebhookRepositoryImpl(db *sql.DB, cfg *config.Config) *WebhookRepositoryImpl {
+	return &WebhookRepositoryImpl{
+		db:      db,
+		cfg:     cfg,
+		dialect: dialectOf(cfg),
+		logger:  logger.NewLogger(),
+	}
+}
+
+// Create сохраняет новый вебхук пользователя с ключом подписи secret.
+func (r *WebhookRepositoryImpl) Create(ctx context.Context, dto models.CreateWebhookDTO, secret string) (*models.ReadWebhookDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "webhooks.create")
+	defer span.End()
+
+	events, err := marshalTags(dto.Events)
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка сериализации событий вебхука", zap.Error(err))
+		return nil, ErrMarshalPayload
+	}
+	query := `
+		insert into webhooks (user_id, url, secret, events)
+		values ($1, $2, $3, $4)
+		returning id, created_at;
+	`
+	webhook := models.ReadWebhookDTO{UserID: dto.UserID, URL: dto.URL, Events: dto.Events, Secret: secret}
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, dto.UserID, dto.URL, secret, r.dialect.jsonArg(events)).Scan(&webhook.ID, &webhook.CreatedAt); err != nil {
+		r.logger.For(ctx).Error("Ошибка при создании вебхука", zap.Uint64("user_id", dto.UserID), zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.For(ctx).Info("Вебхук успешно создан", zap.Uint64("webhook_id", webhook.ID), zap.Uint64("user_id", dto.UserID))
+	return &webhook, nil
+}
+
+// GetAllByUser возвращает вебхуки пользователя без ключей подписи, начиная с первых зарегистрированных.
+func (r *WebhookRepositoryImpl) GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadWebhookDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "webhooks.get_all_by_user")
+	defer span.End()
+
+	query := `
+		select id, user_id, url, events, created_at
+		from webhooks
+		where user_id = $1
+		order by id;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при получении вебхуков пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []models.ReadWebhookDTO
+	for rows.Next() {
+		var webhook models.ReadWebhookDTO
+		var events []byte
+		if err := rows.Scan(&webhook.ID, &webhook.UserID, &webhook.URL, &events, &webhook.CreatedAt); err != nil {
+			r.logger.For(ctx).Error("Ошибка при чтении строки вебхука", zap.Error(err))
+			return nil, err
+		}
+		if webhook.Events, err = unmarshalTags(events); err != nil {
+			r.logger.For(ctx).Error("Ошибка десериализации событий вебхука", zap.Uint64("webhook_id", webhook.ID), zap.Error(err))
+			return nil, ErrUnmarshalPayload
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.For(ctx).Error("Ошибка при чтении вебхуков пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.For(ctx).Info("Вебхуки пользователя успешно получены", zap.Uint64("user_id", userID), zap.Int("count", len(webhooks)))
+	return webhooks, nil
+}
+
+// DeleteByID удаляет вебхук пользователя вместе с журналом его доставок.
+// Если вебхук не найден или принадлежит другому пользователю — возвращает ErrNotFound.
+func (r *WebhookRepositoryImpl) DeleteByID(ctx context.Context, userID, id uint64) error {
+	ctx, span := tracing.StartDB(ctx, "webhooks.delete_by_id")
+	defer span.End()
+
+	query := `
+		delete from webhooks
+		where id = $1 and user_id = $2;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, id, userID)
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при удалении вебхука", zap.Uint64("webhook_id", id), zap.Error(err))
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		r.logger.For(ctx).Error("Не удалось получить количество удалённых вебхуков", zap.Error(err))
+		return err
+	}
+	if count == 0 {
+		r.logger.For(ctx).Warn("Вебхук для удаления не найден", zap.Uint64("webhook_id", id), zap.Uint64("user_id", userID))
+		return ErrNotFound
+	}
+
+	r.logger.For(ctx).Info("Вебхук удалён", zap.Uint64("webhook_id", id))
+	return nil
+}
+
+// Enqueue ставит в очередь доставку события всем вебхукам пользователя, подписанным на него.
+// Возвращает количество созданных доставок.
+func (r *WebhookRepositoryImpl) Enqueue(ctx context.Context, userID uint64, event string, payload []byte) (int64, error) {
+	ctx, span := tracing.StartDB(ctx, "webhooks.enqueue")
+	defer span.End()
+
+	query := `
+		insert into webhook_deliveries (webhook_id, event, payload)
+		select id, $2, $3
+		from webhooks
+		where user_id = $1 and events @> jsonb_build_array($2::text);
+	`
+	if r.dialect == dialectSQLite {
+		query = `
+		insert into webhook_deliveries (webhook_id, event, payload)
+		select id, $2, $3
+		from webhooks
+		where user_id = $1 and exists (select 1 from json_each(events) where value = $2);
+	`
+	}
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, userID, event, r.dialect.jsonArg(payload))
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при постановке события в очередь вебхуков", zap.Uint64("user_id", userID), zap.String("event", event), zap.Error(err))
+		return 0, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		r.logger.For(ctx).Error("Не удалось получить количество доставок вебхуков", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// ClaimDue берёт в работу до limit доставок, время попытки которых наступило, начиная с самых давних.
+// Следующая попытка взятых доставок откладывается на lease: если отправитель не сохранит результат
+// (например, экземпляр сервера остановится), доставку возьмёт другой отправитель.
+// Строки блокируются с SKIP LOCKED, поэтому несколько экземпляров сервера не берут одну доставку.
+// С SQLite работает один экземпляр сервера, и доставки берутся обычным обновлением.
+func (r *WebhookRepositoryImpl) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookTaskDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "webhooks.claim_due")
+	defer span.End()
+
+	if r.dialect == dialectSQLite {
+		return r.claimDueSQLite(ctx, limit, lease)
+	}
+	query := `
+		update webhook_deliveries d
+		set next_attempt_at = now() + $2 * interval '1 millisecond'
+		from webhooks w
+		where w.id = d.webhook_id and d.id in (
+			select id
+			from webhook_deliveries
+			where status = 'pending' and next_attempt_at <= now()
+			order by next_attempt_at
+			limit $1
+			for update skip locked
+		)
+		returning d.id, w.url, w.secret, d.event, d.payload, d.attempts;
+	`
+	return r.scanTasks(ctx, query, limit, lease.Milliseconds())
+}
+
+// claimDueSQLite — ClaimDue для SQLite: срок аренды вычисляется заранее, а адрес и ключ вебхука
+// читаются подзапросами, потому что RETURNING в SQLite не видит таблиц из FROM.
+func (r *WebhookRepositoryImpl) claimDueSQLite(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookTaskDTO, error) {
+	query := `
+		update webhook_deliveries
+		set next_attempt_at = $2
+		where id in (
+			select id
+			from webhook_deliveries
+			where status = 'pending' and next_attempt_at <= now()
+			order by next_attempt_at
+			limit $1
+		)
+		returning id,
+			(select url from webhooks where webhooks.id = webhook_deliveries.webhook_id),
+			(select secret from webhooks where webhooks.id = webhook_deliveries.webhook_id),
+			event, payload, attempts;
+	`
+	return r.scanTasks(ctx, query, limit, r.dialect.timeArg(time.Now().Add(lease)))
+}
+
+// scanTasks выполняет запрос, берущий доставки в работу, и читает их.
+func (r *WebhookRepositoryImpl) scanTasks(ctx context.Context, query string, args ...any) ([]models.WebhookTaskDTO, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при выборке доставок вебхуков", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []models.WebhookTaskDTO
+	for rows.Next() {
+		var task models.WebhookTaskDTO
+		if err := rows.Scan(&task.ID, &task.URL, &task.Secret, &task.Event, &task.Payload, &task.Attempts); err != nil {
+			r.logger.For(ctx).Error("Ошибка при чтении строки доставки вебхука", zap.Error(err))
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.For(ctx).Error("Ошибка при выборке доставок вебхуков", zap.Error(err))
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// SaveAttempt сохраняет результат попытки доставки и увеличивает счётчик попыток.
+func (r *WebhookRepositoryImpl) SaveAttempt(ctx context.Context, id uint64, attempt models.WebhookAttemptDTO) error {
+	ctx, span := tracing.StartDB(ctx, "webhooks.save_attempt")
+	defer span.End()
+
+	query := `
+		update webhook_deliveries
+		set status = $2,
+			attempts = attempts + 1,
+			response_status = $3,
+			error = $4,
+			next_attempt_at = $5,
+			delivered_at = case when $2 = 'delivered' then now() end
+		where id = $1;
+	`
+	var responseStatus any
+	if attempt.ResponseStatus != 0 {
+		responseStatus = attempt.ResponseStatus
+	}
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id, attempt.Status, responseStatus, attempt.Error, r.dialect.timeArg(attempt.NextAttemptAt))
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при сохранении результата доставки вебхука", zap.Uint64("delivery_id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetDeliveries возвращает последние limit доставок вебхука пользователя, начиная с новых.
+// Если вебхук не найден или принадлежит другому пользователю — возвращает ErrNotFound.
+func (r *WebhookRepositoryImpl) GetDeliveries(ctx context.Context, userID, webhookID uint64, limit int) ([]models.WebhookDeliveryDTO, error) {
+	ctx, span := tracing.StartDB(ctx, "webhooks.get_deliveries")
+	defer span.End()
+
+	var id uint64
+	err := conn(ctx, r.db).QueryRowContext(ctx, `select id from webhooks where id = $1 and user_id = $2;`, webhookID, userID).Scan(&id)
+	if err == sql.ErrNoRows {
+		r.logger.For(ctx).Warn("Вебхук не найден", zap.Uint64("webhook_id", webhookID), zap.Uint64("user_id", userID))
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при получении вебхука", zap.Uint64("webhook_id", webhookID), zap.Error(err))
+		return nil, err
+	}
+
+	query := `
+		select id, webhook_id, event, status, attempts, response_status, error, next_attempt_at, created_at, delivered_at
+		from webhook_deliveries
+		where webhook_id = $1
+		order by created_at desc, id desc
+		limit $2;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, webhookID, limit)
+	if err != nil {
+		r.logger.For(ctx).Error("Ошибка при получении журнала доставок вебхука", zap.Uint64("webhook_id", webhookID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDeliveryDTO
+	for rows.Next() {
+		var delivery models.WebhookDeliveryDTO
+		var responseStatus sql.NullInt64
+		var nextAttemptAt time.Time
+		var deliveredAt sql.NullTime
+		err := rows.Scan(&delivery.ID, &delivery.WebhookID, &delivery.Event, &delivery.Status, &delivery.Attempts,
+			&responseStatus, &delivery.Error, &nextAttemptAt, &delivery.CreatedAt, &deliveredAt)
+		if err != nil {
+			r.logger.For(ctx).Error("Ошибка при чтении строки доставки вебхука", zap.Error(err))
+			return nil, err
+		}
+		if responseStatus.Valid {
+			status := int(responseStatus.Int64)
+			delivery.ResponseStatus = &status
+		}
+		if delivery.Status == models.WebhookDeliveryPending {
+			delivery.NextAttemptAt = &nextAttemptAt
+		}
+		if deliveredAt.Valid {
+			delivery.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.For(ctx).Error("Ошибка при чтении журнала доставок вебхука", zap.Uint64("webhook_id", webhookID), zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.For(ctx).Info("Журнал доставок вебхука получен", zap.Uint64("webhook_id", webhookID), zap.Int("count", len(deliveries)))
+	return deliveries, nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookRepositoryImpl_Webhooks(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &WebhookRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("Create", func(t *testing.T) {
+		mock.ExpectQuery("insert into webhooks").
+			WithArgs(uint64(1), "https://example.com/hook", "key", []byte(`["secret.changed"]`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(uint64(3), now))
+
+		webhook, err := repo.Create(ctx, models.CreateWebhookDTO{UserID: 1, URL: "https://example.com/hook", Events: []string{"secret.changed"}}, "key")
+		assert.NoError(t, err)
+		assert.Equal(t, &models.ReadWebhookDTO{ID: 3, UserID: 1, URL: "https://example.com/hook", Events: []string{"secret.changed"}, Secret: "key", CreatedAt: now}, webhook)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("GetAllByUser", func(t *testing.T) {
+		mock.ExpectQuery("select id, user_id, url, events, created_at from webhooks").
+			WithArgs(uint64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "url", "events", "created_at"}).
+				AddRow(uint64(3), uint64(1), "https://example.com/hook", []byte(`["secret.changed","login.new_device"]`), now))
+
+		webhooks, err := repo.GetAllByUser(ctx, 1)
+		assert.NoError(t, err)
+		if assert.Len(t, webhooks, 1) {
+			assert.Equal(t, []string{"secret.changed", "login.new_device"}, webhooks[0].Events)
+			assert.Empty(t, webhooks[0].Secret)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DeleteByID_not_found", func(t *testing.T) {
+		mock.ExpectExec("delete from webhooks").WithArgs(uint64(3), uint64(2)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.DeleteByID(ctx, 2, 3), ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("GetDeliveries", func(t *testing.T) {
+		mock.ExpectQuery("select id from webhooks").WithArgs(uint64(3), uint64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uint64(3)))
+		mock.ExpectQuery("select .+ from webhook_deliveries").WithArgs(uint64(3), 100).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "webhook_id", "event", "status", "attempts", "response_status", "error", "next_attempt_at", "created_at", "delivered_at"}).
+				AddRow(uint64(9), uint64(3), "secret.changed", "pending", 2, int64(502), "unexpected status 502", now, now, nil).
+				AddRow(uint64(8), uint64(3), "secret.changed", "delivered", 1, int64(200), "", now, now, now))
+
+		deliveries, err := repo.GetDeliveries(ctx, 1, 3, 100)
+		assert.NoError(t, err)
+		if assert.Len(t, deliveries, 2) {
+			assert.Equal(t, 502, *deliveries[0].ResponseStatus)
+			assert.Equal(t, now, *deliveries[0].NextAttemptAt)
+			assert.Nil(t, deliveries[0].DeliveredAt)
+			assert.Nil(t, deliveries[1].NextAttemptAt)
+			assert.Equal(t, now, *deliveries[1].DeliveredAt)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("GetDeliveries_not_found", func(t *testing.T) {
+		mock.ExpectQuery("select id from webhooks").WithArgs(uint64(3), uint64(2)).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetDeliveries(ctx, 2, 3, 100)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestWebhookRepositoryImpl_Queue(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &WebhookRepositoryImpl{cfg: &cfg, db: db, logger: logger.NewLogger()}
+	ctx := context.Background()
+
+	t.Run("Enqueue", func(t *testing.T) {
+		mock.ExpectExec("insert into webhook_deliveries .+ where user_id = \\$1 and events @> jsonb_build_array\\(\\$2::text\\)").
+			WithArgs(uint64(1), "secret.changed", []byte(`{}`)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		count, err := repo.Enqueue(ctx, 1, "secret.changed", []byte(`{}`))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ClaimDue", func(t *testing.T) {
+		mock.ExpectQuery("update webhook_deliveries d .+ for update skip locked").
+			WithArgs(50, int64(20000)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "url", "secret", "event", "payload", "attempts"}).
+				AddRow(uint64(9), "https://example.com/hook", "key", "secret.changed", []byte(`{}`), 2))
+
+		tasks, err := repo.ClaimDue(ctx, 50, 20*time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.WebhookTaskDTO{{ID: 9, URL: "https://example.com/hook", Secret: "key", Event: "secret.changed", Payload: []byte(`{}`), Attempts: 2}}, tasks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("SaveAttempt", func(t *testing.T) {
+		next := time.Now()
+		mock.ExpectExec("update webhook_deliveries set status = \\$2, attempts = attempts \\+ 1").
+			WithArgs(uint64(9), "pending", nil, "dial tcp: connection refused", next).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.SaveAttempt(ctx, 9, models.WebhookAttemptDTO{Status: "pending", Error: "dial tcp: connection refused", NextAttemptAt: next})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -3,95 +3,228 @@ package service
 import (
 	"context"
 	"strconv"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/shekshuev/gophkeeper/internal/config"
 	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/metrics"
 	"github.com/shekshuev/gophkeeper/internal/models"
 	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
 	"github.com/shekshuev/gophkeeper/internal/utils"
 )
 
 // AuthServiceImpl — реализация интерфейса AuthService.
 // Отвечает за логику регистрации, аутентификации и генерации JWT-токенов.
+// Попытки входа сохраняются, чтобы сообщать вебхукам о входе с нового устройства и о серии неудачных попыток.
+// Если при входе передан запрос на сертификат, устройству выпускается сертификат, а токены привязываются к нему.
 type AuthServiceImpl struct {
-	repo   repository.UserRepository // Репозиторий пользователей
-	cfg    *config.Config            // Конфигурация приложения (секреты и срок жизни токенов)
-	logger *logger.Logger            // Логгер
+	repo     repository.UserRepository         // Репозиторий пользователей
+	folders  repository.FolderRepository       // Репозиторий папок (папки нового пользователя)
+	attempts repository.LoginAttemptRepository // Журнал попыток входа
+	webhooks WebhookService                    // Очередь событий вебхуков
+	devices  DeviceService                     // Сертификаты устройств
+	tx       repository.TxManager              // Транзакции регистрации
+	cfg      *config.Config                    // Конфигурация приложения (секреты и срок жизни токенов)
+	logger   *logger.Logger                    // Логгер
 }
 
-// NewAuthServiceImpl создаёт новый экземпляр AuthServiceImpl с указанным репозиторием и конфигурацией.
-func NewAuthServiceImpl(repo repository.UserRepository, cfg *config.Config) *AuthServiceImpl {
+// NewAuthServiceImpl создаёт новый экземпляр AuthServiceImpl с указанными репозиториями и конфигурацией.
+func NewAuthServiceImpl(repo repository.UserRepository, folders repository.FolderRepository, attempts repository.LoginAttemptRepository, webhooks WebhookService, devices DeviceService, tx repository.TxManager, cfg *config.Config) *AuthServiceImpl {
 	return &AuthServiceImpl{
-		repo:   repo,
-		cfg:    cfg,
-		logger: logger.NewLogger(),
+		repo:     repo,
+		folders:  folders,
+		attempts: attempts,
+		webhooks: webhooks,
+		devices:  devices,
+		tx:       tx,
+		cfg:      cfg,
+		logger:   logger.NewLogger(),
 	}
 }
 
 // Login выполняет аутентификацию пользователя по логину и паролю.
 // При успехе возвращает пару access/refresh токенов.
+//
+// Если сертификаты устройств включены и передан запрос на сертификат dto.CSR, устройству выпускается сертификат,
+// а токены привязываются к нему и без ключа устройства бесполезны; если выключены, запрос на сертификат не учитывается.
+// В режиме config.DeviceCertRequired вход без запроса на сертификат отклоняется с ErrDeviceCertificateRequired.
 func (s *AuthServiceImpl) Login(ctx context.Context, dto models.LoginUserDTO) (*models.ReadTokenDTO, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Login")
+	defer span.End()
+
+	if s.cfg.DeviceCertMode == config.DeviceCertRequired && dto.CSR == "" {
+		s.logger.For(ctx).Warn("Вход без запроса на сертификат устройства", zap.String("user_name", dto.UserName))
+		metrics.AuthAttempt("login", false)
+		return nil, ErrDeviceCertificateRequired
+	}
 	user, err := s.repo.GetUserByUserName(ctx, dto.UserName)
 	if err != nil {
-		s.logger.Log.Warn("Пользователь не найден при логине", zap.String("user_name", dto.UserName), zap.Error(err))
+		s.logger.For(ctx).Warn("Пользователь не найден при логине", zap.String("user_name", dto.UserName), zap.Error(err))
+		metrics.AuthAttempt("login", false)
 		return nil, ErrUserNotFound
 	}
 	if !utils.VerifyPassword(dto.Password, user.PasswordHash) {
-		s.logger.Log.Warn("Неверный пароль", zap.String("user_name", dto.UserName))
+		s.logger.For(ctx).Warn("Неверный пароль", zap.String("user_name", dto.UserName))
+		s.recordFailure(ctx, user.ID, dto.Device, dto.IP)
+		metrics.AuthAttempt("login", false)
 		return nil, ErrWrongPassword
 	}
+	var thumbprint, certPEM string
+	if dto.CSR != "" && (s.cfg.DeviceCertMode == config.DeviceCertOptional || s.cfg.DeviceCertMode == config.DeviceCertRequired) {
+		device, issued, err := s.devices.Enroll(ctx, *user, dto.Device, dto.CSR)
+		if err != nil {
+			metrics.AuthAttempt("login", false)
+			return nil, err
+		}
+		thumbprint, certPEM = device.Thumbprint, issued
+	}
+	s.recordSuccess(ctx, user.ID, dto.Device, dto.IP)
+	metrics.AuthAttempt("login", true)
 
-	s.logger.Log.Info("Пользователь успешно аутентифицирован", zap.Uint64("user_id", user.ID), zap.String("user_name", user.UserName))
-	return s.generateTokenPair(*user)
+	s.logger.For(ctx).Info("Пользователь успешно аутентифицирован", zap.Uint64("user_id", user.ID), zap.String("user_name", user.UserName))
+	tokens, err := s.generateTokenPair(ctx, *user, thumbprint)
+	if err != nil {
+		return nil, err
+	}
+	tokens.ClientCertificate = certPEM
+	return tokens, nil
 }
 
 // Register регистрирует нового пользователя и сразу возвращает access/refresh токены.
-// Пароль хешируется перед сохранением.
+// Пароль хешируется перед сохранением. Пользователь и его папки из cfg.DefaultFolders
+// создаются в одной транзакции: если папку создать не удалось, пользователь не сохраняется.
 func (s *AuthServiceImpl) Register(ctx context.Context, dto models.RegisterUserDTO) (*models.ReadTokenDTO, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Register")
+	defer span.End()
+
 	createDTO := models.CreateUserDTO{
 		UserName:     dto.UserName,
 		PasswordHash: utils.HashPassword(dto.Password),
 		FirstName:    dto.FirstName,
 		LastName:     dto.LastName,
 	}
-	user, err := s.repo.CreateUser(ctx, createDTO)
+	var user *models.ReadAuthUserDataDTO
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if user, err = s.repo.CreateUser(ctx, createDTO); err != nil {
+			return err
+		}
+		return s.createDefaultFolders(ctx, user.ID)
+	})
 	if err != nil {
-		s.logger.Log.Error("Ошибка при регистрации пользователя", zap.String("user_name", dto.UserName), zap.Error(err))
+		s.logger.For(ctx).Error("Ошибка при регистрации пользователя", zap.String("user_name", dto.UserName), zap.Error(err))
+		metrics.AuthAttempt("register", false)
 		return nil, err
 	}
+	metrics.AuthAttempt("register", true)
+
+	s.logger.For(ctx).Info("Пользователь успешно зарегистрирован", zap.Uint64("user_id", user.ID), zap.String("user_name", user.UserName))
+	s.record(ctx, models.LoginAttemptDTO{UserID: user.ID, Success: true, Device: dto.Device, IP: dto.IP})
+	return s.generateTokenPair(ctx, *user, "")
+}
+
+// createDefaultFolders создаёт новому пользователю папки из cfg.DefaultFolders.
+// Пустые названия и повторы пропускаются.
+func (s *AuthServiceImpl) createDefaultFolders(ctx context.Context, userID uint64) error {
+	seen := make(map[string]bool, len(s.cfg.DefaultFolders))
+	for _, name := range s.cfg.DefaultFolders {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if _, err := s.folders.Create(ctx, models.CreateFolderDTO{UserID: userID, Name: name}); err != nil {
+			s.logger.For(ctx).Error("Не удалось создать папку нового пользователя", zap.Uint64("user_id", userID), zap.String("name", name), zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// recordFailure сохраняет неудачную попытку входа. Когда число неудачных попыток за cfg.LoginFailureWindow
+// достигает cfg.LoginFailureBurst, вебхукам отправляется событие login.failure_burst — один раз на серию.
+// Ошибки только записываются в журнал, чтобы не мешать ответу на попытку входа.
+func (s *AuthServiceImpl) recordFailure(ctx context.Context, userID uint64, device, ip string) {
+	if !s.record(ctx, models.LoginAttemptDTO{UserID: userID, Device: device, IP: ip}) {
+		return
+	}
+	failures, err := s.attempts.CountFailures(ctx, userID, time.Now().Add(-s.cfg.LoginFailureWindow))
+	if err != nil {
+		s.logger.For(ctx).Error("Не удалось подсчитать неудачные попытки входа", zap.Uint64("user_id", userID), zap.Error(err))
+		return
+	}
+	if failures == s.cfg.LoginFailureBurst {
+		s.logger.For(ctx).Warn("Серия неудачных попыток входа", zap.Uint64("user_id", userID), zap.Int("failures", failures))
+		s.notify(ctx, userID, models.WebhookEventLoginFailureBurst, models.LoginAlertDTO{Device: device, IP: ip, Failures: failures})
+	}
+}
 
-	s.logger.Log.Info("Пользователь успешно зарегистрирован", zap.Uint64("user_id", user.ID), zap.String("user_name", user.UserName))
-	return s.generateTokenPair(*user)
+// recordSuccess сохраняет успешный вход. Если пользователь раньше не входил с этого устройства,
+// вебхукам отправляется событие login.new_device. Ошибки только записываются в журнал.
+func (s *AuthServiceImpl) recordSuccess(ctx context.Context, userID uint64, device, ip string) {
+	known := true
+	if device != "" {
+		var err error
+		if known, err = s.attempts.IsKnownDevice(ctx, userID, device); err != nil {
+			s.logger.For(ctx).Error("Не удалось проверить устройство пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+			known = true
+		}
+	}
+	if s.record(ctx, models.LoginAttemptDTO{UserID: userID, Success: true, Device: device, IP: ip}) && !known {
+		s.logger.For(ctx).Info("Вход с нового устройства", zap.Uint64("user_id", userID), zap.String("device", device))
+		s.notify(ctx, userID, models.WebhookEventLoginNewDevice, models.LoginAlertDTO{Device: device, IP: ip})
+	}
+}
+
+// record сохраняет попытку входа и сообщает, удалось ли это.
+func (s *AuthServiceImpl) record(ctx context.Context, dto models.LoginAttemptDTO) bool {
+	if err := s.attempts.Record(ctx, dto); err != nil {
+		s.logger.For(ctx).Error("Не удалось сохранить попытку входа", zap.Uint64("user_id", dto.UserID), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// notify ставит событие входа в очередь вебхуков пользователя.
+func (s *AuthServiceImpl) notify(ctx context.Context, userID uint64, event string, alert models.LoginAlertDTO) {
+	if err := s.webhooks.Notify(ctx, userID, event, alert); err != nil {
+		s.logger.For(ctx).Error("Не удалось поставить событие входа в очередь вебхуков", zap.Uint64("user_id", userID), zap.String("event", event), zap.Error(err))
+	}
 }
 
 // generateTokenPair создаёт access и refresh JWT-токены для пользователя.
-// Токены подписываются соответствующими секретами из конфигурации.
-func (s *AuthServiceImpl) generateTokenPair(user models.ReadAuthUserDataDTO) (*models.ReadTokenDTO, error) {
+// Токены подписываются соответствующими секретами из конфигурации. Если задан отпечаток сертификата устройства
+// thumbprint, токены привязываются к этому сертификату.
+func (s *AuthServiceImpl) generateTokenPair(ctx context.Context, user models.ReadAuthUserDataDTO, thumbprint string) (*models.ReadTokenDTO, error) {
 	userID := strconv.FormatUint(user.ID, 10)
 
-	accessToken, err := utils.CreateToken(
+	accessToken, err := utils.CreateBoundToken(
 		s.cfg.AccessTokenSecret,
 		userID,
+		thumbprint,
 		s.cfg.AccessTokenExpires,
 	)
 	if err != nil {
-		s.logger.Log.Error("Ошибка при создании access токена", zap.Uint64("user_id", user.ID), zap.Error(err))
+		s.logger.For(ctx).Error("Ошибка при создании access токена", zap.Uint64("user_id", user.ID), zap.Error(err))
 		return nil, err
 	}
 
-	refreshToken, err := utils.CreateToken(
+	refreshToken, err := utils.CreateBoundToken(
 		s.cfg.RefreshTokenSecret,
 		userID,
+		thumbprint,
 		s.cfg.RefreshTokenExpires,
 	)
 	if err != nil {
-		s.logger.Log.Error("Ошибка при создании refresh токена", zap.Uint64("user_id", user.ID), zap.Error(err))
+		s.logger.For(ctx).Error("Ошибка при создании refresh токена", zap.Uint64("user_id", user.ID), zap.Error(err))
 		return nil, err
 	}
 
-	s.logger.Log.Info("JWT-токены успешно сгенерированы", zap.Uint64("user_id", user.ID))
+	s.logger.For(ctx).Info("JWT-токены успешно сгенерированы", zap.Uint64("user_id", user.ID))
 	return &models.ReadTokenDTO{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/shekshuev/gophkeeper/internal/config"
@@ -13,12 +14,14 @@ import (
 	"github.com/shekshuev/gophkeeper/internal/models"
 	"github.com/shekshuev/gophkeeper/internal/utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewAuthServiceImpl(t *testing.T) {
 	cfg := config.GetConfig()
-	repo := mocks.NewMockUserRepository(gomock.NewController(t))
-	svc := NewAuthServiceImpl(repo, &cfg)
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockUserRepository(ctrl)
+	svc := NewAuthServiceImpl(repo, mocks.NewMockFolderRepository(ctrl), mocks.NewMockLoginAttemptRepository(ctrl), mocks.NewMockWebhookService(ctrl), mocks.NewMockDeviceService(ctrl), passthroughTx{}, &cfg)
 	assert.NotNil(t, svc)
 }
 
@@ -30,8 +33,13 @@ func TestAuthServiceImpl_Login(t *testing.T) {
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockUserRepository(ctrl)
-	authService := &AuthServiceImpl{repo: repo, cfg: &cfg, logger: logger.NewLogger()}
+	attempts := mocks.NewMockLoginAttemptRepository(ctrl)
+	webhooks := mocks.NewMockWebhookService(ctrl)
+	cfg.LoginFailureBurst = 3
+	cfg.LoginFailureWindow = 15 * time.Minute
+	authService := &AuthServiceImpl{repo: repo, attempts: attempts, webhooks: webhooks, cfg: &cfg, logger: logger.NewLogger()}
 	ctx := context.Background()
+	user := &models.ReadAuthUserDataDTO{ID: 1, UserName: "testuser", PasswordHash: utils.HashPassword("password123")}
 
 	testCases := []struct {
 		name     string
@@ -47,11 +55,53 @@ func TestAuthServiceImpl_Login(t *testing.T) {
 			},
 			hasError: false,
 			mockSet: func() {
-				repo.EXPECT().GetUserByUserName(ctx, "testuser").Return(&models.ReadAuthUserDataDTO{
-					ID:           1,
-					UserName:     "testuser",
-					PasswordHash: utils.HashPassword("password123"),
-				}, nil)
+				repo.EXPECT().GetUserByUserName(ctx, "testuser").Return(user, nil)
+				attempts.EXPECT().Record(ctx, models.LoginAttemptDTO{UserID: 1, Success: true})
+			},
+		},
+		{
+			name: "Success known device",
+			dto: models.LoginUserDTO{
+				UserName: "testuser",
+				Password: "password123",
+				Device:   "laptop",
+				IP:       "192.0.2.1",
+			},
+			hasError: false,
+			mockSet: func() {
+				repo.EXPECT().GetUserByUserName(ctx, "testuser").Return(user, nil)
+				attempts.EXPECT().IsKnownDevice(ctx, uint64(1), "laptop").Return(true, nil)
+				attempts.EXPECT().Record(ctx, models.LoginAttemptDTO{UserID: 1, Success: true, Device: "laptop", IP: "192.0.2.1"})
+			},
+		},
+		{
+			name: "Success new device",
+			dto: models.LoginUserDTO{
+				UserName: "testuser",
+				Password: "password123",
+				Device:   "phone",
+				IP:       "192.0.2.1",
+			},
+			hasError: false,
+			mockSet: func() {
+				repo.EXPECT().GetUserByUserName(ctx, "testuser").Return(user, nil)
+				attempts.EXPECT().IsKnownDevice(ctx, uint64(1), "phone").Return(false, nil)
+				attempts.EXPECT().Record(ctx, models.LoginAttemptDTO{UserID: 1, Success: true, Device: "phone", IP: "192.0.2.1"})
+				webhooks.EXPECT().Notify(ctx, uint64(1), models.WebhookEventLoginNewDevice, models.LoginAlertDTO{Device: "phone", IP: "192.0.2.1"})
+			},
+		},
+		{
+			name: "Success when attempt is not recorded",
+			dto: models.LoginUserDTO{
+				UserName: "testuser",
+				Password: "password123",
+				Device:   "phone",
+			},
+			hasError: false,
+			mockSet: func() {
+				repo.EXPECT().GetUserByUserName(ctx, "testuser").Return(user, nil)
+				attempts.EXPECT().IsKnownDevice(ctx, uint64(1), "phone").Return(false, nil)
+				attempts.EXPECT().Record(ctx, gomock.Any()).Return(assert.AnError)
 			},
 		},
 		{
@@ -73,11 +123,38 @@ func TestAuthServiceImpl_Login(t *testing.T) {
 			},
 			hasError: true,
 			mockSet: func() {
-				repo.EXPECT().GetUserByUserName(ctx, "testuser").Return(&models.ReadAuthUserDataDTO{
-					ID:           1,
-					UserName:     "testuser",
-					PasswordHash: utils.HashPassword("password123"),
-				}, nil)
+				repo.EXPECT().GetUserByUserName(ctx, "testuser").Return(user, nil)
+				attempts.EXPECT().Record(ctx, models.LoginAttemptDTO{UserID: 1})
+				attempts.EXPECT().CountFailures(ctx, uint64(1), gomock.Any()).Return(1, nil)
+			},
+		},
+		{
+			name: "Wrong password burst",
+			dto: models.LoginUserDTO{
+				UserName: "testuser",
+				Password: "wrongpassword",
+				IP:       "203.0.113.5",
+			},
+			hasError: true,
+			mockSet: func() {
+				repo.EXPECT().GetUserByUserName(ctx, "testuser").Return(user, nil)
+				attempts.EXPECT().Record(ctx, models.LoginAttemptDTO{UserID: 1, IP: "203.0.113.5"})
+				attempts.EXPECT().CountFailures(ctx, uint64(1), gomock.Any()).Return(3, nil)
+				webhooks.EXPECT().Notify(ctx, uint64(1), models.WebhookEventLoginFailureBurst, models.LoginAlertDTO{IP: "203.0.113.5", Failures: 3}).
+					Return(assert.AnError)
+			},
+		},
+		{
+			name: "Wrong password after burst",
+			dto: models.LoginUserDTO{
+				UserName: "testuser",
+				Password: "wrongpassword",
+			},
+			hasError: true,
+			mockSet: func() {
+				repo.EXPECT().GetUserByUserName(ctx, "testuser").Return(user, nil)
+				attempts.EXPECT().Record(ctx, gomock.Any())
+				attempts.EXPECT().CountFailures(ctx, uint64(1), gomock.Any()).Return(4, nil)
 			},
 		},
 	}
@@ -95,6 +172,58 @@ func TestAuthServiceImpl_Login(t *testing.T) {
 	}
 }
 
+func TestAuthServiceImpl_Login_DeviceCertificate(t *testing.T) {
+	os.Setenv("ACCESS_TOKEN_SECRET", "test")
+	os.Setenv("REFRESH_TOKEN_SECRET", "test")
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockUserRepository(ctrl)
+	attempts := mocks.NewMockLoginAttemptRepository(ctrl)
+	devices := mocks.NewMockDeviceService(ctrl)
+	cfg.DeviceCertMode = config.DeviceCertRequired
+	authService := &AuthServiceImpl{repo: repo, attempts: attempts, devices: devices, cfg: &cfg, logger: logger.NewLogger()}
+	ctx := context.Background()
+	user := &models.ReadAuthUserDataDTO{ID: 1, UserName: "testuser", PasswordHash: utils.HashPassword("password123")}
+
+	t.Run("CSR_required", func(t *testing.T) {
+		_, err := authService.Login(ctx, models.LoginUserDTO{UserName: "testuser", Password: "password123"})
+		assert.ErrorIs(t, err, ErrDeviceCertificateRequired)
+	})
+
+	t.Run("Certificate_issued", func(t *testing.T) {
+		repo.EXPECT().GetUserByUserName(ctx, "testuser").Return(user, nil)
+		devices.EXPECT().Enroll(ctx, *user, "", "csr").Return(&models.DeviceCertificateDTO{Thumbprint: "thumb"}, "cert", nil)
+		attempts.EXPECT().Record(ctx, models.LoginAttemptDTO{UserID: 1, Success: true})
+
+		tokens, err := authService.Login(ctx, models.LoginUserDTO{UserName: "testuser", Password: "password123", CSR: "csr"})
+		assert.NoError(t, err)
+		assert.Equal(t, "cert", tokens.ClientCertificate)
+		claims, err := utils.GetToken(tokens.AccessToken, cfg.AccessTokenSecret)
+		require.NoError(t, err)
+		assert.Equal(t, "thumb", claims.CertThumbprint())
+	})
+
+	t.Run("Invalid_CSR", func(t *testing.T) {
+		repo.EXPECT().GetUserByUserName(ctx, "testuser").Return(user, nil)
+		devices.EXPECT().Enroll(ctx, *user, "", "garbage").Return(nil, "", ErrInvalidCSR)
+
+		_, err := authService.Login(ctx, models.LoginUserDTO{UserName: "testuser", Password: "password123", CSR: "garbage"})
+		assert.ErrorIs(t, err, ErrInvalidCSR)
+	})
+
+	t.Run("Wrong_password_before_enroll", func(t *testing.T) {
+		repo.EXPECT().GetUserByUserName(ctx, "testuser").Return(user, nil)
+		attempts.EXPECT().Record(ctx, models.LoginAttemptDTO{UserID: 1})
+		attempts.EXPECT().CountFailures(ctx, uint64(1), gomock.Any()).Return(1, nil)
+
+		_, err := authService.Login(ctx, models.LoginUserDTO{UserName: "testuser", Password: "wrong", CSR: "csr"})
+		assert.ErrorIs(t, err, ErrWrongPassword)
+	})
+}
+
 func TestAuthServiceImpl_Register(t *testing.T) {
 	os.Setenv("ACCESS_TOKEN_SECRET", "test")
 	os.Setenv("REFRESH_TOKEN_SECRET", "test")
@@ -103,7 +232,10 @@ func TestAuthServiceImpl_Register(t *testing.T) {
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockUserRepository(ctrl)
-	authService := &AuthServiceImpl{repo: repo, cfg: &cfg, logger: logger.NewLogger()}
+	folders := mocks.NewMockFolderRepository(ctrl)
+	attempts := mocks.NewMockLoginAttemptRepository(ctrl)
+	cfg.DefaultFolders = []string{"Личное", " Работа ", "", "Личное"}
+	authService := &AuthServiceImpl{repo: repo, folders: folders, attempts: attempts, tx: passthroughTx{}, cfg: &cfg, logger: logger.NewLogger()}
 	ctx := context.Background()
 
 	fixedPasswordHash := "$2a$10$CmIxNqxCFrgFoji4qyka0.UvTV4wG54LN5UJjV7mfH6q0caiNGUvK"
@@ -122,6 +254,7 @@ func TestAuthServiceImpl_Register(t *testing.T) {
 				PasswordConfirm: "password123",
 				FirstName:       "Test",
 				LastName:        "User",
+				Device:          "laptop",
 			},
 			expectedErr: false,
 			mockSet: func() {
@@ -130,6 +263,22 @@ func TestAuthServiceImpl_Register(t *testing.T) {
 					UserName:     "testuser",
 					PasswordHash: fixedPasswordHash,
 				}, nil)
+				folders.EXPECT().Create(ctx, models.CreateFolderDTO{UserID: 1, Name: "Личное"}).Return(&models.ReadFolderDTO{ID: 1}, nil)
+				folders.EXPECT().Create(ctx, models.CreateFolderDTO{UserID: 1, Name: "Работа"}).Return(&models.ReadFolderDTO{ID: 2}, nil)
+				attempts.EXPECT().Record(ctx, models.LoginAttemptDTO{UserID: 1, Success: true, Device: "laptop"})
+			},
+		},
+		{
+			name: "Default folder error",
+			dto: models.RegisterUserDTO{
+				UserName:        "testuser",
+				Password:        "password123",
+				PasswordConfirm: "password123",
+			},
+			expectedErr: true,
+			mockSet: func() {
+				repo.EXPECT().CreateUser(ctx, gomock.Any()).Return(&models.ReadAuthUserDataDTO{ID: 1, UserName: "testuser"}, nil)
+				folders.EXPECT().Create(ctx, models.CreateFolderDTO{UserID: 1, Name: "Личное"}).Return(nil, assert.AnError)
 			},
 		},
 	}
@@ -153,7 +302,7 @@ func TestAuthServiceImpl_Register_CreateUserError(t *testing.T) {
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockUserRepository(ctrl)
-	authService := NewAuthServiceImpl(repo, &cfg)
+	authService := NewAuthServiceImpl(repo, nil, nil, nil, nil, passthroughTx{}, &cfg)
 	ctx := context.Background()
 
 	dto := models.RegisterUserDTO{
@@ -183,13 +332,13 @@ func TestAuthServiceImpl_generateTokenPair_Errors(t *testing.T) {
 		RefreshTokenExpires: 0,
 	}
 
-	service := NewAuthServiceImpl(repo, cfg)
+	service := NewAuthServiceImpl(repo, nil, nil, nil, nil, passthroughTx{}, cfg)
 	user := models.ReadAuthUserDataDTO{
 		ID:       1,
 		UserName: "brokenuser",
 	}
 
-	token, err := service.generateTokenPair(user)
+	token, err := service.generateTokenPair(context.Background(), user, "")
 	assert.Error(t, err)
 	assert.Nil(t, token)
 }
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// batchEvents сопоставляет операции пакета с типами событий ленты изменений.
+var batchEvents = map[string]string{
+	models.BatchOpCreate: models.SecretEventCreated,
+	models.BatchOpUpdate: models.SecretEventUpdated,
+	models.BatchOpDelete: models.SecretEventDeleted,
+}
+
+// Batch выполняет пакет операций пользователя над секретами в одной транзакции.
+//
+// Папки операций create и update проверяются в той же транзакции, что и запись пакета (каждая папка — один раз):
+// операция с чужой или несуществующей папкой завершается ErrFolderNotFound и не выполняется.
+// Теги очищаются от пробелов и дублей так же, как при одиночных запросах. При atomic = true
+// любая ошибка отменяет весь пакет, а остальные операции получают ErrBatchRolledBack.
+func (s *SecretServiceImpl) Batch(ctx context.Context, userID uint64, ops []models.BatchOperationDTO, atomic bool, device string) ([]models.BatchOutcome, error) {
+	ctx, span := tracing.Start(ctx, "SecretService.Batch")
+	defer span.End()
+
+	var (
+		outcomes []models.BatchOutcome
+		changes  []models.SecretEventDTO
+	)
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		outcomes = make([]models.BatchOutcome, len(ops))
+		changes = nil
+		valid := make([]models.BatchOperationDTO, 0, len(ops))
+		index := make([]int, 0, len(ops))
+		checked := make(map[uint64]error)
+		rejected := false
+
+		for i, op := range ops {
+			if op.Op != models.BatchOpDelete && op.FolderID != nil {
+				err, ok := checked[*op.FolderID]
+				if !ok {
+					err = s.checkFolder(ctx, userID, op.FolderID)
+					checked[*op.FolderID] = err
+				}
+				if errors.Is(err, ErrFolderNotFound) {
+					outcomes[i] = models.BatchOutcome{ID: op.ID, Err: err}
+					rejected = true
+					continue
+				}
+				if err != nil {
+					return err
+				}
+			}
+			op.Tags = normalizeTags(op.Tags)
+			valid = append(valid, op)
+			index = append(index, i)
+		}
+
+		if rejected && atomic {
+			for _, i := range index {
+				outcomes[i].Err = ErrBatchRolledBack
+			}
+			s.logger.For(ctx).Warn("Пакет операций отменён: папка не найдена", zap.Uint64("user_id", userID))
+			return nil
+		}
+		if len(valid) == 0 {
+			return nil
+		}
+
+		applied, err := s.repo.ApplyBatch(ctx, userID, valid, device, atomic)
+		if err != nil {
+			return err
+		}
+		for j, outcome := range applied {
+			if errors.Is(outcome.Err, repository.ErrNotFound) {
+				outcome.Err = ErrSecretNotFound
+			}
+			outcomes[index[j]] = outcome
+			if outcome.Err != nil {
+				continue
+			}
+			event, err := s.record(ctx, batchEvents[valid[j].Op], userID, outcome.ID, outcome.Version)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, event)
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.For(ctx).Error("Не удалось выполнить пакет операций", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	for _, event := range changes {
+		s.publish(event)
+	}
+
+	s.logger.For(ctx).Info("Пакет операций обработан", zap.Uint64("user_id", userID), zap.Int("count", len(ops)))
+	return outcomes, nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/shekshuev/gophkeeper/internal/events"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+)
+
+// markingTx выполняет fn, помечая контекст, чтобы тест мог проверить, что вызов сделан внутри транзакции.
+type markingTx struct{}
+
+type inTxKey struct{}
+
+func (markingTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(context.WithValue(ctx, inTxKey{}, true))
+}
+
+func TestSecretServiceImpl_Batch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockSecretRepository(ctrl)
+	mockFolders := mocks.NewMockFolderRepository(ctrl)
+	broker := events.NewBroker()
+	service := NewSecretServiceImpl(mockRepo, mockFolders, mocks.NewMockSecretAuditRepository(ctrl), passthroughTx{}, anyWebhooks(ctrl), broker)
+	feed, unsubscribe := broker.Subscribe(1)
+	defer unsubscribe()
+
+	own, foreign := uint64(5), uint64(6)
+	ops := []models.BatchOperationDTO{
+		{Op: models.BatchOpCreate, Title: "A", FolderID: &own, Tags: []string{" work ", "work"}},
+		{Op: models.BatchOpCreate, Title: "B", FolderID: &foreign},
+		{Op: models.BatchOpUpdate, ID: 7, Title: "C", FolderID: &own},
+		{Op: models.BatchOpDelete, ID: 8, FolderID: &foreign},
+	}
+
+	t.Run("Partial", func(t *testing.T) {
+		mockFolders.EXPECT().GetByID(gomock.Any(), uint64(1), own).Return(&models.ReadFolderDTO{ID: own}, nil)
+		mockFolders.EXPECT().GetByID(gomock.Any(), uint64(1), foreign).Return(nil, repository.ErrNotFound)
+		mockRepo.EXPECT().
+			ApplyBatch(gomock.Any(), uint64(1), gomock.Any(), "laptop", false).
+			DoAndReturn(func(_ context.Context, _ uint64, valid []models.BatchOperationDTO, _ string, _ bool) ([]models.BatchOutcome, error) {
+				assert.Len(t, valid, 3)
+				assert.Equal(t, []string{"work"}, valid[0].Tags)
+				return []models.BatchOutcome{{ID: 10, Version: 1}, {ID: 7, Err: repository.ErrNotFound}, {ID: 8}}, nil
+			})
+		gomock.InOrder(
+			expectChange(mockRepo, models.SecretEventCreated, 1, 10, 1, 20),
+			expectChange(mockRepo, models.SecretEventDeleted, 1, 8, 0, 21),
+		)
+
+		outcomes, err := service.Batch(context.Background(), 1, ops, false, "laptop")
+		assert.NoError(t, err)
+		assert.Equal(t, []models.BatchOutcome{
+			{ID: 10, Version: 1},
+			{Err: ErrFolderNotFound},
+			{ID: 7, Err: ErrSecretNotFound},
+			{ID: 8},
+		}, outcomes)
+		assert.Equal(t, models.SecretEventDTO{ID: 20, Type: models.SecretEventCreated, UserID: 1, SecretID: 10, Version: 1}, <-feed)
+		assert.Equal(t, models.SecretEventDTO{ID: 21, Type: models.SecretEventDeleted, UserID: 1, SecretID: 8}, <-feed)
+		assert.Empty(t, feed)
+	})
+
+	t.Run("Atomic_folder_not_found", func(t *testing.T) {
+		mockFolders.EXPECT().GetByID(gomock.Any(), uint64(1), own).Return(&models.ReadFolderDTO{ID: own}, nil)
+		mockFolders.EXPECT().GetByID(gomock.Any(), uint64(1), foreign).Return(nil, repository.ErrNotFound)
+
+		outcomes, err := service.Batch(context.Background(), 1, ops, true, "")
+		assert.NoError(t, err)
+		assert.ErrorIs(t, outcomes[1].Err, ErrFolderNotFound)
+		for _, i := range []int{0, 2, 3} {
+			assert.ErrorIs(t, outcomes[i].Err, ErrBatchRolledBack)
+		}
+	})
+
+	t.Run("Transaction_error", func(t *testing.T) {
+		mockRepo.EXPECT().ApplyBatch(gomock.Any(), uint64(1), gomock.Any(), "", true).Return(nil, errors.New("tx error"))
+
+		outcomes, err := service.Batch(context.Background(), 1, ops[3:], true, "")
+		assert.Error(t, err)
+		assert.Nil(t, outcomes)
+	})
+
+	t.Run("Folder_checked_in_transaction", func(t *testing.T) {
+		service := NewSecretServiceImpl(mockRepo, mockFolders, mocks.NewMockSecretAuditRepository(ctrl), markingTx{}, anyWebhooks(ctrl), broker)
+		mockFolders.EXPECT().GetByID(gomock.Any(), uint64(1), own).
+			DoAndReturn(func(ctx context.Context, _, _ uint64) (*models.ReadFolderDTO, error) {
+				assert.Equal(t, true, ctx.Value(inTxKey{}), "folder must be checked inside the transaction")
+				return &models.ReadFolderDTO{ID: own}, nil
+			})
+		mockRepo.EXPECT().ApplyBatch(gomock.Any(), uint64(1), gomock.Any(), "", true).Return([]models.BatchOutcome{{ID: 10, Version: 1}}, nil)
+		mockRepo.EXPECT().CreateChange(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, event models.SecretEventDTO) (*models.SecretEventDTO, error) {
+				assert.Equal(t, true, ctx.Value(inTxKey{}), "change must be recorded inside the transaction")
+				return &event, nil
+			})
+
+		outcomes, err := service.Batch(context.Background(), 1, ops[:1], true, "")
+		assert.NoError(t, err)
+		assert.NoError(t, outcomes[0].Err)
+		<-feed
+	})
+}
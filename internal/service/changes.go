@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// changesPageSize — размер страницы, которой читается журнал изменений секретов.
+const changesPageSize = 500
+
+// GetChangesAfter передаёт в send изменения секретов пользователя из журнала с ID больше afterID в порядке ID.
+// ID записей журнала растут в порядке фиксации изменений, поэтому клиент, запомнивший ID последнего
+// полученного события, после переподключения получит все изменения, зафиксированные позже, и только их.
+// Журнал читается страницами, и каждая страница передаётся сразу, не дожидаясь остальных.
+//
+// Изменения старше cfg.SecretChangesRetention из журнала удаляются (см. TrashPurger). Если записи afterID
+// в журнале уже нет, часть изменений после неё могла быть удалена: тогда вместо них передаётся одно событие
+// models.SecretEventReset с ID последней записи журнала пользователя, и клиент заново получает все секреты.
+// Ошибка send прерывает чтение и возвращается.
+func (s *SecretServiceImpl) GetChangesAfter(ctx context.Context, userID, afterID uint64, send func(models.SecretEventDTO) error) error {
+	ctx, span := tracing.Start(ctx, "SecretService.GetChangesAfter")
+	defer span.End()
+
+	if afterID > 0 {
+		oldest, newest, err := s.repo.GetChangeRange(ctx, userID)
+		if err != nil {
+			s.logger.For(ctx).Error("Ошибка при получении границ журнала изменений секретов", zap.Uint64("user_id", userID), zap.Error(err))
+			return err
+		}
+		if oldest == 0 || afterID < oldest {
+			s.logger.For(ctx).Warn("Изменения после места возобновления ленты удалены из журнала, нужна полная синхронизация",
+				zap.Uint64("user_id", userID), zap.Uint64("after_id", afterID), zap.Uint64("oldest_id", oldest))
+			return send(models.SecretEventDTO{ID: newest, Type: models.SecretEventReset, UserID: userID, Time: time.Now().UTC()})
+		}
+	}
+
+	count, err := s.sendChanges(ctx, userID, models.SecretChangeFilterDTO{AfterID: afterID}, send)
+	if err != nil {
+		return err
+	}
+	s.logger.For(ctx).Info("Изменения секретов получены из журнала", zap.Uint64("user_id", userID), zap.Uint64("after_id", afterID), zap.Int("count", count))
+	return nil
+}
+
+// GetChangesSince передаёт в send изменения секретов пользователя из журнала, записанные не раньше since, в порядке ID.
+// Время записи назначает база данных; для надёжного возобновления ленты лучше использовать GetChangesAfter.
+// Ошибка send прерывает чтение и возвращается.
+func (s *SecretServiceImpl) GetChangesSince(ctx context.Context, userID uint64, since time.Time, send func(models.SecretEventDTO) error) error {
+	ctx, span := tracing.Start(ctx, "SecretService.GetChangesSince")
+	defer span.End()
+
+	since = since.UTC()
+	count, err := s.sendChanges(ctx, userID, models.SecretChangeFilterDTO{Since: since}, send)
+	if err != nil {
+		return err
+	}
+	s.logger.For(ctx).Info("Изменения секретов получены из журнала", zap.Uint64("user_id", userID), zap.Time("since", since), zap.Int("count", count))
+	return nil
+}
+
+// sendChanges читает журнал изменений пользователя по фильтру страницами по changesPageSize записей
+// и передаёт записи каждой страницы в send. Возвращает количество переданных записей.
+func (s *SecretServiceImpl) sendChanges(ctx context.Context, userID uint64, filter models.SecretChangeFilterDTO, send func(models.SecretEventDTO) error) (int, error) {
+	filter.Limit = changesPageSize
+	count := 0
+	for {
+		page, err := s.repo.GetChanges(ctx, userID, filter)
+		if err != nil {
+			s.logger.For(ctx).Error("Ошибка при получении журнала изменений секретов", zap.Uint64("user_id", userID), zap.Error(err))
+			return count, err
+		}
+		for _, change := range page {
+			if err := send(change); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if len(page) < changesPageSize {
+			return count, nil
+		}
+		filter.AfterID = page[len(page)-1].ID
+	}
+}
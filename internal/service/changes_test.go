@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/shekshuev/gophkeeper/internal/events"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+)
+
+// collect возвращает send, складывающий переданные события в changes.
+func collect(changes *[]models.SecretEventDTO) func(models.SecretEventDTO) error {
+	return func(change models.SecretEventDTO) error {
+		*changes = append(*changes, change)
+		return nil
+	}
+}
+
+func TestSecretServiceImpl_GetChangesAfter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockSecretRepository(ctrl)
+	service := NewSecretServiceImpl(mockRepo, mocks.NewMockFolderRepository(ctrl), mocks.NewMockSecretAuditRepository(ctrl), passthroughTx{}, anyWebhooks(ctrl), events.NewBroker())
+
+	t.Run("Pages", func(t *testing.T) {
+		first := make([]models.SecretEventDTO, changesPageSize)
+		for i := range first {
+			first[i] = models.SecretEventDTO{ID: uint64(11 + i), Type: models.SecretEventUpdated, UserID: 1, SecretID: 7}
+		}
+		last := first[len(first)-1].ID
+		var changes []models.SecretEventDTO
+		gomock.InOrder(
+			mockRepo.EXPECT().GetChangeRange(gomock.Any(), uint64(1)).Return(uint64(5), last+1, nil),
+			mockRepo.EXPECT().GetChanges(gomock.Any(), uint64(1), models.SecretChangeFilterDTO{AfterID: 10, Limit: changesPageSize}).Return(first, nil),
+			mockRepo.EXPECT().GetChanges(gomock.Any(), uint64(1), models.SecretChangeFilterDTO{AfterID: last, Limit: changesPageSize}).
+				DoAndReturn(func(context.Context, uint64, models.SecretChangeFilterDTO) ([]models.SecretEventDTO, error) {
+					assert.Len(t, changes, changesPageSize, "the first page must be sent before the next one is read")
+					return []models.SecretEventDTO{{ID: last + 1, Type: models.SecretEventDeleted, UserID: 1, SecretID: 7}}, nil
+				}),
+		)
+
+		err := service.GetChangesAfter(context.Background(), 1, 10, collect(&changes))
+		assert.NoError(t, err)
+		assert.Len(t, changes, changesPageSize+1)
+		assert.Equal(t, uint64(11), changes[0].ID)
+		assert.Equal(t, models.SecretEventDeleted, changes[changesPageSize].Type)
+	})
+
+	t.Run("From_start", func(t *testing.T) {
+		var changes []models.SecretEventDTO
+		mockRepo.EXPECT().GetChanges(gomock.Any(), uint64(1), models.SecretChangeFilterDTO{Limit: changesPageSize}).
+			Return([]models.SecretEventDTO{{ID: 3, Type: models.SecretEventCreated, UserID: 1, SecretID: 7}}, nil)
+
+		err := service.GetChangesAfter(context.Background(), 1, 0, collect(&changes))
+		assert.NoError(t, err)
+		assert.Len(t, changes, 1)
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			oldest, newest uint64
+		}{
+			{name: "Purged", oldest: 50, newest: 60},
+			{name: "Empty_journal", oldest: 0, newest: 0},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var changes []models.SecretEventDTO
+				mockRepo.EXPECT().GetChangeRange(gomock.Any(), uint64(1)).Return(tt.oldest, tt.newest, nil)
+
+				err := service.GetChangesAfter(context.Background(), 1, 10, collect(&changes))
+				assert.NoError(t, err)
+				if assert.Len(t, changes, 1) {
+					assert.Equal(t, models.SecretEventReset, changes[0].Type)
+					assert.Equal(t, tt.newest, changes[0].ID)
+					assert.Equal(t, uint64(1), changes[0].UserID)
+				}
+			})
+		}
+	})
+
+	t.Run("Send_error", func(t *testing.T) {
+		sendErr := errors.New("connection closed")
+		mockRepo.EXPECT().GetChangeRange(gomock.Any(), uint64(1)).Return(uint64(1), uint64(20), nil)
+		mockRepo.EXPECT().GetChanges(gomock.Any(), uint64(1), gomock.Any()).
+			Return([]models.SecretEventDTO{{ID: 11}, {ID: 12}}, nil)
+
+		calls := 0
+		err := service.GetChangesAfter(context.Background(), 1, 10, func(models.SecretEventDTO) error {
+			calls++
+			return sendErr
+		})
+		assert.ErrorIs(t, err, sendErr)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Range_error", func(t *testing.T) {
+		mockRepo.EXPECT().GetChangeRange(gomock.Any(), uint64(1)).Return(uint64(0), uint64(0), errors.New("db error"))
+
+		err := service.GetChangesAfter(context.Background(), 1, 10, collect(new([]models.SecretEventDTO)))
+		assert.Error(t, err)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockRepo.EXPECT().GetChanges(gomock.Any(), uint64(1), gomock.Any()).Return(nil, errors.New("db error"))
+
+		var changes []models.SecretEventDTO
+		err := service.GetChangesAfter(context.Background(), 1, 0, collect(&changes))
+		assert.Error(t, err)
+		assert.Empty(t, changes)
+	})
+}
+
+func TestSecretServiceImpl_GetChangesSince(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockSecretRepository(ctrl)
+	service := NewSecretServiceImpl(mockRepo, mocks.NewMockFolderRepository(ctrl), mocks.NewMockSecretAuditRepository(ctrl), passthroughTx{}, anyWebhooks(ctrl), events.NewBroker())
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		expected := []models.SecretEventDTO{{ID: 3, Type: models.SecretEventCreated, UserID: 1, SecretID: 7, Version: 1, Time: since.Add(time.Minute)}}
+		mockRepo.EXPECT().GetChanges(gomock.Any(), uint64(1), models.SecretChangeFilterDTO{Since: since, Limit: changesPageSize}).Return(expected, nil)
+
+		var changes []models.SecretEventDTO
+		err := service.GetChangesSince(context.Background(), 1, since.In(time.FixedZone("MSK", 3*60*60)), collect(&changes))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, changes)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockRepo.EXPECT().GetChanges(gomock.Any(), uint64(1), gomock.Any()).Return(nil, errors.New("db error"))
+
+		var changes []models.SecretEventDTO
+		err := service.GetChangesSince(context.Background(), 1, since, collect(&changes))
+		assert.Error(t, err)
+		assert.Empty(t, changes)
+	})
+}
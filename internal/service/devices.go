@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/certs"
+	"github.com/shekshuev/gophkeeper/internal/events"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// deviceCacheTTL — сколько проверенный сертификат устройства хранится в кэше Authenticate.
+// Отзыв на любом экземпляре сбрасывает запись сразу (см. events.KindDeviceRevoked); срок нужен на случай потерянного сообщения.
+const deviceCacheTTL = time.Minute
+
+// cachedDevice — сертификат устройства в кэше Authenticate.
+type cachedDevice struct {
+	device  models.DeviceCertificateDTO
+	expires time.Time
+}
+
+// DeviceServiceImpl — реализация интерфейса DeviceService.
+// Сертификаты подписывает центр сертификации ca; выданные и отозванные сертификаты хранятся в репозитории,
+// поэтому список отзыва переживает перезапуск сервера и общий у всех его экземпляров.
+// Authenticate вызывается на каждое соединение, поэтому действующие сертификаты кэшируются в памяти,
+// а об отзыве экземпляры сообщают друг другу через relay.
+type DeviceServiceImpl struct {
+	repo   repository.DeviceCertificateRepository // Репозиторий сертификатов устройств
+	ca     *certs.DeviceCA                        // Центр сертификации устройств (nil — сертификаты выключены)
+	relay  events.Relay                           // Канал сообщений между экземплярами сервера
+	mu     sync.Mutex
+	cache  map[string]cachedDevice // Действующие сертификаты по серийному номеру
+	logger *logger.Logger          // Логгер
+}
+
+// NewDeviceServiceImpl создаёт новый экземпляр DeviceServiceImpl и подписывает его кэш на отзывы сертификатов через relay.
+// Если ca равен nil, сертификаты не выпускаются, а предъявленные клиентами сертификаты не принимаются.
+func NewDeviceServiceImpl(repo repository.DeviceCertificateRepository, ca *certs.DeviceCA, relay events.Relay) *DeviceServiceImpl {
+	s := &DeviceServiceImpl{
+		repo:   repo,
+		ca:     ca,
+		relay:  relay,
+		cache:  make(map[string]cachedDevice),
+		logger: logger.NewLogger(),
+	}
+	relay.Subscribe(events.KindDeviceRevoked, s.onRevoked)
+	relay.Subscribe(events.KindReconnect, func(json.RawMessage) { s.clearCache() })
+	return s
+}
+
+// Enroll выпускает сертификат устройству device пользователя по запросу на сертификат csr и сохраняет его.
+// Возвращает запись о сертификате и сам сертификат в PEM.
+func (s *DeviceServiceImpl) Enroll(ctx context.Context, user models.ReadAuthUserDataDTO, device, csr string) (*models.DeviceCertificateDTO, string, error) {
+	ctx, span := tracing.Start(ctx, "DeviceService.Enroll")
+	defer span.End()
+
+	if s.ca == nil {
+		return nil, "", ErrDeviceCertsDisabled
+	}
+	cert, certPEM, err := s.ca.Issue(csr, user.UserName, device, time.Now())
+	if err != nil {
+		s.logger.For(ctx).Warn("Не удалось выпустить сертификат устройства", zap.Uint64("user_id", user.ID), zap.String("device", device), zap.Error(err))
+		return nil, "", err
+	}
+	saved, err := s.repo.Create(ctx, models.CreateDeviceCertificateDTO{
+		UserID:     user.ID,
+		Device:     device,
+		Serial:     certs.Serial(cert),
+		Thumbprint: certs.Thumbprint(cert),
+		ExpiresAt:  cert.NotAfter,
+	})
+	if err != nil {
+		s.logger.For(ctx).Error("Не удалось сохранить сертификат устройства", zap.Uint64("user_id", user.ID), zap.Error(err))
+		return nil, "", err
+	}
+
+	s.logger.For(ctx).Info("Устройству выдан сертификат", zap.Uint64("user_id", user.ID), zap.String("device", device), zap.String("serial", saved.Serial))
+	return saved, string(certPEM), nil
+}
+
+// Authenticate сопоставляет сертификат, предъявленный клиентом при установке соединения, с устройством.
+// Цепочку и срок действия сертификата уже проверил TLS; здесь проверяется, что сервер выдавал его и не отозвал.
+func (s *DeviceServiceImpl) Authenticate(ctx context.Context, cert *x509.Certificate) (*models.DeviceCertificateDTO, error) {
+	ctx, span := tracing.Start(ctx, "DeviceService.Authenticate")
+	defer span.End()
+
+	if s.ca == nil {
+		return nil, ErrDeviceCertificateUnknown
+	}
+	if device, ok := s.cached(certs.Serial(cert)); ok && device.Thumbprint == certs.Thumbprint(cert) {
+		return device, nil
+	}
+	device, err := s.repo.GetBySerial(ctx, certs.Serial(cert))
+	if errors.Is(err, repository.ErrNotFound) || err == nil && device.Thumbprint != certs.Thumbprint(cert) {
+		s.logger.For(ctx).Warn("Предъявлен неизвестный сертификат устройства", zap.String("serial", certs.Serial(cert)))
+		return nil, ErrDeviceCertificateUnknown
+	}
+	if err != nil {
+		s.logger.For(ctx).Error("Не удалось проверить сертификат устройства", zap.String("serial", certs.Serial(cert)), zap.Error(err))
+		return nil, err
+	}
+	if device.RevokedAt != nil {
+		s.logger.For(ctx).Warn("Предъявлен отозванный сертификат устройства", zap.Uint64("user_id", device.UserID), zap.String("serial", device.Serial))
+		return nil, ErrDeviceCertificateRevoked
+	}
+	s.store(*device)
+	return device, nil
+}
+
+// GetAllByUser возвращает сертификаты устройств пользователя, начиная с новых.
+func (s *DeviceServiceImpl) GetAllByUser(ctx context.Context, userID uint64) ([]models.DeviceCertificateDTO, error) {
+	ctx, span := tracing.Start(ctx, "DeviceService.GetAllByUser")
+	defer span.End()
+
+	devices, err := s.repo.GetAllByUser(ctx, userID)
+	if err != nil {
+		s.logger.For(ctx).Error("Не удалось получить сертификаты устройств пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	return devices, nil
+}
+
+// Revoke отзывает сертификат устройства любого пользователя.
+func (s *DeviceServiceImpl) Revoke(ctx context.Context, serial string) (*models.DeviceCertificateDTO, error) {
+	ctx, span := tracing.Start(ctx, "DeviceService.Revoke")
+	defer span.End()
+
+	device, err := s.repo.Revoke(ctx, serial)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrDeviceCertificateNotFound
+	}
+	if err != nil {
+		s.logger.For(ctx).Error("Не удалось отозвать сертификат устройства", zap.String("serial", serial), zap.Error(err))
+		return nil, err
+	}
+
+	s.forget(serial)
+	s.relay.Publish(events.KindDeviceRevoked, models.DeviceRevokedDTO{Serial: serial})
+	s.logger.For(ctx).Info("Сертификат устройства отозван", zap.Uint64("user_id", device.UserID), zap.String("device", device.Device), zap.String("serial", serial))
+	return device, nil
+}
+
+// RevokeByUser отзывает сертификат одного из устройств пользователя, например потерянного.
+func (s *DeviceServiceImpl) RevokeByUser(ctx context.Context, userID uint64, serial string) (*models.DeviceCertificateDTO, error) {
+	ctx, span := tracing.Start(ctx, "DeviceService.RevokeByUser")
+	defer span.End()
+
+	device, err := s.repo.GetBySerial(ctx, serial)
+	if errors.Is(err, repository.ErrNotFound) || err == nil && device.UserID != userID {
+		return nil, ErrDeviceCertificateNotFound
+	}
+	if err != nil {
+		s.logger.For(ctx).Error("Не удалось получить сертификат устройства", zap.String("serial", serial), zap.Error(err))
+		return nil, err
+	}
+	return s.Revoke(ctx, serial)
+}
+
+// RevocationList возвращает список отзыва сертификатов устройств (CRL) в PEM.
+// В список попадают отозванные сертификаты, срок действия которых ещё не истёк.
+func (s *DeviceServiceImpl) RevocationList(ctx context.Context) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "DeviceService.RevocationList")
+	defer span.End()
+
+	if s.ca == nil {
+		return nil, ErrDeviceCertsDisabled
+	}
+	now := time.Now()
+	revoked, err := s.repo.GetRevoked(ctx, now)
+	if err != nil {
+		s.logger.For(ctx).Error("Не удалось получить отозванные сертификаты устройств", zap.Error(err))
+		return nil, err
+	}
+	crl, err := s.ca.RevocationList(revoked, now)
+	if err != nil {
+		s.logger.For(ctx).Error("Не удалось подписать список отзыва сертификатов устройств", zap.Error(err))
+		return nil, err
+	}
+	return crl, nil
+}
+
+// cached возвращает сертификат из кэша, если срок его хранения не истёк.
+func (s *DeviceServiceImpl) cached(serial string) (*models.DeviceCertificateDTO, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.cache[serial]
+	if !ok || time.Now().After(entry.expires) {
+		delete(s.cache, serial)
+		return nil, false
+	}
+	device := entry.device
+	return &device, true
+}
+
+// store кэширует действующий сертификат устройства.
+func (s *DeviceServiceImpl) store(device models.DeviceCertificateDTO) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache[device.Serial] = cachedDevice{device: device, expires: time.Now().Add(deviceCacheTTL)}
+}
+
+// forget удаляет сертификат из кэша.
+func (s *DeviceServiceImpl) forget(serial string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, serial)
+}
+
+// clearCache очищает кэш. Вызывается после переподключения к другим экземплярам,
+// когда сообщения об отзыве могли быть потеряны.
+func (s *DeviceServiceImpl) clearCache() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.cache)
+}
+
+// onRevoked удаляет из кэша сертификат, отозванный на любом экземпляре сервера.
+func (s *DeviceServiceImpl) onRevoked(payload json.RawMessage) {
+	var msg models.DeviceRevokedDTO
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		s.logger.Log.Warn("Некорректное сообщение об отзыве сертификата устройства", zap.Error(err))
+		return
+	}
+	s.forget(msg.Serial)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shekshuev/gophkeeper/internal/certs"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/events"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+)
+
+func TestDeviceServiceImpl(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockDeviceCertificateRepository(ctrl)
+	ca, err := certs.LoadDeviceCA(&config.Config{DeviceCertValidity: time.Hour})
+	require.NoError(t, err)
+	relay := events.NewLocalRelay()
+	svc := NewDeviceServiceImpl(repo, ca, relay)
+	ctx := context.Background()
+	user := models.ReadAuthUserDataDTO{ID: 1, UserName: "john"}
+
+	key, err := certs.DeviceKey(filepath.Join(t.TempDir(), "device.key"))
+	require.NoError(t, err)
+	csr, err := certs.CertificateRequest(key, "laptop")
+	require.NoError(t, err)
+
+	var saved models.DeviceCertificateDTO
+	t.Run("Enroll", func(t *testing.T) {
+		repo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(
+			func(_ context.Context, dto models.CreateDeviceCertificateDTO) (*models.DeviceCertificateDTO, error) {
+				saved = models.DeviceCertificateDTO{ID: 1, UserID: dto.UserID, Device: dto.Device, Serial: dto.Serial, Thumbprint: dto.Thumbprint, ExpiresAt: dto.ExpiresAt}
+				return &saved, nil
+			})
+
+		device, certPEM, err := svc.Enroll(ctx, user, "laptop", string(csr))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), device.UserID)
+		block, _ := pem.Decode([]byte(certPEM))
+		require.NotNil(t, block)
+		assert.Equal(t, "CERTIFICATE", block.Type)
+	})
+
+	t.Run("Enroll_invalid_csr", func(t *testing.T) {
+		_, _, err := svc.Enroll(ctx, user, "laptop", "garbage")
+		assert.ErrorIs(t, err, ErrInvalidCSR)
+	})
+
+	t.Run("Authenticate", func(t *testing.T) {
+		repo.EXPECT().Create(ctx, gomock.Any()).Return(&models.DeviceCertificateDTO{}, nil)
+		_, certPEM, err := svc.Enroll(ctx, user, "phone", string(csr))
+		require.NoError(t, err)
+		block, _ := pem.Decode([]byte(certPEM))
+		cert, err := x509.ParseCertificate(block.Bytes)
+		require.NoError(t, err)
+		device := models.DeviceCertificateDTO{UserID: 1, Serial: certs.Serial(cert), Thumbprint: certs.Thumbprint(cert)}
+
+		revoked := device
+		now := time.Now()
+		revoked.RevokedAt = &now
+		repo.EXPECT().GetBySerial(ctx, device.Serial).Return(&revoked, nil)
+		_, err = svc.Authenticate(ctx, cert)
+		assert.ErrorIs(t, err, ErrDeviceCertificateRevoked)
+
+		repo.EXPECT().GetBySerial(ctx, device.Serial).Return(nil, repository.ErrNotFound)
+		_, err = svc.Authenticate(ctx, cert)
+		assert.ErrorIs(t, err, ErrDeviceCertificateUnknown)
+
+		// Действующий сертификат кэшируется: повторная проверка не обращается к репозиторию.
+		repo.EXPECT().GetBySerial(ctx, device.Serial).Return(&device, nil)
+		for range 2 {
+			found, err := svc.Authenticate(ctx, cert)
+			assert.NoError(t, err)
+			assert.Equal(t, &device, found)
+		}
+
+		// Отзыв на другом экземпляре сервера сбрасывает кэш.
+		relay.Publish(events.KindDeviceRevoked, models.DeviceRevokedDTO{Serial: device.Serial})
+		repo.EXPECT().GetBySerial(ctx, device.Serial).Return(&revoked, nil)
+		_, err = svc.Authenticate(ctx, cert)
+		assert.ErrorIs(t, err, ErrDeviceCertificateRevoked)
+	})
+
+	t.Run("Revoke_drops_cache", func(t *testing.T) {
+		revoked := make(chan string, 1)
+		unsubscribe := relay.Subscribe(events.KindDeviceRevoked, func(payload json.RawMessage) {
+			var msg models.DeviceRevokedDTO
+			assert.NoError(t, json.Unmarshal(payload, &msg))
+			revoked <- msg.Serial
+		})
+		defer unsubscribe()
+		svc.store(models.DeviceCertificateDTO{UserID: 1, Serial: "0c"})
+		now := time.Now()
+		repo.EXPECT().Revoke(ctx, "0c").Return(&models.DeviceCertificateDTO{UserID: 1, Serial: "0c", RevokedAt: &now}, nil)
+
+		_, err := svc.Revoke(ctx, "0c")
+		assert.NoError(t, err)
+		assert.Equal(t, "0c", <-revoked)
+		_, ok := svc.cached("0c")
+		assert.False(t, ok)
+	})
+
+	t.Run("RevokeByUser_other_user", func(t *testing.T) {
+		repo.EXPECT().GetBySerial(ctx, "0a1b").Return(&models.DeviceCertificateDTO{UserID: 2, Serial: "0a1b"}, nil)
+
+		_, err := svc.RevokeByUser(ctx, 1, "0a1b")
+		assert.ErrorIs(t, err, ErrDeviceCertificateNotFound)
+	})
+
+	t.Run("RevokeByUser", func(t *testing.T) {
+		now := time.Now()
+		repo.EXPECT().GetBySerial(ctx, "0a1b").Return(&models.DeviceCertificateDTO{UserID: 1, Serial: "0a1b"}, nil)
+		repo.EXPECT().Revoke(ctx, "0a1b").Return(&models.DeviceCertificateDTO{UserID: 1, Serial: "0a1b", RevokedAt: &now}, nil)
+
+		device, err := svc.RevokeByUser(ctx, 1, "0a1b")
+		assert.NoError(t, err)
+		assert.NotNil(t, device.RevokedAt)
+	})
+
+	t.Run("Revoke_not_found", func(t *testing.T) {
+		repo.EXPECT().Revoke(ctx, "ffff").Return(nil, repository.ErrNotFound)
+
+		_, err := svc.Revoke(ctx, "ffff")
+		assert.ErrorIs(t, err, ErrDeviceCertificateNotFound)
+	})
+
+	t.Run("RevocationList", func(t *testing.T) {
+		now := time.Now()
+		repo.EXPECT().GetRevoked(ctx, gomock.Any()).Return([]models.DeviceCertificateDTO{{Serial: "0a1b", RevokedAt: &now}}, nil)
+
+		crl, err := svc.RevocationList(ctx)
+		require.NoError(t, err)
+		block, _ := pem.Decode(crl)
+		require.NotNil(t, block)
+		assert.Equal(t, "X509 CRL", block.Type)
+	})
+}
+
+func TestDeviceServiceImpl_Disabled(t *testing.T) {
+	svc := NewDeviceServiceImpl(nil, nil, events.NewLocalRelay())
+	ctx := context.Background()
+
+	_, _, err := svc.Enroll(ctx, models.ReadAuthUserDataDTO{ID: 1}, "laptop", "csr")
+	assert.ErrorIs(t, err, ErrDeviceCertsDisabled)
+	_, err = svc.RevocationList(ctx)
+	assert.ErrorIs(t, err, ErrDeviceCertsDisabled)
+	_, err = svc.Authenticate(ctx, nil)
+	assert.ErrorIs(t, err, ErrDeviceCertificateUnknown)
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// FolderServiceImpl реализует FolderService.
+// Отвечает за бизнес-логику иерархии папок: проверку владельца и защиту от циклов.
+type FolderServiceImpl struct {
+	repo   repository.FolderRepository // Репозиторий папок
+	tx     repository.TxManager        // Транзакции проверки цикла и переноса папки
+	logger *logger.Logger              // Логгер
+}
+
+// NewFolderServiceImpl создаёт новый экземпляр сервиса папок.
+func NewFolderServiceImpl(repo repository.FolderRepository, tx repository.TxManager) *FolderServiceImpl {
+	return &FolderServiceImpl{
+		repo:   repo,
+		tx:     tx,
+		logger: logger.NewLogger(),
+	}
+}
+
+// Create создаёт новую папку.
+// Если указана родительская папка, проверяет, что она существует и принадлежит пользователю.
+func (s *FolderServiceImpl) Create(ctx context.Context, dto models.CreateFolderDTO) (*models.ReadFolderDTO, error) {
+	ctx, span := tracing.Start(ctx, "FolderService.Create")
+	defer span.End()
+
+	if dto.ParentID != nil {
+		if err := s.checkOwner(ctx, dto.UserID, *dto.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	folder, err := s.repo.Create(ctx, dto)
+	if err != nil {
+		s.logger.For(ctx).Error("Не удалось создать папку", zap.Uint64("user_id", dto.UserID), zap.String("name", dto.Name), zap.Error(err))
+		return nil, err
+	}
+	s.logger.For(ctx).Info("Папка успешно создана", zap.Uint64("folder_id", folder.ID), zap.Uint64("user_id", dto.UserID))
+	return folder, nil
+}
+
+// GetAllByUser возвращает все папки пользователя.
+func (s *FolderServiceImpl) GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadFolderDTO, error) {
+	ctx, span := tracing.Start(ctx, "FolderService.GetAllByUser")
+	defer span.End()
+
+	folders, err := s.repo.GetAllByUser(ctx, userID)
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка при получении папок пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	s.logger.For(ctx).Info("Папки пользователя успешно получены", zap.Uint64("user_id", userID), zap.Int("count", len(folders)))
+	return folders, nil
+}
+
+// Rename изменяет название папки пользователя.
+func (s *FolderServiceImpl) Rename(ctx context.Context, userID, id uint64, dto models.RenameFolderDTO) (*models.ReadFolderDTO, error) {
+	ctx, span := tracing.Start(ctx, "FolderService.Rename")
+	defer span.End()
+
+	folder, err := s.repo.Rename(ctx, userID, id, dto.Name)
+	if errors.Is(err, repository.ErrNotFound) {
+		s.logger.For(ctx).Warn("Папка для переименования не найдена", zap.Uint64("folder_id", id), zap.Uint64("user_id", userID))
+		return nil, ErrFolderNotFound
+	}
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка при переименовании папки", zap.Uint64("folder_id", id), zap.Error(err))
+		return nil, err
+	}
+	s.logger.For(ctx).Info("Папка успешно переименована", zap.Uint64("folder_id", id))
+	return folder, nil
+}
+
+// Move переносит папку в другую родительскую папку.
+// Перед переносом проходит по цепочке предков новой родительской папки
+// и отклоняет перенос, если среди них встречается сама перемещаемая папка.
+// Проверка и перенос выполняются в одной транзакции, поэтому два одновременных переноса
+// не могут вместе образовать цикл.
+func (s *FolderServiceImpl) Move(ctx context.Context, userID, id uint64, dto models.MoveFolderDTO) (*models.ReadFolderDTO, error) {
+	ctx, span := tracing.Start(ctx, "FolderService.Move")
+	defer span.End()
+
+	var folder *models.ReadFolderDTO
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if dto.ParentID != nil {
+			if err := s.checkCycle(ctx, userID, id, *dto.ParentID); err != nil {
+				return err
+			}
+		}
+		var err error
+		folder, err = s.repo.Move(ctx, userID, id, dto.ParentID)
+		return err
+	})
+	if errors.Is(err, ErrFolderNotFound) || errors.Is(err, ErrFolderCycle) {
+		return nil, err
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		s.logger.For(ctx).Warn("Папка для перемещения не найдена", zap.Uint64("folder_id", id), zap.Uint64("user_id", userID))
+		return nil, ErrFolderNotFound
+	}
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка при перемещении папки", zap.Uint64("folder_id", id), zap.Error(err))
+		return nil, err
+	}
+	s.logger.For(ctx).Info("Папка успешно перемещена", zap.Uint64("folder_id", id))
+	return folder, nil
+}
+
+// checkCycle проверяет, что новая родительская папка parentID существует и не является
+// самой папкой id или её потомком.
+func (s *FolderServiceImpl) checkCycle(ctx context.Context, userID, id, parentID uint64) error {
+	folders, err := s.repo.GetAllByUser(ctx, userID)
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка при получении папок пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+		return err
+	}
+	parents := make(map[uint64]*uint64, len(folders))
+	for _, f := range folders {
+		parents[f.ID] = f.ParentID
+	}
+	if _, ok := parents[parentID]; !ok {
+		s.logger.For(ctx).Warn("Родительская папка не найдена", zap.Uint64("folder_id", parentID), zap.Uint64("user_id", userID))
+		return ErrFolderNotFound
+	}
+	for cur, steps := &parentID, 0; cur != nil && steps <= len(folders); cur, steps = parents[*cur], steps+1 {
+		if *cur == id {
+			s.logger.For(ctx).Warn("Попытка переместить папку внутрь самой себя", zap.Uint64("folder_id", id), zap.Uint64("parent_id", parentID))
+			return ErrFolderCycle
+		}
+	}
+	return nil
+}
+
+// checkOwner проверяет, что папка существует и принадлежит пользователю.
+func (s *FolderServiceImpl) checkOwner(ctx context.Context, userID, folderID uint64) error {
+	_, err := s.repo.GetByID(ctx, userID, folderID)
+	if errors.Is(err, repository.ErrNotFound) {
+		s.logger.For(ctx).Warn("Папка не найдена", zap.Uint64("folder_id", folderID), zap.Uint64("user_id", userID))
+		return ErrFolderNotFound
+	}
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка при проверке папки", zap.Uint64("folder_id", folderID), zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func uptr(v uint64) *uint64 {
+	return &v
+}
+
+func TestFolderServiceImpl_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockFolderRepository(ctrl)
+	service := NewFolderServiceImpl(mockRepo, passthroughTx{})
+	ctx := context.Background()
+
+	testCases := []struct {
+		name     string
+		dto      models.CreateFolderDTO
+		mockSet  func()
+		expected error
+	}{
+		{
+			name: "Root_folder",
+			dto:  models.CreateFolderDTO{UserID: 1, Name: "Work"},
+			mockSet: func() {
+				mockRepo.EXPECT().Create(ctx, models.CreateFolderDTO{UserID: 1, Name: "Work"}).
+					Return(&models.ReadFolderDTO{ID: 10, UserID: 1, Name: "Work"}, nil)
+			},
+		},
+		{
+			name: "Nested_folder",
+			dto:  models.CreateFolderDTO{UserID: 1, ParentID: uptr(10), Name: "Servers"},
+			mockSet: func() {
+				mockRepo.EXPECT().GetByID(ctx, uint64(1), uint64(10)).Return(&models.ReadFolderDTO{ID: 10, UserID: 1}, nil)
+				mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(&models.ReadFolderDTO{ID: 11, UserID: 1, ParentID: uptr(10)}, nil)
+			},
+		},
+		{
+			name: "Parent_of_another_user",
+			dto:  models.CreateFolderDTO{UserID: 1, ParentID: uptr(99), Name: "Servers"},
+			mockSet: func() {
+				mockRepo.EXPECT().GetByID(ctx, uint64(1), uint64(99)).Return(nil, repository.ErrNotFound)
+			},
+			expected: ErrFolderNotFound,
+		},
+		{
+			name: "Duplicate_name",
+			dto:  models.CreateFolderDTO{UserID: 1, Name: "Work"},
+			mockSet: func() {
+				mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil, repository.ErrFolderExists)
+			},
+			expected: ErrFolderExists,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockSet()
+			folder, err := service.Create(ctx, tc.dto)
+			if tc.expected != nil {
+				assert.ErrorIs(t, err, tc.expected)
+				assert.Nil(t, folder)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, folder)
+			}
+		})
+	}
+}
+
+func TestFolderServiceImpl_GetAllByUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockFolderRepository(ctrl)
+	service := NewFolderServiceImpl(mockRepo, passthroughTx{})
+
+	mockRepo.EXPECT().GetAllByUser(gomock.Any(), uint64(1)).Return([]models.ReadFolderDTO{{ID: 1}, {ID: 2}}, nil)
+	folders, err := service.GetAllByUser(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, folders, 2)
+
+	mockRepo.EXPECT().GetAllByUser(gomock.Any(), uint64(1)).Return(nil, assert.AnError)
+	folders, err = service.GetAllByUser(context.Background(), 1)
+	assert.Error(t, err)
+	assert.Nil(t, folders)
+}
+
+func TestFolderServiceImpl_Rename(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockFolderRepository(ctrl)
+	service := NewFolderServiceImpl(mockRepo, passthroughTx{})
+
+	mockRepo.EXPECT().Rename(gomock.Any(), uint64(1), uint64(5), "New").Return(&models.ReadFolderDTO{ID: 5, Name: "New"}, nil)
+	folder, err := service.Rename(context.Background(), 1, 5, models.RenameFolderDTO{Name: "New"})
+	assert.NoError(t, err)
+	assert.Equal(t, "New", folder.Name)
+
+	mockRepo.EXPECT().Rename(gomock.Any(), uint64(1), uint64(6), "New").Return(nil, repository.ErrNotFound)
+	_, err = service.Rename(context.Background(), 1, 6, models.RenameFolderDTO{Name: "New"})
+	assert.ErrorIs(t, err, ErrFolderNotFound)
+}
+
+func TestFolderServiceImpl_Move(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockFolderRepository(ctrl)
+	service := NewFolderServiceImpl(mockRepo, passthroughTx{})
+	ctx := context.Background()
+
+	// Дерево: 1 → 2 → 3, 4 — отдельная корневая папка.
+	tree := []models.ReadFolderDTO{
+		{ID: 1},
+		{ID: 2, ParentID: uptr(1)},
+		{ID: 3, ParentID: uptr(2)},
+		{ID: 4},
+	}
+
+	testCases := []struct {
+		name     string
+		id       uint64
+		parentID *uint64
+		mockSet  func()
+		expected error
+	}{
+		{
+			name:     "Move_into_sibling",
+			id:       2,
+			parentID: uptr(4),
+			mockSet: func() {
+				mockRepo.EXPECT().GetAllByUser(ctx, uint64(1)).Return(tree, nil)
+				mockRepo.EXPECT().Move(ctx, uint64(1), uint64(2), uptr(4)).Return(&models.ReadFolderDTO{ID: 2, ParentID: uptr(4)}, nil)
+			},
+		},
+		{
+			name:     "Move_to_root",
+			id:       3,
+			parentID: nil,
+			mockSet: func() {
+				mockRepo.EXPECT().Move(ctx, uint64(1), uint64(3), nil).Return(&models.ReadFolderDTO{ID: 3}, nil)
+			},
+		},
+		{
+			name:     "Move_into_itself",
+			id:       2,
+			parentID: uptr(2),
+			mockSet: func() {
+				mockRepo.EXPECT().GetAllByUser(ctx, uint64(1)).Return(tree, nil)
+			},
+			expected: ErrFolderCycle,
+		},
+		{
+			name:     "Move_into_descendant",
+			id:       1,
+			parentID: uptr(3),
+			mockSet: func() {
+				mockRepo.EXPECT().GetAllByUser(ctx, uint64(1)).Return(tree, nil)
+			},
+			expected: ErrFolderCycle,
+		},
+		{
+			name:     "Unknown_parent",
+			id:       1,
+			parentID: uptr(100),
+			mockSet: func() {
+				mockRepo.EXPECT().GetAllByUser(ctx, uint64(1)).Return(tree, nil)
+			},
+			expected: ErrFolderNotFound,
+		},
+		{
+			name:     "Unknown_folder",
+			id:       100,
+			parentID: nil,
+			mockSet: func() {
+				mockRepo.EXPECT().Move(ctx, uint64(1), uint64(100), nil).Return(nil, repository.ErrNotFound)
+			},
+			expected: ErrFolderNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockSet()
+			folder, err := service.Move(ctx, 1, tc.id, models.MoveFolderDTO{ParentID: tc.parentID})
+			if tc.expected != nil {
+				assert.ErrorIs(t, err, tc.expected)
+				assert.Nil(t, folder)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.id, folder.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/certs"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// HealthServiceImpl реализует HealthService: проверяет зависимости сервера.
+type HealthServiceImpl struct {
+	repo      repository.HealthRepository // Проверки базы данных
+	tlsConfig *tls.Config                 // Конфигурация TLS сервера с его сертификатом
+	ca        *certs.DeviceCA             // Центр сертификации устройств; nil, если сертификаты устройств выключены
+	cfg       *config.Config              // Таймаут проверки, число попыток подключения при старте и секреты токенов
+	now       func() time.Time            // Текущее время; подменяется в тестах
+	logger    *logger.Logger              // Логгер
+}
+
+// NewHealthServiceImpl создаёт сервис проверки готовности. tlsConfig и ca — сертификат сервера
+// и центр сертификации устройств, состояние которых входит в проверку ключей.
+func NewHealthServiceImpl(repo repository.HealthRepository, tlsConfig *tls.Config, ca *certs.DeviceCA, cfg *config.Config) *HealthServiceImpl {
+	return &HealthServiceImpl{
+		repo:      repo,
+		tlsConfig: tlsConfig,
+		ca:        ca,
+		cfg:       cfg,
+		now:       time.Now,
+		logger:    logger.NewLogger(),
+	}
+}
+
+// Check проверяет доступность базы данных, версию миграций и ключи сервера. Все проверки вместе
+// ограничены cfg.ReadinessTimeout. Сервер готов, если база отвечает, последняя миграция завершена,
+// а ключи загружены (см. checkKeys).
+func (s *HealthServiceImpl) Check(ctx context.Context) *models.ReadinessDTO {
+	ctx, span := tracing.Start(ctx, "HealthService.Check")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.ReadinessTimeout)
+	defer cancel()
+
+	result := &models.ReadinessDTO{Status: models.HealthStatusOK}
+
+	start := time.Now()
+	err := s.repo.Ping(ctx)
+	result.Database = models.HealthCheckDTO{Status: models.HealthStatusOK, LatencyMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Database.Status = models.HealthStatusUnavailable
+		result.Database.Error = err.Error()
+	}
+
+	version, dirty, err := s.repo.MigrationVersion(ctx)
+	result.Migrations = models.MigrationStatusDTO{Status: models.HealthStatusOK, Version: version, Dirty: dirty}
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		result.Migrations.Status = models.HealthStatusUnavailable
+		result.Migrations.Error = "migrations have not been applied"
+	case err != nil:
+		result.Migrations.Status = models.HealthStatusUnavailable
+		result.Migrations.Error = err.Error()
+	case dirty:
+		result.Migrations.Status = models.HealthStatusUnavailable
+		result.Migrations.Error = fmt.Sprintf("migration %d is dirty", version)
+	}
+
+	result.Keys = s.checkKeys()
+
+	if result.Database.Status != models.HealthStatusOK || result.Migrations.Status != models.HealthStatusOK ||
+		result.Keys.Status != models.HealthStatusOK {
+		result.Status = models.HealthStatusUnavailable
+		s.logger.For(ctx).Warn("Сервер не готов принимать запросы",
+			zap.String("database", result.Database.Error), zap.String("migrations", result.Migrations.Error),
+			zap.String("keys", result.Keys.Error))
+	}
+	return result
+}
+
+// checkKeys проверяет, что заданы секреты подписи токенов, загружен действующий сертификат TLS,
+// а при включённых сертификатах устройств — действующий сертификат их центра сертификации.
+func (s *HealthServiceImpl) checkKeys() models.KeyStatusDTO {
+	now := s.now()
+	var problems []string
+	if s.cfg.AccessTokenSecret == "" {
+		problems = append(problems, "access token secret is not set")
+	}
+	if s.cfg.RefreshTokenSecret == "" {
+		problems = append(problems, "refresh token secret is not set")
+	}
+	if err := checkCertificate(s.tlsLeaf(), now); err != nil {
+		problems = append(problems, "TLS certificate: "+err.Error())
+	}
+	if s.cfg.DeviceCertMode != config.DeviceCertOff {
+		var caCert *x509.Certificate
+		if s.ca != nil {
+			caCert = s.ca.Certificate()
+		}
+		if err := checkCertificate(caCert, now); err != nil {
+			problems = append(problems, "device CA: "+err.Error())
+		}
+	}
+
+	if len(problems) > 0 {
+		return models.KeyStatusDTO{Status: models.HealthStatusUnavailable, Error: strings.Join(problems, "; ")}
+	}
+	return models.KeyStatusDTO{Status: models.HealthStatusOK}
+}
+
+// tlsLeaf возвращает текущий сертификат TLS сервера: загруженный при запуске или
+// последний перечитанный из файлов (tls.Config.GetCertificate). Если сертификата нет, возвращает nil.
+func (s *HealthServiceImpl) tlsLeaf() *x509.Certificate {
+	if s.tlsConfig == nil {
+		return nil
+	}
+	if s.tlsConfig.GetCertificate != nil {
+		cert, err := s.tlsConfig.GetCertificate(&tls.ClientHelloInfo{})
+		if err != nil || cert == nil {
+			return nil
+		}
+		return cert.Leaf
+	}
+	if len(s.tlsConfig.Certificates) == 0 {
+		return nil
+	}
+	return s.tlsConfig.Certificates[0].Leaf
+}
+
+// checkCertificate проверяет, что сертификат загружен и действует в момент now.
+func checkCertificate(cert *x509.Certificate, now time.Time) error {
+	switch {
+	case cert == nil:
+		return errors.New("not loaded")
+	case now.Before(cert.NotBefore):
+		return fmt.Errorf("not valid before %s", cert.NotBefore.UTC().Format(time.RFC3339))
+	case now.After(cert.NotAfter):
+		return fmt.Errorf("expired at %s", cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// WaitForDatabase ждёт, пока база данных начнёт принимать соединения: делает до cfg.DatabaseConnectAttempts
+// попыток с паузой cfg.DatabaseConnectInterval. Возвращает ошибку последней попытки, если база так и не ответила.
+func (s *HealthServiceImpl) WaitForDatabase(ctx context.Context) error {
+	attempts := max(s.cfg.DatabaseConnectAttempts, 1)
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, s.cfg.ReadinessTimeout)
+		err = s.repo.Ping(pingCtx)
+		cancel()
+		if err == nil {
+			s.logger.For(ctx).Info("База данных доступна", zap.Int("attempt", attempt))
+			return nil
+		}
+		s.logger.For(ctx).Warn("База данных недоступна", zap.Int("attempt", attempt), zap.Error(err))
+		if attempt == attempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.cfg.DatabaseConnectInterval):
+		}
+	}
+	return fmt.Errorf("database is unavailable: %w", err)
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/shekshuev/gophkeeper/internal/certs"
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+)
+
+func TestHealthServiceImpl_Check(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockHealthRepository(ctrl)
+	tlsConfig, _, err := certs.ServerConfig(&config.Config{TLSHosts: []string{"localhost"}})
+	assert.NoError(t, err)
+	ca, err := certs.LoadDeviceCA(&config.Config{DeviceCertValidity: time.Hour})
+	assert.NoError(t, err)
+	cfg := &config.Config{
+		ReadinessTimeout:   time.Second,
+		AccessTokenSecret:  "access",
+		RefreshTokenSecret: "refresh",
+		DeviceCertMode:     config.DeviceCertOptional,
+	}
+	svc := NewHealthServiceImpl(repo, tlsConfig, ca, cfg)
+	ctx := context.Background()
+
+	t.Run("Ready", func(t *testing.T) {
+		repo.EXPECT().Ping(gomock.Any()).Return(nil)
+		repo.EXPECT().MigrationVersion(gomock.Any()).Return(uint(8), false, nil)
+
+		result := svc.Check(ctx)
+		assert.Equal(t, models.HealthStatusOK, result.Status)
+		assert.Equal(t, models.MigrationStatusDTO{Status: models.HealthStatusOK, Version: 8}, result.Migrations)
+		assert.Equal(t, models.KeyStatusDTO{Status: models.HealthStatusOK}, result.Keys)
+	})
+
+	t.Run("Database_unavailable", func(t *testing.T) {
+		repo.EXPECT().Ping(gomock.Any()).Return(assert.AnError)
+		repo.EXPECT().MigrationVersion(gomock.Any()).Return(uint(0), false, assert.AnError)
+
+		result := svc.Check(ctx)
+		assert.Equal(t, models.HealthStatusUnavailable, result.Status)
+		assert.Equal(t, models.HealthStatusUnavailable, result.Database.Status)
+		assert.Equal(t, assert.AnError.Error(), result.Database.Error)
+	})
+
+	t.Run("Dirty_migration", func(t *testing.T) {
+		repo.EXPECT().Ping(gomock.Any()).Return(nil)
+		repo.EXPECT().MigrationVersion(gomock.Any()).Return(uint(8), true, nil)
+
+		result := svc.Check(ctx)
+		assert.Equal(t, models.HealthStatusUnavailable, result.Status)
+		assert.Equal(t, "migration 8 is dirty", result.Migrations.Error)
+	})
+
+	t.Run("Migrations_not_applied", func(t *testing.T) {
+		repo.EXPECT().Ping(gomock.Any()).Return(nil)
+		repo.EXPECT().MigrationVersion(gomock.Any()).Return(uint(0), false, repository.ErrNotFound)
+
+		result := svc.Check(ctx)
+		assert.Equal(t, models.HealthStatusUnavailable, result.Migrations.Status)
+	})
+
+	t.Run("Token_secret_missing", func(t *testing.T) {
+		repo.EXPECT().Ping(gomock.Any()).Return(nil)
+		repo.EXPECT().MigrationVersion(gomock.Any()).Return(uint(8), false, nil)
+		svc := NewHealthServiceImpl(repo, tlsConfig, ca, &config.Config{ReadinessTimeout: time.Second, AccessTokenSecret: "access"})
+
+		result := svc.Check(ctx)
+		assert.Equal(t, models.HealthStatusUnavailable, result.Status)
+		assert.Equal(t, models.KeyStatusDTO{Status: models.HealthStatusUnavailable, Error: "refresh token secret is not set"}, result.Keys)
+	})
+
+	t.Run("Device_CA_not_loaded", func(t *testing.T) {
+		repo.EXPECT().Ping(gomock.Any()).Return(nil)
+		repo.EXPECT().MigrationVersion(gomock.Any()).Return(uint(8), false, nil)
+		svc := NewHealthServiceImpl(repo, tlsConfig, nil, cfg)
+
+		result := svc.Check(ctx)
+		assert.Equal(t, models.HealthStatusUnavailable, result.Status)
+		assert.Equal(t, "device CA: not loaded", result.Keys.Error)
+	})
+
+	t.Run("Device_CA_disabled", func(t *testing.T) {
+		repo.EXPECT().Ping(gomock.Any()).Return(nil)
+		repo.EXPECT().MigrationVersion(gomock.Any()).Return(uint(8), false, nil)
+		svc := NewHealthServiceImpl(repo, tlsConfig, nil, &config.Config{
+			ReadinessTimeout:   time.Second,
+			AccessTokenSecret:  "access",
+			RefreshTokenSecret: "refresh",
+			DeviceCertMode:     config.DeviceCertOff,
+		})
+
+		result := svc.Check(ctx)
+		assert.Equal(t, models.HealthStatusOK, result.Status)
+	})
+
+	t.Run("TLS_certificate_expired", func(t *testing.T) {
+		repo.EXPECT().Ping(gomock.Any()).Return(nil)
+		repo.EXPECT().MigrationVersion(gomock.Any()).Return(uint(8), false, nil)
+		svc := NewHealthServiceImpl(repo, tlsConfig, ca, cfg)
+		svc.now = func() time.Time { return tlsConfig.Certificates[0].Leaf.NotAfter.Add(time.Hour) }
+
+		result := svc.Check(ctx)
+		assert.Equal(t, models.HealthStatusUnavailable, result.Status)
+		assert.Contains(t, result.Keys.Error, "TLS certificate: expired at")
+	})
+}
+
+func TestHealthServiceImpl_WaitForDatabase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockHealthRepository(ctrl)
+	svc := NewHealthServiceImpl(repo, nil, nil, &config.Config{
+		ReadinessTimeout:        time.Second,
+		DatabaseConnectAttempts: 3,
+		DatabaseConnectInterval: time.Millisecond,
+	})
+	ctx := context.Background()
+
+	t.Run("Becomes_available", func(t *testing.T) {
+		gomock.InOrder(
+			repo.EXPECT().Ping(gomock.Any()).Return(assert.AnError),
+			repo.EXPECT().Ping(gomock.Any()).Return(nil),
+		)
+
+		assert.NoError(t, svc.WaitForDatabase(ctx))
+	})
+
+	t.Run("Never_available", func(t *testing.T) {
+		repo.EXPECT().Ping(gomock.Any()).Return(assert.AnError).Times(3)
+
+		assert.ErrorIs(t, svc.WaitForDatabase(ctx), assert.AnError)
+	})
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// idempotencyPurgeInterval — как часто сервер удаляет устаревшие ключи идемпотентности.
+const idempotencyPurgeInterval = time.Hour
+
+// IdempotencyServiceImpl реализует IdempotencyService.
+// Ключ хранится cfg.IdempotencyKeyTTL; по истечении срока запрос с тем же ключом выполняется заново.
+type IdempotencyServiceImpl struct {
+	repo   repository.IdempotencyRepository // Репозиторий ключей идемпотентности
+	cfg    *config.Config                   // Конфигурация (срок хранения ключей)
+	now    func() time.Time                 // Источник текущего времени
+	logger *logger.Logger                   // Логгер
+}
+
+// NewIdempotencyServiceImpl создаёт новый экземпляр сервиса ключей идемпотентности.
+func NewIdempotencyServiceImpl(repo repository.IdempotencyRepository, cfg *config.Config) *IdempotencyServiceImpl {
+	return &IdempotencyServiceImpl{
+		repo:   repo,
+		cfg:    cfg,
+		now:    time.Now,
+		logger: logger.NewLogger(),
+	}
+}
+
+// Begin закрепляет ключ за запросом пользователя.
+// Повтор запроса с тем же ключом и тем же хешем получает сохранённый ответ; другой запрос с тем же
+// ключом отклоняется с ErrIdempotencyKeyReused. Пока исходный запрос не завершён, повторы получают
+// ErrIdempotencyKeyInProgress, но не дольше cfg.IdempotencyLease: затем ключ закрепляется за новым запросом.
+func (s *IdempotencyServiceImpl) Begin(ctx context.Context, userID uint64, key, requestHash string) (*models.IdempotencyRecordDTO, error) {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Begin")
+	defer span.End()
+
+	now := s.now()
+	rec, err := s.repo.Reserve(ctx, userID, key, requestHash, now.Add(-s.cfg.IdempotencyKeyTTL), now.Add(-s.cfg.IdempotencyLease))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	if err != nil {
+		s.logger.For(ctx).Error("Не удалось закрепить ключ идемпотентности", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	if rec == nil {
+		return nil, nil
+	}
+	if rec.RequestHash != requestHash {
+		s.logger.For(ctx).Warn("Ключ идемпотентности использован для другого запроса", zap.Uint64("user_id", userID), zap.String("key", key))
+		return nil, ErrIdempotencyKeyReused
+	}
+	if rec.StatusCode == 0 {
+		s.logger.For(ctx).Warn("Запрос с ключом идемпотентности ещё выполняется", zap.Uint64("user_id", userID), zap.String("key", key))
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	s.logger.For(ctx).Info("Повтор запроса по ключу идемпотентности", zap.Uint64("user_id", userID), zap.String("key", key))
+	return rec, nil
+}
+
+// Complete сохраняет ответ на запрос, за которым закреплён ключ.
+func (s *IdempotencyServiceImpl) Complete(ctx context.Context, userID uint64, key string, statusCode int, response []byte) error {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Complete")
+	defer span.End()
+
+	return s.repo.Complete(ctx, userID, key, statusCode, response)
+}
+
+// Release освобождает ключ, чтобы запрос с ним можно было выполнить заново.
+func (s *IdempotencyServiceImpl) Release(ctx context.Context, userID uint64, key string) error {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Release")
+	defer span.End()
+
+	return s.repo.Release(ctx, userID, key)
+}
+
+// Run удаляет устаревшие ключи сразу после запуска и затем каждый час, пока не будет отменён контекст.
+// Если срок хранения ключей не задан, ключи не используются (см. Handler.idempotent) и Run сразу завершается.
+func (s *IdempotencyServiceImpl) Run(ctx context.Context) {
+	if s.cfg.IdempotencyKeyTTL <= 0 {
+		s.logger.For(ctx).Info("Ключи идемпотентности отключены")
+		return
+	}
+
+	ticker := time.NewTicker(idempotencyPurgeInterval)
+	defer ticker.Stop()
+	for {
+		_, _ = s.Purge(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge удаляет ключи, созданные раньше, чем cfg.IdempotencyKeyTTL назад.
+// Возвращает количество удалённых ключей.
+func (s *IdempotencyServiceImpl) Purge(ctx context.Context) (int64, error) {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Purge")
+	defer span.End()
+
+	count, err := s.repo.DeleteExpired(ctx, s.now().Add(-s.cfg.IdempotencyKeyTTL))
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка при удалении устаревших ключей идемпотентности", zap.Error(err))
+		return 0, err
+	}
+	if count > 0 {
+		s.logger.For(ctx).Info("Удалены устаревшие ключи идемпотентности", zap.Int64("count", count))
+	}
+	return count, nil
+}
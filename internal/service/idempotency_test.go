@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+)
+
+func TestIdempotencyServiceImpl_Begin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIdempotencyRepository(ctrl)
+	now := time.Date(2025, 7, 16, 12, 0, 0, 0, time.UTC)
+	cfg := &config.Config{IdempotencyKeyTTL: time.Hour, IdempotencyLease: time.Minute}
+	service := &IdempotencyServiceImpl{repo: mockRepo, cfg: cfg, now: func() time.Time { return now }, logger: logger.NewLogger()}
+
+	tcs := []struct {
+		name    string
+		rec     *models.IdempotencyRecordDTO
+		repoErr error
+		wantErr error
+	}{
+		{name: "Reserved"},
+		{name: "Replay", rec: &models.IdempotencyRecordDTO{RequestHash: "hash", StatusCode: 201}},
+		{name: "Reused", rec: &models.IdempotencyRecordDTO{RequestHash: "other", StatusCode: 201}, wantErr: ErrIdempotencyKeyReused},
+		{name: "In_progress", rec: &models.IdempotencyRecordDTO{RequestHash: "hash"}, wantErr: ErrIdempotencyKeyInProgress},
+		{name: "Released_meanwhile", repoErr: repository.ErrNotFound, wantErr: ErrIdempotencyKeyInProgress},
+		{name: "Error", repoErr: errors.New("db error")},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo.EXPECT().
+				Reserve(gomock.Any(), uint64(42), "key-1", "hash", now.Add(-time.Hour), now.Add(-time.Minute)).
+				Return(tc.rec, tc.repoErr)
+
+			rec, err := service.Begin(context.Background(), 42, "key-1", "hash")
+			switch {
+			case tc.wantErr != nil:
+				assert.ErrorIs(t, err, tc.wantErr)
+			case tc.repoErr != nil:
+				assert.Error(t, err)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tc.rec, rec)
+			}
+		})
+	}
+}
+
+func TestIdempotencyServiceImpl_Purge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIdempotencyRepository(ctrl)
+	now := time.Date(2025, 7, 16, 12, 0, 0, 0, time.UTC)
+	cfg := &config.Config{IdempotencyKeyTTL: 24 * time.Hour}
+	service := &IdempotencyServiceImpl{repo: mockRepo, cfg: cfg, now: func() time.Time { return now }, logger: logger.NewLogger()}
+
+	t.Run("Purge", func(t *testing.T) {
+		mockRepo.EXPECT().DeleteExpired(gomock.Any(), now.Add(-24*time.Hour)).Return(int64(3), nil)
+
+		count, err := service.Purge(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+
+	t.Run("Run_stops_on_cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		mockRepo.EXPECT().DeleteExpired(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(context.Context, time.Time) (int64, error) {
+				cancel()
+				return 0, errors.New("db error")
+			})
+
+		done := make(chan struct{})
+		go func() {
+			service.Run(ctx)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("idempotency purge did not stop after context cancel")
+		}
+	})
+}
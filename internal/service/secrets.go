@@ -2,76 +2,468 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/shekshuev/gophkeeper/internal/events"
 	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/metrics"
 	"github.com/shekshuev/gophkeeper/internal/models"
 	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+	"github.com/shekshuev/gophkeeper/internal/utils"
 )
 
 // SecretServiceImpl реализует SecretService.
 // Отвечает за бизнес-логику по работе с пользовательскими секретами.
 type SecretServiceImpl struct {
-	repo   repository.SecretRepository // Репозиторий секретов
-	logger *logger.Logger              // Логгер
+	repo     repository.SecretRepository      // Репозиторий секретов
+	folders  repository.FolderRepository      // Репозиторий папок (для проверки владельца папки)
+	audit    repository.SecretAuditRepository // Журнал аудита действий с секретами
+	tx       repository.TxManager             // Транзакции проверки папки и записи секрета
+	webhooks WebhookService                   // Очередь событий вебхуков
+	events   events.Publisher                 // Лента изменений секретов
+	logger   *logger.Logger                   // Логгер
 }
 
 // NewSecretServiceImpl создаёт новый экземпляр сервиса секретов.
-func NewSecretServiceImpl(repo repository.SecretRepository) *SecretServiceImpl {
+// Об изменениях секретов сервис сообщает подписчикам через publisher и вебхукам через очередь доставок webhooks.
+func NewSecretServiceImpl(repo repository.SecretRepository, folders repository.FolderRepository, audit repository.SecretAuditRepository, tx repository.TxManager, webhooks WebhookService, publisher events.Publisher) *SecretServiceImpl {
 	return &SecretServiceImpl{
-		repo:   repo,
-		logger: logger.NewLogger(),
+		repo:     repo,
+		folders:  folders,
+		audit:    audit,
+		tx:       tx,
+		webhooks: webhooks,
+		events:   publisher,
+		logger:   logger.NewLogger(),
 	}
 }
 
+// record записывает изменение секрета в журнал изменений, ставит его в очередь доставок вебхуков
+// и возвращает событие с ID записи. Вызывается в той же транзакции, что и само изменение,
+// поэтому доставки вебхуков не теряются при остановке сервера и не создаются для отменённых изменений.
+func (s *SecretServiceImpl) record(ctx context.Context, eventType string, userID, secretID uint64, version int) (models.SecretEventDTO, error) {
+	change, err := s.repo.CreateChange(ctx, models.SecretEventDTO{
+		Type:     eventType,
+		UserID:   userID,
+		SecretID: secretID,
+		Version:  version,
+	})
+	if err != nil {
+		s.logger.For(ctx).Error("Не удалось записать изменение секрета в журнал", zap.Uint64("secret_id", secretID), zap.String("type", eventType), zap.Error(err))
+		return models.SecretEventDTO{}, err
+	}
+	if err := s.webhooks.Notify(ctx, userID, models.WebhookEventSecretChanged, *change); err != nil {
+		s.logger.For(ctx).Error("Не удалось поставить изменение секрета в очередь вебхуков", zap.Uint64("secret_id", secretID), zap.Error(err))
+		return models.SecretEventDTO{}, err
+	}
+	return *change, nil
+}
+
+// publish учитывает изменение секрета в метриках и сообщает о нём подписчикам.
+// Вызывается после фиксации транзакции, в которой изменение записано в журнал (см. record).
+func (s *SecretServiceImpl) publish(event models.SecretEventDTO) {
+	metrics.SecretOperation(event.Type)
+	s.events.Publish(event)
+}
+
 // Create сохраняет новый секрет.
+// Если указана папка, проверяет, что она принадлежит пользователю; проверка и запись выполняются
+// в одной транзакции. Теги очищаются от пробелов и дублей.
 // Возвращает ID созданного секрета или ошибку.
 func (s *SecretServiceImpl) Create(ctx context.Context, dto models.CreateSecretDTO) (uint64, error) {
-	id, err := s.repo.Create(ctx, dto)
+	ctx, span := tracing.Start(ctx, "SecretService.Create")
+	defer span.End()
+
+	dto.Tags = normalizeTags(dto.Tags)
+	var (
+		id    uint64
+		event models.SecretEventDTO
+	)
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkFolder(ctx, dto.UserID, dto.FolderID); err != nil {
+			return err
+		}
+		var err error
+		if id, err = s.repo.Create(ctx, dto); err != nil {
+			s.logger.For(ctx).Error("Не удалось сохранить секрет", zap.Uint64("user_id", dto.UserID), zap.String("title", dto.Title), zap.Error(err))
+			return err
+		}
+		event, err = s.record(ctx, models.SecretEventCreated, dto.UserID, id, 1)
+		return err
+	})
 	if err != nil {
-		s.logger.Log.Error("Не удалось сохранить секрет", zap.Uint64("user_id", dto.UserID), zap.String("title", dto.Title), zap.Error(err))
 		return 0, err
 	}
-	s.logger.Log.Info("Секрет успешно создан", zap.Uint64("secret_id", id), zap.Uint64("user_id", dto.UserID))
+	s.logger.For(ctx).Info("Секрет успешно создан", zap.Uint64("secret_id", id), zap.Uint64("user_id", dto.UserID))
+	s.publish(event)
 	return id, nil
 }
 
-// GetByID возвращает секрет по ID.
-// Если секрет не найден, возвращает nil, nil.
-func (s *SecretServiceImpl) GetByID(ctx context.Context, id uint64) (*models.ReadSecretDTO, error) {
-	secret, err := s.repo.GetByID(ctx, id)
+// Update изменяет секрет пользователя. Предыдущее состояние сохраняется в истории версий.
+// Если указана папка, проверяет, что она принадлежит пользователю; проверка и изменение выполняются
+// в одной транзакции. Теги очищаются от пробелов и дублей.
+// Возвращает ErrSecretNotFound, если секрет не найден или принадлежит другому пользователю.
+func (s *SecretServiceImpl) Update(ctx context.Context, dto models.UpdateSecretDTO) (*models.ReadSecretDTO, error) {
+	ctx, span := tracing.Start(ctx, "SecretService.Update")
+	defer span.End()
+
+	dto.Tags = normalizeTags(dto.Tags)
+	var (
+		secret *models.ReadSecretDTO
+		event  models.SecretEventDTO
+	)
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkFolder(ctx, dto.UserID, dto.FolderID); err != nil {
+			return err
+		}
+		var err error
+		if secret, err = s.repo.Update(ctx, dto); err != nil {
+			return err
+		}
+		event, err = s.record(ctx, models.SecretEventUpdated, dto.UserID, secret.ID, secret.Version)
+		return err
+	})
+	if errors.Is(err, ErrFolderNotFound) {
+		return nil, err
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		s.logger.For(ctx).Warn("Секрет для изменения не найден", zap.Uint64("secret_id", dto.ID), zap.Uint64("user_id", dto.UserID))
+		return nil, ErrSecretNotFound
+	}
 	if err != nil {
-		s.logger.Log.Error("Ошибка при получении секрета по ID", zap.Uint64("secret_id", id), zap.Error(err))
+		s.logger.For(ctx).Error("Не удалось изменить секрет", zap.Uint64("secret_id", dto.ID), zap.Error(err))
 		return nil, err
 	}
-	if secret == nil {
-		s.logger.Log.Warn("Секрет не найден", zap.Uint64("secret_id", id))
-	} else {
-		s.logger.Log.Info("Секрет успешно получен", zap.Uint64("secret_id", secret.ID))
+	s.logger.For(ctx).Info("Секрет успешно изменён", zap.Uint64("secret_id", secret.ID), zap.Int("version", secret.Version))
+	s.publish(event)
+	return secret, nil
+}
+
+// GetVersions возвращает метаданные предыдущих версий секрета пользователя.
+func (s *SecretServiceImpl) GetVersions(ctx context.Context, userID, id uint64) ([]models.SecretVersionDTO, error) {
+	ctx, span := tracing.Start(ctx, "SecretService.GetVersions")
+	defer span.End()
+
+	versions, err := s.repo.GetVersions(ctx, userID, id)
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка при получении версий секрета", zap.Uint64("secret_id", id), zap.Error(err))
+		return nil, err
 	}
+	s.logger.For(ctx).Info("Версии секрета успешно получены", zap.Uint64("secret_id", id), zap.Int("count", len(versions)))
+	return versions, nil
+}
+
+// GetVersion возвращает предыдущую версию секрета вместе с данными и записывает просмотр в журнал аудита.
+// Возвращает ErrSecretVersionNotFound, если версия не найдена.
+func (s *SecretServiceImpl) GetVersion(ctx context.Context, userID, id uint64, version int, meta models.RequestMetaDTO) (*models.ReadSecretVersionDTO, error) {
+	ctx, span := tracing.Start(ctx, "SecretService.GetVersion")
+	defer span.End()
+
+	v, err := s.getVersion(ctx, userID, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.audit.Create(ctx, models.CreateSecretAuditDTO{
+		UserID:    userID,
+		SecretID:  id,
+		Action:    models.SecretActionRevealVersion,
+		IP:        meta.IP,
+		UserAgent: meta.UserAgent,
+	})
+	if err != nil {
+		s.logger.For(ctx).Error("Не удалось записать просмотр версии секрета в журнал аудита", zap.Uint64("secret_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.For(ctx).Info("Данные версии секрета выданы пользователю", zap.Uint64("secret_id", id), zap.Int("version", version))
+	return v, nil
+}
+
+// Restore делает предыдущую версию секрета текущей.
+// Восстановление выполняется как обычное изменение: текущее состояние тоже попадает в историю,
+// поэтому восстановление можно отменить. Чтение версии и изменение выполняются в одной транзакции.
+func (s *SecretServiceImpl) Restore(ctx context.Context, userID, id uint64, version int, device string) (*models.ReadSecretDTO, error) {
+	ctx, span := tracing.Start(ctx, "SecretService.Restore")
+	defer span.End()
+
+	var (
+		secret *models.ReadSecretDTO
+		event  models.SecretEventDTO
+	)
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		v, err := s.getVersion(ctx, userID, id, version)
+		if err != nil {
+			return err
+		}
+		secret, err = s.repo.Update(ctx, models.UpdateSecretDTO{
+			ID:       id,
+			UserID:   userID,
+			Title:    v.Title,
+			Data:     v.Data,
+			FolderID: v.FolderID,
+			Tags:     v.Tags,
+			Device:   device,
+		})
+		if err != nil {
+			return err
+		}
+		event, err = s.record(ctx, models.SecretEventUpdated, userID, secret.ID, secret.Version)
+		return err
+	})
+	if errors.Is(err, ErrSecretVersionNotFound) {
+		return nil, err
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		s.logger.For(ctx).Warn("Секрет для восстановления не найден", zap.Uint64("secret_id", id))
+		return nil, ErrSecretNotFound
+	}
+	if err != nil {
+		s.logger.For(ctx).Error("Не удалось восстановить версию секрета", zap.Uint64("secret_id", id), zap.Int("version", version), zap.Error(err))
+		return nil, err
+	}
+	s.logger.For(ctx).Info("Версия секрета восстановлена", zap.Uint64("secret_id", id), zap.Int("version", version), zap.Int("new_version", secret.Version))
+	s.publish(event)
 	return secret, nil
 }
 
-// GetAllByUser возвращает все секреты конкретного пользователя.
-func (s *SecretServiceImpl) GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadSecretDTO, error) {
-	secrets, err := s.repo.GetAllByUser(ctx, userID)
+// getVersion загружает версию секрета, преобразуя ErrNotFound репозитория в ErrSecretVersionNotFound.
+func (s *SecretServiceImpl) getVersion(ctx context.Context, userID, id uint64, version int) (*models.ReadSecretVersionDTO, error) {
+	v, err := s.repo.GetVersion(ctx, userID, id, version)
+	if errors.Is(err, repository.ErrNotFound) {
+		s.logger.For(ctx).Warn("Версия секрета не найдена", zap.Uint64("secret_id", id), zap.Int("version", version))
+		return nil, ErrSecretVersionNotFound
+	}
 	if err != nil {
-		s.logger.Log.Error("Ошибка при получении секретов пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+		s.logger.For(ctx).Error("Ошибка при получении версии секрета", zap.Uint64("secret_id", id), zap.Int("version", version), zap.Error(err))
 		return nil, err
 	}
-	s.logger.Log.Info("Секреты пользователя успешно получены", zap.Uint64("user_id", userID), zap.Int("count", len(secrets)))
-	return secrets, nil
+	return v, nil
 }
 
-// DeleteByID удаляет секрет по ID.
-// Возвращает ошибку, если удаление не удалось.
-func (s *SecretServiceImpl) DeleteByID(ctx context.Context, id uint64) error {
-	err := s.repo.DeleteByID(ctx, id)
+// checkFolder проверяет, что папка секрета (если указана) принадлежит пользователю.
+func (s *SecretServiceImpl) checkFolder(ctx context.Context, userID uint64, folderID *uint64) error {
+	if folderID == nil {
+		return nil
+	}
+	_, err := s.folders.GetByID(ctx, userID, *folderID)
+	if errors.Is(err, repository.ErrNotFound) {
+		s.logger.For(ctx).Warn("Папка секрета не найдена", zap.Uint64("user_id", userID), zap.Uint64("folder_id", *folderID))
+		return ErrFolderNotFound
+	}
 	if err != nil {
-		s.logger.Log.Error("Ошибка при удалении секрета", zap.Uint64("secret_id", id), zap.Error(err))
+		s.logger.For(ctx).Error("Ошибка при проверке папки секрета", zap.Uint64("folder_id", *folderID), zap.Error(err))
 		return err
 	}
-	s.logger.Log.Info("Секрет успешно удалён", zap.Uint64("secret_id", id))
 	return nil
 }
+
+// GetAllByUser возвращает метаданные секретов конкретного пользователя без полезных данных, отобранные по фильтру.
+func (s *SecretServiceImpl) GetAllByUser(ctx context.Context, userID uint64, filter models.SecretFilterDTO) ([]models.SecretSummaryDTO, error) {
+	ctx, span := tracing.Start(ctx, "SecretService.GetAllByUser")
+	defer span.End()
+
+	filter.Tag = strings.TrimSpace(filter.Tag)
+	summaries, err := s.repo.GetSummariesByUser(ctx, userID, filter)
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка при получении секретов пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	s.logger.For(ctx).Info("Секреты пользователя успешно получены", zap.Uint64("user_id", userID), zap.Int("count", len(summaries)))
+	return summaries, nil
+}
+
+// Reveal возвращает секрет вместе с данными и записывает просмотр в журнал аудита.
+// Если секрет не найден или принадлежит другому пользователю, возвращает ErrSecretNotFound.
+// Данные не возвращаются, если запись в журнал не удалась.
+func (s *SecretServiceImpl) Reveal(ctx context.Context, userID, id uint64, meta models.RequestMetaDTO) (*models.ReadSecretDTO, error) {
+	ctx, span := tracing.Start(ctx, "SecretService.Reveal")
+	defer span.End()
+
+	secret, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка при получении секрета по ID", zap.Uint64("secret_id", id), zap.Error(err))
+		return nil, err
+	}
+	if secret == nil || secret.UserID != userID {
+		s.logger.For(ctx).Warn("Секрет не найден", zap.Uint64("secret_id", id), zap.Uint64("user_id", userID))
+		return nil, ErrSecretNotFound
+	}
+
+	err = s.audit.Create(ctx, models.CreateSecretAuditDTO{
+		UserID:    userID,
+		SecretID:  id,
+		Action:    models.SecretActionReveal,
+		IP:        meta.IP,
+		UserAgent: meta.UserAgent,
+	})
+	if err != nil {
+		s.logger.For(ctx).Error("Не удалось записать просмотр секрета в журнал аудита", zap.Uint64("secret_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.For(ctx).Info("Данные секрета выданы пользователю", zap.Uint64("secret_id", id), zap.Uint64("user_id", userID))
+	metrics.SecretOperation(models.SecretActionReveal)
+	return secret, nil
+}
+
+// GetAuditBySecret возвращает журнал аудита секрета пользователя.
+func (s *SecretServiceImpl) GetAuditBySecret(ctx context.Context, userID, id uint64) ([]models.ReadSecretAuditDTO, error) {
+	ctx, span := tracing.Start(ctx, "SecretService.GetAuditBySecret")
+	defer span.End()
+
+	records, err := s.audit.GetBySecret(ctx, userID, id)
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка при получении журнала аудита секрета", zap.Uint64("secret_id", id), zap.Error(err))
+		return nil, err
+	}
+	s.logger.For(ctx).Info("Журнал аудита секрета получен", zap.Uint64("secret_id", id), zap.Int("count", len(records)))
+	return records, nil
+}
+
+// ExportPageByUser возвращает страницу секретов пользователя вместе с данными, отобранных и упорядоченных по фильтру,
+// и записывает в журнал аудита просмотр каждого секрета страницы.
+// Запрашивает у репозитория на одну запись больше размера страницы: если она есть,
+// формирует курсор следующей страницы по последней записи текущей.
+// Чтение и записи журнала выполняются в одной транзакции: данные не возвращаются, если журнал записать не удалось.
+func (s *SecretServiceImpl) ExportPageByUser(ctx context.Context, userID uint64, filter models.SecretFilterDTO, meta models.RequestMetaDTO) (*models.SecretPageDTO, error) {
+	ctx, span := tracing.Start(ctx, "SecretService.ExportPageByUser")
+	defer span.End()
+
+	filter, limit := pageFilter(filter)
+	var secrets []models.ReadSecretDTO
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if secrets, err = s.repo.GetAllByUser(ctx, userID, filter); err != nil {
+			return err
+		}
+		for _, secret := range secrets[:min(len(secrets), limit)] {
+			err := s.audit.Create(ctx, models.CreateSecretAuditDTO{
+				UserID:    userID,
+				SecretID:  secret.ID,
+				Action:    models.SecretActionReveal,
+				IP:        meta.IP,
+				UserAgent: meta.UserAgent,
+			})
+			if err != nil {
+				s.logger.For(ctx).Error("Не удалось записать выгрузку секрета в журнал аудита", zap.Uint64("secret_id", secret.ID), zap.Error(err))
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, s.pageError(ctx, userID, err)
+	}
+
+	page := &models.SecretPageDTO{Items: secrets}
+	if page.Items == nil {
+		page.Items = []models.ReadSecretDTO{}
+	}
+	if len(secrets) > limit {
+		page.Items = secrets[:limit]
+		last := page.Items[limit-1]
+		if page.NextCursor, err = nextCursor(filter.Sort, last.ID, last.Title, last.CreatedAt, last.UpdatedAt); err != nil {
+			return nil, s.pageError(ctx, userID, err)
+		}
+	}
+	for range page.Items {
+		metrics.SecretOperation(models.SecretActionReveal)
+	}
+	s.logger.For(ctx).Info("Страница секретов с данными выдана пользователю", zap.Uint64("user_id", userID), zap.Int("count", len(page.Items)), zap.Bool("has_next", page.NextCursor != ""))
+	return page, nil
+}
+
+// GetSummaryPageByUser возвращает страницу метаданных секретов пользователя без полезных данных.
+// Постраничная выборка устроена так же, как в ExportPageByUser.
+func (s *SecretServiceImpl) GetSummaryPageByUser(ctx context.Context, userID uint64, filter models.SecretFilterDTO) (*models.SecretSummaryPageDTO, error) {
+	ctx, span := tracing.Start(ctx, "SecretService.GetSummaryPageByUser")
+	defer span.End()
+
+	filter, limit := pageFilter(filter)
+	summaries, err := s.repo.GetSummariesByUser(ctx, userID, filter)
+	if err != nil {
+		return nil, s.pageError(ctx, userID, err)
+	}
+
+	page := &models.SecretSummaryPageDTO{Items: summaries}
+	if page.Items == nil {
+		page.Items = []models.SecretSummaryDTO{}
+	}
+	if len(summaries) > limit {
+		page.Items = summaries[:limit]
+		last := page.Items[limit-1]
+		if page.NextCursor, err = nextCursor(filter.Sort, last.ID, last.Title, last.CreatedAt, last.UpdatedAt); err != nil {
+			return nil, s.pageError(ctx, userID, err)
+		}
+	}
+	s.logger.For(ctx).Info("Страница списка секретов успешно получена", zap.Uint64("user_id", userID), zap.Int("count", len(page.Items)), zap.Bool("has_next", page.NextCursor != ""))
+	return page, nil
+}
+
+// pageError логирует ошибку получения страницы и преобразует ошибку курсора в ErrInvalidCursor.
+func (s *SecretServiceImpl) pageError(ctx context.Context, userID uint64, err error) error {
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		s.logger.For(ctx).Warn("Невалидный курсор списка секретов", zap.Uint64("user_id", userID))
+		return ErrInvalidCursor
+	}
+	s.logger.For(ctx).Error("Ошибка при получении страницы секретов", zap.Uint64("user_id", userID), zap.Error(err))
+	return err
+}
+
+// pageFilter подставляет в фильтр сортировку и размер страницы по умолчанию
+// и увеличивает лимит на одну запись, чтобы определить наличие следующей страницы.
+// Возвращает подготовленный фильтр и размер страницы.
+func pageFilter(filter models.SecretFilterDTO) (models.SecretFilterDTO, int) {
+	filter.Tag = strings.TrimSpace(filter.Tag)
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Sort == "" {
+		filter.Sort = "-" + models.SecretSortCreatedAt
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultSecretsPageSize
+	}
+	limit := filter.Limit
+	filter.Limit++
+	return filter, limit
+}
+
+// nextCursor формирует закодированный курсор, указывающий на секрет в списке с заданной сортировкой.
+func nextCursor(sort string, id uint64, title string, createdAt, updatedAt time.Time) (string, error) {
+	cursor := models.SecretCursorDTO{Sort: sort, ID: id}
+	switch strings.TrimPrefix(sort, "-") {
+	case models.SecretSortTitle:
+		cursor.Value = title
+	case models.SecretSortUpdatedAt:
+		cursor.Value = updatedAt.Format(models.SecretCursorTimeLayout)
+	default:
+		cursor.Value = createdAt.Format(models.SecretCursorTimeLayout)
+	}
+	return utils.EncodeCursor(cursor)
+}
+
+// normalizeTags убирает пробелы по краям, пустые теги и повторы, сохраняя исходный порядок.
+func normalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		result = append(result, tag)
+	}
+	return result
+}
@@ -7,79 +7,35 @@ import (
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophkeeper/internal/events"
 	"github.com/shekshuev/gophkeeper/internal/mocks"
 	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/shekshuev/gophkeeper/internal/utils"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestSecretServiceImpl_GetByID(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
+// passthroughTx выполняет функцию без транзакции: в тестах сервисов репозитории подменены моками.
+type passthroughTx struct{}
 
-	mockRepo := mocks.NewMockSecretRepository(ctrl)
-	service := NewSecretServiceImpl(mockRepo)
-
-	now := time.Now()
-
-	testCases := []struct {
-		name      string
-		id        uint64
-		setupMock func()
-		expected  *models.ReadSecretDTO
-		hasError  bool
-	}{
-		{
-			name: "Success",
-			id:   1,
-			setupMock: func() {
-				mockRepo.EXPECT().
-					GetByID(gomock.Any(), uint64(1)).
-					Return(&models.ReadSecretDTO{
-						ID:        1,
-						UserID:    10,
-						Title:     "my note",
-						Data:      models.SecretDataDTO{Text: ptr("hello")},
-						CreatedAt: now,
-						UpdatedAt: now,
-					}, nil)
-			},
-			expected: &models.ReadSecretDTO{
-				ID:     1,
-				UserID: 10,
-				Title:  "my note",
-				Data:   models.SecretDataDTO{Text: ptr("hello")},
-			},
-			hasError: false,
-		},
-		{
-			name: "Not found",
-			id:   404,
-			setupMock: func() {
-				mockRepo.EXPECT().
-					GetByID(gomock.Any(), uint64(404)).
-					Return(nil, errors.New("not found"))
-			},
-			expected: nil,
-			hasError: true,
-		},
-	}
+func (passthroughTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			tc.setupMock()
-			result, err := service.GetByID(context.Background(), tc.id)
-
-			if tc.hasError {
-				assert.Error(t, err)
-				assert.Nil(t, result)
-			} else {
-				assert.NoError(t, err)
-				assert.Equal(t, tc.expected.ID, result.ID)
-				assert.Equal(t, tc.expected.Title, result.Title)
-				assert.Equal(t, *tc.expected.Data.Text, *result.Data.Text)
-			}
+// expectChange ожидает запись изменения секрета в журнал изменений; ID записи равен changeID.
+func expectChange(repo *mocks.MockSecretRepository, eventType string, userID, secretID uint64, version int, changeID uint64) *gomock.Call {
+	return repo.EXPECT().
+		CreateChange(gomock.Any(), models.SecretEventDTO{Type: eventType, UserID: userID, SecretID: secretID, Version: version}).
+		DoAndReturn(func(_ context.Context, event models.SecretEventDTO) (*models.SecretEventDTO, error) {
+			event.ID = changeID
+			return &event, nil
 		})
-	}
+}
+
+func anyWebhooks(ctrl *gomock.Controller) *mocks.MockWebhookService {
+	webhooks := mocks.NewMockWebhookService(ctrl)
+	webhooks.EXPECT().Notify(gomock.Any(), gomock.Any(), models.WebhookEventSecretChanged, gomock.Any()).Return(nil).AnyTimes()
+	return webhooks
 }
 
 func TestSecretServiceImpl_Create(t *testing.T) {
@@ -87,7 +43,9 @@ func TestSecretServiceImpl_Create(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockSecretRepository(ctrl)
-	service := NewSecretServiceImpl(mockRepo)
+	mockWebhooks := mocks.NewMockWebhookService(ctrl)
+	broker := events.NewBroker()
+	service := NewSecretServiceImpl(mockRepo, mocks.NewMockFolderRepository(ctrl), mocks.NewMockSecretAuditRepository(ctrl), markingTx{}, mockWebhooks, broker)
 
 	input := models.CreateSecretDTO{
 		UserID: 10,
@@ -96,13 +54,87 @@ func TestSecretServiceImpl_Create(t *testing.T) {
 	}
 
 	t.Run("Success", func(t *testing.T) {
+		feed, unsubscribe := broker.Subscribe(10)
+		defer unsubscribe()
 		mockRepo.EXPECT().
 			Create(gomock.Any(), input).
 			Return(uint64(123), nil)
+		expectChange(mockRepo, models.SecretEventCreated, 10, 123, 1, 55)
+		mockWebhooks.EXPECT().Notify(gomock.Any(), uint64(10), models.WebhookEventSecretChanged, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ uint64, _ string, data any) error {
+				assert.Equal(t, true, ctx.Value(inTxKey{}), "webhook deliveries must be queued inside the transaction")
+				assert.Equal(t, uint64(55), data.(models.SecretEventDTO).ID)
+				return nil
+			})
 
 		id, err := service.Create(context.Background(), input)
 		assert.NoError(t, err)
 		assert.Equal(t, uint64(123), id)
+		event := <-feed
+		assert.Equal(t, uint64(55), event.ID, "published event carries the change-log ID")
+		assert.Equal(t, uint64(123), event.SecretID)
+	})
+
+	t.Run("Webhook_enqueue_error", func(t *testing.T) {
+		feed, unsubscribe := broker.Subscribe(10)
+		defer unsubscribe()
+		mockRepo.EXPECT().
+			Create(gomock.Any(), input).
+			Return(uint64(125), nil)
+		expectChange(mockRepo, models.SecretEventCreated, 10, 125, 1, 56)
+		mockWebhooks.EXPECT().Notify(gomock.Any(), uint64(10), models.WebhookEventSecretChanged, gomock.Any()).Return(errors.New("db error"))
+
+		_, err := service.Create(context.Background(), input)
+		assert.Error(t, err)
+		assert.Empty(t, feed, "nothing is published when webhook deliveries are not queued")
+	})
+
+	t.Run("Change_log_error", func(t *testing.T) {
+		feed, unsubscribe := broker.Subscribe(10)
+		defer unsubscribe()
+		mockRepo.EXPECT().
+			Create(gomock.Any(), input).
+			Return(uint64(124), nil)
+		mockRepo.EXPECT().CreateChange(gomock.Any(), gomock.Any()).Return(nil, errors.New("db error"))
+
+		_, err := service.Create(context.Background(), input)
+		assert.Error(t, err)
+		assert.Empty(t, feed, "nothing is published when the change is not recorded")
+	})
+}
+
+func TestSecretServiceImpl_Create_FolderAndTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockSecretRepository(ctrl)
+	mockFolders := mocks.NewMockFolderRepository(ctrl)
+	service := NewSecretServiceImpl(mockRepo, mockFolders, mocks.NewMockSecretAuditRepository(ctrl), passthroughTx{}, anyWebhooks(ctrl), events.NewBroker())
+	folderID := uint64(5)
+
+	t.Run("Success", func(t *testing.T) {
+		mockFolders.EXPECT().GetByID(gomock.Any(), uint64(10), folderID).Return(&models.ReadFolderDTO{ID: folderID}, nil)
+		mockRepo.EXPECT().
+			Create(gomock.Any(), models.CreateSecretDTO{UserID: 10, Title: "Secret", FolderID: &folderID, Tags: []string{"work", "vpn"}}).
+			Return(uint64(1), nil)
+		expectChange(mockRepo, models.SecretEventCreated, 10, 1, 1, 1)
+
+		id, err := service.Create(context.Background(), models.CreateSecretDTO{
+			UserID:   10,
+			Title:    "Secret",
+			FolderID: &folderID,
+			Tags:     []string{" work ", "vpn", "", "work"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), id)
+	})
+
+	t.Run("Foreign_folder", func(t *testing.T) {
+		mockFolders.EXPECT().GetByID(gomock.Any(), uint64(10), folderID).Return(nil, repository.ErrNotFound)
+
+		id, err := service.Create(context.Background(), models.CreateSecretDTO{UserID: 10, Title: "Secret", FolderID: &folderID})
+		assert.ErrorIs(t, err, ErrFolderNotFound)
+		assert.Equal(t, uint64(0), id)
 	})
 }
 
@@ -111,7 +143,7 @@ func TestSecretServiceImpl_Create_Error(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockSecretRepository(ctrl)
-	service := NewSecretServiceImpl(mockRepo)
+	service := NewSecretServiceImpl(mockRepo, mocks.NewMockFolderRepository(ctrl), mocks.NewMockSecretAuditRepository(ctrl), passthroughTx{}, anyWebhooks(ctrl), events.NewBroker())
 
 	input := models.CreateSecretDTO{
 		UserID: 10,
@@ -133,17 +165,17 @@ func TestSecretServiceImpl_GetAllByUser(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockSecretRepository(ctrl)
-	service := NewSecretServiceImpl(mockRepo)
+	service := NewSecretServiceImpl(mockRepo, mocks.NewMockFolderRepository(ctrl), mocks.NewMockSecretAuditRepository(ctrl), passthroughTx{}, anyWebhooks(ctrl), events.NewBroker())
 
 	t.Run("Success", func(t *testing.T) {
 		mockRepo.EXPECT().
-			GetAllByUser(gomock.Any(), uint64(10)).
-			Return([]models.ReadSecretDTO{
+			GetSummariesByUser(gomock.Any(), uint64(10), models.SecretFilterDTO{}).
+			Return([]models.SecretSummaryDTO{
 				{ID: 1, Title: "A"},
 				{ID: 2, Title: "B"},
 			}, nil)
 
-		result, err := service.GetAllByUser(context.Background(), 10)
+		result, err := service.GetAllByUser(context.Background(), 10, models.SecretFilterDTO{})
 		assert.NoError(t, err)
 		assert.Len(t, result, 2)
 		assert.Equal(t, "A", result[0].Title)
@@ -155,47 +187,200 @@ func TestSecretServiceImpl_GetAllByUser_Error(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockSecretRepository(ctrl)
-	service := NewSecretServiceImpl(mockRepo)
+	service := NewSecretServiceImpl(mockRepo, mocks.NewMockFolderRepository(ctrl), mocks.NewMockSecretAuditRepository(ctrl), passthroughTx{}, anyWebhooks(ctrl), events.NewBroker())
 
 	mockRepo.EXPECT().
-		GetAllByUser(gomock.Any(), uint64(10)).
+		GetSummariesByUser(gomock.Any(), uint64(10), models.SecretFilterDTO{}).
 		Return(nil, errors.New("db error"))
 
-	result, err := service.GetAllByUser(context.Background(), 10)
+	result, err := service.GetAllByUser(context.Background(), 10, models.SecretFilterDTO{})
 	assert.Error(t, err)
 	assert.Nil(t, result)
 }
 
-func TestSecretServiceImpl_DeleteByID(t *testing.T) {
+func TestSecretServiceImpl_ExportPageByUser(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockSecretRepository(ctrl)
-	service := NewSecretServiceImpl(mockRepo)
+	mockAudit := mocks.NewMockSecretAuditRepository(ctrl)
+	service := NewSecretServiceImpl(mockRepo, mocks.NewMockFolderRepository(ctrl), mockAudit, passthroughTx{}, anyWebhooks(ctrl), events.NewBroker())
+	meta := models.RequestMetaDTO{IP: "127.0.0.1", UserAgent: "test"}
+	created := time.Date(2025, 3, 4, 5, 6, 7, 890000000, time.UTC)
 
-	t.Run("Success", func(t *testing.T) {
+	t.Run("Has_next_page", func(t *testing.T) {
 		mockRepo.EXPECT().
-			DeleteByID(gomock.Any(), uint64(77)).
-			Return(nil)
+			GetAllByUser(gomock.Any(), uint64(1), models.SecretFilterDTO{Query: "bank", Sort: "-created_at", Limit: 3}).
+			Return([]models.ReadSecretDTO{{ID: 5}, {ID: 4, CreatedAt: created}, {ID: 3}}, nil)
+		for _, id := range []uint64{5, 4} {
+			mockAudit.EXPECT().
+				Create(gomock.Any(), models.CreateSecretAuditDTO{UserID: 1, SecretID: id, Action: models.SecretActionReveal, IP: "127.0.0.1", UserAgent: "test"}).
+				Return(nil)
+		}
+
+		page, err := service.ExportPageByUser(context.Background(), 1, models.SecretFilterDTO{Query: " bank ", Limit: 2}, meta)
+		assert.NoError(t, err)
+		assert.Len(t, page.Items, 2)
 
-		err := service.DeleteByID(context.Background(), 77)
+		var cursor models.SecretCursorDTO
+		assert.NoError(t, utils.DecodeCursor(page.NextCursor, &cursor))
+		assert.Equal(t, models.SecretCursorDTO{Sort: "-created_at", Value: "2025-03-04T05:06:07.89", ID: 4}, cursor)
+	})
+
+	t.Run("Last_page_by_title", func(t *testing.T) {
+		mockRepo.EXPECT().
+			GetAllByUser(gomock.Any(), uint64(1), models.SecretFilterDTO{Sort: "title", Limit: DefaultSecretsPageSize + 1}).
+			Return(nil, nil)
+
+		page, err := service.ExportPageByUser(context.Background(), 1, models.SecretFilterDTO{Sort: "title"}, meta)
 		assert.NoError(t, err)
+		assert.NotNil(t, page.Items)
+		assert.Empty(t, page.Items)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("Invalid_cursor", func(t *testing.T) {
+		mockRepo.EXPECT().
+			GetAllByUser(gomock.Any(), uint64(1), gomock.Any()).
+			Return(nil, repository.ErrInvalidCursor)
+
+		page, err := service.ExportPageByUser(context.Background(), 1, models.SecretFilterDTO{}, meta)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+		assert.Nil(t, page)
+	})
+
+	t.Run("Repository_error", func(t *testing.T) {
+		mockRepo.EXPECT().
+			GetAllByUser(gomock.Any(), uint64(1), gomock.Any()).
+			Return(nil, errors.New("db error"))
+
+		page, err := service.ExportPageByUser(context.Background(), 1, models.SecretFilterDTO{}, meta)
+		assert.Error(t, err)
+		assert.Nil(t, page)
+	})
+
+	t.Run("Audit_error", func(t *testing.T) {
+		mockRepo.EXPECT().
+			GetAllByUser(gomock.Any(), uint64(1), gomock.Any()).
+			Return([]models.ReadSecretDTO{{ID: 5}}, nil)
+		mockAudit.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+
+		page, err := service.ExportPageByUser(context.Background(), 1, models.SecretFilterDTO{}, meta)
+		assert.Error(t, err)
+		assert.Nil(t, page)
 	})
 }
 
-func TestSecretServiceImpl_DeleteByID_Error(t *testing.T) {
+func TestSecretServiceImpl_GetSummaryPageByUser(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockSecretRepository(ctrl)
-	service := NewSecretServiceImpl(mockRepo)
+	service := NewSecretServiceImpl(mockRepo, mocks.NewMockFolderRepository(ctrl), mocks.NewMockSecretAuditRepository(ctrl), passthroughTx{}, anyWebhooks(ctrl), events.NewBroker())
 
-	mockRepo.EXPECT().
-		DeleteByID(gomock.Any(), uint64(77)).
-		Return(errors.New("delete error"))
+	t.Run("Has_next_page", func(t *testing.T) {
+		mockRepo.EXPECT().
+			GetSummariesByUser(gomock.Any(), uint64(1), models.SecretFilterDTO{Sort: "title", Limit: 2}).
+			Return([]models.SecretSummaryDTO{{ID: 4, Title: "Bank"}, {ID: 3, Title: "VPN"}}, nil)
+
+		page, err := service.GetSummaryPageByUser(context.Background(), 1, models.SecretFilterDTO{Sort: "title", Limit: 1})
+		assert.NoError(t, err)
+		assert.Len(t, page.Items, 1)
+
+		var cursor models.SecretCursorDTO
+		assert.NoError(t, utils.DecodeCursor(page.NextCursor, &cursor))
+		assert.Equal(t, models.SecretCursorDTO{Sort: "title", Value: "Bank", ID: 4}, cursor)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		mockRepo.EXPECT().
+			GetSummariesByUser(gomock.Any(), uint64(1), gomock.Any()).
+			Return(nil, nil)
 
-	err := service.DeleteByID(context.Background(), 77)
+		page, err := service.GetSummaryPageByUser(context.Background(), 1, models.SecretFilterDTO{})
+		assert.NoError(t, err)
+		assert.NotNil(t, page.Items)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("Invalid_cursor", func(t *testing.T) {
+		mockRepo.EXPECT().
+			GetSummariesByUser(gomock.Any(), uint64(1), gomock.Any()).
+			Return(nil, repository.ErrInvalidCursor)
+
+		page, err := service.GetSummaryPageByUser(context.Background(), 1, models.SecretFilterDTO{})
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+		assert.Nil(t, page)
+	})
+}
+
+func TestSecretServiceImpl_Reveal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockSecretRepository(ctrl)
+	mockAudit := mocks.NewMockSecretAuditRepository(ctrl)
+	service := NewSecretServiceImpl(mockRepo, mocks.NewMockFolderRepository(ctrl), mockAudit, passthroughTx{}, anyWebhooks(ctrl), events.NewBroker())
+	meta := models.RequestMetaDTO{IP: "10.0.0.1", UserAgent: "cli"}
+	secret := &models.ReadSecretDTO{ID: 7, UserID: 1, Title: "VPN"}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), uint64(7)).Return(secret, nil)
+		mockAudit.EXPECT().
+			Create(gomock.Any(), models.CreateSecretAuditDTO{UserID: 1, SecretID: 7, Action: models.SecretActionReveal, IP: "10.0.0.1", UserAgent: "cli"}).
+			Return(nil)
+
+		result, err := service.Reveal(context.Background(), 1, 7, meta)
+		assert.NoError(t, err)
+		assert.Equal(t, secret, result)
+	})
+
+	t.Run("Other_owner", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), uint64(7)).Return(secret, nil)
+
+		result, err := service.Reveal(context.Background(), 2, 7, meta)
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("Not_found", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), uint64(8)).Return(nil, nil)
+
+		result, err := service.Reveal(context.Background(), 1, 8, meta)
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("Audit_error", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), uint64(7)).Return(secret, nil)
+		mockAudit.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+
+		result, err := service.Reveal(context.Background(), 1, 7, meta)
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestSecretServiceImpl_GetAuditBySecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAudit := mocks.NewMockSecretAuditRepository(ctrl)
+	service := NewSecretServiceImpl(mocks.NewMockSecretRepository(ctrl), mocks.NewMockFolderRepository(ctrl), mockAudit, passthroughTx{}, anyWebhooks(ctrl), events.NewBroker())
+
+	mockAudit.EXPECT().
+		GetBySecret(gomock.Any(), uint64(1), uint64(7)).
+		Return([]models.ReadSecretAuditDTO{{ID: 1, SecretID: 7, Action: models.SecretActionReveal}}, nil)
+	records, err := service.GetAuditBySecret(context.Background(), 1, 7)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	mockAudit.EXPECT().
+		GetBySecret(gomock.Any(), uint64(1), uint64(8)).
+		Return(nil, errors.New("db error"))
+	records, err = service.GetAuditBySecret(context.Background(), 1, 8)
 	assert.Error(t, err)
+	assert.Nil(t, records)
 }
 
 func ptr(s string) *string {
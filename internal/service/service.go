@@ -2,9 +2,13 @@ package service
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
+	"time"
 
+	"github.com/shekshuev/gophkeeper/internal/certs"
 	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
 )
 
 // UserService определяет операции для управления пользователями и получения информации о них.
@@ -27,9 +31,131 @@ type AuthService interface {
 // SecretService определяет поведение сервиса по работе с секретами.
 type SecretService interface {
 	Create(ctx context.Context, dto models.CreateSecretDTO) (uint64, error)
-	GetByID(ctx context.Context, id uint64) (*models.ReadSecretDTO, error)
-	GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadSecretDTO, error)
-	DeleteByID(ctx context.Context, id uint64) error
+	// Update изменяет секрет пользователя, сохраняя предыдущее состояние в истории версий.
+	// Возвращает ErrSecretNotFound или ErrFolderNotFound.
+	Update(ctx context.Context, dto models.UpdateSecretDTO) (*models.ReadSecretDTO, error)
+	// GetVersions возвращает метаданные предыдущих версий секрета пользователя, начиная с последней.
+	GetVersions(ctx context.Context, userID, id uint64) ([]models.SecretVersionDTO, error)
+	// GetVersion возвращает предыдущую версию секрета с данными и записывает просмотр в журнал аудита.
+	// Возвращает ErrSecretVersionNotFound, если версия не найдена.
+	GetVersion(ctx context.Context, userID, id uint64, version int, meta models.RequestMetaDTO) (*models.ReadSecretVersionDTO, error)
+	// Restore делает предыдущую версию секрета текущей, сохраняя текущее состояние в истории.
+	// Возвращает ErrSecretVersionNotFound, если версия не найдена.
+	Restore(ctx context.Context, userID, id uint64, version int, device string) (*models.ReadSecretDTO, error)
+	// GetAllByUser возвращает метаданные секретов пользователя без полезных данных.
+	GetAllByUser(ctx context.Context, userID uint64, filter models.SecretFilterDTO) ([]models.SecretSummaryDTO, error)
+	// ExportPageByUser возвращает страницу секретов пользователя с данными и курсор следующей страницы,
+	// записывая просмотр каждого секрета страницы в журнал аудита.
+	// Возвращает ErrInvalidCursor, если курсор не соответствует сортировке.
+	ExportPageByUser(ctx context.Context, userID uint64, filter models.SecretFilterDTO, meta models.RequestMetaDTO) (*models.SecretPageDTO, error)
+	// GetSummaryPageByUser возвращает страницу метаданных секретов пользователя без полезных данных.
+	GetSummaryPageByUser(ctx context.Context, userID uint64, filter models.SecretFilterDTO) (*models.SecretSummaryPageDTO, error)
+	// GetChangesAfter передаёт в send изменения секретов пользователя из журнала с ID больше afterID в порядке ID.
+	// Используется лентами изменений, чтобы клиент после переподключения догнал пропущенные события.
+	// Если изменения после afterID уже удалены из журнала, передаёт одно событие models.SecretEventReset.
+	GetChangesAfter(ctx context.Context, userID, afterID uint64, send func(models.SecretEventDTO) error) error
+	// GetChangesSince передаёт в send изменения секретов пользователя из журнала, записанные не раньше since, в порядке ID.
+	GetChangesSince(ctx context.Context, userID uint64, since time.Time, send func(models.SecretEventDTO) error) error
+	// Reveal возвращает секрет пользователя вместе с данными и записывает просмотр в журнал аудита.
+	// Возвращает ErrSecretNotFound, если секрет не найден или принадлежит другому пользователю.
+	Reveal(ctx context.Context, userID, id uint64, meta models.RequestMetaDTO) (*models.ReadSecretDTO, error)
+	// GetAuditBySecret возвращает журнал аудита секрета пользователя, начиная с последних записей.
+	GetAuditBySecret(ctx context.Context, userID, id uint64) ([]models.ReadSecretAuditDTO, error)
+	// DeleteByID перемещает секрет пользователя в корзину.
+	// Возвращает ErrSecretNotFound, если секрет не найден или уже в корзине.
+	DeleteByID(ctx context.Context, userID, id uint64) error
+	// GetTrash возвращает секреты пользователя в корзине (без данных), начиная с последних удалённых.
+	GetTrash(ctx context.Context, userID uint64) ([]models.TrashedSecretDTO, error)
+	// RestoreFromTrash возвращает секрет пользователя из корзины.
+	// Возвращает ErrSecretNotFound, если секрета нет в корзине.
+	RestoreFromTrash(ctx context.Context, userID, id uint64) error
+	// Purge окончательно удаляет секрет пользователя из корзины.
+	// Возвращает ErrSecretNotFound, если секрета нет в корзине.
+	Purge(ctx context.Context, userID, id uint64) error
+	// Batch выполняет пакет операций пользователя над секретами в одной транзакции.
+	// Ошибки операций (ErrSecretNotFound, ErrFolderNotFound, ErrBatchRolledBack) возвращаются в итогах,
+	// ошибка — только если не удалось выполнить саму транзакцию.
+	Batch(ctx context.Context, userID uint64, ops []models.BatchOperationDTO, atomic bool, device string) ([]models.BatchOutcome, error)
+}
+
+// FolderService определяет операции для организации секретов по иерархическим папкам.
+type FolderService interface {
+	// Create создаёт папку пользователя. Родительская папка, если задана, должна принадлежать тому же пользователю.
+	Create(ctx context.Context, dto models.CreateFolderDTO) (*models.ReadFolderDTO, error)
+
+	// GetAllByUser возвращает все папки пользователя (плоским списком, связи — через parent_id).
+	GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadFolderDTO, error)
+
+	// Rename изменяет название папки пользователя.
+	Rename(ctx context.Context, userID, id uint64, dto models.RenameFolderDTO) (*models.ReadFolderDTO, error)
+
+	// Move переносит папку пользователя в другую родительскую папку.
+	// Возвращает ErrFolderCycle, если новая родительская папка находится внутри перемещаемой.
+	Move(ctx context.Context, userID, id uint64, dto models.MoveFolderDTO) (*models.ReadFolderDTO, error)
+}
+
+// DefaultSecretsPageSize — размер страницы списка секретов, если клиент его не указал.
+const DefaultSecretsPageSize = 50
+
+// IdempotencyService определяет операции для безопасного повтора запросов с заголовком Idempotency-Key.
+type IdempotencyService interface {
+	// Begin закрепляет ключ за запросом и возвращает nil, nil, если запрос нужно выполнить.
+	// Если ответ на такой же запрос уже сохранён, возвращает его запись.
+	// Возвращает ErrIdempotencyKeyReused, если ключ использован для другого запроса,
+	// и ErrIdempotencyKeyInProgress, если исходный запрос ещё выполняется.
+	Begin(ctx context.Context, userID uint64, key, requestHash string) (*models.IdempotencyRecordDTO, error)
+	// Complete сохраняет ответ на запрос, чтобы повторять его по ключу.
+	Complete(ctx context.Context, userID uint64, key string, statusCode int, response []byte) error
+	// Release освобождает ключ, если запрос завершился ошибкой сервера и его можно выполнить заново.
+	Release(ctx context.Context, userID uint64, key string) error
+}
+
+// WebhookService определяет операции с вебхуками пользователя и постановку событий в очередь их доставки.
+type WebhookService interface {
+	// Create регистрирует вебхук и возвращает его вместе с ключом подписи.
+	Create(ctx context.Context, dto models.CreateWebhookDTO) (*models.ReadWebhookDTO, error)
+	// GetAllByUser возвращает вебхуки пользователя (без ключей подписи).
+	GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadWebhookDTO, error)
+	// DeleteByID удаляет вебхук пользователя. Возвращает ErrWebhookNotFound, если вебхук не найден.
+	DeleteByID(ctx context.Context, userID, id uint64) error
+	// GetDeliveries возвращает последние доставки вебхука пользователя, начиная с новых.
+	// Возвращает ErrWebhookNotFound, если вебхук не найден.
+	GetDeliveries(ctx context.Context, userID, id uint64) ([]models.WebhookDeliveryDTO, error)
+	// Notify ставит событие в очередь доставки всем вебхукам пользователя, подписанным на него.
+	Notify(ctx context.Context, userID uint64, event string, data any) error
+}
+
+// DeviceService выпускает сертификаты устройствам, сопоставляет предъявленные клиентами сертификаты
+// с устройствами и ведёт список их отзыва.
+type DeviceService interface {
+	// Enroll выпускает сертификат устройству device пользователя по запросу на сертификат csr и сохраняет его.
+	// Возвращает запись о сертификате и сам сертификат в PEM.
+	// Возвращает ErrDeviceCertsDisabled, если сертификаты устройств выключены, и ErrInvalidCSR.
+	Enroll(ctx context.Context, user models.ReadAuthUserDataDTO, device, csr string) (*models.DeviceCertificateDTO, string, error)
+	// Authenticate сопоставляет сертификат, предъявленный клиентом при установке соединения, с устройством.
+	// Возвращает ErrDeviceCertificateUnknown, если сервер не выдавал сертификат, и ErrDeviceCertificateRevoked.
+	Authenticate(ctx context.Context, cert *x509.Certificate) (*models.DeviceCertificateDTO, error)
+	// GetAllByUser возвращает сертификаты устройств пользователя, начиная с новых.
+	GetAllByUser(ctx context.Context, userID uint64) ([]models.DeviceCertificateDTO, error)
+	// Revoke отзывает сертификат устройства любого пользователя (действие администратора).
+	// Возвращает ErrDeviceCertificateNotFound, если сертификат не найден.
+	Revoke(ctx context.Context, serial string) (*models.DeviceCertificateDTO, error)
+	// RevokeByUser отзывает сертификат одного из устройств пользователя.
+	// Возвращает ErrDeviceCertificateNotFound, если сертификат не найден или выдан другому пользователю.
+	RevokeByUser(ctx context.Context, userID uint64, serial string) (*models.DeviceCertificateDTO, error)
+	// RevocationList возвращает список отзыва сертификатов устройств (CRL) в PEM, подписанный центром сертификации.
+	// Возвращает ErrDeviceCertsDisabled, если сертификаты устройств выключены.
+	RevocationList(ctx context.Context) ([]byte, error)
+}
+
+// HealthService определяет интерфейс проверки готовности сервера.
+type HealthService interface {
+	// Check проверяет зависимости сервера и возвращает их состояние.
+	Check(ctx context.Context) *models.ReadinessDTO
+
+	// WaitForDatabase ждёт, пока база данных начнёт принимать соединения, и возвращает ошибку,
+	// если она не ответила за отведённое число попыток.
+	WaitForDatabase(ctx context.Context) error
 }
 
 // ErrUserNotFound возвращается, если пользователь не найден в базе.
@@ -37,3 +163,51 @@ var ErrUserNotFound = fmt.Errorf("user not found")
 
 // ErrWrongPassword возвращается, если пароль не совпадает с сохранённым хешем.
 var ErrWrongPassword = fmt.Errorf("wrong password")
+
+// ErrSecretNotFound возвращается, если секрет не найден или принадлежит другому пользователю.
+var ErrSecretNotFound = fmt.Errorf("secret not found")
+
+// ErrSecretVersionNotFound возвращается, если версия секрета не найдена (или уже удалена по сроку хранения).
+var ErrSecretVersionNotFound = fmt.Errorf("secret version not found")
+
+// ErrFolderNotFound возвращается, если папка не найдена или принадлежит другому пользователю.
+var ErrFolderNotFound = fmt.Errorf("folder not found")
+
+// ErrFolderCycle возвращается при попытке переместить папку внутрь самой себя или своей вложенной папки.
+var ErrFolderCycle = fmt.Errorf("folder cannot be moved into itself or its descendant")
+
+// ErrFolderExists возвращается, если в родительской папке уже есть папка с таким названием.
+var ErrFolderExists = repository.ErrFolderExists
+
+// ErrInvalidCursor возвращается, если курсор постраничной выборки повреждён или выдан для другой сортировки.
+var ErrInvalidCursor = repository.ErrInvalidCursor
+
+// ErrBatchRolledBack возвращается для операций атомарного пакета, отменённых из-за ошибки другой операции.
+var ErrBatchRolledBack = repository.ErrBatchRolledBack
+
+// ErrIdempotencyKeyReused возвращается, если ключ идемпотентности уже использован для запроса с другим методом, путём или телом.
+var ErrIdempotencyKeyReused = fmt.Errorf("idempotency key reused with a different request")
+
+// ErrIdempotencyKeyInProgress возвращается, если запрос с тем же ключом идемпотентности ещё выполняется.
+var ErrIdempotencyKeyInProgress = fmt.Errorf("request with this idempotency key is in progress")
+
+// ErrWebhookNotFound возвращается, если вебхук не найден или принадлежит другому пользователю.
+var ErrWebhookNotFound = fmt.Errorf("webhook not found")
+
+// ErrDeviceCertsDisabled возвращается при выпуске сертификата устройства, если сертификаты устройств выключены.
+var ErrDeviceCertsDisabled = fmt.Errorf("device certificates are disabled")
+
+// ErrDeviceCertificateRequired возвращается при входе без запроса на сертификат, если сертификат устройства обязателен.
+var ErrDeviceCertificateRequired = fmt.Errorf("device certificate signing request is required")
+
+// ErrInvalidCSR возвращается, если запрос на сертификат устройства не разбирается или его подпись неверна.
+var ErrInvalidCSR = certs.ErrInvalidCSR
+
+// ErrDeviceCertificateNotFound возвращается, если сертификат устройства не найден или выдан другому пользователю.
+var ErrDeviceCertificateNotFound = fmt.Errorf("device certificate not found")
+
+// ErrDeviceCertificateUnknown возвращается, если клиент предъявил сертификат, который сервер не выдавал.
+var ErrDeviceCertificateUnknown = fmt.Errorf("device certificate is unknown")
+
+// ErrDeviceCertificateRevoked возвращается, если клиент предъявил отозванный сертификат устройства.
+var ErrDeviceCertificateRevoked = fmt.Errorf("device certificate is revoked")
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// DeleteByID перемещает секрет пользователя в корзину.
+// Секрет остаётся в корзине до восстановления, окончательного удаления или истечения срока хранения.
+func (s *SecretServiceImpl) DeleteByID(ctx context.Context, userID, id uint64) error {
+	ctx, span := tracing.Start(ctx, "SecretService.DeleteByID")
+	defer span.End()
+
+	var event models.SecretEventDTO
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteByID(ctx, userID, id); err != nil {
+			return err
+		}
+		var err error
+		event, err = s.record(ctx, models.SecretEventDeleted, userID, id, 0)
+		return err
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		s.logger.For(ctx).Warn("Секрет для удаления не найден", zap.Uint64("secret_id", id), zap.Uint64("user_id", userID))
+		return ErrSecretNotFound
+	}
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка при удалении секрета", zap.Uint64("secret_id", id), zap.Error(err))
+		return err
+	}
+	s.logger.For(ctx).Info("Секрет перемещён в корзину", zap.Uint64("secret_id", id))
+	s.publish(event)
+	return nil
+}
+
+// GetTrash возвращает секреты пользователя, находящиеся в корзине.
+func (s *SecretServiceImpl) GetTrash(ctx context.Context, userID uint64) ([]models.TrashedSecretDTO, error) {
+	ctx, span := tracing.Start(ctx, "SecretService.GetTrash")
+	defer span.End()
+
+	trash, err := s.repo.GetTrashByUser(ctx, userID)
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка при получении корзины", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	s.logger.For(ctx).Info("Корзина пользователя успешно получена", zap.Uint64("user_id", userID), zap.Int("count", len(trash)))
+	return trash, nil
+}
+
+// RestoreFromTrash возвращает секрет пользователя из корзины.
+func (s *SecretServiceImpl) RestoreFromTrash(ctx context.Context, userID, id uint64) error {
+	ctx, span := tracing.Start(ctx, "SecretService.RestoreFromTrash")
+	defer span.End()
+
+	var event models.SecretEventDTO
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.RestoreFromTrash(ctx, userID, id); err != nil {
+			return err
+		}
+		var err error
+		event, err = s.record(ctx, models.SecretEventRestored, userID, id, 0)
+		return err
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		s.logger.For(ctx).Warn("Секрет не найден в корзине", zap.Uint64("secret_id", id), zap.Uint64("user_id", userID))
+		return ErrSecretNotFound
+	}
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка при восстановлении секрета из корзины", zap.Uint64("secret_id", id), zap.Error(err))
+		return err
+	}
+	s.logger.For(ctx).Info("Секрет восстановлен из корзины", zap.Uint64("secret_id", id))
+	s.publish(event)
+	return nil
+}
+
+// Purge окончательно удаляет секрет пользователя из корзины.
+// Удалить таким образом можно только секрет, который уже находится в корзине.
+func (s *SecretServiceImpl) Purge(ctx context.Context, userID, id uint64) error {
+	ctx, span := tracing.Start(ctx, "SecretService.Purge")
+	defer span.End()
+
+	var event models.SecretEventDTO
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.PurgeByID(ctx, userID, id); err != nil {
+			return err
+		}
+		var err error
+		event, err = s.record(ctx, models.SecretEventPurged, userID, id, 0)
+		return err
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		s.logger.For(ctx).Warn("Секрет не найден в корзине", zap.Uint64("secret_id", id), zap.Uint64("user_id", userID))
+		return ErrSecretNotFound
+	}
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка при окончательном удалении секрета", zap.Uint64("secret_id", id), zap.Error(err))
+		return err
+	}
+	s.logger.For(ctx).Info("Секрет окончательно удалён", zap.Uint64("secret_id", id))
+	s.publish(event)
+	return nil
+}
+
+// TrashPurger периодически удаляет из корзины секреты с истёкшим сроком хранения,
+// а из журнала изменений секретов — записи старше cfg.SecretChangesRetention.
+type TrashPurger struct {
+	repo   repository.SecretRepository // Репозиторий секретов
+	cfg    *config.Config              // Конфигурация (сроки хранения и интервал очистки)
+	now    func() time.Time            // Источник текущего времени
+	logger *logger.Logger              // Логгер
+}
+
+// NewTrashPurger создаёт новый экземпляр фоновой очистки корзины.
+func NewTrashPurger(repo repository.SecretRepository, cfg *config.Config) *TrashPurger {
+	return &TrashPurger{
+		repo:   repo,
+		cfg:    cfg,
+		now:    time.Now,
+		logger: logger.NewLogger(),
+	}
+}
+
+// Run очищает корзину и журнал изменений сразу после запуска и затем с интервалом cfg.TrashPurgeInterval,
+// пока не будет отменён контекст. Если интервал не задан или не задан ни один из сроков хранения, сразу завершается.
+func (p *TrashPurger) Run(ctx context.Context) {
+	if (p.cfg.TrashRetention <= 0 && p.cfg.SecretChangesRetention <= 0) || p.cfg.TrashPurgeInterval <= 0 {
+		p.logger.Log.Info("Автоматическая очистка корзины отключена")
+		return
+	}
+
+	ticker := time.NewTicker(p.cfg.TrashPurgeInterval)
+	defer ticker.Stop()
+	for {
+		if p.cfg.TrashRetention > 0 {
+			_, _ = p.Purge(ctx)
+		}
+		if p.cfg.SecretChangesRetention > 0 {
+			_, _ = p.PurgeChanges(ctx)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge удаляет из корзины секреты, удалённые раньше, чем cfg.TrashRetention назад.
+// Возвращает количество удалённых секретов.
+func (p *TrashPurger) Purge(ctx context.Context) (int64, error) {
+	count, err := p.repo.PurgeDeletedBefore(ctx, p.now().Add(-p.cfg.TrashRetention))
+	if err != nil {
+		p.logger.Log.Error("Ошибка при очистке корзины", zap.Error(err))
+		return 0, err
+	}
+	if count > 0 {
+		p.logger.Log.Info("Из корзины удалены секреты с истёкшим сроком хранения", zap.Int64("count", count))
+	}
+	return count, nil
+}
+
+// PurgeChanges удаляет из журнала изменений секретов записи старше cfg.SecretChangesRetention.
+// Возвращает количество удалённых записей.
+func (p *TrashPurger) PurgeChanges(ctx context.Context) (int64, error) {
+	count, err := p.repo.PurgeChangesBefore(ctx, p.now().Add(-p.cfg.SecretChangesRetention))
+	if err != nil {
+		p.logger.Log.Error("Ошибка при очистке журнала изменений секретов", zap.Error(err))
+		return 0, err
+	}
+	if count > 0 {
+		p.logger.Log.Info("Из журнала изменений удалены устаревшие записи", zap.Int64("count", count))
+	}
+	return count, nil
+}
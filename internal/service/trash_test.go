@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/events"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+)
+
+func TestSecretServiceImpl_DeleteByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockSecretRepository(ctrl)
+	broker := events.NewBroker()
+	service := NewSecretServiceImpl(mockRepo, mocks.NewMockFolderRepository(ctrl), mocks.NewMockSecretAuditRepository(ctrl), passthroughTx{}, anyWebhooks(ctrl), broker)
+	feed, unsubscribe := broker.Subscribe(1)
+	defer unsubscribe()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo.EXPECT().DeleteByID(gomock.Any(), uint64(1), uint64(77)).Return(nil)
+		expectChange(mockRepo, models.SecretEventDeleted, 1, 77, 0, 30)
+		assert.NoError(t, service.DeleteByID(context.Background(), 1, 77))
+
+		event := <-feed
+		assert.Equal(t, models.SecretEventDeleted, event.Type)
+		assert.Equal(t, uint64(77), event.SecretID)
+		assert.Equal(t, uint64(30), event.ID)
+	})
+
+	t.Run("Not_found", func(t *testing.T) {
+		mockRepo.EXPECT().DeleteByID(gomock.Any(), uint64(1), uint64(78)).Return(repository.ErrNotFound)
+		assert.ErrorIs(t, service.DeleteByID(context.Background(), 1, 78), ErrSecretNotFound)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockRepo.EXPECT().DeleteByID(gomock.Any(), uint64(1), uint64(79)).Return(errors.New("delete error"))
+		assert.Error(t, service.DeleteByID(context.Background(), 1, 79))
+	})
+}
+
+func TestSecretServiceImpl_Trash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockSecretRepository(ctrl)
+	service := NewSecretServiceImpl(mockRepo, mocks.NewMockFolderRepository(ctrl), mocks.NewMockSecretAuditRepository(ctrl), passthroughTx{}, anyWebhooks(ctrl), events.NewBroker())
+
+	t.Run("GetTrash", func(t *testing.T) {
+		expected := []models.TrashedSecretDTO{{ID: 7, Title: "VPN", DeletedAt: time.Now()}}
+		mockRepo.EXPECT().GetTrashByUser(gomock.Any(), uint64(1)).Return(expected, nil)
+
+		trash, err := service.GetTrash(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, trash)
+
+		mockRepo.EXPECT().GetTrashByUser(gomock.Any(), uint64(1)).Return(nil, errors.New("db error"))
+		trash, err = service.GetTrash(context.Background(), 1)
+		assert.Error(t, err)
+		assert.Nil(t, trash)
+	})
+
+	t.Run("RestoreFromTrash", func(t *testing.T) {
+		mockRepo.EXPECT().RestoreFromTrash(gomock.Any(), uint64(1), uint64(7)).Return(nil)
+		expectChange(mockRepo, models.SecretEventRestored, 1, 7, 0, 31)
+		assert.NoError(t, service.RestoreFromTrash(context.Background(), 1, 7))
+
+		mockRepo.EXPECT().RestoreFromTrash(gomock.Any(), uint64(1), uint64(8)).Return(repository.ErrNotFound)
+		assert.ErrorIs(t, service.RestoreFromTrash(context.Background(), 1, 8), ErrSecretNotFound)
+
+		mockRepo.EXPECT().RestoreFromTrash(gomock.Any(), uint64(1), uint64(9)).Return(errors.New("db error"))
+		assert.Error(t, service.RestoreFromTrash(context.Background(), 1, 9))
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		mockRepo.EXPECT().PurgeByID(gomock.Any(), uint64(1), uint64(7)).Return(nil)
+		expectChange(mockRepo, models.SecretEventPurged, 1, 7, 0, 32)
+		assert.NoError(t, service.Purge(context.Background(), 1, 7))
+
+		mockRepo.EXPECT().PurgeByID(gomock.Any(), uint64(1), uint64(8)).Return(repository.ErrNotFound)
+		assert.ErrorIs(t, service.Purge(context.Background(), 1, 8), ErrSecretNotFound)
+
+		mockRepo.EXPECT().PurgeByID(gomock.Any(), uint64(1), uint64(9)).Return(errors.New("db error"))
+		assert.Error(t, service.Purge(context.Background(), 1, 9))
+	})
+}
+
+func TestTrashPurger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockSecretRepository(ctrl)
+	now := time.Date(2025, 7, 16, 12, 0, 0, 0, time.UTC)
+	cfg := &config.Config{TrashRetention: 24 * time.Hour, SecretChangesRetention: 48 * time.Hour, TrashPurgeInterval: time.Hour}
+	purger := &TrashPurger{repo: mockRepo, cfg: cfg, now: func() time.Time { return now }, logger: logger.NewLogger()}
+
+	t.Run("Purge", func(t *testing.T) {
+		mockRepo.EXPECT().PurgeDeletedBefore(gomock.Any(), now.Add(-24*time.Hour)).Return(int64(2), nil)
+
+		count, err := purger.Purge(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("Purge_error", func(t *testing.T) {
+		mockRepo.EXPECT().PurgeDeletedBefore(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("db error"))
+
+		_, err := purger.Purge(context.Background())
+		assert.Error(t, err)
+	})
+
+	t.Run("PurgeChanges", func(t *testing.T) {
+		mockRepo.EXPECT().PurgeChangesBefore(gomock.Any(), now.Add(-48*time.Hour)).Return(int64(3), nil)
+
+		count, err := purger.PurgeChanges(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+
+	t.Run("Run_stops_on_cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		mockRepo.EXPECT().PurgeDeletedBefore(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(context.Context, time.Time) (int64, error) {
+				return 0, nil
+			})
+		mockRepo.EXPECT().PurgeChangesBefore(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(context.Context, time.Time) (int64, error) {
+				cancel()
+				return 0, nil
+			})
+
+		done := make(chan struct{})
+		go func() {
+			purger.Run(ctx)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("purger did not stop after context cancel")
+		}
+	})
+
+	t.Run("Run_disabled", func(t *testing.T) {
+		disabled := &TrashPurger{repo: mockRepo, cfg: &config.Config{}, now: time.Now, logger: logger.NewLogger()}
+		disabled.Run(context.Background())
+	})
+}
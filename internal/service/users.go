@@ -9,6 +9,7 @@ import (
 	"github.com/shekshuev/gophkeeper/internal/logger"
 	"github.com/shekshuev/gophkeeper/internal/models"
 	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
 )
 
 // UserServiceImpl — реализация интерфейса UserService.
@@ -31,11 +32,14 @@ func NewUserServiceImpl(repo repository.UserRepository, cfg *config.Config) *Use
 // GetUserByID возвращает информацию о пользователе по его идентификатору.
 // Если пользователь не найден, возвращается ошибка.
 func (s *UserServiceImpl) GetUserByID(ctx context.Context, id uint64) (*models.ReadUserDTO, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUserByID")
+	defer span.End()
+
 	user, err := s.repo.GetUserByID(ctx, id)
 	if err != nil {
-		s.logger.Log.Error("Ошибка при получении пользователя по ID", zap.Uint64("user_id", id), zap.Error(err))
+		s.logger.For(ctx).Error("Ошибка при получении пользователя по ID", zap.Uint64("user_id", id), zap.Error(err))
 		return nil, err
 	}
-	s.logger.Log.Info("Пользователь успешно получен", zap.Uint64("user_id", user.ID))
+	s.logger.For(ctx).Info("Пользователь успешно получен", zap.Uint64("user_id", user.ID))
 	return user, nil
 }
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophkeeper/internal/events"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretServiceImpl_Update(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockSecretRepository(ctrl)
+	mockFolders := mocks.NewMockFolderRepository(ctrl)
+	service := NewSecretServiceImpl(mockRepo, mockFolders, mocks.NewMockSecretAuditRepository(ctrl), passthroughTx{}, anyWebhooks(ctrl), events.NewBroker())
+
+	t.Run("Success", func(t *testing.T) {
+		mockFolders.EXPECT().GetByID(gomock.Any(), uint64(1), uint64(3)).Return(&models.ReadFolderDTO{ID: 3}, nil)
+		mockRepo.EXPECT().
+			Update(gomock.Any(), models.UpdateSecretDTO{ID: 7, UserID: 1, Title: "VPN", FolderID: uptr(3), Tags: []string{"work"}, Device: "laptop"}).
+			Return(&models.ReadSecretDTO{ID: 7, Version: 2}, nil)
+		expectChange(mockRepo, models.SecretEventUpdated, 1, 7, 2, 40)
+
+		secret, err := service.Update(context.Background(), models.UpdateSecretDTO{
+			ID: 7, UserID: 1, Title: "VPN", FolderID: uptr(3), Tags: []string{" work ", "work"}, Device: "laptop",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, secret.Version)
+	})
+
+	t.Run("Folder_not_found", func(t *testing.T) {
+		mockFolders.EXPECT().GetByID(gomock.Any(), uint64(1), uint64(4)).Return(nil, repository.ErrNotFound)
+
+		secret, err := service.Update(context.Background(), models.UpdateSecretDTO{ID: 7, UserID: 1, FolderID: uptr(4)})
+		assert.ErrorIs(t, err, ErrFolderNotFound)
+		assert.Nil(t, secret)
+	})
+
+	t.Run("Secret_not_found", func(t *testing.T) {
+		mockRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil, repository.ErrNotFound)
+
+		secret, err := service.Update(context.Background(), models.UpdateSecretDTO{ID: 8, UserID: 1})
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+		assert.Nil(t, secret)
+	})
+}
+
+func TestSecretServiceImpl_GetVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockSecretRepository(ctrl)
+	mockAudit := mocks.NewMockSecretAuditRepository(ctrl)
+	service := NewSecretServiceImpl(mockRepo, mocks.NewMockFolderRepository(ctrl), mockAudit, passthroughTx{}, anyWebhooks(ctrl), events.NewBroker())
+	meta := models.RequestMetaDTO{IP: "10.0.0.1", UserAgent: "cli"}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo.EXPECT().GetVersion(gomock.Any(), uint64(1), uint64(7), 2).Return(&models.ReadSecretVersionDTO{SecretID: 7, Version: 2}, nil)
+		mockAudit.EXPECT().
+			Create(gomock.Any(), models.CreateSecretAuditDTO{UserID: 1, SecretID: 7, Action: models.SecretActionRevealVersion, IP: "10.0.0.1", UserAgent: "cli"}).
+			Return(nil)
+
+		version, err := service.GetVersion(context.Background(), 1, 7, 2, meta)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, version.Version)
+	})
+
+	t.Run("Not_found", func(t *testing.T) {
+		mockRepo.EXPECT().GetVersion(gomock.Any(), uint64(1), uint64(7), 9).Return(nil, repository.ErrNotFound)
+
+		version, err := service.GetVersion(context.Background(), 1, 7, 9, meta)
+		assert.ErrorIs(t, err, ErrSecretVersionNotFound)
+		assert.Nil(t, version)
+	})
+
+	t.Run("Audit_error", func(t *testing.T) {
+		mockRepo.EXPECT().GetVersion(gomock.Any(), uint64(1), uint64(7), 2).Return(&models.ReadSecretVersionDTO{SecretID: 7, Version: 2}, nil)
+		mockAudit.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+
+		version, err := service.GetVersion(context.Background(), 1, 7, 2, meta)
+		assert.Error(t, err)
+		assert.Nil(t, version)
+	})
+}
+
+func TestSecretServiceImpl_Restore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockSecretRepository(ctrl)
+	service := NewSecretServiceImpl(mockRepo, mocks.NewMockFolderRepository(ctrl), mocks.NewMockSecretAuditRepository(ctrl), passthroughTx{}, anyWebhooks(ctrl), events.NewBroker())
+	old := &models.ReadSecretVersionDTO{
+		SecretID: 7,
+		Version:  2,
+		Title:    "VPN",
+		Data:     models.SecretDataDTO{Text: ptr("old password")},
+		FolderID: uptr(3),
+		Tags:     []string{"work"},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo.EXPECT().GetVersion(gomock.Any(), uint64(1), uint64(7), 2).Return(old, nil)
+		mockRepo.EXPECT().
+			Update(gomock.Any(), models.UpdateSecretDTO{
+				ID: 7, UserID: 1, Title: "VPN", Data: old.Data, FolderID: uptr(3), Tags: []string{"work"}, Device: "phone",
+			}).
+			Return(&models.ReadSecretDTO{ID: 7, Version: 6}, nil)
+		expectChange(mockRepo, models.SecretEventUpdated, 1, 7, 6, 41)
+
+		secret, err := service.Restore(context.Background(), 1, 7, 2, "phone")
+		assert.NoError(t, err)
+		assert.Equal(t, 6, secret.Version)
+	})
+
+	t.Run("Version_not_found", func(t *testing.T) {
+		mockRepo.EXPECT().GetVersion(gomock.Any(), uint64(1), uint64(7), 9).Return(nil, repository.ErrNotFound)
+
+		secret, err := service.Restore(context.Background(), 1, 7, 9, "phone")
+		assert.ErrorIs(t, err, ErrSecretVersionNotFound)
+		assert.Nil(t, secret)
+	})
+
+	t.Run("Update_error", func(t *testing.T) {
+		mockRepo.EXPECT().GetVersion(gomock.Any(), uint64(1), uint64(7), 2).Return(old, nil)
+		mockRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil, errors.New("db error"))
+
+		secret, err := service.Restore(context.Background(), 1, 7, 2, "phone")
+		assert.Error(t, err)
+		assert.Nil(t, secret)
+	})
+}
+
+func TestSecretServiceImpl_GetVersions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockSecretRepository(ctrl)
+	service := NewSecretServiceImpl(mockRepo, mocks.NewMockFolderRepository(ctrl), mocks.NewMockSecretAuditRepository(ctrl), passthroughTx{}, anyWebhooks(ctrl), events.NewBroker())
+
+	mockRepo.EXPECT().GetVersions(gomock.Any(), uint64(1), uint64(7)).Return([]models.SecretVersionDTO{{Version: 1}}, nil)
+	versions, err := service.GetVersions(context.Background(), 1, 7)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 1)
+
+	mockRepo.EXPECT().GetVersions(gomock.Any(), uint64(1), uint64(8)).Return(nil, errors.New("db error"))
+	versions, err = service.GetVersions(context.Background(), 1, 8)
+	assert.Error(t, err)
+	assert.Nil(t, versions)
+}
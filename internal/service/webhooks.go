@@ -0,0 +1,316 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/logger"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+	"github.com/shekshuev/gophkeeper/internal/tracing"
+)
+
+// Заголовки запроса доставки вебхука.
+const (
+	WebhookEventHeader     = "X-GophKeeper-Event"     // Событие (см. models.WebhookEvent*)
+	WebhookDeliveryHeader  = "X-GophKeeper-Delivery"  // ID доставки: одинаков у повторных попыток, по нему получатель отбрасывает дубликаты
+	WebhookTimestampHeader = "X-GophKeeper-Timestamp" // Время отправки (Unix, секунды)
+	WebhookSignatureHeader = "X-GophKeeper-Signature" // Подпись "sha256=<hex>", см. SignWebhook
+)
+
+// Параметры доставки вебхуков.
+const (
+	webhookClaimBatch      = 50               // Сколько доставок отправитель берёт в работу за раз
+	webhookDeliveriesLimit = 100              // Сколько последних доставок возвращает журнал
+	webhookRetryMin        = 30 * time.Second // Пауза перед первой повторной попыткой
+	webhookRetryMax        = time.Hour        // Максимальная пауза между попытками
+	webhookErrorMaxLen     = 255              // Максимальная длина текста ошибки в журнале
+)
+
+// errWebhookAddressForbidden — адрес получателя вебхука находится во внутренней сети.
+var errWebhookAddressForbidden = errors.New("webhook address is not allowed")
+
+// WebhookServiceImpl реализует WebhookService.
+// События ставятся в очередь доставок в базе в той же транзакции, что и изменение, о котором они сообщают,
+// а Run рассылает их получателям: неудачные попытки повторяются с растущей паузой, пока не будет исчерпано cfg.WebhookMaxAttempts.
+type WebhookServiceImpl struct {
+	repo   repository.WebhookRepository // Репозиторий вебхуков и очереди доставок
+	cfg    *config.Config               // Конфигурация (число попыток, интервал опроса, таймаут)
+	client *http.Client                 // HTTP-клиент для доставки
+	logger *logger.Logger               // Логгер
+}
+
+// NewWebhookServiceImpl создаёт новый экземпляр сервиса вебхуков.
+func NewWebhookServiceImpl(repo repository.WebhookRepository, cfg *config.Config) *WebhookServiceImpl {
+	return &WebhookServiceImpl{
+		repo:   repo,
+		cfg:    cfg,
+		client: newWebhookClient(cfg.WebhookTimeout),
+		logger: logger.NewLogger(),
+	}
+}
+
+// newWebhookClient создаёт HTTP-клиент доставки вебхуков. URL вебхука задаёт пользователь, поэтому клиент
+// не соединяется с адресами внутренней сети (см. checkWebhookAddress) и не следует перенаправлениям:
+// ответ с перенаправлением считается неудачной попыткой. Адрес проверяется при соединении, уже после
+// разрешения имени, поэтому его не обойти DNS-записью, указывающей во внутреннюю сеть. Прокси не используется.
+func newWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: checkWebhookAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// forbiddenWebhookPrefixes — диапазоны IPv4, которые не покрывают проверки netip.Addr, но часто ведут
+// во внутреннюю сеть: «эта сеть» 0.0.0.0/8 (RFC 1122) и общее адресное пространство
+// операторского NAT 100.64.0.0/10 (RFC 6598).
+var forbiddenWebhookPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// checkWebhookAddress запрещает соединения с loopback-, частными (RFC 1918, RFC 4193), link-local
+// (в том числе 169.254.169.254 — метаданные облака), групповыми и неуказанными адресами, а также
+// с диапазонами forbiddenWebhookPrefixes. Адреса IPv4, отображённые в IPv6 (::ffff:a.b.c.d), проверяются как IPv4.
+// Вызывается как net.Dialer.Control для каждого адреса, с которым устанавливается соединение.
+func checkWebhookAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("%w: %s", errWebhookAddressForbidden, ip)
+	}
+	for _, prefix := range forbiddenWebhookPrefixes {
+		if prefix.Contains(ip) {
+			return fmt.Errorf("%w: %s", errWebhookAddressForbidden, ip)
+		}
+	}
+	return nil
+}
+
+// Create регистрирует вебхук пользователя и генерирует для него ключ подписи.
+// Ключ возвращается только в ответе на регистрацию.
+func (s *WebhookServiceImpl) Create(ctx context.Context, dto models.CreateWebhookDTO) (*models.ReadWebhookDTO, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.Create")
+	defer span.End()
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		s.logger.For(ctx).Error("Не удалось сгенерировать ключ подписи вебхука", zap.Error(err))
+		return nil, err
+	}
+	webhook, err := s.repo.Create(ctx, dto, hex.EncodeToString(key))
+	if err != nil {
+		s.logger.For(ctx).Error("Не удалось создать вебхук", zap.Uint64("user_id", dto.UserID), zap.Error(err))
+		return nil, err
+	}
+	s.logger.For(ctx).Info("Вебхук успешно создан", zap.Uint64("webhook_id", webhook.ID), zap.Uint64("user_id", dto.UserID))
+	return webhook, nil
+}
+
+// GetAllByUser возвращает вебхуки пользователя.
+func (s *WebhookServiceImpl) GetAllByUser(ctx context.Context, userID uint64) ([]models.ReadWebhookDTO, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.GetAllByUser")
+	defer span.End()
+
+	webhooks, err := s.repo.GetAllByUser(ctx, userID)
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка при получении вебхуков пользователя", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// DeleteByID удаляет вебхук пользователя.
+func (s *WebhookServiceImpl) DeleteByID(ctx context.Context, userID, id uint64) error {
+	ctx, span := tracing.Start(ctx, "WebhookService.DeleteByID")
+	defer span.End()
+
+	err := s.repo.DeleteByID(ctx, userID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrWebhookNotFound
+	}
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка при удалении вебхука", zap.Uint64("webhook_id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetDeliveries возвращает последние доставки вебхука пользователя.
+func (s *WebhookServiceImpl) GetDeliveries(ctx context.Context, userID, id uint64) ([]models.WebhookDeliveryDTO, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.GetDeliveries")
+	defer span.End()
+
+	deliveries, err := s.repo.GetDeliveries(ctx, userID, id, webhookDeliveriesLimit)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка при получении журнала доставок вебхука", zap.Uint64("webhook_id", id), zap.Error(err))
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// Notify ставит событие в очередь доставки всем вебхукам пользователя, подписанным на него.
+// Внутри TxManager.WithinTx доставки создаются в транзакции вызывающего.
+func (s *WebhookServiceImpl) Notify(ctx context.Context, userID uint64, event string, data any) error {
+	ctx, span := tracing.Start(ctx, "WebhookService.Notify")
+	defer span.End()
+
+	payload, err := json.Marshal(models.WebhookPayloadDTO{Event: event, UserID: userID, Time: time.Now().UTC(), Data: data})
+	if err != nil {
+		s.logger.For(ctx).Error("Ошибка сериализации события вебхука", zap.String("event", event), zap.Error(err))
+		return err
+	}
+	count, err := s.repo.Enqueue(ctx, userID, event, payload)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		s.logger.For(ctx).Info("Событие поставлено в очередь вебхуков", zap.Uint64("user_id", userID), zap.String("event", event), zap.Int64("deliveries", count))
+	}
+	return nil
+}
+
+// Run раз в cfg.WebhookPollInterval отправляет доставки из очереди, время попытки которых наступило, пока ctx не отменён.
+func (s *WebhookServiceImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.WebhookPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.deliverDue(ctx)
+		}
+	}
+}
+
+// deliverDue отправляет доставки из очереди пачками, пока очередь готовых доставок не опустеет.
+func (s *WebhookServiceImpl) deliverDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		tasks, err := s.repo.ClaimDue(ctx, webhookClaimBatch, 2*s.cfg.WebhookTimeout)
+		if err != nil {
+			s.logger.For(ctx).Error("Не удалось получить доставки вебхуков", zap.Error(err))
+			return
+		}
+		var wg sync.WaitGroup
+		for _, task := range tasks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.deliver(ctx, task)
+			}()
+		}
+		wg.Wait()
+		if len(tasks) < webhookClaimBatch {
+			return
+		}
+	}
+}
+
+// deliver выполняет одну попытку доставки и сохраняет её результат.
+func (s *WebhookServiceImpl) deliver(ctx context.Context, task models.WebhookTaskDTO) {
+	attempt := s.attempt(ctx, task)
+	switch {
+	case attempt.Status == models.WebhookDeliveryDelivered:
+		s.logger.For(ctx).Info("Вебхук доставлен", zap.Uint64("delivery_id", task.ID), zap.Int("status", attempt.ResponseStatus))
+	case task.Attempts+1 >= s.cfg.WebhookMaxAttempts:
+		attempt.Status = models.WebhookDeliveryFailed
+		s.logger.For(ctx).Warn("Попытки доставки вебхука исчерпаны", zap.Uint64("delivery_id", task.ID), zap.String("error", attempt.Error))
+	default:
+		attempt.Status = models.WebhookDeliveryPending
+		attempt.NextAttemptAt = time.Now().Add(webhookRetryDelay(task.Attempts + 1))
+		s.logger.For(ctx).Warn("Вебхук не доставлен, попытка будет повторена", zap.Uint64("delivery_id", task.ID), zap.Time("next_attempt_at", attempt.NextAttemptAt), zap.String("error", attempt.Error))
+	}
+	if runes := []rune(attempt.Error); len(runes) > webhookErrorMaxLen {
+		attempt.Error = string(runes[:webhookErrorMaxLen])
+	}
+	if err := s.repo.SaveAttempt(context.WithoutCancel(ctx), task.ID, attempt); err != nil {
+		s.logger.For(ctx).Error("Не удалось сохранить результат доставки вебхука", zap.Uint64("delivery_id", task.ID), zap.Error(err))
+	}
+}
+
+// attempt отправляет запрос получателю. Доставка успешна, если получатель ответил статусом 2xx.
+func (s *WebhookServiceImpl) attempt(ctx context.Context, task models.WebhookTaskDTO) models.WebhookAttemptDTO {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, task.URL, bytes.NewReader(task.Payload))
+	if err != nil {
+		return models.WebhookAttemptDTO{Error: err.Error()}
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, task.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(task.ID, 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(task.Secret, timestamp, task.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return models.WebhookAttemptDTO{Error: err.Error()}
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	result := models.WebhookAttemptDTO{ResponseStatus: resp.StatusCode}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		result.Status = models.WebhookDeliveryDelivered
+	} else {
+		result.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return result
+}
+
+// SignWebhook возвращает значение заголовка X-GophKeeper-Signature:
+// HMAC-SHA256 по строке "<timestamp>.<тело запроса>" с ключом вебхука.
+// Получатель проверяет подпись тем же способом и отклоняет запросы со слишком старым timestamp.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookRetryDelay возвращает паузу перед повторной попыткой после attempts неудачных:
+// 30s, 1m, 2m, 4m… но не больше часа.
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := webhookRetryMin
+	for i := 1; i < attempts && delay < webhookRetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookRetryMax)
+}
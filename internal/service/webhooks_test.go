@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+	"github.com/shekshuev/gophkeeper/internal/mocks"
+	"github.com/shekshuev/gophkeeper/internal/models"
+	"github.com/shekshuev/gophkeeper/internal/repository"
+)
+
+func TestWebhookServiceImpl_CRUD(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockWebhookRepository(ctrl)
+	svc := NewWebhookServiceImpl(repo, &config.Config{})
+	ctx := context.Background()
+	dto := models.CreateWebhookDTO{UserID: 1, URL: "https://example.com/hook", Events: []string{models.WebhookEventSecretChanged}}
+
+	t.Run("Create_generates_key", func(t *testing.T) {
+		var key string
+		repo.EXPECT().Create(ctx, dto, gomock.Any()).DoAndReturn(
+			func(_ context.Context, dto models.CreateWebhookDTO, secret string) (*models.ReadWebhookDTO, error) {
+				key = secret
+				return &models.ReadWebhookDTO{ID: 3, UserID: dto.UserID, URL: dto.URL, Events: dto.Events, Secret: secret}, nil
+			})
+
+		webhook, err := svc.Create(ctx, dto)
+		require.NoError(t, err)
+		assert.Len(t, key, 64)
+		assert.Equal(t, key, webhook.Secret)
+	})
+
+	t.Run("Delete_not_found", func(t *testing.T) {
+		repo.EXPECT().DeleteByID(ctx, uint64(1), uint64(3)).Return(repository.ErrNotFound)
+
+		assert.ErrorIs(t, svc.DeleteByID(ctx, 1, 3), ErrWebhookNotFound)
+	})
+
+	t.Run("Deliveries_not_found", func(t *testing.T) {
+		repo.EXPECT().GetDeliveries(ctx, uint64(1), uint64(3), webhookDeliveriesLimit).Return(nil, repository.ErrNotFound)
+
+		_, err := svc.GetDeliveries(ctx, 1, 3)
+		assert.ErrorIs(t, err, ErrWebhookNotFound)
+	})
+
+	t.Run("Notify", func(t *testing.T) {
+		repo.EXPECT().Enqueue(ctx, uint64(1), models.WebhookEventLoginNewDevice, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ uint64, _ string, payload []byte) (int64, error) {
+				assert.JSONEq(t, `{"device":"phone"}`, string(decodePayload(t, payload).Data))
+				return 1, nil
+			})
+
+		assert.NoError(t, svc.Notify(ctx, 1, models.WebhookEventLoginNewDevice, models.LoginAlertDTO{Device: "phone"}))
+	})
+}
+
+func TestWebhookServiceImpl_DeliverDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	payload := []byte(`{"event":"secret.changed","user_id":1}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(WebhookTimestampHeader), 10, 64)
+		assert.Equal(t, payload, body)
+		assert.Equal(t, models.WebhookEventSecretChanged, r.Header.Get(WebhookEventHeader))
+		assert.Equal(t, SignWebhook("key", timestamp, body), r.Header.Get(WebhookSignatureHeader))
+		switch r.Header.Get(WebhookDeliveryHeader) {
+		case "1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	repo := mocks.NewMockWebhookRepository(ctrl)
+	cfg := &config.Config{WebhookMaxAttempts: 3, WebhookTimeout: time.Second}
+	svc := NewWebhookServiceImpl(repo, cfg)
+	svc.client = server.Client() // Тестовый сервер слушает loopback, куда клиент по умолчанию не соединяется.
+	ctx := context.Background()
+	task := func(id uint64, attempts int) models.WebhookTaskDTO {
+		return models.WebhookTaskDTO{ID: id, URL: server.URL, Secret: "key", Event: models.WebhookEventSecretChanged, Payload: payload, Attempts: attempts}
+	}
+
+	repo.EXPECT().ClaimDue(ctx, webhookClaimBatch, 2*time.Second).Return([]models.WebhookTaskDTO{task(1, 0), task(2, 0), task(3, 2)}, nil)
+	repo.EXPECT().SaveAttempt(gomock.Any(), uint64(1), models.WebhookAttemptDTO{Status: models.WebhookDeliveryDelivered, ResponseStatus: http.StatusNoContent})
+	repo.EXPECT().SaveAttempt(gomock.Any(), uint64(2), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ uint64, attempt models.WebhookAttemptDTO) error {
+			assert.Equal(t, models.WebhookDeliveryPending, attempt.Status)
+			assert.Equal(t, http.StatusInternalServerError, attempt.ResponseStatus)
+			assert.WithinDuration(t, time.Now().Add(webhookRetryMin), attempt.NextAttemptAt, 5*time.Second)
+			return nil
+		})
+	repo.EXPECT().SaveAttempt(gomock.Any(), uint64(3), models.WebhookAttemptDTO{Status: models.WebhookDeliveryFailed, ResponseStatus: http.StatusInternalServerError, Error: "unexpected status 500"})
+
+	svc.deliverDue(ctx)
+}
+
+func TestWebhookServiceImpl_Attempt_InternalAddress(t *testing.T) {
+	redirect := httptest.NewServer(http.RedirectHandler("https://example.com/hook", http.StatusFound))
+	defer redirect.Close()
+
+	svc := NewWebhookServiceImpl(nil, &config.Config{WebhookTimeout: time.Second})
+	payload := []byte(`{}`)
+
+	t.Run("Loopback", func(t *testing.T) {
+		attempt := svc.attempt(context.Background(), models.WebhookTaskDTO{ID: 1, URL: redirect.URL, Payload: payload})
+		assert.Equal(t, models.WebhookAttemptDTO{Error: attempt.Error}, attempt)
+		assert.Contains(t, attempt.Error, errWebhookAddressForbidden.Error())
+	})
+
+	t.Run("Redirect_not_followed", func(t *testing.T) {
+		svc.client.Transport = redirect.Client().Transport
+		attempt := svc.attempt(context.Background(), models.WebhookTaskDTO{ID: 1, URL: redirect.URL, Payload: payload})
+		assert.Equal(t, http.StatusFound, attempt.ResponseStatus)
+		assert.Equal(t, "unexpected status 302", attempt.Error)
+	})
+}
+
+func TestCheckWebhookAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.215.14:443", true},
+		{"[2606:2800:21f:cb07:6820:80da:af6b:8b2c]:443", true},
+		{"127.0.0.1:80", false},
+		{"[::1]:80", false},
+		{"10.0.0.5:80", false},
+		{"172.16.1.1:80", false},
+		{"192.168.1.1:80", false},
+		{"169.254.169.254:80", false},
+		{"[fe80::1]:80", false},
+		{"[fd00::1]:80", false},
+		{"[::ffff:127.0.0.1]:80", false},
+		{"0.0.0.0:80", false},
+		{"0.1.2.3:80", false},
+		{"[::ffff:0.1.2.3]:80", false},
+		{"100.64.0.1:80", false},
+		{"100.127.255.254:80", false},
+		{"[::ffff:100.100.100.200]:80", false},
+		{"[::ffff:10.0.0.5]:80", false},
+		{"[::ffff:169.254.169.254]:80", false},
+		{"100.63.255.255:80", true},
+		{"100.128.0.1:80", true},
+		{"[::ffff:93.184.215.14]:443", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := checkWebhookAddress("tcp", tt.address, nil)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, errWebhookAddressForbidden)
+			}
+		})
+	}
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhookRetryDelay(1))
+	assert.Equal(t, time.Minute, webhookRetryDelay(2))
+	assert.Equal(t, 4*time.Minute, webhookRetryDelay(4))
+	assert.Equal(t, time.Hour, webhookRetryDelay(20))
+}
+
+// decodePayload разбирает тело доставки вебхука, оставляя подробности события в JSON.
+func decodePayload(t *testing.T, payload []byte) struct{ Data json.RawMessage } {
+	t.Helper()
+	var decoded struct{ Data json.RawMessage }
+	require.NoError(t, json.Unmarshal(payload, &decoded))
+	return decoded
+}
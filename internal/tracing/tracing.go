@@ -0,0 +1,111 @@
+// Package tracing настраивает трассировку OpenTelemetry и содержит помощники для создания спанов
+// в обработчиках, сервисах и репозиториях. Контекст трассировки передаётся между слоями через
+// context.Context, а между клиентом и сервером — в заголовках W3C Trace Context (traceparent).
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+)
+
+// Экспортёры спанов, которые можно выбрать в config.Config.TracingExporter.
+const (
+	ExporterNone   = ""       // Трассировка выключена
+	ExporterStdout = "stdout" // Спаны печатаются в стандартный вывод в формате JSON
+	ExporterOTLP   = "otlp"   // Спаны отправляются коллектору по OTLP/HTTP
+)
+
+// instrumentationName — имя, под которым создаются спаны приложения.
+const instrumentationName = "github.com/shekshuev/gophkeeper"
+
+// ErrUnknownExporter возвращается, если в конфигурации указан неизвестный экспортёр.
+var ErrUnknownExporter = errors.New("unknown tracing exporter")
+
+// enabled выставляется, когда Setup настроил экспортёр. Пока трассировка выключена,
+// Start и StartDB не создают спанов и возвращают исходный контекст.
+var enabled atomic.Bool
+
+// Setup настраивает глобальный провайдер трассировки и распространение W3C Trace Context.
+// service — имя сервиса в спанах (например, "gophkeeper-server"), version — версия сборки.
+// Возвращает функцию, которая выгружает накопленные спаны и останавливает провайдер.
+// Если экспортёр не указан, трассировка остаётся выключенной.
+func Setup(ctx context.Context, cfg *config.Config, service, version string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch cfg.TracingExporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.TracingEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.TracingEndpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, ErrUnknownExporter
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(service),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, err
+	}
+	return install(sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)), nil
+}
+
+// install делает provider глобальным провайдером трассировки и включает создание спанов.
+// Возвращает функцию остановки провайдера.
+func install(provider *sdktrace.TracerProvider) func(context.Context) error {
+	otel.SetTracerProvider(provider)
+	enabled.Store(true)
+
+	return func(ctx context.Context) error {
+		enabled.Store(false)
+		return provider.Shutdown(ctx)
+	}
+}
+
+// Start начинает спан name, дочерний по отношению к спану из ctx.
+// Спан нужно завершить вызовом span.End(). Когда трассировка выключена, возвращает ctx без изменений.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if !enabled.Load() {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// StartDB начинает спан запроса к базе данных. В спан попадает только имя запроса
+// (например, "secrets.create"), но не его параметры: они могут содержать данные пользователя.
+func StartDB(ctx context.Context, query string) (context.Context, trace.Span) {
+	return Start(ctx, "db "+query,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationName(query)),
+	)
+}
@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/shekshuev/gophkeeper/internal/config"
+)
+
+func TestSetup(t *testing.T) {
+	shutdown, err := Setup(context.Background(), &config.Config{}, "gophkeeper-test", "dev")
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	_, err = Setup(context.Background(), &config.Config{TracingExporter: "zipkin"}, "gophkeeper-test", "dev")
+	assert.ErrorIs(t, err, ErrUnknownExporter)
+}
+
+func TestStart_disabled(t *testing.T) {
+	ctx := context.Background()
+	spanCtx, span := Start(ctx, "SecretServiceImpl.Create")
+	defer span.End()
+
+	assert.Equal(t, ctx, spanCtx)
+	assert.False(t, span.IsRecording())
+}
+
+func TestStart_layers(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	shutdown := install(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer shutdown(context.Background())
+
+	ctx, request := Start(context.Background(), "POST /v1.0/secrets/", trace.WithSpanKind(trace.SpanKindServer))
+	ctx, svc := Start(ctx, "SecretServiceImpl.Create")
+	_, db := StartDB(ctx, "secrets.create")
+	db.End()
+	svc.End()
+	request.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "db secrets.create", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), semconv.DBOperationName("secrets.create"))
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, spans[2].SpanContext().SpanID(), spans[1].Parent().SpanID())
+	assert.Equal(t, spans[2].SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+}
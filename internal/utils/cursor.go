@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidCursor возвращается, если курсор постраничной выборки не удалось декодировать.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor кодирует позицию постраничной выборки в непрозрачную строку (JSON в base64url),
+// пригодную для передачи в query-параметре.
+func EncodeCursor(v any) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// DecodeCursor декодирует строку, полученную от EncodeCursor, в v.
+// Возвращает ErrInvalidCursor, если строка повреждена.
+func DecodeCursor(s string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeDecodeCursor(t *testing.T) {
+	type cursor struct {
+		Value string `json:"v"`
+		ID    uint64 `json:"id"`
+	}
+	testCases := []struct {
+		name     string
+		encoded  string
+		expected *cursor
+	}{
+		{
+			name:     "Round trip",
+			expected: &cursor{Value: "Тест & 100%", ID: 42},
+		},
+		{
+			name:    "Broken base64",
+			encoded: "***",
+		},
+		{
+			name:    "Not a JSON",
+			encoded: "bm90LWpzb24",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encoded := tc.encoded
+			if tc.expected != nil {
+				var err error
+				encoded, err = EncodeCursor(tc.expected)
+				assert.NoError(t, err)
+				assert.NotContains(t, encoded, "=")
+			}
+			var got cursor
+			err := DecodeCursor(encoded, &got)
+			if tc.expected == nil {
+				assert.ErrorIs(t, err, ErrInvalidCursor)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, *tc.expected, got)
+			}
+		})
+	}
+}
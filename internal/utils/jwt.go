@@ -5,11 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
 )
 
 // ContextKey используется для ключей в context.Context.
@@ -24,6 +27,9 @@ const (
 
 	// ContextClaimsKey — ключ для хранения JWT claims в context.Context.
 	ContextClaimsKey = ContextKey("user-claims")
+
+	// ContextDeviceKey — ключ для хранения сертификата устройства, предъявленного клиентом, в context.Context.
+	ContextDeviceKey = ContextKey("device-certificate")
 )
 
 var (
@@ -35,8 +41,35 @@ var (
 
 	// ErrTokenInvalid возвращается, если токен невалиден по другим причинам.
 	ErrTokenInvalid = fmt.Errorf("token is invalid")
+
+	// ErrTokenDeviceMismatch возвращается, если токен привязан к сертификату, который клиент не предъявил.
+	ErrTokenDeviceMismatch = fmt.Errorf("token is bound to another device certificate")
+
+	// ErrTokenNotBound возвращается, если сервер требует токены, привязанные к сертификату устройства.
+	ErrTokenNotBound = fmt.Errorf("token is not bound to a device certificate")
 )
 
+// Claims — утверждения JWT-токенов GophKeeper.
+type Claims struct {
+	jwt.RegisteredClaims
+	// Confirmation привязывает токен к сертификату устройства (RFC 8705): токен принимается
+	// только от клиента, предъявившего этот сертификат при установке TLS-соединения.
+	Confirmation *Confirmation `json:"cnf,omitempty"`
+}
+
+// Confirmation — сертификат, к которому привязан токен.
+type Confirmation struct {
+	CertThumbprint string `json:"x5t#S256"` // Отпечаток сертификата SHA-256 в base64url (см. certs.Thumbprint)
+}
+
+// CertThumbprint возвращает отпечаток сертификата, к которому привязан токен, или пустую строку.
+func (c Claims) CertThumbprint() string {
+	if c.Confirmation == nil {
+		return ""
+	}
+	return c.Confirmation.CertThumbprint
+}
+
 // GetRawAccessToken извлекает access-токен из заголовка Authorization.
 // Ожидается формат: "Authorization: Bearer <token>".
 func GetRawAccessToken(req *http.Request) (string, error) {
@@ -59,23 +92,34 @@ func GetRawRefreshToken(req *http.Request) (string, error) {
 // CreateToken создаёт JWT-токен с указанным userId, сроком жизни и секретом.
 // Возвращает подписанную строку токена.
 func CreateToken(secret, userId string, exp time.Duration) (string, error) {
+	return CreateBoundToken(secret, userId, "", exp)
+}
+
+// CreateBoundToken создаёт JWT-токен, привязанный к сертификату устройства с отпечатком certThumbprint
+// (см. Claims.Confirmation). Пустой отпечаток создаёт обычный токен.
+func CreateBoundToken(secret, userId, certThumbprint string, exp time.Duration) (string, error) {
 	if secret == "" {
 		return "", errors.New("secret cannot be empty")
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
-		Issuer:    "Gophkeeper",
-		Subject:   userId,
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(exp)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		ID:        uuid.New().String(),
-	})
-	return token.SignedString([]byte(secret))
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "Gophkeeper",
+			Subject:   userId,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(exp)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        uuid.New().String(),
+		},
+	}
+	if certThumbprint != "" {
+		claims.Confirmation = &Confirmation{CertThumbprint: certThumbprint}
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
 }
 
 // GetToken парсит и валидирует JWT по строке и секрету.
 // Возвращает claims или соответствующую ошибку.
-func GetToken(tokenString, secret string) (*jwt.RegisteredClaims, error) {
-	claims := &jwt.RegisteredClaims{}
+func GetToken(tokenString, secret string) (*Claims, error) {
+	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
 		return []byte(secret), nil
 	})
@@ -100,12 +144,41 @@ func GetToken(tokenString, secret string) (*jwt.RegisteredClaims, error) {
 }
 
 // GetClaimsFromContext извлекает JWT claims из context.Context.
-func GetClaimsFromContext(ctx context.Context) (jwt.RegisteredClaims, bool) {
-	claims, ok := ctx.Value(ContextClaimsKey).(jwt.RegisteredClaims)
+func GetClaimsFromContext(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(ContextClaimsKey).(Claims)
 	return claims, ok
 }
 
 // PutClaimsToContext сохраняет JWT claims в context.Context.
-func PutClaimsToContext(ctx context.Context, claims jwt.RegisteredClaims) context.Context {
+func PutClaimsToContext(ctx context.Context, claims Claims) context.Context {
 	return context.WithValue(ctx, ContextClaimsKey, claims)
 }
+
+// GetDeviceFromContext извлекает из context.Context действующий сертификат устройства, предъявленный клиентом.
+func GetDeviceFromContext(ctx context.Context) (models.DeviceCertificateDTO, bool) {
+	device, ok := ctx.Value(ContextDeviceKey).(models.DeviceCertificateDTO)
+	return device, ok
+}
+
+// PutDeviceToContext сохраняет сертификат устройства в context.Context.
+func PutDeviceToContext(ctx context.Context, device models.DeviceCertificateDTO) context.Context {
+	return context.WithValue(ctx, ContextDeviceKey, device)
+}
+
+// CheckDevice проверяет, что токен с claims предъявило устройство, к которому он привязан:
+// сертификат устройства из context.Context (см. PutDeviceToContext) должен совпадать с сертификатом в токене.
+// Если requireDevice равен true, токены без привязки к сертификату не принимаются.
+func CheckDevice(ctx context.Context, claims Claims, requireDevice bool) error {
+	thumbprint := claims.CertThumbprint()
+	if thumbprint == "" {
+		if requireDevice {
+			return ErrTokenNotBound
+		}
+		return nil
+	}
+	device, ok := GetDeviceFromContext(ctx)
+	if !ok || device.Thumbprint != thumbprint || strconv.FormatUint(device.UserID, 10) != claims.Subject {
+		return ErrTokenDeviceMismatch
+	}
+	return nil
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/shekshuev/gophkeeper/internal/models"
 )
 
 func TestCreateToken(t *testing.T) {
@@ -31,6 +33,23 @@ func TestCreateToken(t *testing.T) {
 	assert.Equal(t, userId, claims["sub"])
 }
 
+func TestCreateBoundToken(t *testing.T) {
+	token, err := CreateBoundToken("testsecret", "12345", "thumbprint", time.Hour)
+	assert.NoError(t, err)
+
+	claims, err := GetToken(token, "testsecret")
+	assert.NoError(t, err)
+	assert.Equal(t, "12345", claims.Subject)
+	assert.Equal(t, "thumbprint", claims.CertThumbprint())
+
+	token, err = CreateToken("testsecret", "12345", time.Hour)
+	assert.NoError(t, err)
+	claims, err = GetToken(token, "testsecret")
+	assert.NoError(t, err)
+	assert.Nil(t, claims.Confirmation)
+	assert.Empty(t, claims.CertThumbprint())
+}
+
 func TestGetToken(t *testing.T) {
 	secret := "testsecret"
 	userId := "12345"
@@ -101,7 +120,7 @@ func TestGetRawRefreshToken(t *testing.T) {
 }
 
 func TestContextClaims(t *testing.T) {
-	claims := jwt.RegisteredClaims{Subject: "user123"}
+	claims := Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user123"}}
 
 	ctx := context.Background()
 	ctxWithClaims := PutClaimsToContext(ctx, claims)
@@ -113,3 +132,34 @@ func TestContextClaims(t *testing.T) {
 	_, ok = GetClaimsFromContext(ctx)
 	assert.False(t, ok)
 }
+
+func TestContextDevice(t *testing.T) {
+	device := models.DeviceCertificateDTO{UserID: 1, Device: "laptop", Serial: "0a"}
+
+	ctx := PutDeviceToContext(context.Background(), device)
+	extracted, ok := GetDeviceFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, device, extracted)
+
+	_, ok = GetDeviceFromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestCheckDevice(t *testing.T) {
+	device := models.DeviceCertificateDTO{UserID: 42, Device: "laptop", Thumbprint: "thumb"}
+	bound := Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "42"}, Confirmation: &Confirmation{CertThumbprint: "thumb"}}
+	unbound := Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "42"}}
+	withDevice := PutDeviceToContext(context.Background(), device)
+
+	assert.NoError(t, CheckDevice(withDevice, bound, true))
+	assert.NoError(t, CheckDevice(context.Background(), unbound, false))
+	assert.ErrorIs(t, CheckDevice(withDevice, unbound, true), ErrTokenNotBound)
+	assert.ErrorIs(t, CheckDevice(context.Background(), bound, false), ErrTokenDeviceMismatch)
+
+	other := device
+	other.Thumbprint = "other"
+	assert.ErrorIs(t, CheckDevice(PutDeviceToContext(context.Background(), other), bound, false), ErrTokenDeviceMismatch)
+	other = device
+	other.UserID = 7
+	assert.ErrorIs(t, CheckDevice(PutDeviceToContext(context.Background(), other), bound, false), ErrTokenDeviceMismatch)
+}
@@ -0,0 +1,5 @@
+// Package pb содержит сгенерированные из api/proto/gophkeeper.proto типы и gRPC-клиенты GophKeeper.
+// Пакет публичный: другие сервисы импортируют его, чтобы обращаться к серверу через типизированные стабы.
+package pb
+
+//go:generate protoc -I ../../api/proto --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative gophkeeper.proto